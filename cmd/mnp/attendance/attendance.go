@@ -0,0 +1,84 @@
+// Package attendance implements the attendance command.
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/attendance"
+)
+
+func headers() []string {
+	return []string{"Player", "Matches", "Rounds/Match", "Missed Weeks"}
+}
+
+// Command shows how often each player on a team's roster has played this
+// season.
+type Command struct {
+	Team string `arg:"" default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the attendance command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	team := strings.ToUpper(c.Team)
+	r, err := attendance.Analyze(ctx, store, team)
+	if err != nil {
+		return fmt.Errorf("load attendance for %s: %w", team, err)
+	}
+
+	if len(r.Players) == 0 {
+		p.Printf("No roster found for %s\n", team)
+		return nil
+	}
+	if r.Matches == 0 {
+		p.Printf("%s hasn't played a match this season\n", team)
+		return nil
+	}
+
+	if err := p.Table(headers(), playersToRows(r)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Printf("Matches this season each %s player was in the lineup for, how many rounds\n", team)
+	p.Println("they played per match, and the weeks they weren't in the lineup.")
+	return nil
+}
+
+func playersToRows(r *attendance.Result) [][]string {
+	rows := make([][]string, len(r.Players))
+	for i, pl := range r.Players {
+		rows[i] = []string{
+			pl.Name,
+			output.FormatAttendance(pl.Matches, r.Matches),
+			fmt.Sprintf("%.1f", pl.RoundsPerMatch()),
+			formatWeeks(pl.Missed),
+		}
+	}
+	return rows
+}
+
+func formatWeeks(weeks []int) string {
+	if len(weeks) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(weeks))
+	for i, w := range weeks {
+		parts[i] = strconv.Itoa(w)
+	}
+	return strings.Join(parts, ", ")
+}
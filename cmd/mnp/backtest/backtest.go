@@ -0,0 +1,172 @@
+// Package backtest implements the backtest command.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/accuracy"
+	"github.com/negz/mnp/internal/strategy/backtest"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// Command replays past matches to measure how well matchup predictions would
+// have done.
+type Command struct {
+	Seasons string `help:"Seasons to replay, as a range (e.g., 20-23) or separated by commas (e.g., 22,23). Defaults to the latest season."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the backtest command.
+func (c *Command) Run(d *cache.DB) error {
+	seasons, err := parseSeasons(c.Seasons)
+	if err != nil {
+		return fmt.Errorf("--seasons: %w", err)
+	}
+
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	r, err := backtest.Analyze(ctx, store, backtest.InSeasons(seasons...))
+	if err != nil {
+		return fmt.Errorf("backtest: %w", err)
+	}
+
+	overall := r.Accuracy.Overall
+	if overall.Predictions == 0 {
+		p.Println("No matches to replay")
+		return nil
+	}
+
+	p.Printf("Replayed %d matches, %d predictions: %s accurate (%d correct, %d pushes)\n",
+		r.Matches, overall.Predictions, formatAccuracy(overall), overall.Correct, overall.Pushes)
+	if backtest.BeatsCoinFlip(overall) {
+		p.Println("That's significantly better than a coin flip.")
+	} else {
+		p.Println("That's not significantly better than a coin flip.")
+	}
+	p.Println()
+
+	if err := p.Table(tallyHeaders("Confidence"), confidenceRows(r.Accuracy.ByConfidence)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+	p.Println()
+	if err := p.Table(tallyHeaders("Edge"), edgeRows(r.ByEdge)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+	p.Println()
+	if err := p.Table(tallyHeaders("Season"), seasonRows(r.BySeason)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Println("Each match is predicted from games played before its week. A prediction is")
+	p.Println("correct if the team with the edge won more points on the machine. Pushes are")
+	p.Println("even predictions or split points, and don't count toward accuracy.")
+	return nil
+}
+
+// parseSeasons parses a range of seasons like 20-23, or a list like 22,23.
+func parseSeasons(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		lo, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid season %q", from)
+		}
+		hi, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("invalid season %q", to)
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("range %q ends before it starts", s)
+		}
+		seasons := make([]int, 0, hi-lo+1)
+		for n := lo; n <= hi; n++ {
+			seasons = append(seasons, n)
+		}
+		return seasons, nil
+	}
+	var seasons []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("invalid season %q", f)
+		}
+		seasons = append(seasons, n)
+	}
+	return seasons, nil
+}
+
+func tallyHeaders(first string) []string {
+	return []string{first, "Predictions", "Correct", "Pushes", "Accuracy"}
+}
+
+func tallyRow(label string, t accuracy.Tally) []string {
+	return []string{
+		label,
+		strconv.Itoa(t.Predictions),
+		strconv.Itoa(t.Correct),
+		strconv.Itoa(t.Pushes),
+		formatAccuracy(t),
+	}
+}
+
+func confidenceRows(cs []accuracy.ConfidenceAccuracy) [][]string {
+	rows := make([][]string, len(cs))
+	for i, c := range cs {
+		rows[i] = tallyRow(confidenceLabel(c.Confidence), c.Tally)
+	}
+	return rows
+}
+
+func edgeRows(es []backtest.EdgeAccuracy) [][]string {
+	rows := make([][]string, len(es))
+	for i, e := range es {
+		label := fmt.Sprintf("%.0f-%.0f%%", e.Min, e.Max)
+		if math.IsInf(e.Max, 1) {
+			label = fmt.Sprintf("%.0f%%+", e.Min)
+		}
+		rows[i] = tallyRow(label, e.Tally)
+	}
+	return rows
+}
+
+func seasonRows(ss []backtest.SeasonAccuracy) [][]string {
+	rows := make([][]string, len(ss))
+	for i, s := range ss {
+		rows[i] = tallyRow(strconv.Itoa(s.Season), s.Tally)
+	}
+	return rows
+}
+
+func formatAccuracy(t accuracy.Tally) string {
+	if t.Predictions == t.Pushes {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", t.Accuracy()*100)
+}
+
+func confidenceLabel(c matchup.Confidence) string {
+	switch c {
+	case matchup.ConfidenceHigh:
+		return "High"
+	case matchup.ConfidenceMedium:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
@@ -0,0 +1,102 @@
+// Package bot implements the bot command.
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/discord"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/web"
+)
+
+// Command starts a Discord bot that answers slash commands.
+type Command struct {
+	DiscordToken string        `env:"MNP_DISCORD_TOKEN"                                                     help:"Discord bot token."                                                                            required:""`
+	Addr         string        `default:":8081"                                                             help:"Address to listen on. Discord POSTs slash commands to /interactions, which must be reachable."`
+	Timezone     string        `default:"America/Los_Angeles"                                               help:"League timezone, used to find teams' next matches."`
+	NoRegister   bool          `help:"Don't register the bot's slash commands with Discord when it starts."`
+	CacheSize    int64         `default:"67108864"                                                          help:"Approximate maximum bytes of analyses and stats to cache in memory."`
+	SyncInterval time.Duration `default:"15m"                                                               help:"How often to sync new match data."`
+}
+
+// Run executes the bot command.
+func (c *Command) Run(d *cache.DB, _ *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	clock, err := schedule.NewClock(c.Timezone)
+	if err != nil {
+		return err
+	}
+
+	client := discord.NewClient(discord.DefaultAPIURL, c.DiscordToken)
+	app, err := client.Application(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := discord.ParsePublicKey(app.VerifyKey)
+	if err != nil {
+		return err
+	}
+	if !c.NoRegister {
+		if err := client.RegisterCommands(ctx, app.ID, discord.Commands()); err != nil {
+			return err
+		}
+		log.Info("Registered slash commands", "app", app.ID)
+	}
+
+	dbst, err := d.Store(ctx)
+	if err != nil {
+		return err
+	}
+	st := cache.NewInMemoryStore(dbst, cache.WithMaxBytes(c.CacheSize))
+
+	go web.Sync(ctx, func(ctx context.Context) error {
+		if err := d.Sync(ctx); err != nil {
+			return err
+		}
+		return st.Refresh(ctx)
+	}, c.SyncInterval, log)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /interactions", discord.NewBot(st, key, log, discord.WithClock(clock)))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	// Discord gives up on an interaction that isn't answered within three
+	// seconds, so there's no point spending longer than that.
+	s := &http.Server{
+		Addr:              c.Addr,
+		Handler:           web.WithLogging(http.TimeoutHandler(mux, 3*time.Second, "Timed out"), log),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	log.Info("Starting Discord bot", "addr", c.Addr)
+
+	errs := make(chan error, 1)
+	go func() { errs <- s.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(sctx); err != nil {
+		return fmt.Errorf("shut down Discord bot: %w", err)
+	}
+	return nil
+}
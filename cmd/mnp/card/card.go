@@ -0,0 +1,56 @@
+// Package card implements the card command.
+package card
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/card"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+// Command saves a shareable player card image.
+type Command struct {
+	Name   string `arg:""                                                                   help:"Player name (e.g., 'Jay Ostby')."`
+	Output string `help:"File to write. Defaults to the player's name, e.g. Jay-Ostby.png." short:"o"                               type:"path"`
+}
+
+// Run executes the card command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	r, err := player.Analyze(ctx, store, c.Name, player.WithSignatureWin())
+	if err != nil {
+		return fmt.Errorf("look up %s: %w", c.Name, err)
+	}
+	if len(r.GlobalStats) == 0 {
+		return fmt.Errorf("no data for %s", c.Name)
+	}
+
+	path := c.Output
+	if path == "" {
+		path = strings.ReplaceAll(r.Name, " ", "-") + ".png"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := card.Render(f, r); err != nil {
+		f.Close() //nolint:errcheck // Already returning error.
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	fmt.Printf("Saved %s's card to %s.\n", r.Name, path)
+	return nil
+}
@@ -0,0 +1,15 @@
+// Package configure implements the config command group.
+package configure
+
+import (
+	"github.com/negz/mnp/cmd/mnp/configure/set"
+	"github.com/negz/mnp/cmd/mnp/configure/show"
+	"github.com/negz/mnp/cmd/mnp/configure/unset"
+)
+
+// Command groups config file subcommands.
+type Command struct {
+	Set   set.Command   `cmd:"" help:"Set your default team or venue."`
+	Unset unset.Command `cmd:"" help:"Unset your default team or venue."`
+	Show  show.Command  `cmd:"" help:"Show your default team and venue, and where they're saved."`
+}
@@ -0,0 +1,38 @@
+// Package set implements the config set command.
+package set
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/negz/mnp/internal/config"
+)
+
+// Command sets a default in the config file.
+type Command struct {
+	Key   string `arg:"" enum:"team,venue" help:"Setting to set: team or venue."`
+	Value string `arg:""                   help:"Team or venue key (e.g., CRA or ANC)."`
+}
+
+// Run executes the config set command.
+func (c *Command) Run() error {
+	path := config.Path()
+	if path == "" {
+		return errors.New("can't determine the user config directory")
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set(c.Key, c.Value); err != nil {
+		return err
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Default %s is now %s.\n", c.Key, strings.ToUpper(c.Value))
+	return nil
+}
@@ -0,0 +1,26 @@
+// Package show implements the config show command.
+package show
+
+import (
+	"fmt"
+
+	"github.com/negz/mnp/internal/config"
+)
+
+// Command shows the defaults in the config file.
+type Command struct{}
+
+// Run executes the config show command.
+func (c *Command) Run(cfg *config.Config) error {
+	fmt.Printf("Config file: %s\n", config.Path())
+	fmt.Printf("Team:        %s\n", orNone(cfg.Team))
+	fmt.Printf("Venue:       %s\n", orNone(cfg.Venue))
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
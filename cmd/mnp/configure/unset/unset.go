@@ -0,0 +1,36 @@
+// Package unset implements the config unset command.
+package unset
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/negz/mnp/internal/config"
+)
+
+// Command unsets a default in the config file.
+type Command struct {
+	Key string `arg:"" enum:"team,venue" help:"Setting to unset: team or venue."`
+}
+
+// Run executes the config unset command.
+func (c *Command) Run() error {
+	path := config.Path()
+	if path == "" {
+		return errors.New("can't determine the user config directory")
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set(c.Key, ""); err != nil {
+		return err
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Unset default %s.\n", c.Key)
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package aliasmachines implements the alias-machines command.
+package aliasmachines
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/opdb"
+)
+
+// Command loads curated machine aliases from a CSV file or URL.
+type Command struct {
+	Source string `arg:"" help:"CSV file or URL with key and opdb_id columns."`
+}
+
+// Run executes the alias-machines command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	n, err := opdb.LoadAliases(ctx, store, c.Source)
+	if err != nil {
+		return fmt.Errorf("load machine aliases: %w", err)
+	}
+
+	fmt.Printf("Loaded %d machine aliases from %s. Run mnp db sync-machines to match them.\n", n, c.Source)
+	return nil
+}
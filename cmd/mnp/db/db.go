@@ -2,12 +2,32 @@
 package db
 
 import (
+	"github.com/negz/mnp/cmd/mnp/db/aliasmachines"
+	"github.com/negz/mnp/cmd/mnp/db/export"
+	"github.com/negz/mnp/cmd/mnp/db/freshness"
+	"github.com/negz/mnp/cmd/mnp/db/importcsv"
+	"github.com/negz/mnp/cmd/mnp/db/importdb"
+	"github.com/negz/mnp/cmd/mnp/db/importexternal"
 	"github.com/negz/mnp/cmd/mnp/db/query"
+	"github.com/negz/mnp/cmd/mnp/db/retransform"
 	"github.com/negz/mnp/cmd/mnp/db/schema"
+	"github.com/negz/mnp/cmd/mnp/db/syncipr"
+	"github.com/negz/mnp/cmd/mnp/db/syncmachines"
+	"github.com/negz/mnp/cmd/mnp/db/unmatchedmachines"
 )
 
 // Command groups database utility subcommands.
 type Command struct {
-	Query  query.Command  `cmd:"" help:"Run a SQL query against the database."`
-	Schema schema.Command `cmd:"" help:"Print the database schema."`
+	Query             query.Command             `cmd:"" help:"Run a read-only SQL query against the database."`
+	Schema            schema.Command            `cmd:"" help:"Print the database schema."`
+	ImportExternal    importexternal.Command    `cmd:"" help:"Import other leagues' scores from a CSV file."`
+	ImportCSV         importcsv.Command         `cmd:"" help:"Import a season that predates the MNP archive from a CSV file."`
+	SyncIPR           syncipr.Command           `cmd:"" help:"Load player IPRs from a CSV or JSON file or URL."`
+	SyncMachines      syncmachines.Command      `cmd:"" help:"Load machine manufacturers and years from the Open Pinball Database (OPDB)."`
+	AliasMachines     aliasmachines.Command     `cmd:"" help:"Map machines to OPDB's machines from a CSV file, for machines whose names don't match."`
+	UnmatchedMachines unmatchedmachines.Command `cmd:"" help:"List machines without a manufacturer or year."`
+	Freshness         freshness.Command         `cmd:"" help:"Show which archive commit the data is from, and when it was last synced."`
+	Export            export.Command            `cmd:"" help:"Save a copy of the database to share with teammates."`
+	Import            importdb.Command          `cmd:"" help:"Replace the database with one saved by export."`
+	Retransform       retransform.Command       `cmd:"" help:"Load matches again from the match JSON stored by the last sync."`
 }
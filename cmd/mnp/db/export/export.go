@@ -0,0 +1,30 @@
+// Package export implements the export command.
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/cache"
+)
+
+// Command saves a copy of the database to share.
+type Command struct {
+	File string `arg:"" help:"File to write the database to. Must not exist." type:"path"`
+}
+
+// Run executes the export command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	if err := store.Export(ctx, c.File); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported the database to %s. Import it with mnp db import.\n", c.File)
+	return nil
+}
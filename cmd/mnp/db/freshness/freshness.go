@@ -0,0 +1,39 @@
+// Package freshness implements the freshness command.
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command shows which archive commit the database was last synced from.
+type Command struct{}
+
+// Run executes the freshness command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	f, err := store.GetFreshness(ctx)
+	if err != nil {
+		return fmt.Errorf("get data freshness: %w", err)
+	}
+	if f.Commit == "" {
+		fmt.Println("Never synced. Run any command, or pass --sync, to sync the MNP archive.")
+		return nil
+	}
+
+	now := time.Now()
+	fmt.Println(output.FormatDataAsOf(f.Commit, f.CommitTime.Local(), now))
+	if !f.LastSync.IsZero() {
+		fmt.Printf("Last synced %s.\n", f.LastSync.Local().Format("Mon Jan 2 3:04pm"))
+	}
+	return nil
+}
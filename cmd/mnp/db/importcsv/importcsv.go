@@ -0,0 +1,79 @@
+// Package importcsv implements the import-csv command.
+package importcsv
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/csvimport"
+	"github.com/negz/mnp/internal/mnp"
+)
+
+// Command imports a season that predates the MNP archive from a CSV file.
+type Command struct {
+	File   string `arg:""                                 help:"CSV file with one row per player per game." type:"existingfile"`
+	Season int    `help:"Season number the CSV contains." required:""`
+	Format string `default:"legacy"                       enum:"legacy"                                     help:"CSV format (${enum})."`
+}
+
+// Run executes the import-csv command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	latest, err := store.MaxSeasonNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("get latest season: %w", err)
+	}
+	if latest > 0 && c.Season > latest {
+		return fmt.Errorf("season %d is newer than the latest season %d; only seasons that predate the archive can be imported", c.Season, latest)
+	}
+
+	f, err := os.Open(c.File)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", c.File, err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only file.
+
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	matches, err := csvimport.ParseLegacy(f, c.Season, formats.For(c.Season))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", c.File, err)
+	}
+
+	// Keep known venues' names rather than replacing them with their keys.
+	venues, err := store.ListVenues(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list venues: %w", err)
+	}
+	names := make(map[string]string, len(venues))
+	for _, v := range venues {
+		names[v.Key] = v.Name
+	}
+	games := 0
+	for i := range matches {
+		if name, ok := names[matches[i].Venue.Key]; ok {
+			matches[i].Venue.Name = name
+		}
+		games += len(matches[i].Games)
+	}
+
+	if err := mnp.LoadMatches(ctx, store, c.Season, matches); err != nil {
+		return fmt.Errorf("import season %d: %w", c.Season, err)
+	}
+	if err := store.RebuildStats(ctx); err != nil {
+		return fmt.Errorf("rebuild stats: %w", err)
+	}
+
+	fmt.Printf("Imported %d matches (%d games) into season %d.\n", len(matches), games, c.Season)
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package importdb implements the import command.
+package importdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command replaces the database with one saved by the export command.
+type Command struct {
+	File string `arg:"" help:"Database file written by mnp db export." type:"existingfile"`
+}
+
+// Run executes the import command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	if err := d.Import(ctx, c.File); err != nil {
+		return fmt.Errorf("import %s: %w", c.File, err)
+	}
+
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	f, err := store.GetFreshness(ctx)
+	if err != nil {
+		return fmt.Errorf("get data freshness: %w", err)
+	}
+	if f.Commit == "" {
+		fmt.Println("Imported the database.")
+		return nil
+	}
+	fmt.Printf("Imported the database. %s.\n", output.FormatDataAsOf(f.Commit, f.CommitTime.Local(), time.Now()))
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package importexternal implements the import-external command.
+package importexternal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/csvimport"
+)
+
+// Command imports other leagues' scores from a CSV file.
+type Command struct {
+	File   string `arg:""                                                                   help:"CSV file with venue, machine, and score columns, and optionally player and date." type:"existingfile"`
+	Source string `help:"Where the scores came from (e.g., \"Add-a-Ball Tuesday league\")." required:""`
+}
+
+// Run executes the import-external command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	f, err := os.Open(c.File)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", c.File, err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only file.
+
+	results, err := csvimport.ParseExternal(f)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", c.File, err)
+	}
+
+	venues, err := store.ListVenues(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list venues: %w", err)
+	}
+	known := make(map[string]bool, len(venues))
+	for _, v := range venues {
+		known[v.Key] = true
+	}
+	var unknown []string
+	for _, r := range results {
+		if len(known) > 0 && !known[r.VenueKey] && !slices.Contains(unknown, r.VenueKey) {
+			unknown = append(unknown, r.VenueKey)
+		}
+	}
+	for _, v := range unknown {
+		fmt.Fprintf(os.Stderr, "Warning: %s isn't a known MNP venue\n", v)
+	}
+
+	if err := store.ReplaceExternalResults(ctx, c.Source, results); err != nil {
+		return fmt.Errorf("import scores: %w", err)
+	}
+
+	fmt.Printf("Imported %d scores from %s.\n", len(results), c.Source)
+	return nil
+}
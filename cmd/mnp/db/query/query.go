@@ -2,18 +2,22 @@
 package query
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"strings"
-	"text/tabwriter"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
 )
 
-// Command runs SQL queries against the MNP database.
+// Command runs a read-only SQL query against the MNP database.
 type Command struct {
-	SQL string `arg:"" help:"SQL query to execute."`
+	SQL string `arg:"" help:"SELECT statement to execute. Statements that write are rejected."`
+
+	Output string `default:"table" enum:"table,csv,json" help:"Write rows as a text table, as CSV for spreadsheets, or as JSON." short:"o"`
 }
 
 // Run executes the query command.
@@ -24,48 +28,64 @@ func (c *Command) Run(db *cache.DB) error {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	rows, err := store.DB().QueryContext(ctx, c.SQL)
+	r, err := store.QueryReadOnly(ctx, c.SQL)
 	if err != nil {
-		return fmt.Errorf("query: %w", err)
+		return err
 	}
-	defer rows.Close() //nolint:errcheck // Nothing to do with error on program exit.
 
-	cols, err := rows.Columns()
-	if err != nil {
-		return fmt.Errorf("get columns: %w", err)
+	if c.Output == "json" {
+		return writeJSON(os.Stdout, r.Columns, r.Rows)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-	// Print header
-	fmt.Fprintln(w, strings.Join(cols, "\t")) //nolint:errcheck // Errors surface in Flush.
-
-	// Print rows
-	values := make([]any, len(cols))
-	ptrs := make([]any, len(cols))
-	for i := range values {
-		ptrs[i] = &values[i]
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = make([]string, len(row))
+		for j, v := range row {
+			if v != nil {
+				rows[i][j] = fmt.Sprintf("%v", v)
+			}
+		}
 	}
 
-	for rows.Next() {
-		if err := rows.Scan(ptrs...); err != nil {
-			return fmt.Errorf("scan row: %w", err)
-		}
+	p := output.NewPrinter(output.Format(c.Output), os.Stdout, os.Stderr)
+	return p.Table(r.Columns, rows)
+}
 
-		strs := make([]string, len(cols))
-		for i, v := range values {
-			if v == nil {
-				strs[i] = ""
-			} else {
-				strs[i] = fmt.Sprintf("%v", v)
+// writeJSON writes rows as an array of objects, keeping the columns' order.
+func writeJSON(w io.Writer, cols []string, rows [][]any) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			if b, ok := v.([]byte); ok {
+				v = string(b)
 			}
+			k, err := json.Marshal(cols[j])
+			if err != nil {
+				return fmt.Errorf("encode column %s: %w", cols[j], err)
+			}
+			val, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("encode column %s: %w", cols[j], err)
+			}
+			buf.Write(k)
+			buf.WriteString(": ")
+			buf.Write(val)
 		}
-		fmt.Fprintln(w, strings.Join(strs, "\t")) //nolint:errcheck // Errors surface in Flush.
+		buf.WriteString("}")
 	}
-
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("iterate rows: %w", err)
+	if len(rows) > 0 {
+		buf.WriteString("\n")
 	}
+	buf.WriteString("]\n")
 
-	return w.Flush()
+	_, err := w.Write(buf.Bytes())
+	return err
 }
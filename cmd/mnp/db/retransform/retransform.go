@@ -0,0 +1,34 @@
+// Package retransform implements the retransform command.
+package retransform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/cache"
+)
+
+// Command loads matches again from the match JSON stored by the last sync.
+type Command struct {
+	Season []int `help:"Only retransform these seasons, separated by commas (e.g., 22,23). Defaults to every loaded season."`
+}
+
+// Run executes the retransform command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	if _, err := d.Store(ctx); err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	n, err := d.Retransform(ctx, c.Season...)
+	if err != nil {
+		return fmt.Errorf("retransform matches: %w", err)
+	}
+
+	if n == 0 {
+		fmt.Println("No stored match JSON. Run any command, or pass --sync, to sync the MNP archive.")
+		return nil
+	}
+	fmt.Printf("Retransformed %d matches.\n", n)
+	return nil
+}
@@ -0,0 +1,41 @@
+// Package syncipr implements the sync-ipr command.
+package syncipr
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/ipr"
+)
+
+// Command loads player IPRs from a CSV or JSON file or URL.
+type Command struct {
+	Source string `arg:"" help:"CSV or JSON file or URL with name and ipr columns or fields. Defaults to --ipr-source, or the archive's IPR.csv." optional:""`
+}
+
+// Run executes the sync-ipr command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	source := c.Source
+	if source == "" {
+		source = d.IPRSource
+	}
+	if source == "" {
+		source = filepath.Join(d.ArchiveDir(), "IPR.csv")
+	}
+
+	n, err := ipr.Sync(ctx, store, source)
+	if err != nil {
+		return fmt.Errorf("sync IPRs: %w", err)
+	}
+
+	fmt.Printf("Loaded %d IPRs from %s.\n", n, source)
+	return nil
+}
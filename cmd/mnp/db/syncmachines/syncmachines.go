@@ -0,0 +1,50 @@
+// Package syncmachines implements the sync-machines command.
+package syncmachines
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/opdb"
+)
+
+// Command loads machine manufacturers and years from OPDB.
+type Command struct {
+	Source string `arg:"" help:"opdb to fetch OPDB's export API, or a saved OPDB export JSON file or URL. Defaults to --machine-source, or opdb." optional:""`
+}
+
+// Run executes the sync-machines command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	source := c.Source
+	if source == "" {
+		source = d.MachineSource
+	}
+	if source == "" {
+		source = opdb.SourceAPI
+	}
+
+	r, err := opdb.Sync(ctx, store, source, opdb.WithToken(d.OPDBToken))
+	if err != nil {
+		return fmt.Errorf("sync machines: %w", err)
+	}
+
+	fmt.Printf("Found the manufacturer and year of %d machines in %s.\n", r.Matched, source)
+	if len(r.Fuzzy) > 0 {
+		fmt.Printf("Matched %d by similar names. Alias any that are wrong with mnp db alias-machines: %s\n", len(r.Fuzzy), strings.Join(r.Fuzzy, ", "))
+	}
+	if len(r.Duplicates) > 0 {
+		fmt.Printf("Matched %d OPDB machines more than once. Alias any that are wrong with mnp db alias-machines: %s\n", len(r.Duplicates), strings.Join(r.Duplicates, ", "))
+	}
+	if len(r.Unmatched) > 0 {
+		fmt.Printf("Couldn't find %d: %s\n", len(r.Unmatched), strings.Join(r.Unmatched, ", "))
+	}
+	return nil
+}
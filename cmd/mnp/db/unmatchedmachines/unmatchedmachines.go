@@ -0,0 +1,48 @@
+// Package unmatchedmachines implements the unmatched-machines command.
+package unmatchedmachines
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command lists machines that have no manufacturer or year.
+type Command struct {
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the unmatched-machines command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	machines, err := store.ListUnmatchedMachines(ctx)
+	if err != nil {
+		return fmt.Errorf("load unmatched machines: %w", err)
+	}
+	if len(machines) == 0 {
+		p.Println("Every machine has a manufacturer and year.")
+		return nil
+	}
+
+	rows := make([][]string, len(machines))
+	for i, m := range machines {
+		rows[i] = []string{m.Key, m.Name}
+	}
+	if err := p.Table([]string{"Key", "Name"}, rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Println("These machines' names don't match any in OPDB. Map them to OPDB's with")
+	p.Println("mnp db alias-machines, then run mnp db sync-machines.")
+	return nil
+}
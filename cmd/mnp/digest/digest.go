@@ -0,0 +1,49 @@
+// Package digest implements the digest command.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/digest"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// Command summarizes a team's next match, and optionally posts the summary to
+// a webhook.
+type Command struct {
+	Team    string `arg:""                       default:"${team}"                                                                   help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Webhook string `env:"MNP_DIGEST_WEBHOOK_URL" help:"Slack-compatible webhook URL to post the digest to, rather than printing it."`
+}
+
+// Run executes the digest command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+
+	team := strings.ToUpper(c.Team)
+	dg, err := digest.Analyze(ctx, store, clock.Today(time.Now()), team)
+	if err != nil {
+		return fmt.Errorf("summarize %s's next match: %w", team, err)
+	}
+
+	if c.Webhook == "" {
+		fmt.Println(digest.Message(dg))
+		return nil
+	}
+	if dg.Match == nil {
+		return fmt.Errorf("%s has no upcoming matches", team)
+	}
+	return digest.NewWebhook(c.Webhook).Post(ctx, dg)
+}
@@ -0,0 +1,93 @@
+// Package doubles implements the doubles command.
+package doubles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/doubles"
+)
+
+func headers() []string {
+	return []string{"Pair", "Games", "Points", "Efficiency"}
+}
+
+// Command shows which teammates play doubles well together.
+type Command struct {
+	Team   string `arg:""                                                                         default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Season []int  `help:"Only count games from these seasons, separated by commas (e.g., 22,23)."`
+}
+
+// Run executes the doubles command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	opts := []doubles.Option{doubles.WithFormats(formats)}
+	if len(c.Season) > 0 {
+		opts = append(opts, doubles.InSeasons(c.Season...))
+	}
+
+	team := strings.ToUpper(c.Team)
+	r, err := doubles.Analyze(ctx, store, team, opts...)
+	if err != nil {
+		return fmt.Errorf("analyze %s doubles: %w", c.Team, err)
+	}
+
+	if len(r.Pairs) == 0 {
+		fmt.Printf("No doubles games played together by players on %s's season %d roster\n", team, r.Season)
+		return nil
+	}
+
+	if err := output.Table(os.Stdout, headers(), pairsToRows(r.Pairs)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Pairs of players on %s's season %d roster, by the share of the points\n", team, r.Season)
+	fmt.Println("possible they won playing doubles together.")
+
+	fmt.Println()
+	for _, round := range r.Rounds {
+		names := make([]string, len(round.Pairs))
+		for i, p := range round.Pairs {
+			names[i] = pairName(p)
+		}
+		if len(names) == 0 {
+			names = []string{"No proven pairs left"}
+		}
+		fmt.Printf("Round %d: %s\n", round.Number, strings.Join(names, ", "))
+	}
+	fmt.Println()
+	fmt.Println("Recommended pairs have played at least 3 doubles games together.")
+	return nil
+}
+
+func pairName(p doubles.Pair) string {
+	return p.Player1 + " & " + p.Player2
+}
+
+func pairsToRows(pairs []doubles.Pair) [][]string {
+	rows := make([][]string, len(pairs))
+	for i, p := range pairs {
+		rows[i] = []string{
+			pairName(p),
+			fmt.Sprintf("%d", p.Games),
+			fmt.Sprintf("%s of %s", output.FormatPoints(p.PointsWon), output.FormatPoints(p.PointsPossible)),
+			fmt.Sprintf("%.0f%%", p.Efficiency()*100),
+		}
+	}
+	return rows
+}
@@ -0,0 +1,104 @@
+// Package games implements the games command.
+package games
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command lists individual game results, so aggregates can be checked against
+// the games behind them.
+type Command struct {
+	Player  string `help:"Only games played by this player. Case doesn't matter."`
+	Team    string `help:"Only games played for this team (e.g., CRA)."`
+	Machine string `help:"Only games on this machine (e.g., TZ)."`
+	Season  int    `help:"Only games from this season. Defaults to every season."`
+	Sort    string `default:"newest"                                              enum:"newest,oldest,score,points"                       help:"Order games newest or oldest first, or by highest score or most points."`
+	Limit   int    `default:"50"                                                  help:"Most games to list. Set to 0 to list every game."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the games command.
+func (c *Command) Run(d *cache.DB) error {
+	if c.Player == "" && c.Team == "" && c.Machine == "" {
+		return fmt.Errorf("filter games with at least one of --player, --team, or --machine")
+	}
+	if c.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	log, err := store.GetGameLog(ctx, db.GameLogFilter{
+		PlayerName: c.Player,
+		TeamKey:    strings.ToUpper(c.Team),
+		MachineKey: strings.ToUpper(c.Machine),
+		Season:     c.Season,
+		Sort:       db.GameLogSort(c.Sort),
+		Limit:      c.Limit,
+	})
+	if err != nil {
+		return fmt.Errorf("list games: %w", err)
+	}
+	if len(log) == 0 {
+		p.Println("No games found")
+		return nil
+	}
+
+	names, err := store.GetMachineNames(ctx)
+	if err != nil {
+		return fmt.Errorf("load machine names: %w", err)
+	}
+
+	rows := make([][]string, len(log))
+	for i, e := range log {
+		rows[i] = []string{
+			fmt.Sprintf("%d", e.Season),
+			fmt.Sprintf("%d", e.Week),
+			formatDate(e.Date),
+			e.PlayerName,
+			e.TeamKey,
+			e.OpponentKey,
+			output.MachineName(names, e.MachineKey),
+			formatRound(e),
+			output.FormatScore(float64(e.Score)),
+			output.FormatPoints(e.Points),
+		}
+	}
+	if err := p.Table([]string{"Season", "Week", "Date", "Player", "Team", "Vs", "Machine", "Round", "Score", "Points"}, rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	if c.Limit > 0 && len(log) == c.Limit {
+		p.Println()
+		p.Printf("Showing the first %d games. Use --limit to see more.\n", c.Limit)
+	}
+	return nil
+}
+
+func formatDate(date string) string {
+	if date == "" {
+		return "-"
+	}
+	return date
+}
+
+// formatRound returns a game's round, noting doubles rounds, e.g. "1 (doubles)".
+func formatRound(e db.GameLogEntry) string {
+	if e.Doubles {
+		return fmt.Sprintf("%d (doubles)", e.Round)
+	}
+	return fmt.Sprintf("%d", e.Round)
+}
@@ -0,0 +1,15 @@
+// Package goal implements the goal command group.
+package goal
+
+import (
+	"github.com/negz/mnp/cmd/mnp/goal/list"
+	"github.com/negz/mnp/cmd/mnp/goal/remove"
+	"github.com/negz/mnp/cmd/mnp/goal/set"
+)
+
+// Command groups player goal subcommands.
+type Command struct {
+	Set    set.Command    `cmd:"" help:"Set a goal for a player on a machine."`
+	List   list.Command   `cmd:"" help:"Show a player's progress toward their goals."`
+	Remove remove.Command `cmd:"" help:"Remove a goal."`
+}
@@ -0,0 +1,80 @@
+// Package list implements the goal list command.
+package list
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+func headers() []string {
+	return []string{"ID", "Machine", "Goal", "Set", "Games", "Current", "Status"}
+}
+
+// Command shows a player's progress toward their goals.
+type Command struct {
+	Player string `arg:"" help:"Player name (e.g., 'Jay Ostby')."`
+}
+
+// Run executes the goal list command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	r, err := player.Analyze(ctx, store, c.Player, player.WithGoals())
+	if err != nil {
+		return fmt.Errorf("look up %s: %w", c.Player, err)
+	}
+
+	if len(r.Goals) == 0 {
+		fmt.Printf("No goals for %s\n", r.Name)
+		return nil
+	}
+
+	return output.Table(os.Stdout, headers(), goalsToRows(r.Goals))
+}
+
+func goalsToRows(goals []player.GoalProgress) [][]string {
+	rows := make([][]string, len(goals))
+	for i, g := range goals {
+		target, current := output.FormatScore(g.Target), output.FormatScore(g.Current)
+		if g.Kind == db.GoalPercentile {
+			target, current = fmt.Sprintf("%.0f%%", g.Target), fmt.Sprintf("%.0f%%", g.Current)
+		}
+
+		status := fmt.Sprintf("%.0f%% there", g.Progress()*100)
+		switch {
+		case g.Met:
+			status = "Met"
+		case g.Games == 0:
+			status = "No games yet"
+			current = "-"
+		}
+
+		rows[i] = []string{
+			fmt.Sprintf("%d", g.ID),
+			g.MachineName,
+			describe(g.Kind, target),
+			g.SetOn,
+			fmt.Sprintf("%d", g.Games),
+			current,
+			status,
+		}
+	}
+	return rows
+}
+
+func describe(kind db.GoalKind, target string) string {
+	if kind == db.GoalPercentile {
+		return "Median rank " + target
+	}
+	return "Score " + target
+}
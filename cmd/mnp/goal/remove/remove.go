@@ -0,0 +1,30 @@
+// Package remove implements the goal remove command.
+package remove
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/cache"
+)
+
+// Command removes a goal.
+type Command struct {
+	ID int64 `arg:"" help:"Goal ID, as shown by mnp goal list."`
+}
+
+// Run executes the goal remove command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	if err := store.DeleteGoal(ctx, c.ID); err != nil {
+		return fmt.Errorf("remove goal %d: %w", c.ID, err)
+	}
+
+	fmt.Printf("Removed goal %d.\n", c.ID)
+	return nil
+}
@@ -0,0 +1,69 @@
+// Package set implements the goal set command.
+package set
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// Command sets a goal for a player on a machine.
+type Command struct {
+	Player     string  `arg:""                                                                            help:"Player name (e.g., 'Jay Ostby')."`
+	Machine    string  `arg:""                                                                            help:"Machine key (e.g., MM)."`
+	Score      string  `help:"Score to beat (e.g., 100M)."                                                xor:"target"`
+	Percentile float64 `help:"Median percentile rank to reach over recent games (e.g., 50 for top half)." xor:"target"`
+}
+
+// Run executes the goal set command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	g := db.Goal{PlayerName: c.Player, MachineKey: strings.ToUpper(c.Machine)}
+	switch {
+	case c.Score != "":
+		if g.Target, err = output.ParseScore(c.Score); err != nil {
+			return err
+		}
+		g.Kind = db.GoalScore
+	case c.Percentile > 0 && c.Percentile <= 100:
+		g.Target = c.Percentile
+		g.Kind = db.GoalPercentile
+	default:
+		return errors.New("set either --score, or a --percentile between 1 and 100")
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	g.SetOn = clock.Today(time.Now())
+
+	names, err := store.GetMachineNames(ctx)
+	if err != nil {
+		return fmt.Errorf("load machine names: %w", err)
+	}
+	if _, ok := names[g.MachineKey]; len(names) > 0 && !ok {
+		fmt.Fprintf(os.Stderr, "Warning: %s isn't a known machine\n", g.MachineKey)
+	}
+
+	id, err := store.AddGoal(ctx, g)
+	if err != nil {
+		return fmt.Errorf("set goal: %w", err)
+	}
+
+	fmt.Printf("Set goal %d for %s on %s.\n", id, g.PlayerName, output.MachineName(names, g.MachineKey))
+	return nil
+}
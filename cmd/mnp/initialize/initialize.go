@@ -0,0 +1,100 @@
+// Package initialize implements the init command.
+package initialize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/negz/mnp/internal/config"
+)
+
+// Command writes a starter config, and optionally a systemd unit that runs the
+// web UI.
+type Command struct {
+	Team    string `arg:""                                                                  help:"Your team's key (e.g., CRA). Adds aliases for its captain's common commands." optional:""`
+	Force   bool   `help:"Overwrite an existing config file or systemd unit."`
+	Systemd bool   `help:"Also write a systemd user unit that runs the web UI. Linux only."`
+	Addr    string `default:":8080"                                                         help:"Address the systemd unit's web UI listens on."`
+}
+
+// Run executes the init command.
+func (c *Command) Run() error {
+	path, unit := config.Path(), config.SystemdUnitPath()
+	if path == "" || unit == "" {
+		return errors.New("can't determine the user config directory")
+	}
+	if c.Systemd && runtime.GOOS != "linux" {
+		return fmt.Errorf("systemd units are only supported on Linux, not %s", runtime.GOOS)
+	}
+
+	// Check before writing anything, so nothing is written unless
+	// everything can be.
+	if err := c.checkOverwrite(path); err != nil {
+		return err
+	}
+	if c.Systemd {
+		if err := c.checkOverwrite(unit); err != nil {
+			return err
+		}
+	}
+
+	cfg := config.Starter(c.Team)
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s.\n", path)
+	if cfg.Team != "" {
+		fmt.Printf("  Commands that take a team default to %s.\n", cfg.Team)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Aliases)) {
+		fmt.Printf("  mnp %-6s runs mnp %s\n", name, cfg.Aliases[name])
+	}
+
+	if !c.Systemd {
+		return nil
+	}
+	return c.writeUnit(unit)
+}
+
+// writeUnit writes a systemd user unit that runs this binary's web UI.
+func (c *Command) writeUnit(path string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("find mnp binary: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create systemd unit directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(config.SystemdUnit(exe, c.Addr)), 0o600); err != nil {
+		return fmt.Errorf("write systemd unit: %w", err)
+	}
+
+	fmt.Printf("\nWrote %s. Start the web UI now and at login with:\n\n", path)
+	fmt.Println("  systemctl --user daemon-reload")
+	fmt.Println("  systemctl --user enable --now mnp")
+	fmt.Println("\nTo keep it running when you're logged out, run: loginctl enable-linger")
+	return nil
+}
+
+// checkOverwrite returns an error if the file at path exists and --force isn't
+// set.
+func (c *Command) checkOverwrite(path string) error {
+	if c.Force {
+		return nil
+	}
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%s already exists. Pass --force to overwrite it", path)
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return fmt.Errorf("check %s: %w", path, err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package leaderboard implements the leaderboard command.
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command lists the highest scores ever posted on each machine.
+type Command struct {
+	Machine string `arg:""                                                         help:"Machine key (e.g., TZ). Omit to list every machine's top scores." optional:""`
+	Venue   string `help:"Only scores posted at this venue."                       short:"e"`
+	Season  int    `help:"Only scores from this season. Defaults to every season."`
+	Top     int    `default:"5"                                                    help:"Scores to list for each machine."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the leaderboard command.
+func (c *Command) Run(d *cache.DB) error {
+	if c.Top < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	scores, err := store.GetLeaderboard(ctx, strings.ToUpper(c.Machine), strings.ToUpper(c.Venue), c.Season, c.Top)
+	if err != nil {
+		return fmt.Errorf("get leaderboard: %w", err)
+	}
+	if len(scores) == 0 {
+		p.Println("No scores found")
+		return nil
+	}
+
+	names, err := store.GetMachineNames(ctx)
+	if err != nil {
+		return fmt.Errorf("load machine names: %w", err)
+	}
+
+	rows := make([][]string, len(scores))
+	for i, ts := range scores {
+		rows[i] = []string{
+			output.MachineName(names, ts.MachineKey),
+			fmt.Sprintf("%d", ts.Rank),
+			output.FormatScore(float64(ts.Score)),
+			ts.PlayerName,
+			ts.TeamKey,
+			formatMatch(ts),
+		}
+	}
+	return p.Table([]string{"Machine", "#", "Score", "Player", "Team", "Match"}, rows)
+}
+
+// formatMatch returns when and where a score was posted, e.g. "S23 W1,
+// 2024-01-15 at STN".
+func formatMatch(ts db.TopScore) string {
+	m := fmt.Sprintf("S%d W%d", ts.Season, ts.Week)
+	if ts.Date != "" {
+		m += ", " + ts.Date
+	}
+	if ts.VenueKey != "" {
+		m += " at " + ts.VenueKey
+	}
+	return m
+}
@@ -5,33 +5,56 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
 	"github.com/negz/mnp/internal/output"
 )
 
-// Command lists all machines with their keys.
+// Command lists all machines with their keys, the venues that have them, and
+// how much they've been played this season.
 type Command struct {
 	Search string `arg:"" help:"Search term (matches key or name)." optional:""`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the machines command.
 func (c *Command) Run(d *cache.DB) error {
 	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	machines, err := store.ListMachines(ctx, c.Search)
+	machines, err := store.ListMachineSummaries(ctx, c.Search)
 	if err != nil {
 		return fmt.Errorf("list machines: %w", err)
 	}
 
 	rows := make([][]string, len(machines))
 	for i, m := range machines {
-		rows[i] = []string{m.Key, m.Name}
+		venues := make([]string, len(m.Venues))
+		for j, v := range m.Venues {
+			venues[j] = v.Key
+		}
+		rows[i] = []string{m.Key, m.Name, formatMade(m), strings.Join(venues, ", "), fmt.Sprintf("%d", m.Games)}
 	}
 
-	return output.Table(os.Stdout, []string{"Key", "Name"}, rows)
+	return p.Table([]string{"Key", "Name", "Made", "Venues", "Games"}, rows, output.WrapColumn(3, 40))
+}
+
+// formatMade returns who made a machine and when, e.g. "Stern 2024", or "-" if
+// that's unknown. See mnp db sync-machines.
+func formatMade(m db.MachineSummary) string {
+	made := strings.TrimSpace(m.Manufacturer)
+	if m.Year != 0 {
+		made = strings.TrimSpace(fmt.Sprintf("%s %d", made, m.Year))
+	}
+	if made == "" {
+		return "-"
+	}
+	return made
 }
@@ -7,17 +7,43 @@ import (
 
 	"github.com/alecthomas/kong"
 
+	"github.com/negz/mnp/cmd/mnp/attendance"
+	"github.com/negz/mnp/cmd/mnp/backtest"
+	"github.com/negz/mnp/cmd/mnp/bot"
+	"github.com/negz/mnp/cmd/mnp/card"
+	"github.com/negz/mnp/cmd/mnp/configure"
 	"github.com/negz/mnp/cmd/mnp/db"
+	"github.com/negz/mnp/cmd/mnp/digest"
+	"github.com/negz/mnp/cmd/mnp/doubles"
+	"github.com/negz/mnp/cmd/mnp/games"
+	"github.com/negz/mnp/cmd/mnp/goal"
+	"github.com/negz/mnp/cmd/mnp/initialize"
+	"github.com/negz/mnp/cmd/mnp/leaderboard"
 	"github.com/negz/mnp/cmd/mnp/machines"
 	"github.com/negz/mnp/cmd/mnp/matchup"
+	"github.com/negz/mnp/cmd/mnp/next"
 	"github.com/negz/mnp/cmd/mnp/player"
 	"github.com/negz/mnp/cmd/mnp/players"
+	"github.com/negz/mnp/cmd/mnp/practice"
+	"github.com/negz/mnp/cmd/mnp/predict"
+	"github.com/negz/mnp/cmd/mnp/recap"
 	"github.com/negz/mnp/cmd/mnp/recommend"
+	"github.com/negz/mnp/cmd/mnp/recruit"
+	"github.com/negz/mnp/cmd/mnp/report"
+	"github.com/negz/mnp/cmd/mnp/rosterchanges"
+	"github.com/negz/mnp/cmd/mnp/schedule"
 	"github.com/negz/mnp/cmd/mnp/scout"
+	"github.com/negz/mnp/cmd/mnp/search"
 	"github.com/negz/mnp/cmd/mnp/serve"
+	"github.com/negz/mnp/cmd/mnp/simulate"
+	"github.com/negz/mnp/cmd/mnp/standings"
+	"github.com/negz/mnp/cmd/mnp/team"
 	"github.com/negz/mnp/cmd/mnp/teams"
+	"github.com/negz/mnp/cmd/mnp/venue"
 	"github.com/negz/mnp/cmd/mnp/venues"
+	"github.com/negz/mnp/cmd/mnp/week"
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/config"
 	"github.com/negz/mnp/internal/version"
 )
 
@@ -25,28 +51,87 @@ type cli struct {
 	Version kong.VersionFlag `help:"Print version."       short:"V"`
 	Verbose bool             `help:"Print sync progress." short:"v"`
 
-	Recommend recommend.Command `cmd:"" help:"Recommend players for a machine."`
-	Scout     scout.Command     `cmd:"" help:"Scout a team's strengths and weaknesses."`
-	Matchup   matchup.Command   `cmd:"" help:"Compare two teams head-to-head at a venue."`
-	Player    player.Command    `cmd:"" help:"Show a player's stats across machines."`
-	Players   players.Command   `cmd:"" help:"List all players."`
-	Teams     teams.Command     `cmd:"" help:"List all teams."`
-	Venues    venues.Command    `cmd:"" help:"List all venues."`
-	Machines  machines.Command  `cmd:"" help:"List all machines."`
-	DB        db.Command        `cmd:"" help:"Database utilities."`
-	Serve     serve.Command     `cmd:"" help:"Start the web UI."`
+	Recommend     recommend.Command     `aliases:"r" cmd:""                                                                       help:"Recommend players for a machine."`
+	Scout         scout.Command         `aliases:"s" cmd:""                                                                       help:"Scout a team's strengths and weaknesses."`
+	Matchup       matchup.Command       `aliases:"m" cmd:""                                                                       help:"Compare two teams head-to-head at a venue."`
+	Next          next.Command          `cmd:""      help:"Show a team's next match and how it matches up."`
+	Player        player.Command        `aliases:"p" cmd:""                                                                       help:"Show a player's stats across machines."`
+	Card          card.Command          `cmd:""      help:"Save a shareable player card image."`
+	Goal          goal.Command          `cmd:""      help:"Set and track players' goals."`
+	Week          week.Command          `cmd:""      help:"Summarize every match in a week."`
+	Recap         recap.Command         `cmd:""      help:"Recap a team's latest match."`
+	Report        report.Command        `cmd:""      help:"Recap a week's results to paste into a team chat."`
+	Digest        digest.Command        `cmd:""      help:"Summarize a team's next match, optionally posting it to a webhook."`
+	Standings     standings.Command     `cmd:""      help:"Show the league table."`
+	Team          team.Command          `cmd:""      help:"Compare a team between seasons."`
+	Doubles       doubles.Command       `cmd:""      help:"Recommend doubles pairings for a team."`
+	Recruit       recruit.Command       `cmd:""      help:"List the machine eras and machines a team most needs players for."`
+	Practice      practice.Command      `cmd:""      help:"Plan practice for a team's upcoming matches."`
+	Attendance    attendance.Command    `cmd:""      help:"Show how often each player on a team's roster has played this season."`
+	RosterChanges rosterchanges.Command `cmd:""      help:"List players who joined or left teams' rosters this season."`
+	Predict       predict.Command       `cmd:""      help:"Predict a team's lineup and machine picks for its next match."`
+	Schedule      schedule.Command      `cmd:""      help:"Export a team's schedule."`
+	Leaderboard   leaderboard.Command   `cmd:""      help:"List the highest scores ever posted on each machine."`
+	Backtest      backtest.Command      `cmd:""      help:"Replay past matches to measure how accurate matchup predictions are."`
+	Simulate      simulate.Command      `cmd:""      help:"Simulate a match between two teams to estimate who'll win."`
+	Games         games.Command         `cmd:""      help:"List individual game results."`
+	Search        search.Command        `cmd:""      help:"Search players, teams, machines, and venues."`
+	Players       players.Command       `cmd:""      help:"List all players."`
+	Teams         teams.Command         `cmd:""      help:"List all teams."`
+	Venue         venue.Command         `cmd:""      help:"Show the machines at a venue, or how they changed each season."`
+	Venues        venues.Command        `cmd:""      help:"List all venues."`
+	Machines      machines.Command      `cmd:""      help:"List all machines."`
+	DB            db.Command            `cmd:""      help:"Database utilities."`
+	Serve         serve.Command         `cmd:""      help:"Start the web UI."`
+	Bot           bot.Command           `cmd:""      help:"Start a Discord bot that answers slash commands."`
+	Init          initialize.Command    `cmd:""      help:"Write a starter config, and optionally a systemd unit for the web UI."`
+	Config        configure.Command     `cmd:""      help:"Set your default team and venue."`
 
 	Cache cache.DB `embed:""`
 }
 
+// Validate requires a team for commands that default to the config file's
+// team, if the config file doesn't set one.
+func (c *cli) Validate(kctx *kong.Context) error {
+	node := kctx.Selected()
+	if node == nil {
+		return nil
+	}
+	values := node.Positional
+	for _, f := range node.Flags {
+		values = append(values, f.Value)
+	}
+	for _, v := range values {
+		if v.Name == "team" && v.HasDefault && v.Target.String() == "" {
+			return config.ErrNoTeam
+		}
+	}
+	return nil
+}
+
 func main() {
+	// The config sets flags' defaults, so it's loaded before parsing. Its
+	// error is reported once there's a parser to report it.
+	cfg, cfgErr := config.Load(config.Path())
+	if cfgErr != nil {
+		cfg = &config.Config{}
+	}
+
 	c := &cli{}
-	ctx := kong.Parse(c,
+	parser := kong.Must(c,
 		kong.Name("mnp"),
 		kong.Description("Monday Night Pinball data tools."),
 		kong.UsageOnError(),
-		kong.Vars{"version": version.Version},
+		kong.Vars{"version": version.Version, "team": cfg.Team},
+		kong.Resolvers(cfg.Resolver()),
 	)
+	parser.FatalIfErrorf(cfgErr)
+
+	args, err := cfg.Expand(os.Args[1:])
+	parser.FatalIfErrorf(err)
+
+	ctx, err := parser.Parse(args)
+	parser.FatalIfErrorf(err)
 
 	defer c.Cache.Close() //nolint:errcheck // Not much we can do about this.
 
@@ -57,7 +142,7 @@ func main() {
 	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 
 	c.Cache.SetLogger(log)
-	ctx.Bind(log, &c.Cache)
+	ctx.Bind(log, &c.Cache, cfg)
 
 	ctx.FatalIfErrorf(ctx.Run())
 }
@@ -7,37 +7,126 @@ import (
 	"math"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/config"
 	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
 	"github.com/negz/mnp/internal/strategy/matchup"
 )
 
 // Command compares two teams head-to-head at a venue.
 type Command struct {
-	Venue string `arg:"" help:"Venue key (e.g., ANC)."`
-	Team1 string `arg:"" help:"First team key (e.g., CRA)."`
-	Team2 string `arg:"" help:"Second team key (e.g., PYC)."`
+	Venue string `arg:"" help:"Venue key (e.g., ANC). Pass only the two teams to use the default venue or their next match's, or only an opponent to use the default team."`
+	Team1 string `arg:"" help:"First team key (e.g., CRA)."                                                                                                                 optional:""`
+	Team2 string `arg:"" help:"Second team key (e.g., PYC)."                                                                                                                optional:""`
+
+	Season  []int  `help:"Only count games from these seasons, separated by commas (e.g., 22,23)."`
+	Home    string `help:"Home team key, to plan each round. Defaults to the home team of the teams' next match."`
+	Compact bool   `help:"Show a narrow table for reading on a phone, without likely players' scores."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the matchup command.
-func (c *Command) Run(d *cache.DB) error {
+func (c *Command) Run(d *cache.DB, cfg *config.Config) error {
 	ctx := context.Background()
+	var popts []output.PrinterOption
+	if c.Compact {
+		popts = append(popts, output.Compact())
+	}
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr, popts...)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	r, err := matchup.Analyze(ctx, store, c.Venue, c.Team1, c.Team2)
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
 	if err != nil {
-		return fmt.Errorf("matchup %s vs %s: %w", c.Team1, c.Team2, err)
+		return err
+	}
+	today := clock.Today(time.Now())
+
+	// With only two arguments, they're the teams. With only one, it's the
+	// opponent of the config file's team.
+	venue, team1, team2 := c.Venue, c.Team1, c.Team2
+	switch {
+	case team1 == "":
+		if cfg.Team == "" {
+			return config.ErrNoTeam
+		}
+		venue, team1, team2 = cfg.Venue, cfg.Team, c.Venue
+	case team2 == "":
+		venue, team1, team2 = cfg.Venue, c.Venue, c.Team1
+	}
+	team1, team2 = strings.ToUpper(team1), strings.ToUpper(team2)
+
+	if venue == "" {
+		m, err := schedule.NextVenue(ctx, store, today, team1, team2)
+		if err != nil {
+			return fmt.Errorf("find %s's next venue: %w", team1, err)
+		}
+		if m == nil {
+			return fmt.Errorf("no venue given, and %s has no upcoming match at a known venue", team1)
+		}
+		venue = m.VenueKey
+		p.Printf("At %s (%s), the venue of %s's next match. Pass a venue to choose another.\n\n", m.Venue, m.VenueKey, team1)
+	}
+
+	opts := []matchup.Option{matchup.WithFormats(formats)}
+	if len(c.Season) > 0 {
+		opts = append(opts, matchup.InSeasons(c.Season...))
+	}
+
+	// The home team decides who picks machines in each round.
+	home := strings.ToUpper(c.Home)
+	if home == "" {
+		next, err := schedule.NextMatch(ctx, store, today, team1, team2)
+		if err != nil {
+			return fmt.Errorf("find %s's next match against %s: %w", team1, team2, err)
+		}
+		if next != nil {
+			home = next.HomeTeamKey
+		}
+	}
+	if home != "" {
+		opts = append(opts, matchup.HomeTeam(home))
+	}
+
+	r, err := matchup.Analyze(ctx, store, venue, team1, team2, opts...)
+	if err != nil {
+		return fmt.Errorf("matchup %s vs %s: %w", team1, team2, err)
 	}
 
 	if len(r.Machines) == 0 {
-		fmt.Printf("No machines found at %s\n", c.Venue)
+		p.Printf("No machines found at %s\n", venue)
 		return nil
 	}
 
+	h, rows := headers(r), machinesToRows(r)
+	if c.Compact {
+		h, rows = compactHeaders(r), compactRows(r)
+	}
+	if err := p.Table(h, rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	printAnalysis(p, r)
+	printRounds(p, r)
+	return nil
+}
+
+func headers(r *matchup.Result) []string {
+	return []string{"Machine", r.Team1 + " P50", r.Team1 + " Likely", r.Team2 + " P50", r.Team2 + " Likely", "Edge"}
+}
+
+func machinesToRows(r *matchup.Result) [][]string {
 	rows := make([][]string, len(r.Machines))
 	for i, m := range r.Machines {
 		rows[i] = []string{
@@ -49,16 +138,27 @@ func (c *Command) Run(d *cache.DB) error {
 			formatEdge(m.Edge, r.Team1, r.Team2, m.Confidence),
 		}
 	}
+	return rows
+}
 
-	if err := output.Table(os.Stdout,
-		[]string{"Machine", r.Team1 + " P50", r.Team1 + " Likely", r.Team2 + " P50", r.Team2 + " Likely", "Edge"},
-		rows,
-	); err != nil {
-		return fmt.Errorf("write table: %w", err)
-	}
+// compactHeaders head each team's P50 with just its key.
+func compactHeaders(r *matchup.Result) []string {
+	return []string{"Machine", r.Team1, r.Team2, "Edge"}
+}
 
-	printAnalysis(r)
-	return nil
+// compactRows are like machinesToRows, but with short machine names and without
+// likely players' scores.
+func compactRows(r *matchup.Result) [][]string {
+	rows := make([][]string, len(r.Machines))
+	for i, m := range r.Machines {
+		rows[i] = []string{
+			output.ShortMachineName(m.MachineName),
+			formatScore(m.Team1P50),
+			formatScore(m.Team2P50),
+			formatEdge(m.Edge, r.Team1, r.Team2, m.Confidence),
+		}
+	}
+	return rows
 }
 
 func formatScore(score float64) string {
@@ -112,22 +212,55 @@ func formatEdge(pct float64, team1, team2 string, conf matchup.Confidence) strin
 	}
 }
 
-func printAnalysis(r *matchup.Result) {
+func printAnalysis(p *output.Printer, r *matchup.Result) {
 	if len(r.Machines) == 0 {
 		return
 	}
 
-	fmt.Println()
-	fmt.Println(confHigh + " high confidence  " + confMedium + " medium  " + confLow + " low (based on likely players' games)")
+	p.Println()
+	p.Println(confHigh + " high confidence  " + confMedium + " medium  " + confLow + " low (based on likely players' games)")
 
 	a := r.Analysis
 	if len(a.Team1Advantages) > 0 {
-		fmt.Printf("%s advantages: %s\n", r.Team1, strings.Join(a.Team1Advantages, ", "))
+		p.Printf("%s advantages: %s\n", r.Team1, strings.Join(a.Team1Advantages, ", "))
 	}
 	if len(a.Team2Advantages) > 0 {
-		fmt.Printf("%s advantages: %s\n", r.Team2, strings.Join(a.Team2Advantages, ", "))
+		p.Printf("%s advantages: %s\n", r.Team2, strings.Join(a.Team2Advantages, ", "))
 	}
 	if len(a.Contested) > 0 {
-		fmt.Printf("Contested: %s\n", strings.Join(a.Contested, ", "))
+		p.Printf("Contested: %s\n", strings.Join(a.Contested, ", "))
+	}
+}
+
+func printRounds(p *output.Printer, r *matchup.Result) {
+	if len(r.Rounds) == 0 {
+		return
+	}
+
+	p.Println()
+	for _, round := range r.Rounds {
+		kind := "singles"
+		if round.Doubles {
+			kind = "doubles"
+		}
+		p.Printf("Round %d (%s, %d points a game): %s\n", round.Number, kind, round.Points, formatRound(round))
+	}
+	p.Println()
+	p.Println("The away team picks machines in odd rounds, and the home team in even. Each")
+	p.Println("round's picker should pick its biggest edge, saving the biggest for rounds")
+	p.Println("worth the most points.")
+}
+
+func formatRound(round matchup.Round) string {
+	if round.Machine == nil {
+		return fmt.Sprintf("%s picks, but has no machines left", round.Picker)
+	}
+	advice := fmt.Sprintf("%s picks %s", round.Picker, round.Machine.MachineName)
+	if len(round.Play) > 0 {
+		advice += ", plays " + strings.Join(round.Play, " and ")
+	}
+	if len(round.Respond) > 0 {
+		advice += fmt.Sprintf("; %s responds with %s", round.Responder, strings.Join(round.Respond, " and "))
 	}
+	return advice
 }
@@ -0,0 +1,88 @@
+// Package next implements the next command.
+package next
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/cmd/mnp/matchup"
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/config"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// Command shows a team's next match and how it matches up, or every match in
+// the next week of the schedule.
+type Command struct {
+	Team string `arg:"" help:"Team key (e.g., CRA). Omit to list every match next week." optional:""`
+}
+
+// Run executes the next command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	today := clock.Today(now)
+
+	if c.Team == "" {
+		sched, err := store.ListSchedule(ctx, today)
+		if err != nil {
+			return fmt.Errorf("load schedule: %w", err)
+		}
+		if len(sched) == 0 {
+			fmt.Println("No upcoming matches")
+			return nil
+		}
+		for _, m := range sched {
+			if m.Week != sched[0].Week {
+				break
+			}
+			printMatch(m, clock.Countdown(now, m.Date))
+		}
+		return nil
+	}
+
+	team := strings.ToUpper(c.Team)
+	m, err := schedule.NextMatch(ctx, store, today, team)
+	if err != nil {
+		return fmt.Errorf("find %s's next match: %w", team, err)
+	}
+	if m == nil {
+		fmt.Printf("No upcoming matches for %s\n", team)
+		return nil
+	}
+
+	printMatch(*m, clock.Countdown(now, m.Date))
+	fmt.Println()
+
+	if m.VenueKey == "" {
+		fmt.Println("No venue set for this match yet.")
+		return nil
+	}
+
+	mc := &matchup.Command{Venue: m.VenueKey, Team1: team, Team2: schedule.Opponent(*m, team)}
+	return mc.Run(d, &config.Config{})
+}
+
+func printMatch(m db.ScheduleMatch, countdown string) {
+	venue := m.Venue
+	if venue == "" {
+		venue = "venue TBD"
+	}
+	date := m.Date
+	if countdown != "" {
+		date += ", " + countdown
+	}
+	fmt.Printf("%s (%s): %s @ %s, %s\n", m.WeekName(), date, m.AwayTeamKey, m.HomeTeamKey, venue)
+}
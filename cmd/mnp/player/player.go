@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/negz/mnp/internal/cache"
@@ -14,22 +15,42 @@ import (
 
 // Command shows an individual player's stats across all machines.
 type Command struct {
-	Name  string `arg:""                                         help:"Player name (e.g., 'Jay Ostby')."`
-	Venue string `help:"Filter to machines at a specific venue." short:"e"`
+	Name    string `arg:""                                                                              help:"Player name (e.g., 'Jay Ostby'). Case doesn't matter."`
+	Venue   string `help:"Filter to machines at a specific venue. Defaults to the config file's venue." short:"e"`
+	Season  []int  `help:"Only count games from these seasons, separated by commas (e.g., 22,23)."`
+	Trends  bool   `help:"Compare recent games on each machine against the games before them."`
+	Trend   bool   `help:"Show P50 on each machine season by season."`
+	Compact bool   `help:"Show narrow tables for reading on a phone."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the player command.
 func (c *Command) Run(d *cache.DB) error {
 	ctx := context.Background()
+	var popts []output.PrinterOption
+	if c.Compact {
+		popts = append(popts, output.Compact())
+	}
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr, popts...)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	var opts []player.Option
+	opts := []player.Option{player.WithBadges(), player.WithPoints(), player.WithSplits()}
 	if c.Venue != "" {
 		opts = append(opts, player.AtVenue(c.Venue))
 	}
+	if c.Trends {
+		opts = append(opts, player.WithTrends(player.DefaultTrendWindow))
+	}
+	if c.Trend {
+		opts = append(opts, player.WithSeasonTrends())
+	}
+	if len(c.Season) > 0 {
+		opts = append(opts, player.InSeasons(c.Season...))
+	}
 
 	r, err := player.Analyze(ctx, store, c.Name, opts...)
 	if err != nil {
@@ -37,49 +58,157 @@ func (c *Command) Run(d *cache.DB) error {
 	}
 
 	if len(r.GlobalStats) == 0 {
-		fmt.Printf("No data for %s\n", r.Name)
+		p.Printf("No data for %s\n", r.Name)
+		if len(r.Suggestions) > 0 {
+			p.Printf("Did you mean %s?\n", strings.Join(r.Suggestions, ", "))
+		}
 		return nil
 	}
 
-	if err := output.Table(os.Stdout, headers(), statsToRows(r.GlobalStats)); err != nil {
+	// Compact tables have short machine names, and the main table abbreviated
+	// headers.
+	name, h := func(n string) string { return n }, headers()
+	if c.Compact {
+		name, h = output.ShortMachineName, compactHeaders()
+	}
+
+	if err := p.Table(h, statsToRows(r.GlobalStats, name)); err != nil {
 		return fmt.Errorf("write table: %w", err)
 	}
 
-	printFooter(r)
+	printFooter(p, r)
+
+	if len(r.Trends) > 0 {
+		p.Println()
+		p.Printf("Last %d games on each machine vs the %d before:\n\n", r.Trends[0].Games, r.Trends[0].Games)
+		if err := p.Table(trendHeaders(), trendsToRows(r.Trends, name)); err != nil {
+			return fmt.Errorf("write table: %w", err)
+		}
+	}
+
+	if len(r.Seasons) > 0 {
+		p.Println()
+		p.Printf("P50 on each machine by season:\n\n")
+		seasons := seasonNumbers(r.Seasons)
+		if err := p.Table(seasonHeaders(seasons), seasonsToRows(r.Seasons, seasons, name)); err != nil {
+			return fmt.Errorf("write table: %w", err)
+		}
+	}
 	return nil
 }
 
-func printFooter(r *player.Result) {
-	fmt.Println()
+func printFooter(p *output.Printer, r *player.Result) {
+	p.Println()
 
 	if r.IPR > 0 {
-		fmt.Printf("IPR:  %d\n", r.IPR)
+		p.Printf("IPR:  %d\n", r.IPR)
 	}
 	if r.Team != nil {
-		fmt.Printf("Team: %s (%s)\n", r.Team.Name, r.Team.Key)
+		p.Printf("Team: %s (%s)\n", r.Team.Name, r.Team.Key)
 	}
 
+	if r.Points.Possible > 0 {
+		p.Printf("Points:    %s of possible (%s of %s)\n", output.FormatEfficiency(r.Points.Won, r.Points.Possible),
+			output.FormatPoints(r.Points.Won), output.FormatPoints(r.Points.Possible))
+	}
 	if len(r.Analysis.Strongest) > 0 {
-		fmt.Printf("Strongest: %s\n", strings.Join(r.Analysis.Strongest, ", "))
+		p.Printf("Strongest: %s\n", strings.Join(r.Analysis.Strongest, ", "))
 	}
 	if len(r.Analysis.Weakest) > 0 {
-		fmt.Printf("Weakest:   %s\n", strings.Join(r.Analysis.Weakest, ", "))
+		p.Printf("Weakest:   %s\n", strings.Join(r.Analysis.Weakest, ", "))
+	}
+	if len(r.Badges) > 0 {
+		names := make([]string, len(r.Badges))
+		for i, b := range r.Badges {
+			names[i] = b.Name
+		}
+		p.Printf("Badges:    %s\n", strings.Join(names, ", "))
 	}
 }
 
 func headers() []string {
-	return []string{"Machine", "Games", "P50 (vs Avg)", "P90"}
+	return []string{"Machine", "Games", "P50 (vs Avg)", "P50 Pctl", "P90", "Home / Away P50", "Points Won"}
 }
 
-func statsToRows(stats []player.MachineStats) [][]string {
+func compactHeaders() []string {
+	return []string{"Machine", "G", "P50", "Pctl", "P90", "H/A", "Pts"}
+}
+
+// statsToRows returns a row for each machine. Like trendsToRows and
+// seasonsToRows, it formats machine names with the supplied function.
+func statsToRows(stats []player.MachineStats, name func(string) string) [][]string {
 	rows := make([][]string, len(stats))
 	for i, s := range stats {
 		rows[i] = []string{
-			s.MachineName,
+			name(s.MachineName),
 			fmt.Sprintf("%d", s.Games),
 			output.FormatP50(s.P50Score, s.LeagueP50),
+			output.FormatPercentRank(s.PercentRank),
 			output.FormatScore(s.P90Score),
+			output.FormatSplit(s.Split.HomeP50, s.Split.AwayP50),
+			output.FormatEfficiency(s.Points.Won, s.Points.Possible),
+		}
+	}
+	return rows
+}
+
+func trendHeaders() []string {
+	return []string{"Machine", "Before", "Recent", "Change", "Rank"}
+}
+
+func trendsToRows(trends []player.Trend, name func(string) string) [][]string {
+	rows := make([][]string, len(trends))
+	for i, t := range trends {
+		rows[i] = []string{
+			name(t.MachineName),
+			output.FormatScore(t.Previous.P50Score),
+			output.FormatScore(t.Recent.P50Score),
+			output.FormatChange(t.Previous.P50Score, t.Recent.P50Score),
+			fmt.Sprintf("%.0f%% -> %.0f%% (%+.0f)", t.Previous.PercentRank*100, t.Recent.PercentRank*100, t.RankChange()),
+		}
+	}
+	return rows
+}
+
+// seasonNumbers returns every season any trend covers, oldest first.
+func seasonNumbers(trends []player.SeasonTrend) []int {
+	var seasons []int
+	for _, t := range trends {
+		for _, s := range t.Seasons {
+			if !slices.Contains(seasons, s.Season) {
+				seasons = append(seasons, s.Season)
+			}
+		}
+	}
+	slices.Sort(seasons)
+	return seasons
+}
+
+func seasonHeaders(seasons []int) []string {
+	h := []string{"Machine"}
+	for _, s := range seasons {
+		h = append(h, fmt.Sprintf("S%d", s))
+	}
+	return append(h, "Change")
+}
+
+func seasonsToRows(trends []player.SeasonTrend, seasons []int, name func(string) string) [][]string {
+	rows := make([][]string, len(trends))
+	for i, t := range trends {
+		p50 := make(map[int]float64, len(t.Seasons))
+		for _, s := range t.Seasons {
+			p50[s.Season] = s.P50Score
+		}
+		row := []string{name(t.MachineName)}
+		for _, s := range seasons {
+			if v, ok := p50[s]; ok {
+				row = append(row, output.FormatScore(v))
+				continue
+			}
+			row = append(row, "-")
 		}
+		first, last := t.Seasons[0].P50Score, t.Seasons[len(t.Seasons)-1].P50Score
+		rows[i] = append(row, output.FormatChange(first, last))
 	}
 	return rows
 }
@@ -0,0 +1,76 @@
+// Package practice implements the practice command.
+package practice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/practice"
+)
+
+func headers() []string {
+	return []string{"Machine", "Matches", "Edge", "Likely Games", "Why"}
+}
+
+// Command lists machines for a team to practice before its upcoming matches.
+type Command struct {
+	Team  string `default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Weeks int    `default:"3"       help:"Weeks of upcoming matches to plan for."`
+}
+
+// Run executes the practice command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+
+	team := strings.ToUpper(c.Team)
+	r, err := practice.Analyze(ctx, store, team, clock.Today(time.Now()), practice.ForWeeks(c.Weeks))
+	if err != nil {
+		return fmt.Errorf("plan practice for %s: %w", c.Team, err)
+	}
+
+	if len(r.Matches) == 0 {
+		fmt.Printf("No upcoming matches for %s\n", team)
+		return nil
+	}
+
+	for _, m := range r.Matches {
+		fmt.Printf("Week %d: %s @ %s (%s)\n", m.Week, m.AwayTeamKey, m.HomeTeamKey, m.Venue)
+	}
+	fmt.Println()
+
+	if len(r.Machines) == 0 {
+		fmt.Println("Nothing to practice: the team leads comfortably on every machine.")
+		return nil
+	}
+
+	return output.Table(os.Stdout, headers(), machinesToRows(r.Machines))
+}
+
+func machinesToRows(machines []practice.Machine) [][]string {
+	rows := make([][]string, len(machines))
+	for i, m := range machines {
+		rows[i] = []string{
+			m.MachineName,
+			fmt.Sprintf("%d", len(m.Matches)),
+			fmt.Sprintf("%+.0f%%", m.Edge),
+			fmt.Sprintf("%.1f", m.LikelyGames),
+			strings.Join(m.Reasons(), ", "),
+		}
+	}
+	return rows
+}
@@ -0,0 +1,135 @@
+// Package predict implements the predict command.
+package predict
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/predict"
+)
+
+// maxPicks is how many of the machines a team has picked in a round are shown.
+const maxPicks = 4
+
+func headers() []string {
+	return []string{"Player", "Matches", "Likely"}
+}
+
+// Command predicts who a team will field in its next match, and which
+// machines it will pick.
+type Command struct {
+	Team    string `arg:""                                                                                                                        default:"${team}"                                         help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Venue   string `help:"Only predict picks of machines at a venue. Defaults to the config file's venue, or the venue of the team's next match." short:"e"`
+	Matches int    `default:"6"                                                                                                                   help:"Predict the lineup from the team's last N matches."`
+	Season  []int  `help:"Only count picks from these seasons, separated by commas (e.g., 22,23)."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the predict command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+
+	team := strings.ToUpper(c.Team)
+	opts := []predict.Option{predict.WithFormats(formats), predict.FromMatches(c.Matches)}
+	if len(c.Season) > 0 {
+		opts = append(opts, predict.InSeasons(c.Season...))
+	}
+
+	// The team's next match decides which rounds it picks machines in.
+	next, err := schedule.NextMatch(ctx, store, clock.Today(time.Now()), team)
+	if err != nil {
+		return fmt.Errorf("find %s's next match: %w", team, err)
+	}
+	venue := c.Venue
+	if next != nil {
+		opts = append(opts, predict.AtHome(next.HomeTeamKey == team))
+		if venue == "" && next.VenueKey != "" {
+			venue = next.VenueKey
+			p.Printf("At %s (%s), the venue of %s's week %d match against %s.\n\n", next.Venue, next.VenueKey, team, next.Week, schedule.Opponent(*next, team))
+		}
+	}
+	if venue != "" {
+		opts = append(opts, predict.AtVenue(strings.ToUpper(venue)))
+	}
+
+	r, err := predict.Analyze(ctx, store, team, opts...)
+	if err != nil {
+		return fmt.Errorf("predict %s: %w", team, err)
+	}
+
+	if r.Matches == 0 {
+		p.Printf("No matches played by %s\n", team)
+		return nil
+	}
+
+	if err := p.Table(headers(), playersToRows(r)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Printf("%s usually fields %d players. Likely players are the %s season %d roster\n", team, r.Lineup, team, r.Season)
+	p.Printf("players who played the most of its last %d matches.\n", r.Matches)
+
+	p.Println()
+	for _, round := range r.Rounds {
+		kind := "singles"
+		if round.Doubles {
+			kind = "doubles"
+		}
+		p.Printf("Round %d (%s): %s\n", round.Number, kind, formatPicks(round.Picks))
+	}
+	p.Println()
+	if next == nil {
+		p.Println("No upcoming match found, so every round is shown. Teams pick machines in")
+		p.Println("alternate rounds: the away team in odd rounds, and the home team in even.")
+	} else {
+		p.Printf("Machines %s picked in these rounds before, and how many matches it picked\n", team)
+		p.Println("each in.")
+	}
+	return nil
+}
+
+func playersToRows(r *predict.Result) [][]string {
+	rows := make([][]string, len(r.Players))
+	for i, pl := range r.Players {
+		likely := ""
+		if pl.Likely {
+			likely = "Yes"
+		}
+		rows[i] = []string{pl.Name, fmt.Sprintf("%d of %d", pl.Matches, r.Matches), likely}
+	}
+	return rows
+}
+
+func formatPicks(picks []predict.Pick) string {
+	if len(picks) == 0 {
+		return "No picks yet"
+	}
+	parts := make([]string, 0, maxPicks)
+	for _, pk := range picks[:min(maxPicks, len(picks))] {
+		parts = append(parts, fmt.Sprintf("%s (%d)", pk.MachineName, pk.Matches))
+	}
+	return strings.Join(parts, ", ")
+}
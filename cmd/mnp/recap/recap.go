@@ -0,0 +1,101 @@
+// Package recap implements the recap command.
+package recap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/recap"
+)
+
+// Command recaps a team's most recently completed match.
+type Command struct {
+	Team  string `arg:""                                                                      default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Match string `help:"Match key to recap (e.g., mnp-23-1-CRA-PYC). Defaults to the latest."`
+}
+
+// Run executes the recap command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	var opts []recap.Option
+	if c.Match != "" {
+		opts = append(opts, recap.ForMatch(c.Match))
+	}
+
+	r, err := recap.Analyze(ctx, store, strings.ToUpper(c.Team), opts...)
+	if err != nil {
+		return fmt.Errorf("recap %s: %w", c.Team, err)
+	}
+
+	if r.Match == nil {
+		fmt.Printf("No completed matches for %s\n", c.Team)
+		return nil
+	}
+
+	m := r.Match
+	fmt.Printf("Week %d: %s %s @ %s %s\n\n", m.Week,
+		m.AwayTeamKey, output.FormatPoints(r.AwayPoints),
+		m.HomeTeamKey, output.FormatPoints(r.HomePoints))
+
+	if err := output.Table(os.Stdout, headers(m.AwayTeamKey, m.HomeTeamKey), gamesToRows(r.Games)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	printFooter(r)
+	return nil
+}
+
+func headers(away, home string) []string {
+	return []string{"Round", "Machine", away, home}
+}
+
+func gamesToRows(games []recap.Game) [][]string {
+	rows := make([][]string, len(games))
+	for i, g := range games {
+		rows[i] = []string{
+			fmt.Sprintf("%d", g.Round),
+			g.MachineName,
+			fmt.Sprintf("%s (%s)", strings.Join(g.Away.Players, ", "), output.FormatPoints(g.Away.Points)),
+			fmt.Sprintf("%s (%s)", strings.Join(g.Home.Players, ", "), output.FormatPoints(g.Home.Points)),
+		}
+	}
+	return rows
+}
+
+func printFooter(r *recap.Result) {
+	fmt.Println()
+
+	if len(r.MVPs) > 0 {
+		names := make([]string, len(r.MVPs))
+		for i, p := range r.MVPs {
+			names[i] = fmt.Sprintf("%s (%s)", p.Name, p.TeamKey)
+		}
+		fmt.Printf("MVP:   %s, %s points\n", strings.Join(names, ", "), output.FormatPoints(r.MVPs[0].Points))
+	}
+	if u := r.Upset; u != nil {
+		fmt.Printf("Upset: %s won %s despite a combined IPR %d lower\n", u.Winner, u.Game.MachineName, u.IPRGap)
+	}
+	for _, b := range r.Badges {
+		fmt.Printf("Badge: %s earned %s (%s)\n", b.PlayerName, b.Name, b.Description)
+	}
+	if len(r.Predictions) > 0 {
+		fmt.Printf("Calls: favorites won %d of %d predicted machines", len(r.Right()), len(r.Predictions))
+		if wrong := r.Wrong(); len(wrong) > 0 {
+			names := make([]string, len(wrong))
+			for i, p := range wrong {
+				names[i] = fmt.Sprintf("%s (%s by %.0f%%)", p.MachineName, p.Favorite, p.Edge)
+			}
+			fmt.Printf(", and lost %s", strings.Join(names, ", "))
+		}
+		fmt.Println()
+	}
+}
@@ -5,84 +5,145 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/config"
 	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
 	"github.com/negz/mnp/internal/strategy/recommend"
 )
 
 // Command recommends which players should play a specific machine.
 type Command struct {
-	Team     string `arg:""                                     help:"Team key (e.g., CRA)."`
-	Machine  string `arg:""                                     help:"Machine key (e.g., TZ)."`
-	Venue    string `help:"Filter to venue-specific stats."     short:"e"`
-	Opponent string `help:"Compare against opponent's players." name:"vs"`
+	Team        string `arg:""                                                                                                             help:"Team key (e.g., CRA). Pass only the machine to use the config file's team."`
+	Machine     string `arg:""                                                                                                             help:"Machine key (e.g., TZ)."                                                    optional:""`
+	Venue       string `help:"Filter to venue-specific stats. Defaults to the config file's venue, or the venue of the team's next match." short:"e"`
+	AllVenues   bool   `help:"Show stats across every venue, rather than the next match's venue."`
+	Season      []int  `help:"Only count games from these seasons, separated by commas (e.g., 22,23)."`
+	Opponent    string `help:"Compare against opponent's players."                                                                         name:"vs"`
+	Compact     bool   `help:"Show narrow tables for reading on a phone."`
+	Percentiles []int  `help:"Percentiles to show instead of P50 and P90, separated by commas (e.g., 25,50,75,90)."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the recommend command.
-func (c *Command) Run(d *cache.DB) error {
+func (c *Command) Run(d *cache.DB, cfg *config.Config) error {
+	for _, pct := range c.Percentiles {
+		if pct < 1 || pct > 100 {
+			return fmt.Errorf("--percentiles must be between 1 and 100, not %d", pct)
+		}
+	}
+
+	// With only one argument, it's the machine.
+	team, machine := c.Team, c.Machine
+	if machine == "" {
+		if cfg.Team == "" {
+			return config.ErrNoTeam
+		}
+		team, machine = cfg.Team, c.Team
+	}
+
 	ctx := context.Background()
+	var popts []output.PrinterOption
+	if c.Compact {
+		popts = append(popts, output.Compact())
+	}
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr, popts...)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
+	// --all-venues overrides a venue set in the config file.
+	venue := c.Venue
+	if c.AllVenues {
+		venue = ""
+	}
+	if venue == "" && !c.AllVenues {
+		clock, err := schedule.NewClock(schedule.DefaultTimezone)
+		if err != nil {
+			return err
+		}
+		m, err := schedule.NextVenue(ctx, store, clock.Today(time.Now()), strings.ToUpper(team), strings.ToUpper(c.Opponent))
+		if err != nil {
+			return fmt.Errorf("find %s's next venue: %w", team, err)
+		}
+		if m != nil {
+			venue = m.VenueKey
+			p.Printf("At %s (%s), the venue of %s's next match. Use --all-venues for every venue.\n\n", m.Venue, m.VenueKey, strings.ToUpper(team))
+		}
+	}
+
 	var opts []recommend.Option
-	if c.Venue != "" {
-		opts = append(opts, recommend.AtVenue(c.Venue))
+	if venue != "" {
+		opts = append(opts, recommend.AtVenue(venue))
 	}
 	if c.Opponent != "" {
 		opts = append(opts, recommend.VsOpponent(c.Opponent))
 	}
+	if len(c.Season) > 0 {
+		opts = append(opts, recommend.InSeasons(c.Season...))
+	}
+	if len(c.Percentiles) > 0 {
+		opts = append(opts, recommend.WithPercentiles(c.Percentiles...))
+	}
 
-	r, err := recommend.Analyze(ctx, store, c.Team, c.Machine, opts...)
+	r, err := recommend.Analyze(ctx, store, team, machine, opts...)
 	if err != nil {
-		return fmt.Errorf("recommend %s on %s: %w", c.Team, c.Machine, err)
+		return fmt.Errorf("recommend %s on %s: %w", team, machine, err)
 	}
 
-	if r.Opponent != "" {
-		return printOpponent(r)
+	t := statsTable{compact: c.Compact, percentiles: r.Percentiles}
+	switch {
+	case r.Opponent != "":
+		err = printOpponent(p, t, r)
+	case r.Venue != "":
+		err = printVenue(p, t, r)
+	default:
+		return printBasic(p, t, r)
 	}
-
-	if r.Venue != "" {
-		return printVenue(r)
+	if err != nil {
+		return err
 	}
 
-	return printBasic(r)
+	return printExternal(p, r)
 }
 
-func printBasic(r *recommend.Result) error {
+func printBasic(p *output.Printer, t statsTable, r *recommend.Result) error {
 	if len(r.GlobalStats) == 0 {
-		fmt.Printf("No data for %s on %s\n", r.Team, r.Machine)
+		p.Printf("No data for %s on %s\n", r.Team, r.Machine)
 		return nil
 	}
 
-	return output.Table(os.Stdout, headers(), statsToRows(r.GlobalStats))
+	return t.write(p, r.GlobalStats)
 }
 
-func printVenue(r *recommend.Result) error {
+func printVenue(p *output.Printer, t statsTable, r *recommend.Result) error {
 	if len(r.VenueStats) == 0 && len(r.GlobalStats) == 0 {
-		fmt.Printf("No data for %s on %s\n", r.Team, r.Machine)
+		p.Printf("No data for %s on %s\n", r.Team, r.Machine)
 		return nil
 	}
 
 	if len(r.VenueStats) > 0 {
-		fmt.Printf("At %s:\n\n", r.Venue)
-		if err := output.Table(os.Stdout, headers(), statsToRows(r.VenueStats)); err != nil {
+		p.Printf("At %s:\n\n", r.Venue)
+		if err := t.write(p, r.VenueStats); err != nil {
 			return fmt.Errorf("write table: %w", err)
 		}
-		fmt.Println()
+		p.Println()
 	}
 
-	fmt.Println("Global (for context):")
-	fmt.Println()
-	if err := output.Table(os.Stdout, headers(), statsToRows(r.GlobalStats)); err != nil {
+	p.Println("Global (for context):")
+	p.Println()
+	if err := t.write(p, r.GlobalStats); err != nil {
 		return fmt.Errorf("write table: %w", err)
 	}
 
 	for _, s := range r.GlobalStats {
 		if s.NoVenueData {
-			fmt.Printf("\n*No %s data\n", r.Venue)
+			p.Printf("\n*No %s data\n", r.Venue)
 			break
 		}
 	}
@@ -90,37 +151,55 @@ func printVenue(r *recommend.Result) error {
 	return nil
 }
 
-func printOpponent(r *recommend.Result) error {
+func printOpponent(p *output.Printer, t statsTable, r *recommend.Result) error {
 	if len(r.GlobalStats) == 0 && len(r.OpponentStats) == 0 {
-		fmt.Printf("No data for %s or %s on %s\n", r.Team, r.Opponent, r.Machine)
+		p.Printf("No data for %s or %s on %s\n", r.Team, r.Opponent, r.Machine)
 		return nil
 	}
 
-	fmt.Printf("%s options:\n\n", r.Team)
+	p.Printf("%s options:\n\n", r.Team)
 	if len(r.GlobalStats) > 0 {
-		if err := output.Table(os.Stdout, headers(), statsToRows(r.GlobalStats)); err != nil {
+		if err := t.write(p, r.GlobalStats); err != nil {
 			return fmt.Errorf("write table: %w", err)
 		}
 	} else {
-		fmt.Println("(no data)")
+		p.Println("(no data)")
 	}
 
-	fmt.Printf("\n%s likely players:\n\n", r.Opponent)
+	p.Printf("\n%s likely players:\n\n", r.Opponent)
 	if len(r.OpponentStats) > 0 {
-		if err := output.Table(os.Stdout, headers(), statsToRows(r.OpponentStats)); err != nil {
+		if err := t.write(p, r.OpponentStats); err != nil {
 			return fmt.Errorf("write table: %w", err)
 		}
 	} else {
-		fmt.Println("(no data)")
+		p.Println("(no data)")
 	}
 
 	if a := r.Assessment; a != nil {
-		fmt.Printf("\nAssessment: %s\n", formatAssessment(r))
+		p.Printf("\nAssessment: %s\n", formatAssessment(r))
 	}
 
 	return nil
 }
 
+func printExternal(p *output.Printer, r *recommend.Result) error {
+	if len(r.ExternalStats) == 0 {
+		return nil
+	}
+
+	p.Printf("\nOther leagues at %s (not MNP results):\n\n", r.Venue)
+	rows := make([][]string, len(r.ExternalStats))
+	for i, s := range r.ExternalStats {
+		rows[i] = []string{
+			s.Source,
+			fmt.Sprintf("%d", s.Games),
+			output.FormatScore(s.P50Score),
+			output.FormatScore(s.P90Score),
+		}
+	}
+	return p.Table([]string{"Source", "Games", "P50", "P90"}, rows)
+}
+
 func formatAssessment(r *recommend.Result) string {
 	a := r.Assessment
 	switch a.Verdict {
@@ -137,14 +216,32 @@ func formatAssessment(r *recommend.Result) string {
 	return ""
 }
 
+// A statsTable writes tables of players' stats on the machine.
+type statsTable struct {
+	compact     bool  // Abbreviate headers and players' names.
+	percentiles []int // Show these percentiles instead of P50 and P90.
+}
+
+func (t statsTable) write(p *output.Printer, stats []recommend.PlayerStats) error {
+	if len(t.percentiles) > 0 {
+		return t.writePercentiles(p, stats)
+	}
+	if t.compact {
+		return p.Table([]string{"Player", "G", "P50", "P90", "IPR"}, statsToRows(stats, output.ShortPlayerName))
+	}
+	return p.Table(headers(), statsToRows(stats, func(name string) string { return name }))
+}
+
 func headers() []string {
 	return []string{"Player", "Games", "P50 (vs Avg)", "P90", "IPR"}
 }
 
-func statsToRows(stats []recommend.PlayerStats) [][]string {
+// statsToRows returns a row for each player, with their name formatted by the
+// supplied function.
+func statsToRows(stats []recommend.PlayerStats, format func(string) string) [][]string {
 	rows := make([][]string, len(stats))
 	for i, s := range stats {
-		name := s.Name
+		name := format(s.Name)
 		if s.NoVenueData {
 			name += "*"
 		}
@@ -158,3 +255,36 @@ func statsToRows(stats []recommend.PlayerStats) [][]string {
 	}
 	return rows
 }
+
+// writePercentiles writes a table of each player's scores at the table's
+// percentiles.
+func (t statsTable) writePercentiles(p *output.Printer, stats []recommend.PlayerStats) error {
+	name, games := func(name string) string { return name }, "Games"
+	if t.compact {
+		name, games = output.ShortPlayerName, "G"
+	}
+
+	h := []string{"Player", games}
+	for _, pct := range t.percentiles {
+		h = append(h, fmt.Sprintf("P%d", pct))
+	}
+	h = append(h, "IPR")
+
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		n := name(s.Name)
+		if s.NoVenueData {
+			n += "*"
+		}
+		row := []string{n, fmt.Sprintf("%d", s.Games)}
+		for j := range t.percentiles {
+			score := "-"
+			if j < len(s.Percentiles) {
+				score = output.FormatScore(s.Percentiles[j])
+			}
+			row = append(row, score)
+		}
+		rows[i] = append(row, output.FormatIPR(s.IPR))
+	}
+	return p.Table(h, rows)
+}
@@ -0,0 +1,108 @@
+// Package recruit implements the recruit command.
+package recruit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/era"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/recruit"
+)
+
+func headers() []string {
+	return []string{"Machine", "Era", "Visits", "Games", "P50 (vs Avg)"}
+}
+
+func eraHeaders() []string {
+	return []string{"Era", "Machines", "Share of Need"}
+}
+
+// Command lists the eras of machine, and machines, a team most needs stronger
+// players on.
+type Command struct {
+	Team string `arg:"" default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+}
+
+// Run executes the recruit command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	team := strings.ToUpper(c.Team)
+	r, err := recruit.Analyze(ctx, store, team)
+	if err != nil {
+		return fmt.Errorf("recruit for %s: %w", c.Team, err)
+	}
+
+	if r.Matches == 0 {
+		fmt.Printf("No scheduled matches for %s\n", team)
+		return nil
+	}
+	if len(r.Machines) == 0 {
+		fmt.Printf("%s is at or above the league average on every machine on its schedule\n", team)
+		return nil
+	}
+
+	if len(r.Eras) > 0 {
+		if err := output.Table(os.Stdout, eraHeaders(), erasToRows(r.Eras)); err != nil {
+			return fmt.Errorf("write table: %w", err)
+		}
+		fmt.Println()
+	}
+
+	if err := output.Table(os.Stdout, headers(), machinesToRows(r.Machines)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Machines at venues on %s's schedule this season (%d matches) that it plays\n", team, r.Matches)
+	fmt.Println("below the league average or has barely played, greatest need first. Eras")
+	fmt.Println("(EM, SS, DMD, LCD) come from machines' years; run mnp db sync-machines if")
+	fmt.Println("they're missing.")
+	return nil
+}
+
+func erasToRows(eras []recruit.EraNeed) [][]string {
+	total := 0.0
+	for _, e := range eras {
+		total += e.Need
+	}
+	rows := make([][]string, len(eras))
+	for i, e := range eras {
+		rows[i] = []string{
+			string(e.Era),
+			fmt.Sprintf("%d", e.Machines),
+			fmt.Sprintf("%.0f%%", e.Need/total*100),
+		}
+	}
+	return rows
+}
+
+func machinesToRows(machines []recruit.Machine) [][]string {
+	rows := make([][]string, len(machines))
+	for i, m := range machines {
+		p50 := "-"
+		if m.Played() {
+			p50 = output.FormatP50(m.P50Score, m.LeagueP50)
+		}
+		e := string(m.Era)
+		if m.Era == era.Unknown {
+			e = "-"
+		}
+		rows[i] = []string{
+			m.MachineName,
+			e,
+			fmt.Sprintf("%d", m.Visits),
+			fmt.Sprintf("%d", m.Games),
+			p50,
+		}
+	}
+	return rows
+}
@@ -0,0 +1,50 @@
+// Package report implements the report command.
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/strategy/report"
+)
+
+// Command recaps a week's results, formatted to paste into a team chat.
+type Command struct {
+	Week   int    `help:"Week of the current season to recap. Defaults to the latest week with results."`
+	Team   string `help:"Only recap this team's match (e.g., CRA). Standings still list every team."`
+	Format string `default:"markdown"                                                                    enum:"markdown,html" help:"Write the recap as Markdown or HTML."`
+}
+
+// Run executes the report command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	var opts []report.Option
+	if c.Week != 0 {
+		opts = append(opts, report.InWeek(c.Week))
+	}
+	if c.Team != "" {
+		opts = append(opts, report.ForTeam(strings.ToUpper(c.Team)))
+	}
+
+	r, err := report.Analyze(ctx, store, opts...)
+	if err != nil {
+		return fmt.Errorf("recap week: %w", err)
+	}
+	if c.Week != 0 && len(r.Matches) == 0 {
+		return fmt.Errorf("no matches in week %d", c.Week)
+	}
+
+	if c.Format == report.FormatHTML {
+		fmt.Print(report.HTML(r))
+		return nil
+	}
+	fmt.Print(report.Markdown(r))
+	return nil
+}
@@ -0,0 +1,73 @@
+// Package rosterchanges implements the roster-changes command.
+package rosterchanges
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// Command lists the players who joined or left teams' rosters this season.
+type Command struct {
+	Team string `arg:"" help:"Team key (e.g., CRA). Omit to list every team's changes." optional:""`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the roster-changes command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+
+	team := strings.ToUpper(c.Team)
+	events, err := store.ListRosterEvents(ctx, team)
+	if err != nil {
+		return fmt.Errorf("list roster changes: %w", err)
+	}
+	if len(events) == 0 {
+		if team == "" {
+			p.Println("No roster changes this season")
+			return nil
+		}
+		p.Printf("No roster changes for %s this season\n", team)
+		return nil
+	}
+
+	rows := make([][]string, len(events))
+	for i, e := range events {
+		rows[i] = []string{e.SeenAt.In(clock.Location).Format("2006-01-02"), e.TeamKey, e.PlayerName, formatChange(e.Change)}
+	}
+	if err := p.Table([]string{"Seen", "Team", "Player", "Change"}, rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Println("Changes are noticed when a sync finds a roster that differs from the last")
+	p.Println("sync's, so each is dated by the sync that saw it.")
+	return nil
+}
+
+func formatChange(c db.RosterChange) string {
+	switch c {
+	case db.RosterJoined:
+		return "Joined"
+	case db.RosterLeft:
+		return "Left"
+	}
+	return string(c)
+}
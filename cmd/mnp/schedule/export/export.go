@@ -0,0 +1,77 @@
+// Package export implements the schedule export command.
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/ical"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// Command saves a team's schedule as an iCalendar file.
+type Command struct {
+	Team       string        `arg:""                                                          help:"Team key (e.g., CRA)."`
+	Output     string        `help:"File to write. Defaults to the team's key, e.g. CRA.ics." short:"o"                                                                type:"path"`
+	Arrive     time.Duration `default:"30m"                                                   help:"How long before matches start to arrive. Events start this early."`
+	Timezone   string        `default:"America/Los_Angeles"                                   help:"League timezone. Match dates are in this timezone."`
+	MatchStart time.Duration `default:"20h"                                                   help:"When matches start, as a duration after midnight."`
+}
+
+// Run executes the schedule export command.
+func (c *Command) Run(d *cache.DB) error {
+	if c.Arrive < 0 {
+		return fmt.Errorf("--arrive must not be negative")
+	}
+
+	clock, err := schedule.NewClock(c.Timezone)
+	if err != nil {
+		return err
+	}
+	clock.Start = c.MatchStart
+
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	sched, err := store.ListSchedule(ctx, "")
+	if err != nil {
+		return fmt.Errorf("load schedule: %w", err)
+	}
+
+	team := strings.ToUpper(c.Team)
+	cal, err := clock.Calendar(sched, team, c.Arrive, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(cal.Events) == 0 {
+		fmt.Printf("No scheduled matches for %s\n", team)
+		return nil
+	}
+
+	path := c.Output
+	if path == "" {
+		path = team + ".ics"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := ical.Write(f, cal); err != nil {
+		f.Close() //nolint:errcheck // Already returning error.
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	fmt.Printf("Saved %s's %d matches to %s.\n", team, len(cal.Events), path)
+	return nil
+}
@@ -0,0 +1,11 @@
+// Package schedule implements the schedule command group.
+package schedule
+
+import (
+	"github.com/negz/mnp/cmd/mnp/schedule/export"
+)
+
+// Command groups schedule subcommands.
+type Command struct {
+	Export export.Command `cmd:"" help:"Save a team's matches this season as an iCalendar file."`
+}
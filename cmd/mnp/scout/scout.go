@@ -2,91 +2,385 @@
 package scout
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
 	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
 	"github.com/negz/mnp/internal/strategy/scout"
+	"github.com/negz/mnp/internal/strategy/seasons"
+	"github.com/negz/mnp/internal/strategy/streaks"
 )
 
 func headers() []string {
-	return []string{"Machine", "Games", "P50 (vs Avg)", "P90", "Likely Players"}
+	return []string{"Machine", "Games", "P50 (vs Avg)", "P50 Pctl", "P90", "Home / Away P50", "Points Won", "Likely Players"}
+}
+
+func compactHeaders() []string {
+	return []string{"Machine", "G", "P50", "Pctl", "P90", "H/A", "Pts"}
+}
+
+func seasonHeaders(r *seasons.Result) []string {
+	from, to := fmt.Sprintf("S%d", r.From.Number), fmt.Sprintf("S%d", r.To.Number)
+	return []string{"Machine", from + " P50", to + " P50", "Change", "Roster Change"}
+}
+
+func pickHeaders() []string {
+	return []string{"Machine", "Picked", "Opponent Picked", "Share of Picks"}
 }
 
 // Command scouts a team's strengths and weaknesses across machines.
 type Command struct {
-	Team  string `arg:""                                         help:"Team key (e.g., CRA)."`
-	Venue string `help:"Filter to machines at a specific venue." short:"e"`
+	Team           string `arg:""                                                                                                                     default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	Venue          string `help:"Filter to machines at a specific venue. Defaults to the config file's venue, or the venue of the team's next match." short:"e"`
+	AllVenues      bool   `help:"Show machines at every venue, rather than the next match's venue."`
+	Blend          bool   `help:"Weight recent seasons' scores over older seasons'."`
+	Season         []int  `help:"Only count games from these seasons, separated by commas (e.g., 22,23)."`
+	Next           bool   `help:"Scout the team's next opponent, at the venue of their match."`
+	Picks          bool   `help:"Show which machines the team picks, rather than how it plays them."`
+	CompareSeasons bool   `help:"Compare the team's P50 on each machine, and its roster, with its previous season's."`
+	Compact        bool   `help:"Show narrow tables for reading on a phone, without likely players."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the scout command.
 func (c *Command) Run(d *cache.DB) error {
 	ctx := context.Background()
+	var popts []output.PrinterOption
+	if c.Compact {
+		popts = append(popts, output.Compact())
+	}
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr, popts...)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	var opts []scout.Option
-	if c.Venue != "" {
-		opts = append(opts, scout.AtVenue(c.Venue))
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	today := clock.Today(time.Now())
+
+	// --all-venues overrides a venue set in the config file.
+	team, venue := strings.ToUpper(c.Team), c.Venue
+	if c.AllVenues {
+		venue = ""
+	}
+	var next *db.ScheduleMatch
+	if c.Next {
+		next, err = schedule.NextMatch(ctx, store, today, team)
+		if err != nil {
+			return fmt.Errorf("find %s's next match: %w", team, err)
+		}
+		if next == nil {
+			p.Printf("No upcoming matches for %s\n", team)
+			return nil
+		}
+		team = schedule.Opponent(*next, team)
+		p.Printf("Scouting %s, %s's week %d opponent.\n\n", team, strings.ToUpper(c.Team), next.Week)
+	}
+
+	if c.CompareSeasons {
+		return printSeasons(ctx, p, store, team, c.Compact)
+	}
+
+	if venue == "" && !c.AllVenues {
+		if next == nil {
+			next, err = schedule.NextVenue(ctx, store, today, team, "")
+			if err != nil {
+				return fmt.Errorf("find %s's next venue: %w", team, err)
+			}
+		}
+		if next != nil && next.VenueKey != "" {
+			venue = next.VenueKey
+			p.Printf("At %s (%s), the venue of %s's next match. Use --all-venues for every venue.\n\n", next.Venue, next.VenueKey, team)
+		}
 	}
 
-	r, err := scout.Analyze(ctx, store, c.Team, opts...)
+	opts := []scout.Option{scout.WithPoints(), scout.WithSplits()}
+	if venue != "" {
+		opts = append(opts, scout.AtVenue(venue))
+	}
+	if c.Blend {
+		opts = append(opts, scout.Blended())
+	}
+	if len(c.Season) > 0 {
+		opts = append(opts, scout.InSeasons(c.Season...))
+	}
+	if c.Picks {
+		opts = append(opts, scout.WithPicks())
+	}
+
+	// The blended store computes stats weighting recent seasons over older
+	// ones, which is what blends them.
+	var ss scout.Store = store
+	if c.Blend {
+		blended, err := d.BlendedStore(ctx)
+		if err != nil {
+			return err
+		}
+		defer blended.Close() //nolint:errcheck // Read-only use.
+		ss = blended
+	}
+
+	r, err := scout.Analyze(ctx, ss, team, opts...)
 	if err != nil {
-		return fmt.Errorf("scout %s: %w", c.Team, err)
+		return fmt.Errorf("scout %s: %w", team, err)
 	}
 
 	if len(r.GlobalStats) == 0 {
-		fmt.Printf("No data for %s\n", c.Team)
+		p.Printf("No data for %s\n", team)
 		return nil
 	}
 
-	if err := output.Table(os.Stdout, headers(), statsToRows(r.GlobalStats)); err != nil {
+	if c.Picks {
+		return printPicks(p, r)
+	}
+
+	h, rows := headers(), statsToRows(r.GlobalStats, r.Blended)
+	if c.Compact {
+		h, rows = compactHeaders(), compactRows(r.GlobalStats, r.Blended)
+	}
+	if err := p.Table(h, rows); err != nil {
 		return fmt.Errorf("write table: %w", err)
 	}
 
-	printAnalysis(r.Analysis)
+	printAnalysis(p, r.Analysis)
+
+	st, err := streaks.Analyze(ctx, store, team)
+	if err != nil {
+		return fmt.Errorf("find %s's streaks: %w", team, err)
+	}
+	printStreaks(p, st)
+
+	p.Println()
+	p.Println("Points won is the share of the match points possible that the roster won on")
+	p.Printf("each machine: %s overall.\n", output.FormatEfficiency(r.Points.Won, r.Points.Possible))
+	p.Println("P50 pctl is the share of all league scores on the machine below the P50.")
+	p.Println("Strongest and weakest machines are ranked by it, since scoring differs so")
+	p.Println("much between machines.")
+	p.Println("Home and away P50 split the roster's scores by whether their team hosted.")
+	if !c.Compact {
+		p.Println("Likely players show P50 and matches played of the team's matches this season.")
+	}
+	if len(st.Hot) > 0 || len(st.Cold) > 0 {
+		p.Printf("Hot and cold players' median percentile rank over their last %d weeks is well\n", st.Weeks)
+		p.Println("above or below their usual median before then.")
+	}
+	if r.Blended {
+		p.Println()
+		p.Println("Blended: P50 and P90 count this season's scores fully, last season's at")
+		p.Println("half weight, and each earlier season at half again.")
+	}
 	return nil
 }
 
-func statsToRows(stats []scout.MachineStats) [][]string {
+func statsToRows(stats []scout.MachineStats, blended bool) [][]string {
 	rows := make([][]string, len(stats))
 	for i, s := range stats {
+		games := fmt.Sprintf("%d", s.Games)
+		if blended {
+			games = fmt.Sprintf("%d (%d this season)", s.Games, s.CurrentGames)
+		}
 		rows[i] = []string{
 			s.MachineName,
-			fmt.Sprintf("%d", s.Games),
+			games,
 			output.FormatP50(s.P50Score, s.LeagueP50),
+			output.FormatPercentRank(s.PercentRank),
 			output.FormatScore(s.P90Score),
+			output.FormatSplit(s.Split.HomeP50, s.Split.AwayP50),
+			output.FormatEfficiency(s.Points.Won, s.Points.Possible),
 			formatLikelyPlayers(s.LikelyPlayers),
 		}
 	}
 	return rows
 }
 
+// compactRows are like statsToRows, but with short machine names and no likely
+// players.
+func compactRows(stats []scout.MachineStats, blended bool) [][]string {
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		games := fmt.Sprintf("%d", s.Games)
+		if blended {
+			games = fmt.Sprintf("%d (%d)", s.Games, s.CurrentGames)
+		}
+		rows[i] = []string{
+			output.ShortMachineName(s.MachineName),
+			games,
+			output.FormatP50(s.P50Score, s.LeagueP50),
+			output.FormatPercentRank(s.PercentRank),
+			output.FormatScore(s.P90Score),
+			output.FormatSplit(s.Split.HomeP50, s.Split.AwayP50),
+			output.FormatEfficiency(s.Points.Won, s.Points.Possible),
+		}
+	}
+	return rows
+}
+
 func formatLikelyPlayers(players []scout.LikelyPlayer) string {
 	parts := make([]string, len(players))
 	for i, p := range players {
-		short := p.Name
-		if first, last, ok := strings.Cut(p.Name, " "); ok {
-			short = first + " " + last[:1]
+		parts[i] = fmt.Sprintf("%s (%s", output.ShortPlayerName(p.Name), output.FormatScore(p.P50Score))
+		if p.Attendance.TeamMatches > 0 {
+			parts[i] += fmt.Sprintf(", %d/%d", p.Attendance.Matches, p.Attendance.TeamMatches)
 		}
-		parts[i] = fmt.Sprintf("%s (%s)", short, output.FormatScore(p.P50Score))
+		parts[i] += ")"
 	}
 	return strings.Join(parts, ", ")
 }
 
-func printAnalysis(a scout.Analysis) {
+func printAnalysis(p *output.Printer, a scout.Analysis) {
 	if len(a.Strongest) == 0 {
 		return
 	}
 
-	fmt.Println()
-	fmt.Printf("Strongest: %s\n", strings.Join(a.Strongest, ", "))
+	p.Println()
+	p.Printf("Strongest: %s\n", strings.Join(a.Strongest, ", "))
 	if len(a.Weakest) > 0 {
-		fmt.Printf("Weakest:   %s\n", strings.Join(a.Weakest, ", "))
+		p.Printf("Weakest:   %s\n", strings.Join(a.Weakest, ", "))
+	}
+}
+
+func printStreaks(p *output.Printer, r *streaks.Result) {
+	if len(r.Hot) == 0 && len(r.Cold) == 0 {
+		return
+	}
+
+	p.Println()
+	if len(r.Hot) > 0 {
+		p.Printf("Hot:  %s\n", formatStreaks(r.Hot))
+	}
+	if len(r.Cold) > 0 {
+		p.Printf("Cold: %s\n", formatStreaks(r.Cold))
+	}
+}
+
+func formatStreaks(ss []streaks.Streak) string {
+	parts := make([]string, len(ss))
+	for i, s := range ss {
+		parts[i] = fmt.Sprintf("%s (%.0f%% vs %.0f%% usually)", s.Name, s.Recent*100, s.Career*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printSeasons(ctx context.Context, p *output.Printer, store seasons.Store, team string, compact bool) error {
+	r, err := seasons.Analyze(ctx, store, team)
+	if err != nil {
+		return fmt.Errorf("compare %s's seasons: %w", team, err)
+	}
+	if r.From.Number == 0 {
+		p.Printf("%s has only played one season\n", team)
+		return nil
 	}
+
+	rows := make([][]string, len(r.Machines))
+	for i, m := range r.Machines {
+		name := m.MachineName
+		if compact {
+			name = output.ShortMachineName(name)
+		}
+		change, roster := "-", "-"
+		if m.Compared() {
+			change = output.FormatChange(m.From.P50Score, m.To.P50Score)
+		}
+		if m.RosterCompared() {
+			roster = output.FormatChange(m.FromRoster.P50Score, m.ToRoster.P50Score)
+		}
+		rows[i] = []string{name, formatSeasonP50(m.From), formatSeasonP50(m.To), change, roster}
+	}
+
+	p.Printf("%s: season %d vs season %d\n\n", team, r.From.Number, r.To.Number)
+	if err := p.Table(seasonHeaders(r), rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	for _, line := range []struct {
+		label string
+		names []string
+	}{
+		{"New:    ", r.Joined},
+		{"Left:   ", r.Left},
+		{"Weaker: ", r.Weaker},
+	} {
+		if len(line.names) > 0 {
+			p.Printf("%s%s\n", line.label, strings.Join(line.names, ", "))
+		}
+	}
+
+	p.Println()
+	p.Println("P50 is the median of the team's scores on each machine that season. Roster")
+	p.Println("change compares the P50 of each season's roster across every game they've")
+	p.Println("played, so it shows what the roster changes alone did. Weaker machines are")
+	p.Println("those the new roster is weaker on, biggest drop first.")
+	return nil
+}
+
+func formatSeasonP50(s seasons.Stats) string {
+	if s.Games == 0 {
+		return "-"
+	}
+	return output.FormatScore(s.P50Score)
+}
+
+func printPicks(p *output.Printer, r *scout.Result) error {
+	stats := slices.Clone(r.GlobalStats)
+	slices.SortStableFunc(stats, func(a, b scout.MachineStats) int {
+		return cmp.Or(cmp.Compare(b.Picks.Picked, a.Picks.Picked), cmp.Compare(b.Picks.Stuck, a.Picks.Stuck))
+	})
+
+	total := 0
+	for _, s := range stats {
+		total += s.Picks.Picked
+	}
+
+	rows := make([][]string, 0, len(stats))
+	var never []string
+	for _, s := range stats {
+		if s.Picks == (db.MachinePicks{}) {
+			continue
+		}
+		if s.Picks.Picked == 0 {
+			never = append(never, s.MachineName)
+		}
+		share := "-"
+		if total > 0 {
+			share = fmt.Sprintf("%.0f%%", float64(s.Picks.Picked)/float64(total)*100)
+		}
+		rows = append(rows, []string{
+			s.MachineName,
+			fmt.Sprintf("%d", s.Picks.Picked),
+			fmt.Sprintf("%d", s.Picks.Stuck),
+			share,
+		})
+	}
+
+	if len(rows) == 0 {
+		p.Printf("No picks recorded for %s\n", r.Team)
+		return nil
+	}
+
+	if err := p.Table(pickHeaders(), rows); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	if len(never) > 0 {
+		p.Println()
+		p.Printf("Never picks: %s\n", strings.Join(never, ", "))
+	}
+	p.Println()
+	p.Printf("Games %s played on each machine in rounds it picked the machines, and in\n", r.Team)
+	p.Println("rounds its opponent picked them. The away team picks in odd rounds, and the")
+	p.Println("home team in even.")
+	return nil
 }
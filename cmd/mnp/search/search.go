@@ -0,0 +1,66 @@
+// Package search implements the search command.
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command searches players, teams, machines, and venues at once.
+type Command struct {
+	Query []string `arg:"" help:"Words to search for. Each matches the start of a word in a key or name."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the search command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	query := strings.Join(c.Query, " ")
+	results, err := store.Search(ctx, query)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if len(results) == 0 {
+		p.Printf("Nothing matches %q\n", query)
+		return nil
+	}
+
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		key := r.Key
+		if r.Kind == db.SearchPlayer {
+			key = "-"
+		}
+		rows[i] = []string{kindName(r.Kind), r.Name, key}
+	}
+
+	return p.Table([]string{"Type", "Name", "Key"}, rows)
+}
+
+func kindName(kind string) string {
+	switch kind {
+	case db.SearchPlayer:
+		return "Player"
+	case db.SearchTeam:
+		return "Team"
+	case db.SearchMachine:
+		return "Machine"
+	case db.SearchVenue:
+		return "Venue"
+	}
+	return kind
+}
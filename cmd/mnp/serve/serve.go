@@ -3,48 +3,233 @@ package serve
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/negz/mnp/internal/anomaly"
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/digest"
+	"github.com/negz/mnp/internal/imgcache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/accuracy"
 	"github.com/negz/mnp/internal/web"
 )
 
 // Command starts the MNP web server.
 type Command struct {
-	Addr string `default:":8080" help:"Address to listen on."`
+	Addr                string        `default:":8080"                                                                                    help:"Address to listen on."`
+	AvatarDir           string        `help:"Directory of player avatar images (e.g. jay-ostby.png)."                                     type:"path"`
+	AdminToken          string        `env:"MNP_ADMIN_TOKEN"                                                                              help:"Token required to use admin pages such as avatar uploads."`
+	MachineArtURL       string        `help:"Machine backglass URL template, with {key} replaced by the machine key. Unset disables art."`
+	MachineArtCacheSize int64         `default:"52428800"                                                                                 help:"Maximum bytes of machine art to cache on disk."`
+	Locale              string        `help:"Format numbers for this language (e.g. de), rather than each visitor's browser language."`
+	Timezone            string        `default:"America/Los_Angeles"                                                                      help:"League timezone. Match dates are in this timezone."`
+	MatchStart          time.Duration `default:"20h"                                                                                      help:"When matches start, as a duration after midnight."`
+	AnomalyWebhookURL   string        `env:"MNP_ANOMALY_WEBHOOK_URL"                                                                      help:"Slack-compatible webhook URL to post data anomalies found after each sync."`
+	DigestWebhookURL    string        `env:"MNP_DIGEST_WEBHOOK_URL"                                                                       help:"Slack-compatible webhook URL to post digests of --digest-team's next matches to before match night."`
+	DigestTeam          []string      `help:"Teams to post digests of before each match (e.g. CRA,PYC)."`
+	DigestLead          time.Duration `default:"6h"                                                                                       help:"How long before matches start to post digests."`
+	FullScores          bool          `help:"Show every digit of scores by default, rather than abbreviating them (e.g. 2.5B)."`
+	NoCompress          bool          `help:"Don't gzip HTML, CSS, JSON, and CSV responses."`
+	CacheSize           int64         `default:"67108864"                                                                                 help:"Approximate maximum bytes of analyses and stats to cache in memory."`
+
+	ReadTimeout     time.Duration `default:"30s" help:"How long clients may take to send a request, including its body."`
+	RequestTimeout  time.Duration `default:"20s" help:"How long to spend on a request before giving up with 503 Service Unavailable. 0 disables the limit."`
+	ShutdownTimeout time.Duration `default:"10s" help:"How long to let in-flight requests finish when asked to stop."`
+
+	RateLimit      float64 `default:"5"                                                                                                                                         help:"Requests per second each client may make, once they've used their burst. 0 disables rate limiting."`
+	RateBurst      int     `default:"30"                                                                                                                                        help:"Requests each client may make at once before they're rate limited."`
+	ClientIPHeader string  `help:"Header a trusted proxy sets to the client's IP (e.g. Fly-Client-IP), used to rate limit clients. Unset uses the address they connected from."`
 }
 
 // Run executes the serve command.
 func (c *Command) Run(d *cache.DB, _ *slog.Logger) error {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	dbst, err := d.Store(ctx)
 	if err != nil {
 		return err
 	}
 
-	st := cache.NewInMemoryStore(dbst)
+	st := cache.NewInMemoryStore(dbst, cache.WithMaxBytes(c.CacheSize))
+
+	blended, err := d.BlendedStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer blended.Close() //nolint:errcheck // Nothing to do if closing fails on exit.
 
 	log := slog.New(slog.NewJSONHandler(os.Stderr, nil))
 
-	go web.Sync(ctx, func(ctx context.Context) error {
+	clock, err := schedule.NewClock(c.Timezone)
+	if err != nil {
+		return err
+	}
+	clock.Start = c.MatchStart
+
+	var hook *anomaly.Webhook
+	if c.AnomalyWebhookURL != "" {
+		hook = anomaly.NewWebhook(c.AnomalyWebhookURL)
+	}
+
+	if c.DigestWebhookURL != "" && len(c.DigestTeam) == 0 {
+		return fmt.Errorf("--digest-webhook-url needs at least one --digest-team")
+	}
+	var digests *digest.Webhook
+	if c.DigestWebhookURL != "" {
+		digests = digest.NewWebhook(c.DigestWebhookURL)
+	}
+
+	m := web.NewMetrics(dbst.Size, st.Stats)
+
+	// The server is ready once the first successful sync has refreshed the
+	// in-memory cache.
+	var ready atomic.Bool
+
+	go web.Sync(ctx, m.Sync(func(ctx context.Context) error {
 		if err := d.Sync(ctx); err != nil {
 			return err
 		}
-		return st.Refresh(ctx)
-	}, 15*time.Minute, log)
+		if err := st.Refresh(ctx); err != nil {
+			return err
+		}
+		ready.Store(true)
+
+		// Predict upcoming matches so they can be scored once results load.
+		n, err := accuracy.Record(ctx, dbst, clock.Today(time.Now()))
+		if err != nil {
+			return fmt.Errorf("record predictions: %w", err)
+		}
+		log.Info("Recorded predictions", "count", n)
+
+		anomalies, err := anomaly.Check(ctx, dbst)
+		if err != nil {
+			return fmt.Errorf("check for anomalies: %w", err)
+		}
+		for _, a := range anomalies {
+			log.Warn("Data anomaly", "kind", a.Kind, "subject", a.Subject, "msg", a.Message)
+		}
+		if hook != nil {
+			if err := hook.Notify(ctx, anomalies); err != nil {
+				log.Error("Cannot post anomalies to webhook", "err", err)
+			}
+		}
+		if _, err := anomaly.Snapshot(ctx, dbst, time.Now()); err != nil {
+			return fmt.Errorf("snapshot player P50s: %w", err)
+		}
+
+		// Analyze next week's matches now, so the first visitor doesn't wait.
+		n, err = st.Warm(ctx, clock.Today(time.Now()))
+		if err != nil {
+			return fmt.Errorf("warm analysis cache: %w", err)
+		}
+		cs := st.Stats()
+		log.Info("Warmed analysis cache", "matches", n, "entries", cs.Entries, "bytes", cs.Bytes, "hits", cs.Hits, "misses", cs.Misses, "evictions", cs.Evictions)
+
+		// Post digests once their match is close. Each is only posted once,
+		// though syncs continue through the lead time and serve may restart.
+		if digests == nil {
+			return nil
+		}
+		now := time.Now()
+		for _, team := range c.DigestTeam {
+			dg, err := digest.Analyze(ctx, st, clock.Today(now), strings.ToUpper(team))
+			if err != nil {
+				return fmt.Errorf("summarize %s's next match: %w", team, err)
+			}
+			if !digest.Due(dg, clock, now, c.DigestLead) {
+				continue
+			}
+			if err := digests.Notify(ctx, dbst, dg); err != nil {
+				log.Error("Cannot post digest to webhook", "team", dg.Team, "err", err)
+			}
+		}
+		return nil
+	}), 15*time.Minute, log)
+
+	opts := []web.ServerOption{web.WithClock(clock), web.WithMetrics(m), web.WithReadyCheck(ready.Load), web.WithBlendedStore(blended)}
+	if c.AvatarDir != "" {
+		opts = append(opts, web.WithAvatarDir(c.AvatarDir))
+	}
+	if c.AdminToken != "" {
+		opts = append(opts, web.WithAdminToken(c.AdminToken))
+	}
+
+	if c.Locale != "" {
+		l, ok := output.ParseLocale(c.Locale)
+		if !ok {
+			return fmt.Errorf("unsupported locale %q", c.Locale)
+		}
+		opts = append(opts, web.WithLocale(l))
+	}
+	if c.FullScores {
+		opts = append(opts, web.WithFullScores())
+	}
+	if c.MachineArtURL != "" {
+		art := imgcache.New(filepath.Join(d.Dir(), "machine-art"), c.MachineArtURL, imgcache.WithMaxBytes(c.MachineArtCacheSize))
+		opts = append(opts, web.WithMachineArt(art))
+	}
+
+	if c.RateLimit < 0 || c.RateBurst < 1 {
+		return fmt.Errorf("rate limit must be at least 0 and rate burst at least 1, got %g and %d", c.RateLimit, c.RateBurst)
+	}
 
 	log.Info("Starting web server", "addr", c.Addr)
 
+	h := web.NewServer(st, log, opts...).Handler()
+
+	// Give up on slow requests, such as analyses of teams no one has looked at
+	// since the last sync, before the connection's write deadline cuts them
+	// off without a response. Without a request timeout the write deadline
+	// still stops slow clients holding connections open forever.
+	writeTimeout := 30 * time.Second
+	if c.RequestTimeout > 0 {
+		h = http.TimeoutHandler(h, c.RequestTimeout, "This page took too long to load. Please try again.")
+		writeTimeout = c.RequestTimeout + 10*time.Second
+	}
+
+	h = web.WithCacheControl(h, "public, max-age=60")
+	if !c.NoCompress {
+		h = web.WithCompression(h)
+	}
+	if c.RateLimit > 0 {
+		h = web.WithRateLimit(h, c.RateLimit, c.RateBurst, c.ClientIPHeader)
+	}
+
 	s := &http.Server{
 		Addr:              c.Addr,
-		Handler:           web.WithLogging(web.WithCacheControl(web.NewServer(st, log).Handler(), "public, max-age=60"), log),
+		Handler:           web.WithLogging(h, log),
 		ReadHeaderTimeout: 10 * time.Second,
-		WriteTimeout:      30 * time.Second,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      writeTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- s.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
 	}
 
-	return s.ListenAndServe()
+	// Stop accepting connections, and give in-flight requests a chance to
+	// finish rather than cutting them off.
+	log.Info("Shutting down web server", "timeout", c.ShutdownTimeout)
+	sctx, cancel := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(sctx); err != nil {
+		return fmt.Errorf("shut down web server: %w", err)
+	}
+	return nil
 }
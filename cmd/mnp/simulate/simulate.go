@@ -0,0 +1,162 @@
+// Package simulate implements the simulate command.
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/simulate"
+)
+
+// bucketWidth is how many points of spread each row of the outcome
+// distribution covers.
+const bucketWidth = 10
+
+// Command simulates a match between two teams many times.
+type Command struct {
+	Team1 string `arg:"" help:"First team key (e.g., CRA)."`
+	Team2 string `arg:"" help:"Second team key (e.g., PYC)."`
+
+	Venue       string `help:"Venue key (e.g., ANC). Defaults to the venue of the teams' next match."                short:"e"`
+	Home        string `help:"Home team key. Defaults to the home team of the teams' next match, or the first team."`
+	Simulations int    `default:"10000"                                                                              help:"Matches to simulate." short:"n"`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the simulate command.
+func (c *Command) Run(d *cache.DB) error {
+	if c.Simulations < 1 {
+		return fmt.Errorf("-n must be at least 1, got %d", c.Simulations)
+	}
+
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	today := clock.Today(time.Now())
+
+	team1, team2 := strings.ToUpper(c.Team1), strings.ToUpper(c.Team2)
+	venue := strings.ToUpper(c.Venue)
+	if venue == "" {
+		m, err := schedule.NextVenue(ctx, store, today, team1, team2)
+		if err != nil {
+			return fmt.Errorf("find %s's next venue: %w", team1, err)
+		}
+		if m == nil {
+			return fmt.Errorf("no --venue given, and %s has no upcoming match at a known venue", team1)
+		}
+		venue = m.VenueKey
+		p.Printf("At %s (%s), the venue of %s's next match. Pass --venue to choose another.\n\n", m.Venue, m.VenueKey, team1)
+	}
+
+	// The home team decides who picks machines in each round.
+	home := strings.ToUpper(c.Home)
+	if home == "" {
+		next, err := schedule.NextMatch(ctx, store, today, team1, team2)
+		if err != nil {
+			return fmt.Errorf("find %s's next match against %s: %w", team1, team2, err)
+		}
+		if next != nil {
+			home = next.HomeTeamKey
+		}
+	}
+
+	opts := []simulate.Option{simulate.WithFormats(formats), simulate.Simulations(c.Simulations)}
+	if home != "" {
+		opts = append(opts, simulate.HomeTeam(home))
+	}
+
+	r, err := simulate.Analyze(ctx, store, venue, team1, team2, opts...)
+	if err != nil {
+		return fmt.Errorf("simulate %s vs %s: %w", team1, team2, err)
+	}
+
+	p.Printf("Simulated %d matches of %s vs %s at %s, with %s at home and %d points up for grabs.\n\n",
+		r.Simulations, r.Team1, r.Team2, r.Venue, r.Home, formats.Latest().TotalPoints())
+	for _, round := range r.Rounds {
+		kind := "singles"
+		if round.Doubles {
+			kind = "doubles"
+		}
+		machines := make([]string, len(round.Games))
+		for i, g := range round.Games {
+			machines[i] = output.ShortMachineName(g.MachineName)
+		}
+		p.Printf("Round %d (%s, %s picks): %s\n", round.Number, kind, round.Picker, strings.Join(machines, ", "))
+	}
+
+	p.Println()
+	p.Printf("%s wins %s, %s wins %s, ties %s.\n", r.Team1, pct(r.Team1Wins, r.Simulations), r.Team2, pct(r.Team2Wins, r.Simulations), pct(r.Ties, r.Simulations))
+	p.Printf("Expected points: %s %.1f, %s %.1f (%s).\n", r.Team1, r.Team1Points, r.Team2, r.Team2Points, formatSpread(r.Team1, r.Team2, r.Spread()))
+	p.Printf("Middle 80%% of outcomes: %s to %s.\n\n", formatSpread(r.Team1, r.Team2, r.SpreadPercentile(0.1)), formatSpread(r.Team1, r.Team2, r.SpreadPercentile(0.9)))
+
+	if err := p.Table([]string{r.Team1 + " Spread", "Matches", "Share"}, bucketRows(r)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Printf("Spreads are %s's points minus %s's. Each row includes its lower bound.\n", r.Team1, r.Team2)
+	p.Println("Each player's scores are sampled from every score they've posted on the")
+	p.Println("machine, or the league's if they haven't played it.")
+	return nil
+}
+
+// bucketRows groups outcomes into rows bucketWidth points of spread wide.
+func bucketRows(r *simulate.Result) [][]string {
+	var rows [][]string
+	var lo float64
+	count := 0
+	flush := func() {
+		if count > 0 {
+			rows = append(rows, []string{fmt.Sprintf("%+.0f to %+.0f", lo, lo+bucketWidth), strconv.Itoa(count), pct(count, r.Simulations)})
+		}
+	}
+	for _, o := range r.Outcomes {
+		b := math.Floor(o.Spread/bucketWidth) * bucketWidth
+		if b != lo || count == 0 {
+			flush()
+			lo, count = b, 0
+		}
+		count += o.Count
+	}
+	flush()
+	return rows
+}
+
+// formatSpread describes a point spread, e.g. "CRA by 6.5".
+func formatSpread(team1, team2 string, spread float64) string {
+	spread = math.Round(spread*10) / 10
+	switch {
+	case spread > 0:
+		return fmt.Sprintf("%s by %s", team1, output.FormatPoints(spread))
+	case spread < 0:
+		return fmt.Sprintf("%s by %s", team2, output.FormatPoints(-spread))
+	default:
+		return "even"
+	}
+}
+
+func pct(n, total int) string {
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
@@ -0,0 +1,50 @@
+// Package standings implements the standings command.
+package standings
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command shows the league table for the current season, or an earlier one.
+type Command struct {
+	Season int `help:"Show standings for this season number (e.g., 20) rather than the current season."`
+}
+
+// Run executes the standings command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	standings, err := store.GetStandings(ctx, c.Season)
+	if err != nil {
+		return fmt.Errorf("get standings: %w", err)
+	}
+	if len(standings) == 0 {
+		fmt.Println("No teams found")
+		return nil
+	}
+
+	rows := make([][]string, len(standings))
+	for i, st := range standings {
+		rows[i] = []string{
+			strconv.Itoa(i + 1),
+			st.TeamKey,
+			st.TeamName,
+			strconv.Itoa(st.Played),
+			fmt.Sprintf("%d-%d-%d", st.Wins, st.Losses, st.Ties),
+			output.FormatPoints(st.Points),
+			output.FormatPoints(st.OpponentPoints),
+		}
+	}
+
+	return output.Table(os.Stdout, []string{"#", "Key", "Name", "Played", "W-L-T", "Points", "Against"}, rows)
+}
@@ -0,0 +1,114 @@
+// Package team implements the team command.
+package team
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/seasons"
+)
+
+// Command compares a team between two seasons.
+type Command struct {
+	Team           string `arg:""                                                                                         default:"${team}" help:"Team key (e.g., CRA). Defaults to the config file's team."`
+	CompareSeasons []int  `help:"Two seasons to compare, separated by a comma (e.g., 22,23). Defaults to the latest two."`
+}
+
+// Run executes the team command.
+func (c *Command) Run(d *cache.DB) error {
+	if len(c.CompareSeasons) != 0 && len(c.CompareSeasons) != 2 {
+		return fmt.Errorf("--compare-seasons takes exactly two seasons")
+	}
+
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	var opts []seasons.Option
+	if len(c.CompareSeasons) == 2 {
+		opts = append(opts, seasons.Between(c.CompareSeasons[0], c.CompareSeasons[1]))
+	}
+
+	team := strings.ToUpper(c.Team)
+	r, err := seasons.Analyze(ctx, store, team, opts...)
+	if err != nil {
+		return fmt.Errorf("compare %s seasons: %w", c.Team, err)
+	}
+
+	if r.From.Number == 0 {
+		fmt.Printf("%s has only played one season\n", team)
+		return nil
+	}
+
+	fmt.Printf("%s: season %d vs season %d\n\n", team, r.From.Number, r.To.Number)
+	fmt.Printf("Points:  %s (season %d), %s (season %d)\n",
+		formatPct(r.From), r.From.Number, formatPct(r.To), r.To.Number)
+	fmt.Printf("Roster:  kept %d of %d players\n\n", len(r.Kept), len(r.From.Roster))
+
+	if err := output.Table(os.Stdout, headers(r), machinesToRows(r.Machines)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	printTurnover(r)
+	return nil
+}
+
+func headers(r *seasons.Result) []string {
+	from, to := fmt.Sprintf("S%d", r.From.Number), fmt.Sprintf("S%d", r.To.Number)
+	return []string{"Machine", from + " Games", from + " P50", to + " Games", to + " P50", "Change"}
+}
+
+func machinesToRows(machines []seasons.Machine) [][]string {
+	rows := make([][]string, len(machines))
+	for i, m := range machines {
+		change := "-"
+		if m.Compared() {
+			change = output.FormatChange(m.From.P50Score, m.To.P50Score)
+		}
+		rows[i] = []string{
+			m.MachineName,
+			fmt.Sprintf("%d", m.From.Games),
+			formatP50(m.From),
+			fmt.Sprintf("%d", m.To.Games),
+			formatP50(m.To),
+			change,
+		}
+	}
+	return rows
+}
+
+func formatP50(s seasons.Stats) string {
+	if s.Games == 0 {
+		return "-"
+	}
+	return output.FormatScore(s.P50Score)
+}
+
+func formatPct(s seasons.Season) string {
+	if s.Points.Matches == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", s.PointsPct()*100)
+}
+
+func printTurnover(r *seasons.Result) {
+	fmt.Println()
+	for _, line := range []struct {
+		label   string
+		players []string
+	}{
+		{"Kept:  ", r.Kept},
+		{"Joined:", r.Joined},
+		{"Left:  ", r.Left},
+	} {
+		if len(line.players) > 0 {
+			fmt.Printf("%s %s\n", line.label, strings.Join(line.players, ", "))
+		}
+	}
+}
@@ -5,33 +5,94 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
 	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/roster"
 )
 
-// Command lists all teams in the current season.
+// Command lists all teams in the current season, or an earlier one, or shows one
+// team's roster in detail.
 type Command struct {
-	Search string `arg:"" help:"Search term (matches key or name)." optional:""`
+	Search string `arg:""                                                                                                          help:"Search term (matches key or name)." optional:""`
+	Season int    `help:"List teams from this season number (e.g., 20) rather than the current season."`
+	Detail string `help:"Show one team's current roster (e.g., CRA), with each player's IPR, games this season, and top machines."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the teams command.
 func (c *Command) Run(d *cache.DB) error {
 	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	teams, err := store.ListTeams(ctx, c.Search)
+	if c.Detail != "" {
+		return printRoster(ctx, p, store, strings.ToUpper(c.Detail))
+	}
+
+	teams, err := store.ListTeams(ctx, c.Search, c.Season)
 	if err != nil {
 		return fmt.Errorf("list teams: %w", err)
 	}
 
+	// Roster retention is only known for the current season.
+	if c.Season != 0 {
+		rows := make([][]string, len(teams))
+		for i, t := range teams {
+			rows[i] = []string{t.Key, t.Name, t.Venue}
+		}
+		return p.Table([]string{"Key", "Name", "Venue"}, rows)
+	}
+
+	retention, err := store.ListRosterRetention(ctx)
+	if err != nil {
+		return fmt.Errorf("list roster retention: %w", err)
+	}
+	returning := make(map[string]db.RosterRetention, len(retention))
+	for _, rr := range retention {
+		returning[rr.TeamKey] = rr
+	}
+
 	rows := make([][]string, len(teams))
 	for i, t := range teams {
-		rows[i] = []string{t.Key, t.Name, t.Venue}
+		rr := returning[t.Key]
+		rows[i] = []string{t.Key, t.Name, t.Venue, output.FormatRetention(rr.Kept, rr.Previous)}
+	}
+
+	return p.Table([]string{"Key", "Name", "Venue", "Returning"}, rows)
+}
+
+func printRoster(ctx context.Context, p *output.Printer, s roster.Store, team string) error {
+	r, err := roster.Analyze(ctx, s, team)
+	if err != nil {
+		return fmt.Errorf("summarize %s's roster: %w", team, err)
+	}
+	if len(r.Players) == 0 {
+		p.Printf("No roster found for %s\n", team)
+		return nil
+	}
+
+	rows := make([][]string, len(r.Players))
+	for i, pl := range r.Players {
+		top := "-"
+		if len(pl.Strongest) > 0 {
+			top = strings.Join(pl.Strongest, ", ")
+		}
+		rows[i] = []string{pl.Name, output.FormatIPR(pl.IPR), fmt.Sprintf("%d", pl.Games), top}
+	}
+	if err := p.Table([]string{"Player", "IPR", "Games", "Top Machines"}, rows, output.WrapColumn(3, 50)); err != nil {
+		return fmt.Errorf("write table: %w", err)
 	}
 
-	return output.Table(os.Stdout, []string{"Key", "Name", "Venue"}, rows)
+	p.Println()
+	p.Println("Games are this season's. Top machines are those where the player's P50")
+	p.Println("ranks highest among the league's scores, of those they've played at least")
+	p.Println("three times. See mnp player <name> for more, or mnp scout " + team + ".")
+	return nil
 }
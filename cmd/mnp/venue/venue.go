@@ -0,0 +1,94 @@
+// Package venue implements the venue command.
+package venue
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Command shows the machines at a venue.
+type Command struct {
+	Venue   string `arg:""                                                                                         help:"Venue key (e.g., ANC)."`
+	History bool   `help:"Show the machines added and removed in each season, rather than the machines there now."`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
+}
+
+// Run executes the venue command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	names, err := store.GetMachineNames(ctx)
+	if err != nil {
+		return fmt.Errorf("load machine names: %w", err)
+	}
+
+	venue := strings.ToUpper(c.Venue)
+	if !c.History {
+		machines, err := store.GetVenueMachines(ctx, venue, 0)
+		if err != nil {
+			return fmt.Errorf("load %s's machines: %w", venue, err)
+		}
+		if len(machines) == 0 {
+			p.Printf("No machines known at %s\n", venue)
+			return nil
+		}
+		rows := make([][]string, 0, len(machines))
+		for key := range machines {
+			rows = append(rows, []string{key, output.MachineName(names, key)})
+		}
+		slices.SortFunc(rows, func(a, b []string) int {
+			return cmp.Compare(a[1], b[1])
+		})
+		return p.Table([]string{"Key", "Name"}, rows)
+	}
+
+	history, err := store.GetVenueMachineHistory(ctx, venue)
+	if err != nil {
+		return fmt.Errorf("load %s's machine history: %w", venue, err)
+	}
+	if len(history) == 0 {
+		p.Printf("No machines known at %s\n", venue)
+		return nil
+	}
+
+	rows := make([][]string, len(history))
+	for i, vs := range history {
+		rows[i] = []string{strconv.Itoa(vs.Season), strconv.Itoa(len(vs.Machines)), machineNames(names, vs.Added), machineNames(names, vs.Removed)}
+	}
+	if err := p.Table([]string{"Season", "Machines", "Added", "Removed"}, rows, output.WrapColumn(2, 40), output.WrapColumn(3, 40)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	p.Println()
+	p.Println("The latest season's machines are the venue's machines now. Earlier seasons'")
+	p.Println("are the machines played there, so a machine nobody picked looks removed.")
+	return nil
+}
+
+// machineNames returns the names of the supplied machines, sorted and
+// separated by commas, or "-" if there are none.
+func machineNames(names map[string]string, keys []string) string {
+	if len(keys) == 0 {
+		return "-"
+	}
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = output.MachineName(names, key)
+	}
+	slices.Sort(out)
+	return strings.Join(out, ", ")
+}
@@ -5,33 +5,37 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/negz/mnp/internal/cache"
 	"github.com/negz/mnp/internal/output"
 )
 
-// Command lists all venues with their keys.
+// Command lists all venues with their keys, machine counts, and home teams.
 type Command struct {
 	Search string `arg:"" help:"Search term (matches key or name)." optional:""`
+
+	Output output.Format `default:"table" enum:"table,csv" help:"Write tables as text, or as CSV for spreadsheets." short:"o"`
 }
 
 // Run executes the venues command.
 func (c *Command) Run(d *cache.DB) error {
 	ctx := context.Background()
+	p := output.NewPrinter(c.Output, os.Stdout, os.Stderr)
 	store, err := d.SyncedStore(ctx)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 
-	venues, err := store.ListVenues(ctx, c.Search)
+	venues, err := store.ListVenueSummaries(ctx, c.Search)
 	if err != nil {
 		return fmt.Errorf("list venues: %w", err)
 	}
 
 	rows := make([][]string, len(venues))
 	for i, v := range venues {
-		rows[i] = []string{v.Key, v.Name}
+		rows[i] = []string{v.Key, v.Name, fmt.Sprintf("%d", v.Machines), strings.Join(v.HomeTeams, ", ")}
 	}
 
-	return output.Table(os.Stdout, []string{"Key", "Name"}, rows)
+	return p.Table([]string{"Key", "Name", "Machines", "Home Teams"}, rows, output.WrapColumn(3, 30))
 }
@@ -0,0 +1,104 @@
+// Package week implements the week command.
+package week
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/week"
+)
+
+func headers() []string {
+	return []string{"Match", "Venue", "Favorite", "Edges", "Home's Best", "Away's Best"}
+}
+
+// Command summarizes every match in a week of the schedule.
+type Command struct {
+	Week int `help:"Week of the current season to summarize. Defaults to the next week with matches."`
+}
+
+// Run executes the week command.
+func (c *Command) Run(d *cache.DB) error {
+	ctx := context.Background()
+	store, err := d.SyncedStore(ctx)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		return err
+	}
+
+	var opts []week.Option
+	if c.Week != 0 {
+		opts = append(opts, week.InWeek(c.Week))
+	}
+
+	r, err := week.Analyze(ctx, store, clock.Today(time.Now()), opts...)
+	if err != nil {
+		return fmt.Errorf("summarize week: %w", err)
+	}
+
+	if r.Week == 0 {
+		fmt.Println("No matches to summarize")
+		return nil
+	}
+
+	fmt.Printf("Week %d\n\n", r.Week)
+	if err := output.Table(os.Stdout, headers(), matchesToRows(r.Matches)); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("Favorites have the edge on more of the venue's machines, comparing each")
+	fmt.Println("team's likely players. Edges are home-away. Each team's best machine is the")
+	fmt.Println("one its likely players are furthest ahead on.")
+	return nil
+}
+
+func matchesToRows(matches []week.Match) [][]string {
+	rows := make([][]string, len(matches))
+	for i, m := range matches {
+		venue, favorite, edges := m.Venue, "-", "-"
+		if !m.Analyzed() {
+			venue = "TBD"
+		} else {
+			favorite = m.Favorite()
+			if favorite == "" {
+				favorite = "Toss-up"
+			}
+			edges = fmt.Sprintf("%d-%d", m.HomeEdges, m.AwayEdges)
+		}
+		rows[i] = []string{
+			m.AwayTeamKey + " @ " + m.HomeTeamKey,
+			venue,
+			favorite,
+			edges,
+			formatBest(m.HomeKey),
+			formatBest(m.AwayKey),
+		}
+	}
+	return rows
+}
+
+// formatBest formats a team's best machine and its edge, e.g. "The Addams
+// Family (+25%)". Machines the other team has no likely players on have no
+// meaningful percentage.
+func formatBest(m *matchup.MachineMatchup) string {
+	if m == nil {
+		return "-"
+	}
+	pct := math.Abs(m.Edge)
+	if pct > 1e15 {
+		return m.MachineName + " (unplayed by opponent)"
+	}
+	return fmt.Sprintf("%s (+%.0f%%)", m.MachineName, pct)
+}
@@ -0,0 +1,109 @@
+// Command release cross-compiles mnp for every platform it's released for.
+//
+//	go run ./hack/release --version v0.1.0
+//
+// It writes one binary per platform to the output directory, named like
+// mnp-linux-amd64 as the Dockerfile expects, and a SHA256SUMS file. The
+// binaries are static, and embed the web UI's templates and static assets, so
+// a binary is all a server needs.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// platforms are the GOOS/GOARCH pairs mnp is released for.
+//
+//nolint:gochecknoglobals // Read-only list of platforms.
+var platforms = []string{
+	"linux/amd64",
+	"linux/arm64",
+	"darwin/amd64",
+	"darwin/arm64",
+	"windows/amd64",
+	"windows/arm64",
+}
+
+func main() {
+	version := flag.String("version", "v0.0.0-dev", "Version to embed in the binaries.")
+	out := flag.String("out", "dist", "Directory to write binaries to.")
+	only := flag.String("platform", "", "Only build these GOOS/GOARCH pairs, separated by commas (e.g., linux/amd64). Defaults to every release platform.")
+	flag.Parse()
+
+	targets := platforms
+	if *only != "" {
+		targets = strings.Split(*only, ",")
+	}
+
+	if err := release(*version, *out, targets); err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func release(version, out string, targets []string) error {
+	if err := os.MkdirAll(out, 0o750); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	var sums strings.Builder
+	for _, t := range targets {
+		goos, goarch, ok := strings.Cut(t, "/")
+		if !ok {
+			return fmt.Errorf("platform %q isn't GOOS/GOARCH", t)
+		}
+
+		name := fmt.Sprintf("mnp-%s-%s", goos, goarch)
+		if goos == "windows" {
+			name += ".exe"
+		}
+		path := filepath.Join(out, name)
+
+		fmt.Printf("Building %s\n", path)
+		if err := build(version, goos, goarch, path); err != nil {
+			return fmt.Errorf("build %s: %w", t, err)
+		}
+
+		sum, err := sha256File(path)
+		if err != nil {
+			return fmt.Errorf("checksum %s: %w", name, err)
+		}
+		fmt.Fprintf(&sums, "%s  %s\n", sum, name)
+	}
+
+	if err := os.WriteFile(filepath.Join(out, "SHA256SUMS"), []byte(sums.String()), 0o600); err != nil {
+		return fmt.Errorf("write checksums: %w", err)
+	}
+	return nil
+}
+
+// build compiles a static mnp binary for one platform.
+func build(version, goos, goarch, path string) error {
+	ldflags := "-s -w -X github.com/negz/mnp/internal/version.Version=" + version
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", path, "./cmd/mnp")
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // A binary we just built.
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck // Read-only file.
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
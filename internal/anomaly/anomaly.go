@@ -0,0 +1,178 @@
+// Package anomaly checks synced data for signs that something went wrong
+// while loading it.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+const (
+	// inactiveWeeks is how many weeks into a season a team can go without
+	// playing a game before it's reported.
+	inactiveWeeks = 3
+
+	// snapshotAge is how long a P50 snapshot is compared against before it's
+	// replaced.
+	snapshotAge = 7 * 24 * time.Hour
+
+	// minJumpGames is the fewest games a snapshot P50 must be based on to
+	// report a jump. P50s from fewer games swing too much to be meaningful.
+	minJumpGames = 3
+
+	// jumpFactor is how many times its snapshot value a P50 must reach to be
+	// reported.
+	jumpFactor = 2
+)
+
+// Store is the set of queries needed to check for anomalies.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetLatestPlayedWeek(ctx context.Context) (int, error)
+	ListTeamGameCounts(ctx context.Context) ([]db.TeamGameCount, error)
+	ListVenuesWithoutMachines(ctx context.Context) ([]db.Venue, error)
+	ListPlayerP50s(ctx context.Context) ([]db.PlayerP50, error)
+	GetP50Snapshot(ctx context.Context) ([]db.PlayerP50, time.Time, error)
+}
+
+// SnapshotStore is the set of queries needed to save P50 snapshots.
+type SnapshotStore interface {
+	ListPlayerP50s(ctx context.Context) ([]db.PlayerP50, error)
+	GetP50Snapshot(ctx context.Context) ([]db.PlayerP50, time.Time, error)
+	SaveP50Snapshot(ctx context.Context, p50s []db.PlayerP50, takenAt time.Time) error
+}
+
+// Kind is a kind of anomaly.
+type Kind string
+
+// Kinds of anomaly.
+const (
+	KindInactiveTeam Kind = "inactive-team" // A team with no games several weeks into the season.
+	KindP50Jump      Kind = "p50-jump"      // A player whose P50 on a machine suddenly doubled.
+	KindEmptyVenue   Kind = "empty-venue"   // A venue hosting matches with no machines.
+)
+
+// An Anomaly is something in the data that looks wrong.
+type Anomaly struct {
+	Kind    Kind
+	Subject string // The team, player, or venue key the anomaly is about.
+	Message string
+}
+
+// Check returns anything in the data that looks wrong, such as a team that
+// hasn't played three weeks into the season, a player whose P50 on a machine
+// doubled since the last snapshot, or a venue that lost all its machines.
+func Check(ctx context.Context, s Store) ([]Anomaly, error) {
+	var out []Anomaly
+
+	week, err := s.GetLatestPlayedWeek(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load latest week: %w", err)
+	}
+	if week >= inactiveWeeks {
+		teams, err := s.ListTeamGameCounts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load team game counts: %w", err)
+		}
+		for _, t := range teams {
+			if t.Games > 0 {
+				continue
+			}
+			out = append(out, Anomaly{
+				Kind:    KindInactiveTeam,
+				Subject: t.Key,
+				Message: fmt.Sprintf("%s (%s) has no games, %d weeks into the season.", t.Name, t.Key, week),
+			})
+		}
+	}
+
+	venues, err := s.ListVenuesWithoutMachines(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load venues without machines: %w", err)
+	}
+	for _, v := range venues {
+		out = append(out, Anomaly{
+			Kind:    KindEmptyVenue,
+			Subject: v.Key,
+			Message: fmt.Sprintf("%s (%s) hosts matches this season but has no machines.", v.Name, v.Key),
+		})
+	}
+
+	jumps, err := p50Jumps(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, jumps...), nil
+}
+
+func p50Jumps(ctx context.Context, s Store) ([]Anomaly, error) {
+	snapshot, taken, err := s.GetP50Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load P50 snapshot: %w", err)
+	}
+	if taken.IsZero() {
+		return nil, nil
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	current, err := s.ListPlayerP50s(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load player P50s: %w", err)
+	}
+
+	type key struct{ player, machine string }
+	before := make(map[key]db.PlayerP50, len(snapshot))
+	for _, p := range snapshot {
+		before[key{p.PlayerName, p.MachineKey}] = p
+	}
+
+	var out []Anomaly
+	for _, p := range current {
+		b, ok := before[key{p.PlayerName, p.MachineKey}]
+		if !ok || b.Games < minJumpGames || b.P50Score <= 0 {
+			continue
+		}
+		if p.P50Score < b.P50Score*jumpFactor {
+			continue
+		}
+		out = append(out, Anomaly{
+			Kind:    KindP50Jump,
+			Subject: p.PlayerName,
+			Message: fmt.Sprintf("%s's P50 on %s went from %s to %s since %s.",
+				p.PlayerName, output.MachineName(names, p.MachineKey),
+				output.FormatScore(b.P50Score), output.FormatScore(p.P50Score), taken.Format("Jan 2")),
+		})
+	}
+	return out, nil
+}
+
+// Snapshot saves the current player P50s for Check to compare against, if
+// there's no snapshot yet or the last one is more than a week old. It returns
+// true if it saved a new snapshot.
+func Snapshot(ctx context.Context, s SnapshotStore, now time.Time) (bool, error) {
+	_, taken, err := s.GetP50Snapshot(ctx)
+	if err != nil {
+		return false, fmt.Errorf("load P50 snapshot: %w", err)
+	}
+	if !taken.IsZero() && now.Sub(taken) < snapshotAge {
+		return false, nil
+	}
+
+	p50s, err := s.ListPlayerP50s(ctx)
+	if err != nil {
+		return false, fmt.Errorf("load player P50s: %w", err)
+	}
+	if err := s.SaveP50Snapshot(ctx, p50s, now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,209 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames           func(ctx context.Context) (map[string]string, error)
+	MockGetLatestPlayedWeek       func(ctx context.Context) (int, error)
+	MockListTeamGameCounts        func(ctx context.Context) ([]db.TeamGameCount, error)
+	MockListVenuesWithoutMachines func(ctx context.Context) ([]db.Venue, error)
+	MockListPlayerP50s            func(ctx context.Context) ([]db.PlayerP50, error)
+	MockGetP50Snapshot            func(ctx context.Context) ([]db.PlayerP50, time.Time, error)
+	MockSaveP50Snapshot           func(ctx context.Context, p50s []db.PlayerP50, takenAt time.Time) error
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetLatestPlayedWeek(ctx context.Context) (int, error) {
+	return m.MockGetLatestPlayedWeek(ctx)
+}
+
+func (m *MockStore) ListTeamGameCounts(ctx context.Context) ([]db.TeamGameCount, error) {
+	return m.MockListTeamGameCounts(ctx)
+}
+
+func (m *MockStore) ListVenuesWithoutMachines(ctx context.Context) ([]db.Venue, error) {
+	return m.MockListVenuesWithoutMachines(ctx)
+}
+
+func (m *MockStore) ListPlayerP50s(ctx context.Context) ([]db.PlayerP50, error) {
+	return m.MockListPlayerP50s(ctx)
+}
+
+func (m *MockStore) GetP50Snapshot(ctx context.Context) ([]db.PlayerP50, time.Time, error) {
+	return m.MockGetP50Snapshot(ctx)
+}
+
+func (m *MockStore) SaveP50Snapshot(ctx context.Context, p50s []db.PlayerP50, takenAt time.Time) error {
+	return m.MockSaveP50Snapshot(ctx, p50s, takenAt)
+}
+
+func TestCheck(t *testing.T) {
+	taken := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	teams := []db.TeamGameCount{
+		{Key: "CRA", Name: "Castle Crashers", Games: 30},
+		{Key: "PYC", Name: "Pinball Pirates"},
+	}
+	before := []db.PlayerP50{
+		{PlayerName: "Alice", MachineKey: "TAF", Games: 5, P50Score: 1_000_000},
+		{PlayerName: "Bob", MachineKey: "TAF", Games: 2, P50Score: 1_000_000},
+		{PlayerName: "Carol", MachineKey: "TAF", Games: 5, P50Score: 1_000_000},
+	}
+	after := []db.PlayerP50{
+		{PlayerName: "Alice", MachineKey: "TAF", Games: 6, P50Score: 2_500_000},
+		{PlayerName: "Bob", MachineKey: "TAF", Games: 3, P50Score: 3_000_000},
+		{PlayerName: "Carol", MachineKey: "TAF", Games: 6, P50Score: 1_500_000},
+		{PlayerName: "Dave", MachineKey: "TAF", Games: 6, P50Score: 9_000_000},
+	}
+
+	store := func(week int, snapshot []db.PlayerP50, taken time.Time, venues []db.Venue) *MockStore {
+		return &MockStore{
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TAF": "The Addams Family"}, nil
+			},
+			MockGetLatestPlayedWeek:       func(_ context.Context) (int, error) { return week, nil },
+			MockListTeamGameCounts:        func(_ context.Context) ([]db.TeamGameCount, error) { return teams, nil },
+			MockListVenuesWithoutMachines: func(_ context.Context) ([]db.Venue, error) { return venues, nil },
+			MockListPlayerP50s:            func(_ context.Context) ([]db.PlayerP50, error) { return after, nil },
+			MockGetP50Snapshot: func(_ context.Context) ([]db.PlayerP50, time.Time, error) {
+				return snapshot, taken, nil
+			},
+		}
+	}
+
+	type want struct {
+		anomalies []Anomaly
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  *MockStore
+		want   want
+	}{
+		"Healthy": {
+			reason: "Early in the season, with no snapshot and no empty venues, nothing should be reported.",
+			store:  store(2, nil, time.Time{}, nil),
+			want:   want{},
+		},
+		"InactiveTeam": {
+			reason: "A team with no games three weeks into the season should be reported.",
+			store:  store(3, nil, time.Time{}, nil),
+			want: want{anomalies: []Anomaly{
+				{Kind: KindInactiveTeam, Subject: "PYC", Message: "Pinball Pirates (PYC) has no games, 3 weeks into the season."},
+			}},
+		},
+		"EmptyVenue": {
+			reason: "A venue hosting matches with no machines should be reported.",
+			store:  store(1, nil, time.Time{}, []db.Venue{{Key: "ANC", Name: "Add-a-Ball"}}),
+			want: want{anomalies: []Anomaly{
+				{Kind: KindEmptyVenue, Subject: "ANC", Message: "Add-a-Ball (ANC) hosts matches this season but has no machines."},
+			}},
+		},
+		"P50Jump": {
+			reason: "A P50 that doubled since the snapshot should be reported, unless the snapshot P50 was based on too few games.",
+			store:  store(1, before, taken, nil),
+			want: want{anomalies: []Anomaly{
+				{Kind: KindP50Jump, Subject: "Alice", Message: "Alice's P50 on The Addams Family went from 1.0M to 2.5M since Jan 15."},
+			}},
+		},
+		"WeekError": {
+			reason: "An error loading the latest week should be returned.",
+			store: &MockStore{
+				MockGetLatestPlayedWeek: func(_ context.Context) (int, error) { return 0, errors.New("boom") },
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Check(context.Background(), tc.store)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.anomalies, got); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	now := time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC)
+	p50s := []db.PlayerP50{{PlayerName: "Alice", MachineKey: "TAF", Games: 5, P50Score: 1_000_000}}
+
+	type want struct {
+		saved bool
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		taken  time.Time
+		want   want
+	}{
+		"NoSnapshot": {
+			reason: "The first snapshot should be saved.",
+			want:   want{saved: true},
+		},
+		"Fresh": {
+			reason: "A snapshot less than a week old should be kept.",
+			taken:  now.Add(-6 * 24 * time.Hour),
+			want:   want{saved: false},
+		},
+		"Stale": {
+			reason: "A snapshot a week old should be replaced.",
+			taken:  now.Add(-7 * 24 * time.Hour),
+			want:   want{saved: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			saved := false
+			s := &MockStore{
+				MockListPlayerP50s: func(_ context.Context) ([]db.PlayerP50, error) { return p50s, nil },
+				MockGetP50Snapshot: func(_ context.Context) ([]db.PlayerP50, time.Time, error) {
+					return nil, tc.taken, nil
+				},
+				MockSaveP50Snapshot: func(_ context.Context, got []db.PlayerP50, takenAt time.Time) error {
+					saved = true
+					if diff := cmp.Diff(p50s, got); diff != "" {
+						t.Errorf("SaveP50Snapshot(...): -want, +got:\n%s", diff)
+					}
+					if !takenAt.Equal(now) {
+						t.Errorf("SaveP50Snapshot(...): want time %v, got %v", now, takenAt)
+					}
+					return nil
+				},
+			}
+
+			got, err := Snapshot(context.Background(), s, now)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.saved, got); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.saved, saved); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want SaveP50Snapshot called, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package anomaly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxWebhookLines is the most anomalies listed in a single webhook message.
+const maxWebhookLines = 20
+
+// A Webhook posts anomalies to a Slack-compatible incoming webhook URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+
+	mu   sync.Mutex // Protects everything below.
+	last []Anomaly
+}
+
+// NewWebhook returns a Webhook that posts to the supplied URL.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts the supplied anomalies, unless there are none or they're the
+// same as the anomalies it last posted. Checks run after every sync, so this
+// keeps a lingering problem from being posted again and again.
+func (w *Webhook) Notify(ctx context.Context, anomalies []Anomaly) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if slices.Equal(anomalies, w.last) {
+		return nil
+	}
+	if len(anomalies) == 0 {
+		w.last = nil
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{"text": message(anomalies)})
+	if err != nil {
+		return fmt.Errorf("encode webhook message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Response body is ignored.
+
+	if rsp.StatusCode/100 != 2 {
+		return fmt.Errorf("post webhook: unexpected status %s", rsp.Status)
+	}
+
+	w.last = anomalies
+	return nil
+}
+
+func message(anomalies []Anomaly) string {
+	var b strings.Builder
+	b.WriteString("MNP data looks wrong after the latest sync:")
+	for i, a := range anomalies {
+		if i == maxWebhookLines {
+			fmt.Fprintf(&b, "\n…and %d more.", len(anomalies)-i)
+			break
+		}
+		b.WriteString("\n• " + a.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package anomaly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNotify(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		got = append(got, body.Text)
+	}))
+	defer srv.Close()
+
+	venue := Anomaly{Kind: KindEmptyVenue, Subject: "ANC", Message: "ANC has no machines."}
+	team := Anomaly{Kind: KindInactiveTeam, Subject: "PYC", Message: "PYC has no games."}
+
+	w := NewWebhook(srv.URL)
+	for _, anomalies := range [][]Anomaly{
+		{venue},
+		{venue}, // Unchanged, so not posted again.
+		{venue, team},
+		nil, // Nothing to report.
+		{venue},
+	} {
+		if err := w.Notify(context.Background(), anomalies); err != nil {
+			t.Fatalf("Notify(...): %v", err)
+		}
+	}
+
+	want := []string{
+		"MNP data looks wrong after the latest sync:\n• ANC has no machines.",
+		"MNP data looks wrong after the latest sync:\n• ANC has no machines.\n• PYC has no games.",
+		"MNP data looks wrong after the latest sync:\n• ANC has no machines.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Notify(...) should only post when anomalies change: -want, +got:\n%s", diff)
+	}
+}
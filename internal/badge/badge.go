@@ -0,0 +1,205 @@
+// Package badge awards players badges for standout results.
+package badge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+const (
+	// billion is the score that earns the billion badge.
+	billion = 1_000_000_000
+
+	// perfectNightGames is the fewest games a player must top in one match to
+	// earn the perfect night badge.
+	perfectNightGames = 3
+)
+
+// Store is the set of queries needed to award badges.
+type Store interface {
+	ListPlayedResults(ctx context.Context, from, to string) ([]db.PlayedResult, error)
+	SaveBadges(ctx context.Context, badges []db.Badge) (int, error)
+}
+
+// A Rule decides which players earned a badge in a match.
+type Rule struct {
+	Key         string // Stored in the database. Never change it.
+	Name        string
+	Description string
+
+	// Earned returns the players who earned the badge, given every player
+	// result in one match ordered by round, game, then position.
+	Earned func(results []db.MatchResult) []string
+}
+
+// Rules returns every badge rule.
+func Rules() []Rule {
+	return []Rule{
+		{
+			Key:         "billion",
+			Name:        "Billionaire",
+			Description: "Scored a billion points in a game.",
+			Earned:      billionaire,
+		},
+		{
+			Key:         "giant-killer",
+			Name:        "Giant Killer",
+			Description: "Beat a higher rated player in a singles game.",
+			Earned:      giantKiller,
+		},
+		{
+			Key:         "perfect-night",
+			Name:        "Perfect Night",
+			Description: fmt.Sprintf("Had the top score in every game of a match, playing at least %d.", perfectNightGames),
+			Earned:      perfectNight,
+		},
+	}
+}
+
+// Earned is a badge a player earned, with the rule that awarded it.
+type Earned struct {
+	db.Badge
+
+	Name        string
+	Description string
+}
+
+// Describe looks up the rule for each badge. Badges with no rule, for
+// example because the rule was removed, are left out.
+func Describe(badges []db.Badge) []Earned {
+	rules := make(map[string]Rule)
+	for _, r := range Rules() {
+		rules[r.Key] = r
+	}
+
+	var out []Earned
+	for _, b := range badges {
+		r, ok := rules[b.Badge]
+		if !ok {
+			continue
+		}
+		out = append(out, Earned{Badge: b, Name: r.Name, Description: r.Description})
+	}
+	return out
+}
+
+// Award evaluates every rule against every played match, and saves the first
+// time each player earned each badge. Players keep badges they already have.
+// It returns the number of badges newly awarded.
+//
+// Ratings are players' current IPRs, since the archive doesn't record what
+// they were rated at the time.
+func Award(ctx context.Context, s Store, rules []Rule) (int, error) {
+	results, err := s.ListPlayedResults(ctx, "", "")
+	if err != nil {
+		return 0, fmt.Errorf("load played results: %w", err)
+	}
+
+	type earned struct{ player, badge string }
+	seen := make(map[earned]bool)
+	var badges []db.Badge
+
+	for i := 0; i < len(results); {
+		j := i
+		match := make([]db.MatchResult, 0, 16)
+		for j < len(results) && results[j].MatchKey == results[i].MatchKey {
+			match = append(match, results[j].MatchResult)
+			j++
+		}
+
+		for _, r := range rules {
+			for _, p := range r.Earned(match) {
+				if seen[earned{p, r.Key}] {
+					continue
+				}
+				seen[earned{p, r.Key}] = true
+				badges = append(badges, db.Badge{
+					PlayerName: p,
+					Badge:      r.Key,
+					MatchKey:   results[i].MatchKey,
+					EarnedOn:   results[i].Date,
+				})
+			}
+		}
+		i = j
+	}
+
+	n, err := s.SaveBadges(ctx, badges)
+	if err != nil {
+		return 0, fmt.Errorf("save badges: %w", err)
+	}
+	return n, nil
+}
+
+// games groups a match's results by game, in the order they were played.
+func games(results []db.MatchResult) [][]db.MatchResult {
+	var out [][]db.MatchResult
+	for i, r := range results {
+		if i == 0 || r.GameID != results[i-1].GameID {
+			out = append(out, nil)
+		}
+		out[len(out)-1] = append(out[len(out)-1], r)
+	}
+	return out
+}
+
+func billionaire(results []db.MatchResult) []string {
+	var players []string
+	for _, r := range results {
+		if r.Score >= billion {
+			players = append(players, r.PlayerName)
+		}
+	}
+	return players
+}
+
+func giantKiller(results []db.MatchResult) []string {
+	var players []string
+	for _, g := range games(results) {
+		if len(g) != 2 {
+			continue
+		}
+		winner, loser := g[0], g[1]
+		if loser.Points > winner.Points {
+			winner, loser = loser, winner
+		}
+		if winner.Points == loser.Points || winner.IPR == 0 {
+			continue
+		}
+		if loser.IPR > winner.IPR {
+			players = append(players, winner.PlayerName)
+		}
+	}
+	return players
+}
+
+func perfectNight(results []db.MatchResult) []string {
+	played := make(map[string]int)
+	topped := make(map[string]int)
+	var order []string
+	for _, g := range games(results) {
+		var best int64
+		for _, r := range g {
+			if played[r.PlayerName] == 0 {
+				order = append(order, r.PlayerName)
+			}
+			played[r.PlayerName]++
+			best = max(best, r.Score)
+		}
+		for _, r := range g {
+			if r.Score == best && best > 0 {
+				topped[r.PlayerName]++
+			}
+		}
+	}
+
+	var players []string
+	for _, p := range order {
+		if played[p] >= perfectNightGames && topped[p] == played[p] {
+			players = append(players, p)
+		}
+	}
+	return players
+}
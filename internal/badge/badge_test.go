@@ -0,0 +1,146 @@
+package badge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockListPlayedResults func(ctx context.Context, from, to string) ([]db.PlayedResult, error)
+	MockSaveBadges        func(ctx context.Context, badges []db.Badge) (int, error)
+}
+
+func (m *MockStore) ListPlayedResults(ctx context.Context, from, to string) ([]db.PlayedResult, error) {
+	return m.MockListPlayedResults(ctx, from, to)
+}
+
+func (m *MockStore) SaveBadges(ctx context.Context, badges []db.Badge) (int, error) {
+	return m.MockSaveBadges(ctx, badges)
+}
+
+func TestAward(t *testing.T) {
+	result := func(match string, game int64, player string, ipr int, score int64, points float64) db.PlayedResult {
+		return db.PlayedResult{
+			MatchKey:    match,
+			Date:        map[string]string{"m1": "2024-01-15", "m2": "2024-01-22"}[match],
+			MatchResult: db.MatchResult{GameID: game, PlayerName: player, IPR: ipr, Score: score, Points: points},
+		}
+	}
+
+	results := []db.PlayedResult{
+		// Alice tops all three of her games, beating a higher rated Carol in
+		// singles along the way.
+		result("m1", 1, "Alice", 3, 500, 2.5),
+		result("m1", 1, "Bob", 4, 400, 2),
+		result("m1", 1, "Carol", 5, 300, 0.5),
+		result("m1", 1, "Dave", 2, 200, 0),
+		result("m1", 2, "Alice", 3, 600, 3),
+		result("m1", 2, "Carol", 5, 100, 0),
+		result("m1", 3, "Alice", 3, 900, 2.5),
+		result("m1", 3, "Bob", 4, 100, 1),
+		result("m1", 3, "Carol", 5, 800, 1.5),
+		result("m1", 3, "Dave", 2, 50, 0),
+		// Bob scores a billion twice, but only earns the badge once. Dave
+		// beats a higher rated Carol, but a lower rated Alice beating Dave
+		// doesn't count as beating a giant.
+		result("m2", 4, "Bob", 4, 1_500_000_000, 3),
+		result("m2", 4, "Dave", 2, 10, 0),
+		result("m2", 5, "Dave", 2, 300, 3),
+		result("m2", 5, "Carol", 5, 200, 0),
+		result("m2", 6, "Bob", 4, 2_000_000_000, 3),
+		result("m2", 6, "Alice", 3, 10, 0),
+	}
+
+	type want struct {
+		badges []db.Badge
+		n      int
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  func(saved *[]db.Badge) Store
+		want   want
+	}{
+		"Awarded": {
+			reason: "Each player should be awarded each badge they earned once, for the first match they earned it in.",
+			store: func(saved *[]db.Badge) Store {
+				return &MockStore{
+					MockListPlayedResults: func(_ context.Context, _, _ string) ([]db.PlayedResult, error) { return results, nil },
+					MockSaveBadges: func(_ context.Context, badges []db.Badge) (int, error) {
+						*saved = badges
+						return len(badges), nil
+					},
+				}
+			},
+			want: want{
+				badges: []db.Badge{
+					{PlayerName: "Alice", Badge: "giant-killer", MatchKey: "m1", EarnedOn: "2024-01-15"},
+					{PlayerName: "Alice", Badge: "perfect-night", MatchKey: "m1", EarnedOn: "2024-01-15"},
+					{PlayerName: "Bob", Badge: "billion", MatchKey: "m2", EarnedOn: "2024-01-22"},
+					{PlayerName: "Dave", Badge: "giant-killer", MatchKey: "m2", EarnedOn: "2024-01-22"},
+				},
+				n: 4,
+			},
+		},
+		"ListPlayedResultsError": {
+			reason: "An error loading results should be returned.",
+			store: func(_ *[]db.Badge) Store {
+				return &MockStore{
+					MockListPlayedResults: func(_ context.Context, _, _ string) ([]db.PlayedResult, error) { return nil, errors.New("boom") },
+				}
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"SaveBadgesError": {
+			reason: "An error saving badges should be returned.",
+			store: func(_ *[]db.Badge) Store {
+				return &MockStore{
+					MockListPlayedResults: func(_ context.Context, _, _ string) ([]db.PlayedResult, error) { return results, nil },
+					MockSaveBadges: func(_ context.Context, _ []db.Badge) (int, error) {
+						return 0, errors.New("boom")
+					},
+				}
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var saved []db.Badge
+			n, err := Award(context.Background(), tc.store(&saved), Rules())
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAward(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.n, n); diff != "" {
+				t.Errorf("\n%s\nAward(...): -want awarded, +got awarded:\n%s", tc.reason, diff)
+			}
+			if tc.want.err == nil {
+				if diff := cmp.Diff(tc.want.badges, saved); diff != "" {
+					t.Errorf("\n%s\nAward(...): -want saved badges, +got saved badges:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	badges := []db.Badge{
+		{PlayerName: "Alice", Badge: "billion", MatchKey: "m1"},
+		{PlayerName: "Alice", Badge: "retired", MatchKey: "m1"},
+	}
+	want := []Earned{
+		{Badge: badges[0], Name: "Billionaire", Description: "Scored a billion points in a game."},
+	}
+	if diff := cmp.Diff(want, Describe(badges)); diff != "" {
+		t.Errorf("Describe(...): badges without a rule should be left out: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+type scoutKey struct {
+	team  string
+	venue string
+}
+
+type matchupKey struct {
+	venue string
+	team1 string
+	team2 string
+}
+
+// Stats describes the analysis cache, which also holds the team and player
+// stats analyses are computed from.
+type Stats struct {
+	Entries   int    // Analyses and stats currently cached.
+	Bytes     int64  // Approximate size of the cached analyses and stats.
+	MaxBytes  int64  // Approximate size past which entries are evicted.
+	Hits      uint64 // Analyses and stats served from the cache.
+	Misses    uint64 // Analyses and stats that had to be computed.
+	Evictions uint64 // Entries evicted to stay under MaxBytes.
+}
+
+// Stats returns the analysis cache's size and hit rate. Counts accumulate
+// across refreshes.
+func (s *InMemoryStore) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Stats{
+		Entries:   len(s.analyses.entries),
+		Bytes:     s.analyses.bytes,
+		MaxBytes:  s.analyses.maxBytes,
+		Hits:      s.analyses.hits,
+		Misses:    s.analyses.misses,
+		Evictions: s.analyses.evictions,
+	}
+}
+
+// Scout returns a scouting report for the supplied team, optionally limited to
+// the supplied venue's machines. Reports are cached until the next Refresh.
+func (s *InMemoryStore) Scout(ctx context.Context, team, venue string) (*scout.Result, error) {
+	k := scoutKey{team: team, venue: venue}
+	v, gen, ok := s.cached(k)
+	if ok {
+		return v.(*scout.Result), nil //nolint:forcetypeassert // Only scout results use scoutKey.
+	}
+
+	var opts []scout.Option
+	if venue != "" {
+		opts = append(opts, scout.AtVenue(venue))
+	}
+	r, err := scout.Analyze(ctx, s, team, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(k, r, gen)
+	return r, nil
+}
+
+// Matchup returns a matchup analysis of the supplied teams at the supplied
+// venue. Analyses are cached until the next Refresh.
+func (s *InMemoryStore) Matchup(ctx context.Context, venue, team1, team2 string) (*matchup.Result, error) {
+	k := matchupKey{venue: venue, team1: team1, team2: team2}
+	v, gen, ok := s.cached(k)
+	if ok {
+		return v.(*matchup.Result), nil //nolint:forcetypeassert // Only matchup results use matchupKey.
+	}
+
+	r, err := matchup.Analyze(ctx, s, venue, team1, team2)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(k, r, gen)
+	return r, nil
+}
+
+// cached returns the analysis cached for the supplied key, if any, and the
+// cache generation to pass to cache if it must be computed.
+func (s *InMemoryStore) cached(key any) (any, int, bool) {
+	// Lookups reorder the LRU, so they need the write lock.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.analyses.get(key)
+	return v, s.generation, ok
+}
+
+// cache caches an analysis computed during the supplied generation. It drops
+// the analysis if a Refresh ran while it was being computed, since it may be
+// stale.
+func (s *InMemoryStore) cache(key, value any, gen int) {
+	size := sizeOf(value)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != gen {
+		return
+	}
+	s.analyses.add(key, value, size)
+}
+
+// sizeOf approximates how much memory the supplied analysis uses by the size
+// of its JSON encoding. It's not exact, but it scales with the number of
+// machines and players in the analysis, which is what matters for the budget.
+func sizeOf(v any) int64 {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return int64(len(b))
+}
+
+// Warm computes and caches the analyses for every match in the next week of
+// the schedule on or after the supplied date: a scouting report of each team
+// at the match's venue, and the matchup at the venue. It returns how many
+// matches it warmed. Matches without a venue yet are skipped.
+func (s *InMemoryStore) Warm(ctx context.Context, date string) (int, error) {
+	sched, err := s.ListSchedule(ctx, date)
+	if err != nil {
+		return 0, fmt.Errorf("load schedule: %w", err)
+	}
+
+	n := 0
+	for _, m := range sched {
+		if m.Week != sched[0].Week {
+			break
+		}
+		if m.VenueKey == "" {
+			continue
+		}
+		for _, team := range []string{m.HomeTeamKey, m.AwayTeamKey} {
+			if _, err := s.Scout(ctx, team, m.VenueKey); err != nil {
+				return n, fmt.Errorf("scout %s at %s: %w", team, m.VenueKey, err)
+			}
+		}
+		if _, err := s.Matchup(ctx, m.VenueKey, m.HomeTeamKey, m.AwayTeamKey); err != nil {
+			return n, fmt.Errorf("match up %s and %s at %s: %w", m.HomeTeamKey, m.AwayTeamKey, m.VenueKey, err)
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// countingStore implements the queries the analyses need, counting how many
+// times team stats are loaded. Other Store methods panic.
+type countingStore struct {
+	Store
+
+	schedule []db.ScheduleMatch
+	loads    map[string]int
+}
+
+func (s *countingStore) ListSchedule(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+	return s.schedule, nil
+}
+
+func (s *countingStore) GetTeamMachineStats(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
+	s.loads[teamKey]++
+	return []db.TeamMachineStats{{MachineKey: "TAF", Games: 1}}, nil
+}
+
+func (s *countingStore) GetVenueMachines(_ context.Context, _ string, _ int) (map[string]bool, error) {
+	return map[string]bool{"TAF": true}, nil
+}
+
+func (s *countingStore) GetTeamAttendance(_ context.Context, _ string) (map[string]db.Attendance, error) {
+	return nil, nil
+}
+
+func TestWarm(t *testing.T) {
+	type want struct {
+		warmed int
+		loads  map[string]int
+		hits   uint64
+		misses uint64
+	}
+
+	cases := map[string]struct {
+		reason   string
+		schedule []db.ScheduleMatch
+		want     want
+	}{
+		"NextWeekOnly": {
+			reason: "Only the next week's matches should be warmed. Each team's stats load once, to scout, then the matchup reuses them.",
+			schedule: []db.ScheduleMatch{
+				{Week: 3, VenueKey: "ANC", HomeTeamKey: "CRA", AwayTeamKey: "PYC"},
+				{Week: 4, VenueKey: "ANC", HomeTeamKey: "CRA", AwayTeamKey: "DSV"},
+			},
+			want: want{warmed: 1, loads: map[string]int{"CRA": 1, "PYC": 1}, hits: 4, misses: 5},
+		},
+		"NoVenue": {
+			reason: "Matches without a venue should be skipped.",
+			schedule: []db.ScheduleMatch{
+				{Week: 3, HomeTeamKey: "CRA", AwayTeamKey: "PYC"},
+			},
+			want: want{warmed: 0, loads: map[string]int{}},
+		},
+		"NoSchedule": {
+			reason: "An empty schedule should warm nothing.",
+			want:   want{warmed: 0, loads: map[string]int{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cs := &countingStore{schedule: tc.schedule, loads: map[string]int{}}
+			s := NewInMemoryStore(cs)
+
+			got, err := s.Warm(context.Background(), "2025-01-01")
+			if err != nil {
+				t.Fatalf("\n%s\nWarm(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.warmed, got); diff != "" {
+				t.Errorf("\n%s\nWarm(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			// Analyses of warmed matches should now be served from memory.
+			for _, m := range tc.schedule {
+				if m.Week != 3 || m.VenueKey == "" {
+					continue
+				}
+				if _, err := s.Scout(context.Background(), m.HomeTeamKey, m.VenueKey); err != nil {
+					t.Fatalf("\n%s\nScout(...): unexpected error: %v", tc.reason, err)
+				}
+				if _, err := s.Matchup(context.Background(), m.VenueKey, m.HomeTeamKey, m.AwayTeamKey); err != nil {
+					t.Fatalf("\n%s\nMatchup(...): unexpected error: %v", tc.reason, err)
+				}
+			}
+			if diff := cmp.Diff(tc.want.loads, cs.loads); diff != "" {
+				t.Errorf("\n%s\nGetTeamMachineStats calls: -want, +got:\n%s", tc.reason, diff)
+			}
+			st := s.Stats()
+			if diff := cmp.Diff([]uint64{tc.want.hits, tc.want.misses}, []uint64{st.Hits, st.Misses}); diff != "" {
+				t.Errorf("\n%s\nStats(): -want hits and misses, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetTeamMachineStats(t *testing.T) {
+	ctx := context.Background()
+	cs := &countingStore{loads: map[string]int{}}
+	s := NewInMemoryStore(cs)
+
+	first, err := s.GetTeamMachineStats(ctx, "CRA", "", nil)
+	if err != nil {
+		t.Fatalf("GetTeamMachineStats(...): unexpected error: %v", err)
+	}
+	first[0].Games = 100
+
+	second, err := s.GetTeamMachineStats(ctx, "CRA", "", nil)
+	if err != nil {
+		t.Fatalf("GetTeamMachineStats(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]db.TeamMachineStats{{MachineKey: "TAF", Games: 1}}, second); diff != "" {
+		t.Errorf("GetTeamMachineStats(...): modifying one caller's stats shouldn't modify another's: -want, +got:\n%s", diff)
+	}
+
+	if _, err := s.GetTeamMachineStats(ctx, "CRA", "", []int{23}); err != nil {
+		t.Fatalf("GetTeamMachineStats(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]int{"CRA": 2}, cs.loads); diff != "" {
+		t.Errorf("GetTeamMachineStats(...): stats should load once for each set of seasons: -want, +got:\n%s", diff)
+	}
+}
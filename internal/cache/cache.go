@@ -3,16 +3,25 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/negz/mnp/internal/badge"
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/ipr"
+	"github.com/negz/mnp/internal/league"
 	"github.com/negz/mnp/internal/mnp"
+	"github.com/negz/mnp/internal/opdb"
+	"github.com/negz/mnp/internal/strategy/scout"
 )
 
-// Dir returns the MNP cache directory.
+// Dir returns the default MNP cache directory, used unless --cache-dir is set.
 //
 // It uses os.UserCacheDir, which respects XDG_CACHE_HOME on Linux, uses
 // ~/Library/Caches on macOS, and %LocalAppData% on Windows. If the user cache
@@ -28,11 +37,36 @@ func Dir() string {
 // DB provides access to an MNP database.
 // It lazily opens the database on first use.
 type DB struct {
-	ArchiveURL string `default:"https://github.com/Invader-Zim/mnp-data-archive.git" help:"MNP archive git repo URL."`
-	ForceSync  bool   `help:"Sync data before running command."                      name:"sync"                      short:"s"`
+	ArchiveURL         string  `default:"https://github.com/Invader-Zim/mnp-data-archive.git" help:"MNP archive git repo URL."`
+	CacheDir           string  `env:"MNP_CACHE_DIR"                                           help:"Directory to keep the database and archive clone in. Defaults to mnp under the user's cache directory."                                                type:"path"`
+	ForceSync          bool    `help:"Sync data before running command."                      name:"sync"                                                                                                                                                  short:"s"`
+	IPRSource          string  `env:"MNP_IPR_SOURCE"                                          help:"CSV or JSON file or URL of player IPRs to load once a day, instead of the archive's."`
+	LeagueFormats      string  `env:"MNP_LEAGUE_FORMATS"                                      help:"JSON file of the rounds each season played as doubles, overriding the built-in formats."                                                               type:"existingfile"`
+	MachineSource      string  `env:"MNP_MACHINE_SOURCE"                                      help:"Where to load machine manufacturers and years from once a day: opdb for OPDB's API, or a saved OPDB export JSON file or URL."`
+	OPDBToken          string  `env:"MNP_OPDB_TOKEN"                                          help:"OPDB API token, needed to load machines from OPDB's API."`
+	OpponentAdjustment float64 `default:"0"                                                   help:"Adjust each score by this fraction for each point of IPR its opponents average above or below the league's when computing P50 and P90, from 0 to 0.2."`
+	RecentWeight       float64 `default:"1"                                                   help:"Weight of each season's scores relative to the next season's when computing P50 and P90, from 0 to 1."`
 
 	log   *slog.Logger
-	store *db.SQLiteStore
+	store db.Store
+}
+
+// Dir returns the directory the database and archive clone are kept in.
+func (d *DB) Dir() string {
+	if d.CacheDir != "" {
+		return d.CacheDir
+	}
+	return Dir()
+}
+
+// ArchiveDir returns the directory the MNP archive is cloned into.
+func (d *DB) ArchiveDir() string {
+	return filepath.Join(d.Dir(), "mnp-data-archive")
+}
+
+// Path returns the path of the MNP database.
+func (d *DB) Path() string {
+	return filepath.Join(d.Dir(), "mnp.db")
 }
 
 // SetLogger configures the logger for sync progress.
@@ -43,19 +77,24 @@ func (d *DB) SetLogger(log *slog.Logger) {
 // Store returns the database store, opening it if needed. It does not sync
 // data from the archive. Use SyncedStore when the caller needs fresh data
 // before proceeding.
-func (d *DB) Store(ctx context.Context) (*db.SQLiteStore, error) {
+func (d *DB) Store(ctx context.Context) (db.Store, error) {
 	if d.store != nil {
 		return d.store, nil
 	}
 
-	cacheDir := Dir()
+	if d.RecentWeight <= 0 || d.RecentWeight > 1 {
+		return nil, fmt.Errorf("recent weight must be greater than 0 and at most 1, got %g", d.RecentWeight)
+	}
+
+	if d.OpponentAdjustment < 0 || d.OpponentAdjustment > 0.2 {
+		return nil, fmt.Errorf("opponent adjustment must be from 0 to 0.2, got %g", d.OpponentAdjustment)
+	}
 
-	if err := os.MkdirAll(cacheDir, 0o750); err != nil {
+	if err := os.MkdirAll(d.Dir(), 0o750); err != nil {
 		return nil, fmt.Errorf("create cache directory: %w", err)
 	}
 
-	dbPath := filepath.Join(cacheDir, "mnp.db")
-	store, err := db.Open(ctx, dbPath)
+	store, err := db.Open(ctx, d.Path(), db.WithRecencyWeight(d.RecentWeight), db.WithOpponentAdjustment(d.OpponentAdjustment))
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -70,7 +109,7 @@ func (d *DB) Store(ctx context.Context) (*db.SQLiteStore, error) {
 }
 
 // SyncedStore returns the database store, syncing data from the archive first.
-func (d *DB) SyncedStore(ctx context.Context) (*db.SQLiteStore, error) {
+func (d *DB) SyncedStore(ctx context.Context) (db.Store, error) {
 	store, err := d.Store(ctx)
 	if err != nil {
 		return nil, err
@@ -85,6 +124,22 @@ func (d *DB) SyncedStore(ctx context.Context) (*db.SQLiteStore, error) {
 	return store, nil
 }
 
+// BlendedStore opens another store on the database whose P50 and P90 stats
+// weight each season's scores per scout.BlendWeight, rather than RecentWeight,
+// for scouting with scout.Blended. It opens the database first if needed. The
+// caller must close the blended store.
+func (d *DB) BlendedStore(ctx context.Context) (db.Store, error) {
+	if _, err := d.Store(ctx); err != nil {
+		return nil, err
+	}
+
+	store, err := db.Open(ctx, d.Path(), db.WithRecencyWeight(scout.BlendWeight), db.WithOpponentAdjustment(d.OpponentAdjustment))
+	if err != nil {
+		return nil, fmt.Errorf("open blended database: %w", err)
+	}
+	return store, nil
+}
+
 // Close closes the database connection.
 func (d *DB) Close() error {
 	if d.store == nil {
@@ -93,16 +148,175 @@ func (d *DB) Close() error {
 	return d.store.Close()
 }
 
-// Sync synchronizes data from the MNP data archive.
-// It respects staleness unless ForceSync is set.
+// Import replaces the database with a copy of one written by db.Export, so a
+// new install doesn't need to load every season from the archive. The next
+// sync only loads what changed since the copy was exported. It must not be
+// called while another process, such as mnp serve, has the database open.
+func (d *DB) Import(ctx context.Context, path string) error {
+	if err := db.CheckExport(ctx, path); err != nil {
+		return err
+	}
+
+	if err := d.Close(); err != nil {
+		return fmt.Errorf("close database: %w", err)
+	}
+	d.store = nil
+
+	if err := os.MkdirAll(d.Dir(), 0o750); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	// Copy next to the database then rename, so an interrupted import leaves
+	// the old database intact.
+	tmp := d.Path() + ".import"
+	if err := copyFile(path, tmp); err != nil {
+		os.Remove(tmp) //nolint:errcheck // Already returning an error.
+		return fmt.Errorf("copy %s: %w", path, err)
+	}
+
+	// The old database's write-ahead log would be applied to the new one.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(d.Path() + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove old database's %s file: %w", suffix, err)
+		}
+	}
+
+	if err := os.Rename(tmp, d.Path()); err != nil {
+		return fmt.Errorf("replace database: %w", err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // The user chose the file to import.
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck // Read-only file.
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() //nolint:errcheck // Already returning an error.
+		return err
+	}
+	return out.Close()
+}
+
+// Formats returns the format each season was played in: the built-in formats,
+// overridden by LeagueFormats if set.
+func (d *DB) Formats() (league.Formats, error) {
+	return league.Load(d.LeagueFormats)
+}
+
+// Sync synchronizes data from the MNP data archive, and IPRSource and
+// MachineSource if set, then awards players any badges they earned in newly
+// loaded matches and rebuilds the search index and materialized stats. It
+// respects staleness unless ForceSync is set. It records when it ran, how long
+// it took, and whether it failed.
 func (d *DB) Sync(ctx context.Context) error {
-	archivePath := filepath.Join(Dir(), "mnp-data-archive")
+	started := time.Now()
+	err := d.sync(ctx)
+	if d.store != nil {
+		if rerr := d.store.RecordSyncAttempt(ctx, started, time.Since(started), err); rerr != nil {
+			d.log.Warn("Failed to record sync attempt", "error", rerr)
+		}
+	}
+	return err
+}
 
-	mnpClient := mnp.NewClient(archivePath,
+func (d *DB) sync(ctx context.Context) error {
+	formats, err := d.Formats()
+	if err != nil {
+		return err
+	}
+
+	opts := []mnp.ClientOption{
 		mnp.WithRepoURL(d.ArchiveURL),
 		mnp.WithLogger(d.log),
 		mnp.WithStore(d.store),
+		mnp.WithFormats(formats),
+	}
+	// The archive's IPRs would overwrite IPRSource's every sync, though
+	// IPRSource is only loaded once a day.
+	if d.IPRSource != "" {
+		opts = append(opts, mnp.WithoutIPRs())
+	}
+	mnpClient := mnp.NewClient(d.ArchiveDir(), opts...)
+
+	if err := mnpClient.SyncIfStale(ctx, d.ForceSync); err != nil {
+		return err
+	}
+
+	if d.IPRSource != "" {
+		n, synced, err := ipr.SyncIfStale(ctx, d.store, d.IPRSource, d.ForceSync)
+		switch {
+		case err != nil:
+			d.log.Warn("Failed to sync IPRs", "source", d.IPRSource, "error", err)
+		case synced:
+			d.log.Info("Synced IPRs", "source", d.IPRSource, "count", n)
+		}
+	}
+
+	if d.MachineSource != "" {
+		r, err := opdb.SyncIfStale(ctx, d.store, d.MachineSource, d.ForceSync, opdb.WithToken(d.OPDBToken))
+		switch {
+		case err != nil:
+			d.log.Warn("Failed to sync machine details", "source", d.MachineSource, "error", err)
+		case r != nil:
+			d.log.Info("Synced machine details", "source", d.MachineSource, "matched", r.Matched, "fuzzy", len(r.Fuzzy), "duplicates", len(r.Duplicates), "unmatched", len(r.Unmatched))
+		}
+	}
+
+	n, err := badge.Award(ctx, d.store, badge.Rules())
+	if err != nil {
+		return fmt.Errorf("award badges: %w", err)
+	}
+	d.log.Info("Awarded badges", "count", n)
+
+	if err := d.store.RebuildSearchIndex(ctx); err != nil {
+		return fmt.Errorf("rebuild search index: %w", err)
+	}
+
+	if err := d.store.RebuildStats(ctx); err != nil {
+		return fmt.Errorf("rebuild stats: %w", err)
+	}
+	return nil
+}
+
+// Retransform loads the supplied seasons' matches, or every season's, again
+// from the match JSON stored by the last sync, then awards players any badges
+// they earned and rebuilds the search index and materialized stats. It doesn't
+// sync the archive. It returns how many matches it loaded.
+func (d *DB) Retransform(ctx context.Context, seasons ...int) (int, error) {
+	formats, err := d.Formats()
+	if err != nil {
+		return 0, err
+	}
+
+	mnpClient := mnp.NewClient(d.ArchiveDir(),
+		mnp.WithLogger(d.log),
+		mnp.WithStore(d.store),
+		mnp.WithFormats(formats),
 	)
 
-	return mnpClient.SyncIfStale(ctx, d.ForceSync)
+	n, err := mnpClient.Retransform(ctx, seasons...)
+	if err != nil {
+		return n, err
+	}
+
+	if _, err := badge.Award(ctx, d.store, badge.Rules()); err != nil {
+		return n, fmt.Errorf("award badges: %w", err)
+	}
+
+	if err := d.store.RebuildSearchIndex(ctx); err != nil {
+		return n, fmt.Errorf("rebuild search index: %w", err)
+	}
+
+	if err := d.store.RebuildStats(ctx); err != nil {
+		return n, fmt.Errorf("rebuild stats: %w", err)
+	}
+	return n, nil
 }
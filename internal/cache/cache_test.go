@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// exported returns the path of an exported database with season 23's week 1
+// loaded.
+func exported(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	s, err := db.Open(ctx, filepath.Join(t.TempDir(), "source.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close() //nolint:errcheck // Test cleanup.
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	season, err := s.UpsertSeason(ctx, 23)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	home, err := s.UpsertTeam(ctx, db.Team{Key: "TTT", Name: "The Trailer Trashers", SeasonID: season})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	away, err := s.UpsertTeam(ctx, db.Team{Key: "KNR", Name: "Knight Riders", SeasonID: season})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	if _, err := s.UpsertMatch(ctx, db.Match{Key: "mnp-23-1-TTT-KNR", SeasonID: season, Week: 1, HomeTeamID: home, AwayTeamID: away}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "mnp.db")
+	if err := s.Export(ctx, path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	return path
+}
+
+func TestImport(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ctx := context.Background()
+
+	d := &DB{RecentWeight: 1}
+	defer d.Close() //nolint:errcheck // Test cleanup.
+
+	// Open the database to be replaced.
+	if _, err := d.Store(ctx); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	if err := d.Import(ctx, filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Errorf("Import(...): want error importing a missing file, got nil")
+	}
+
+	if err := d.Import(ctx, exported(t)); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	s, err := d.Store(ctx)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	got, err := s.LoadedSeasons(ctx)
+	if err != nil {
+		t.Fatalf("LoadedSeasons: %v", err)
+	}
+	if diff := cmp.Diff(map[int]bool{23: true}, got); diff != "" {
+		t.Errorf("LoadedSeasons() after Import(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestBlendedStore(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ctx := context.Background()
+
+	d := &DB{RecentWeight: 1}
+	defer d.Close() //nolint:errcheck // Test cleanup.
+
+	if err := d.Import(ctx, exported(t)); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	s, err := d.BlendedStore(ctx)
+	if err != nil {
+		t.Fatalf("BlendedStore: %v", err)
+	}
+	defer s.Close() //nolint:errcheck // Test cleanup.
+
+	got, err := s.LoadedSeasons(ctx)
+	if err != nil {
+		t.Fatalf("LoadedSeasons: %v", err)
+	}
+	if diff := cmp.Diff(map[int]bool{23: true}, got); diff != "" {
+		t.Errorf("BlendedStore(...).LoadedSeasons(): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"container/list"
+)
+
+// An lru is a size-bounded cache that evicts the least recently used entries.
+// It isn't safe for concurrent use.
+type lru struct {
+	maxBytes int64
+	bytes    int64
+	order    *list.List // Most recently used at the front.
+	entries  map[any]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type lruEntry struct {
+	key   any
+	value any
+	size  int64
+}
+
+func newLRU(maxBytes int64) *lru {
+	return &lru{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[any]*list.Element),
+	}
+}
+
+// get returns the value cached for the supplied key, if any, and marks it most
+// recently used.
+func (c *lru) get(key any) (any, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true //nolint:forcetypeassert // Only lruEntry is stored.
+}
+
+// add caches the supplied value, which is about size bytes, evicting the least
+// recently used values until the cache fits. Values bigger than the whole
+// cache aren't cached.
+func (c *lru) add(key, value any, size int64) {
+	if size > c.maxBytes {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, size: size})
+	c.bytes += size
+
+	for c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+func (c *lru) remove(e *list.Element) {
+	le := c.order.Remove(e).(*lruEntry) //nolint:forcetypeassert // Only lruEntry is stored.
+	delete(c.entries, le.key)
+	c.bytes -= le.size
+}
+
+// clear removes every entry. Hit, miss, and eviction counts are kept.
+func (c *lru) clear() {
+	c.order.Init()
+	c.entries = make(map[any]*list.Element)
+	c.bytes = 0
+}
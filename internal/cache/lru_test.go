@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLRU(t *testing.T) {
+	type add struct {
+		key  string
+		size int64
+	}
+	type want struct {
+		keys      []string // Still cached, most recently used first.
+		bytes     int64
+		evictions uint64
+	}
+
+	cases := map[string]struct {
+		reason string
+		adds   []add
+		get    string // Used between the adds and the last add.
+		want   want
+	}{
+		"UnderBudget": {
+			reason: "Nothing should be evicted while the cache fits.",
+			adds:   []add{{"a", 3}, {"b", 3}, {"c", 4}},
+			want:   want{keys: []string{"c", "b", "a"}, bytes: 10},
+		},
+		"EvictLeastRecentlyUsed": {
+			reason: "The least recently added entries should be evicted once the cache is over budget.",
+			adds:   []add{{"a", 4}, {"b", 4}, {"c", 4}},
+			want:   want{keys: []string{"c", "b"}, bytes: 8, evictions: 1},
+		},
+		"GetMarksUsed": {
+			reason: "Getting an entry should protect it from eviction.",
+			adds:   []add{{"a", 4}, {"b", 4}, {"c", 4}},
+			get:    "a",
+			want:   want{keys: []string{"c", "a"}, bytes: 8, evictions: 1},
+		},
+		"TooBig": {
+			reason: "An entry bigger than the whole budget shouldn't be cached, or evict anything.",
+			adds:   []add{{"a", 4}, {"b", 11}},
+			want:   want{keys: []string{"a"}, bytes: 4},
+		},
+		"Replace": {
+			reason: "Adding an existing key should replace its entry and size.",
+			adds:   []add{{"a", 4}, {"a", 6}},
+			want:   want{keys: []string{"a"}, bytes: 6},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newLRU(10)
+			for i, a := range tc.adds {
+				if tc.get != "" && i == len(tc.adds)-1 {
+					c.get(tc.get)
+				}
+				c.add(a.key, a.key, a.size)
+			}
+
+			var keys []string
+			for e := c.order.Front(); e != nil; e = e.Next() {
+				keys = append(keys, e.Value.(*lruEntry).key.(string))
+			}
+			if diff := cmp.Diff(tc.want.keys, keys); diff != "" {
+				t.Errorf("\n%s\nkeys: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.bytes, c.bytes); diff != "" {
+				t.Errorf("\n%s\nbytes: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.evictions, c.evictions); diff != "" {
+				t.Errorf("\n%s\nevictions: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -2,34 +2,77 @@ package cache
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/negz/mnp/internal/anomaly"
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/accuracy"
+	"github.com/negz/mnp/internal/strategy/backtest"
+	"github.com/negz/mnp/internal/strategy/history"
 	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/pickem"
 	"github.com/negz/mnp/internal/strategy/player"
+	"github.com/negz/mnp/internal/strategy/recap"
 	"github.com/negz/mnp/internal/strategy/recommend"
+	"github.com/negz/mnp/internal/strategy/report"
+	"github.com/negz/mnp/internal/strategy/roster"
 	"github.com/negz/mnp/internal/strategy/scout"
+	"github.com/negz/mnp/internal/strategy/seasons"
+	"github.com/negz/mnp/internal/strategy/simulate"
+	"github.com/negz/mnp/internal/strategy/streaks"
 )
 
 // Store is the set of queries needed by the web UI. It composes the strategy
 // package store interfaces with the list queries used to populate dropdowns.
-type Store interface { //nolint:interfacebloat // Composes four strategy store interfaces plus list queries.
+type Store interface { //nolint:interfacebloat // Composes the strategy store interfaces plus list queries.
 	scout.Store
 	matchup.Store
 	recommend.Store
 	player.Store
-
-	ListTeams(ctx context.Context, search string) ([]db.TeamSummary, error)
+	recap.Store
+	accuracy.Store
+	backtest.Store
+	anomaly.Store
+	seasons.Store
+	history.Store
+	pickem.Store
+	streaks.Store
+	roster.Store
+	report.Store
+	simulate.Store
+
+	ListTeams(ctx context.Context, search string, season int) ([]db.TeamSummary, error)
 	ListPlayers(ctx context.Context, search string) ([]db.PlayerSummary, error)
 	ListVenues(ctx context.Context, search string) ([]db.Venue, error)
+	ListVenueSummaries(ctx context.Context, search string) ([]db.VenueSummary, error)
 	ListMachines(ctx context.Context, search string) ([]db.Machine, error)
+	ListMachineSummaries(ctx context.Context, search string) ([]db.MachineSummary, error)
+	GetMachineYears(ctx context.Context) (map[string]int, error)
 	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+	ListRosterRetention(ctx context.Context) ([]db.RosterRetention, error)
+	ListRosterEvents(ctx context.Context, teamKey string) ([]db.RosterEvent, error)
+	ListSeasons(ctx context.Context) ([]int, error)
+	Search(ctx context.Context, query string) ([]db.SearchResult, error)
+	GetStandings(ctx context.Context, season int) ([]db.Standing, error)
+	GetFreshness(ctx context.Context) (db.Freshness, error)
+	GetSyncStatus(ctx context.Context) (db.SyncStatus, error)
+	GetMatchDetail(ctx context.Context, matchKey string) (*db.MatchDetail, error)
+	GetGameLog(ctx context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error)
+	GetLeaderboard(ctx context.Context, machineKey, venueKey string, season, top int) ([]db.TopScore, error)
+	ListPicks(ctx context.Context, picker string) ([]db.Pick, error)
+	UpsertPick(ctx context.Context, p db.Pick) error
+	ClaimPicker(ctx context.Context, name, token string) error
 }
 
 // An InMemoryStore wraps a Store, caching data that only changes when a sync
 // runs. Cached methods serve from memory. All other methods pass through to
-// the underlying store. Call Refresh after each sync to repopulate the cache.
+// the underlying store. Scout and matchup analyses, and the team and player
+// stats they're computed from, are cached as they're computed. Call Refresh
+// after each sync to repopulate the cache.
 type InMemoryStore struct {
 	wrapped Store
 
@@ -38,19 +81,43 @@ type InMemoryStore struct {
 	venues       []db.Venue
 	machines     []db.Machine
 	players      []db.PlayerSummary
+	playerNames  []string
 	leagueP50    map[string]float64
+	leagueScores map[string]db.LeagueScores
 	machineNames map[string]string
+	freshness    db.Freshness
+	analyses     *lru
+	generation   int // Incremented by each Refresh.
+}
+
+// DefaultMaxBytes is the default approximate size of the analyses an
+// InMemoryStore caches.
+const DefaultMaxBytes = 64 << 20
+
+// An Option configures an InMemoryStore.
+type Option func(*InMemoryStore)
+
+// WithMaxBytes sets the approximate maximum size of cached analyses. The least
+// recently used analyses are evicted when the cache grows past this size.
+func WithMaxBytes(n int64) Option {
+	return func(s *InMemoryStore) {
+		s.analyses = newLRU(n)
+	}
 }
 
 // NewInMemoryStore returns an InMemoryStore that caches slow-changing data in
 // memory.
-func NewInMemoryStore(s Store) *InMemoryStore {
-	return &InMemoryStore{wrapped: s}
+func NewInMemoryStore(s Store, opts ...Option) *InMemoryStore {
+	c := &InMemoryStore{wrapped: s, analyses: newLRU(DefaultMaxBytes)}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
 }
 
 // Refresh repopulates the in-memory cache from the underlying store.
 func (s *InMemoryStore) Refresh(ctx context.Context) error {
-	teams, err := s.wrapped.ListTeams(ctx, "")
+	teams, err := s.wrapped.ListTeams(ctx, "", 0)
 	if err != nil {
 		return err
 	}
@@ -70,16 +137,31 @@ func (s *InMemoryStore) Refresh(ctx context.Context) error {
 		return err
 	}
 
+	playerNames, err := s.wrapped.ListPlayerNames(ctx)
+	if err != nil {
+		return err
+	}
+
 	leagueP50, err := s.wrapped.GetLeagueP50(ctx)
 	if err != nil {
 		return err
 	}
 
+	leagueScores, err := s.wrapped.GetLeagueScores(ctx)
+	if err != nil {
+		return err
+	}
+
 	machineNames, err := s.wrapped.GetMachineNames(ctx)
 	if err != nil {
 		return err
 	}
 
+	freshness, err := s.wrapped.GetFreshness(ctx)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -87,16 +169,29 @@ func (s *InMemoryStore) Refresh(ctx context.Context) error {
 	s.venues = venues
 	s.machines = machines
 	s.players = players
+	s.playerNames = playerNames
 	s.leagueP50 = leagueP50
+	s.leagueScores = leagueScores
 	s.machineNames = machineNames
+	s.freshness = freshness
+
+	// Analyses were computed from the old data.
+	s.analyses.clear()
+	s.generation++
 
 	return nil
 }
 
 // Cached methods.
 
-// ListTeams returns teams from the cache, optionally filtered by search term.
-func (s *InMemoryStore) ListTeams(_ context.Context, search string) ([]db.TeamSummary, error) {
+// ListTeams returns the current season's teams from the cache, optionally
+// filtered by search term. Other seasons' teams pass through to the underlying
+// store.
+func (s *InMemoryStore) ListTeams(ctx context.Context, search string, season int) ([]db.TeamSummary, error) {
+	if season != 0 {
+		return s.wrapped.ListTeams(ctx, search, season)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -171,6 +266,13 @@ func (s *InMemoryStore) ListPlayers(_ context.Context, search string) ([]db.Play
 	return out, nil
 }
 
+// ListPlayerNames returns every player's name from the cache.
+func (s *InMemoryStore) ListPlayerNames(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.playerNames, nil
+}
+
 // GetLeagueP50 returns league-wide P50 scores from the cache.
 func (s *InMemoryStore) GetLeagueP50(_ context.Context) (map[string]float64, error) {
 	s.mu.RLock()
@@ -178,6 +280,13 @@ func (s *InMemoryStore) GetLeagueP50(_ context.Context) (map[string]float64, err
 	return s.leagueP50, nil
 }
 
+// GetLeagueScores returns every league score on each machine from the cache.
+func (s *InMemoryStore) GetLeagueScores(_ context.Context) (map[string]db.LeagueScores, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.leagueScores, nil
+}
+
 // GetMachineNames returns machine key-to-name mappings from the cache.
 func (s *InMemoryStore) GetMachineNames(_ context.Context) (map[string]string, error) {
 	s.mu.RLock()
@@ -185,26 +294,89 @@ func (s *InMemoryStore) GetMachineNames(_ context.Context) (map[string]string, e
 	return s.machineNames, nil
 }
 
+// GetFreshness returns how up to date the archive data is from the cache.
+func (s *InMemoryStore) GetFreshness(_ context.Context) (db.Freshness, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.freshness, nil
+}
+
+type teamStatsKey struct {
+	team    string
+	venue   string
+	seasons string
+}
+
+type playerStatsKey struct {
+	team    string
+	machine string
+	venue   string
+	seasons string
+}
+
+// GetTeamMachineStats returns a team's stats on each machine. Stats are
+// computed by the underlying store, then cached alongside analyses until the
+// next Refresh. Callers get their own copy of the slice, so they may modify it.
+func (s *InMemoryStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	k := teamStatsKey{team: teamKey, venue: venueKey, seasons: fmt.Sprint(seasons)}
+	v, gen, ok := s.cached(k)
+	if ok {
+		return slices.Clone(v.([]db.TeamMachineStats)), nil //nolint:forcetypeassert // Only team stats use teamStatsKey.
+	}
+
+	stats, err := s.wrapped.GetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(k, stats, gen)
+	return slices.Clone(stats), nil
+}
+
+// GetPlayerMachineStats returns a team's players' stats on a machine. Stats
+// are computed by the underlying store, then cached alongside analyses until
+// the next Refresh. Callers get their own copy of the slice, so they may
+// modify it.
+func (s *InMemoryStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]db.PlayerStats, error) {
+	k := playerStatsKey{team: teamKey, machine: machineKey, venue: venueKey, seasons: fmt.Sprint(seasons)}
+	v, gen, ok := s.cached(k)
+	if ok {
+		return slices.Clone(v.([]db.PlayerStats)), nil //nolint:forcetypeassert // Only player stats use playerStatsKey.
+	}
+
+	stats, err := s.wrapped.GetPlayerMachineStats(ctx, teamKey, machineKey, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(k, stats, gen)
+	return slices.Clone(stats), nil
+}
+
 // Passthrough methods.
 
+// GetSyncStatus passes through to the underlying store, so that failed syncs,
+// which don't refresh the cache, are reported.
+func (s *InMemoryStore) GetSyncStatus(ctx context.Context) (db.SyncStatus, error) {
+	return s.wrapped.GetSyncStatus(ctx)
+}
+
 // ListSchedule passes through to the underlying store.
 func (s *InMemoryStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
 	return s.wrapped.ListSchedule(ctx, after)
 }
 
-// GetTeamMachineStats passes through to the underlying store.
-func (s *InMemoryStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error) {
-	return s.wrapped.GetTeamMachineStats(ctx, teamKey, venueKey)
+// GetVenueMachines passes through to the underlying store.
+func (s *InMemoryStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return s.wrapped.GetVenueMachines(ctx, venueKey, season)
 }
 
-// GetVenueMachines passes through to the underlying store.
-func (s *InMemoryStore) GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error) {
-	return s.wrapped.GetVenueMachines(ctx, venueKey)
+// GetExternalMachineStats passes through to the underlying store.
+func (s *InMemoryStore) GetExternalMachineStats(ctx context.Context, venueKey, machineKey string) ([]db.ExternalStats, error) {
+	return s.wrapped.GetExternalMachineStats(ctx, venueKey, machineKey)
 }
 
-// GetPlayerMachineStats passes through to the underlying store.
-func (s *InMemoryStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string) ([]db.PlayerStats, error) {
-	return s.wrapped.GetPlayerMachineStats(ctx, teamKey, machineKey, venueKey)
+// GetPlayerMachinePercentiles passes through to the underlying store.
+func (s *InMemoryStore) GetPlayerMachinePercentiles(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error) {
+	return s.wrapped.GetPlayerMachinePercentiles(ctx, teamKey, machineKey, venueKey, seasons, percentiles)
 }
 
 // GetPlayer passes through to the underlying store.
@@ -212,7 +384,237 @@ func (s *InMemoryStore) GetPlayer(ctx context.Context, playerName string) (db.Pl
 	return s.wrapped.GetPlayer(ctx, playerName)
 }
 
+// GetPlayerHomeAwaySplits passes through to the underlying store.
+func (s *InMemoryStore) GetPlayerHomeAwaySplits(ctx context.Context, playerName string, seasons []int) (map[string]db.HomeAwaySplit, error) {
+	return s.wrapped.GetPlayerHomeAwaySplits(ctx, playerName, seasons)
+}
+
 // GetSinglePlayerMachineStats passes through to the underlying store.
-func (s *InMemoryStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string) ([]db.PlayerMachineStats, error) {
-	return s.wrapped.GetSinglePlayerMachineStats(ctx, playerName, venueKey)
+func (s *InMemoryStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error) {
+	return s.wrapped.GetSinglePlayerMachineStats(ctx, playerName, venueKey, seasons)
+}
+
+// ListPlayedMatches passes through to the underlying store.
+func (s *InMemoryStore) ListPlayedMatches(ctx context.Context, teamKey string) ([]db.PlayedMatch, error) {
+	return s.wrapped.ListPlayedMatches(ctx, teamKey)
+}
+
+// Search passes through to the underlying store.
+func (s *InMemoryStore) Search(ctx context.Context, query string) ([]db.SearchResult, error) {
+	return s.wrapped.Search(ctx, query)
+}
+
+// ListTeamRosterScores passes through to the underlying store.
+func (s *InMemoryStore) ListTeamRosterScores(ctx context.Context, teamKey string) ([]db.RosterScore, error) {
+	return s.wrapped.ListTeamRosterScores(ctx, teamKey)
+}
+
+// GetMatchDetail passes through to the underlying store.
+func (s *InMemoryStore) GetMatchDetail(ctx context.Context, matchKey string) (*db.MatchDetail, error) {
+	return s.wrapped.GetMatchDetail(ctx, matchKey)
+}
+
+// ListPickOutcomes passes through to the underlying store.
+func (s *InMemoryStore) ListPickOutcomes(ctx context.Context) ([]db.PickOutcome, error) {
+	return s.wrapped.ListPickOutcomes(ctx)
+}
+
+// ListPicks passes through to the underlying store.
+func (s *InMemoryStore) ListPicks(ctx context.Context, picker string) ([]db.Pick, error) {
+	return s.wrapped.ListPicks(ctx, picker)
+}
+
+// UpsertPick passes through to the underlying store.
+func (s *InMemoryStore) UpsertPick(ctx context.Context, p db.Pick) error {
+	return s.wrapped.UpsertPick(ctx, p)
+}
+
+// ClaimPicker passes through to the underlying store.
+func (s *InMemoryStore) ClaimPicker(ctx context.Context, name, token string) error {
+	return s.wrapped.ClaimPicker(ctx, name, token)
+}
+
+// ListMatchResults passes through to the underlying store.
+func (s *InMemoryStore) ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error) {
+	return s.wrapped.ListMatchResults(ctx, matchKey)
+}
+
+// ListPredictionOutcomes passes through to the underlying store.
+func (s *InMemoryStore) ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error) {
+	return s.wrapped.ListPredictionOutcomes(ctx)
+}
+
+// GetLatestPlayedWeek passes through to the underlying store.
+func (s *InMemoryStore) GetLatestPlayedWeek(ctx context.Context) (int, error) {
+	return s.wrapped.GetLatestPlayedWeek(ctx)
+}
+
+// ListTeamGameCounts passes through to the underlying store.
+func (s *InMemoryStore) ListTeamGameCounts(ctx context.Context) ([]db.TeamGameCount, error) {
+	return s.wrapped.ListTeamGameCounts(ctx)
+}
+
+// ListVenuesWithoutMachines passes through to the underlying store.
+func (s *InMemoryStore) ListVenuesWithoutMachines(ctx context.Context) ([]db.Venue, error) {
+	return s.wrapped.ListVenuesWithoutMachines(ctx)
+}
+
+// ListPlayerP50s passes through to the underlying store.
+func (s *InMemoryStore) ListPlayerP50s(ctx context.Context) ([]db.PlayerP50, error) {
+	return s.wrapped.ListPlayerP50s(ctx)
+}
+
+// GetP50Snapshot passes through to the underlying store.
+func (s *InMemoryStore) GetP50Snapshot(ctx context.Context) ([]db.PlayerP50, time.Time, error) {
+	return s.wrapped.GetP50Snapshot(ctx)
+}
+
+// ListTeamSeasons passes through to the underlying store.
+func (s *InMemoryStore) ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error) {
+	return s.wrapped.ListTeamSeasons(ctx, teamKey)
+}
+
+// GetTeamSeasonMachineStats passes through to the underlying store.
+func (s *InMemoryStore) GetTeamSeasonMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error) {
+	return s.wrapped.GetTeamSeasonMachineStats(ctx, teamKey, season)
+}
+
+// GetTeamSeasonRosterMachineStats passes through to the underlying store.
+func (s *InMemoryStore) GetTeamSeasonRosterMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error) {
+	return s.wrapped.GetTeamSeasonRosterMachineStats(ctx, teamKey, season)
+}
+
+// GetTeamSeasonRoster passes through to the underlying store.
+func (s *InMemoryStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	return s.wrapped.GetTeamSeasonRoster(ctx, teamKey, season)
+}
+
+// GetTeamSeasonPoints passes through to the underlying store.
+func (s *InMemoryStore) GetTeamSeasonPoints(ctx context.Context, teamKey string, season int) (db.TeamSeasonPoints, error) {
+	return s.wrapped.GetTeamSeasonPoints(ctx, teamKey, season)
+}
+
+// ListRosterRetention passes through to the underlying store.
+func (s *InMemoryStore) ListRosterRetention(ctx context.Context) ([]db.RosterRetention, error) {
+	return s.wrapped.ListRosterRetention(ctx)
+}
+
+// ListRosterEvents passes through to the underlying store.
+func (s *InMemoryStore) ListRosterEvents(ctx context.Context, teamKey string) ([]db.RosterEvent, error) {
+	return s.wrapped.ListRosterEvents(ctx, teamKey)
+}
+
+// ListTeamMachineScores passes through to the underlying store.
+func (s *InMemoryStore) ListTeamMachineScores(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error) {
+	return s.wrapped.ListTeamMachineScores(ctx, teamKey)
+}
+
+// GetTeamAttendance passes through to the underlying store.
+func (s *InMemoryStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return s.wrapped.GetTeamAttendance(ctx, teamKey)
+}
+
+// ListPlayerMachineScores passes through to the underlying store.
+func (s *InMemoryStore) ListPlayerMachineScores(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error) {
+	return s.wrapped.ListPlayerMachineScores(ctx, playerName)
+}
+
+// GetPlayerMachinePoints passes through to the underlying store.
+func (s *InMemoryStore) GetPlayerMachinePoints(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]db.Points, error) {
+	return s.wrapped.GetPlayerMachinePoints(ctx, playerName, venueKey, seasons)
+}
+
+// GetTeamHomeAwaySplits passes through to the underlying store.
+func (s *InMemoryStore) GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error) {
+	return s.wrapped.GetTeamHomeAwaySplits(ctx, teamKey, seasons)
+}
+
+// GetTeamMachinePicks passes through to the underlying store.
+func (s *InMemoryStore) GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error) {
+	return s.wrapped.GetTeamMachinePicks(ctx, teamKey, seasons)
+}
+
+// GetTeamMachinePoints passes through to the underlying store.
+func (s *InMemoryStore) GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error) {
+	return s.wrapped.GetTeamMachinePoints(ctx, teamKey, seasons)
+}
+
+// GetPlayerTrend passes through to the underlying store.
+func (s *InMemoryStore) GetPlayerTrend(ctx context.Context, playerName string) ([]db.PlayerSeasonStats, error) {
+	return s.wrapped.GetPlayerTrend(ctx, playerName)
+}
+
+// GetMonthlyP50 passes through to the underlying store.
+func (s *InMemoryStore) GetMonthlyP50(ctx context.Context, playerName string) ([]db.MonthlyStats, error) {
+	return s.wrapped.GetMonthlyP50(ctx, playerName)
+}
+
+// GetGameLog passes through to the underlying store.
+func (s *InMemoryStore) GetGameLog(ctx context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error) {
+	return s.wrapped.GetGameLog(ctx, f)
+}
+
+// GetLeaderboard passes through to the underlying store.
+func (s *InMemoryStore) GetLeaderboard(ctx context.Context, machineKey, venueKey string, season, top int) ([]db.TopScore, error) {
+	return s.wrapped.GetLeaderboard(ctx, machineKey, venueKey, season, top)
+}
+
+// GetPlayerGameLog passes through to the underlying store.
+func (s *InMemoryStore) GetPlayerGameLog(ctx context.Context, playerName string) ([]db.GameLogEntry, error) {
+	return s.wrapped.GetPlayerGameLog(ctx, playerName)
+}
+
+// ListPlayerGoals passes through to the underlying store.
+func (s *InMemoryStore) ListPlayerGoals(ctx context.Context, playerName string) ([]db.Goal, error) {
+	return s.wrapped.ListPlayerGoals(ctx, playerName)
+}
+
+// ListPlayerBadges passes through to the underlying store.
+func (s *InMemoryStore) ListPlayerBadges(ctx context.Context, playerName string) ([]db.Badge, error) {
+	return s.wrapped.ListPlayerBadges(ctx, playerName)
+}
+
+// ListMatchBadges passes through to the underlying store.
+func (s *InMemoryStore) ListMatchBadges(ctx context.Context, matchKey string) ([]db.Badge, error) {
+	return s.wrapped.ListMatchBadges(ctx, matchKey)
+}
+
+// GetSignatureWin passes through to the underlying store.
+func (s *InMemoryStore) GetSignatureWin(ctx context.Context, playerName string) (*db.SignatureWin, error) {
+	return s.wrapped.GetSignatureWin(ctx, playerName)
+}
+
+// GetMachineYears passes through to the underlying store.
+func (s *InMemoryStore) GetMachineYears(ctx context.Context) (map[string]int, error) {
+	return s.wrapped.GetMachineYears(ctx)
+}
+
+// ListMachineSummaries passes through to the underlying store.
+func (s *InMemoryStore) ListMachineSummaries(ctx context.Context, search string) ([]db.MachineSummary, error) {
+	return s.wrapped.ListMachineSummaries(ctx, search)
+}
+
+// ListVenueSummaries passes through to the underlying store.
+func (s *InMemoryStore) ListVenueSummaries(ctx context.Context, search string) ([]db.VenueSummary, error) {
+	return s.wrapped.ListVenueSummaries(ctx, search)
+}
+
+// ListSeasons passes through to the underlying store.
+func (s *InMemoryStore) ListSeasons(ctx context.Context) ([]int, error) {
+	return s.wrapped.ListSeasons(ctx)
+}
+
+// ListPlayedResults passes through to the underlying store.
+func (s *InMemoryStore) ListPlayedResults(ctx context.Context, from, to string) ([]db.PlayedResult, error) {
+	return s.wrapped.ListPlayedResults(ctx, from, to)
+}
+
+// GetStandings passes through to the underlying store.
+func (s *InMemoryStore) GetStandings(ctx context.Context, season int) ([]db.Standing, error) {
+	return s.wrapped.GetStandings(ctx, season)
+}
+
+// GetStandingsThroughWeek passes through to the underlying store.
+func (s *InMemoryStore) GetStandingsThroughWeek(ctx context.Context, season, week int) ([]db.Standing, error) {
+	return s.wrapped.GetStandingsThroughWeek(ctx, season, week)
 }
@@ -70,7 +70,7 @@ func TestListTeamsFilter(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.ListTeams(context.Background(), tc.search)
+			got, err := s.ListTeams(context.Background(), tc.search, 0)
 			if err != nil {
 				t.Fatalf("\n%s\nListTeams(...): unexpected error: %v", tc.reason, err)
 			}
@@ -0,0 +1,174 @@
+// Package card draws shareable player cards.
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+// Width and Height are the size of a card in pixels: the 1.91:1 size social
+// sites use for link previews.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin = 60
+
+	// Text scales, in pixels per font pixel.
+	nameScale    = 8
+	bodyScale    = 4
+	captionScale = 3
+)
+
+//nolint:gochecknoglobals // Read-only colors.
+var (
+	background = color.RGBA{0x1b, 0x1f, 0x2a, 0xff}
+	accent     = color.RGBA{0xf5, 0xb7, 0x31, 0xff}
+	text       = color.RGBA{0xf2, 0xf2, 0xf2, 0xff}
+	muted      = color.RGBA{0x9a, 0xa3, 0xb5, 0xff}
+)
+
+// Render draws a player card as a PNG: the player's name, team, and IPR,
+// their strongest machines, and their signature win. The Result should be
+// from player.Analyze with the WithSignatureWin option.
+func Render(w io.Writer, r *player.Result) error {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, margin/3, Height), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	c := &canvas{img: img, y: margin}
+	c.line(r.Name, text, fitScale(r.Name, nameScale))
+
+	var about []string
+	if r.Team != nil {
+		about = append(about, fmt.Sprintf("%s (%s)", r.Team.Name, r.Team.Key))
+	}
+	if r.IPR > 0 {
+		about = append(about, fmt.Sprintf("IPR %d", r.IPR))
+	}
+	if len(about) > 0 {
+		c.line(strings.Join(about, " | "), muted, bodyScale)
+	}
+	c.space()
+
+	if top := topMachines(r); len(top) > 0 {
+		c.line("TOP MACHINES", accent, captionScale)
+		for _, m := range top {
+			c.line(m, text, bodyScale)
+		}
+		c.space()
+	}
+
+	if win := r.Win; win != nil {
+		c.line("SIGNATURE WIN", accent, captionScale)
+		opponent := win.Opponent
+		if win.OpponentIPR > 0 {
+			opponent = fmt.Sprintf("%s (IPR %d)", win.Opponent, win.OpponentIPR)
+		}
+		c.line(fmt.Sprintf("Beat %s on %s", opponent, win.MachineName), text, bodyScale)
+		detail := output.FormatScore(float64(win.Score))
+		if win.Date != "" {
+			detail += " on " + win.Date
+		}
+		c.line(detail, muted, captionScale)
+	}
+
+	footer := "Monday Night Pinball"
+	drawText(img, Width-margin-textWidth(footer, captionScale), Height-margin-glyphHeight*captionScale, footer, muted, captionScale)
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("encode card: %w", err)
+	}
+	return nil
+}
+
+// topMachines describes the player's strongest machines, e.g.
+// "Medieval Madness 45.0M (+20%)".
+func topMachines(r *player.Result) []string {
+	stats := make(map[string]player.MachineStats, len(r.GlobalStats))
+	for _, s := range r.GlobalStats {
+		stats[s.MachineName] = s
+	}
+	out := make([]string, 0, len(r.Analysis.Strongest))
+	for _, name := range r.Analysis.Strongest {
+		s := stats[name]
+		out = append(out, name+"  "+output.FormatP50(s.P50Score, s.LeagueP50))
+	}
+	return out
+}
+
+// A canvas draws lines of text down an image.
+type canvas struct {
+	img *image.RGBA
+	y   int
+}
+
+// line draws a line of text at the canvas's left margin, truncating it to fit.
+func (c *canvas) line(s string, col color.Color, scale int) {
+	drawText(c.img, margin, c.y, truncate(s, Width-2*margin, scale), col, scale)
+	c.y += (glyphHeight + 3) * scale
+}
+
+// space leaves a gap between sections.
+func (c *canvas) space() {
+	c.y += glyphHeight * captionScale
+}
+
+// drawText draws a string with its top left corner at x, y.
+func drawText(img *image.RGBA, x, y int, s string, col color.Color, scale int) {
+	fill := image.NewUniform(col)
+	for _, r := range s {
+		g := glyph(r)
+		for cx, column := range g {
+			for cy := range glyphHeight {
+				if column&(1<<cy) == 0 {
+					continue
+				}
+				px, py := x+cx*scale, y+cy*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), fill, image.Point{}, draw.Src)
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
+
+// textWidth returns the width of a string in pixels.
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+// fitScale returns the largest scale up to max at which a string fits
+// between the card's margins.
+func fitScale(s string, largest int) int {
+	scale := largest
+	for scale > 1 && textWidth(s, scale) > Width-2*margin {
+		scale--
+	}
+	return scale
+}
+
+// truncate shortens a string with an ellipsis until it fits the width.
+func truncate(s string, width, scale int) string {
+	if textWidth(s, scale) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", scale) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "..."
+}
@@ -0,0 +1,83 @@
+package card
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+func TestFont(t *testing.T) {
+	// Printable ASCII runs from ' ' to '~'.
+	if diff := cmp.Diff(int('~'-' ')+1, len(font)); diff != "" {
+		t.Errorf("font should cover printable ASCII: -want glyphs, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(glyph('?'), glyph('é')); diff != "" {
+		t.Errorf("glyph(...) of an uncovered rune should be '?': -want, +got:\n%s", diff)
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := &player.Result{
+		Name: "Alice",
+		IPR:  4,
+		Team: &player.Team{Key: "TTT", Name: "The Trailer Trashers"},
+		GlobalStats: []player.MachineStats{
+			{MachineKey: "MM", MachineName: "Medieval Madness", Games: 10, P50Score: 60_000_000, LeagueP50: 50_000_000},
+		},
+		Analysis: player.Analysis{Strongest: []string{"Medieval Madness"}},
+		Win: &player.SignatureWin{
+			SignatureWin: db.SignatureWin{MachineKey: "MM", Opponent: "Bob", OpponentIPR: 6, Score: 70_000_000, Date: "2024-01-15"},
+			MachineName:  "Medieval Madness",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, r); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Render(...) should write a PNG: %v", err)
+	}
+	if diff := cmp.Diff([2]int{Width, Height}, [2]int{img.Bounds().Dx(), img.Bounds().Dy()}); diff != "" {
+		t.Errorf("Render(...): -want size, +got:\n%s", diff)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	type args struct {
+		s     string
+		width int
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Fits": {
+			reason: "A string that fits should be left alone.",
+			args:   args{s: "Medieval Madness", width: textWidth("Medieval Madness", 1)},
+			want:   "Medieval Madness",
+		},
+		"TooWide": {
+			reason: "A string that's too wide should be cut short with an ellipsis.",
+			args:   args{s: "Medieval Madness", width: textWidth("Medieval...", 1)},
+			want:   "Medieval...",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := truncate(tc.args.s, tc.args.width, 1)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ntruncate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
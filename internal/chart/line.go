@@ -0,0 +1,61 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Width and height of a line chart's viewBox.
+const (
+	LineWidth  = 120
+	LineHeight = 30
+)
+
+// linePad keeps the line and its end marker inside the viewBox.
+const linePad = 3
+
+// A Line is a small line chart, or sparkline, of values in order. It has no
+// axes: it shows the shape of a trend, not its values.
+type Line struct {
+	Title  string
+	Values []float64
+}
+
+// WriteSVG writes the chart as an SVG element. The lowest value is drawn at the
+// bottom and the highest at the top, and the last value is marked. It returns
+// an error if the chart has fewer than two values.
+func (l Line) WriteSVG(w io.Writer) error {
+	if len(l.Values) < 2 {
+		return errors.New("line charts need at least two values")
+	}
+
+	lo, hi := l.Values[0], l.Values[0]
+	for _, v := range l.Values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	pts := make([]string, len(l.Values))
+	var x, y float64
+	for i, v := range l.Values {
+		x = linePad + float64(i)*(LineWidth-2*linePad)/float64(len(l.Values)-1)
+		y = LineHeight / 2 // A flat line runs through the middle.
+		if hi > lo {
+			y = linePad + (hi-v)/(hi-lo)*(LineHeight-2*linePad)
+		}
+		pts[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="%s">`,
+		LineWidth, LineHeight, LineWidth, LineHeight, html.EscapeString(l.Title))
+	fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(l.Title))
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="currentColor" stroke-width="1.5" stroke-linejoin="round"/>`, strings.Join(pts, " "))
+	fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2.5" fill="%s"/>`, x, y, colors[0])
+	b.WriteString(`</svg>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
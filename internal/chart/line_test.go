@@ -0,0 +1,58 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLineWriteSVG(t *testing.T) {
+	type want struct {
+		contains []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		line   Line
+		want   want
+	}{
+		"Rising": {
+			reason: "Values should be spread evenly across the chart, lowest at the bottom, with the last marked.",
+			line:   Line{Title: "TAF P50 by month", Values: []float64{10, 30, 20}},
+			want: want{contains: []string{
+				`aria-label="TAF P50 by month"`,
+				`<polyline points="3.0,27.0 60.0,3.0 117.0,15.0"`,
+				`<circle cx="117.0" cy="15.0"`,
+			}},
+		},
+		"Flat": {
+			reason: "Equal values should be drawn across the middle.",
+			line:   Line{Values: []float64{5, 5}},
+			want:   want{contains: []string{`<polyline points="3.0,15.0 117.0,15.0"`}},
+		},
+		"TooFewValues": {
+			reason: "A chart with fewer than two values should be rejected.",
+			line:   Line{Values: []float64{5}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			err := tc.line.WriteSVG(&b)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteSVG(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, s := range tc.want.contains {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("\n%s\nWriteSVG(...): want output to contain %q, got:\n%s", tc.reason, s, b.String())
+				}
+			}
+		})
+	}
+}
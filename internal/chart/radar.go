@@ -0,0 +1,120 @@
+// Package chart draws small SVG charts for the web UI.
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+// Size is the width and height of a chart's viewBox. Charts scale to fit their
+// container.
+const Size = 300
+
+const (
+	// radius is how far the outer ring is from the center, leaving room for
+	// axis labels around it.
+	radius = 100
+	rings  = 4
+)
+
+// colors are the fill and stroke of each series, in order. Later series reuse
+// the last color.
+//
+//nolint:gochecknoglobals // Read-only colors.
+var colors = []string{"#0172ad", "#9aa3b5"}
+
+// A Series is one set of values to plot, one per axis.
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// A Radar is a radar (or spider) chart. Each axis radiates from the center, and
+// each series is drawn as a polygon joining its value on every axis.
+type Radar struct {
+	Title  string
+	Axes   []string
+	Series []Series
+
+	// Max is the value at the outer ring. Larger values are drawn at the
+	// outer ring.
+	Max float64
+}
+
+// WriteSVG writes the chart as an SVG element. It returns an error if the
+// chart has fewer than three axes, or a series doesn't have a value for every
+// axis.
+func (r Radar) WriteSVG(w io.Writer) error {
+	if len(r.Axes) < 3 {
+		return errors.New("radar charts need at least three axes")
+	}
+	if r.Max <= 0 {
+		return errors.New("radar charts need a positive maximum")
+	}
+	for _, s := range r.Series {
+		if len(s.Values) != len(r.Axes) {
+			return fmt.Errorf("series %q has %d values for %d axes", s.Name, len(s.Values), len(r.Axes))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img" aria-label="%s">`, Size, Size, html.EscapeString(r.Title))
+	fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(r.Title))
+
+	// Rings and spokes.
+	b.WriteString(`<g fill="none" stroke="currentColor" stroke-opacity="0.2">`)
+	for i := 1; i <= rings; i++ {
+		fmt.Fprintf(&b, `<polygon points="%s"/>`, r.points(func(int) float64 { return float64(i) / rings }))
+	}
+	for i := range r.Axes {
+		x, y := r.point(i, 1)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%.1f" y2="%.1f"/>`, Size/2, Size/2, x, y)
+	}
+	b.WriteString(`</g>`)
+
+	for i, s := range r.Series {
+		c := colors[min(i, len(colors)-1)]
+		fmt.Fprintf(&b, `<polygon points="%s" fill="%s" fill-opacity="0.25" stroke="%s" stroke-width="2"><title>%s</title></polygon>`,
+			r.points(func(axis int) float64 { return min(s.Values[axis], r.Max) / r.Max }), c, c, html.EscapeString(s.Name))
+	}
+
+	// Labels sit just beyond the outer ring, anchored away from the center.
+	b.WriteString(`<g font-size="11" fill="currentColor">`)
+	for i, a := range r.Axes {
+		x, y := r.point(i, 1.12)
+		anchor := "middle"
+		switch {
+		case x < Size/2-1:
+			anchor = "end"
+		case x > Size/2+1:
+			anchor = "start"
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="%s" dominant-baseline="middle">%s</text>`, x, y, anchor, html.EscapeString(a))
+	}
+	b.WriteString(`</g></svg>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// point returns the position of the supplied fraction of the way along an
+// axis. The first axis points straight up, and the rest follow clockwise.
+func (r Radar) point(axis int, fraction float64) (x, y float64) {
+	angle := 2*math.Pi*float64(axis)/float64(len(r.Axes)) - math.Pi/2
+	return Size/2 + radius*fraction*math.Cos(angle), Size/2 + radius*fraction*math.Sin(angle)
+}
+
+// points returns an SVG polygon's points, at the fraction of the way along each
+// axis returned by the supplied function.
+func (r Radar) points(fraction func(axis int) float64) string {
+	pts := make([]string, len(r.Axes))
+	for i := range r.Axes {
+		x, y := r.point(i, fraction(i))
+		pts[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(pts, " ")
+}
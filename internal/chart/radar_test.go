@@ -0,0 +1,75 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRadarWriteSVG(t *testing.T) {
+	type want struct {
+		contains []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		radar  Radar
+		want   want
+	}{
+		"Square": {
+			reason: "Each value should be plotted along its axis, clockwise from the top, with values past the maximum at the outer ring.",
+			radar: Radar{
+				Title:  "Alice vs league",
+				Axes:   []string{"TAF", "MM", "TZ", "AFM"},
+				Series: []Series{{Name: "Alice", Values: []float64{2, 1, 0, 4}}},
+				Max:    2,
+			},
+			want: want{contains: []string{
+				`aria-label="Alice vs league"`,
+				`<polygon points="150.0,50.0 200.0,150.0 150.0,150.0 50.0,150.0" fill="#0172ad"`,
+				`text-anchor="middle" dominant-baseline="middle">TAF</text>`,
+				`text-anchor="start" dominant-baseline="middle">MM</text>`,
+				`text-anchor="end" dominant-baseline="middle">AFM</text>`,
+			}},
+		},
+		"EscapesLabels": {
+			reason: "Axis labels, series names, and the title should be escaped.",
+			radar: Radar{
+				Title:  "<b>",
+				Axes:   []string{"Tom & Jerry", "B", "C"},
+				Series: []Series{{Name: "O'Brien", Values: []float64{1, 1, 1}}},
+				Max:    1,
+			},
+			want: want{contains: []string{"<title>&lt;b&gt;</title>", "Tom &amp; Jerry", "<title>O&#39;Brien</title>"}},
+		},
+		"TooFewAxes": {
+			reason: "A chart with fewer than three axes should be rejected.",
+			radar:  Radar{Axes: []string{"A", "B"}, Max: 1},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"MissingValues": {
+			reason: "A series without a value for every axis should be rejected.",
+			radar:  Radar{Axes: []string{"A", "B", "C"}, Series: []Series{{Values: []float64{1}}}, Max: 1},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			err := tc.radar.WriteSVG(&b)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteSVG(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, s := range tc.want.contains {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("\n%s\nWriteSVG(...): want output to contain %q, got:\n%s", tc.reason, s, b.String())
+				}
+			}
+		})
+	}
+}
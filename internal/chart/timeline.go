@@ -0,0 +1,112 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Width and height of a timeline's viewBox.
+const (
+	TimelineWidth  = 300
+	TimelineHeight = 120
+)
+
+const (
+	// timelineLeft and timelineBottom leave room for the value and time axis
+	// labels.
+	timelineLeft   = 48
+	timelineBottom = 18
+
+	// timelinePad keeps points inside the plot.
+	timelinePad = 6
+)
+
+// A Point is one value on a timeline.
+type Point struct {
+	Label string // Labels the time axis, e.g. "Wk 3". Only the first and last are drawn.
+	Value float64
+	Title string // Shown when hovering over the point.
+}
+
+// A Timeline is a line chart of values over time, e.g. a player's score in each
+// game of a season. Unlike a Line it labels its axes: the lowest and highest
+// values, and the first and last points' labels.
+type Timeline struct {
+	Title  string
+	Points []Point
+
+	// Format formats the value axis labels. Values are formatted with %g if
+	// it's nil.
+	Format func(float64) string
+}
+
+// WriteSVG writes the chart as an SVG element. Points are spread evenly across
+// the chart, oldest at the left, and each is marked. It returns an error if the
+// chart has fewer than two points.
+func (t Timeline) WriteSVG(w io.Writer) error {
+	if len(t.Points) < 2 {
+		return errors.New("timelines need at least two points")
+	}
+	format := t.Format
+	if format == nil {
+		format = func(v float64) string { return fmt.Sprintf("%g", v) }
+	}
+
+	lo, hi := t.Points[0].Value, t.Points[0].Value
+	for _, p := range t.Points {
+		lo, hi = min(lo, p.Value), max(hi, p.Value)
+	}
+
+	const (
+		left, right = timelineLeft + timelinePad, TimelineWidth - timelinePad
+		top, bottom = timelinePad, TimelineHeight - timelineBottom - timelinePad
+	)
+	xs := make([]float64, len(t.Points))
+	ys := make([]float64, len(t.Points))
+	pts := make([]string, len(t.Points))
+	for i, p := range t.Points {
+		xs[i] = left + float64(i)*(right-left)/float64(len(t.Points)-1)
+		ys[i] = (top + bottom) / 2 // A flat line runs through the middle.
+		if hi > lo {
+			ys[i] = top + (hi-p.Value)/(hi-lo)*(bottom-top)
+		}
+		pts[i] = fmt.Sprintf("%.1f,%.1f", xs[i], ys[i])
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		TimelineWidth, TimelineHeight, html.EscapeString(t.Title))
+	fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(t.Title))
+
+	// Axes.
+	fmt.Fprintf(&b, `<g fill="none" stroke="currentColor" stroke-opacity="0.2"><polyline points="%d,0 %d,%d %d,%d"/></g>`,
+		timelineLeft, timelineLeft, TimelineHeight-timelineBottom, TimelineWidth, TimelineHeight-timelineBottom)
+
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5" stroke-linejoin="round"/>`, strings.Join(pts, " "), colors[0])
+	fmt.Fprintf(&b, `<g fill="%s">`, colors[0])
+	for i, p := range t.Points {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3"><title>%s</title></circle>`, xs[i], ys[i], html.EscapeString(p.Title))
+	}
+	b.WriteString(`</g>`)
+
+	// Labels: the highest and lowest values to the left, and the first and
+	// last points below. A flat line has one value, labelled where it's
+	// drawn.
+	first, last := t.Points[0], t.Points[len(t.Points)-1]
+	b.WriteString(`<g font-size="11" fill="currentColor">`)
+	if hi > lo {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" dominant-baseline="middle">%s</text>`, timelineLeft-4, top, html.EscapeString(format(hi)))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" dominant-baseline="middle">%s</text>`, timelineLeft-4, bottom, html.EscapeString(format(lo)))
+	} else {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" dominant-baseline="middle">%s</text>`, timelineLeft-4, (top+bottom)/2, html.EscapeString(format(hi)))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="start">%s</text>`, left, TimelineHeight-4, html.EscapeString(first.Label))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, right, TimelineHeight-4, html.EscapeString(last.Label))
+	b.WriteString(`</g></svg>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTimelineWriteSVG(t *testing.T) {
+	type want struct {
+		contains []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		timeline Timeline
+		want     want
+	}{
+		"Rising": {
+			reason: "Points should be spread evenly across the chart, lowest at the bottom, with the value and time axes labelled.",
+			timeline: Timeline{
+				Title: "TAF scores",
+				Points: []Point{
+					{Label: "Wk 1", Value: 10, Title: "Week 1: 10"},
+					{Label: "Wk 2", Value: 30, Title: "Week 2: 30"},
+					{Label: "Wk 4", Value: 20, Title: "Week 4: 20"},
+				},
+				Format: func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) },
+			},
+			want: want{contains: []string{
+				`aria-label="TAF scores"`,
+				`<polyline points="54.0,96.0 174.0,6.0 294.0,51.0"`,
+				`<circle cx="174.0" cy="6.0" r="3"><title>Week 2: 30</title></circle>`,
+				`>30.0</text>`,
+				`>10.0</text>`,
+				`>Wk 1</text>`,
+				`>Wk 4</text>`,
+			}},
+		},
+		"Flat": {
+			reason:   "Equal values should be drawn, and labelled, across the middle.",
+			timeline: Timeline{Points: []Point{{Value: 5}, {Value: 5}}},
+			want: want{contains: []string{
+				`<polyline points="54.0,51.0 294.0,51.0"`,
+				`y="51" text-anchor="end" dominant-baseline="middle">5</text>`,
+			}},
+		},
+		"TooFewPoints": {
+			reason:   "A chart with fewer than two points should be rejected.",
+			timeline: Timeline{Points: []Point{{Value: 5}}},
+			want:     want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			err := tc.timeline.WriteSVG(&b)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWriteSVG(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			for _, s := range tc.want.contains {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("\n%s\nWriteSVG(...): want output to contain %q, got:\n%s", tc.reason, s, b.String())
+				}
+			}
+		})
+	}
+}
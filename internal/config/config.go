@@ -0,0 +1,216 @@
+// Package config loads the mnp CLI's user configuration file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// ErrNoTeam is returned when a command needs a team, but none was given and
+// no default team is set.
+var ErrNoTeam = errors.New("no team given, and no default team set. Run mnp config set team <team> to set one")
+
+// Path returns the path of the config file.
+//
+// It uses os.UserConfigDir, which respects XDG_CONFIG_HOME on Linux, uses
+// ~/Library/Application Support on macOS, and %AppData% on Windows. If the
+// user config directory can't be determined it returns an empty string.
+func Path() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "mnp", "config.json")
+}
+
+// Config is the user's configuration.
+type Config struct {
+	// Aliases maps a name to the command line it expands to, e.g. "tonight"
+	// to "matchup ANC CRA PYC". Quote arguments that contain spaces.
+	Aliases map[string]string `json:"aliases"`
+
+	// Team is the team commands use when none is given, e.g. "CRA".
+	Team string `json:"team,omitempty"`
+
+	// Venue is the venue commands use when none is given, e.g. "ANC". It
+	// sets the default of every --venue flag.
+	Venue string `json:"venue,omitempty"`
+
+	// Flags sets flags' defaults, keyed by flag name, e.g. "archive-url" or
+	// "venue". A key naming a command, e.g. "serve" or "db sync-ipr", holds
+	// defaults for that command's flags only. Flags passed on the command line or set by
+	// environment variable take precedence.
+	Flags map[string]any `json:"flags,omitempty"`
+}
+
+// Load reads the config file at the supplied path. A missing file is an empty
+// config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	if path == "" {
+		return c, nil
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // Reading the user's own config file.
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Resolver returns a kong.Resolver that reads flags' defaults from the config.
+func (c *Config) Resolver() kong.Resolver {
+	return kong.ResolverFunc(func(_ *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+		for _, env := range flag.Envs {
+			if _, ok := os.LookupEnv(env); ok {
+				return nil, nil
+			}
+		}
+		if parent.Command != nil {
+			if cmd, ok := c.Flags[commandPath(parent.Command)].(map[string]any); ok {
+				if v, ok := cmd[flag.Name]; ok {
+					return v, nil
+				}
+			}
+		}
+		if v, ok := c.Flags[flag.Name]; ok {
+			if _, ok := v.(map[string]any); !ok {
+				return v, nil
+			}
+		}
+		if flag.Name == "venue" && c.Venue != "" {
+			return c.Venue, nil
+		}
+		return nil, nil
+	})
+}
+
+// Set sets the named setting, either "team" or "venue". An empty value unsets
+// it.
+func (c *Config) Set(key, value string) error {
+	switch key {
+	case "team":
+		c.Team = strings.ToUpper(value)
+	case "venue":
+		c.Venue = strings.ToUpper(value)
+	default:
+		return fmt.Errorf("unknown setting %q, want team or venue", key)
+	}
+	return nil
+}
+
+// commandPath returns a command's name, prefixed by its parents' names, e.g.
+// "db sync-ipr".
+func commandPath(n *kong.Node) string {
+	var names []string
+	for ; n != nil && n.Type == kong.CommandNode; n = n.Parent {
+		names = append([]string{n.Name}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
+// Starter returns a starter config. If team is set it's the default team, and
+// there are aliases for the commands a captain of that team runs most.
+func Starter(team string) *Config {
+	c := &Config{Aliases: map[string]string{}}
+	if team == "" {
+		return c
+	}
+	team = strings.ToUpper(team)
+	c.Team = team
+	c.Aliases["prep"] = "next " + team
+	c.Aliases["opp"] = "scout " + team + " --next"
+	c.Aliases["drill"] = "practice --team " + team
+	return c
+}
+
+// Save writes the config to the supplied path, creating its directory if
+// needed.
+func (c *Config) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// Expand replaces the command in the supplied arguments (without the program
+// name) with the command line it's aliased to, if any. Flags before the
+// command are kept in place. Aliases aren't expanded recursively, so an alias
+// may share its name with the command it runs.
+func (c *Config) Expand(args []string) ([]string, error) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		line, ok := c.Aliases[arg]
+		if !ok {
+			return args, nil
+		}
+		expanded, err := Split(line)
+		if err != nil {
+			return nil, fmt.Errorf("expand alias %q: %w", arg, err)
+		}
+		out := make([]string, 0, len(args)+len(expanded))
+		out = append(out, args[:i]...)
+		out = append(out, expanded...)
+		return append(out, args[i+1:]...), nil
+	}
+	return args, nil
+}
+
+// Split splits a command line into arguments at spaces. Single or double
+// quotes group words containing spaces, e.g. player "Nic Cope".
+func Split(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,358 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"aliases": {"tonight": "matchup ANC CRA PYC"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"aliases": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		config *Config
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Valid": {
+			reason: "Aliases should be read from the config file.",
+			path:   valid,
+			want:   want{config: &Config{Aliases: map[string]string{"tonight": "matchup ANC CRA PYC"}}},
+		},
+		"Missing": {
+			reason: "A missing config file should be an empty config.",
+			path:   filepath.Join(dir, "missing.json"),
+			want:   want{config: &Config{}},
+		},
+		"Invalid": {
+			reason: "A malformed config file should return an error.",
+			path:   invalid,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Load(tc.path)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoad(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.config, got); diff != "" {
+				t.Errorf("\n%s\nLoad(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	c := &Config{Aliases: map[string]string{
+		"tonight": "matchup ANC CRA PYC",
+		"nic":     `player "Nic Cope"`,
+		"scout":   "scout --blend",
+		"broken":  `player "Nic`,
+	}}
+
+	type want struct {
+		args []string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		args   []string
+		want   want
+	}{
+		"Alias": {
+			reason: "An alias should expand to its command line.",
+			args:   []string{"tonight"},
+			want:   want{args: []string{"matchup", "ANC", "CRA", "PYC"}},
+		},
+		"Quoted": {
+			reason: "Quoted words in an alias should stay one argument.",
+			args:   []string{"nic", "--trends"},
+			want:   want{args: []string{"player", "Nic Cope", "--trends"}},
+		},
+		"LeadingFlags": {
+			reason: "Flags before the alias should be kept in place.",
+			args:   []string{"-v", "tonight"},
+			want:   want{args: []string{"-v", "matchup", "ANC", "CRA", "PYC"}},
+		},
+		"NotRecursive": {
+			reason: "An alias named after the command it runs should expand once.",
+			args:   []string{"scout", "CRA"},
+			want:   want{args: []string{"scout", "--blend", "CRA"}},
+		},
+		"NotAnAlias": {
+			reason: "A command that isn't an alias should be left alone.",
+			args:   []string{"player", "tonight"},
+			want:   want{args: []string{"player", "tonight"}},
+		},
+		"UnterminatedQuote": {
+			reason: "An alias with an unterminated quote should return an error.",
+			args:   []string{"broken"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.Expand(tc.args)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nExpand(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.args, got); diff != "" {
+				t.Errorf("\n%s\nExpand(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		team   string
+		want   *Config
+	}{
+		"Team": {
+			reason: "A starter config for a team should default to the team, alias its captain's common commands, and load back unchanged.",
+			team:   "cra",
+			want: &Config{
+				Aliases: map[string]string{
+					"prep":  "next CRA",
+					"opp":   "scout CRA --next",
+					"drill": "practice --team CRA",
+				},
+				Team: "CRA",
+			},
+		},
+		"NoTeam": {
+			reason: "A starter config without a team should have no aliases.",
+			want:   &Config{Aliases: map[string]string{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mnp", "config.json")
+			if err := Starter(tc.team).Save(path); err != nil {
+				t.Fatalf("Save(...): %v", err)
+			}
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSave(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		exe    string
+		addr   string
+		want   string
+	}{
+		"Plain": {
+			reason: "The unit should run the binary's web UI on the supplied address.",
+			exe:    "/usr/local/bin/mnp",
+			addr:   ":8080",
+			want:   "ExecStart=/usr/local/bin/mnp serve --addr :8080\n",
+		},
+		"Spaces": {
+			reason: "A binary path with spaces should be quoted.",
+			exe:    `/home/jay/my "bin"/mnp`,
+			addr:   ":80",
+			want:   `ExecStart="/home/jay/my \"bin\"/mnp" serve --addr :80` + "\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := SystemdUnit(tc.exe, tc.addr)
+			if !strings.Contains(got, tc.want) {
+				t.Errorf("\n%s\nSystemdUnit(...): want unit to contain %q, got:\n%s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	type cli struct {
+		ArchiveURL string `default:"https://example.org/archive.git"`
+		Token      string `env:"MNP_TEST_TOKEN"`
+		Venue      string
+
+		Serve struct {
+			Addr string `default:":8080"`
+		} `cmd:""`
+		DB struct {
+			Import struct {
+				Force bool
+			} `cmd:""`
+		} `cmd:""`
+	}
+
+	c := &Config{Flags: map[string]any{
+		"archive-url": "https://example.org/fork.git",
+		"token":       "from-config",
+		"venue":       "STN",
+		"serve":       map[string]any{"addr": ":9090"},
+		"db import":   map[string]any{"force": true},
+	}}
+	t.Setenv("MNP_TEST_TOKEN", "from-env")
+
+	type want struct {
+		archiveURL string
+		token      string
+		venue      string
+		addr       string
+		force      bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   []string
+		want   want
+	}{
+		"Serve": {
+			reason: "Flags should default to the config's values, but environment variables should take precedence.",
+			args:   []string{"serve"},
+			want:   want{archiveURL: "https://example.org/fork.git", token: "from-env", venue: "STN", addr: ":9090"},
+		},
+		"CommandLine": {
+			reason: "Flags passed on the command line should take precedence over the config.",
+			args:   []string{"--venue", "GPA", "serve", "--addr", ":1234"},
+			want:   want{archiveURL: "https://example.org/fork.git", token: "from-env", venue: "GPA", addr: ":1234"},
+		},
+		"Subcommand": {
+			reason: "A subcommand's flags should default to the values under its full name.",
+			args:   []string{"db", "import"},
+			want:   want{archiveURL: "https://example.org/fork.git", token: "from-env", venue: "STN", addr: ":8080", force: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := &cli{}
+			parser, err := kong.New(got, kong.Resolvers(c.Resolver()))
+			if err != nil {
+				t.Fatalf("kong.New(...): %v", err)
+			}
+			if _, err := parser.Parse(tc.args); err != nil {
+				t.Fatalf("Parse(%v): %v", tc.args, err)
+			}
+			w := want{archiveURL: got.ArchiveURL, token: got.Token, venue: got.Venue, addr: got.Serve.Addr, force: got.DB.Import.Force}
+			if diff := cmp.Diff(tc.want, w, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nParse(%v): -want, +got:\n%s", tc.reason, tc.args, diff)
+			}
+		})
+	}
+}
+
+func TestResolverVenue(t *testing.T) {
+	type cli struct {
+		Venue string
+	}
+
+	cases := map[string]struct {
+		reason string
+		config *Config
+		want   string
+	}{
+		"Venue": {
+			reason: "The --venue flag should default to the config's venue.",
+			config: &Config{Venue: "ANC"},
+			want:   "ANC",
+		},
+		"Flags": {
+			reason: "A venue set in the config's flags should take precedence over its venue.",
+			config: &Config{Venue: "ANC", Flags: map[string]any{"venue": "STN"}},
+			want:   "STN",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := &cli{}
+			parser, err := kong.New(got, kong.Resolvers(tc.config.Resolver()))
+			if err != nil {
+				t.Fatalf("kong.New(...): %v", err)
+			}
+			if _, err := parser.Parse(nil); err != nil {
+				t.Fatalf("Parse(): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got.Venue); diff != "" {
+				t.Errorf("\n%s\nParse(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	type args struct {
+		key   string
+		value string
+	}
+	type want struct {
+		config *Config
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Team": {
+			reason: "Setting the team should store its key in upper case.",
+			args:   args{key: "team", value: "cra"},
+			want:   want{config: &Config{Team: "CRA", Venue: "STN"}},
+		},
+		"Venue": {
+			reason: "Setting the venue should store its key in upper case.",
+			args:   args{key: "venue", value: "anc"},
+			want:   want{config: &Config{Team: "PYC", Venue: "ANC"}},
+		},
+		"Unset": {
+			reason: "Setting an empty value should unset it.",
+			args:   args{key: "team"},
+			want:   want{config: &Config{Venue: "STN"}},
+		},
+		"Unknown": {
+			reason: "Setting an unknown key should return an error.",
+			args:   args{key: "season", value: "23"},
+			want:   want{config: &Config{Team: "PYC", Venue: "STN"}, err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := &Config{Team: "PYC", Venue: "STN"}
+			err := got.Set(tc.args.key, tc.args.value)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSet(%q, %q): -want error, +got error:\n%s", tc.reason, tc.args.key, tc.args.value, diff)
+			}
+			if diff := cmp.Diff(tc.want.config, got); diff != "" {
+				t.Errorf("\n%s\nSet(%q, %q): -want, +got:\n%s", tc.reason, tc.args.key, tc.args.value, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemdUnitPath returns the path of the systemd user unit that runs the web
+// UI, or an empty string if the user config directory can't be determined.
+func SystemdUnitPath() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "systemd", "user", "mnp.service")
+}
+
+// SystemdUnit returns a systemd user unit that runs the supplied mnp binary's
+// web UI, listening on addr. The unit restarts the web UI if it exits.
+func SystemdUnit(exe, addr string) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=Monday Night Pinball web UI\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s serve --addr %s\n", quote(exe), quote(addr))
+	b.WriteString("Restart=on-failure\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// quote quotes a systemd command line argument if it contains spaces.
+func quote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"\\") {
+		return arg
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(arg) + `"`
+}
@@ -0,0 +1,99 @@
+// Package csvimport parses score spreadsheets exported as CSV.
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// ParseExternal parses other leagues' scores from a CSV file. The first row is
+// a header naming the columns, in any order:
+//
+//	venue    MNP venue key (e.g. ANC). Required.
+//	machine  MNP machine key (e.g. TAF). Required.
+//	score    Pinball score. Thousands separators are allowed. Required.
+//	player   Player name. Optional.
+//	date     ISO date the score was played (e.g. 2024-01-15). Optional.
+//
+// Column names, venue keys, and machine keys are case-insensitive. Other
+// columns are ignored.
+func ParseExternal(r io.Reader) ([]db.ExternalResult, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := columns(header)
+	for _, required := range []string{"venue", "machine", "score"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header is missing required column %q", required)
+		}
+	}
+
+	var results []db.ExternalResult
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV row: %w", err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(col string) string {
+			i, ok := cols[col]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		er := db.ExternalResult{
+			VenueKey:   strings.ToUpper(get("venue")),
+			MachineKey: strings.ToUpper(get("machine")),
+			PlayerName: get("player"),
+			Date:       get("date"),
+		}
+		if er.VenueKey == "" || er.MachineKey == "" {
+			return nil, fmt.Errorf("line %d: venue and machine are required", line)
+		}
+
+		score, err := strconv.ParseInt(strings.ReplaceAll(get("score"), ",", ""), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid score %q", line, get("score"))
+		}
+		er.Score = score
+
+		if er.Date != "" {
+			if _, err := time.Parse(time.DateOnly, er.Date); err != nil {
+				return nil, fmt.Errorf("line %d: invalid date %q (want YYYY-MM-DD)", line, er.Date)
+			}
+		}
+
+		results = append(results, er)
+	}
+
+	return results, nil
+}
+
+// columns maps lowercased column names to their index.
+func columns(header []string) map[string]int {
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return cols
+}
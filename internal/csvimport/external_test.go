@@ -0,0 +1,64 @@
+package csvimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+func TestParseExternal(t *testing.T) {
+	type want struct {
+		results []db.ExternalResult
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		csv    string
+		want   want
+	}{
+		"AllColumns": {
+			reason: "Columns should be matched by name in any order, ignoring unknown columns and blank rows. Keys should be upper case.",
+			csv: "Date,Player,Machine,Venue,Score,Notes\n" +
+				"2024-01-09,Zed,TAF,anc,\"12,345,670\",great game\n" +
+				",,,,,\n" +
+				",,tz,ANC,500,\n",
+			want: want{results: []db.ExternalResult{
+				{VenueKey: "ANC", MachineKey: "TAF", PlayerName: "Zed", Score: 12_345_670, Date: "2024-01-09"},
+				{VenueKey: "ANC", MachineKey: "TZ", Score: 500},
+			}},
+		},
+		"MissingColumn": {
+			reason: "A header without a required column should be rejected.",
+			csv:    "venue,machine\nANC,TAF\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidScore": {
+			reason: "A row with a non-numeric score should be rejected.",
+			csv:    "venue,machine,score\nANC,TAF,lots\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidDate": {
+			reason: "A row with a date that isn't ISO 8601 should be rejected.",
+			csv:    "venue,machine,score,date\nANC,TAF,100,1/9/2024\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseExternal(strings.NewReader(tc.csv))
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseExternal(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.results, got); diff != "" {
+				t.Errorf("\n%s\nParseExternal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
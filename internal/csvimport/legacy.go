@@ -0,0 +1,162 @@
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/league"
+	"github.com/negz/mnp/internal/mnp"
+)
+
+// ParseLegacy parses a season of match results from a CSV file in the legacy
+// format used for seasons that predate the MNP archive, played in the supplied
+// league format. Each row is one player's score in one game. The first row is a
+// header naming the columns, in any order:
+//
+//	week     Week number. Required.
+//	home     Home team key (e.g. CRA). Required.
+//	away     Away team key (e.g. PYC). Required.
+//	round    Round number, from 1. The format decides which are doubles.
+//	         Required.
+//	machine  MNP machine key (e.g. TAF). Required.
+//	player   Player name. Required.
+//	team     The player's team key. Must be the home or away team. Required.
+//	score    Pinball score. Thousands separators are allowed. Required.
+//	points   Match points the player earned. Optional.
+//	game     Game number within the round. Optional; rows in the same round
+//	         on the same machine are otherwise treated as one game.
+//	date     ISO date the match was played (e.g. 2015-01-15). Optional.
+//	venue    MNP venue key (e.g. ANC). Optional.
+//
+// Column names are case-insensitive. Other columns are ignored. Games and
+// matches are returned in the order they first appear, and players are
+// positioned within a game in the order their rows appear.
+func ParseLegacy(r io.Reader, season int, f league.Format) ([]mnp.MatchData, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := columns(header)
+	for _, required := range []string{"week", "home", "away", "round", "machine", "player", "team", "score"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header is missing required column %q", required)
+		}
+	}
+
+	type gameKey struct {
+		round   int
+		game    string
+		machine string
+	}
+
+	var matches []mnp.MatchData
+	matchIdx := make(map[string]int)
+	gameIdx := make(map[string]map[gameKey]int)
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV row: %w", err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(col string) string {
+			i, ok := cols[col]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		week, err := strconv.Atoi(get("week"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid week %q", line, get("week"))
+		}
+		round, err := strconv.Atoi(get("round"))
+		if err != nil || round < 1 || round > f.Rounds {
+			return nil, fmt.Errorf("line %d: invalid round %q (want 1-%d)", line, get("round"), f.Rounds)
+		}
+		score, err := strconv.ParseInt(strings.ReplaceAll(get("score"), ",", ""), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid score %q", line, get("score"))
+		}
+		var points float64
+		if p := get("points"); p != "" {
+			if points, err = strconv.ParseFloat(p, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid points %q", line, p)
+			}
+		}
+
+		home, away := strings.ToUpper(get("home")), strings.ToUpper(get("away"))
+		team := strings.ToUpper(get("team"))
+		machine, player := get("machine"), get("player")
+		if home == "" || away == "" || machine == "" || player == "" {
+			return nil, fmt.Errorf("line %d: home, away, machine, and player are required", line)
+		}
+		if team != home && team != away {
+			return nil, fmt.Errorf("line %d: team %q is neither the home team %q nor the away team %q", line, team, home, away)
+		}
+
+		date := get("date")
+		if date != "" {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return nil, fmt.Errorf("line %d: invalid date %q (want YYYY-MM-DD)", line, date)
+			}
+		}
+
+		key := fmt.Sprintf("mnp-%d-%d-%s-%s", season, week, away, home)
+		mi, ok := matchIdx[key]
+		if !ok {
+			mi = len(matches)
+			matchIdx[key] = mi
+			gameIdx[key] = make(map[gameKey]int)
+			venue := strings.ToUpper(get("venue"))
+			matches = append(matches, mnp.MatchData{
+				Key:     key,
+				Week:    week,
+				Date:    date,
+				Venue:   mnp.VenueRef{Key: venue, Name: venue},
+				HomeKey: home,
+				AwayKey: away,
+			})
+		}
+		m := &matches[mi]
+
+		gk := gameKey{round: round, game: get("game"), machine: machine}
+		gi, ok := gameIdx[key][gk]
+		if !ok {
+			gi = len(m.Games)
+			gameIdx[key][gk] = gi
+			m.Games = append(m.Games, mnp.GameData{
+				Round:      round,
+				MachineKey: machine,
+				IsDoubles:  f.IsDoubles(round),
+			})
+		}
+		g := &m.Games[gi]
+
+		g.Results = append(g.Results, mnp.ResultData{
+			PlayerName: player,
+			Score:      score,
+			Points:     points,
+			Position:   len(g.Results) + 1,
+			IsHome:     team == home,
+		})
+	}
+
+	return matches, nil
+}
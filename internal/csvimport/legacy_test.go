@@ -0,0 +1,145 @@
+package csvimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/league"
+	"github.com/negz/mnp/internal/mnp"
+)
+
+func TestParseLegacy(t *testing.T) {
+	type want struct {
+		matches []mnp.MatchData
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		csv    string
+		format league.Format // Defaults to season 12's format.
+		want   want
+	}{
+		"Season": {
+			reason: "Rows should be grouped into games and matches, with players positioned in row order.",
+			csv: "Week,Date,Venue,Home,Away,Round,Machine,Player,Team,Score,Points\n" +
+				"1,2015-01-12,anc,CRA,PYC,1,TAF,Alice,CRA,\"1,200,000\",2.5\n" +
+				"1,2015-01-12,anc,CRA,PYC,1,TAF,Bob,PYC,900000,0.5\n" +
+				",,,,,,,,,,\n" +
+				"1,2015-01-12,anc,CRA,PYC,2,MM,Alice,cra,50000000,3\n" +
+				"1,2015-01-12,anc,CRA,PYC,2,MM,Bob,PYC,20000000,0\n" +
+				"2,,,PYC,CRA,3,TZ,Bob,PYC,100,\n",
+			want: want{matches: []mnp.MatchData{
+				{
+					Key:     "mnp-12-1-PYC-CRA",
+					Week:    1,
+					Date:    "2015-01-12",
+					Venue:   mnp.VenueRef{Key: "ANC", Name: "ANC"},
+					HomeKey: "CRA",
+					AwayKey: "PYC",
+					Games: []mnp.GameData{
+						{Round: 1, MachineKey: "TAF", IsDoubles: true, Results: []mnp.ResultData{
+							{PlayerName: "Alice", Score: 1_200_000, Points: 2.5, Position: 1, IsHome: true},
+							{PlayerName: "Bob", Score: 900_000, Points: 0.5, Position: 2},
+						}},
+						{Round: 2, MachineKey: "MM", Results: []mnp.ResultData{
+							{PlayerName: "Alice", Score: 50_000_000, Points: 3, Position: 1, IsHome: true},
+							{PlayerName: "Bob", Score: 20_000_000, Position: 2},
+						}},
+					},
+				},
+				{
+					Key:     "mnp-12-2-CRA-PYC",
+					Week:    2,
+					HomeKey: "PYC",
+					AwayKey: "CRA",
+					Games: []mnp.GameData{
+						{Round: 3, MachineKey: "TZ", Results: []mnp.ResultData{
+							{PlayerName: "Bob", Score: 100, Position: 1, IsHome: true},
+						}},
+					},
+				},
+			}},
+		},
+		"GameNumber": {
+			reason: "Rows with different game numbers should be separate games, even on the same machine.",
+			csv: "week,home,away,round,game,machine,player,team,score\n" +
+				"1,CRA,PYC,2,1,TAF,Alice,CRA,100\n" +
+				"1,CRA,PYC,2,2,TAF,Carol,CRA,200\n",
+			want: want{matches: []mnp.MatchData{
+				{
+					Key:     "mnp-12-1-PYC-CRA",
+					Week:    1,
+					HomeKey: "CRA",
+					AwayKey: "PYC",
+					Games: []mnp.GameData{
+						{Round: 2, MachineKey: "TAF", Results: []mnp.ResultData{
+							{PlayerName: "Alice", Score: 100, Position: 1, IsHome: true},
+						}},
+						{Round: 2, MachineKey: "TAF", Results: []mnp.ResultData{
+							{PlayerName: "Carol", Score: 200, Position: 1, IsHome: true},
+						}},
+					},
+				},
+			}},
+		},
+		"HistoricalFormat": {
+			reason: "Rounds should be doubles, and allowed at all, according to the season's format.",
+			csv: "week,home,away,round,machine,player,team,score\n" +
+				"1,CRA,PYC,2,TAF,Alice,CRA,100\n" +
+				"1,CRA,PYC,5,MM,Alice,CRA,200\n",
+			format: league.Format{Rounds: 5, DoublesRounds: []int{2}},
+			want: want{matches: []mnp.MatchData{
+				{
+					Key:     "mnp-12-1-PYC-CRA",
+					Week:    1,
+					HomeKey: "CRA",
+					AwayKey: "PYC",
+					Games: []mnp.GameData{
+						{Round: 2, MachineKey: "TAF", IsDoubles: true, Results: []mnp.ResultData{
+							{PlayerName: "Alice", Score: 100, Position: 1, IsHome: true},
+						}},
+						{Round: 5, MachineKey: "MM", Results: []mnp.ResultData{
+							{PlayerName: "Alice", Score: 200, Position: 1, IsHome: true},
+						}},
+					},
+				},
+			}},
+		},
+		"MissingColumn": {
+			reason: "A header without a required column should be rejected.",
+			csv:    "week,home,away,round,machine,player,score\n1,CRA,PYC,1,TAF,Alice,100\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidRound": {
+			reason: "A row with a round the format doesn't have should be rejected.",
+			csv:    "week,home,away,round,machine,player,team,score\n1,CRA,PYC,5,TAF,Alice,CRA,100\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"UnknownTeam": {
+			reason: "A row whose player's team isn't playing in the match should be rejected.",
+			csv:    "week,home,away,round,machine,player,team,score\n1,CRA,PYC,1,TAF,Alice,SCS,100\n",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := tc.format
+			if f.Rounds == 0 {
+				f = league.Defaults().For(12)
+			}
+			got, err := ParseLegacy(strings.NewReader(tc.csv), 12, f)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseLegacy(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.matches, got); diff != "" {
+				t.Errorf("\n%s\nParseLegacy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
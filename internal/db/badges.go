@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// PlayedResult is one player's result in one game of any played match.
+type PlayedResult struct {
+	MatchResult
+
+	MatchKey string
+	Date     string // ISO date of the match. Empty if unknown.
+}
+
+// ListPlayedResults returns every player result in matches played between the
+// supplied ISO dates, inclusive, ordered by match date, then match, round,
+// game, and position. An empty date leaves that end of the range open.
+func (s *SQLiteStore) ListPlayedResults(ctx context.Context, from, to string) ([]PlayedResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			m.key,
+			COALESCE(m.date, ''),
+			g.id,
+			g.round,
+			COALESCE(g.machine_key, ''),
+			p.name,
+			t.key,
+			COALESCE(ipr.ipr, 0),
+			COALESCE(gr.score, 0),
+			gr.points
+		FROM game_results gr
+		JOIN games g ON g.id = gr.game_id
+		JOIN matches m ON m.id = g.match_id
+		JOIN seasons s ON s.id = m.season_id
+		JOIN players p ON p.id = gr.player_id
+		JOIN teams t ON t.id = gr.team_id
+		LEFT JOIN player_iprs ipr ON ipr.name = p.name
+		WHERE (? = '' OR m.date >= ?)
+		  AND (? = '' OR m.date <= ?)
+		ORDER BY s.number, m.week, m.date, m.key, g.round, g.id, gr.position
+	`, from, from, to, to)
+	if err != nil {
+		return nil, fmt.Errorf("query played results: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PlayedResult
+	for rows.Next() {
+		var r PlayedResult
+		if err := rows.Scan(
+			&r.MatchKey,
+			&r.Date,
+			&r.GameID,
+			&r.Round,
+			&r.MachineKey,
+			&r.PlayerName,
+			&r.TeamKey,
+			&r.IPR,
+			&r.Score,
+			&r.Points,
+		); err != nil {
+			return nil, fmt.Errorf("scan played result: %w", err)
+		}
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate played results: %w", err)
+	}
+
+	return result, nil
+}
+
+// Badge is a badge a player earned in a match.
+type Badge struct {
+	PlayerName string
+	Badge      string // The badge's key, e.g. 'billion'.
+	MatchKey   string // The match the player first earned it in.
+	EarnedOn   string // ISO date of the match. Empty if unknown.
+}
+
+// SaveBadges records badges, skipping any a player already has. It returns
+// the number of badges newly awarded.
+func (s *SQLiteStore) SaveBadges(ctx context.Context, badges []Badge) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	n := 0
+	for _, b := range badges {
+		res, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO player_badges (player_name, badge, match_key, earned_on)
+			VALUES (?, ?, ?, ?)
+		`, b.PlayerName, b.Badge, b.MatchKey, b.EarnedOn)
+		if err != nil {
+			return 0, fmt.Errorf("insert badge: %w", err)
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("count inserted badges: %w", err)
+		}
+		n += int(inserted)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit badges: %w", err)
+	}
+	return n, nil
+}
+
+// ListPlayerBadges returns the badges a player has earned, oldest first.
+func (s *SQLiteStore) ListPlayerBadges(ctx context.Context, playerName string) ([]Badge, error) {
+	return s.listBadges(ctx, "player_name = ?", playerName)
+}
+
+// ListMatchBadges returns the badges players earned in a match, ordered by
+// player then badge.
+func (s *SQLiteStore) ListMatchBadges(ctx context.Context, matchKey string) ([]Badge, error) {
+	return s.listBadges(ctx, "match_key = ?", matchKey)
+}
+
+func (s *SQLiteStore) listBadges(ctx context.Context, where string, arg any) ([]Badge, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT player_name, badge, match_key, earned_on
+		FROM player_badges
+		WHERE `+where+`
+		ORDER BY earned_on, match_key, player_name, badge
+	`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("query badges: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var badges []Badge
+	for rows.Next() {
+		var b Badge
+		if err := rows.Scan(&b.PlayerName, &b.Badge, &b.MatchKey, &b.EarnedOn); err != nil {
+			return nil, fmt.Errorf("scan badge: %w", err)
+		}
+		badges = append(badges, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate badges: %w", err)
+	}
+
+	return badges, nil
+}
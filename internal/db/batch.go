@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MatchGames is a match and its games, for LoadMatchBatch.
+type MatchGames struct {
+	Match  Match
+	Games  []GameResults
+	Lineup []LineupPlayer // Both teams' lineups.
+
+	// JSON is the match's raw archive JSON. If set, it's stored compressed
+	// for ListMatchJSON.
+	JSON []byte
+}
+
+// GameResults is a game and its players' results. The game's MatchID is
+// ignored in favor of its match's.
+type GameResults struct {
+	Game    Game
+	Results []PlayerResult
+}
+
+// LineupPlayer is a player in a team's lineup for a match, identified by name.
+type LineupPlayer struct {
+	PlayerName string
+	TeamID     int64
+}
+
+// PlayerResult is a player's result in a game, identified by name.
+type PlayerResult struct {
+	PlayerName string
+	TeamID     int64
+	Position   int
+	Score      int64
+	Points     float64
+}
+
+// LoadMatchBatch upserts matches, replacing any games and lineups previously
+// loaded for them, and creates players as needed. Every match is loaded in one
+// transaction using prepared statements, which is much faster than calling
+// UpsertMatch, InsertGame, and InsertGameResult for each row. If any match
+// fails to load, none are. Each machine played in a past season is associated
+// with the match's venue that season, so past seasons' venue machines are known
+// even though the archive only lists venues' current machines.
+func (s *SQLiteStore) LoadMatchBatch(ctx context.Context, matches []MatchGames) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	var stmts []*sql.Stmt
+	defer func() {
+		for _, st := range stmts {
+			st.Close() //nolint:errcheck // Closed with the transaction anyway.
+		}
+	}()
+	var prepErr error
+	prepare := func(query string) *sql.Stmt {
+		if prepErr != nil {
+			return nil
+		}
+		st, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			prepErr = fmt.Errorf("prepare statement: %w", err)
+			return nil
+		}
+		stmts = append(stmts, st)
+		return st
+	}
+	upsertMatch, selectMatchID := prepare(upsertMatchQuery), prepare(selectMatchIDQuery)
+	deleteResults, deleteGames := prepare(deleteMatchResultsQuery), prepare(deleteMatchGamesQuery)
+	upsertPlayer, selectPlayerID := prepare(upsertPlayerQuery), prepare(selectPlayerIDQuery)
+	insertGame, insertResult := prepare(insertGameQuery), prepare(insertGameResultQuery)
+	insertLineup, deleteLineups := prepare(insertMatchLineupQuery), prepare(deleteMatchLineupsQuery)
+	upsertJSON, upsertVenueMachine := prepare(upsertMatchJSONQuery), prepare(upsertPlayedVenueMachineQuery)
+	if prepErr != nil {
+		return prepErr
+	}
+
+	players := make(map[string]int64)
+	playerID := func(name string) (int64, error) {
+		if id, ok := players[name]; ok {
+			return id, nil
+		}
+		if _, err := upsertPlayer.ExecContext(ctx, name); err != nil {
+			return 0, fmt.Errorf("upsert player %s: %w", name, err)
+		}
+		var id int64
+		if err := selectPlayerID.QueryRowContext(ctx, name).Scan(&id); err != nil {
+			return 0, fmt.Errorf("get player id: %w", err)
+		}
+		players[name] = id
+		return id, nil
+	}
+
+	for _, mg := range matches {
+		m := mg.Match
+		if _, err := upsertMatch.ExecContext(ctx, m.Key, m.SeasonID, m.Week, m.WeekLabel, m.Stage.orRegular(), m.Date, m.HomeTeamID, m.AwayTeamID, nullID(m.VenueID)); err != nil {
+			return fmt.Errorf("upsert match %s: %w", m.Key, err)
+		}
+		var matchID int64
+		if err := selectMatchID.QueryRowContext(ctx, m.Key).Scan(&matchID); err != nil {
+			return fmt.Errorf("get match id: %w", err)
+		}
+
+		if mg.JSON != nil {
+			z, err := compress(mg.JSON)
+			if err != nil {
+				return fmt.Errorf("compress match %s JSON: %w", m.Key, err)
+			}
+			if _, err := upsertJSON.ExecContext(ctx, m.Key, z, time.Now().UTC().Format(time.RFC3339), m.SeasonID); err != nil {
+				return fmt.Errorf("store match %s JSON: %w", m.Key, err)
+			}
+		}
+
+		if _, err := deleteResults.ExecContext(ctx, matchID); err != nil {
+			return fmt.Errorf("delete game results: %w", err)
+		}
+		if _, err := deleteGames.ExecContext(ctx, matchID); err != nil {
+			return fmt.Errorf("delete games: %w", err)
+		}
+
+		if _, err := deleteLineups.ExecContext(ctx, matchID); err != nil {
+			return fmt.Errorf("delete lineups: %w", err)
+		}
+		for _, l := range mg.Lineup {
+			pid, err := playerID(l.PlayerName)
+			if err != nil {
+				return err
+			}
+			if _, err := insertLineup.ExecContext(ctx, matchID, pid, l.TeamID); err != nil {
+				return fmt.Errorf("insert lineup: %w", err)
+			}
+		}
+
+		for _, gr := range mg.Games {
+			g := gr.Game
+			res, err := insertGame.ExecContext(ctx, matchID, g.Round, g.MachineKey, boolInt(g.IsDoubles))
+			if err != nil {
+				return fmt.Errorf("insert game: %w", err)
+			}
+			if m.VenueID != 0 && g.MachineKey != "" {
+				if _, err := upsertVenueMachine.ExecContext(ctx, m.VenueID, m.SeasonID, g.MachineKey, m.SeasonID); err != nil {
+					return fmt.Errorf("upsert venue machine %s: %w", g.MachineKey, err)
+				}
+			}
+			gameID, err := res.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("get game id: %w", err)
+			}
+
+			for _, r := range gr.Results {
+				pid, err := playerID(r.PlayerName)
+				if err != nil {
+					return err
+				}
+				if _, err := insertResult.ExecContext(ctx, gameID, pid, r.TeamID, r.Position, r.Score, r.Points); err != nil {
+					return fmt.Errorf("insert game result: %w", err)
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit matches: %w", err)
+	}
+	return nil
+}
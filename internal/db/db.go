@@ -6,17 +6,45 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	_ "modernc.org/sqlite" // SQL driver registration.
 )
 
 // SQLiteStore is a SQLite database for MNP data.
 type SQLiteStore struct {
-	db *sql.DB
+	db         *sql.DB
+	recency    float64
+	adjustment float64
+}
+
+// Option configures a SQLiteStore.
+type Option func(*SQLiteStore)
+
+// WithRecencyWeight weights scores from earlier seasons less heavily when
+// computing P50 and P90 stats. Each season's scores count w times as much as
+// the following season's, so a w of 0.5 halves their weight every season back.
+// A w of 1, the default, weights every season equally.
+func WithRecencyWeight(w float64) Option {
+	return func(s *SQLiteStore) {
+		s.recency = w
+	}
+}
+
+// WithOpponentAdjustment adjusts each score for the strength of the opposing
+// players when computing P50 and P90 stats. Each point of IPR the opponents
+// average above the league's average raises a score by the fraction a, and
+// each point below lowers it by the same fraction. An a of 0.05 raises a score
+// against opponents a point above average by 5%. An a of 0, the default,
+// leaves scores unadjusted.
+func WithOpponentAdjustment(a float64) Option {
+	return func(s *SQLiteStore) {
+		s.adjustment = a
+	}
 }
 
 // Open opens or creates a SQLite database at the given path.
-func Open(ctx context.Context, path string) (*SQLiteStore, error) {
+func Open(ctx context.Context, path string, opts ...Option) (*SQLiteStore, error) {
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
@@ -35,7 +63,11 @@ func Open(ctx context.Context, path string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("set pragmas: %w", err)
 	}
 
-	return &SQLiteStore{db: db}, nil
+	s := &SQLiteStore{db: db, recency: 1}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
 }
 
 // Close closes the database.
@@ -48,11 +80,73 @@ func (s *SQLiteStore) DB() *sql.DB {
 	return s.db
 }
 
-// Init creates the database schema.
+// Size returns the size of the database in bytes, not counting its write-ahead
+// log.
+func (s *SQLiteStore) Size(ctx context.Context) (int64, error) {
+	var pages, size int64
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("query page count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&size); err != nil {
+		return 0, fmt.Errorf("query page size: %w", err)
+	}
+	return pages * size, nil
+}
+
+// schemaVersion is the version of the schema below. Bump it whenever the
+// schema changes in a way CREATE TABLE IF NOT EXISTS can't apply to an existing
+// database, such as adding a column.
+const schemaVersion = 4
+
+// archiveTables are the tables loaded from the MNP archive, in an order that
+// can be dropped without violating foreign keys. They can always be rebuilt by
+// syncing, so they're dropped rather than migrated when the schema changes.
+func archiveTables() []string {
+	return []string{
+		"search_index",
+		"team_machine_stats",
+		"player_machine_stats",
+		"player_badges",
+		"game_results",
+		"games",
+		"match_lineups",
+		"matches",
+		"rosters",
+		"venue_machines",
+		"teams",
+		"venues",
+		"player_iprs",
+		"players",
+		"seasons",
+		"machines",
+		"sync_metadata",
+	}
+}
+
+// Init creates the database schema. If the database was created by an older
+// schema, the archive tables are dropped and recreated, and will be reloaded
+// by the next sync.
 func (s *SQLiteStore) Init(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+
+	if version < schemaVersion {
+		for _, t := range archiveTables() {
+			if _, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+t); err != nil {
+				return fmt.Errorf("drop outdated table %s: %w", t, err)
+			}
+		}
+	}
+
 	if _, err := s.db.ExecContext(ctx, schema); err != nil {
 		return fmt.Errorf("create schema: %w", err)
 	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return fmt.Errorf("set schema version: %w", err)
+	}
 	return nil
 }
 
@@ -85,6 +179,117 @@ func (s *SQLiteStore) SetMetadata(ctx context.Context, key, value string) error
 	return nil
 }
 
+// Sync metadata keys recording how fresh the archive data is.
+const (
+	MetadataArchiveCommit     = "mnp_archive_commit"      // Hash of the archive commit last synced.
+	MetadataArchiveCommitTime = "mnp_archive_commit_time" // When that commit was made, RFC 3339.
+	MetadataLastSync          = "mnp_last_sync"           // When the archive was last synced, RFC 3339.
+)
+
+// Sync metadata keys recording the last attempt to sync, whether or not it
+// succeeded.
+const (
+	MetadataSyncAttempt  = "mnp_sync_attempt"  // When the last sync started, RFC 3339.
+	MetadataSyncDuration = "mnp_sync_duration" // How long it took, e.g. 1m30s.
+	MetadataSyncError    = "mnp_sync_error"    // Why it failed. Empty if it succeeded.
+)
+
+// Freshness describes how up to date the archive data is.
+type Freshness struct {
+	Commit     string    // Hash of the archive commit last synced. Empty if never synced.
+	CommitTime time.Time // When that commit was made.
+	LastSync   time.Time // When the archive was last synced.
+}
+
+// GetFreshness returns how up to date the archive data is.
+func (s *SQLiteStore) GetFreshness(ctx context.Context) (Freshness, error) {
+	var f Freshness
+	var err error
+	if f.Commit, err = s.GetMetadata(ctx, MetadataArchiveCommit); err != nil {
+		return Freshness{}, err
+	}
+	for key, t := range map[string]*time.Time{MetadataArchiveCommitTime: &f.CommitTime, MetadataLastSync: &f.LastSync} {
+		v, err := s.GetMetadata(ctx, key)
+		if err != nil {
+			return Freshness{}, err
+		}
+		if v == "" {
+			continue
+		}
+		if *t, err = time.Parse(time.RFC3339, v); err != nil {
+			return Freshness{}, fmt.Errorf("parse metadata %s: %w", key, err)
+		}
+	}
+	return f, nil
+}
+
+// SyncStatus describes the last attempt to sync, and how up to date the data
+// loaded by the last successful sync is.
+type SyncStatus struct {
+	Freshness
+
+	Attempted time.Time     // When the last sync started. Zero if never attempted.
+	Duration  time.Duration // How long it took.
+	Error     string        // Why it failed. Empty if it succeeded.
+}
+
+// Failed returns true if the last attempt to sync failed.
+func (s SyncStatus) Failed() bool {
+	return s.Error != ""
+}
+
+// GetSyncStatus returns the last attempt to sync and how up to date the data
+// is.
+func (s *SQLiteStore) GetSyncStatus(ctx context.Context) (SyncStatus, error) {
+	f, err := s.GetFreshness(ctx)
+	if err != nil {
+		return SyncStatus{}, err
+	}
+	st := SyncStatus{Freshness: f}
+
+	if st.Error, err = s.GetMetadata(ctx, MetadataSyncError); err != nil {
+		return SyncStatus{}, err
+	}
+	v, err := s.GetMetadata(ctx, MetadataSyncAttempt)
+	if err != nil {
+		return SyncStatus{}, err
+	}
+	if v != "" {
+		if st.Attempted, err = time.Parse(time.RFC3339, v); err != nil {
+			return SyncStatus{}, fmt.Errorf("parse metadata %s: %w", MetadataSyncAttempt, err)
+		}
+	}
+	v, err = s.GetMetadata(ctx, MetadataSyncDuration)
+	if err != nil {
+		return SyncStatus{}, err
+	}
+	if v != "" {
+		if st.Duration, err = time.ParseDuration(v); err != nil {
+			return SyncStatus{}, fmt.Errorf("parse metadata %s: %w", MetadataSyncDuration, err)
+		}
+	}
+	return st, nil
+}
+
+// RecordSyncAttempt records an attempt to sync that started at the supplied
+// time and took the supplied duration. A nil syncErr means it succeeded.
+func (s *SQLiteStore) RecordSyncAttempt(ctx context.Context, started time.Time, d time.Duration, syncErr error) error {
+	msg := ""
+	if syncErr != nil {
+		msg = syncErr.Error()
+	}
+	for key, value := range map[string]string{
+		MetadataSyncAttempt:  started.UTC().Format(time.RFC3339),
+		MetadataSyncDuration: d.Round(time.Millisecond).String(),
+		MetadataSyncError:    msg,
+	} {
+		if err := s.SetMetadata(ctx, key, value); err != nil {
+			return fmt.Errorf("record sync attempt: %w", err)
+		}
+	}
+	return nil
+}
+
 // LoadedSeasons returns season numbers that have at least one match loaded.
 func (s *SQLiteStore) LoadedSeasons(ctx context.Context) (map[int]bool, error) {
 	rows, err := s.db.QueryContext(ctx, `
@@ -167,11 +372,13 @@ CREATE TABLE IF NOT EXISTS venues (
     name TEXT NOT NULL              -- Full name (e.g., 'Add-a-Ball')
 );
 
--- Current machines at each venue (replaced on each sync)
+-- Machines at each venue in each season. The latest season's are the archive's
+-- current machines. Every season's include the machines played at the venue.
 CREATE TABLE IF NOT EXISTS venue_machines (
     venue_id INTEGER NOT NULL REFERENCES venues(id),
+    season_id INTEGER NOT NULL REFERENCES seasons(id),
     machine_key TEXT NOT NULL REFERENCES machines(key),
-    PRIMARY KEY (venue_id, machine_key)
+    PRIMARY KEY (venue_id, season_id, machine_key)
 );
 
 -- Rosters (player-team membership)
@@ -189,7 +396,9 @@ CREATE TABLE IF NOT EXISTS matches (
     id INTEGER PRIMARY KEY,
     key TEXT NOT NULL UNIQUE,       -- e.g., 'mnp-23-1-CRA-PYC'
     season_id INTEGER NOT NULL REFERENCES seasons(id),
-    week INTEGER NOT NULL,          -- Week number within season
+    week INTEGER NOT NULL,          -- Week number within season, counting on through the playoffs
+    week_label TEXT NOT NULL DEFAULT '',    -- The archive's name for the week, e.g. '3', or 'SF' in the playoffs
+    stage TEXT NOT NULL DEFAULT 'regular',  -- 'regular' or 'playoffs'
     date TEXT,                      -- ISO date (e.g., '2024-01-15')
     home_team_id INTEGER NOT NULL REFERENCES teams(id),
     away_team_id INTEGER NOT NULL REFERENCES teams(id),
@@ -214,19 +423,214 @@ CREATE TABLE IF NOT EXISTS game_results (
     team_id INTEGER NOT NULL REFERENCES teams(id),
     position INTEGER NOT NULL,      -- Player order (1-4 for doubles, 1-2 for singles)
     score INTEGER,                  -- Pinball score achieved
+    points REAL NOT NULL DEFAULT 0, -- Match points earned (can be fractional for ties)
     PRIMARY KEY (game_id, player_id)
 );
 
+-- Players in each team's lineup for a match, including those who didn't play
+-- a game
+CREATE TABLE IF NOT EXISTS match_lineups (
+    match_id INTEGER NOT NULL REFERENCES matches(id),
+    player_id INTEGER NOT NULL REFERENCES players(id),
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    PRIMARY KEY (match_id, player_id)
+);
+
 -- Indexes for common query patterns
 CREATE INDEX IF NOT EXISTS idx_game_results_player_score ON game_results(player_id, score);
 CREATE INDEX IF NOT EXISTS idx_game_results_machine ON game_results(game_id);
 CREATE INDEX IF NOT EXISTS idx_games_machine ON games(machine_key);
 CREATE INDEX IF NOT EXISTS idx_matches_season ON matches(season_id);
+CREATE INDEX IF NOT EXISTS idx_match_lineups_team ON match_lineups(team_id);
 CREATE INDEX IF NOT EXISTS idx_teams_season ON teams(season_id);
 
+-- Each player's stats on each machine across every season, rebuilt from the
+-- tables above after each sync so the most common stats queries are lookups.
+-- Scores are unweighted and unadjusted. Like every stats query, P50 and P90
+-- are nearest-rank percentiles.
+CREATE TABLE IF NOT EXISTS player_machine_stats (
+    player_id INTEGER NOT NULL REFERENCES players(id),
+    machine_key TEXT NOT NULL,
+    games INTEGER NOT NULL,
+    p50 REAL,
+    p90 REAL,
+    PRIMARY KEY (player_id, machine_key)
+);
+
+-- Each team's current roster's stats on each machine across every season,
+-- pooling their scores for any team. Rebuilt like player_machine_stats.
+CREATE TABLE IF NOT EXISTS team_machine_stats (
+    team_key TEXT NOT NULL,         -- Team key. The roster is the latest season's.
+    machine_key TEXT NOT NULL,
+    games INTEGER NOT NULL,
+    p50 REAL,
+    p90 REAL,
+    PRIMARY KEY (team_key, machine_key)
+);
+
+-- Full-text index of players, teams, machines, and venues, rebuilt from the
+-- tables above after each sync
+CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+    kind UNINDEXED,                 -- 'player', 'team', 'machine', or 'venue'
+    key,                            -- Key to look the entity up by (a player's name)
+    name                            -- Display name
+);
+
 -- Sync metadata for tracking cache freshness
 CREATE TABLE IF NOT EXISTS sync_metadata (
     key TEXT PRIMARY KEY,            -- e.g., 'mnp_last_sync'
     value TEXT NOT NULL              -- ISO timestamp or other value
 );
+
+-- Changing game results or rosters makes player_machine_stats and
+-- team_machine_stats stale, so forget they were rebuilt until they're rebuilt
+-- again. Triggers catch writes from every process, such as mnp db import-csv
+-- while mnp serve is running.
+CREATE TRIGGER IF NOT EXISTS game_results_insert_stale AFTER INSERT ON game_results
+BEGIN DELETE FROM sync_metadata WHERE key = 'mnp_stats_rebuilt'; END;
+CREATE TRIGGER IF NOT EXISTS game_results_update_stale AFTER UPDATE ON game_results
+BEGIN DELETE FROM sync_metadata WHERE key = 'mnp_stats_rebuilt'; END;
+CREATE TRIGGER IF NOT EXISTS game_results_delete_stale AFTER DELETE ON game_results
+BEGIN DELETE FROM sync_metadata WHERE key = 'mnp_stats_rebuilt'; END;
+CREATE TRIGGER IF NOT EXISTS rosters_insert_stale AFTER INSERT ON rosters
+BEGIN DELETE FROM sync_metadata WHERE key = 'mnp_stats_rebuilt'; END;
+CREATE TRIGGER IF NOT EXISTS rosters_delete_stale AFTER DELETE ON rosters
+BEGIN DELETE FROM sync_metadata WHERE key = 'mnp_stats_rebuilt'; END;
+
+-- Raw match JSON from the archive, gzip compressed, as of the last sync. It
+-- lets matches be transformed and loaded again, for example after a loader
+-- fix, without walking the archive. It references matches by key so it's kept
+-- when the archive tables are dropped.
+CREATE TABLE IF NOT EXISTS match_json (
+    match_key TEXT PRIMARY KEY,     -- e.g., 'mnp-23-1-CRA-PYC'
+    season INTEGER NOT NULL,        -- Season number, e.g. 23
+    json BLOB NOT NULL,             -- gzip compressed match JSON
+    stored_at TEXT NOT NULL         -- When it was stored, RFC 3339
+);
+
+-- Pre-match predicted edges, recorded by mnp serve before each match is played.
+--
+-- Predictions can't be rebuilt from the archive, so they reference matches and
+-- machines by key rather than ID and are kept when the archive tables are
+-- dropped for a schema change.
+CREATE TABLE IF NOT EXISTS predictions (
+    match_key TEXT NOT NULL,        -- e.g., 'mnp-23-1-CRA-PYC'
+    machine_key TEXT NOT NULL,      -- e.g., 'TAF'
+    home_team_key TEXT NOT NULL,
+    away_team_key TEXT NOT NULL,
+    edge REAL NOT NULL,             -- Predicted % edge, positive favors the home team
+    confidence INTEGER NOT NULL,    -- 0 (low), 1 (medium), or 2 (high)
+    predicted_at TEXT NOT NULL,     -- ISO timestamp of the latest prediction
+    PRIMARY KEY (match_key, machine_key)
+);
+
+-- Player P50 scores saved by mnp serve's anomaly checks, replaced weekly.
+-- Comparing with the current P50s catches loader regressions, such as scores
+-- that suddenly double. Like predictions, the snapshot can't be rebuilt from
+-- the archive.
+CREATE TABLE IF NOT EXISTS p50_snapshots (
+    player_name TEXT NOT NULL,      -- Player name (matches players.name)
+    machine_key TEXT NOT NULL,      -- e.g., 'TAF'
+    games INTEGER NOT NULL,         -- Games the P50 was computed from
+    p50 REAL NOT NULL,              -- Median score across all seasons
+    taken_at TEXT NOT NULL,         -- ISO timestamp of the snapshot
+    PRIMARY KEY (player_name, machine_key)
+);
+
+-- Scores from other leagues, imported from CSV with mnp db import-external.
+--
+-- Some venues post other leagues' scores on the same machines. These add
+-- context where MNP's own venue-specific data is sparse, but are always shown
+-- separately and labeled with their source. Like predictions, they can't be
+-- rebuilt from the archive so they reference venues and machines by key.
+CREATE TABLE IF NOT EXISTS external_results (
+    id INTEGER PRIMARY KEY,
+    source TEXT NOT NULL,           -- Where the scores came from (e.g., 'Add-a-Ball Tuesday league')
+    venue_key TEXT NOT NULL,        -- e.g., 'ANC'
+    machine_key TEXT NOT NULL,      -- e.g., 'TAF'
+    player_name TEXT NOT NULL DEFAULT '', -- Empty if the source doesn't name players
+    score INTEGER NOT NULL,         -- Pinball score achieved
+    date TEXT NOT NULL DEFAULT ''   -- ISO date (e.g., '2024-01-15'), if known
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_results_venue_machine ON external_results(venue_key, machine_key);
+
+-- Badges players earned, awarded by mnp serve after each sync. Derived from
+-- the archive, so dropped and re-awarded when the schema changes.
+CREATE TABLE IF NOT EXISTS player_badges (
+    player_name TEXT NOT NULL,      -- Player name (matches players.name)
+    badge TEXT NOT NULL,            -- e.g., 'billion', 'giant-killer'
+    match_key TEXT NOT NULL,        -- The match the badge was first earned in
+    earned_on TEXT NOT NULL,        -- ISO date of the match, or '' if unknown
+    PRIMARY KEY (player_name, badge)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_badges_match ON player_badges(match_key);
+
+-- Goals players set for themselves with mnp goal set, tracked against the
+-- results they post afterwards. Like predictions, they can't be rebuilt from
+-- the archive so they reference players and machines by name and key.
+CREATE TABLE IF NOT EXISTS player_goals (
+    id INTEGER PRIMARY KEY,
+    player_name TEXT NOT NULL,      -- Player name (matches players.name)
+    machine_key TEXT NOT NULL,      -- e.g., 'MM'
+    kind TEXT NOT NULL,             -- 'score' or 'percentile'
+    target REAL NOT NULL,           -- Score to beat, or percentile rank (0-100) to reach
+    set_on TEXT NOT NULL            -- ISO date the goal was set (e.g., '2024-01-15')
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_goals_player ON player_goals(player_name);
+
+-- Pick'em game picks league members make in the web UI, scored against match
+-- results once they're loaded. Like predictions, they can't be rebuilt from the
+-- archive so they reference matches and teams by key.
+CREATE TABLE IF NOT EXISTS picks (
+    picker TEXT NOT NULL,           -- Name the picker entered. Not necessarily a player.
+    match_key TEXT NOT NULL,        -- e.g., 'mnp-23-1-CRA-PYC'
+    team_key TEXT NOT NULL,         -- Team picked to win (e.g., 'CRA')
+    picked_at TEXT NOT NULL,        -- ISO timestamp of the latest pick
+    PRIMARY KEY (picker, match_key)
+);
+
+-- The browser each pick'em picker's name belongs to, so no one else can change
+-- their picks. Like picks, they can't be rebuilt from the archive.
+CREATE TABLE IF NOT EXISTS pickers (
+    name TEXT PRIMARY KEY,          -- Name the picker entered, as in picks.picker
+    token_hash TEXT NOT NULL,       -- Hex SHA-256 of the token in the picker's cookie
+    claimed_at TEXT NOT NULL        -- ISO timestamp of the picker's first pick
+);
+
+-- Players joining or leaving a team's roster, noticed when a sync reloads the
+-- team's season. Like predictions, they can't be rebuilt from the archive so
+-- they reference seasons, teams, and players by number, key, and name.
+CREATE TABLE IF NOT EXISTS roster_events (
+    season INTEGER NOT NULL,        -- Season number, e.g. 23
+    team_key TEXT NOT NULL,         -- e.g., 'CRA'
+    player_name TEXT NOT NULL,      -- Player name (matches players.name)
+    change TEXT NOT NULL,           -- 'joined' or 'left'
+    seen_at TEXT NOT NULL           -- ISO timestamp of the sync that noticed the change
+);
+
+CREATE INDEX IF NOT EXISTS idx_roster_events_season_team ON roster_events(season, team_key);
+
+-- Who made each machine, and when, matched by name from an external machine
+-- database such as OPDB. The archive doesn't have them, so they reference
+-- machines by key and are kept when the schema changes.
+CREATE TABLE IF NOT EXISTS machine_details (
+    machine_key TEXT PRIMARY KEY,   -- MNP's short code (e.g., 'TAF')
+    manufacturer TEXT NOT NULL,     -- e.g., 'Williams', 'Stern'
+    year INTEGER NOT NULL,          -- Year of manufacture, or 0 if unknown
+    source TEXT NOT NULL,           -- Where the details came from, e.g. 'opdb'
+    source_id TEXT NOT NULL         -- The source's ID for the machine
+);
+
+-- Curated mappings from machines to an external machine database's machines,
+-- for machines whose names don't match theirs. Like machine_details, they
+-- reference machines by key and are kept when the schema changes.
+CREATE TABLE IF NOT EXISTS machine_key_aliases (
+    source TEXT NOT NULL,           -- The external database, e.g. 'opdb'
+    machine_key TEXT NOT NULL,      -- MNP's short code (e.g., 'TAF')
+    source_id TEXT NOT NULL,        -- The source's ID for the machine
+    PRIMARY KEY (source, machine_key)
+);
 `
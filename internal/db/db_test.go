@@ -2,9 +2,14 @@ package db
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 // testFixture holds IDs from fixture setup for use in test assertions.
@@ -31,6 +36,7 @@ type testFixture struct {
 //	  Game 2: TZ  (singles) — Alice 100, Carol 150
 //	  Game 3: TAF (singles) — Bob 350, Dave 250
 //	  Game 4: MM  (singles) — Alice 600, Carol 700
+//	  Points: TTT 7.5 (Alice 2.5, Bob 5), KNR 6.5 (Carol 6.5, Dave 0)
 //
 // This gives us predictable P50/P90 values for each team/machine/player
 // combination.
@@ -87,7 +93,7 @@ func newTestStore(t *testing.T) (*SQLiteStore, testFixture) {
 		{f.gpaID, "MM"},
 		{f.gpaID, "TZ"},
 	} {
-		if err := s.UpsertVenueMachine(ctx, vm.venueID, vm.machineKey); err != nil {
+		if err := s.UpsertVenueMachine(ctx, vm.venueID, f.seasonID, vm.machineKey); err != nil {
 			t.Fatalf("UpsertVenueMachine: %v", err)
 		}
 	}
@@ -147,6 +153,7 @@ func newTestStore(t *testing.T) (*SQLiteStore, testFixture) {
 		team     int64
 		position int
 		score    int64
+		points   float64
 	}
 	games := []struct {
 		round      int
@@ -157,31 +164,31 @@ func newTestStore(t *testing.T) (*SQLiteStore, testFixture) {
 		{
 			round: 1, machineKey: "TAF", isDoubles: true,
 			results: []result{
-				{"Alice", f.tttID, 1, 500},
-				{"Bob", f.tttID, 2, 400},
-				{"Carol", f.knrID, 1, 300},
-				{"Dave", f.knrID, 2, 200},
+				{"Alice", f.tttID, 1, 500, 2.5},
+				{"Bob", f.tttID, 2, 400, 2},
+				{"Carol", f.knrID, 1, 300, 0.5},
+				{"Dave", f.knrID, 2, 200, 0},
 			},
 		},
 		{
 			round: 2, machineKey: "TZ", isDoubles: false,
 			results: []result{
-				{"Alice", f.tttID, 1, 100},
-				{"Carol", f.knrID, 2, 150},
+				{"Alice", f.tttID, 1, 100, 0},
+				{"Carol", f.knrID, 2, 150, 3},
 			},
 		},
 		{
 			round: 3, machineKey: "TAF", isDoubles: false,
 			results: []result{
-				{"Bob", f.tttID, 1, 350},
-				{"Dave", f.knrID, 2, 250},
+				{"Bob", f.tttID, 1, 350, 3},
+				{"Dave", f.knrID, 2, 250, 0},
 			},
 		},
 		{
 			round: 4, machineKey: "MM", isDoubles: false,
 			results: []result{
-				{"Alice", f.tttID, 1, 600},
-				{"Carol", f.knrID, 2, 700},
+				{"Alice", f.tttID, 1, 600, 0},
+				{"Carol", f.knrID, 2, 700, 3},
 			},
 		},
 	}
@@ -203,12 +210,28 @@ func newTestStore(t *testing.T) (*SQLiteStore, testFixture) {
 				TeamID:   r.team,
 				Position: r.position,
 				Score:    r.score,
+				Points:   r.points,
 			}); err != nil {
 				t.Fatalf("InsertGameResult %s round %d: %v", r.player, g.round, err)
 			}
 		}
 	}
 
+	// Lineups, of everyone who played.
+	for _, l := range []struct {
+		player string
+		team   int64
+	}{
+		{"Alice", f.tttID},
+		{"Bob", f.tttID},
+		{"Carol", f.knrID},
+		{"Dave", f.knrID},
+	} {
+		if err := s.InsertMatchLineup(ctx, MatchLineup{MatchID: f.matchID, PlayerID: players[l.player], TeamID: l.team}); err != nil {
+			t.Fatalf("InsertMatchLineup %s: %v", l.player, err)
+		}
+	}
+
 	// Second match: KNR vs TTT, week 2 at GPA (no games — future match).
 	f.match2ID, err = s.UpsertMatch(ctx, Match{
 		Key:        "mnp-23-2-KNR-TTT",
@@ -235,6 +258,7 @@ func TestGetTeamMachineStats(t *testing.T) {
 	type args struct {
 		teamKey  string
 		venueKey string
+		seasons  []int
 	}
 	type want struct {
 		stats []TeamMachineStats
@@ -277,6 +301,11 @@ func TestGetTeamMachineStats(t *testing.T) {
 				},
 			}},
 		},
+		"SeasonFilter": {
+			reason: "With a season filter, stats should only include games played in those seasons.",
+			args:   args{teamKey: "TTT", seasons: []int{22}},
+			want:   want{stats: nil},
+		},
 		"VenueFilter": {
 			reason: "With venue filter, stats should only include machines at that venue and games played there.",
 			args:   args{teamKey: "TTT", venueKey: "STN"},
@@ -303,7 +332,7 @@ func TestGetTeamMachineStats(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.GetTeamMachineStats(ctx, tc.args.teamKey, tc.args.venueKey)
+			got, err := s.GetTeamMachineStats(ctx, tc.args.teamKey, tc.args.venueKey, tc.args.seasons)
 			if err != nil {
 				t.Fatalf("GetTeamMachineStats: %v", err)
 			}
@@ -339,11 +368,54 @@ func TestGetLeagueP50(t *testing.T) {
 	}
 }
 
+func TestGetLeagueScores(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.GetLeagueScores(ctx)
+	if err != nil {
+		t.Fatalf("GetLeagueScores: %v", err)
+	}
+
+	want := map[string]LeagueScores{
+		"TAF": {200, 250, 300, 350, 400, 500},
+		"TZ":  {100, 150},
+		"MM":  {600, 700},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetLeagueScores(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPercentRank(t *testing.T) {
+	scores := LeagueScores{200, 250, 300, 350, 400, 500}
+
+	cases := map[string]struct {
+		scores LeagueScores
+		score  float64
+		want   float64
+	}{
+		"Lowest":   {scores: scores, score: 200, want: 0},
+		"Median":   {scores: scores, score: 325, want: 0.5},
+		"Tied":     {scores: scores, score: 350, want: 0.5},
+		"Highest":  {scores: scores, score: 1000, want: 1},
+		"NoScores": {score: 1000, want: 0},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.scores.PercentRank(tc.score); got != tc.want {
+				t.Errorf("PercentRank(%v): got %v, want %v", tc.score, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestGetPlayerMachineStats(t *testing.T) {
 	type args struct {
 		teamKey    string
 		machineKey string
 		venueKey   string
+		seasons    []int
 	}
 	type want struct {
 		stats []PlayerStats
@@ -364,6 +436,14 @@ func TestGetPlayerMachineStats(t *testing.T) {
 				{Name: "Bob", Games: 2, P50Score: 350, P90Score: 400},
 			}},
 		},
+		"SeasonFilter": {
+			reason: "With a season filter, should only include games played in those seasons.",
+			args:   args{teamKey: "TTT", machineKey: "TAF", seasons: []int{22, 23}},
+			want: want{stats: []PlayerStats{
+				{Name: "Alice", Games: 1, P50Score: 500, P90Score: 500},
+				{Name: "Bob", Games: 2, P50Score: 350, P90Score: 400},
+			}},
+		},
 		"NoResults": {
 			reason: "Should return nil when no roster players have results on a machine.",
 			args:   args{teamKey: "TTT", machineKey: "NONEXISTENT", venueKey: ""},
@@ -376,7 +456,7 @@ func TestGetPlayerMachineStats(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.GetPlayerMachineStats(ctx, tc.args.teamKey, tc.args.machineKey, tc.args.venueKey)
+			got, err := s.GetPlayerMachineStats(ctx, tc.args.teamKey, tc.args.machineKey, tc.args.venueKey, tc.args.seasons)
 			if err != nil {
 				t.Fatalf("GetPlayerMachineStats: %v", err)
 			}
@@ -387,10 +467,65 @@ func TestGetPlayerMachineStats(t *testing.T) {
 	}
 }
 
+func TestGetPlayerMachinePercentiles(t *testing.T) {
+	type args struct {
+		teamKey     string
+		machineKey  string
+		seasons     []int
+		percentiles []int
+	}
+	type want struct {
+		scores map[string][]float64
+		err    error
+	}
+
+	// TTT on TAF: Alice [500], Bob [350, 400].
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Percentiles": {
+			reason: "Should return each player's score at each percentile, in order, matching GetPlayerMachineStats' P50 and P90.",
+			args:   args{teamKey: "TTT", machineKey: "TAF", percentiles: []int{25, 50, 75, 90}},
+			want: want{scores: map[string][]float64{
+				"Alice": {500, 500, 500, 500},
+				"Bob":   {350, 350, 400, 400},
+			}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, should only include games played in those seasons.",
+			args:   args{teamKey: "TTT", machineKey: "TAF", seasons: []int{99}, percentiles: []int{50}},
+			want:   want{scores: map[string][]float64{}},
+		},
+		"OutOfRange": {
+			reason: "Percentiles outside 1 to 100 should be rejected.",
+			args:   args{teamKey: "TTT", machineKey: "TAF", percentiles: []int{0}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetPlayerMachinePercentiles(ctx, tc.args.teamKey, tc.args.machineKey, "", tc.args.seasons, tc.args.percentiles)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetPlayerMachinePercentiles(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.scores, got); diff != "" {
+				t.Errorf("\n%s\nGetPlayerMachinePercentiles(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestGetSinglePlayerMachineStats(t *testing.T) {
 	type args struct {
 		playerName string
 		venueKey   string
+		seasons    []int
 	}
 	type want struct {
 		stats []PlayerMachineStats
@@ -409,6 +544,11 @@ func TestGetSinglePlayerMachineStats(t *testing.T) {
 				{MachineKey: "TAF", Games: 2, P50Score: 350, P90Score: 400},
 			}},
 		},
+		"SeasonFilter": {
+			reason: "With a season filter, should only include games played in those seasons.",
+			args:   args{playerName: "Bob", seasons: []int{22}},
+			want:   want{stats: nil},
+		},
 		"VenueFilter": {
 			reason: "With venue filter, should only include games played at that venue.",
 			args:   args{playerName: "Bob", venueKey: "STN"},
@@ -423,7 +563,7 @@ func TestGetSinglePlayerMachineStats(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.GetSinglePlayerMachineStats(ctx, tc.args.playerName, tc.args.venueKey)
+			got, err := s.GetSinglePlayerMachineStats(ctx, tc.args.playerName, tc.args.venueKey, tc.args.seasons)
 			if err != nil {
 				t.Fatalf("GetSinglePlayerMachineStats: %v", err)
 			}
@@ -434,6 +574,143 @@ func TestGetSinglePlayerMachineStats(t *testing.T) {
 	}
 }
 
+func TestRecencyWeight(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Last season Alice played TAF twice, scoring lower than this season.
+	seasonID, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	oldID, err := s.UpsertTeam(ctx, Team{Key: "OLD", Name: "Old Team", SeasonID: seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	matchID, err := s.UpsertMatch(ctx, Match{Key: "mnp-22-1-OLD-OLD", SeasonID: seasonID, Week: 1, HomeTeamID: oldID, AwayTeamID: oldID})
+	if err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	for i, score := range []int64{100, 200} {
+		gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: i + 2, MachineKey: "TAF"})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: oldID, Position: 1, Score: score}); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		weight float64
+		want   PlayerMachineStats
+	}{
+		"Unweighted": {
+			reason: "Every season should count the same by default.",
+			weight: 1,
+			want:   PlayerMachineStats{MachineKey: "TAF", Games: 3, P50Score: 200, P90Score: 500},
+		},
+		"Weighted": {
+			reason: "Last season's two games should count for less than this season's one.",
+			weight: 0.25,
+			want:   PlayerMachineStats{MachineKey: "TAF", Games: 3, P50Score: 500, P90Score: 500},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			WithRecencyWeight(tc.weight)(s)
+
+			stats, err := s.GetSinglePlayerMachineStats(ctx, "Alice", "", nil)
+			if err != nil {
+				t.Fatalf("GetSinglePlayerMachineStats: %v", err)
+			}
+			var got PlayerMachineStats
+			for _, ps := range stats {
+				if ps.MachineKey == "TAF" {
+					got = ps
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetSinglePlayerMachineStats(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOpponentAdjustment(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// The league averages IPR 3. Carol is two points above average and Dave
+	// two below, so Alice and Carol's singles games count as against strong
+	// opponents, and Bob's singles game against Dave as against a weak one.
+	for name, ipr := range map[string]int{"Alice": 3, "Bob": 3, "Carol": 5, "Dave": 1} {
+		if err := s.UpsertPlayerIPR(ctx, name, ipr); err != nil {
+			t.Fatalf("UpsertPlayerIPR: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		reason     string
+		adjustment float64
+		player     string
+		want       map[string]PlayerMachineStats
+	}{
+		"Unadjusted": {
+			reason:     "Scores should be unadjusted by default.",
+			adjustment: 0,
+			player:     "Alice",
+			want: map[string]PlayerMachineStats{
+				"TAF": {MachineKey: "TAF", Games: 1, P50Score: 500, P90Score: 500},
+				"TZ":  {MachineKey: "TZ", Games: 1, P50Score: 100, P90Score: 100},
+				"MM":  {MachineKey: "MM", Games: 1, P50Score: 600, P90Score: 600},
+			},
+		},
+		"StrongOpponents": {
+			reason:     "Scores against opponents above average should be raised, and scores against average opponents left alone.",
+			adjustment: 0.1,
+			player:     "Alice",
+			want: map[string]PlayerMachineStats{
+				"TAF": {MachineKey: "TAF", Games: 1, P50Score: 500, P90Score: 500},
+				"TZ":  {MachineKey: "TZ", Games: 1, P50Score: 120, P90Score: 120},
+				"MM":  {MachineKey: "MM", Games: 1, P50Score: 720, P90Score: 720},
+			},
+		},
+		"WeakOpponents": {
+			reason:     "Scores against opponents below average should be lowered.",
+			adjustment: 0.1,
+			player:     "Bob",
+			want: map[string]PlayerMachineStats{
+				"TAF": {MachineKey: "TAF", Games: 2, P50Score: 280, P90Score: 400},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			WithOpponentAdjustment(tc.adjustment)(s)
+
+			stats, err := s.GetSinglePlayerMachineStats(ctx, tc.player, "", nil)
+			if err != nil {
+				t.Fatalf("GetSinglePlayerMachineStats: %v", err)
+			}
+			got := make(map[string]PlayerMachineStats, len(stats))
+			for _, ps := range stats {
+				got[ps.MachineKey] = ps
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("\n%s\nGetSinglePlayerMachineStats(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestGetPlayer(t *testing.T) {
 	s, _ := newTestStore(t)
 	ctx := context.Background()
@@ -449,6 +726,20 @@ func TestGetPlayer(t *testing.T) {
 	}
 }
 
+func TestListPlayerNames(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	got, err := s.ListPlayerNames(context.Background())
+	if err != nil {
+		t.Fatalf("ListPlayerNames: %v", err)
+	}
+
+	want := []string{"Alice", "Bob", "Carol", "Dave"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListPlayerNames(...): -want, +got:\n%s", diff)
+	}
+}
+
 func TestListMachines(t *testing.T) {
 	type args struct {
 		search string
@@ -507,6 +798,81 @@ func TestListMachines(t *testing.T) {
 	}
 }
 
+func TestListMachineSummaries(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	if err := s.UpsertMachineDetails(ctx, MachineDetails{Key: "TAF", Manufacturer: "Bally", Year: 1992, Source: "opdb", SourceID: "G5pe4-MePZv"}); err != nil {
+		t.Fatalf("UpsertMachineDetails: %v", err)
+	}
+
+	got, err := s.ListMachineSummaries(ctx, "")
+	if err != nil {
+		t.Fatalf("ListMachineSummaries: %v", err)
+	}
+
+	stn := Venue{Key: "STN", Name: "Seattle Tavern and Pool Hall"}
+	gpa := Venue{Key: "GPA", Name: "Georgetown Pizza and Arcade"}
+	want := []MachineSummary{
+		{Key: "MM", Name: "Medieval Madness", Venues: []Venue{gpa}, Games: 1},
+		{Key: "TAF", Name: "The Addams Family", Manufacturer: "Bally", Year: 1992, Venues: []Venue{stn}, Games: 2},
+		{Key: "TZ", Name: "Twilight Zone", Venues: []Venue{gpa, stn}, Games: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListMachineSummaries(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListUnmatchedMachines(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	if err := s.UpsertMachineDetails(ctx, MachineDetails{Key: "TAF", Manufacturer: "Bally", Year: 1992, Source: "opdb", SourceID: "G5pe4-MePZv"}); err != nil {
+		t.Fatalf("UpsertMachineDetails: %v", err)
+	}
+
+	got, err := s.ListUnmatchedMachines(ctx)
+	if err != nil {
+		t.Fatalf("ListUnmatchedMachines: %v", err)
+	}
+
+	want := []Machine{
+		{Key: "MM", Name: "Medieval Madness"},
+		{Key: "TZ", Name: "Twilight Zone"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListUnmatchedMachines(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestMachineAliases(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	aliases := []MachineAlias{
+		{Key: "TAF", Source: "opdb", SourceID: "G5pe4"},
+		{Key: "TZ", Source: "opdb", SourceID: "G4ODR"},
+		{Key: "TZ", Source: "other", SourceID: "123"},
+		// Aliasing a machine again should replace its alias.
+		{Key: "TAF", Source: "opdb", SourceID: "G5pe4-MePZv"},
+	}
+	for _, a := range aliases {
+		if err := s.UpsertMachineAlias(ctx, a); err != nil {
+			t.Fatalf("UpsertMachineAlias: %v", err)
+		}
+	}
+
+	got, err := s.ListMachineAliases(ctx, "opdb")
+	if err != nil {
+		t.Fatalf("ListMachineAliases: %v", err)
+	}
+
+	want := map[string]string{"TAF": "G5pe4-MePZv", "TZ": "G4ODR"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListMachineAliases(...): -want, +got:\n%s", diff)
+	}
+}
+
 func TestListVenues(t *testing.T) {
 	type args struct {
 		search string
@@ -557,225 +923,506 @@ func TestListVenues(t *testing.T) {
 	}
 }
 
-func TestListTeams(t *testing.T) {
-	type args struct {
-		search string
+func TestRebuildStats(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	type stats struct {
+		teams   map[string][]TeamMachineStats
+		rosters map[string][]PlayerStats
+		players map[string][]PlayerMachineStats
 	}
-	type want struct {
-		teams []TeamSummary
+	load := func(t *testing.T) stats {
+		t.Helper()
+		st := stats{
+			teams:   make(map[string][]TeamMachineStats),
+			rosters: make(map[string][]PlayerStats),
+			players: make(map[string][]PlayerMachineStats),
+		}
+		for _, team := range []string{"TTT", "KNR", "NONEXISTENT"} {
+			ts, err := s.GetTeamMachineStats(ctx, team, "", nil)
+			if err != nil {
+				t.Fatalf("GetTeamMachineStats: %v", err)
+			}
+			st.teams[team] = ts
+			for _, machine := range []string{"TAF", "TZ", "MM"} {
+				ps, err := s.GetPlayerMachineStats(ctx, team, machine, "", nil)
+				if err != nil {
+					t.Fatalf("GetPlayerMachineStats: %v", err)
+				}
+				st.rosters[team+"/"+machine] = ps
+			}
+		}
+		for _, player := range []string{"Alice", "Bob", "Carol", "Dave"} {
+			ps, err := s.GetSinglePlayerMachineStats(ctx, player, "", nil)
+			if err != nil {
+				t.Fatalf("GetSinglePlayerMachineStats: %v", err)
+			}
+			st.players[player] = ps
+		}
+		return st
+	}
+
+	want := load(t)
+
+	// Rebuilding again shouldn't duplicate stats.
+	for range 2 {
+		if err := s.RebuildStats(ctx); err != nil {
+			t.Fatalf("RebuildStats: %v", err)
+		}
+	}
+
+	got := load(t)
+
+	// Machines played equally often may be in any order.
+	opts := []cmp.Option{
+		cmp.AllowUnexported(stats{}),
+		cmpopts.SortSlices(func(a, b TeamMachineStats) bool { return a.MachineKey < b.MachineKey }),
+		cmpopts.SortSlices(func(a, b PlayerMachineStats) bool { return a.MachineKey < b.MachineKey }),
+	}
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("RebuildStats(): stats looked up should match stats computed from game results: -want, +got:\n%s", diff)
 	}
+}
+
+func TestLookupStats(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	type query func() (any, error)
+
 	cases := map[string]struct {
 		reason string
-		args   args
-		want   want
+		cte    query
+		lookup query
 	}{
-		"All": {
-			reason: "Without search, should return all teams in the current season.",
-			args:   args{search: ""},
-			want: want{teams: []TeamSummary{
-				{Key: "KNR", Name: "Knight Riders", Venue: "Georgetown Pizza and Arcade (GPA)"},
-				{Key: "TTT", Name: "The Trailer Trashers", Venue: "Seattle Tavern and Pool Hall (STN)"},
-			}},
+		"TeamMachineAgg": {
+			reason: "A team's stats looked up should match those computed from game results.",
+			cte:    func() (any, error) { return s.GetTeamMachineAgg(ctx, "TTT", "", nil) },
+			lookup: func() (any, error) { return s.lookupTeamMachineAgg(ctx, "TTT") },
 		},
-		"SearchByKey": {
-			reason: "Should match teams by case-insensitive key substring.",
-			args:   args{search: "ttt"},
-			want: want{teams: []TeamSummary{
-				{Key: "TTT", Name: "The Trailer Trashers", Venue: "Seattle Tavern and Pool Hall (STN)"},
-			}},
+		"TopPlayers": {
+			reason: "A team's top players looked up should match those computed from game results.",
+			cte:    func() (any, error) { return s.GetTopPlayers(ctx, "KNR", "", nil) },
+			lookup: func() (any, error) { return s.lookupTopPlayers(ctx, "KNR") },
+		},
+		"SinglePlayerMachineStats": {
+			reason: "A player's stats looked up should match those computed from game results.",
+			cte:    func() (any, error) { return s.GetSinglePlayerMachineStats(ctx, "Alice", "", nil) },
+			lookup: func() (any, error) { return s.lookupSinglePlayerMachineStats(ctx, "Alice") },
+		},
+		"PlayerMachineStats": {
+			reason: "A roster's stats on a machine looked up should match those computed from game results.",
+			cte:    func() (any, error) { return s.GetPlayerMachineStats(ctx, "TTT", "TAF", "", nil) },
+			lookup: func() (any, error) { return s.lookupPlayerMachineStats(ctx, "TTT", "TAF") },
+		},
+		"UnknownTeam": {
+			reason: "A team that doesn't exist should have no stats either way.",
+			cte:    func() (any, error) { return s.GetTeamMachineAgg(ctx, "NONEXISTENT", "", nil) },
+			lookup: func() (any, error) { return s.lookupTeamMachineAgg(ctx, "NONEXISTENT") },
 		},
 	}
 
-	s, _ := newTestStore(t)
-	ctx := context.Background()
+	// Machines played equally often may be in any order.
+	opts := []cmp.Option{
+		cmpopts.EquateEmpty(),
+		cmpopts.SortSlices(func(a, b TeamMachineStats) bool { return a.MachineKey < b.MachineKey }),
+		cmpopts.SortSlices(func(a, b PlayerMachineStats) bool { return a.MachineKey < b.MachineKey }),
+	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.ListTeams(ctx, tc.args.search)
+			// Forget any rebuild so the stats are computed from game results.
+			if err := s.SetMetadata(ctx, metadataStatsRebuilt, ""); err != nil {
+				t.Fatalf("SetMetadata: %v", err)
+			}
+			want, err := tc.cte()
 			if err != nil {
-				t.Fatalf("ListTeams: %v", err)
+				t.Fatalf("computing stats: %v", err)
 			}
-			if diff := cmp.Diff(tc.want.teams, got); diff != "" {
-				t.Errorf("\n%s\nListTeams(...): -want, +got:\n%s", tc.reason, diff)
+			if err := s.RebuildStats(ctx); err != nil {
+				t.Fatalf("RebuildStats: %v", err)
+			}
+			got, err := tc.lookup()
+			if err != nil {
+				t.Fatalf("looking up stats: %v", err)
+			}
+			if diff := cmp.Diff(want, got, opts...); diff != "" {
+				t.Errorf("\n%s\n-computed, +looked up:\n%s", tc.reason, diff)
 			}
 		})
 	}
 }
 
-func TestGetMetadata(t *testing.T) {
-	type args struct {
-		key string
+func TestRebuiltStatsStale(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Two stores sharing a file stand in for two processes, e.g. mnp serve
+	// and mnp db import-csv.
+	path := filepath.Join(t.TempDir(), "mnp.db")
+	if err := s.Export(ctx, path); err != nil {
+		t.Fatalf("Export: %v", err)
 	}
-	type want struct {
-		value string
+	serve, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { serve.Close() })
+	imp, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
 	}
+	t.Cleanup(func() { imp.Close() })
+
 	cases := map[string]struct {
 		reason string
-		args   args
-		want   want
+		write  string
 	}{
-		"Exists": {
-			reason: "Should return the stored value for an existing key.",
-			args:   args{key: "mnp_last_sync"},
-			want:   want{value: "2024-01-15T00:00:00Z"},
+		"InsertGameResult": {
+			reason: "Adding a game result should make rebuilt stats stale.",
+			write: `
+				INSERT INTO game_results (game_id, player_id, team_id, position, score)
+				SELECT g.id, r.player_id, r.team_id, 5, 100 FROM games g, rosters r
+				WHERE NOT EXISTS (SELECT 1 FROM game_results gr WHERE gr.game_id = g.id AND gr.player_id = r.player_id)
+				LIMIT 1`,
 		},
-		"Missing": {
-			reason: "Should return empty string for a missing key.",
-			args:   args{key: "nonexistent"},
-			want:   want{value: ""},
+		"UpdateGameResult": {
+			reason: "Changing a game result should make rebuilt stats stale.",
+			write:  "UPDATE game_results SET score = score + 1",
+		},
+		"DeleteGameResult": {
+			reason: "Removing a game result should make rebuilt stats stale.",
+			write:  "DELETE FROM game_results WHERE rowid = (SELECT MAX(rowid) FROM game_results)",
+		},
+		"InsertRoster": {
+			reason: "Adding a player to a roster should make rebuilt stats stale.",
+			write: `
+				INSERT INTO rosters (player_id, team_id)
+				SELECT p.id, t.id FROM players p, teams t
+				WHERE NOT EXISTS (SELECT 1 FROM rosters r WHERE r.player_id = p.id AND r.team_id = t.id)
+				LIMIT 1`,
+		},
+		"DeleteRoster": {
+			reason: "Removing a player from a roster should make rebuilt stats stale.",
+			write:  "DELETE FROM rosters WHERE rowid = (SELECT MAX(rowid) FROM rosters)",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := serve.RebuildStats(ctx); err != nil {
+				t.Fatalf("RebuildStats: %v", err)
+			}
+			if ok, err := serve.useRollups(ctx, "", nil); err != nil || !ok {
+				t.Fatalf("useRollups(...): want true after RebuildStats, got %v, %v", ok, err)
+			}
+			if _, err := imp.DB().ExecContext(ctx, tc.write); err != nil {
+				t.Fatalf("ExecContext: %v", err)
+			}
+			ok, err := serve.useRollups(ctx, "", nil)
+			if err != nil {
+				t.Fatalf("useRollups: %v", err)
+			}
+			if ok {
+				t.Errorf("\n%s\nuseRollups(...): want false, got true", tc.reason)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		query  string
+		want   []SearchResult
+	}{
+		"Player": {
+			reason: "Should match players by the start of their name.",
+			query:  "ali",
+			want:   []SearchResult{{Kind: SearchPlayer, Key: "Alice", Name: "Alice"}},
+		},
+		"EveryWord": {
+			reason: "Should match entities with a word starting with each word of the query.",
+			query:  "twi zo",
+			want:   []SearchResult{{Kind: SearchMachine, Key: "TZ", Name: "Twilight Zone"}},
+		},
+		"Mixed": {
+			reason: "Should match teams, machines, and venues by key or name.",
+			query:  "T",
+			want: []SearchResult{
+				{Kind: SearchMachine, Key: "TAF", Name: "The Addams Family"},
+				{Kind: SearchMachine, Key: "TZ", Name: "Twilight Zone"},
+				{Kind: SearchTeam, Key: "TTT", Name: "The Trailer Trashers"},
+				{Kind: SearchVenue, Key: "STN", Name: "Seattle Tavern and Pool Hall"},
+			},
+		},
+		"Syntax": {
+			reason: "FTS5 operators should be searched for rather than interpreted.",
+			query:  `ali OR "bob`,
+			want:   nil,
+		},
+		"Empty": {
+			reason: "An empty query should match nothing.",
+			query:  " ",
+			want:   nil,
 		},
 	}
 
 	s, _ := newTestStore(t)
 	ctx := context.Background()
+	if err := s.RebuildSearchIndex(ctx); err != nil {
+		t.Fatalf("RebuildSearchIndex: %v", err)
+	}
+
+	// Rebuilding again shouldn't duplicate results.
+	if err := s.RebuildSearchIndex(ctx); err != nil {
+		t.Fatalf("RebuildSearchIndex: %v", err)
+	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.GetMetadata(ctx, tc.args.key)
+			got, err := s.Search(ctx, tc.query)
 			if err != nil {
-				t.Fatalf("GetMetadata: %v", err)
+				t.Fatalf("Search: %v", err)
 			}
-			if diff := cmp.Diff(tc.want.value, got); diff != "" {
-				t.Errorf("\n%s\nGetMetadata(...): -want, +got:\n%s", tc.reason, diff)
+			sort := cmpopts.SortSlices(func(a, b SearchResult) bool { return a.Kind+a.Key < b.Kind+b.Key })
+			if diff := cmp.Diff(tc.want, got, sort); diff != "" {
+				t.Errorf("\n%s\nSearch(%q): -want, +got:\n%s", tc.reason, tc.query, diff)
 			}
 		})
 	}
 }
 
-func TestGetVenueMachines(t *testing.T) {
+func TestQueryReadOnly(t *testing.T) {
+	type want struct {
+		result *QueryResult
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		query  string
+		want   want
+	}{
+		"Select": {
+			reason: "A SELECT should return its columns and rows.",
+			query:  "SELECT key, name FROM venues ORDER BY key;",
+			want: want{result: &QueryResult{
+				Columns: []string{"key", "name"},
+				Rows: [][]any{
+					{"GPA", "Georgetown Pizza and Arcade"},
+					{"STN", "Seattle Tavern and Pool Hall"},
+				},
+			}},
+		},
+		"QuotedSemicolon": {
+			reason: "Semicolons in literals and comments shouldn't count as separating statements.",
+			query:  "-- Just one; really.\nWITH v AS (SELECT ';' AS \"a;b\", NULL AS n) SELECT * FROM v /* ; */",
+			want: want{result: &QueryResult{
+				Columns: []string{"a;b", "n"},
+				Rows:    [][]any{{";", nil}},
+			}},
+		},
+		"Delete": {
+			reason: "Statements other than SELECT should be rejected.",
+			query:  "DELETE FROM venues",
+			want:   want{err: ErrNotReadOnly},
+		},
+		"MultipleStatements": {
+			reason: "A SELECT followed by another statement should be rejected.",
+			query:  "SELECT 1; PRAGMA query_only = OFF; DELETE FROM venues",
+			want:   want{err: ErrNotReadOnly},
+		},
+		"WriteInWith": {
+			reason: "A WITH clause before a write should fail to write.",
+			query:  "WITH v AS (SELECT 1) DELETE FROM venues",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
 	s, _ := newTestStore(t)
 	ctx := context.Background()
 
-	got, err := s.GetVenueMachines(ctx, "STN")
-	if err != nil {
-		t.Fatalf("GetVenueMachines: %v", err)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.QueryReadOnly(ctx, tc.query)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nQueryReadOnly(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nQueryReadOnly(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
 	}
 
-	want := map[string]bool{"TAF": true, "TZ": true}
-	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("GetVenueMachines(...): -want, +got:\n%s", diff)
+	// Nothing should have been deleted, and the store should still be writable.
+	venues, err := s.ListVenues(ctx, "")
+	if err != nil {
+		t.Fatalf("ListVenues: %v", err)
+	}
+	if len(venues) != 2 {
+		t.Errorf("ListVenues(...): want 2 venues, got %d", len(venues))
+	}
+	if _, err := s.UpsertVenue(ctx, "ANC", "Add-a-Ball"); err != nil {
+		t.Errorf("UpsertVenue after QueryReadOnly: %v", err)
 	}
 }
 
-func TestGetMachineNames(t *testing.T) {
+func TestListVenueSummaries(t *testing.T) {
 	s, _ := newTestStore(t)
 	ctx := context.Background()
 
-	got, err := s.GetMachineNames(ctx)
+	got, err := s.ListVenueSummaries(ctx, "")
 	if err != nil {
-		t.Fatalf("GetMachineNames: %v", err)
+		t.Fatalf("ListVenueSummaries: %v", err)
 	}
 
-	want := map[string]string{
-		"TAF": "The Addams Family",
-		"TZ":  "Twilight Zone",
-		"MM":  "Medieval Madness",
+	want := []VenueSummary{
+		{Key: "GPA", Name: "Georgetown Pizza and Arcade", Machines: 2, HomeTeams: []string{"KNR"}},
+		{Key: "STN", Name: "Seattle Tavern and Pool Hall", Machines: 2, HomeTeams: []string{"TTT"}},
 	}
 	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("GetMachineNames(): -want, +got:\n%s", diff)
+		t.Errorf("ListVenueSummaries(...): -want, +got:\n%s", diff)
 	}
 }
 
-func TestLoadedSeasons(t *testing.T) {
+func TestListTeams(t *testing.T) {
+	type args struct {
+		search string
+	}
+	type want struct {
+		teams []TeamSummary
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"All": {
+			reason: "Without search, should return all teams in the current season.",
+			args:   args{search: ""},
+			want: want{teams: []TeamSummary{
+				{Key: "KNR", Name: "Knight Riders", Venue: "Georgetown Pizza and Arcade (GPA)"},
+				{Key: "TTT", Name: "The Trailer Trashers", Venue: "Seattle Tavern and Pool Hall (STN)"},
+			}},
+		},
+		"SearchByKey": {
+			reason: "Should match teams by case-insensitive key substring.",
+			args:   args{search: "ttt"},
+			want: want{teams: []TeamSummary{
+				{Key: "TTT", Name: "The Trailer Trashers", Venue: "Seattle Tavern and Pool Hall (STN)"},
+			}},
+		},
+	}
+
 	s, _ := newTestStore(t)
 	ctx := context.Background()
 
-	got, err := s.LoadedSeasons(ctx)
-	if err != nil {
-		t.Fatalf("LoadedSeasons: %v", err)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.ListTeams(ctx, tc.args.search, 0)
+			if err != nil {
+				t.Fatalf("ListTeams: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.teams, got); diff != "" {
+				t.Errorf("\n%s\nListTeams(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
 	}
+}
 
-	want := map[int]bool{23: true}
-	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("LoadedSeasons(): -want, +got:\n%s", diff)
+func TestSize(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	got, err := s.Size(context.Background())
+	if err != nil {
+		t.Fatalf("Size: %v", err)
+	}
+	if got <= 0 {
+		t.Errorf("Size(...): got %d, want a positive size", got)
 	}
 }
 
-func TestMaxSeasonNumber(t *testing.T) {
+func TestCurrentSeasonIsHighestNumber(t *testing.T) {
 	s, _ := newTestStore(t)
 	ctx := context.Background()
 
-	got, err := s.MaxSeasonNumber(ctx)
+	// Import an older season after the current one, as import-csv does. Its
+	// row ID is higher, but it shouldn't become the current season.
+	seasonID, err := s.UpsertSeason(ctx, 12)
 	if err != nil {
-		t.Fatalf("MaxSeasonNumber: %v", err)
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	teamID, err := s.UpsertTeam(ctx, Team{Key: "OLD", Name: "Old Timers", SeasonID: seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.UpsertRoster(ctx, alice, teamID, "P"); err != nil {
+		t.Fatalf("UpsertRoster: %v", err)
 	}
 
-	if diff := cmp.Diff(23, got); diff != "" {
-		t.Errorf("MaxSeasonNumber(): -want, +got:\n%s", diff)
+	teams, err := s.ListTeams(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("ListTeams: %v", err)
+	}
+	wantTeams := []TeamSummary{
+		{Key: "KNR", Name: "Knight Riders", Venue: "Georgetown Pizza and Arcade (GPA)"},
+		{Key: "TTT", Name: "The Trailer Trashers", Venue: "Seattle Tavern and Pool Hall (STN)"},
+	}
+	if diff := cmp.Diff(wantTeams, teams); diff != "" {
+		t.Errorf("ListTeams(...) should list the highest-numbered season's teams: -want, +got:\n%s", diff)
+	}
+
+	seasons, err := s.ListSeasons(ctx)
+	if err != nil {
+		t.Fatalf("ListSeasons: %v", err)
+	}
+	if diff := cmp.Diff([]int{23, 12}, seasons); diff != "" {
+		t.Errorf("ListSeasons(...) should list seasons newest first: -want, +got:\n%s", diff)
+	}
+
+	old, err := s.ListTeams(ctx, "", 12)
+	if err != nil {
+		t.Fatalf("ListTeams: %v", err)
+	}
+	if diff := cmp.Diff([]TeamSummary{{Key: "OLD", Name: "Old Timers"}}, old); diff != "" {
+		t.Errorf("ListTeams(...) should list an earlier season's teams when asked: -want, +got:\n%s", diff)
+	}
+
+	p, err := s.GetPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("GetPlayer: %v", err)
+	}
+	if diff := cmp.Diff("TTT", p.TeamKey); diff != "" {
+		t.Errorf("GetPlayer(...) should return the player's team in the highest-numbered season: -want, +got:\n%s", diff)
 	}
 }
 
-func TestListSchedule(t *testing.T) {
-	// Fixture has two matches:
-	//   Week 1: 2024-01-15, TTT (home) vs KNR (away) at STN
-	//   Week 2: 2024-01-22, KNR (home) vs TTT (away) at GPA
+func TestGetMetadata(t *testing.T) {
+	type args struct {
+		key string
+	}
+	type want struct {
+		value string
+	}
 	cases := map[string]struct {
 		reason string
-		after  string
-		want   []ScheduleMatch
+		args   args
+		want   want
 	}{
-		"AllMatches": {
-			reason: "A date before both matches should return both, ordered by week.",
-			after:  "2024-01-01",
-			want: []ScheduleMatch{
-				{
-					Week:        1,
-					Date:        "2024-01-15",
-					HomeTeamKey: "TTT",
-					HomeTeam:    "The Trailer Trashers",
-					AwayTeamKey: "KNR",
-					AwayTeam:    "Knight Riders",
-					VenueKey:    "STN",
-					Venue:       "Seattle Tavern and Pool Hall",
-				},
-				{
-					Week:        2,
-					Date:        "2024-01-22",
-					HomeTeamKey: "KNR",
-					HomeTeam:    "Knight Riders",
-					AwayTeamKey: "TTT",
-					AwayTeam:    "The Trailer Trashers",
-					VenueKey:    "GPA",
-					Venue:       "Georgetown Pizza and Arcade",
-				},
-			},
-		},
-		"InclusiveDate": {
-			reason: "A date equal to a match's date should include that match.",
-			after:  "2024-01-22",
-			want: []ScheduleMatch{
-				{
-					Week:        2,
-					Date:        "2024-01-22",
-					HomeTeamKey: "KNR",
-					HomeTeam:    "Knight Riders",
-					AwayTeamKey: "TTT",
-					AwayTeam:    "The Trailer Trashers",
-					VenueKey:    "GPA",
-					Venue:       "Georgetown Pizza and Arcade",
-				},
-			},
-		},
-		"AfterBoth": {
-			reason: "A date after all matches should return nil.",
-			after:  "2024-02-01",
-			want:   nil,
+		"Exists": {
+			reason: "Should return the stored value for an existing key.",
+			args:   args{key: "mnp_last_sync"},
+			want:   want{value: "2024-01-15T00:00:00Z"},
 		},
-		"BetweenMatches": {
-			reason: "A date between the two matches should return only the later one.",
-			after:  "2024-01-16",
-			want: []ScheduleMatch{
-				{
-					Week:        2,
-					Date:        "2024-01-22",
-					HomeTeamKey: "KNR",
-					HomeTeam:    "Knight Riders",
-					AwayTeamKey: "TTT",
-					AwayTeam:    "The Trailer Trashers",
-					VenueKey:    "GPA",
-					Venue:       "Georgetown Pizza and Arcade",
-				},
-			},
+		"Missing": {
+			reason: "Should return empty string for a missing key.",
+			args:   args{key: "nonexistent"},
+			want:   want{value: ""},
 		},
 	}
 
@@ -784,13 +1431,2327 @@ func TestListSchedule(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := s.ListSchedule(ctx, tc.after)
+			got, err := s.GetMetadata(ctx, tc.args.key)
 			if err != nil {
-				t.Fatalf("ListSchedule: %v", err)
+				t.Fatalf("GetMetadata: %v", err)
 			}
-			if diff := cmp.Diff(tc.want, got); diff != "" {
-				t.Errorf("\n%s\nListSchedule(...): -want, +got:\n%s", tc.reason, diff)
+			if diff := cmp.Diff(tc.want.value, got); diff != "" {
+				t.Errorf("\n%s\nGetMetadata(...): -want, +got:\n%s", tc.reason, diff)
 			}
 		})
 	}
 }
+
+func TestSyncStatus(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	started := time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)
+	if err := s.RecordSyncAttempt(ctx, started, 90*time.Second, errors.New("fetch archive: no network")); err != nil {
+		t.Fatalf("RecordSyncAttempt: %v", err)
+	}
+	f, err := s.GetFreshness(ctx)
+	if err != nil {
+		t.Fatalf("GetFreshness: %v", err)
+	}
+
+	got, err := s.GetSyncStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetSyncStatus: %v", err)
+	}
+	want := SyncStatus{Freshness: f, Attempted: started, Duration: 90 * time.Second, Error: "fetch archive: no network"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetSyncStatus(...): -want, +got:\n%s", diff)
+	}
+
+	// A later successful attempt should clear the error.
+	if err := s.RecordSyncAttempt(ctx, started.Add(15*time.Minute), time.Second, nil); err != nil {
+		t.Fatalf("RecordSyncAttempt: %v", err)
+	}
+	got, err = s.GetSyncStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetSyncStatus: %v", err)
+	}
+	if got.Failed() {
+		t.Errorf("GetSyncStatus(...): want a successful attempt, got error %q", got.Error)
+	}
+}
+
+func TestGetTeamMachineStatsAtVenueInSeason(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// In season 22 STN had MM, which it no longer has, and Alice played it
+	// there.
+	s22, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	if err := s.LoadMatchBatch(ctx, []MatchGames{{
+		Match: Match{Key: "mnp-22-1-KNR-TTT", SeasonID: s22, Week: 1, HomeTeamID: f.knrID, AwayTeamID: f.tttID, VenueID: f.stnID},
+		Games: []GameResults{{
+			Game:    Game{Round: 2, MachineKey: "MM"},
+			Results: []PlayerResult{{PlayerName: "Alice", TeamID: f.tttID, Position: 1, Score: 800, Points: 3}},
+		}},
+	}}); err != nil {
+		t.Fatalf("LoadMatchBatch: %v", err)
+	}
+
+	cases := map[string]struct {
+		reason  string
+		seasons []int
+		want    []string
+	}{
+		"Now": {
+			reason: "Without seasons, stats at a venue should only include the machines there now.",
+			want:   []string{"TAF", "TZ"},
+		},
+		"PastSeason": {
+			reason:  "In a past season, stats at a venue should include the machines there that season.",
+			seasons: []int{22},
+			want:    []string{"MM"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stats, err := s.GetTeamMachineStats(ctx, "TTT", "STN", tc.seasons)
+			if err != nil {
+				t.Fatalf("GetTeamMachineStats: %v", err)
+			}
+			got := make([]string, 0, len(stats))
+			for _, st := range stats {
+				got = append(got, st.MachineKey)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("\n%s\nGetTeamMachineStats(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetVenueMachines(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// In season 22 STN had TAF and MM, and GPA's machines are only known
+	// from the games played there. XYZ isn't a known machine.
+	s22, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	for _, key := range []string{"TAF", "MM"} {
+		if err := s.UpsertVenueMachine(ctx, f.stnID, s22, key); err != nil {
+			t.Fatalf("UpsertVenueMachine: %v", err)
+		}
+	}
+	if err := s.LoadMatchBatch(ctx, []MatchGames{{
+		Match: Match{Key: "mnp-22-1-KNR-TTT", SeasonID: s22, Week: 1, HomeTeamID: f.knrID, AwayTeamID: f.tttID, VenueID: f.gpaID},
+		Games: []GameResults{
+			{Game: Game{Round: 1, MachineKey: "TZ"}},
+			{Game: Game{Round: 2, MachineKey: "XYZ"}},
+		},
+	}}); err != nil {
+		t.Fatalf("LoadMatchBatch: %v", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		venue  string
+		season int
+		want   map[string]bool
+	}{
+		"Latest": {
+			reason: "Season 0 should return the venue's machines in the latest season.",
+			venue:  "STN",
+			want:   map[string]bool{"TAF": true, "TZ": true},
+		},
+		"EarlierSeason": {
+			reason: "An earlier season should return the venue's machines that season.",
+			venue:  "STN",
+			season: 22,
+			want:   map[string]bool{"TAF": true, "MM": true},
+		},
+		"Played": {
+			reason: "Known machines played at a venue should be at the venue that season.",
+			venue:  "GPA",
+			season: 22,
+			want:   map[string]bool{"TZ": true},
+		},
+		"NoMachines": {
+			reason: "A season the venue had no machines in should return none.",
+			venue:  "STN",
+			season: 21,
+			want:   map[string]bool{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetVenueMachines(ctx, tc.venue, tc.season)
+			if err != nil {
+				t.Fatalf("GetVenueMachines: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetVenueMachines(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("History", func(t *testing.T) {
+		got, err := s.GetVenueMachineHistory(ctx, "STN")
+		if err != nil {
+			t.Fatalf("GetVenueMachineHistory: %v", err)
+		}
+		want := []VenueSeason{
+			{Season: 22, Machines: []string{"MM", "TAF"}, Added: []string{"MM", "TAF"}},
+			{Season: 23, Machines: []string{"TAF", "TZ"}, Added: []string{"TZ"}, Removed: []string{"MM"}},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("GetVenueMachineHistory(...): -want, +got:\n%s", diff)
+		}
+	})
+}
+
+func TestReplaceVenueMachines(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// STN removed TZ this season, after a match was played on it there.
+	if err := s.LoadMatchBatch(ctx, []MatchGames{{
+		Match: Match{Key: "mnp-23-5-TTT-KNR", SeasonID: f.seasonID, Week: 5, HomeTeamID: f.tttID, AwayTeamID: f.knrID, VenueID: f.stnID},
+		Games: []GameResults{{Game: Game{Round: 1, MachineKey: "TZ"}}},
+	}}); err != nil {
+		t.Fatalf("LoadMatchBatch: %v", err)
+	}
+	if err := s.ReplaceVenueMachines(ctx, f.stnID, f.seasonID, []string{"TAF", "MM"}); err != nil {
+		t.Fatalf("ReplaceVenueMachines: %v", err)
+	}
+
+	// Reloading the match, as every sync does, shouldn't put TZ back.
+	if err := s.LoadMatchBatch(ctx, []MatchGames{{
+		Match: Match{Key: "mnp-23-5-TTT-KNR", SeasonID: f.seasonID, Week: 5, HomeTeamID: f.tttID, AwayTeamID: f.knrID, VenueID: f.stnID},
+		Games: []GameResults{{Game: Game{Round: 1, MachineKey: "TZ"}}},
+	}}); err != nil {
+		t.Fatalf("LoadMatchBatch: %v", err)
+	}
+
+	got, err := s.GetVenueMachines(ctx, "STN", 0)
+	if err != nil {
+		t.Fatalf("GetVenueMachines: %v", err)
+	}
+	if diff := cmp.Diff(map[string]bool{"TAF": true, "MM": true}, got); diff != "" {
+		t.Errorf("ReplaceVenueMachines(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetMachineNames(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.GetMachineNames(ctx)
+	if err != nil {
+		t.Fatalf("GetMachineNames: %v", err)
+	}
+
+	want := map[string]string{
+		"TAF": "The Addams Family",
+		"TZ":  "Twilight Zone",
+		"MM":  "Medieval Madness",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetMachineNames(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetMachineYears(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for _, d := range []MachineDetails{
+		{Key: "TAF", Manufacturer: "Bally", Year: 1992, Source: "opdb", SourceID: "G5pe4"},
+		{Key: "MM", Manufacturer: "Williams", Source: "opdb", SourceID: "G4ODR"},
+	} {
+		if err := s.UpsertMachineDetails(ctx, d); err != nil {
+			t.Fatalf("UpsertMachineDetails: %v", err)
+		}
+	}
+
+	got, err := s.GetMachineYears(ctx)
+	if err != nil {
+		t.Fatalf("GetMachineYears: %v", err)
+	}
+
+	// MM's year isn't known, and TZ has no details at all.
+	want := map[string]int{"TAF": 1992}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetMachineYears(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestLoadedSeasons(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.LoadedSeasons(ctx)
+	if err != nil {
+		t.Fatalf("LoadedSeasons: %v", err)
+	}
+
+	want := map[int]bool{23: true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadedSeasons(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestMaxSeasonNumber(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.MaxSeasonNumber(ctx)
+	if err != nil {
+		t.Fatalf("MaxSeasonNumber: %v", err)
+	}
+
+	if diff := cmp.Diff(23, got); diff != "" {
+		t.Errorf("MaxSeasonNumber(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListSchedule(t *testing.T) {
+	// Fixture has two matches:
+	//   Week 1: 2024-01-15, TTT (home) vs KNR (away) at STN
+	//   Week 2: 2024-01-22, KNR (home) vs TTT (away) at GPA
+	cases := map[string]struct {
+		reason string
+		after  string
+		want   []ScheduleMatch
+	}{
+		"AllMatches": {
+			reason: "A date before both matches should return both, ordered by week.",
+			after:  "2024-01-01",
+			want: []ScheduleMatch{
+				{
+					Key:         "mnp-23-1-TTT-KNR",
+					Week:        1,
+					Stage:       StageRegular,
+					Date:        "2024-01-15",
+					HomeTeamKey: "TTT",
+					HomeTeam:    "The Trailer Trashers",
+					AwayTeamKey: "KNR",
+					AwayTeam:    "Knight Riders",
+					VenueKey:    "STN",
+					Venue:       "Seattle Tavern and Pool Hall",
+				},
+				{
+					Key:         "mnp-23-2-KNR-TTT",
+					Week:        2,
+					Stage:       StageRegular,
+					Date:        "2024-01-22",
+					HomeTeamKey: "KNR",
+					HomeTeam:    "Knight Riders",
+					AwayTeamKey: "TTT",
+					AwayTeam:    "The Trailer Trashers",
+					VenueKey:    "GPA",
+					Venue:       "Georgetown Pizza and Arcade",
+				},
+			},
+		},
+		"InclusiveDate": {
+			reason: "A date equal to a match's date should include that match.",
+			after:  "2024-01-22",
+			want: []ScheduleMatch{
+				{
+					Key:         "mnp-23-2-KNR-TTT",
+					Week:        2,
+					Stage:       StageRegular,
+					Date:        "2024-01-22",
+					HomeTeamKey: "KNR",
+					HomeTeam:    "Knight Riders",
+					AwayTeamKey: "TTT",
+					AwayTeam:    "The Trailer Trashers",
+					VenueKey:    "GPA",
+					Venue:       "Georgetown Pizza and Arcade",
+				},
+			},
+		},
+		"AfterBoth": {
+			reason: "A date after all matches should return nil.",
+			after:  "2024-02-01",
+			want:   nil,
+		},
+		"BetweenMatches": {
+			reason: "A date between the two matches should return only the later one.",
+			after:  "2024-01-16",
+			want: []ScheduleMatch{
+				{
+					Key:         "mnp-23-2-KNR-TTT",
+					Week:        2,
+					Stage:       StageRegular,
+					Date:        "2024-01-22",
+					HomeTeamKey: "KNR",
+					HomeTeam:    "Knight Riders",
+					AwayTeamKey: "TTT",
+					AwayTeam:    "The Trailer Trashers",
+					VenueKey:    "GPA",
+					Venue:       "Georgetown Pizza and Arcade",
+				},
+			},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.ListSchedule(ctx, tc.after)
+			if err != nil {
+				t.Fatalf("ListSchedule: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nListSchedule(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	exported := filepath.Join(dir, "mnp.db")
+	if err := s.Export(ctx, exported); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if err := s.Export(ctx, exported); err == nil {
+		t.Errorf("Export(...): want error exporting over an existing file, got nil")
+	}
+
+	outdated := filepath.Join(dir, "outdated.db")
+	if err := s.Export(ctx, outdated); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	o, err := Open(ctx, outdated)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := o.DB().ExecContext(ctx, "PRAGMA user_version = 1"); err != nil {
+		t.Fatalf("set user_version: %v", err)
+	}
+	o.Close() //nolint:errcheck // Test cleanup.
+
+	empty := filepath.Join(dir, "empty.db")
+	e, err := Open(ctx, empty)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := e.Init(ctx); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	e.Close() //nolint:errcheck // Test cleanup.
+
+	text := filepath.Join(dir, "text.db")
+	if err := os.WriteFile(text, []byte("not a database"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   error
+	}{
+		"Exported": {
+			reason: "An exported database should be importable.",
+			path:   exported,
+		},
+		"Outdated": {
+			reason: "A database with an older schema shouldn't be importable.",
+			path:   outdated,
+			want:   ErrIncompatibleExport,
+		},
+		"Empty": {
+			reason: "A database without any matches shouldn't be importable.",
+			path:   empty,
+			want:   ErrIncompatibleExport,
+		},
+		"NotADatabase": {
+			reason: "A file that isn't a database shouldn't be importable.",
+			path:   text,
+			want:   ErrIncompatibleExport,
+		},
+		"Missing": {
+			reason: "A file that doesn't exist shouldn't be importable.",
+			path:   filepath.Join(dir, "missing.db"),
+			want:   os.ErrNotExist,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := CheckExport(ctx, tc.path)
+			if diff := cmp.Diff(tc.want, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheckExport(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInitDropsOutdatedSchema(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	// An archive table from before game_results had a points column.
+	if _, err := s.DB().ExecContext(ctx, "CREATE TABLE game_results (game_id INTEGER, score INTEGER)"); err != nil {
+		t.Fatalf("create old table: %v", err)
+	}
+
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	var points int
+	if err := s.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info('game_results') WHERE name = 'points'").Scan(&points); err != nil {
+		t.Fatalf("query columns: %v", err)
+	}
+	if diff := cmp.Diff(1, points); diff != "" {
+		t.Errorf("Init() should recreate outdated tables: -want points columns, +got:\n%s", diff)
+	}
+
+	var version int
+	if err := s.DB().QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("query version: %v", err)
+	}
+	if diff := cmp.Diff(schemaVersion, version); diff != "" {
+		t.Errorf("Init() should record the schema version: -want, +got:\n%s", diff)
+	}
+}
+
+func TestListPlayedMatches(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Only the week 1 match has games.
+	want := []PlayedMatch{
+		{
+			Key:         "mnp-23-1-TTT-KNR",
+			Week:        1,
+			Date:        "2024-01-15",
+			HomeTeamKey: "TTT",
+			HomeTeam:    "The Trailer Trashers",
+			AwayTeamKey: "KNR",
+			AwayTeam:    "Knight Riders",
+			VenueKey:    "STN",
+			Venue:       "Seattle Tavern and Pool Hall",
+		},
+	}
+
+	got, err := s.ListPlayedMatches(ctx, "KNR")
+	if err != nil {
+		t.Fatalf("ListPlayedMatches: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListPlayedMatches(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListMatchResults(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	want := []MatchResult{
+		{Round: 1, MachineKey: "TAF", PlayerName: "Alice", TeamKey: "TTT", Score: 500, Points: 2.5},
+		{Round: 1, MachineKey: "TAF", PlayerName: "Carol", TeamKey: "KNR", Score: 300, Points: 0.5},
+		{Round: 1, MachineKey: "TAF", PlayerName: "Bob", TeamKey: "TTT", Score: 400, Points: 2},
+		{Round: 1, MachineKey: "TAF", PlayerName: "Dave", TeamKey: "KNR", Score: 200, Points: 0},
+		{Round: 2, MachineKey: "TZ", PlayerName: "Alice", TeamKey: "TTT", Score: 100, Points: 0},
+		{Round: 2, MachineKey: "TZ", PlayerName: "Carol", TeamKey: "KNR", Score: 150, Points: 3},
+		{Round: 3, MachineKey: "TAF", PlayerName: "Bob", TeamKey: "TTT", Score: 350, Points: 3},
+		{Round: 3, MachineKey: "TAF", PlayerName: "Dave", TeamKey: "KNR", Score: 250, Points: 0},
+		{Round: 4, MachineKey: "MM", PlayerName: "Alice", TeamKey: "TTT", Score: 600, Points: 0},
+		{Round: 4, MachineKey: "MM", PlayerName: "Carol", TeamKey: "KNR", Score: 700, Points: 3},
+	}
+
+	got, err := s.ListMatchResults(ctx, "mnp-23-1-TTT-KNR")
+	if err != nil {
+		t.Fatalf("ListMatchResults: %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(MatchResult{}, "GameID")); diff != "" {
+		t.Errorf("ListMatchResults(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetMatchDetail(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.GetMatchDetail(ctx, "mnp-23-1-TTT-KNR")
+	if err != nil {
+		t.Fatalf("GetMatchDetail: %v", err)
+	}
+	want := PlayedMatch{
+		Key:         "mnp-23-1-TTT-KNR",
+		Week:        1,
+		Date:        "2024-01-15",
+		HomeTeamKey: "TTT",
+		HomeTeam:    "The Trailer Trashers",
+		AwayTeamKey: "KNR",
+		AwayTeam:    "Knight Riders",
+		VenueKey:    "STN",
+		Venue:       "Seattle Tavern and Pool Hall",
+	}
+	if diff := cmp.Diff(want, got.PlayedMatch); diff != "" {
+		t.Errorf("GetMatchDetail(...): -want match, +got match:\n%s", diff)
+	}
+	if diff := cmp.Diff(23, got.Season); diff != "" {
+		t.Errorf("GetMatchDetail(...): -want season, +got season:\n%s", diff)
+	}
+	if diff := cmp.Diff(10, len(got.Results)); diff != "" {
+		t.Errorf("GetMatchDetail(...): -want results, +got results:\n%s", diff)
+	}
+
+	missing, err := s.GetMatchDetail(ctx, "mnp-23-9-TTT-KNR")
+	if err != nil {
+		t.Fatalf("GetMatchDetail: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("GetMatchDetail(...): want nil for a missing match, got %+v", missing)
+	}
+}
+
+func TestLoadMatchBatch(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Reload week 1 with different games, and load week 3 with a new player.
+	batch := []MatchGames{
+		{
+			Match: Match{Key: "mnp-23-1-TTT-KNR", SeasonID: f.seasonID, Week: 1, Date: "2024-01-15", HomeTeamID: f.tttID, AwayTeamID: f.knrID, VenueID: f.stnID},
+			Games: []GameResults{{
+				Game: Game{Round: 2, MachineKey: "TZ"},
+				Results: []PlayerResult{
+					{PlayerName: "Carol", TeamID: f.knrID, Position: 1, Score: 150, Points: 3},
+					{PlayerName: "Alice", TeamID: f.tttID, Position: 2, Score: 100},
+				},
+			}},
+			Lineup: []LineupPlayer{{PlayerName: "Alice", TeamID: f.tttID}, {PlayerName: "Carol", TeamID: f.knrID}},
+		},
+		{
+			Match: Match{Key: "mnp-23-3-KNR-TTT", SeasonID: f.seasonID, Week: 3, HomeTeamID: f.tttID, AwayTeamID: f.knrID},
+			Games: []GameResults{{
+				Game: Game{Round: 1, MachineKey: "TAF", IsDoubles: true},
+				Results: []PlayerResult{
+					{PlayerName: "Erin", TeamID: f.knrID, Position: 1, Score: 900, Points: 5},
+					{PlayerName: "Alice", TeamID: f.tttID, Position: 2, Score: 800},
+				},
+			}},
+			// Frank was in the lineup, but didn't play.
+			Lineup: []LineupPlayer{{PlayerName: "Alice", TeamID: f.tttID}, {PlayerName: "Erin", TeamID: f.knrID}, {PlayerName: "Frank", TeamID: f.knrID}},
+		},
+	}
+	// Loading twice shouldn't duplicate anything.
+	for range 2 {
+		if err := s.LoadMatchBatch(ctx, batch); err != nil {
+			t.Fatalf("LoadMatchBatch: %v", err)
+		}
+	}
+
+	want := map[string][]MatchResult{
+		"mnp-23-1-TTT-KNR": {
+			{Round: 2, MachineKey: "TZ", PlayerName: "Carol", TeamKey: "KNR", Score: 150, Points: 3},
+			{Round: 2, MachineKey: "TZ", PlayerName: "Alice", TeamKey: "TTT", Score: 100},
+		},
+		"mnp-23-3-KNR-TTT": {
+			{Round: 1, MachineKey: "TAF", PlayerName: "Erin", TeamKey: "KNR", Score: 900, Points: 5},
+			{Round: 1, MachineKey: "TAF", PlayerName: "Alice", TeamKey: "TTT", Score: 800},
+		},
+	}
+	for key, w := range want {
+		got, err := s.ListMatchResults(ctx, key)
+		if err != nil {
+			t.Fatalf("ListMatchResults: %v", err)
+		}
+		if diff := cmp.Diff(w, got, cmpopts.IgnoreFields(MatchResult{}, "GameID")); diff != "" {
+			t.Errorf("LoadMatchBatch(...): %s: -want, +got:\n%s", key, diff)
+		}
+	}
+
+	// Reloading week 1 should replace its lineup.
+	lineups, err := s.ListTeamLineups(ctx, "KNR")
+	if err != nil {
+		t.Fatalf("ListTeamLineups: %v", err)
+	}
+	wantLineups := []Lineup{
+		{MatchKey: "mnp-23-3-KNR-TTT", Season: 23, Week: 3, Players: []string{"Erin", "Frank"}},
+		{MatchKey: "mnp-23-1-TTT-KNR", Season: 23, Week: 1, Date: "2024-01-15", Players: []string{"Carol"}},
+	}
+	if diff := cmp.Diff(wantLineups, lineups); diff != "" {
+		t.Errorf("LoadMatchBatch(...): lineups: -want, +got:\n%s", diff)
+	}
+}
+
+func TestLoadMatchBatchRollsBack(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// The second match's team doesn't exist, so neither match should load.
+	batch := []MatchGames{
+		{Match: Match{Key: "mnp-23-3-KNR-TTT", SeasonID: f.seasonID, Week: 3, HomeTeamID: f.tttID, AwayTeamID: f.knrID}},
+		{Match: Match{Key: "mnp-23-4-KNR-TTT", SeasonID: f.seasonID, Week: 4, HomeTeamID: f.tttID, AwayTeamID: 9999}},
+	}
+	if err := s.LoadMatchBatch(ctx, batch); err == nil {
+		t.Fatal("LoadMatchBatch: want error for a match with an unknown team, got nil")
+	}
+
+	var n int
+	if err := s.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE week > 2").Scan(&n); err != nil {
+		t.Fatalf("count matches: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("LoadMatchBatch(...): want no matches loaded after an error, got %d", n)
+	}
+}
+
+func TestListMatchJSON(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Only the first match has JSON. Loading the second without JSON
+	// shouldn't remove what was stored for it.
+	load := func(mgs ...MatchGames) {
+		t.Helper()
+		if err := s.LoadMatchBatch(ctx, mgs); err != nil {
+			t.Fatalf("LoadMatchBatch: %v", err)
+		}
+	}
+	m1 := Match{Key: "mnp-23-1-TTT-KNR", SeasonID: f.seasonID, Week: 1, HomeTeamID: f.tttID, AwayTeamID: f.knrID}
+	m2 := Match{Key: "mnp-23-2-KNR-TTT", SeasonID: f.seasonID, Week: 2, HomeTeamID: f.knrID, AwayTeamID: f.tttID}
+	load(MatchGames{Match: m1, JSON: []byte(`{"key":"old"}`)}, MatchGames{Match: m2, JSON: []byte(`{"key":"mnp-23-2-KNR-TTT"}`)})
+	load(MatchGames{Match: m1, JSON: []byte(`{"key":"mnp-23-1-TTT-KNR"}`)}, MatchGames{Match: m2})
+
+	want := []MatchJSON{
+		{Key: "mnp-23-1-TTT-KNR", Season: 23, JSON: []byte(`{"key":"mnp-23-1-TTT-KNR"}`)},
+		{Key: "mnp-23-2-KNR-TTT", Season: 23, JSON: []byte(`{"key":"mnp-23-2-KNR-TTT"}`)},
+	}
+	got, err := s.ListMatchJSON(ctx, 23)
+	if err != nil {
+		t.Fatalf("ListMatchJSON: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListMatchJSON(...): -want, +got:\n%s", diff)
+	}
+
+	got, err = s.ListMatchJSON(ctx, 22)
+	if err != nil {
+		t.Fatalf("ListMatchJSON: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ListMatchJSON(...): want no JSON for another season, got %d matches", len(got))
+	}
+}
+
+func TestPredictionOutcomes(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	predict := func(p Prediction) {
+		t.Helper()
+		if err := s.UpsertPrediction(ctx, p); err != nil {
+			t.Fatalf("UpsertPrediction: %v", err)
+		}
+	}
+
+	// Week 1 has already been played, so this prediction should be ignored.
+	predict(Prediction{MatchKey: "mnp-23-1-TTT-KNR", MachineKey: "TAF", HomeTeamKey: "TTT", AwayTeamKey: "KNR", Edge: 10})
+
+	// Week 2 hasn't been played. The second TAF prediction replaces the first.
+	predict(Prediction{MatchKey: "mnp-23-2-KNR-TTT", MachineKey: "TAF", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: 5})
+	predict(Prediction{MatchKey: "mnp-23-2-KNR-TTT", MachineKey: "TAF", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: -20, Confidence: 1})
+	predict(Prediction{MatchKey: "mnp-23-2-KNR-TTT", MachineKey: "TZ", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: 15})
+
+	// Week 2 is played, but only on TAF.
+	gameID, err := s.InsertGame(ctx, Game{MatchID: f.match2ID, Round: 2, MachineKey: "TAF"})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	players := map[string]int64{}
+	for _, name := range []string{"Alice", "Carol"} {
+		id, err := s.UpsertPlayer(ctx, name)
+		if err != nil {
+			t.Fatalf("UpsertPlayer: %v", err)
+		}
+		players[name] = id
+	}
+	for _, r := range []GameResult{
+		{GameID: gameID, PlayerID: players["Alice"], TeamID: f.tttID, Position: 1, Score: 500, Points: 3},
+		{GameID: gameID, PlayerID: players["Carol"], TeamID: f.knrID, Position: 2, Score: 400, Points: 0},
+	} {
+		if err := s.InsertGameResult(ctx, r); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	// Predictions made after the match was played should be ignored.
+	predict(Prediction{MatchKey: "mnp-23-2-KNR-TTT", MachineKey: "TAF", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: 50})
+
+	want := []PredictionOutcome{
+		{
+			Prediction: Prediction{MatchKey: "mnp-23-2-KNR-TTT", MachineKey: "TAF", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: -20, Confidence: 1},
+			HomePoints: 0,
+			AwayPoints: 3,
+		},
+	}
+
+	got, err := s.ListPredictionOutcomes(ctx)
+	if err != nil {
+		t.Fatalf("ListPredictionOutcomes: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListPredictionOutcomes(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPicks(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	pick := func(p Pick) {
+		t.Helper()
+		if err := s.UpsertPick(ctx, p); err != nil {
+			t.Fatalf("UpsertPick: %v", err)
+		}
+	}
+
+	// Week 2 hasn't been played. Erin's second pick replaces her first.
+	pick(Pick{Picker: "Erin", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "TTT"})
+	pick(Pick{Picker: "Erin", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "KNR"})
+	pick(Pick{Picker: "Frank", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "TTT"})
+
+	// Week 1 has already been played, and CRA isn't playing in week 2.
+	for _, p := range []Pick{
+		{Picker: "Erin", MatchKey: "mnp-23-1-TTT-KNR", TeamKey: "TTT"},
+		{Picker: "Erin", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "CRA"},
+		{Picker: "Erin", MatchKey: "mnp-23-9-KNR-TTT", TeamKey: "TTT"},
+	} {
+		if err := s.UpsertPick(ctx, p); !errors.Is(err, ErrPickRejected) {
+			t.Errorf("UpsertPick(%+v): want ErrPickRejected, got %v", p, err)
+		}
+	}
+
+	got, err := s.ListPicks(ctx, "Erin")
+	if err != nil {
+		t.Fatalf("ListPicks: %v", err)
+	}
+	if diff := cmp.Diff([]Pick{{Picker: "Erin", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "KNR"}}, got); diff != "" {
+		t.Errorf("ListPicks(...): -want, +got:\n%s", diff)
+	}
+
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	play := func(round int, points float64) {
+		t.Helper()
+		gameID, err := s.InsertGame(ctx, Game{MatchID: f.match2ID, Round: round, MachineKey: "TAF"})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: f.tttID, Position: 1, Score: 500, Points: points}); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	// A match isn't scored until its last round has results.
+	play(1, 3)
+	outcomes, err := s.ListPickOutcomes(ctx)
+	if err != nil {
+		t.Fatalf("ListPickOutcomes: %v", err)
+	}
+	if len(outcomes) != 0 {
+		t.Errorf("ListPickOutcomes(...): want no outcomes for an unfinished match, got %+v", outcomes)
+	}
+
+	play(4, 2)
+	want := []PickOutcome{
+		{Pick: Pick{Picker: "Erin", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "KNR"}, Season: 23, HomeTeamKey: "KNR", AwayTeamKey: "TTT", AwayPoints: 5},
+		{Pick: Pick{Picker: "Frank", MatchKey: "mnp-23-2-KNR-TTT", TeamKey: "TTT"}, Season: 23, HomeTeamKey: "KNR", AwayTeamKey: "TTT", AwayPoints: 5},
+	}
+	outcomes, err = s.ListPickOutcomes(ctx)
+	if err != nil {
+		t.Fatalf("ListPickOutcomes: %v", err)
+	}
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("ListPickOutcomes(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestClaimPicker(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		reason string
+		name   string
+		token  string
+		want   error
+	}{
+		{reason: "The first token to claim a name should own it.", name: "Erin", token: "erin", want: nil},
+		{reason: "The owner should be able to claim their name again.", name: "Erin", token: "erin", want: nil},
+		{reason: "Another token shouldn't be able to claim an owned name.", name: "Erin", token: "impostor", want: ErrPickerClaimed},
+		{reason: "A token should be able to claim another unowned name.", name: "Frank", token: "erin", want: nil},
+	}
+	for _, tc := range cases {
+		err := s.ClaimPicker(ctx, tc.name, tc.token)
+		if diff := cmp.Diff(tc.want, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("\n%s\nClaimPicker(%q, %q): -want error, +got error:\n%s", tc.reason, tc.name, tc.token, diff)
+		}
+	}
+}
+
+func TestGetLatestPlayedWeek(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	// Week 2 is scheduled, but only week 1 has games.
+	got, err := s.GetLatestPlayedWeek(context.Background())
+	if err != nil {
+		t.Fatalf("GetLatestPlayedWeek: %v", err)
+	}
+	if diff := cmp.Diff(1, got); diff != "" {
+		t.Errorf("GetLatestPlayedWeek(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListTeamGameCounts(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	want := []TeamGameCount{
+		{Key: "KNR", Name: "Knight Riders", Games: 4},
+		{Key: "TTT", Name: "The Trailer Trashers", Games: 4},
+	}
+
+	got, err := s.ListTeamGameCounts(context.Background())
+	if err != nil {
+		t.Fatalf("ListTeamGameCounts: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListTeamGameCounts(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListVenuesWithoutMachines(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// A venue with no machines that hosts a match this season.
+	empID, err := s.UpsertVenue(ctx, "EMP", "Empty Arcade")
+	if err != nil {
+		t.Fatalf("UpsertVenue: %v", err)
+	}
+	if _, err := s.UpsertMatch(ctx, Match{
+		Key:        "mnp-23-3-TTT-KNR",
+		SeasonID:   f.seasonID,
+		Week:       3,
+		HomeTeamID: f.tttID,
+		AwayTeamID: f.knrID,
+		VenueID:    empID,
+	}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	// A venue that had machines last season but none this season.
+	gonID, err := s.UpsertVenue(ctx, "GON", "Gone Arcade")
+	if err != nil {
+		t.Fatalf("UpsertVenue: %v", err)
+	}
+	s22, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	if err := s.UpsertVenueMachine(ctx, gonID, s22, "MM"); err != nil {
+		t.Fatalf("UpsertVenueMachine: %v", err)
+	}
+	if _, err := s.UpsertMatch(ctx, Match{
+		Key:        "mnp-23-3-KNR-TTT",
+		SeasonID:   f.seasonID,
+		Week:       3,
+		HomeTeamID: f.knrID,
+		AwayTeamID: f.tttID,
+		VenueID:    gonID,
+	}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	// A venue with no machines and no matches shouldn't be reported.
+	if _, err := s.UpsertVenue(ctx, "OLD", "Closed Arcade"); err != nil {
+		t.Fatalf("UpsertVenue: %v", err)
+	}
+
+	want := []Venue{{Key: "EMP", Name: "Empty Arcade"}, {Key: "GON", Name: "Gone Arcade"}}
+
+	got, err := s.ListVenuesWithoutMachines(ctx)
+	if err != nil {
+		t.Fatalf("ListVenuesWithoutMachines: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListVenuesWithoutMachines(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestP50Snapshot(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Bob's TAF scores are [350, 400] and Dave's are [200, 250], so the lower
+	// score is their median.
+	want := []PlayerP50{
+		{PlayerName: "Alice", MachineKey: "MM", Games: 1, P50Score: 600},
+		{PlayerName: "Alice", MachineKey: "TAF", Games: 1, P50Score: 500},
+		{PlayerName: "Alice", MachineKey: "TZ", Games: 1, P50Score: 100},
+		{PlayerName: "Bob", MachineKey: "TAF", Games: 2, P50Score: 350},
+		{PlayerName: "Carol", MachineKey: "MM", Games: 1, P50Score: 700},
+		{PlayerName: "Carol", MachineKey: "TAF", Games: 1, P50Score: 300},
+		{PlayerName: "Carol", MachineKey: "TZ", Games: 1, P50Score: 150},
+		{PlayerName: "Dave", MachineKey: "TAF", Games: 2, P50Score: 200},
+	}
+
+	got, err := s.ListPlayerP50s(ctx)
+	if err != nil {
+		t.Fatalf("ListPlayerP50s: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListPlayerP50s(...): -want, +got:\n%s", diff)
+	}
+
+	_, taken, err := s.GetP50Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("GetP50Snapshot: %v", err)
+	}
+	if !taken.IsZero() {
+		t.Errorf("GetP50Snapshot(...): want zero time before a snapshot is saved, got %v", taken)
+	}
+
+	now := time.Date(2024, 1, 16, 12, 0, 0, 0, time.UTC)
+	if err := s.SaveP50Snapshot(ctx, got[:2], now); err != nil {
+		t.Fatalf("SaveP50Snapshot: %v", err)
+	}
+	if err := s.SaveP50Snapshot(ctx, got, now); err != nil {
+		t.Fatalf("SaveP50Snapshot: %v", err)
+	}
+
+	snap, taken, err := s.GetP50Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("GetP50Snapshot: %v", err)
+	}
+	if diff := cmp.Diff(want, snap); diff != "" {
+		t.Errorf("GetP50Snapshot(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(now, taken); diff != "" {
+		t.Errorf("GetP50Snapshot(...): -want time, +got:\n%s", diff)
+	}
+}
+
+func TestExternalResults(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	replace := func(source string, results ...ExternalResult) {
+		t.Helper()
+		if err := s.ReplaceExternalResults(ctx, source, results); err != nil {
+			t.Fatalf("ReplaceExternalResults: %v", err)
+		}
+	}
+
+	// Re-importing a source replaces its earlier scores.
+	replace("Tuesday", ExternalResult{VenueKey: "STN", MachineKey: "TAF", Score: 1})
+	replace("Tuesday",
+		ExternalResult{VenueKey: "STN", MachineKey: "TAF", Score: 300},
+		ExternalResult{VenueKey: "STN", MachineKey: "TAF", Score: 100},
+		ExternalResult{VenueKey: "STN", MachineKey: "TAF", Score: 200, PlayerName: "Zed", Date: "2024-01-09"},
+		ExternalResult{VenueKey: "STN", MachineKey: "TZ", Score: 900},
+		ExternalResult{VenueKey: "GPA", MachineKey: "TAF", Score: 900},
+	)
+	replace("Wednesday", ExternalResult{VenueKey: "STN", MachineKey: "TAF", Score: 500})
+
+	// Tuesday TAF scores at STN: [100, 200, 300], P50 rn=2 → 200, P90 rn=3 → 300.
+	want := []ExternalStats{
+		{Source: "Tuesday", Games: 3, P50Score: 200, P90Score: 300},
+		{Source: "Wednesday", Games: 1, P50Score: 500, P90Score: 500},
+	}
+
+	got, err := s.GetExternalMachineStats(ctx, "STN", "TAF")
+	if err != nil {
+		t.Fatalf("GetExternalMachineStats: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetExternalMachineStats(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestTeamSeasons(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	seasons, err := s.ListTeamSeasons(ctx, "TTT")
+	if err != nil {
+		t.Fatalf("ListTeamSeasons: %v", err)
+	}
+	if diff := cmp.Diff([]int{23}, seasons); diff != "" {
+		t.Errorf("ListTeamSeasons(...): -want, +got:\n%s", diff)
+	}
+
+	stats, err := s.GetTeamSeasonMachineStats(ctx, "TTT", 23)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonMachineStats: %v", err)
+	}
+	wantStats := []TeamMachineStats{
+		{MachineKey: "TAF", Games: 3, P50Score: 400, P90Score: 500},
+		{MachineKey: "MM", Games: 1, P50Score: 600, P90Score: 600},
+		{MachineKey: "TZ", Games: 1, P50Score: 100, P90Score: 100},
+	}
+	if diff := cmp.Diff(wantStats, stats); diff != "" {
+		t.Errorf("GetTeamSeasonMachineStats(...): -want, +got:\n%s", diff)
+	}
+
+	// KNR's roster is Carol and Dave.
+	rosterStats, err := s.GetTeamSeasonRosterMachineStats(ctx, "KNR", 23)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonRosterMachineStats: %v", err)
+	}
+	wantRosterStats := []TeamMachineStats{
+		{MachineKey: "TAF", Games: 3, P50Score: 250, P90Score: 300},
+		{MachineKey: "MM", Games: 1, P50Score: 700, P90Score: 700},
+		{MachineKey: "TZ", Games: 1, P50Score: 150, P90Score: 150},
+	}
+	if diff := cmp.Diff(wantRosterStats, rosterStats); diff != "" {
+		t.Errorf("GetTeamSeasonRosterMachineStats(...): -want, +got:\n%s", diff)
+	}
+
+	roster, err := s.GetTeamSeasonRoster(ctx, "TTT", 23)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonRoster: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Alice", "Bob"}, roster); diff != "" {
+		t.Errorf("GetTeamSeasonRoster(...): -want, +got:\n%s", diff)
+	}
+
+	points, err := s.GetTeamSeasonPoints(ctx, "TTT", 23)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonPoints: %v", err)
+	}
+	if diff := cmp.Diff(TeamSeasonPoints{Matches: 1, Points: 7.5, OpponentPoints: 6.5}, points); diff != "" {
+		t.Errorf("GetTeamSeasonPoints(...): -want, +got:\n%s", diff)
+	}
+
+	none, err := s.GetTeamSeasonMachineStats(ctx, "TTT", 22)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonMachineStats: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("GetTeamSeasonMachineStats(...) for an unplayed season: want no stats, got %v", none)
+	}
+}
+
+func TestRosterEvents(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	ids := make(map[string]int64)
+	for _, name := range []string{"Alice", "Bob", "Erin"} {
+		id, err := s.UpsertPlayer(ctx, name)
+		if err != nil {
+			t.Fatalf("UpsertPlayer %s: %v", name, err)
+		}
+		ids[name] = id
+	}
+
+	// Erin replaces Bob on TTT mid-season.
+	if err := s.ReplaceRoster(ctx, f.tttID, []int64{ids["Alice"], ids["Erin"]}, "P"); err != nil {
+		t.Fatalf("ReplaceRoster: %v", err)
+	}
+
+	// A team's first roster isn't a change.
+	newID, err := s.UpsertTeam(ctx, Team{Key: "NEW", Name: "New Team", SeasonID: f.seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	if err := s.ReplaceRoster(ctx, newID, []int64{ids["Bob"]}, "P"); err != nil {
+		t.Fatalf("ReplaceRoster: %v", err)
+	}
+
+	roster, err := s.GetTeamSeasonRoster(ctx, "TTT", 23)
+	if err != nil {
+		t.Fatalf("GetTeamSeasonRoster: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Alice", "Erin"}, roster); diff != "" {
+		t.Errorf("GetTeamSeasonRoster(...) after ReplaceRoster: -want, +got:\n%s", diff)
+	}
+
+	want := []RosterEvent{
+		{Season: 23, TeamKey: "TTT", PlayerName: "Bob", Change: RosterLeft},
+		{Season: 23, TeamKey: "TTT", PlayerName: "Erin", Change: RosterJoined},
+	}
+	for _, team := range []string{"TTT", ""} {
+		got, err := s.ListRosterEvents(ctx, team)
+		if err != nil {
+			t.Fatalf("ListRosterEvents(%q): %v", team, err)
+		}
+		if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(RosterEvent{}, "SeenAt")); diff != "" {
+			t.Errorf("ListRosterEvents(%q): -want, +got:\n%s", team, diff)
+		}
+	}
+
+	none, err := s.ListRosterEvents(ctx, "KNR")
+	if err != nil {
+		t.Fatalf("ListRosterEvents: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("ListRosterEvents(...) for an unchanged team: want no events, got %v", none)
+	}
+}
+
+func TestListRosterRetention(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Last season TTT had Alice and Carol, and KNR didn't exist.
+	seasonID, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	teamID, err := s.UpsertTeam(ctx, Team{Key: "TTT", Name: "The Trailer Trashers", SeasonID: seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	for _, name := range []string{"Alice", "Carol"} {
+		id, err := s.UpsertPlayer(ctx, name)
+		if err != nil {
+			t.Fatalf("UpsertPlayer: %v", err)
+		}
+		if err := s.UpsertRoster(ctx, id, teamID, "P"); err != nil {
+			t.Fatalf("UpsertRoster: %v", err)
+		}
+	}
+
+	got, err := s.ListRosterRetention(ctx)
+	if err != nil {
+		t.Fatalf("ListRosterRetention: %v", err)
+	}
+	want := []RosterRetention{
+		{TeamKey: "KNR", TeamName: "Knight Riders", Roster: 2},
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Roster: 2, Previous: 2, Kept: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListRosterRetention(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListTeamMachineScores(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Last season Alice played TAF for another team.
+	seasonID, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	oldID, err := s.UpsertTeam(ctx, Team{Key: "OLD", Name: "Old Team", SeasonID: seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	matchID, err := s.UpsertMatch(ctx, Match{Key: "mnp-22-1-OLD-OLD", SeasonID: seasonID, Week: 1, HomeTeamID: oldID, AwayTeamID: oldID})
+	if err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+	gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: 2, MachineKey: "TAF"})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: oldID, Position: 1, Score: 900}); err != nil {
+		t.Fatalf("InsertGameResult: %v", err)
+	}
+
+	got, err := s.ListTeamMachineScores(ctx, "TTT")
+	if err != nil {
+		t.Fatalf("ListTeamMachineScores: %v", err)
+	}
+	want := []TeamMachineScore{
+		{MachineKey: "MM", Score: 600},
+		{MachineKey: "TAF", Score: 350},
+		{MachineKey: "TAF", Score: 400},
+		{MachineKey: "TAF", Score: 500},
+		{MachineKey: "TAF", SeasonsAgo: 1, Score: 900},
+		{MachineKey: "TZ", Score: 100},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListTeamMachineScores(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetTeamAttendance(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Carol joins TTT but hasn't played for it.
+	carol, err := s.UpsertPlayer(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.UpsertRoster(ctx, carol, f.tttID, "S"); err != nil {
+		t.Fatalf("UpsertRoster: %v", err)
+	}
+
+	// Erin was in TTT's lineup for its match, but didn't play a game.
+	erin, err := s.UpsertPlayer(ctx, "Erin")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.UpsertRoster(ctx, erin, f.tttID, "P"); err != nil {
+		t.Fatalf("UpsertRoster: %v", err)
+	}
+	if err := s.InsertMatchLineup(ctx, MatchLineup{MatchID: f.matchID, PlayerID: erin, TeamID: f.tttID}); err != nil {
+		t.Fatalf("InsertMatchLineup: %v", err)
+	}
+
+	got, err := s.GetTeamAttendance(ctx, "TTT")
+	if err != nil {
+		t.Fatalf("GetTeamAttendance: %v", err)
+	}
+	want := map[string]Attendance{
+		"Alice": {Matches: 1, TeamMatches: 1, Games: 3},
+		"Bob":   {Matches: 1, TeamMatches: 1, Games: 2},
+		"Carol": {TeamMatches: 1},
+		"Erin":  {Matches: 1, TeamMatches: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetTeamAttendance(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetTeamAbsences(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Carol joins TTT but wasn't in the lineup for its match.
+	carol, err := s.UpsertPlayer(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.UpsertRoster(ctx, carol, f.tttID, "S"); err != nil {
+		t.Fatalf("UpsertRoster: %v", err)
+	}
+
+	got, err := s.GetTeamAbsences(ctx, "TTT")
+	if err != nil {
+		t.Fatalf("GetTeamAbsences: %v", err)
+	}
+
+	// Carol was in KNR's lineup for the match, which doesn't count for TTT.
+	// The week 2 match hasn't been played, so no one has missed it.
+	want := map[string][]int{"Carol": {1}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetTeamAbsences(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetDoublesPairs(t *testing.T) {
+	type args struct {
+		teamKey string
+		seasons []int
+	}
+	type want struct {
+		pairs []DoublesPair
+	}
+
+	// Round 1 on TAF is the only doubles game: Alice (2.5) and Bob (2) won
+	// 4.5 of its 5 points for TTT, Carol (0.5) and Dave (0) 0.5 for KNR.
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"TTT": {
+			reason: "A pair's points should be summed, and compared to every point awarded in its games.",
+			args:   args{teamKey: "TTT"},
+			want: want{pairs: []DoublesPair{
+				{Player1: "Alice", Player2: "Bob", Games: 1, PointsWon: 4.5, PointsPossible: 5},
+			}},
+		},
+		"KNR": {
+			reason: "Pairs should only include players who played together for the team.",
+			args:   args{teamKey: "KNR"},
+			want: want{pairs: []DoublesPair{
+				{Player1: "Carol", Player2: "Dave", Games: 1, PointsWon: 0.5, PointsPossible: 5},
+			}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, pairs should only include games played in those seasons.",
+			args:   args{teamKey: "TTT", seasons: []int{22}},
+			want:   want{pairs: nil},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetDoublesPairs(ctx, tc.args.teamKey, tc.args.seasons)
+			if err != nil {
+				t.Fatalf("GetDoublesPairs: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.pairs, got); diff != "" {
+				t.Errorf("\n%s\nGetDoublesPairs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListTeamLineups(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	got, err := s.ListTeamLineups(context.Background(), "KNR")
+	if err != nil {
+		t.Fatalf("ListTeamLineups: %v", err)
+	}
+
+	// The week 2 match hasn't been played, so has no lineup.
+	want := []Lineup{
+		{MatchKey: "mnp-23-1-TTT-KNR", Season: 23, Week: 1, Date: "2024-01-15", Players: []string{"Carol", "Dave"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListTeamLineups(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListTeamRoundMachines(t *testing.T) {
+	type args struct {
+		teamKey string
+		seasons []int
+	}
+	type want struct {
+		machines []RoundMachine
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Home": {
+			reason: "Machines should be listed by round, marked as played at home.",
+			args:   args{teamKey: "TTT"},
+			want: want{machines: []RoundMachine{
+				{Round: 1, Home: true, MachineKey: "TAF", Matches: 1},
+				{Round: 2, Home: true, MachineKey: "TZ", Matches: 1},
+				{Round: 3, Home: true, MachineKey: "TAF", Matches: 1},
+				{Round: 4, Home: true, MachineKey: "MM", Matches: 1},
+			}},
+		},
+		"Away": {
+			reason: "The away team's machines should be marked as played away.",
+			args:   args{teamKey: "KNR"},
+			want: want{machines: []RoundMachine{
+				{Round: 1, MachineKey: "TAF", Matches: 1},
+				{Round: 2, MachineKey: "TZ", Matches: 1},
+				{Round: 3, MachineKey: "TAF", Matches: 1},
+				{Round: 4, MachineKey: "MM", Matches: 1},
+			}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, only matches played in those seasons should count.",
+			args:   args{teamKey: "TTT", seasons: []int{22}},
+			want:   want{machines: nil},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.ListTeamRoundMachines(ctx, tc.args.teamKey, tc.args.seasons)
+			if err != nil {
+				t.Fatalf("ListTeamRoundMachines: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.machines, got); diff != "" {
+				t.Errorf("\n%s\nListTeamRoundMachines(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetPlayerMachinePoints(t *testing.T) {
+	type args struct {
+		player   string
+		venueKey string
+		seasons  []int
+	}
+	type want struct {
+		points map[string]Points
+	}
+
+	// Bob won 2 of 2.5 possible in round 1's TAF doubles, and 3 of 3 in
+	// round 3's TAF singles. Carol won 0.5 of 2.5 on TAF, and 3 of 3 on TZ
+	// and MM.
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Doubles": {
+			reason: "A doubles player should be able to win half the game's points.",
+			args:   args{player: "Bob"},
+			want: want{points: map[string]Points{
+				"TAF": {Games: 2, Won: 5, Possible: 5.5},
+			}},
+		},
+		"Singles": {
+			reason: "A singles player should be able to win all the game's points.",
+			args:   args{player: "Carol"},
+			want: want{points: map[string]Points{
+				"TAF": {Games: 1, Won: 0.5, Possible: 2.5},
+				"TZ":  {Games: 1, Won: 3, Possible: 3},
+				"MM":  {Games: 1, Won: 3, Possible: 3},
+			}},
+		},
+		"VenueFilter": {
+			reason: "With a venue filter, points should only count games played there.",
+			args:   args{player: "Carol", venueKey: "GPA"},
+			want:   want{points: map[string]Points{}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, points should only count games played in those seasons.",
+			args:   args{player: "Carol", seasons: []int{22}},
+			want:   want{points: map[string]Points{}},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetPlayerMachinePoints(ctx, tc.args.player, tc.args.venueKey, tc.args.seasons)
+			if err != nil {
+				t.Fatalf("GetPlayerMachinePoints: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.points, got); diff != "" {
+				t.Errorf("\n%s\nGetPlayerMachinePoints(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetTeamMachinePicks(t *testing.T) {
+	type args struct {
+		teamKey string
+		seasons []int
+	}
+	type want struct {
+		picks map[string]MachinePicks
+	}
+
+	// TTT hosted KNR, so KNR picked rounds 1 and 3 (TAF), and TTT rounds 2
+	// (TZ) and 4 (MM).
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Home": {
+			reason: "The home team should have picked the machines in even rounds.",
+			args:   args{teamKey: "TTT"},
+			want: want{picks: map[string]MachinePicks{
+				"TAF": {Stuck: 2},
+				"TZ":  {Picked: 1},
+				"MM":  {Picked: 1},
+			}},
+		},
+		"Away": {
+			reason: "The away team should have picked the machines in odd rounds.",
+			args:   args{teamKey: "KNR"},
+			want: want{picks: map[string]MachinePicks{
+				"TAF": {Picked: 2},
+				"TZ":  {Stuck: 1},
+				"MM":  {Stuck: 1},
+			}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, only games played in those seasons should count.",
+			args:   args{teamKey: "TTT", seasons: []int{22}},
+			want:   want{picks: map[string]MachinePicks{}},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetTeamMachinePicks(ctx, tc.args.teamKey, tc.args.seasons)
+			if err != nil {
+				t.Fatalf("GetTeamMachinePicks: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.picks, got); diff != "" {
+				t.Errorf("\n%s\nGetTeamMachinePicks(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListTeamRosterScores(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.ListTeamRosterScores(ctx, "TTT")
+	if err != nil {
+		t.Fatalf("ListTeamRosterScores: %v", err)
+	}
+
+	// TAF has six scores: 200, 250, 300, 350, 400, 500. TZ and MM have two.
+	want := []RosterScore{
+		{PlayerName: "Alice", Season: 23, Week: 1, PercentRank: 1},
+		{PlayerName: "Alice", Season: 23, Week: 1, PercentRank: 0},
+		{PlayerName: "Alice", Season: 23, Week: 1, PercentRank: 0},
+		{PlayerName: "Bob", Season: 23, Week: 1, PercentRank: 0.8},
+		{PlayerName: "Bob", Season: 23, Week: 1, PercentRank: 0.6},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("ListTeamRosterScores(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetTeamHomeAwaySplits(t *testing.T) {
+	type args struct {
+		teamKey string
+		seasons []int
+	}
+	type want struct {
+		splits map[string]HomeAwaySplit
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Home": {
+			reason: "TTT hosted the only match played, so all its roster's games should be at home.",
+			args:   args{teamKey: "TTT"},
+			want: want{splits: map[string]HomeAwaySplit{
+				"TAF": {HomeGames: 3, HomeP50: 400},
+				"TZ":  {HomeGames: 1, HomeP50: 100},
+				"MM":  {HomeGames: 1, HomeP50: 600},
+			}},
+		},
+		"Away": {
+			reason: "KNR played the only match away, so all its roster's games should be away.",
+			args:   args{teamKey: "KNR"},
+			want: want{splits: map[string]HomeAwaySplit{
+				"TAF": {AwayGames: 3, AwayP50: 250},
+				"TZ":  {AwayGames: 1, AwayP50: 150},
+				"MM":  {AwayGames: 1, AwayP50: 700},
+			}},
+		},
+		"SeasonFilter": {
+			reason: "With a season filter, only games played in those seasons should count.",
+			args:   args{teamKey: "TTT", seasons: []int{22}},
+			want:   want{splits: map[string]HomeAwaySplit{}},
+		},
+	}
+
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetTeamHomeAwaySplits(ctx, tc.args.teamKey, tc.args.seasons)
+			if err != nil {
+				t.Fatalf("GetTeamHomeAwaySplits: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.splits, got); diff != "" {
+				t.Errorf("\n%s\nGetTeamHomeAwaySplits(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetPlayerHomeAwaySplits(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Carol plays TZ again in week 2, this time hosting TTT.
+	gameID, err := s.InsertGame(ctx, Game{MatchID: f.match2ID, Round: 2, MachineKey: "TZ"})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	carol, err := s.UpsertPlayer(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: carol, TeamID: f.knrID, Position: 1, Score: 900, Points: 3}); err != nil {
+		t.Fatalf("InsertGameResult: %v", err)
+	}
+
+	got, err := s.GetPlayerHomeAwaySplits(ctx, "Carol", nil)
+	if err != nil {
+		t.Fatalf("GetPlayerHomeAwaySplits: %v", err)
+	}
+	want := map[string]HomeAwaySplit{
+		"TAF": {AwayGames: 1, AwayP50: 300},
+		"TZ":  {HomeGames: 1, HomeP50: 900, AwayGames: 1, AwayP50: 150},
+		"MM":  {AwayGames: 1, AwayP50: 700},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetPlayerHomeAwaySplits(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetTeamMachinePoints(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// TTT's roster is Alice and Bob.
+	got, err := s.GetTeamMachinePoints(ctx, "TTT", nil)
+	if err != nil {
+		t.Fatalf("GetTeamMachinePoints: %v", err)
+	}
+	want := map[string]Points{
+		"TAF": {Games: 3, Won: 7.5, Possible: 8},
+		"TZ":  {Games: 1, Won: 0, Possible: 3},
+		"MM":  {Games: 1, Won: 0, Possible: 3},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetTeamMachinePoints(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestListPlayerMachineScores(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	got, err := s.ListPlayerMachineScores(context.Background(), "Bob")
+	if err != nil {
+		t.Fatalf("ListPlayerMachineScores: %v", err)
+	}
+	// TAF scores league-wide are 200, 250, 300, 350, 400, 500.
+	want := []PlayerMachineScore{
+		{MachineKey: "TAF", Score: 400, PercentRank: 0.8, Date: "2024-01-15"},
+		{MachineKey: "TAF", Score: 350, PercentRank: 0.6, Date: "2024-01-15"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("ListPlayerMachineScores(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetPlayerTrend(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	// Last season Alice played TAF twice for another team.
+	seasonID, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	oldID, err := s.UpsertTeam(ctx, Team{Key: "OLD", Name: "Old Team", SeasonID: seasonID})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	matchID, err := s.UpsertMatch(ctx, Match{Key: "mnp-22-1-OLD-OLD", SeasonID: seasonID, Week: 1, HomeTeamID: oldID, AwayTeamID: oldID})
+	if err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	for i, score := range []int64{300, 200} {
+		gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: i + 2, MachineKey: "TAF"})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: oldID, Position: 1, Score: score}); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	got, err := s.GetPlayerTrend(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("GetPlayerTrend: %v", err)
+	}
+	want := []PlayerSeasonStats{
+		{MachineKey: "MM", Season: 23, Games: 1, P50Score: 600},
+		{MachineKey: "TAF", Season: 22, Games: 2, P50Score: 200},
+		{MachineKey: "TAF", Season: 23, Games: 1, P50Score: 500},
+		{MachineKey: "TZ", Season: 23, Games: 1, P50Score: 100},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetPlayerTrend(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetMonthlyP50(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Alice played TAF again in February, and in a match with no date.
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	for _, m := range []Match{
+		{Key: "mnp-23-5-TTT-KNR", SeasonID: f.seasonID, Week: 5, Date: "2024-02-12", HomeTeamID: f.tttID, AwayTeamID: f.knrID},
+		{Key: "mnp-23-6-TTT-KNR", SeasonID: f.seasonID, Week: 6, HomeTeamID: f.tttID, AwayTeamID: f.knrID},
+	} {
+		matchID, err := s.UpsertMatch(ctx, m)
+		if err != nil {
+			t.Fatalf("UpsertMatch: %v", err)
+		}
+		gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: 2, MachineKey: "TAF"})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: f.tttID, Position: 1, Score: 900}); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		player string
+		want   []MonthlyStats
+	}{
+		"Player": {
+			reason: "A player's P50 should only count their own scores.",
+			player: "Alice",
+			want: []MonthlyStats{
+				{MachineKey: "MM", Month: "2024-01", Games: 1, P50Score: 600},
+				{MachineKey: "TAF", Month: "2024-01", Games: 1, P50Score: 500},
+				{MachineKey: "TAF", Month: "2024-02", Games: 1, P50Score: 900},
+				{MachineKey: "TZ", Month: "2024-01", Games: 1, P50Score: 100},
+			},
+		},
+		"League": {
+			reason: "Without a player, every league score should count.",
+			want: []MonthlyStats{
+				{MachineKey: "MM", Month: "2024-01", Games: 2, P50Score: 600},
+				{MachineKey: "TAF", Month: "2024-01", Games: 6, P50Score: 300},
+				{MachineKey: "TAF", Month: "2024-02", Games: 1, P50Score: 900},
+				{MachineKey: "TZ", Month: "2024-01", Games: 2, P50Score: 100},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetMonthlyP50(ctx, tc.player)
+			if err != nil {
+				t.Fatalf("GetMonthlyP50: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetMonthlyP50(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetGameLog(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	entry := func(round int, machine, player, team, opponent string, score int64, points float64) GameLogEntry {
+		return GameLogEntry{
+			Season: 23, Week: 1, Date: "2024-01-15", Round: round, Doubles: round == 1, MachineKey: machine,
+			PlayerName: player, TeamKey: team, OpponentKey: opponent, Score: score, Points: points,
+		}
+	}
+
+	type want struct {
+		log []GameLogEntry
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		filter GameLogFilter
+		want   want
+	}{
+		"Player": {
+			reason: "A player's games should be returned newest first by default.",
+			filter: GameLogFilter{PlayerName: "Bob"},
+			want: want{log: []GameLogEntry{
+				entry(3, "TAF", "Bob", "TTT", "KNR", 350, 3),
+				entry(1, "TAF", "Bob", "TTT", "KNR", 400, 2),
+			}},
+		},
+		"MachineByScore": {
+			reason: "Sorting by score should return the highest scores on a machine, up to the limit.",
+			filter: GameLogFilter{MachineKey: "TAF", Sort: SortScore, Limit: 2},
+			want: want{log: []GameLogEntry{
+				entry(1, "TAF", "Alice", "TTT", "KNR", 500, 2.5),
+				entry(1, "TAF", "Bob", "TTT", "KNR", 400, 2),
+			}},
+		},
+		"TeamByPoints": {
+			reason: "Sorting by points should return a team's most points first, breaking ties by score.",
+			filter: GameLogFilter{TeamKey: "KNR", Sort: SortPoints, Limit: 2},
+			want: want{log: []GameLogEntry{
+				entry(4, "MM", "Carol", "KNR", "TTT", 700, 3),
+				entry(2, "TZ", "Carol", "KNR", "TTT", 150, 3),
+			}},
+		},
+		"Season": {
+			reason: "Games outside the season shouldn't be returned.",
+			filter: GameLogFilter{PlayerName: "Alice", Season: 22},
+		},
+		"UnknownSort": {
+			reason: "An unknown sort should return an error.",
+			filter: GameLogFilter{Sort: "best"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetGameLog(context.Background(), tc.filter)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetGameLog(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.log, got); diff != "" {
+				t.Errorf("\n%s\nGetGameLog(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	score := func(machine string, rank int, player, team string, score int64) TopScore {
+		return TopScore{
+			MachineKey: machine, Rank: rank, PlayerName: player, TeamKey: team, Score: score,
+			MatchKey: "mnp-23-1-TTT-KNR", Season: 23, Week: 1, Date: "2024-01-15", VenueKey: "STN",
+		}
+	}
+
+	type args struct {
+		machine string
+		venue   string
+		season  int
+		top     int
+	}
+	type want struct {
+		scores []TopScore
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EveryMachine": {
+			reason: "Each machine's highest scores should be returned, highest first.",
+			args:   args{top: 2},
+			want: want{scores: []TopScore{
+				score("MM", 1, "Carol", "KNR", 700),
+				score("MM", 2, "Alice", "TTT", 600),
+				score("TAF", 1, "Alice", "TTT", 500),
+				score("TAF", 2, "Bob", "TTT", 400),
+				score("TZ", 1, "Carol", "KNR", 150),
+				score("TZ", 2, "Alice", "TTT", 100),
+			}},
+		},
+		"Machine": {
+			reason: "Only the machine's scores should be returned, including a player's second score.",
+			args:   args{machine: "TAF", top: 3},
+			want: want{scores: []TopScore{
+				score("TAF", 1, "Alice", "TTT", 500),
+				score("TAF", 2, "Bob", "TTT", 400),
+				score("TAF", 3, "Bob", "TTT", 350),
+			}},
+		},
+		"Venue": {
+			reason: "Scores posted at other venues shouldn't be returned.",
+			args:   args{venue: "GPA", top: 3},
+		},
+		"Season": {
+			reason: "Scores posted in other seasons shouldn't be returned.",
+			args:   args{season: 22, top: 3},
+		},
+		"NoTop": {
+			reason: "Asking for fewer than one score per machine should return an error.",
+			args:   args{top: 0},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetLeaderboard(context.Background(), tc.args.machine, tc.args.venue, tc.args.season, tc.args.top)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetLeaderboard(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.scores, got); diff != "" {
+				t.Errorf("\n%s\nGetLeaderboard(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetPlayerGameLog(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// Alice played TAF last season, and again in week 2, in a match with no date.
+	seasonID, err := s.UpsertSeason(ctx, 22)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	for _, m := range []Match{
+		{Key: "mnp-22-1-TTT-KNR", SeasonID: seasonID, Week: 1, Date: "2023-09-04", HomeTeamID: f.tttID, AwayTeamID: f.knrID},
+		{Key: "mnp-23-2-TTT-KNR", SeasonID: f.seasonID, Week: 2, HomeTeamID: f.tttID, AwayTeamID: f.knrID},
+	} {
+		matchID, err := s.UpsertMatch(ctx, m)
+		if err != nil {
+			t.Fatalf("UpsertMatch: %v", err)
+		}
+		gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: 2, MachineKey: "TAF"})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: f.tttID, Position: 1, Score: 900}); err != nil {
+			t.Fatalf("InsertGameResult: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		player string
+		want   []GameLogEntry
+	}{
+		"LatestSeason": {
+			reason: "Only the player's scores from the latest season they played should be returned.",
+			player: "Alice",
+			want: []GameLogEntry{
+				{Season: 23, Week: 1, Date: "2024-01-15", Round: 4, MachineKey: "MM", PlayerName: "Alice", TeamKey: "TTT", OpponentKey: "KNR", Score: 600},
+				{Season: 23, Week: 1, Date: "2024-01-15", Round: 1, Doubles: true, MachineKey: "TAF", PlayerName: "Alice", TeamKey: "TTT", OpponentKey: "KNR", Score: 500, Points: 2.5},
+				{Season: 23, Week: 2, Round: 2, MachineKey: "TAF", PlayerName: "Alice", TeamKey: "TTT", OpponentKey: "KNR", Score: 900},
+				{Season: 23, Week: 1, Date: "2024-01-15", Round: 2, MachineKey: "TZ", PlayerName: "Alice", TeamKey: "TTT", OpponentKey: "KNR", Score: 100},
+			},
+		},
+		"UnknownPlayer": {
+			reason: "A player who doesn't exist has no games.",
+			player: "Nobody",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.GetPlayerGameLog(ctx, tc.player)
+			if err != nil {
+				t.Fatalf("GetPlayerGameLog: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetPlayerGameLog(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPlayerGoals(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	add := func(g Goal) int64 {
+		t.Helper()
+		id, err := s.AddGoal(ctx, g)
+		if err != nil {
+			t.Fatalf("AddGoal: %v", err)
+		}
+		return id
+	}
+
+	score := add(Goal{PlayerName: "Alice", MachineKey: "MM", Kind: GoalScore, Target: 1000, SetOn: "2024-01-01"})
+	pct := add(Goal{PlayerName: "Alice", MachineKey: "TZ", Kind: GoalPercentile, Target: 50, SetOn: "2024-01-08"})
+	add(Goal{PlayerName: "Bob", MachineKey: "TAF", Kind: GoalScore, Target: 500, SetOn: "2024-01-01"})
+
+	if err := s.DeleteGoal(ctx, score); err != nil {
+		t.Fatalf("DeleteGoal: %v", err)
+	}
+	if err := s.DeleteGoal(ctx, score); !errors.Is(err, ErrGoalNotFound) {
+		t.Errorf("DeleteGoal(...) of a deleted goal: want ErrGoalNotFound, got %v", err)
+	}
+
+	got, err := s.ListPlayerGoals(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("ListPlayerGoals: %v", err)
+	}
+	want := []Goal{
+		{ID: pct, PlayerName: "Alice", MachineKey: "TZ", Kind: GoalPercentile, Target: 50, SetOn: "2024-01-08"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListPlayerGoals(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestBadges(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	results, err := s.ListPlayedResults(ctx, "", "")
+	if err != nil {
+		t.Fatalf("ListPlayedResults: %v", err)
+	}
+	// Four games: one doubles and three singles.
+	if diff := cmp.Diff(10, len(results)); diff != "" {
+		t.Errorf("ListPlayedResults(...): -want results, +got:\n%s", diff)
+	}
+	for _, r := range [][2]string{{"2024-01-15", "2024-01-15"}, {"", "2024-01-15"}, {"2024-01-15", ""}} {
+		got, err := s.ListPlayedResults(ctx, r[0], r[1])
+		if err != nil {
+			t.Fatalf("ListPlayedResults: %v", err)
+		}
+		if diff := cmp.Diff(10, len(got)); diff != "" {
+			t.Errorf("ListPlayedResults(%q, %q) should include matches on the range's ends: -want results, +got:\n%s", r[0], r[1], diff)
+		}
+	}
+	none, err := s.ListPlayedResults(ctx, "2024-01-16", "2024-02-01")
+	if err != nil {
+		t.Fatalf("ListPlayedResults: %v", err)
+	}
+	if diff := cmp.Diff(0, len(none)); diff != "" {
+		t.Errorf("ListPlayedResults(...) should exclude matches outside the range: -want results, +got:\n%s", diff)
+	}
+	want := PlayedResult{
+		MatchKey:    "mnp-23-1-TTT-KNR",
+		Date:        "2024-01-15",
+		MatchResult: MatchResult{Round: 1, MachineKey: "TAF", PlayerName: "Alice", TeamKey: "TTT", Score: 500, Points: 2.5},
+	}
+	if diff := cmp.Diff(want, results[0], cmpopts.IgnoreFields(MatchResult{}, "GameID")); diff != "" {
+		t.Errorf("ListPlayedResults(...): -want first result, +got:\n%s", diff)
+	}
+
+	badges := []Badge{
+		{PlayerName: "Alice", Badge: "perfect-night", MatchKey: "mnp-23-1-TTT-KNR", EarnedOn: "2024-01-15"},
+		{PlayerName: "Bob", Badge: "giant-killer", MatchKey: "mnp-23-1-TTT-KNR", EarnedOn: "2024-01-15"},
+	}
+	n, err := s.SaveBadges(ctx, badges)
+	if err != nil {
+		t.Fatalf("SaveBadges: %v", err)
+	}
+	if diff := cmp.Diff(2, n); diff != "" {
+		t.Errorf("SaveBadges(...): -want awarded, +got:\n%s", diff)
+	}
+
+	// Players keep the badge from the first match they earned it in.
+	n, err = s.SaveBadges(ctx, []Badge{{PlayerName: "Alice", Badge: "perfect-night", MatchKey: "mnp-23-2-KNR-TTT", EarnedOn: "2024-01-22"}})
+	if err != nil {
+		t.Fatalf("SaveBadges: %v", err)
+	}
+	if diff := cmp.Diff(0, n); diff != "" {
+		t.Errorf("SaveBadges(...) of a badge the player has: -want awarded, +got:\n%s", diff)
+	}
+
+	got, err := s.ListPlayerBadges(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("ListPlayerBadges: %v", err)
+	}
+	if diff := cmp.Diff(badges[:1], got); diff != "" {
+		t.Errorf("ListPlayerBadges(...): -want, +got:\n%s", diff)
+	}
+
+	got, err = s.ListMatchBadges(ctx, "mnp-23-1-TTT-KNR")
+	if err != nil {
+		t.Fatalf("ListMatchBadges: %v", err)
+	}
+	if diff := cmp.Diff(badges, got); diff != "" {
+		t.Errorf("ListMatchBadges(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetSignatureWin(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	if err := s.UpsertPlayerIPR(ctx, "Alice", 5); err != nil {
+		t.Fatalf("UpsertPlayerIPR: %v", err)
+	}
+
+	// Carol beat Alice in singles twice. The later game wins the tie.
+	got, err := s.GetSignatureWin(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("GetSignatureWin: %v", err)
+	}
+	want := &SignatureWin{MatchKey: "mnp-23-1-TTT-KNR", Date: "2024-01-15", MachineKey: "MM", Opponent: "Alice", OpponentIPR: 5, Score: 700}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetSignatureWin(...): -want, +got:\n%s", diff)
+	}
+
+	// Alice lost both her singles games.
+	got, err = s.GetSignatureWin(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("GetSignatureWin: %v", err)
+	}
+	if got != nil {
+		t.Errorf("GetSignatureWin(...) for a player without a singles win: want nil, got %+v", got)
+	}
+}
+
+func TestGetStandings(t *testing.T) {
+	s, _ := newTestStore(t)
+	ctx := context.Background()
+
+	got, err := s.GetStandings(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	// The week 2 match hasn't been played, so only week 1 counts.
+	want := []Standing{
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Played: 1, Wins: 1, Points: 7.5, OpponentPoints: 6.5},
+		{TeamKey: "KNR", TeamName: "Knight Riders", Played: 1, Losses: 1, Points: 6.5, OpponentPoints: 7.5},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetStandings(...): -want, +got:\n%s", diff)
+	}
+
+	explicit, err := s.GetStandings(ctx, 23)
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	if diff := cmp.Diff(want, explicit); diff != "" {
+		t.Errorf("GetStandings(...) for season 23: -want, +got:\n%s", diff)
+	}
+
+	none, err := s.GetStandings(ctx, 22)
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("GetStandings(...) for a missing season: want no standings, got %v", none)
+	}
+}
+
+func TestGetStandingsThroughWeek(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// KNR win the week 2 match.
+	carol, err := s.UpsertPlayer(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	alice, err := s.UpsertPlayer(ctx, "Alice")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	gameID, err := s.InsertGame(ctx, Game{MatchID: f.match2ID, Round: 2, MachineKey: "MM"})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: carol, TeamID: f.knrID, Position: 1, Score: 100, Points: 3}); err != nil {
+		t.Fatalf("InsertGameResult: %v", err)
+	}
+	if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: alice, TeamID: f.tttID, Position: 2, Score: 50}); err != nil {
+		t.Fatalf("InsertGameResult: %v", err)
+	}
+
+	week1, err := s.GetStandingsThroughWeek(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("GetStandingsThroughWeek: %v", err)
+	}
+	want := []Standing{
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Played: 1, Wins: 1, Points: 7.5, OpponentPoints: 6.5},
+		{TeamKey: "KNR", TeamName: "Knight Riders", Played: 1, Losses: 1, Points: 6.5, OpponentPoints: 7.5},
+	}
+	if diff := cmp.Diff(want, week1); diff != "" {
+		t.Errorf("GetStandingsThroughWeek(...) through week 1: -want, +got:\n%s", diff)
+	}
+
+	all, err := s.GetStandingsThroughWeek(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("GetStandingsThroughWeek: %v", err)
+	}
+	want = []Standing{
+		{TeamKey: "KNR", TeamName: "Knight Riders", Played: 2, Wins: 1, Losses: 1, Points: 9.5, OpponentPoints: 7.5},
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Played: 2, Wins: 1, Losses: 1, Points: 7.5, OpponentPoints: 9.5},
+	}
+	if diff := cmp.Diff(want, all); diff != "" {
+		t.Errorf("GetStandingsThroughWeek(...) through every week: -want, +got:\n%s", diff)
+	}
+}
+
+func TestPlayoffs(t *testing.T) {
+	s, f := newTestStore(t)
+	ctx := context.Background()
+
+	// The schedule numbers the playoff week. The match file doesn't.
+	stub := Match{Key: "mnp-23-SF-KNR-TTT", SeasonID: f.seasonID, Week: 3, WeekLabel: "SF", Stage: StagePlayoffs, Date: "2024-01-29", HomeTeamID: f.knrID, AwayTeamID: f.tttID}
+	if _, err := s.UpsertMatch(ctx, stub); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+	played := stub
+	played.Week = 0
+	matchID, err := s.UpsertMatch(ctx, played)
+	if err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	// KNR win the playoff match, which shouldn't change the standings.
+	carol, err := s.UpsertPlayer(ctx, "Carol")
+	if err != nil {
+		t.Fatalf("UpsertPlayer: %v", err)
+	}
+	gameID, err := s.InsertGame(ctx, Game{MatchID: matchID, Round: 2, MachineKey: "MM"})
+	if err != nil {
+		t.Fatalf("InsertGame: %v", err)
+	}
+	if err := s.InsertGameResult(ctx, GameResult{GameID: gameID, PlayerID: carol, TeamID: f.knrID, Position: 1, Score: 100, Points: 3}); err != nil {
+		t.Fatalf("InsertGameResult: %v", err)
+	}
+
+	sched, err := s.ListSchedule(ctx, "2024-01-29")
+	if err != nil {
+		t.Fatalf("ListSchedule: %v", err)
+	}
+	want := []ScheduleMatch{{
+		Key:         "mnp-23-SF-KNR-TTT",
+		Week:        3,
+		WeekLabel:   "SF",
+		Stage:       StagePlayoffs,
+		Date:        "2024-01-29",
+		HomeTeamKey: "KNR",
+		HomeTeam:    "Knight Riders",
+		AwayTeamKey: "TTT",
+		AwayTeam:    "The Trailer Trashers",
+	}}
+	if diff := cmp.Diff(want, sched); diff != "" {
+		t.Errorf("ListSchedule(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("Playoffs SF", sched[0].WeekName()); diff != "" {
+		t.Errorf("WeekName(): -want, +got:\n%s", diff)
+	}
+
+	standings, err := s.GetStandings(ctx, 0)
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	wantStandings := []Standing{
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Played: 1, Wins: 1, Points: 7.5, OpponentPoints: 6.5},
+		{TeamKey: "KNR", TeamName: "Knight Riders", Played: 1, Losses: 1, Points: 6.5, OpponentPoints: 7.5},
+	}
+	if diff := cmp.Diff(wantStandings, standings); diff != "" {
+		t.Errorf("GetStandings(...): -want, +got:\n%s", diff)
+	}
+}
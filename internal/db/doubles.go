@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// DoublesPair is how two teammates have done playing doubles games together.
+type DoublesPair struct {
+	Player1        string // Sorts before Player2.
+	Player2        string
+	Games          int
+	PointsWon      float64 // Points the pair won between them.
+	PointsPossible float64 // Points awarded in the pair's games, to either team.
+}
+
+// GetDoublesPairs returns every pair of players who have played doubles games
+// together for a team, in any season it played under the supplied key. If
+// seasons is non-empty, filters to games played in those seasons. Results are
+// ordered by games played descending.
+func (s *SQLiteStore) GetDoublesPairs(ctx context.Context, teamKey string, seasons []int) ([]DoublesPair, error) {
+	query := `
+		SELECT
+			p1.name,
+			p2.name,
+			COUNT(*) as games,
+			SUM(gr1.points + gr2.points),
+			SUM((SELECT SUM(a.points) FROM game_results a WHERE a.game_id = g.id))
+		FROM game_results gr1
+		JOIN game_results gr2 ON gr2.game_id = gr1.game_id AND gr2.team_id = gr1.team_id
+		JOIN players p1 ON p1.id = gr1.player_id
+		JOIN players p2 ON p2.id = gr2.player_id
+		JOIN teams t ON t.id = gr1.team_id
+		JOIN games g ON g.id = gr1.game_id
+		JOIN matches m ON m.id = g.match_id
+		WHERE t.key = ?
+		  AND g.is_doubles = 1
+		  AND p1.name < p2.name
+	`
+	args := []any{teamKey}
+
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
+	query += `
+		GROUP BY p1.id, p2.id
+		ORDER BY games DESC, p1.name, p2.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query doubles pairs: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var pairs []DoublesPair
+	for rows.Next() {
+		var p DoublesPair
+		if err := rows.Scan(&p.Player1, &p.Player2, &p.Games, &p.PointsWon, &p.PointsPossible); err != nil {
+			return nil, fmt.Errorf("scan doubles pair: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate doubles pairs: %w", err)
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// ErrIncompatibleExport is returned when a file isn't a database exported by
+// this version of mnp.
+var ErrIncompatibleExport = errors.New("not a database exported by this version of mnp")
+
+// Export writes a compacted copy of the database to a new file at path. The
+// copy is consistent even if the database is being written to.
+func (s *SQLiteStore) Export(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("export database: %w", err)
+	}
+	return nil
+}
+
+// CheckExport returns ErrIncompatibleExport if the file at path isn't a
+// database exported with this version of the schema, with at least one season
+// of matches loaded. It opens the file read-only.
+func CheckExport(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite", (&url.URL{Scheme: "file", OmitHost: true, Path: path, RawQuery: "mode=ro"}).String())
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer db.Close() //nolint:errcheck // Read-only database.
+
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("%w: %w", ErrIncompatibleExport, err)
+	}
+	if version != schemaVersion {
+		return fmt.Errorf("%w: schema version %d, want %d", ErrIncompatibleExport, version, schemaVersion)
+	}
+
+	var matches int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches").Scan(&matches); err != nil {
+		return fmt.Errorf("%w: %w", ErrIncompatibleExport, err)
+	}
+	if matches == 0 {
+		return fmt.Errorf("%w: no matches loaded", ErrIncompatibleExport)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExternalResult is a score from another league, played at an MNP venue.
+type ExternalResult struct {
+	VenueKey   string
+	MachineKey string
+	PlayerName string // Empty if the source doesn't name players.
+	Score      int64
+	Date       string // Empty if unknown.
+}
+
+// ReplaceExternalResults replaces all external results from the supplied
+// source, so re-importing a source's scores doesn't duplicate them.
+func (s *SQLiteStore) ReplaceExternalResults(ctx context.Context, source string, results []ExternalResult) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM external_results WHERE source = ?", source); err != nil {
+		return fmt.Errorf("delete external results from %s: %w", source, err)
+	}
+
+	for _, r := range results {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO external_results (source, venue_key, machine_key, player_name, score, date)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, source, r.VenueKey, r.MachineKey, r.PlayerName, r.Score, r.Date); err != nil {
+			return fmt.Errorf("insert external result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit external results: %w", err)
+	}
+	return nil
+}
+
+// ExternalStats contains aggregated stats from one external source for a
+// machine at a venue.
+type ExternalStats struct {
+	Source   string
+	Games    int
+	P50Score float64 // Median (50th percentile)
+	P90Score float64 // 90th percentile
+}
+
+// GetExternalMachineStats returns stats from each external source for a
+// machine at a venue, ordered by play count descending.
+func (s *SQLiteStore) GetExternalMachineStats(ctx context.Context, venueKey, machineKey string) ([]ExternalStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH scores AS (
+			SELECT
+				source,
+				score,
+				ROW_NUMBER() OVER (PARTITION BY source ORDER BY score) as rn,
+				COUNT(*) OVER (PARTITION BY source) as total
+			FROM external_results
+			WHERE venue_key = ?
+			  AND machine_key = ?
+		),
+		source_agg AS (
+			SELECT DISTINCT source, total
+			FROM scores
+		)
+		SELECT
+			sa.source,
+			sa.total as games,
+			(SELECT score FROM scores s WHERE s.source = sa.source
+			 AND s.rn = (sa.total + 1) / 2) as p50,
+			(SELECT score FROM scores s WHERE s.source = sa.source
+			 AND s.rn = (sa.total * 9 + 9) / 10) as p90
+		FROM source_agg sa
+		ORDER BY games DESC, sa.source
+	`, venueKey, machineKey)
+	if err != nil {
+		return nil, fmt.Errorf("query external machine stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []ExternalStats
+	for rows.Next() {
+		var es ExternalStats
+		if err := rows.Scan(&es.Source, &es.Games, &es.P50Score, &es.P90Score); err != nil {
+			return nil, fmt.Errorf("scan external machine stats: %w", err)
+		}
+		stats = append(stats, es)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate external machine stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// GameLogEntry is one player's result in one game.
+type GameLogEntry struct {
+	Season      int
+	Week        int
+	Date        string // ISO date of the match. Empty if unknown.
+	Round       int
+	Doubles     bool
+	MachineKey  string
+	PlayerName  string
+	TeamKey     string
+	OpponentKey string
+	Score       int64
+	Points      float64
+}
+
+// GameLogSort orders a game log.
+type GameLogSort string
+
+// Game log orders.
+const (
+	SortNewest GameLogSort = "newest"
+	SortOldest GameLogSort = "oldest"
+	SortScore  GameLogSort = "score"  // Highest first.
+	SortPoints GameLogSort = "points" // Most first.
+)
+
+// GameLogFilter selects the games GetGameLog returns. Empty fields don't
+// filter.
+type GameLogFilter struct {
+	PlayerName string // Case doesn't matter.
+	TeamKey    string
+	MachineKey string
+	Season     int
+	Sort       GameLogSort // Defaults to SortNewest.
+	Limit      int         // Zero for every game.
+}
+
+// gameLogQuery selects game log entries. Callers append conditions and an
+// ORDER BY.
+const gameLogQuery = `
+	SELECT
+		s.number,
+		m.week,
+		COALESCE(m.date, ''),
+		g.round,
+		g.is_doubles,
+		g.machine_key,
+		p.name,
+		t.key,
+		CASE WHEN gr.team_id = m.home_team_id THEN at.key ELSE ht.key END,
+		gr.score,
+		gr.points
+	FROM game_results gr
+	JOIN games g ON g.id = gr.game_id
+	JOIN matches m ON m.id = g.match_id
+	JOIN seasons s ON s.id = m.season_id
+	JOIN players p ON p.id = gr.player_id
+	JOIN teams t ON t.id = gr.team_id
+	JOIN teams ht ON ht.id = m.home_team_id
+	JOIN teams at ON at.id = m.away_team_id
+	WHERE g.machine_key IS NOT NULL
+	  AND gr.score IS NOT NULL`
+
+// GetGameLog returns individual game results matching the filter.
+func (s *SQLiteStore) GetGameLog(ctx context.Context, f GameLogFilter) ([]GameLogEntry, error) {
+	q := gameLogQuery
+	var args []any
+	if f.PlayerName != "" {
+		q += " AND p.name = ? COLLATE NOCASE"
+		args = append(args, f.PlayerName)
+	}
+	if f.TeamKey != "" {
+		q += " AND t.key = ?"
+		args = append(args, f.TeamKey)
+	}
+	if f.MachineKey != "" {
+		q += " AND g.machine_key = ?"
+		args = append(args, f.MachineKey)
+	}
+	if f.Season != 0 {
+		q += " AND s.number = ?"
+		args = append(args, f.Season)
+	}
+
+	switch f.Sort {
+	case SortNewest, "":
+		q += " ORDER BY s.number DESC, m.week DESC, g.round DESC, g.id DESC, gr.position"
+	case SortOldest:
+		q += " ORDER BY s.number, m.week, g.round, g.id, gr.position"
+	case SortScore:
+		q += " ORDER BY gr.score DESC, s.number DESC, m.week DESC"
+	case SortPoints:
+		q += " ORDER BY gr.points DESC, gr.score DESC, s.number DESC, m.week DESC"
+	default:
+		return nil, fmt.Errorf("unknown game log sort %q", f.Sort)
+	}
+
+	if f.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	return s.gameLog(ctx, q, args...)
+}
+
+// GetPlayerGameLog returns every score a player posted in the latest season
+// they played, ordered by machine and then oldest first.
+func (s *SQLiteStore) GetPlayerGameLog(ctx context.Context, playerName string) ([]GameLogEntry, error) {
+	return s.gameLog(ctx, gameLogQuery+`
+		  AND p.name = ?
+		  AND s.number = (
+			SELECT MAX(s2.number)
+			FROM game_results gr2
+			JOIN games g2 ON g2.id = gr2.game_id
+			JOIN matches m2 ON m2.id = g2.match_id
+			JOIN seasons s2 ON s2.id = m2.season_id
+			WHERE gr2.player_id = p.id
+		  )
+		ORDER BY g.machine_key, m.week, g.round, g.id`, playerName)
+}
+
+func (s *SQLiteStore) gameLog(ctx context.Context, query string, args ...any) ([]GameLogEntry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query game log: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var log []GameLogEntry
+	for rows.Next() {
+		var e GameLogEntry
+		if err := rows.Scan(&e.Season, &e.Week, &e.Date, &e.Round, &e.Doubles, &e.MachineKey,
+			&e.PlayerName, &e.TeamKey, &e.OpponentKey, &e.Score, &e.Points); err != nil {
+			return nil, fmt.Errorf("scan game log: %w", err)
+		}
+		log = append(log, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate game log: %w", err)
+	}
+
+	return log, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrGoalNotFound is returned when deleting a goal that doesn't exist.
+var ErrGoalNotFound = errors.New("goal not found")
+
+// GoalKind is what a player's goal measures.
+type GoalKind string
+
+// Goal kinds.
+const (
+	// GoalScore is met by posting a score of at least the target.
+	GoalScore GoalKind = "score"
+	// GoalPercentile is met when the player's median percentile rank over
+	// their recent games reaches the target.
+	GoalPercentile GoalKind = "percentile"
+)
+
+// Goal is a target a player set themselves on a machine.
+type Goal struct {
+	ID         int64
+	PlayerName string
+	MachineKey string
+	Kind       GoalKind
+	Target     float64 // A score, or a percentile rank from 0 to 100.
+	SetOn      string  // ISO date. Only results from this date on count.
+}
+
+// AddGoal records a goal and returns its ID.
+func (s *SQLiteStore) AddGoal(ctx context.Context, g Goal) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO player_goals (player_name, machine_key, kind, target, set_on)
+		VALUES (?, ?, ?, ?, ?)
+	`, g.PlayerName, g.MachineKey, g.Kind, g.Target, g.SetOn)
+	if err != nil {
+		return 0, fmt.Errorf("insert goal: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("get goal id: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteGoal deletes a goal. It returns ErrGoalNotFound if the goal doesn't
+// exist.
+func (s *SQLiteStore) DeleteGoal(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM player_goals WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete goal: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("count deleted goals: %w", err)
+	}
+	if n == 0 {
+		return ErrGoalNotFound
+	}
+	return nil
+}
+
+// ListPlayerGoals returns a player's goals, oldest first.
+func (s *SQLiteStore) ListPlayerGoals(ctx context.Context, playerName string) ([]Goal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, player_name, machine_key, kind, target, set_on
+		FROM player_goals
+		WHERE player_name = ?
+		ORDER BY id
+	`, playerName)
+	if err != nil {
+		return nil, fmt.Errorf("query player goals: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var goals []Goal
+	for rows.Next() {
+		var g Goal
+		if err := rows.Scan(&g.ID, &g.PlayerName, &g.MachineKey, &g.Kind, &g.Target, &g.SetOn); err != nil {
+			return nil, fmt.Errorf("scan player goal: %w", err)
+		}
+		goals = append(goals, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player goals: %w", err)
+	}
+
+	return goals, nil
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetLatestPlayedWeek returns the latest week of the current (latest) season
+// with at least one completed game, or 0 if no games have been played.
+func (s *SQLiteStore) GetLatestPlayedWeek(ctx context.Context) (int, error) {
+	var week sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT MAX(m.week)
+		FROM matches m
+		WHERE m.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		  AND EXISTS (SELECT 1 FROM games g WHERE g.match_id = m.id)
+	`).Scan(&week)
+	if err != nil {
+		return 0, fmt.Errorf("query latest played week: %w", err)
+	}
+	return int(week.Int64), nil
+}
+
+// TeamGameCount is the number of games a team has played this season.
+type TeamGameCount struct {
+	Key   string
+	Name  string
+	Games int
+}
+
+// ListTeamGameCounts returns the number of games each team in the current
+// (latest) season has played, ordered by team key.
+func (s *SQLiteStore) ListTeamGameCounts(ctx context.Context) ([]TeamGameCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			t.key,
+			t.name,
+			COUNT(DISTINCT gr.game_id)
+		FROM teams t
+		LEFT JOIN game_results gr ON gr.team_id = t.id
+		WHERE t.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		GROUP BY t.id
+		ORDER BY t.key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query team game counts: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []TeamGameCount
+	for rows.Next() {
+		var tc TeamGameCount
+		if err := rows.Scan(&tc.Key, &tc.Name, &tc.Games); err != nil {
+			return nil, fmt.Errorf("scan team game count: %w", err)
+		}
+		result = append(result, tc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team game counts: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListVenuesWithoutMachines returns venues hosting matches in the current
+// (latest) season that have no machines that season, ordered by key.
+func (s *SQLiteStore) ListVenuesWithoutMachines(ctx context.Context) ([]Venue, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH latest AS (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		SELECT v.key, v.name
+		FROM venues v
+		WHERE v.id IN (
+			SELECT m.venue_id FROM matches m
+			WHERE m.season_id = (SELECT id FROM latest)
+		)
+		  AND NOT EXISTS (
+			SELECT 1 FROM venue_machines vm
+			WHERE vm.venue_id = v.id AND vm.season_id = (SELECT id FROM latest)
+		  )
+		ORDER BY v.key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query venues without machines: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []Venue
+	for rows.Next() {
+		var v Venue
+		if err := rows.Scan(&v.Key, &v.Name); err != nil {
+			return nil, fmt.Errorf("scan venue: %w", err)
+		}
+		result = append(result, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate venues without machines: %w", err)
+	}
+
+	return result, nil
+}
+
+// PlayerP50 is a player's median score on a machine across all seasons.
+type PlayerP50 struct {
+	PlayerName string
+	MachineKey string
+	Games      int
+	P50Score   float64
+}
+
+// ListPlayerP50s returns every player's median score on every machine they've
+// played, across all seasons, ordered by player then machine.
+func (s *SQLiteStore) ListPlayerP50s(ctx context.Context) ([]PlayerP50, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH scores AS (
+			SELECT
+				p.name,
+				g.machine_key,
+				gr.score,
+				ROW_NUMBER() OVER (PARTITION BY gr.player_id, g.machine_key ORDER BY gr.score) as rn,
+				COUNT(*) OVER (PARTITION BY gr.player_id, g.machine_key) as total
+			FROM game_results gr
+			JOIN players p ON p.id = gr.player_id
+			JOIN games g ON g.id = gr.game_id
+			WHERE g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+		)
+		SELECT name, machine_key, total, score
+		FROM scores
+		WHERE rn = (total + 1) / 2
+		ORDER BY name, machine_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query player P50s: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PlayerP50
+	for rows.Next() {
+		var p PlayerP50
+		if err := rows.Scan(&p.PlayerName, &p.MachineKey, &p.Games, &p.P50Score); err != nil {
+			return nil, fmt.Errorf("scan player P50: %w", err)
+		}
+		result = append(result, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player P50s: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetP50Snapshot returns the player P50s saved by the last call to
+// SaveP50Snapshot, and when they were saved. It returns a zero time if no
+// snapshot has been saved.
+func (s *SQLiteStore) GetP50Snapshot(ctx context.Context) ([]PlayerP50, time.Time, error) {
+	var takenAt sql.NullString
+	if err := s.db.QueryRowContext(ctx, "SELECT MIN(taken_at) FROM p50_snapshots").Scan(&takenAt); err != nil {
+		return nil, time.Time{}, fmt.Errorf("query snapshot time: %w", err)
+	}
+	if !takenAt.Valid {
+		return nil, time.Time{}, nil
+	}
+	taken, err := time.Parse(time.RFC3339, takenAt.String)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("parse snapshot time: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT player_name, machine_key, games, p50
+		FROM p50_snapshots
+		ORDER BY player_name, machine_key
+	`)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("query snapshot: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PlayerP50
+	for rows.Next() {
+		var p PlayerP50
+		if err := rows.Scan(&p.PlayerName, &p.MachineKey, &p.Games, &p.P50Score); err != nil {
+			return nil, time.Time{}, fmt.Errorf("scan snapshot: %w", err)
+		}
+		result = append(result, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("iterate snapshot: %w", err)
+	}
+
+	return result, taken, nil
+}
+
+// SaveP50Snapshot replaces the saved player P50 snapshot.
+func (s *SQLiteStore) SaveP50Snapshot(ctx context.Context, p50s []PlayerP50, takenAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM p50_snapshots"); err != nil {
+		return fmt.Errorf("delete old snapshot: %w", err)
+	}
+
+	ts := takenAt.UTC().Format(time.RFC3339)
+	for _, p := range p50s {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO p50_snapshots (player_name, machine_key, games, p50, taken_at) VALUES (?, ?, ?, ?, ?)",
+			p.PlayerName, p.MachineKey, p.Games, p.P50Score, ts); err != nil {
+			return fmt.Errorf("insert snapshot %s %s: %w", p.PlayerName, p.MachineKey, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit snapshot: %w", err)
+	}
+	return nil
+}
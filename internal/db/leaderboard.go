@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// TopScore is one of the highest scores ever posted on a machine.
+type TopScore struct {
+	MachineKey string
+	Rank       int // 1 for the machine's highest score.
+	PlayerName string
+	TeamKey    string
+	Score      int64
+	MatchKey   string
+	Season     int
+	Week       int
+	Date       string // ISO date of the match. Empty if unknown.
+	VenueKey   string // Empty if unknown.
+}
+
+// GetLeaderboard returns the top highest scores posted on each machine, ordered
+// by machine and then rank. Ties go to whoever posted the score first. An empty
+// machine or venue key, or a zero season, doesn't filter.
+func (s *SQLiteStore) GetLeaderboard(ctx context.Context, machineKey, venueKey string, season, top int) ([]TopScore, error) {
+	if top < 1 {
+		return nil, fmt.Errorf("top must be at least 1, not %d", top)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT
+				g.machine_key,
+				ROW_NUMBER() OVER (
+					PARTITION BY g.machine_key
+					ORDER BY gr.score DESC, s.number, m.week, g.round, g.id
+				) as rank,
+				p.name as player_name,
+				t.key as team_key,
+				gr.score,
+				m.key as match_key,
+				s.number as season,
+				m.week,
+				COALESCE(m.date, '') as date,
+				COALESCE(v.key, '') as venue_key
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			JOIN seasons s ON s.id = m.season_id
+			JOIN players p ON p.id = gr.player_id
+			JOIN teams t ON t.id = gr.team_id
+			LEFT JOIN venues v ON v.id = m.venue_id
+			WHERE g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+			  AND (? = '' OR g.machine_key = ?)
+			  AND (? = '' OR v.key = ?)
+			  AND (? = 0 OR s.number = ?)
+		)
+		SELECT machine_key, rank, player_name, team_key, score, match_key, season, week, date, venue_key
+		FROM ranked
+		WHERE rank <= ?
+		ORDER BY machine_key, rank
+	`, machineKey, machineKey, venueKey, venueKey, season, season, top)
+	if err != nil {
+		return nil, fmt.Errorf("query leaderboard: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var scores []TopScore
+	for rows.Next() {
+		var ts TopScore
+		if err := rows.Scan(&ts.MachineKey, &ts.Rank, &ts.PlayerName, &ts.TeamKey, &ts.Score,
+			&ts.MatchKey, &ts.Season, &ts.Week, &ts.Date, &ts.VenueKey); err != nil {
+			return nil, fmt.Errorf("scan leaderboard: %w", err)
+		}
+		scores = append(scores, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate leaderboard: %w", err)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Lineup is the players in a team's lineup for one of its matches.
+type Lineup struct {
+	MatchKey string
+	Season   int
+	Week     int
+	Date     string
+	Players  []string // Sorted by name.
+}
+
+// ListTeamLineups returns a team's lineup for each of its played matches, in
+// any season it played under the supplied key, most recent first. Lineups
+// include players who were at the match but didn't play a game.
+func (s *SQLiteStore) ListTeamLineups(ctx context.Context, teamKey string) ([]Lineup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.key, s.number, m.week, COALESCE(m.date, ''), p.name
+		FROM match_lineups ml
+		JOIN players p ON p.id = ml.player_id
+		JOIN teams t ON t.id = ml.team_id
+		JOIN matches m ON m.id = ml.match_id
+		JOIN seasons s ON s.id = m.season_id
+		WHERE t.key = ?
+		  AND EXISTS (SELECT 1 FROM games g WHERE g.match_id = m.id)
+		ORDER BY s.number DESC, m.week DESC, m.date DESC, m.key, p.name
+	`, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team lineups: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var lineups []Lineup
+	for rows.Next() {
+		var l Lineup
+		var player string
+		if err := rows.Scan(&l.MatchKey, &l.Season, &l.Week, &l.Date, &player); err != nil {
+			return nil, fmt.Errorf("scan team lineup: %w", err)
+		}
+		if n := len(lineups); n > 0 && lineups[n-1].MatchKey == l.MatchKey {
+			lineups[n-1].Players = append(lineups[n-1].Players, player)
+			continue
+		}
+		l.Players = []string{player}
+		lineups = append(lineups, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team lineups: %w", err)
+	}
+
+	return lineups, nil
+}
+
+// RoundMachine is how many of a team's matches a machine was played in during
+// one round, split by whether the team was at home.
+type RoundMachine struct {
+	Round      int
+	Home       bool
+	MachineKey string
+	Matches    int
+}
+
+// ListTeamRoundMachines returns the machines played in each round of a team's
+// matches, in any season it played under the supplied key. If seasons is
+// non-empty, filters to matches played in those seasons. Results are ordered
+// by round, then by matches descending.
+func (s *SQLiteStore) ListTeamRoundMachines(ctx context.Context, teamKey string, seasons []int) ([]RoundMachine, error) {
+	query := `
+		SELECT g.round, m.home_team_id = t.id, g.machine_key, COUNT(DISTINCT m.id) as matches
+		FROM games g
+		JOIN matches m ON m.id = g.match_id
+		JOIN teams t ON t.id IN (m.home_team_id, m.away_team_id)
+		WHERE t.key = ?
+		  AND g.machine_key IS NOT NULL
+	`
+	args := []any{teamKey}
+
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
+	query += `
+		GROUP BY g.round, m.home_team_id = t.id, g.machine_key
+		ORDER BY g.round, matches DESC, g.machine_key
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team round machines: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []RoundMachine
+	for rows.Next() {
+		var rm RoundMachine
+		if err := rows.Scan(&rm.Round, &rm.Home, &rm.MachineKey, &rm.Matches); err != nil {
+			return nil, fmt.Errorf("scan team round machine: %w", err)
+		}
+		result = append(result, rm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team round machines: %w", err)
+	}
+
+	return result, nil
+}
+
+// MachinePicks is how often a team played a machine it picked, and how often
+// it played one its opponent picked.
+type MachinePicks struct {
+	Picked int // Games on the machine in rounds the team picked.
+	Stuck  int // Games on the machine in rounds its opponent picked.
+}
+
+// GetTeamMachinePicks returns how often a team picked each machine it played,
+// in any season it played under the supplied key, keyed by machine. The away
+// team picks the machines in odd rounds, and the home team in even rounds. If
+// seasons is non-empty, filters to games played in those seasons.
+func (s *SQLiteStore) GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]MachinePicks, error) {
+	query := `
+		SELECT
+			g.machine_key,
+			SUM((g.round % 2 = 0) = (m.home_team_id = t.id)),
+			SUM((g.round % 2 = 0) != (m.home_team_id = t.id))
+		FROM games g
+		JOIN matches m ON m.id = g.match_id
+		JOIN teams t ON t.id IN (m.home_team_id, m.away_team_id)
+		WHERE t.key = ?
+		  AND g.machine_key IS NOT NULL
+	`
+	args := []any{teamKey}
+
+	cond, condArgs := inSeasons(seasons)
+	query += cond + " GROUP BY g.machine_key"
+	args = append(args, condArgs...)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team machine picks: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]MachinePicks)
+	for rows.Next() {
+		var key string
+		var p MachinePicks
+		if err := rows.Scan(&key, &p.Picked, &p.Stuck); err != nil {
+			return nil, fmt.Errorf("scan team machine picks: %w", err)
+		}
+		result[key] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team machine picks: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+)
+
+// upsertMatchJSONQuery stores a match's compressed JSON, looking up the
+// season number from its ID.
+const upsertMatchJSONQuery = `
+	INSERT INTO match_json (match_key, season, json, stored_at)
+	SELECT ?, number, ?, ? FROM seasons WHERE id = ?
+	ON CONFLICT(match_key) DO UPDATE SET
+		season = excluded.season,
+		json = excluded.json,
+		stored_at = excluded.stored_at
+`
+
+// MatchJSON is a match's raw archive JSON, as of the last sync.
+type MatchJSON struct {
+	Key    string
+	Season int
+	JSON   []byte // Uncompressed.
+}
+
+// ListMatchJSON returns the raw JSON stored for each of a season's matches,
+// ordered by match key.
+func (s *SQLiteStore) ListMatchJSON(ctx context.Context, season int) ([]MatchJSON, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT match_key, season, json
+		FROM match_json
+		WHERE season = ?
+		ORDER BY match_key
+	`, season)
+	if err != nil {
+		return nil, fmt.Errorf("query match JSON: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var matches []MatchJSON
+	for rows.Next() {
+		var m MatchJSON
+		var z []byte
+		if err := rows.Scan(&m.Key, &m.Season, &z); err != nil {
+			return nil, fmt.Errorf("scan match JSON: %w", err)
+		}
+		if m.JSON, err = decompress(z); err != nil {
+			return nil, fmt.Errorf("decompress match %s JSON: %w", m.Key, err)
+		}
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate match JSON: %w", err)
+	}
+
+	return matches, nil
+}
+
+// compress returns data gzip compressed.
+func compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// decompress returns the data gzip compressed by compress.
+func decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close() //nolint:errcheck // Read-only.
+	return io.ReadAll(zr)
+}
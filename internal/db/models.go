@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"slices"
 )
 
 // Machine represents a pinball machine.
@@ -25,6 +26,77 @@ func (s *SQLiteStore) UpsertMachine(ctx context.Context, m Machine) error {
 	return nil
 }
 
+// MachineDetails describes who made a machine, and when, as found in an
+// external machine database.
+type MachineDetails struct {
+	Key          string // MNP's machine key.
+	Manufacturer string // e.g., 'Stern'.
+	Year         int    // Zero if unknown.
+	Source       string // Where the details came from, e.g. 'opdb'.
+	SourceID     string // The source's ID for the machine.
+}
+
+// UpsertMachineDetails inserts or updates a machine's manufacturer and year.
+func (s *SQLiteStore) UpsertMachineDetails(ctx context.Context, d MachineDetails) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO machine_details (machine_key, manufacturer, year, source, source_id)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(machine_key) DO UPDATE SET
+			manufacturer = excluded.manufacturer,
+			year = excluded.year,
+			source = excluded.source,
+			source_id = excluded.source_id
+	`, d.Key, d.Manufacturer, d.Year, d.Source, d.SourceID); err != nil {
+		return fmt.Errorf("upsert machine details %s: %w", d.Key, err)
+	}
+	return nil
+}
+
+// MachineAlias maps a machine to an external machine database's machine, for
+// machines whose names don't match.
+type MachineAlias struct {
+	Key      string // MNP's machine key.
+	Source   string // The external database, e.g. 'opdb'.
+	SourceID string // The source's ID for the machine.
+}
+
+// UpsertMachineAlias inserts or updates a machine's alias in a source.
+func (s *SQLiteStore) UpsertMachineAlias(ctx context.Context, a MachineAlias) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO machine_key_aliases (source, machine_key, source_id)
+		VALUES (?, ?, ?)
+		ON CONFLICT(source, machine_key) DO UPDATE SET source_id = excluded.source_id
+	`, a.Source, a.Key, a.SourceID); err != nil {
+		return fmt.Errorf("upsert machine alias %s: %w", a.Key, err)
+	}
+	return nil
+}
+
+// ListMachineAliases returns the IDs machines are aliased to in a source,
+// keyed by machine key.
+func (s *SQLiteStore) ListMachineAliases(ctx context.Context, source string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT machine_key, source_id FROM machine_key_aliases WHERE source = ?", source)
+	if err != nil {
+		return nil, fmt.Errorf("query machine aliases: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var key, id string
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, fmt.Errorf("scan machine alias: %w", err)
+		}
+		result[key] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate machine aliases: %w", err)
+	}
+
+	return result, nil
+}
+
 // Season represents a league season.
 type Season struct {
 	ID     int64
@@ -53,17 +125,78 @@ type Player struct {
 	Name string
 }
 
-// UpsertPlayer inserts or updates a player and returns their ID.
-func (s *SQLiteStore) UpsertPlayer(ctx context.Context, name string) (int64, error) {
-	if _, err := s.db.ExecContext(ctx, `
+// Queries shared by the single row methods below and LoadMatchBatch.
+const (
+	upsertPlayerQuery = `
 		INSERT INTO players (name) VALUES (?)
 		ON CONFLICT(name) DO NOTHING
-	`, name); err != nil {
+	`
+	selectPlayerIDQuery = "SELECT id FROM players WHERE name = ?"
+	upsertMatchQuery    = `
+		INSERT INTO matches (key, season_id, week, week_label, stage, date, home_team_id, away_team_id, venue_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			-- Playoff match files don't number their week. Keep the number
+			-- the schedule gave them.
+			week = CASE WHEN excluded.week = 0 THEN matches.week ELSE excluded.week END,
+			week_label = excluded.week_label,
+			stage = excluded.stage,
+			date = excluded.date,
+			home_team_id = excluded.home_team_id,
+			away_team_id = excluded.away_team_id,
+			venue_id = excluded.venue_id
+	`
+	selectMatchIDQuery = "SELECT id FROM matches WHERE key = ?"
+	insertGameQuery    = `
+		INSERT INTO games (match_id, round, machine_key, is_doubles)
+		VALUES (?, ?, ?, ?)
+	`
+	insertGameResultQuery = `
+		INSERT INTO game_results (game_id, player_id, team_id, position, score, points)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(game_id, player_id) DO UPDATE SET
+			team_id = excluded.team_id,
+			position = excluded.position,
+			score = excluded.score,
+			points = excluded.points
+	`
+	deleteMatchResultsQuery = `
+		DELETE FROM game_results WHERE game_id IN (SELECT id FROM games WHERE match_id = ?)
+	`
+	deleteMatchGamesQuery  = "DELETE FROM games WHERE match_id = ?"
+	insertMatchLineupQuery = `
+		INSERT INTO match_lineups (match_id, player_id, team_id)
+		VALUES (?, ?, ?)
+		ON CONFLICT(match_id, player_id) DO UPDATE SET team_id = excluded.team_id
+	`
+	deleteMatchLineupsQuery = "DELETE FROM match_lineups WHERE match_id = ?"
+	upsertVenueMachineQuery = `
+		INSERT INTO venue_machines (venue_id, season_id, machine_key)
+		VALUES (?, ?, ?)
+		ON CONFLICT(venue_id, season_id, machine_key) DO NOTHING
+	`
+	// Games may be played on machines the store doesn't know yet, which
+	// can't be associated with a venue. The latest season's venue machines
+	// are those the archive lists, so machines played there earlier in the
+	// season but since removed aren't associated. It takes the season ID
+	// again as its last argument.
+	upsertPlayedVenueMachineQuery = `
+		INSERT INTO venue_machines (venue_id, season_id, machine_key)
+		SELECT ?, ?, key FROM machines
+		WHERE key = ? AND ? != (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		ON CONFLICT(venue_id, season_id, machine_key) DO NOTHING
+	`
+	deleteVenueMachinesQuery = "DELETE FROM venue_machines WHERE venue_id = ? AND season_id = ?"
+)
+
+// UpsertPlayer inserts or updates a player and returns their ID.
+func (s *SQLiteStore) UpsertPlayer(ctx context.Context, name string) (int64, error) {
+	if _, err := s.db.ExecContext(ctx, upsertPlayerQuery, name); err != nil {
 		return 0, fmt.Errorf("upsert player %s: %w", name, err)
 	}
 
 	var id int64
-	if err := s.db.QueryRowContext(ctx, "SELECT id FROM players WHERE name = ?", name).Scan(&id); err != nil {
+	if err := s.db.QueryRowContext(ctx, selectPlayerIDQuery, name).Scan(&id); err != nil {
 		return 0, fmt.Errorf("get player id: %w", err)
 	}
 	return id, nil
@@ -109,7 +242,7 @@ func (s *SQLiteStore) UpsertTeam(ctx context.Context, t Team) (int64, error) {
 		ON CONFLICT(key, season_id) DO UPDATE SET
 			name = excluded.name,
 			home_venue_id = excluded.home_venue_id
-	`, t.Key, t.Name, t.SeasonID, t.HomeVenueID); err != nil {
+	`, t.Key, t.Name, t.SeasonID, nullID(t.HomeVenueID)); err != nil {
 		return 0, fmt.Errorf("upsert team %s: %w", t.Key, err)
 	}
 
@@ -122,26 +255,75 @@ func (s *SQLiteStore) UpsertTeam(ctx context.Context, t Team) (int64, error) {
 	return id, nil
 }
 
-// UpsertVenueMachine associates a machine with a venue.
-func (s *SQLiteStore) UpsertVenueMachine(ctx context.Context, venueID int64, machineKey string) error {
-	if _, err := s.db.ExecContext(ctx, `
-		INSERT INTO venue_machines (venue_id, machine_key)
-		VALUES (?, ?)
-		ON CONFLICT(venue_id, machine_key) DO NOTHING
-	`, venueID, machineKey); err != nil {
+// UpsertVenueMachine associates a machine with a venue in a season.
+func (s *SQLiteStore) UpsertVenueMachine(ctx context.Context, venueID, seasonID int64, machineKey string) error {
+	if _, err := s.db.ExecContext(ctx, upsertVenueMachineQuery, venueID, seasonID, machineKey); err != nil {
 		return fmt.Errorf("upsert venue machine %s: %w", machineKey, err)
 	}
 	return nil
 }
 
-// GetVenueMachines returns the machine keys at a venue.
-func (s *SQLiteStore) GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT vm.machine_key
+// ReplaceVenueMachines replaces the machines at a venue in a season with the
+// supplied machines.
+func (s *SQLiteStore) ReplaceVenueMachines(ctx context.Context, venueID, seasonID int64, machineKeys []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	if _, err := tx.ExecContext(ctx, deleteVenueMachinesQuery, venueID, seasonID); err != nil {
+		return fmt.Errorf("delete venue machines: %w", err)
+	}
+	for _, key := range machineKeys {
+		if _, err := tx.ExecContext(ctx, upsertVenueMachineQuery, venueID, seasonID, key); err != nil {
+			return fmt.Errorf("upsert venue machine %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit venue machines: %w", err)
+	}
+	return nil
+}
+
+// venueMachinesQuery selects the keys of the machines at a venue in a season.
+// It takes the venue's key, then a season number. Season 0 is the latest
+// season the venue has machines in.
+const venueMachinesQuery = `
+	SELECT vm.machine_key
+	FROM venue_machines vm
+	JOIN venues v ON v.id = vm.venue_id
+	JOIN seasons s ON s.id = vm.season_id
+	WHERE v.key = ?
+	  AND s.number = COALESCE(NULLIF(?, 0), (
+		SELECT MAX(s2.number)
+		FROM venue_machines vm2
+		JOIN seasons s2 ON s2.id = vm2.season_id
+		WHERE vm2.venue_id = v.id
+	  ))
+`
+
+// currentVenueMachinesCTE is a CTE of the machines at each venue in the latest
+// season it has machines in.
+const currentVenueMachinesCTE = `
+	current_venue_machines AS (
+		SELECT vm.venue_id, vm.machine_key
 		FROM venue_machines vm
-		JOIN venues v ON v.id = vm.venue_id
-		WHERE v.key = ?
-	`, venueKey)
+		JOIN seasons s ON s.id = vm.season_id
+		WHERE s.number = (
+			SELECT MAX(s2.number)
+			FROM venue_machines vm2
+			JOIN seasons s2 ON s2.id = vm2.season_id
+			WHERE vm2.venue_id = vm.venue_id
+		)
+	)
+`
+
+// GetVenueMachines returns the machine keys at a venue in a season. Season 0
+// returns the machines at the venue now, or in the latest season it had any.
+func (s *SQLiteStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, venueMachinesQuery, venueKey, season)
 	if err != nil {
 		return nil, fmt.Errorf("query venue machines: %w", err)
 	}
@@ -163,6 +345,78 @@ func (s *SQLiteStore) GetVenueMachines(ctx context.Context, venueKey string) (ma
 	return result, nil
 }
 
+// LatestSeason returns the latest of the supplied seasons, or 0 if there are
+// none. Analysis that only counts some seasons should look at the machines at a
+// venue in the latest of them.
+func LatestSeason(seasons []int) int {
+	if len(seasons) == 0 {
+		return 0
+	}
+	return slices.Max(seasons)
+}
+
+// VenueSeason is the machines at a venue in a season, and how they changed
+// since the previous season the venue had machines in.
+type VenueSeason struct {
+	Season   int
+	Machines []string // Machine keys, sorted.
+	Added    []string // Machines that weren't at the venue the previous season.
+	Removed  []string // Machines that were at the venue the previous season, but left.
+}
+
+// GetVenueMachineHistory returns the machines at a venue in each season it had
+// any, oldest first. Every machine is added in the venue's first season.
+func (s *SQLiteStore) GetVenueMachineHistory(ctx context.Context, venueKey string) ([]VenueSeason, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.number, vm.machine_key
+		FROM venue_machines vm
+		JOIN venues v ON v.id = vm.venue_id
+		JOIN seasons s ON s.id = vm.season_id
+		WHERE v.key = ?
+		ORDER BY s.number, vm.machine_key
+	`, venueKey)
+	if err != nil {
+		return nil, fmt.Errorf("query venue machine history: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []VenueSeason
+	for rows.Next() {
+		var season int
+		var key string
+		if err := rows.Scan(&season, &key); err != nil {
+			return nil, fmt.Errorf("scan venue machine history: %w", err)
+		}
+		if len(result) == 0 || result[len(result)-1].Season != season {
+			result = append(result, VenueSeason{Season: season})
+		}
+		vs := &result[len(result)-1]
+		vs.Machines = append(vs.Machines, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate venue machine history: %w", err)
+	}
+
+	var previous []string
+	for i := range result {
+		vs := &result[i]
+		for _, key := range vs.Machines {
+			if !slices.Contains(previous, key) {
+				vs.Added = append(vs.Added, key)
+			}
+		}
+		for _, key := range previous {
+			if !slices.Contains(vs.Machines, key) {
+				vs.Removed = append(vs.Removed, key)
+			}
+		}
+		previous = vs.Machines
+	}
+
+	return result, nil
+}
+
 // UpsertRoster adds a player to a team roster.
 func (s *SQLiteStore) UpsertRoster(ctx context.Context, playerID, teamID int64, role string) error {
 	if _, err := s.db.ExecContext(ctx, `
@@ -181,29 +435,38 @@ type Match struct {
 	Key        string
 	SeasonID   int64
 	Week       int
+	WeekLabel  string
+	Stage      Stage // Defaults to StageRegular.
 	Date       string
 	HomeTeamID int64
 	AwayTeamID int64
 	VenueID    int64
 }
 
+// A Stage is a part of a season.
+type Stage string
+
+// Stages.
+const (
+	StageRegular  Stage = "regular"
+	StagePlayoffs Stage = "playoffs"
+)
+
+func (s Stage) orRegular() Stage {
+	if s == "" {
+		return StageRegular
+	}
+	return s
+}
+
 // UpsertMatch inserts or updates a match and returns its ID.
 func (s *SQLiteStore) UpsertMatch(ctx context.Context, m Match) (int64, error) {
-	if _, err := s.db.ExecContext(ctx, `
-		INSERT INTO matches (key, season_id, week, date, home_team_id, away_team_id, venue_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(key) DO UPDATE SET
-			week = excluded.week,
-			date = excluded.date,
-			home_team_id = excluded.home_team_id,
-			away_team_id = excluded.away_team_id,
-			venue_id = excluded.venue_id
-	`, m.Key, m.SeasonID, m.Week, m.Date, m.HomeTeamID, m.AwayTeamID, m.VenueID); err != nil {
+	if _, err := s.db.ExecContext(ctx, upsertMatchQuery, m.Key, m.SeasonID, m.Week, m.WeekLabel, m.Stage.orRegular(), m.Date, m.HomeTeamID, m.AwayTeamID, nullID(m.VenueID)); err != nil {
 		return 0, fmt.Errorf("upsert match %s: %w", m.Key, err)
 	}
 
 	var id int64
-	if err := s.db.QueryRowContext(ctx, "SELECT id FROM matches WHERE key = ?", m.Key).Scan(&id); err != nil {
+	if err := s.db.QueryRowContext(ctx, selectMatchIDQuery, m.Key).Scan(&id); err != nil {
 		return 0, fmt.Errorf("get match id: %w", err)
 	}
 	return id, nil
@@ -220,15 +483,7 @@ type Game struct {
 
 // InsertGame inserts a game and returns its ID.
 func (s *SQLiteStore) InsertGame(ctx context.Context, g Game) (int64, error) {
-	isDoubles := 0
-	if g.IsDoubles {
-		isDoubles = 1
-	}
-
-	result, err := s.db.ExecContext(ctx, `
-		INSERT INTO games (match_id, round, machine_key, is_doubles)
-		VALUES (?, ?, ?, ?)
-	`, g.MatchID, g.Round, g.MachineKey, isDoubles)
+	result, err := s.db.ExecContext(ctx, insertGameQuery, g.MatchID, g.Round, g.MachineKey, boolInt(g.IsDoubles))
 	if err != nil {
 		return 0, fmt.Errorf("insert game: %w", err)
 	}
@@ -242,23 +497,32 @@ type GameResult struct {
 	TeamID   int64
 	Position int
 	Score    int64
+	Points   float64
 }
 
 // InsertGameResult inserts a game result.
 func (s *SQLiteStore) InsertGameResult(ctx context.Context, r GameResult) error {
-	if _, err := s.db.ExecContext(ctx, `
-		INSERT INTO game_results (game_id, player_id, team_id, position, score)
-		VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(game_id, player_id) DO UPDATE SET
-			team_id = excluded.team_id,
-			position = excluded.position,
-			score = excluded.score
-	`, r.GameID, r.PlayerID, r.TeamID, r.Position, r.Score); err != nil {
+	if _, err := s.db.ExecContext(ctx, insertGameResultQuery, r.GameID, r.PlayerID, r.TeamID, r.Position, r.Score, r.Points); err != nil {
 		return fmt.Errorf("insert game result: %w", err)
 	}
 	return nil
 }
 
+// MatchLineup represents a player in a team's lineup for a match.
+type MatchLineup struct {
+	MatchID  int64
+	PlayerID int64
+	TeamID   int64
+}
+
+// InsertMatchLineup adds a player to a team's lineup for a match.
+func (s *SQLiteStore) InsertMatchLineup(ctx context.Context, l MatchLineup) error {
+	if _, err := s.db.ExecContext(ctx, insertMatchLineupQuery, l.MatchID, l.PlayerID, l.TeamID); err != nil {
+		return fmt.Errorf("insert match lineup: %w", err)
+	}
+	return nil
+}
+
 // UpsertPlayerIPR inserts or updates a player's IPR by name.
 func (s *SQLiteStore) UpsertPlayerIPR(ctx context.Context, name string, ipr int) error {
 	if _, err := s.db.ExecContext(ctx, `
@@ -270,15 +534,31 @@ func (s *SQLiteStore) UpsertPlayerIPR(ctx context.Context, name string, ipr int)
 	return nil
 }
 
+// boolInt returns b as SQLite stores booleans.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// nullID returns nil for a zero ID, so an unknown optional reference (e.g. a
+// team with no home venue) is stored as NULL rather than violating its
+// foreign key.
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 // DeleteMatchGames deletes all games and results for a match (for re-import).
 func (s *SQLiteStore) DeleteMatchGames(ctx context.Context, matchID int64) error {
-	if _, err := s.db.ExecContext(ctx, `
-		DELETE FROM game_results WHERE game_id IN (SELECT id FROM games WHERE match_id = ?)
-	`, matchID); err != nil {
+	if _, err := s.db.ExecContext(ctx, deleteMatchResultsQuery, matchID); err != nil {
 		return fmt.Errorf("delete game results: %w", err)
 	}
 
-	if _, err := s.db.ExecContext(ctx, "DELETE FROM games WHERE match_id = ?", matchID); err != nil {
+	if _, err := s.db.ExecContext(ctx, deleteMatchGamesQuery, matchID); err != nil {
 		return fmt.Errorf("delete games: %w", err)
 	}
 	return nil
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPickRejected is returned when a pick is for a match that doesn't exist,
+// has already been played, or that the picked team isn't playing in.
+var ErrPickRejected = errors.New("pick rejected")
+
+// ErrPickerClaimed is returned when a picker's name belongs to someone else.
+var ErrPickerClaimed = errors.New("picker name belongs to someone else")
+
+// Pick is a league member's pick of which team will win a match.
+type Pick struct {
+	Picker   string // Whoever made the pick. Not necessarily a player.
+	MatchKey string
+	TeamKey  string // The team picked to win.
+}
+
+// UpsertPick records a pick, replacing the picker's earlier pick for the same
+// match. It returns ErrPickRejected if the match doesn't exist, already has
+// results, or the picked team isn't playing in it.
+func (s *SQLiteStore) UpsertPick(ctx context.Context, p Pick) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO picks (picker, match_key, team_key, picked_at)
+		SELECT ?, m.key, t.key, ?
+		FROM matches m
+		JOIN teams t ON t.id IN (m.home_team_id, m.away_team_id)
+		WHERE m.key = ?
+		  AND t.key = ?
+		  AND NOT EXISTS (SELECT 1 FROM games g WHERE g.match_id = m.id)
+		ON CONFLICT (picker, match_key) DO UPDATE SET
+			team_key = excluded.team_key,
+			picked_at = excluded.picked_at
+	`, p.Picker, time.Now().UTC().Format(time.RFC3339), p.MatchKey, p.TeamKey)
+	if err != nil {
+		return fmt.Errorf("upsert pick %s %s: %w", p.Picker, p.MatchKey, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("count upserted picks: %w", err)
+	}
+	if n == 0 {
+		return ErrPickRejected
+	}
+	return nil
+}
+
+// ClaimPicker claims a picker's name for whoever holds the supplied token,
+// unless someone else already has. It returns ErrPickerClaimed if the name
+// belongs to another token. Only a hash of the token is stored.
+func (s *SQLiteStore) ClaimPicker(ctx context.Context, name, token string) error {
+	sum := sha256.Sum256([]byte(token))
+	hash := hex.EncodeToString(sum[:])
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO pickers (name, token_hash, claimed_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO NOTHING
+	`, name, hash, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("claim picker %s: %w", name, err)
+	}
+
+	var owner string
+	if err := s.db.QueryRowContext(ctx, "SELECT token_hash FROM pickers WHERE name = ?", name).Scan(&owner); err != nil {
+		return fmt.Errorf("get picker %s: %w", name, err)
+	}
+	if owner != hash {
+		return ErrPickerClaimed
+	}
+	return nil
+}
+
+// ListPicks returns every pick a picker has made, ordered by match.
+func (s *SQLiteStore) ListPicks(ctx context.Context, picker string) ([]Pick, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT picker, match_key, team_key
+		FROM picks
+		WHERE picker = ?
+		ORDER BY match_key
+	`, picker)
+	if err != nil {
+		return nil, fmt.Errorf("query picks: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []Pick
+	for rows.Next() {
+		var p Pick
+		if err := rows.Scan(&p.Picker, &p.MatchKey, &p.TeamKey); err != nil {
+			return nil, fmt.Errorf("scan pick: %w", err)
+		}
+		result = append(result, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate picks: %w", err)
+	}
+
+	return result, nil
+}
+
+// PickOutcome is a pick alongside the points each team actually won in the
+// match.
+type PickOutcome struct {
+	Pick
+
+	Season      int
+	HomeTeamKey string
+	AwayTeamKey string
+	HomePoints  float64
+	AwayPoints  float64
+}
+
+// ListPickOutcomes returns every pick for a match that has since been played,
+// ordered by match then picker. Matches are only scored once their last round
+// has results, so a match that's still loading isn't scored early.
+func (s *SQLiteStore) ListPickOutcomes(ctx context.Context) ([]PickOutcome, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			p.picker,
+			p.match_key,
+			p.team_key,
+			s.number,
+			ht.key,
+			at.key,
+			SUM(CASE WHEN gr.team_id = m.home_team_id THEN gr.points ELSE 0 END),
+			SUM(CASE WHEN gr.team_id = m.away_team_id THEN gr.points ELSE 0 END)
+		FROM picks p
+		JOIN matches m ON m.key = p.match_key
+		JOIN seasons s ON s.id = m.season_id
+		JOIN teams ht ON ht.id = m.home_team_id
+		JOIN teams at ON at.id = m.away_team_id
+		JOIN games g ON g.match_id = m.id
+		JOIN game_results gr ON gr.game_id = g.id
+		GROUP BY p.picker, p.match_key
+		HAVING MAX(g.round) >= 4
+		ORDER BY p.match_key, p.picker
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query pick outcomes: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PickOutcome
+	for rows.Next() {
+		var o PickOutcome
+		if err := rows.Scan(
+			&o.Picker,
+			&o.MatchKey,
+			&o.TeamKey,
+			&o.Season,
+			&o.HomeTeamKey,
+			&o.AwayTeamKey,
+			&o.HomePoints,
+			&o.AwayPoints,
+		); err != nil {
+			return nil, fmt.Errorf("scan pick outcome: %w", err)
+		}
+		result = append(result, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pick outcomes: %w", err)
+	}
+
+	return result, nil
+}
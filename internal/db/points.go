@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Points is the match points a player or team won over a number of games, out
+// of the points they could have won.
+type Points struct {
+	Games    int
+	Won      float64
+	Possible float64
+}
+
+// Add returns the sum of p and o.
+func (p Points) Add(o Points) Points {
+	return Points{Games: p.Games + o.Games, Won: p.Won + o.Won, Possible: p.Possible + o.Possible}
+}
+
+// Efficiency returns the fraction of the points possible that were won, or
+// zero if none were possible.
+func (p Points) Efficiency() float64 {
+	if p.Possible == 0 {
+		return 0
+	}
+	return p.Won / p.Possible
+}
+
+// resultPoints is the points each player won in each game, and the points they
+// could have won: the game's points split evenly between the players on their
+// team. A singles player can win all 3 of a game's points, and each doubles
+// player 2.5 of 5. Games nobody won points in, such as unfinished games, are
+// excluded.
+const resultPoints = `
+	result_points AS (
+		SELECT
+			gr.game_id,
+			gr.player_id,
+			gr.points,
+			(SELECT SUM(a.points) FROM game_results a WHERE a.game_id = gr.game_id) * 1.0 /
+			(SELECT COUNT(*) FROM game_results b WHERE b.game_id = gr.game_id AND b.team_id = gr.team_id) as possible
+		FROM game_results gr
+	)`
+
+// GetPlayerMachinePoints returns the points a player won on each machine, keyed
+// by machine. If venueKey is non-empty, filters to games played at that venue.
+// If seasons is non-empty, filters to games played in those seasons.
+func (s *SQLiteStore) GetPlayerMachinePoints(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]Points, error) {
+	query := `
+		WITH` + resultPoints + `
+		SELECT g.machine_key, COUNT(*), SUM(rp.points), SUM(rp.possible)
+		FROM result_points rp
+		JOIN players p ON p.id = rp.player_id
+		JOIN games g ON g.id = rp.game_id
+		JOIN matches m ON m.id = g.match_id
+		WHERE p.name = ?
+		  AND g.machine_key IS NOT NULL
+		  AND rp.possible > 0
+	`
+	args := []any{playerName}
+
+	if venueKey != "" {
+		query += " AND m.venue_id = (SELECT id FROM venues WHERE key = ?)"
+		args = append(args, venueKey)
+	}
+
+	cond, condArgs := inSeasons(seasons)
+	query += cond + " GROUP BY g.machine_key"
+	args = append(args, condArgs...)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query player machine points: %w", err)
+	}
+	return scanMachinePoints(rows)
+}
+
+// GetTeamMachinePoints returns the points the players on a team's current
+// roster won on each machine, keyed by machine. Like GetTeamMachineStats, it
+// counts their games for any team, in any season. If seasons is non-empty,
+// filters to games played in those seasons.
+func (s *SQLiteStore) GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]Points, error) {
+	query := `
+		WITH` + resultPoints + `,
+		current_roster AS (
+			SELECT DISTINCT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			WHERE t.key = ?
+			  AND t.season_id = (
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
+			  )
+		)
+		SELECT g.machine_key, COUNT(*), SUM(rp.points), SUM(rp.possible)
+		FROM result_points rp
+		JOIN games g ON g.id = rp.game_id
+		JOIN matches m ON m.id = g.match_id
+		WHERE rp.player_id IN (SELECT player_id FROM current_roster)
+		  AND g.machine_key IS NOT NULL
+		  AND rp.possible > 0
+	`
+	args := []any{teamKey, teamKey}
+
+	cond, condArgs := inSeasons(seasons)
+	query += cond + " GROUP BY g.machine_key"
+	args = append(args, condArgs...)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team machine points: %w", err)
+	}
+	return scanMachinePoints(rows)
+}
+
+func scanMachinePoints(rows *sql.Rows) (map[string]Points, error) {
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]Points)
+	for rows.Next() {
+		var key string
+		var p Points
+		if err := rows.Scan(&key, &p.Games, &p.Won, &p.Possible); err != nil {
+			return nil, fmt.Errorf("scan machine points: %w", err)
+		}
+		result[key] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate machine points: %w", err)
+	}
+
+	return result, nil
+}
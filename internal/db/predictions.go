@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Prediction is the predicted edge on one machine in an upcoming match.
+type Prediction struct {
+	MatchKey    string
+	MachineKey  string
+	HomeTeamKey string
+	AwayTeamKey string
+	Edge        float64 // Positive favors the home team.
+	Confidence  int
+}
+
+// UpsertPrediction records a prediction, replacing any earlier prediction for
+// the same match and machine. Predictions for matches that already have
+// results are left unchanged, so the stored prediction is always the last one
+// made before the match was played.
+func (s *SQLiteStore) UpsertPrediction(ctx context.Context, p Prediction) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO predictions (match_key, machine_key, home_team_key, away_team_key, edge, confidence, predicted_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM games g
+			JOIN matches m ON m.id = g.match_id
+			WHERE m.key = ?
+		)
+		ON CONFLICT (match_key, machine_key) DO UPDATE SET
+			home_team_key = excluded.home_team_key,
+			away_team_key = excluded.away_team_key,
+			edge = excluded.edge,
+			confidence = excluded.confidence,
+			predicted_at = excluded.predicted_at
+	`, p.MatchKey, p.MachineKey, p.HomeTeamKey, p.AwayTeamKey, p.Edge, p.Confidence, time.Now().UTC().Format(time.RFC3339), p.MatchKey)
+	if err != nil {
+		return fmt.Errorf("upsert prediction %s %s: %w", p.MatchKey, p.MachineKey, err)
+	}
+	return nil
+}
+
+// PredictionOutcome is a prediction alongside the points each team actually
+// won on that machine.
+type PredictionOutcome struct {
+	Prediction
+
+	HomePoints float64
+	AwayPoints float64
+}
+
+// ListPredictionOutcomes returns every prediction for a machine that has since
+// been played, ordered by match then machine. Predictions for machines that
+// weren't picked aren't returned.
+func (s *SQLiteStore) ListPredictionOutcomes(ctx context.Context) ([]PredictionOutcome, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			p.match_key,
+			p.machine_key,
+			p.home_team_key,
+			p.away_team_key,
+			p.edge,
+			p.confidence,
+			SUM(CASE WHEN t.key = p.home_team_key THEN gr.points ELSE 0 END),
+			SUM(CASE WHEN t.key = p.away_team_key THEN gr.points ELSE 0 END)
+		FROM predictions p
+		JOIN matches m ON m.key = p.match_key
+		JOIN games g ON g.match_id = m.id AND g.machine_key = p.machine_key
+		JOIN game_results gr ON gr.game_id = g.id
+		JOIN teams t ON t.id = gr.team_id
+		GROUP BY p.match_key, p.machine_key
+		ORDER BY p.match_key, p.machine_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query prediction outcomes: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PredictionOutcome
+	for rows.Next() {
+		var o PredictionOutcome
+		if err := rows.Scan(
+			&o.MatchKey,
+			&o.MachineKey,
+			&o.HomeTeamKey,
+			&o.AwayTeamKey,
+			&o.Edge,
+			&o.Confidence,
+			&o.HomePoints,
+			&o.AwayPoints,
+		); err != nil {
+			return nil, fmt.Errorf("scan prediction outcome: %w", err)
+		}
+		result = append(result, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prediction outcomes: %w", err)
+	}
+
+	return result, nil
+}
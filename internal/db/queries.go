@@ -53,6 +53,126 @@ func (s *SQLiteStore) ListMachines(ctx context.Context, search string) ([]Machin
 	return result, nil
 }
 
+// MachineSummary contains a machine's current venues and how much it's been
+// played this season.
+type MachineSummary struct {
+	Key          string
+	Name         string
+	Manufacturer string  // Empty if unknown. See UpsertMachineDetails.
+	Year         int     // Zero if unknown.
+	Venues       []Venue // Venues that currently have the machine, ordered by key.
+	Games        int     // Games played on the machine in the current (latest) season.
+}
+
+// ListMachineSummaries returns the machines ListMachines would, with the
+// venues that currently have each and how many games it has seen this season.
+func (s *SQLiteStore) ListMachineSummaries(ctx context.Context, search string) ([]MachineSummary, error) {
+	query := `
+		SELECT
+			m.key,
+			m.name,
+			COALESCE(md.manufacturer, ''),
+			COALESCE(md.year, 0),
+			(SELECT COUNT(*) FROM games g
+			 JOIN matches mt ON mt.id = g.match_id
+			 WHERE g.machine_key = m.key
+			   AND mt.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1))
+		FROM machines m
+		LEFT JOIN machine_details md ON md.machine_key = m.key
+		WHERE m.key IN (SELECT DISTINCT machine_key FROM games WHERE machine_key IS NOT NULL)
+	`
+	var args []any
+
+	if search != "" {
+		query += " AND (LOWER(m.key) LIKE ? OR LOWER(m.name) LIKE ?)"
+		pattern := "%" + strings.ToLower(search) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	query += " ORDER BY m.key"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query machine summaries: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []MachineSummary
+	idx := make(map[string]int)
+	for rows.Next() {
+		var m MachineSummary
+		if err := rows.Scan(&m.Key, &m.Name, &m.Manufacturer, &m.Year, &m.Games); err != nil {
+			return nil, fmt.Errorf("scan machine summary: %w", err)
+		}
+		idx[m.Key] = len(result)
+		result = append(result, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate machine summaries: %w", err)
+	}
+
+	vrows, err := s.db.QueryContext(ctx, `
+		WITH `+currentVenueMachinesCTE+`
+		SELECT vm.machine_key, v.key, v.name
+		FROM current_venue_machines vm
+		JOIN venues v ON v.id = vm.venue_id
+		ORDER BY v.key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query machine venues: %w", err)
+	}
+	defer vrows.Close() //nolint:errcheck // Read-only query.
+
+	for vrows.Next() {
+		var key string
+		var v Venue
+		if err := vrows.Scan(&key, &v.Key, &v.Name); err != nil {
+			return nil, fmt.Errorf("scan machine venue: %w", err)
+		}
+		if i, ok := idx[key]; ok {
+			result[i].Venues = append(result[i].Venues, v)
+		}
+	}
+
+	if err := vrows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate machine venues: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListUnmatchedMachines returns the machines ListMachines would that have no
+// manufacturer or year, ordered by name. See UpsertMachineDetails.
+func (s *SQLiteStore) ListUnmatchedMachines(ctx context.Context) ([]Machine, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.key, m.name
+		FROM machines m
+		WHERE m.key IN (SELECT DISTINCT machine_key FROM games WHERE machine_key IS NOT NULL)
+		  AND m.key NOT IN (SELECT machine_key FROM machine_details)
+		ORDER BY m.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query unmatched machines: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []Machine
+	for rows.Next() {
+		var m Machine
+		if err := rows.Scan(&m.Key, &m.Name); err != nil {
+			return nil, fmt.Errorf("scan machine: %w", err)
+		}
+		result = append(result, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unmatched machines: %w", err)
+	}
+
+	return result, nil
+}
+
 // ListVenues returns all venues, optionally filtered by a case-insensitive
 // search term matching key or name.
 func (s *SQLiteStore) ListVenues(ctx context.Context, search string) ([]Venue, error) {
@@ -89,16 +209,98 @@ func (s *SQLiteStore) ListVenues(ctx context.Context, search string) ([]Venue, e
 	return result, nil
 }
 
-// ListTeams returns teams in the current (latest) season, optionally filtered
-// by a case-insensitive search term matching key or name.
-func (s *SQLiteStore) ListTeams(ctx context.Context, search string) ([]TeamSummary, error) {
+// VenueSummary contains a venue's machine count and the teams based there.
+type VenueSummary struct {
+	Key       string
+	Name      string
+	Machines  int      // Machines currently at the venue.
+	HomeTeams []string // Keys of current (latest) season teams based at the venue, ordered by key.
+}
+
+// ListVenueSummaries returns the venues ListVenues would, with how many
+// machines each has and which teams call it home.
+func (s *SQLiteStore) ListVenueSummaries(ctx context.Context, search string) ([]VenueSummary, error) {
+	query := `
+		WITH ` + currentVenueMachinesCTE + `
+		SELECT
+			v.key,
+			v.name,
+			(SELECT COUNT(*) FROM current_venue_machines vm WHERE vm.venue_id = v.id)
+		FROM venues v
+		WHERE 1=1
+	`
+	var args []any
+
+	if search != "" {
+		query += " AND (LOWER(v.key) LIKE ? OR LOWER(v.name) LIKE ?)"
+		pattern := "%" + strings.ToLower(search) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	query += " ORDER BY v.key"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query venue summaries: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []VenueSummary
+	idx := make(map[string]int)
+	for rows.Next() {
+		var v VenueSummary
+		if err := rows.Scan(&v.Key, &v.Name, &v.Machines); err != nil {
+			return nil, fmt.Errorf("scan venue summary: %w", err)
+		}
+		idx[v.Key] = len(result)
+		result = append(result, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate venue summaries: %w", err)
+	}
+
+	trows, err := s.db.QueryContext(ctx, `
+		SELECT v.key, t.key
+		FROM teams t
+		JOIN venues v ON v.id = t.home_venue_id
+		WHERE t.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		ORDER BY t.key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query venue home teams: %w", err)
+	}
+	defer trows.Close() //nolint:errcheck // Read-only query.
+
+	for trows.Next() {
+		var venue, team string
+		if err := trows.Scan(&venue, &team); err != nil {
+			return nil, fmt.Errorf("scan venue home team: %w", err)
+		}
+		if i, ok := idx[venue]; ok {
+			result[i].HomeTeams = append(result[i].HomeTeams, team)
+		}
+	}
+
+	if err := trows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate venue home teams: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListTeams returns teams in the supplied season, or the current (latest)
+// season if it's zero, optionally filtered by a case-insensitive search term
+// matching key or name.
+func (s *SQLiteStore) ListTeams(ctx context.Context, search string, season int) ([]TeamSummary, error) {
 	query := `
 		SELECT t.key, t.name, COALESCE(v.name || ' (' || v.key || ')', '') as venue
 		FROM teams t
+		JOIN seasons s ON s.id = t.season_id
 		LEFT JOIN venues v ON v.id = t.home_venue_id
-		WHERE t.season_id = (SELECT MAX(season_id) FROM teams)
+		WHERE s.number = COALESCE(NULLIF(?, 0), (SELECT MAX(number) FROM seasons))
 	`
-	var args []any
+	args := []any{season}
 
 	if search != "" {
 		query += " AND (LOWER(t.key) LIKE ? OR LOWER(t.name) LIKE ?)"
@@ -148,7 +350,7 @@ func (s *SQLiteStore) ListPlayers(ctx context.Context, search string) ([]PlayerS
 		JOIN rosters r ON r.player_id = p.id
 		JOIN teams t ON t.id = r.team_id
 		LEFT JOIN player_iprs ipr ON ipr.name = p.name
-		WHERE t.season_id = (SELECT MAX(season_id) FROM teams)
+		WHERE t.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
 	`
 	var args []any
 
@@ -182,6 +384,31 @@ func (s *SQLiteStore) ListPlayers(ctx context.Context, search string) ([]PlayerS
 	return result, nil
 }
 
+// ListPlayerNames returns the names of every player in the archive, from every
+// season, in alphabetical order.
+func (s *SQLiteStore) ListPlayerNames(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM players ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("query player names: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan player name: %w", err)
+		}
+		result = append(result, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player names: %w", err)
+	}
+
+	return result, nil
+}
+
 // ListMachineKeys returns the keys of all known machines.
 func (s *SQLiteStore) ListMachineKeys(ctx context.Context) (map[string]bool, error) {
 	rows, err := s.db.QueryContext(ctx, "SELECT key FROM machines")
@@ -221,7 +448,10 @@ func (s *SQLiteStore) GetTeamID(ctx context.Context, key string, seasonID int64)
 // ScheduleMatch contains match schedule info with resolved team and venue
 // names for display.
 type ScheduleMatch struct {
+	Key         string
 	Week        int
+	WeekLabel   string
+	Stage       Stage
 	Date        string
 	HomeTeamKey string
 	HomeTeam    string
@@ -231,12 +461,29 @@ type ScheduleMatch struct {
 	Venue       string
 }
 
+// Playoffs returns true if the match is a playoff match.
+func (m ScheduleMatch) Playoffs() bool {
+	return m.Stage == StagePlayoffs
+}
+
+// WeekName names the match's week, e.g. "Week 3". Playoff weeks are named by
+// the archive's label for them, e.g. "Playoffs SF".
+func (m ScheduleMatch) WeekName() string {
+	if m.Playoffs() {
+		return strings.TrimSpace("Playoffs " + m.WeekLabel)
+	}
+	return fmt.Sprintf("Week %d", m.Week)
+}
+
 // ListSchedule returns all matches on or after the given date, ordered by week
 // then date. The date should be an ISO 8601 date string (e.g. "2025-02-07").
 func (s *SQLiteStore) ListSchedule(ctx context.Context, after string) ([]ScheduleMatch, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT
+			m.key,
 			m.week,
+			m.week_label,
+			m.stage,
 			m.date,
 			ht.key,
 			ht.name,
@@ -248,7 +495,7 @@ func (s *SQLiteStore) ListSchedule(ctx context.Context, after string) ([]Schedul
 		JOIN teams ht ON ht.id = m.home_team_id
 		JOIN teams at ON at.id = m.away_team_id
 		LEFT JOIN venues v ON v.id = m.venue_id
-		WHERE m.season_id = (SELECT MAX(id) FROM seasons)
+		WHERE m.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
 		  AND m.date >= ?
 		ORDER BY m.week, m.date
 	`, after)
@@ -261,7 +508,10 @@ func (s *SQLiteStore) ListSchedule(ctx context.Context, after string) ([]Schedul
 	for rows.Next() {
 		var sm ScheduleMatch
 		if err := rows.Scan(
+			&sm.Key,
 			&sm.Week,
+			&sm.WeekLabel,
+			&sm.Stage,
 			&sm.Date,
 			&sm.HomeTeamKey,
 			&sm.HomeTeam,
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrNotReadOnly is returned when a query passed to QueryReadOnly could write
+// to the database, or is more than one statement.
+var ErrNotReadOnly = errors.New("only a single SELECT statement is allowed")
+
+// QueryResult is the result of an ad-hoc query.
+type QueryResult struct {
+	Columns []string
+	Rows    [][]any // Each row's values, in column order. NULL is nil.
+}
+
+// QueryReadOnly runs a single ad-hoc SELECT statement. It rejects any other
+// kind of statement, and runs the query with SQLite's query_only pragma set so
+// that a statement that looks like a SELECT but writes, such as a WITH clause
+// before a DELETE, fails too.
+func (s *SQLiteStore) QueryReadOnly(ctx context.Context, query string) (*QueryResult, error) {
+	if !readOnly(query) {
+		return nil, ErrNotReadOnly
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // Returned to the pool.
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return nil, fmt.Errorf("set query only: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA query_only = OFF") //nolint:errcheck // Best effort before returning the connection to the pool.
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	r := &QueryResult{}
+	r.Columns, err = rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("get columns: %w", err)
+	}
+
+	for rows.Next() {
+		values := make([]any, len(r.Columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		r.Rows = append(r.Rows, values)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return r, nil
+}
+
+// readOnly returns true if the query is a single statement that starts with
+// SELECT, WITH, or VALUES. Semicolons in comments, string literals, and quoted
+// identifiers don't count as separating statements.
+func readOnly(query string) bool {
+	var code strings.Builder
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			// A doubled quote is an escaped quote, which this treats as the
+			// end of one literal and the start of the next.
+			j := strings.IndexByte(query[i+1:], end)
+			if j < 0 {
+				return false
+			}
+			i += j + 1
+			code.WriteByte(' ')
+		case strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			i += j
+			code.WriteByte(' ')
+		case strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				return false
+			}
+			i += j + 3
+			code.WriteByte(' ')
+		default:
+			code.WriteByte(c)
+		}
+	}
+
+	stmt := strings.TrimRight(strings.TrimSpace(code.String()), "; \t\r\n")
+	if strings.Contains(stmt, ";") {
+		return false
+	}
+
+	stmt = strings.TrimLeft(stmt, "( \t\r\n")
+	end := strings.IndexFunc(stmt, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(stmt)
+	}
+	switch strings.ToUpper(stmt[:end]) {
+	case "SELECT", "WITH", "VALUES":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// PlayedMatch is a match with at least one completed game.
+type PlayedMatch struct {
+	Key         string
+	Week        int
+	Date        string
+	HomeTeamKey string
+	HomeTeam    string
+	AwayTeamKey string
+	AwayTeam    string
+	VenueKey    string
+	Venue       string
+}
+
+// ListPlayedMatches returns a team's matches in the current (latest) season
+// that have results, most recent first.
+func (s *SQLiteStore) ListPlayedMatches(ctx context.Context, teamKey string) ([]PlayedMatch, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			m.key,
+			m.week,
+			COALESCE(m.date, ''),
+			ht.key,
+			ht.name,
+			at.key,
+			at.name,
+			COALESCE(v.key, ''),
+			COALESCE(v.name, '')
+		FROM matches m
+		JOIN teams ht ON ht.id = m.home_team_id
+		JOIN teams at ON at.id = m.away_team_id
+		LEFT JOIN venues v ON v.id = m.venue_id
+		WHERE m.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
+		  AND (ht.key = ? OR at.key = ?)
+		  AND EXISTS (SELECT 1 FROM games g WHERE g.match_id = m.id)
+		ORDER BY m.week DESC, m.date DESC
+	`, teamKey, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query played matches: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []PlayedMatch
+	for rows.Next() {
+		var pm PlayedMatch
+		if err := rows.Scan(
+			&pm.Key,
+			&pm.Week,
+			&pm.Date,
+			&pm.HomeTeamKey,
+			&pm.HomeTeam,
+			&pm.AwayTeamKey,
+			&pm.AwayTeam,
+			&pm.VenueKey,
+			&pm.Venue,
+		); err != nil {
+			return nil, fmt.Errorf("scan played match: %w", err)
+		}
+		result = append(result, pm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate played matches: %w", err)
+	}
+
+	return result, nil
+}
+
+// MatchResult is one player's result in one game of a match.
+type MatchResult struct {
+	GameID     int64
+	Round      int
+	MachineKey string
+	PlayerName string
+	TeamKey    string
+	IPR        int
+	Score      int64
+	Points     float64
+}
+
+// ListMatchResults returns every player result in a match, ordered by round,
+// game, then position.
+func (s *SQLiteStore) ListMatchResults(ctx context.Context, matchKey string) ([]MatchResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			g.id,
+			g.round,
+			COALESCE(g.machine_key, ''),
+			p.name,
+			t.key,
+			COALESCE(ipr.ipr, 0),
+			COALESCE(gr.score, 0),
+			gr.points
+		FROM game_results gr
+		JOIN games g ON g.id = gr.game_id
+		JOIN matches m ON m.id = g.match_id
+		JOIN players p ON p.id = gr.player_id
+		JOIN teams t ON t.id = gr.team_id
+		LEFT JOIN player_iprs ipr ON ipr.name = p.name
+		WHERE m.key = ?
+		ORDER BY g.round, g.id, gr.position
+	`, matchKey)
+	if err != nil {
+		return nil, fmt.Errorf("query match results: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []MatchResult
+	for rows.Next() {
+		var r MatchResult
+		if err := rows.Scan(
+			&r.GameID,
+			&r.Round,
+			&r.MachineKey,
+			&r.PlayerName,
+			&r.TeamKey,
+			&r.IPR,
+			&r.Score,
+			&r.Points,
+		); err != nil {
+			return nil, fmt.Errorf("scan match result: %w", err)
+		}
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate match results: %w", err)
+	}
+
+	return result, nil
+}
+
+// MatchDetail is a match and every player result in it.
+type MatchDetail struct {
+	PlayedMatch
+
+	Season  int
+	Results []MatchResult // Ordered by round, game, then position.
+}
+
+// GetMatchDetail returns a match and every player result in it. It returns nil
+// if the match doesn't exist.
+func (s *SQLiteStore) GetMatchDetail(ctx context.Context, matchKey string) (*MatchDetail, error) {
+	var d MatchDetail
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			m.key,
+			m.week,
+			COALESCE(m.date, ''),
+			ht.key,
+			ht.name,
+			at.key,
+			at.name,
+			COALESCE(v.key, ''),
+			COALESCE(v.name, ''),
+			s.number
+		FROM matches m
+		JOIN seasons s ON s.id = m.season_id
+		JOIN teams ht ON ht.id = m.home_team_id
+		JOIN teams at ON at.id = m.away_team_id
+		LEFT JOIN venues v ON v.id = m.venue_id
+		WHERE m.key = ?
+	`, matchKey).Scan(
+		&d.Key,
+		&d.Week,
+		&d.Date,
+		&d.HomeTeamKey,
+		&d.HomeTeam,
+		&d.AwayTeamKey,
+		&d.AwayTeam,
+		&d.VenueKey,
+		&d.Venue,
+		&d.Season,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get match: %w", err)
+	}
+
+	d.Results, err = s.ListMatchResults(ctx, matchKey)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// SignatureWin is a player's most impressive singles win.
+type SignatureWin struct {
+	MatchKey    string
+	Date        string // ISO date of the match. Empty if unknown.
+	MachineKey  string
+	Opponent    string
+	OpponentIPR int // Zero if the opponent is unrated.
+	Score       int64
+}
+
+// GetSignatureWin returns the singles game a player won against the highest
+// rated opponent, the most recent if there are several. It returns nil if the
+// player hasn't won a singles game.
+func (s *SQLiteStore) GetSignatureWin(ctx context.Context, playerName string) (*SignatureWin, error) {
+	var w SignatureWin
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			m.key,
+			COALESCE(m.date, ''),
+			g.machine_key,
+			op.name,
+			COALESCE(oi.ipr, 0),
+			COALESCE(gr.score, 0)
+		FROM game_results gr
+		JOIN games g ON g.id = gr.game_id
+		JOIN matches m ON m.id = g.match_id
+		JOIN seasons s ON s.id = m.season_id
+		JOIN game_results ogr ON ogr.game_id = gr.game_id AND ogr.team_id != gr.team_id
+		JOIN players op ON op.id = ogr.player_id
+		LEFT JOIN player_iprs oi ON oi.name = op.name
+		WHERE gr.player_id = (SELECT id FROM players WHERE name = ?)
+		  AND g.is_doubles = 0
+		  AND g.machine_key IS NOT NULL
+		  AND gr.points > ogr.points
+		ORDER BY COALESCE(oi.ipr, 0) DESC, s.number DESC, m.week DESC, g.round DESC
+		LIMIT 1
+	`, playerName).Scan(&w.MatchKey, &w.Date, &w.MachineKey, &w.Opponent, &w.OpponentIPR, &w.Score)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get signature win: %w", err)
+	}
+	return &w, nil
+}
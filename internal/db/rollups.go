@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// metadataStatsRebuilt is the sync metadata key recording when RebuildStats
+// last ran, RFC 3339. Schema triggers delete it when game results or rosters
+// change.
+const metadataStatsRebuilt = "mnp_stats_rebuilt"
+
+// RebuildStats materializes each player's and each team's current roster's
+// stats on each machine, replacing any materialized before. Until it's called
+// the stats queries compute everything from game results. After, they look up
+// unweighted, unadjusted, unfiltered stats instead, until game results or
+// rosters change. Call it after loading matches so lookups resume.
+func (s *SQLiteStore) RebuildStats(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	for _, table := range []string{"player_machine_stats", "team_machine_stats"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+
+	// Both use the nearest-rank percentiles the stats queries compute when
+	// every season weighs the same.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO player_machine_stats (player_id, machine_key, games, p50, p90)
+		WITH scores AS (
+			SELECT
+				gr.player_id,
+				g.machine_key,
+				gr.score,
+				COUNT(*) OVER (PARTITION BY gr.player_id, g.machine_key ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				COUNT(*) OVER (PARTITION BY gr.player_id, g.machine_key) as total
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			WHERE g.machine_key IS NOT NULL
+		)
+		SELECT
+			player_id,
+			machine_key,
+			MAX(total),
+			MIN(CASE WHEN cw * 2 >= total THEN score END),
+			MIN(CASE WHEN cw * 10 >= total * 9 THEN score END)
+		FROM scores
+		GROUP BY player_id, machine_key
+	`); err != nil {
+		return fmt.Errorf("rebuild player machine stats: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO team_machine_stats (team_key, machine_key, games, p50, p90)
+		WITH current_rosters AS (
+			SELECT DISTINCT t.key as team_key, r.player_id
+			FROM teams t
+			JOIN seasons s ON s.id = t.season_id
+			JOIN rosters r ON r.team_id = t.id
+			WHERE s.number = (
+				SELECT MAX(s2.number) FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = t.key
+			)
+		),
+		scores AS (
+			SELECT
+				cr.team_key,
+				g.machine_key,
+				gr.score,
+				COUNT(*) OVER (PARTITION BY cr.team_key, g.machine_key ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				COUNT(*) OVER (PARTITION BY cr.team_key, g.machine_key) as total
+			FROM game_results gr
+			JOIN current_rosters cr ON cr.player_id = gr.player_id
+			JOIN games g ON g.id = gr.game_id
+			WHERE g.machine_key IS NOT NULL
+		)
+		SELECT
+			team_key,
+			machine_key,
+			MAX(total),
+			MIN(CASE WHEN cw * 2 >= total THEN score END),
+			MIN(CASE WHEN cw * 10 >= total * 9 THEN score END)
+		FROM scores
+		GROUP BY team_key, machine_key
+	`); err != nil {
+		return fmt.Errorf("rebuild team machine stats: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO sync_metadata (key, value) VALUES (?, ?)",
+		metadataStatsRebuilt, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("set metadata %s: %w", metadataStatsRebuilt, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// useRollups returns true if stats filtered by the supplied venue and seasons
+// can be looked up from the stats RebuildStats materialized. They can't if
+// game results or rosters changed since, even in another process.
+func (s *SQLiteStore) useRollups(ctx context.Context, venueKey string, seasons []int) (bool, error) {
+	if s.recency != 1 || s.adjustment != 0 || venueKey != "" || len(seasons) != 0 {
+		return false, nil
+	}
+	rebuilt, err := s.GetMetadata(ctx, metadataStatsRebuilt)
+	if err != nil {
+		return false, err
+	}
+	return rebuilt != "", nil
+}
+
+// currentRoster is a CTE of the players on a team's current roster. It takes
+// the team's key as its arguments, twice.
+const currentRoster = `
+		current_roster AS (
+			SELECT DISTINCT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			WHERE t.key = ?
+			  AND t.season_id = (
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
+			  )
+		)`
+
+func (s *SQLiteStore) lookupTeamMachineAgg(ctx context.Context, teamKey string) ([]TeamMachineStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT machine_key, games, p50, p90
+		FROM team_machine_stats
+		WHERE team_key = ?
+		ORDER BY games DESC
+	`, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team machine stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []TeamMachineStats
+	for rows.Next() {
+		var ts TeamMachineStats
+		if err := rows.Scan(&ts.MachineKey, &ts.Games, &ts.P50Score, &ts.P90Score); err != nil {
+			return nil, fmt.Errorf("scan team machine stats: %w", err)
+		}
+		stats = append(stats, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team machine stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (s *SQLiteStore) lookupTopPlayers(ctx context.Context, teamKey string) (map[string][]LikelyPlayer, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH`+currentRoster+`,
+		ranked AS (
+			SELECT
+				pms.machine_key,
+				p.name,
+				pms.games,
+				pms.p50,
+				ROW_NUMBER() OVER (PARTITION BY pms.machine_key ORDER BY pms.games DESC, pms.p50 DESC) as rn
+			FROM player_machine_stats pms
+			JOIN players p ON p.id = pms.player_id
+			WHERE pms.player_id IN (SELECT player_id FROM current_roster)
+		)
+		SELECT machine_key, name, games, p50
+		FROM ranked
+		WHERE rn <= 2
+		ORDER BY machine_key, rn
+	`, teamKey, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query top players: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string][]LikelyPlayer)
+	for rows.Next() {
+		var machineKey, name string
+		var games int
+		var p50 float64
+		if err := rows.Scan(&machineKey, &name, &games, &p50); err != nil {
+			return nil, fmt.Errorf("scan top player: %w", err)
+		}
+		result[machineKey] = append(result[machineKey], LikelyPlayer{Name: name, Games: games, P50Score: p50})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate top players: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *SQLiteStore) lookupSinglePlayerMachineStats(ctx context.Context, playerName string) ([]PlayerMachineStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT pms.machine_key, pms.games, pms.p50, pms.p90
+		FROM player_machine_stats pms
+		JOIN players p ON p.id = pms.player_id
+		WHERE p.name = ?
+		ORDER BY pms.games DESC
+	`, playerName)
+	if err != nil {
+		return nil, fmt.Errorf("query single player machine stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []PlayerMachineStats
+	for rows.Next() {
+		var ps PlayerMachineStats
+		if err := rows.Scan(&ps.MachineKey, &ps.Games, &ps.P50Score, &ps.P90Score); err != nil {
+			return nil, fmt.Errorf("scan single player machine stats: %w", err)
+		}
+		stats = append(stats, ps)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate single player machine stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (s *SQLiteStore) lookupPlayerMachineStats(ctx context.Context, teamKey, machineKey string) ([]PlayerStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH`+currentRoster+`
+		SELECT p.name, pms.games, pms.p50, pms.p90, COALESCE(pipr.ipr, 0)
+		FROM player_machine_stats pms
+		JOIN players p ON p.id = pms.player_id
+		LEFT JOIN player_iprs pipr ON pipr.name = p.name
+		WHERE pms.machine_key = ?
+		  AND pms.player_id IN (SELECT player_id FROM current_roster)
+		ORDER BY pms.p50 DESC
+	`, teamKey, teamKey, machineKey)
+	if err != nil {
+		return nil, fmt.Errorf("query player stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []PlayerStats
+	for rows.Next() {
+		var ps PlayerStats
+		if err := rows.Scan(&ps.Name, &ps.Games, &ps.P50Score, &ps.P90Score, &ps.IPR); err != nil {
+			return nil, fmt.Errorf("scan player stats: %w", err)
+		}
+		stats = append(stats, ps)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player stats: %w", err)
+	}
+
+	return stats, nil
+}
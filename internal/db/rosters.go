@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// A RosterChange is a player joining or leaving a team's roster.
+type RosterChange string
+
+// Roster changes.
+const (
+	RosterJoined RosterChange = "joined"
+	RosterLeft   RosterChange = "left"
+)
+
+// RosterEvent is a change to a team's roster, noticed by a sync.
+type RosterEvent struct {
+	Season     int
+	TeamKey    string
+	PlayerName string
+	Change     RosterChange
+	SeenAt     time.Time // When the sync that noticed the change ran.
+}
+
+// ReplaceRoster replaces a team's roster with the supplied players, each with
+// the supplied role. If the team already had a roster, it records the players
+// who joined or left it as roster events. A team getting its first roster,
+// for example in a newly loaded season, gets it without events.
+func (s *SQLiteStore) ReplaceRoster(ctx context.Context, teamID int64, playerIDs []int64, role string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	before, err := rosterPlayerIDs(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+
+	seen := time.Now().UTC().Format(time.RFC3339)
+	record := func(playerID int64, c RosterChange) error {
+		if len(before) == 0 {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO roster_events (season, team_key, player_name, change, seen_at)
+			SELECT s.number, t.key, p.name, ?, ?
+			FROM teams t
+			JOIN seasons s ON s.id = t.season_id
+			JOIN players p ON p.id = ?
+			WHERE t.id = ?
+		`, c, seen, playerID, teamID); err != nil {
+			return fmt.Errorf("record roster event: %w", err)
+		}
+		return nil
+	}
+
+	after := make(map[int64]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		after[id] = true
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO rosters (player_id, team_id, role)
+			VALUES (?, ?, ?)
+			ON CONFLICT(player_id, team_id) DO UPDATE SET role = excluded.role
+		`, id, teamID, role); err != nil {
+			return fmt.Errorf("upsert roster: %w", err)
+		}
+		if !before[id] {
+			if err := record(id, RosterJoined); err != nil {
+				return err
+			}
+		}
+	}
+	for id := range before {
+		if after[id] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM rosters WHERE player_id = ? AND team_id = ?", id, teamID); err != nil {
+			return fmt.Errorf("delete roster: %w", err)
+		}
+		if err := record(id, RosterLeft); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit roster: %w", err)
+	}
+	return nil
+}
+
+func rosterPlayerIDs(ctx context.Context, tx *sql.Tx, teamID int64) (map[int64]bool, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT player_id FROM rosters WHERE team_id = ?", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("query roster: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan roster: %w", err)
+		}
+		ids[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListRosterEvents returns the roster changes in the current (latest) season,
+// newest first, then by team and player. An empty team key returns every
+// team's changes.
+func (s *SQLiteStore) ListRosterEvents(ctx context.Context, teamKey string) ([]RosterEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT season, team_key, player_name, change, seen_at
+		FROM roster_events
+		WHERE season = (SELECT MAX(number) FROM seasons)
+		  AND (? = '' OR team_key = ?)
+		ORDER BY seen_at DESC, team_key, player_name
+	`, teamKey, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query roster events: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var events []RosterEvent
+	for rows.Next() {
+		var e RosterEvent
+		var seen string
+		if err := rows.Scan(&e.Season, &e.TeamKey, &e.PlayerName, &e.Change, &seen); err != nil {
+			return nil, fmt.Errorf("scan roster event: %w", err)
+		}
+		if e.SeenAt, err = time.Parse(time.RFC3339, seen); err != nil {
+			return nil, fmt.Errorf("parse roster event time %q: %w", seen, err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster events: %w", err)
+	}
+
+	return events, nil
+}
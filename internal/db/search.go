@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Kinds of search result.
+const (
+	SearchPlayer  = "player"
+	SearchTeam    = "team"
+	SearchMachine = "machine"
+	SearchVenue   = "venue"
+)
+
+// searchLimit is the most results Search returns.
+const searchLimit = 50
+
+// SearchResult is a player, team, machine, or venue matching a search.
+type SearchResult struct {
+	Kind string // SearchPlayer, SearchTeam, SearchMachine, or SearchVenue.
+	Key  string // A player's name, or a team, machine, or venue's key.
+	Name string
+}
+
+// RebuildSearchIndex replaces the full-text search index with every player,
+// team, machine, and venue. Teams are named as of the latest season they
+// played, and machines are only indexed if they've been played.
+func (s *SQLiteStore) RebuildSearchIndex(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op after commit.
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM search_index"); err != nil {
+		return fmt.Errorf("clear search index: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO search_index (kind, key, name)
+		SELECT ?, name, name FROM players
+		UNION ALL
+		SELECT ?, t.key, t.name
+		FROM teams t
+		JOIN seasons s ON s.id = t.season_id
+		WHERE s.number = (
+			SELECT MAX(s2.number) FROM teams t2
+			JOIN seasons s2 ON s2.id = t2.season_id
+			WHERE t2.key = t.key
+		)
+		UNION ALL
+		SELECT ?, key, name FROM machines
+		WHERE key IN (SELECT DISTINCT machine_key FROM games WHERE machine_key IS NOT NULL)
+		UNION ALL
+		SELECT ?, key, name FROM venues
+	`, SearchPlayer, SearchTeam, SearchMachine, SearchVenue); err != nil {
+		return fmt.Errorf("index search: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Search returns the players, teams, machines, and venues whose key or name
+// has words starting with every word of the query, best matches first.
+func (s *SQLiteStore) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	match := searchMatch(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, key, name
+		FROM search_index
+		WHERE search_index MATCH ?
+		ORDER BY rank, name
+		LIMIT ?
+	`, match, searchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("query search index: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.Kind, &r.Key, &r.Name); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate search results: %w", err)
+	}
+
+	return result, nil
+}
+
+// searchMatch turns a query into an FTS5 match expression that matches every
+// word as a prefix. Each word is quoted so FTS5 syntax in the query, such as
+// AND or a column filter, is searched for rather than interpreted.
+func searchMatch(query string) string {
+	words := strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	for i, w := range words {
+		words[i] = `"` + w + `"*`
+	}
+	return strings.Join(words, " ")
+}
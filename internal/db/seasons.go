@@ -0,0 +1,361 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListSeasons returns the numbers of every loaded season, newest first.
+func (s *SQLiteStore) ListSeasons(ctx context.Context) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT number FROM seasons ORDER BY number DESC")
+	if err != nil {
+		return nil, fmt.Errorf("query seasons: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var seasons []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("scan season: %w", err)
+		}
+		seasons = append(seasons, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate seasons: %w", err)
+	}
+
+	return seasons, nil
+}
+
+// ListTeamSeasons returns the numbers of the seasons a team played in, oldest
+// first.
+func (s *SQLiteStore) ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.number
+		FROM teams t
+		JOIN seasons s ON s.id = t.season_id
+		WHERE t.key = ?
+		ORDER BY s.number
+	`, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team seasons: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var seasons []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("scan team season: %w", err)
+		}
+		seasons = append(seasons, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team seasons: %w", err)
+	}
+
+	return seasons, nil
+}
+
+// GetTeamSeasonMachineStats returns per-machine aggregate stats (P50, P90)
+// for the games a team played in one season. Unlike GetTeamMachineAgg, stats
+// cover whoever played for the team that season, not its current roster.
+// Results are ordered by play count descending.
+func (s *SQLiteStore) GetTeamSeasonMachineStats(ctx context.Context, teamKey string, season int) ([]TeamMachineStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH scores AS (
+			SELECT
+				g.machine_key,
+				gr.score,
+				ROW_NUMBER() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as rn,
+				COUNT(*) OVER (PARTITION BY g.machine_key) as total
+			FROM game_results gr
+			JOIN teams t ON t.id = gr.team_id
+			JOIN seasons s ON s.id = t.season_id
+			JOIN games g ON g.id = gr.game_id
+			WHERE t.key = ?
+			  AND s.number = ?
+			  AND g.machine_key IS NOT NULL
+		),
+		machine_agg AS (
+			SELECT DISTINCT machine_key, total
+			FROM scores
+		)
+		SELECT
+			ma.machine_key,
+			ma.total as games,
+			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.rn = (ma.total + 1) / 2) as p50,
+			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.rn = (ma.total * 9 + 9) / 10) as p90
+		FROM machine_agg ma
+		ORDER BY games DESC, ma.machine_key
+	`, teamKey, season)
+	if err != nil {
+		return nil, fmt.Errorf("query team season machine stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []TeamMachineStats
+	for rows.Next() {
+		var ts TeamMachineStats
+		if err := rows.Scan(&ts.MachineKey, &ts.Games, &ts.P50Score, &ts.P90Score); err != nil {
+			return nil, fmt.Errorf("scan team season machine stats: %w", err)
+		}
+		stats = append(stats, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team season machine stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetTeamSeasonRosterMachineStats returns per-machine aggregate stats (P50,
+// P90) for every game played by the players on a team's roster in one season,
+// in any season and for any team. Comparing two seasons' results shows how a
+// team's roster changes made it stronger or weaker on each machine, whatever
+// its players scored that season. Results are ordered by play count
+// descending.
+func (s *SQLiteStore) GetTeamSeasonRosterMachineStats(ctx context.Context, teamKey string, season int) ([]TeamMachineStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH roster AS (
+			SELECT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			JOIN seasons s ON s.id = t.season_id
+			WHERE t.key = ?
+			  AND s.number = ?
+		),
+		scores AS (
+			SELECT
+				g.machine_key,
+				gr.score,
+				ROW_NUMBER() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as rn,
+				COUNT(*) OVER (PARTITION BY g.machine_key) as total
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			WHERE gr.player_id IN (SELECT player_id FROM roster)
+			  AND g.machine_key IS NOT NULL
+		),
+		machine_agg AS (
+			SELECT DISTINCT machine_key, total
+			FROM scores
+		)
+		SELECT
+			ma.machine_key,
+			ma.total as games,
+			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.rn = (ma.total + 1) / 2) as p50,
+			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.rn = (ma.total * 9 + 9) / 10) as p90
+		FROM machine_agg ma
+		ORDER BY games DESC, ma.machine_key
+	`, teamKey, season)
+	if err != nil {
+		return nil, fmt.Errorf("query team season roster machine stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []TeamMachineStats
+	for rows.Next() {
+		var ts TeamMachineStats
+		if err := rows.Scan(&ts.MachineKey, &ts.Games, &ts.P50Score, &ts.P90Score); err != nil {
+			return nil, fmt.Errorf("scan team season roster machine stats: %w", err)
+		}
+		stats = append(stats, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team season roster machine stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetTeamSeasonRoster returns the names of the players on a team's roster in
+// one season, sorted by name.
+func (s *SQLiteStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.name
+		FROM rosters r
+		JOIN players p ON p.id = r.player_id
+		JOIN teams t ON t.id = r.team_id
+		JOIN seasons s ON s.id = t.season_id
+		WHERE t.key = ?
+		  AND s.number = ?
+		ORDER BY p.name
+	`, teamKey, season)
+	if err != nil {
+		return nil, fmt.Errorf("query team season roster: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan team season roster: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team season roster: %w", err)
+	}
+
+	return names, nil
+}
+
+// TeamSeasonPoints is the match points a team and its opponents earned over
+// one season.
+type TeamSeasonPoints struct {
+	Matches        int
+	Points         float64
+	OpponentPoints float64
+}
+
+// GetTeamSeasonPoints returns the points a team and its opponents earned in
+// the team's played matches in one season.
+func (s *SQLiteStore) GetTeamSeasonPoints(ctx context.Context, teamKey string, season int) (TeamSeasonPoints, error) {
+	var p TeamSeasonPoints
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(DISTINCT m.id),
+			COALESCE(SUM(CASE WHEN gr.team_id = t.id THEN gr.points END), 0),
+			COALESCE(SUM(CASE WHEN gr.team_id != t.id THEN gr.points END), 0)
+		FROM teams t
+		JOIN seasons s ON s.id = t.season_id
+		JOIN matches m ON m.home_team_id = t.id OR m.away_team_id = t.id
+		JOIN games g ON g.match_id = m.id
+		JOIN game_results gr ON gr.game_id = g.id
+		WHERE t.key = ?
+		  AND s.number = ?
+	`, teamKey, season).Scan(&p.Matches, &p.Points, &p.OpponentPoints); err != nil {
+		return p, fmt.Errorf("get team season points: %w", err)
+	}
+	return p, nil
+}
+
+// RosterRetention is how much of a team's roster carried over from the
+// previous season.
+type RosterRetention struct {
+	TeamKey  string
+	TeamName string
+	Roster   int // Players on the current roster.
+	Previous int // Players on the previous season's roster. Zero for new teams.
+	Kept     int // Players on both rosters.
+}
+
+// ListRosterRetention returns, for each team in the current (latest) season,
+// how many players from its roster in the previous season are still on it.
+// Results are ordered by team key.
+func (s *SQLiteStore) ListRosterRetention(ctx context.Context) ([]RosterRetention, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH current_season AS (
+			SELECT id, number FROM seasons ORDER BY number DESC LIMIT 1
+		),
+		previous_season AS (
+			SELECT id FROM seasons
+			WHERE number < (SELECT number FROM current_season)
+			ORDER BY number DESC LIMIT 1
+		),
+		previous_roster AS (
+			SELECT pt.key as team_key, r.player_id
+			FROM rosters r
+			JOIN teams pt ON pt.id = r.team_id
+			WHERE pt.season_id = (SELECT id FROM previous_season)
+		)
+		SELECT
+			t.key,
+			t.name,
+			(SELECT COUNT(*) FROM rosters r WHERE r.team_id = t.id),
+			(SELECT COUNT(*) FROM previous_roster pr WHERE pr.team_key = t.key),
+			(SELECT COUNT(*) FROM rosters r
+			 JOIN previous_roster pr ON pr.player_id = r.player_id AND pr.team_key = t.key
+			 WHERE r.team_id = t.id)
+		FROM teams t
+		WHERE t.season_id = (SELECT id FROM current_season)
+		ORDER BY t.key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query roster retention: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var result []RosterRetention
+	for rows.Next() {
+		var rr RosterRetention
+		if err := rows.Scan(&rr.TeamKey, &rr.TeamName, &rr.Roster, &rr.Previous, &rr.Kept); err != nil {
+			return nil, fmt.Errorf("scan roster retention: %w", err)
+		}
+		result = append(result, rr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster retention: %w", err)
+	}
+
+	return result, nil
+}
+
+// TeamMachineScore is one score by a player on a team's current roster.
+type TeamMachineScore struct {
+	MachineKey string
+	SeasonsAgo int // Zero for the current season.
+	Score      int64
+}
+
+// ListTeamMachineScores returns every score by players on a team's current
+// roster (latest season with that team key), across all seasons, ordered by
+// machine then score.
+func (s *SQLiteStore) ListTeamMachineScores(ctx context.Context, teamKey string) ([]TeamMachineScore, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH current_roster AS (
+			SELECT DISTINCT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			WHERE t.key = ?
+			  AND t.season_id = (
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
+			  )
+		)
+		SELECT
+			g.machine_key,
+			(SELECT MAX(number) FROM seasons) - s.number,
+			gr.score
+		FROM game_results gr
+		JOIN games g ON g.id = gr.game_id
+		JOIN matches m ON m.id = g.match_id
+		JOIN seasons s ON s.id = m.season_id
+		WHERE gr.player_id IN (SELECT player_id FROM current_roster)
+		  AND g.machine_key IS NOT NULL
+		ORDER BY g.machine_key, gr.score
+	`, teamKey, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team machine scores: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var scores []TeamMachineScore
+	for rows.Next() {
+		var ts TeamMachineScore
+		if err := rows.Scan(&ts.MachineKey, &ts.SeasonsAgo, &ts.Score); err != nil {
+			return nil, fmt.Errorf("scan team machine score: %w", err)
+		}
+		scores = append(scores, ts)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team machine scores: %w", err)
+	}
+
+	return scores, nil
+}
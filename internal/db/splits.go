@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// HomeAwaySplit compares scores on a machine in matches the player's team
+// hosted against scores in matches it played away.
+type HomeAwaySplit struct {
+	HomeGames int
+	HomeP50   float64
+	AwayGames int
+	AwayP50   float64
+}
+
+// GetTeamHomeAwaySplits returns the P50 of a team's current roster on each
+// machine at home and away, keyed by machine. Like GetTeamMachineStats, it
+// counts their games for any team, in any season, and a game is at home if
+// the player's team hosted the match. If seasons is non-empty, filters to
+// games played in those seasons.
+func (s *SQLiteStore) GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]HomeAwaySplit, error) {
+	roster := `,
+		current_roster AS (
+			SELECT DISTINCT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			WHERE t.key = ?
+			  AND t.season_id = (
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
+			  )
+		)`
+	splits, err := s.homeAwaySplits(ctx, roster, "gr.player_id IN (SELECT player_id FROM current_roster)", []any{teamKey, teamKey}, seasons)
+	if err != nil {
+		return nil, fmt.Errorf("query team home away splits: %w", err)
+	}
+	return splits, nil
+}
+
+// GetPlayerHomeAwaySplits returns a player's P50 on each machine at home and
+// away, keyed by machine. A game is at home if the player's team hosted the
+// match. If seasons is non-empty, filters to games played in those seasons.
+func (s *SQLiteStore) GetPlayerHomeAwaySplits(ctx context.Context, playerName string, seasons []int) (map[string]HomeAwaySplit, error) {
+	splits, err := s.homeAwaySplits(ctx, "", "gr.player_id = (SELECT id FROM players WHERE name = ?)", []any{playerName}, seasons)
+	if err != nil {
+		return nil, fmt.Errorf("query player home away splits: %w", err)
+	}
+	return splits, nil
+}
+
+// homeAwaySplits returns the P50 of the results matching the supplied
+// condition on each machine, split by whether the player's team hosted the
+// match. The ctes are appended to the stats CTEs, and their arguments and the
+// condition's are passed in args.
+func (s *SQLiteStore) homeAwaySplits(ctx context.Context, ctes, cond string, args []any, seasons []int) (map[string]HomeAwaySplit, error) {
+	query := `
+		WITH` + statsCTEs + ctes + `,
+		scores AS (
+			SELECT
+				g.machine_key,
+				gr.team_id = m.home_team_id as home,
+				gr.score,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key, gr.team_id = m.home_team_id ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key, gr.team_id = m.home_team_id) as tw,
+				COUNT(*) OVER (PARTITION BY g.machine_key, gr.team_id = m.home_team_id) as total
+			FROM adjusted_results gr
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			JOIN season_weights sw ON sw.season_id = m.season_id
+			WHERE ` + cond + `
+			  AND g.machine_key IS NOT NULL
+	`
+	args = append(s.statsArgs(), args...)
+
+	seasonCond, seasonArgs := inSeasons(seasons)
+	query += seasonCond
+	args = append(args, seasonArgs...)
+
+	query += `
+		),
+		split_agg AS (
+			SELECT DISTINCT machine_key, home, total
+			FROM scores
+		)
+		SELECT
+			sa.machine_key,
+			sa.home,
+			sa.total,
+			(SELECT MIN(score) FROM scores s WHERE s.machine_key = sa.machine_key
+			 AND s.home = sa.home AND s.cw * 2 >= s.tw) as p50
+		FROM split_agg sa
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]HomeAwaySplit)
+	for rows.Next() {
+		var key string
+		var home bool
+		var games int
+		var p50 float64
+		if err := rows.Scan(&key, &home, &games, &p50); err != nil {
+			return nil, fmt.Errorf("scan home away split: %w", err)
+		}
+		split := result[key]
+		if home {
+			split.HomeGames, split.HomeP50 = games, p50
+		} else {
+			split.AwayGames, split.AwayP50 = games, p50
+		}
+		result[key] = split
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate home away splits: %w", err)
+	}
+
+	return result, nil
+}
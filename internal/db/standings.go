@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Standing is a team's record over one season.
+type Standing struct {
+	TeamKey        string
+	TeamName       string
+	Played         int
+	Wins           int
+	Losses         int
+	Ties           int
+	Points         float64
+	OpponentPoints float64
+}
+
+// GetStandings returns every team's record in the supplied season, or the
+// current (latest) season if it's zero. A team wins a match by earning more
+// points than its opponent. Only regular season matches count. Teams are
+// ordered by total points, most first, as the league ranks them.
+func (s *SQLiteStore) GetStandings(ctx context.Context, season int) ([]Standing, error) {
+	return s.GetStandingsThroughWeek(ctx, season, 0)
+}
+
+// GetStandingsThroughWeek returns every team's record like GetStandings, but
+// counts only matches played in or before the supplied week. A zero week
+// counts every match.
+func (s *SQLiteStore) GetStandingsThroughWeek(ctx context.Context, season, week int) ([]Standing, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH season AS (
+			SELECT id FROM seasons
+			WHERE number = COALESCE(NULLIF(?, 0), (SELECT MAX(number) FROM seasons))
+		),
+		match_points AS (
+			SELECT m.id AS match_id, gr.team_id, SUM(gr.points) AS points
+			FROM matches m
+			JOIN games g ON g.match_id = m.id
+			JOIN game_results gr ON gr.game_id = g.id
+			WHERE m.season_id = (SELECT id FROM season)
+			  AND m.stage = 'regular'
+			  AND (? = 0 OR m.week <= ?)
+			GROUP BY m.id, gr.team_id
+		),
+		results AS (
+			SELECT us.team_id, us.points, COALESCE(them.points, 0) AS opponent_points
+			FROM match_points us
+			LEFT JOIN match_points them ON them.match_id = us.match_id AND them.team_id != us.team_id
+		)
+		SELECT
+			t.key,
+			t.name,
+			COUNT(r.team_id),
+			COALESCE(SUM(r.points > r.opponent_points), 0),
+			COALESCE(SUM(r.points < r.opponent_points), 0),
+			COALESCE(SUM(r.points = r.opponent_points), 0),
+			COALESCE(SUM(r.points), 0) AS points,
+			COALESCE(SUM(r.opponent_points), 0)
+		FROM teams t
+		LEFT JOIN results r ON r.team_id = t.id
+		WHERE t.season_id = (SELECT id FROM season)
+		GROUP BY t.id
+		ORDER BY points DESC, t.key
+	`, season, week, week)
+	if err != nil {
+		return nil, fmt.Errorf("query standings: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var out []Standing
+	for rows.Next() {
+		var st Standing
+		if err := rows.Scan(&st.TeamKey, &st.TeamName, &st.Played, &st.Wins, &st.Losses, &st.Ties, &st.Points, &st.OpponentPoints); err != nil {
+			return nil, fmt.Errorf("scan standing: %w", err)
+		}
+		out = append(out, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate standings: %w", err)
+	}
+	return out, nil
+}
@@ -3,8 +3,65 @@ package db
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 )
 
+// seasonWeights is a CTE that weights each season by how recent it is. It
+// takes the store's recency weight as its only argument. Stats queries weight
+// each score by its season, then pick the lowest score whose cumulative weight
+// reaches the percentile, which is the plain nearest-rank percentile when every
+// season weighs the same.
+const seasonWeights = `
+		season_weights AS (
+			SELECT id as season_id, POWER(?, (SELECT MAX(number) FROM seasons) - number) as weight
+			FROM seasons
+		)`
+
+// adjustedResults is a CTE of game results with each score adjusted for the
+// strength of the opposing players, per WithOpponentAdjustment. It takes the
+// store's opponent adjustment as its arguments, twice. Opponents are rated by
+// IPR, relative to the league's average IPR. Unrated opponents count as
+// average.
+const adjustedResults = `
+		adjusted_results AS (
+			SELECT
+				gr.game_id,
+				gr.player_id,
+				gr.team_id,
+				CASE WHEN ? = 0 THEN gr.score ELSE gr.score * (1 + ? * COALESCE((
+					SELECT AVG(oi.ipr) FROM game_results o
+					JOIN players op ON op.id = o.player_id
+					JOIN player_iprs oi ON oi.name = op.name
+					WHERE o.game_id = gr.game_id AND o.team_id != gr.team_id
+				) - (SELECT AVG(ipr) FROM player_iprs), 0)) END as score
+			FROM game_results gr
+		)`
+
+// statsCTEs are the CTEs stats queries compute P50 and P90 from. Their
+// arguments are returned by statsArgs.
+const statsCTEs = seasonWeights + "," + adjustedResults
+
+// statsArgs returns the arguments to statsCTEs.
+func (s *SQLiteStore) statsArgs() []any {
+	return []any{s.recency, s.adjustment, s.adjustment}
+}
+
+// inSeasons returns a condition restricting matches aliased m to the supplied
+// seasons, and its arguments. It returns an empty condition if seasons is
+// empty.
+func inSeasons(seasons []int) (string, []any) {
+	if len(seasons) == 0 {
+		return "", nil
+	}
+	args := make([]any, len(seasons))
+	for i, n := range seasons {
+		args[i] = n
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(seasons)), ", ")
+	return " AND m.season_id IN (SELECT id FROM seasons WHERE number IN (" + placeholders + "))", args
+}
+
 // PlayerStats contains aggregated stats for a player on a specific machine.
 type PlayerStats struct {
 	Name     string
@@ -32,17 +89,20 @@ type LikelyPlayer struct {
 
 // GetTeamMachineStats returns per-machine stats for a team's current roster.
 // Stats are aggregated across all seasons, but only for players currently on
-// the team (latest season with that team key).
-// If venueKey is non-empty, filters to games played at that venue.
+// the team (latest season with that team key). Earlier seasons are weighted
+// per WithRecencyWeight.
+// If venueKey is non-empty, filters to games played at that venue. If seasons
+// is non-empty, filters to games played in those seasons.
 // Results are ordered by play count descending (most-played machines first).
-// Top 2 players per machine (by P50) are included.
-func (s *SQLiteStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]TeamMachineStats, error) {
-	stats, err := s.GetTeamMachineAgg(ctx, teamKey, venueKey)
+// Top 2 players per machine (by P50) are included. Unweighted, unfiltered stats
+// are looked up if RebuildStats has run since game results last changed.
+func (s *SQLiteStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]TeamMachineStats, error) {
+	stats, err := s.GetTeamMachineAgg(ctx, teamKey, venueKey, seasons)
 	if err != nil {
 		return nil, err
 	}
 
-	topPlayers, err := s.GetTopPlayers(ctx, teamKey, venueKey)
+	topPlayers, err := s.GetTopPlayers(ctx, teamKey, venueKey, seasons)
 	if err != nil {
 		return nil, err
 	}
@@ -55,43 +115,58 @@ func (s *SQLiteStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey
 }
 
 // GetTeamMachineAgg returns per-machine aggregate stats (P50, P90) for a
-// team's current roster.
-func (s *SQLiteStore) GetTeamMachineAgg(ctx context.Context, teamKey, venueKey string) ([]TeamMachineStats, error) {
+// team's current roster, filtered like GetTeamMachineStats.
+func (s *SQLiteStore) GetTeamMachineAgg(ctx context.Context, teamKey, venueKey string, seasons []int) ([]TeamMachineStats, error) {
+	rollups, err := s.useRollups(ctx, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	if rollups {
+		return s.lookupTeamMachineAgg(ctx, teamKey)
+	}
+
 	query := `
-		WITH current_roster AS (
+		WITH` + statsCTEs + `,
+		current_roster AS (
 			SELECT DISTINCT p.id as player_id
 			FROM players p
 			JOIN rosters r ON r.player_id = p.id
 			JOIN teams t ON t.id = r.team_id
 			WHERE t.key = ?
 			  AND t.season_id = (
-				SELECT MAX(t2.season_id) FROM teams t2 WHERE t2.key = ?
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
 			  )
 		),
 		scores AS (
 			SELECT
 				g.machine_key,
 				gr.score,
-				ROW_NUMBER() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as rn,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key) as tw,
 				COUNT(*) OVER (PARTITION BY g.machine_key) as total
-			FROM game_results gr
+			FROM adjusted_results gr
 			JOIN players p ON p.id = gr.player_id
 			JOIN games g ON g.id = gr.game_id
 			JOIN matches m ON m.id = g.match_id
+			JOIN season_weights sw ON sw.season_id = m.season_id
 			WHERE p.id IN (SELECT player_id FROM current_roster)
 			  AND g.machine_key IS NOT NULL
 	`
-	args := []any{teamKey, teamKey}
+	args := append(s.statsArgs(), teamKey, teamKey)
 
 	if venueKey != "" {
 		query += " AND m.venue_id = (SELECT id FROM venues WHERE key = ?)"
-		query += ` AND g.machine_key IN (
-			SELECT vm.machine_key FROM venue_machines vm
-			JOIN venues v ON v.id = vm.venue_id
-			WHERE v.key = ?)`
-		args = append(args, venueKey, venueKey)
+		query += " AND g.machine_key IN (" + venueMachinesQuery + ")"
+		args = append(args, venueKey, venueKey, LatestSeason(seasons))
 	}
 
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
 	query += `
 		),
 		machine_agg AS (
@@ -101,10 +176,10 @@ func (s *SQLiteStore) GetTeamMachineAgg(ctx context.Context, teamKey, venueKey s
 		SELECT
 			ma.machine_key,
 			ma.total as games,
-			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
-			 AND s.rn = (ma.total + 1) / 2) as p50,
-			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
-			 AND s.rn = (ma.total * 9 + 9) / 10) as p90
+			(SELECT MIN(score) FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.cw * 2 >= s.tw) as p50,
+			(SELECT MIN(score) FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.cw * 10 >= s.tw * 9) as p90
 		FROM machine_agg ma
 		ORDER BY games DESC
 	`
@@ -132,17 +207,29 @@ func (s *SQLiteStore) GetTeamMachineAgg(ctx context.Context, teamKey, venueKey s
 }
 
 // GetTopPlayers returns the top 2 players by play count for each machine,
-// keyed by machine key.
-func (s *SQLiteStore) GetTopPlayers(ctx context.Context, teamKey, venueKey string) (map[string][]LikelyPlayer, error) {
+// keyed by machine key, filtered like GetTeamMachineStats.
+func (s *SQLiteStore) GetTopPlayers(ctx context.Context, teamKey, venueKey string, seasons []int) (map[string][]LikelyPlayer, error) {
+	rollups, err := s.useRollups(ctx, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	if rollups {
+		return s.lookupTopPlayers(ctx, teamKey)
+	}
+
 	query := `
-		WITH current_roster AS (
+		WITH` + statsCTEs + `,
+		current_roster AS (
 			SELECT DISTINCT p.id as player_id
 			FROM players p
 			JOIN rosters r ON r.player_id = p.id
 			JOIN teams t ON t.id = r.team_id
 			WHERE t.key = ?
 			  AND t.season_id = (
-				SELECT MAX(t2.season_id) FROM teams t2 WHERE t2.key = ?
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
 			  )
 		),
 		player_scores AS (
@@ -151,26 +238,29 @@ func (s *SQLiteStore) GetTopPlayers(ctx context.Context, teamKey, venueKey strin
 				gr.player_id,
 				p.name,
 				gr.score,
-				ROW_NUMBER() OVER (PARTITION BY g.machine_key, gr.player_id ORDER BY gr.score) as rn,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key, gr.player_id ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key, gr.player_id) as tw,
 				COUNT(*) OVER (PARTITION BY g.machine_key, gr.player_id) as total
-			FROM game_results gr
+			FROM adjusted_results gr
 			JOIN players p ON p.id = gr.player_id
 			JOIN games g ON g.id = gr.game_id
 			JOIN matches m ON m.id = g.match_id
+			JOIN season_weights sw ON sw.season_id = m.season_id
 			WHERE p.id IN (SELECT player_id FROM current_roster)
 			  AND g.machine_key IS NOT NULL
 	`
-	args := []any{teamKey, teamKey}
+	args := append(s.statsArgs(), teamKey, teamKey)
 
 	if venueKey != "" {
 		query += " AND m.venue_id = (SELECT id FROM venues WHERE key = ?)"
-		query += ` AND g.machine_key IN (
-			SELECT vm.machine_key FROM venue_machines vm
-			JOIN venues v ON v.id = vm.venue_id
-			WHERE v.key = ?)`
-		args = append(args, venueKey, venueKey)
+		query += " AND g.machine_key IN (" + venueMachinesQuery + ")"
+		args = append(args, venueKey, venueKey, LatestSeason(seasons))
 	}
 
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
 	query += `
 		),
 		player_agg AS (
@@ -179,10 +269,10 @@ func (s *SQLiteStore) GetTopPlayers(ctx context.Context, teamKey, venueKey strin
 				player_id,
 				name,
 				total as games,
-				(SELECT score FROM player_scores ps2
+				(SELECT MIN(score) FROM player_scores ps2
 				 WHERE ps2.machine_key = ps1.machine_key
 				   AND ps2.player_id = ps1.player_id
-				   AND ps2.rn = (ps1.total + 1) / 2
+				   AND ps2.cw * 2 >= ps2.tw
 				) as p50
 			FROM player_scores ps1
 		),
@@ -225,6 +315,117 @@ func (s *SQLiteStore) GetTopPlayers(ctx context.Context, teamKey, venueKey strin
 	return result, nil
 }
 
+// Attendance is how often a rostered player has played for their team this
+// season.
+type Attendance struct {
+	Matches     int // Matches the player was in the lineup for.
+	TeamMatches int // Matches the team has played.
+	Games       int // Games the player played.
+}
+
+// GetTeamAttendance returns how many of a team's played matches in its latest
+// season each player on its current roster was in the lineup for, and how many
+// games they played, keyed by player name.
+func (s *SQLiteStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]Attendance, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH team AS (
+			SELECT t.id FROM teams t
+			JOIN seasons s ON s.id = t.season_id
+			WHERE t.key = ?
+			ORDER BY s.number DESC LIMIT 1
+		),
+		played AS (
+			SELECT DISTINCT m.id
+			FROM matches m
+			JOIN games g ON g.match_id = m.id
+			WHERE m.home_team_id = (SELECT id FROM team)
+			   OR m.away_team_id = (SELECT id FROM team)
+		)
+		SELECT
+			p.name,
+			(SELECT COUNT(*) FROM match_lineups ml
+			 WHERE ml.player_id = r.player_id AND ml.team_id = r.team_id
+			   AND ml.match_id IN (SELECT id FROM played)),
+			(SELECT COUNT(*) FROM played),
+			(SELECT COUNT(*) FROM game_results gr
+			 WHERE gr.player_id = r.player_id AND gr.team_id = r.team_id)
+		FROM rosters r
+		JOIN players p ON p.id = r.player_id
+		WHERE r.team_id = (SELECT id FROM team)
+	`, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team attendance: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]Attendance)
+	for rows.Next() {
+		var name string
+		var a Attendance
+		if err := rows.Scan(&name, &a.Matches, &a.TeamMatches, &a.Games); err != nil {
+			return nil, fmt.Errorf("scan team attendance: %w", err)
+		}
+		result[name] = a
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team attendance: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetTeamAbsences returns the weeks of a team's played matches in its latest
+// season that each player on its current roster wasn't in the lineup for,
+// keyed by player name. Players who haven't missed a match are omitted.
+func (s *SQLiteStore) GetTeamAbsences(ctx context.Context, teamKey string) (map[string][]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH team AS (
+			SELECT t.id FROM teams t
+			JOIN seasons s ON s.id = t.season_id
+			WHERE t.key = ?
+			ORDER BY s.number DESC LIMIT 1
+		),
+		played AS (
+			SELECT DISTINCT m.id, m.week
+			FROM matches m
+			JOIN games g ON g.match_id = m.id
+			WHERE m.home_team_id = (SELECT id FROM team)
+			   OR m.away_team_id = (SELECT id FROM team)
+		)
+		SELECT p.name, pl.week
+		FROM rosters r
+		JOIN players p ON p.id = r.player_id
+		CROSS JOIN played pl
+		WHERE r.team_id = (SELECT id FROM team)
+		  AND NOT EXISTS (
+			SELECT 1 FROM match_lineups ml
+			WHERE ml.match_id = pl.id AND ml.player_id = r.player_id AND ml.team_id = r.team_id
+		  )
+		ORDER BY p.name, pl.week
+	`, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team absences: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string][]int)
+	for rows.Next() {
+		var name string
+		var week int
+		if err := rows.Scan(&name, &week); err != nil {
+			return nil, fmt.Errorf("scan team absence: %w", err)
+		}
+		result[name] = append(result[name], week)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team absences: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetLeagueP50 returns the league-wide P50 score for each machine. League P50
 // is computed across all scores by players on any team's current roster.
 func (s *SQLiteStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
@@ -233,7 +434,7 @@ func (s *SQLiteStore) GetLeagueP50(ctx context.Context) (map[string]float64, err
 			SELECT DISTINCT r.player_id
 			FROM rosters r
 			JOIN teams t ON t.id = r.team_id
-			WHERE t.season_id = (SELECT MAX(season_id) FROM teams)
+			WHERE t.season_id = (SELECT id FROM seasons ORDER BY number DESC LIMIT 1)
 		),
 		scores AS (
 			SELECT
@@ -281,6 +482,54 @@ func (s *SQLiteStore) GetLeagueP50(ctx context.Context) (map[string]float64, err
 	return result, nil
 }
 
+// LeagueScores are every league score posted on a machine, lowest first.
+type LeagueScores []int64
+
+// PercentRank returns the fraction of the scores below the supplied score, from
+// 0 to 1. It ranks scores like ListPlayerMachineScores does, so a P50 can be
+// compared across machines whose scores differ by orders of magnitude. It
+// returns 0 if there are no scores.
+func (l LeagueScores) PercentRank(score float64) float64 {
+	if len(l) == 0 {
+		return 0
+	}
+	below := sort.Search(len(l), func(i int) bool { return float64(l[i]) >= score })
+	return float64(below) / float64(len(l))
+}
+
+// GetLeagueScores returns every score ever posted on each machine, lowest
+// first, so scores can be ranked against the league's.
+func (s *SQLiteStore) GetLeagueScores(ctx context.Context) (map[string]LeagueScores, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT g.machine_key, gr.score
+		FROM game_results gr
+		JOIN games g ON g.id = gr.game_id
+		WHERE g.machine_key IS NOT NULL
+		  AND gr.score IS NOT NULL
+		ORDER BY g.machine_key, gr.score
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query league scores: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]LeagueScores)
+	for rows.Next() {
+		var key string
+		var score int64
+		if err := rows.Scan(&key, &score); err != nil {
+			return nil, fmt.Errorf("scan league score: %w", err)
+		}
+		result[key] = append(result[key], score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate league scores: %w", err)
+	}
+
+	return result, nil
+}
+
 // PlayerMachineStats contains per-machine stats for a single player.
 type PlayerMachineStats struct {
 	MachineKey string
@@ -290,30 +539,47 @@ type PlayerMachineStats struct {
 }
 
 // GetSinglePlayerMachineStats returns per-machine stats for a single player.
-// If venueKey is non-empty, filters to games played at that venue.
+// Earlier seasons are weighted per WithRecencyWeight.
+// If venueKey is non-empty, filters to games played at that venue. If seasons
+// is non-empty, filters to games played in those seasons.
 // Results are ordered by play count descending.
-func (s *SQLiteStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string) ([]PlayerMachineStats, error) {
+func (s *SQLiteStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]PlayerMachineStats, error) {
+	rollups, err := s.useRollups(ctx, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	if rollups {
+		return s.lookupSinglePlayerMachineStats(ctx, playerName)
+	}
+
 	query := `
-		WITH scores AS (
+		WITH` + statsCTEs + `,
+		scores AS (
 			SELECT
 				g.machine_key,
 				gr.score,
-				ROW_NUMBER() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as rn,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				SUM(sw.weight) OVER (PARTITION BY g.machine_key) as tw,
 				COUNT(*) OVER (PARTITION BY g.machine_key) as total
-			FROM game_results gr
+			FROM adjusted_results gr
 			JOIN players p ON p.id = gr.player_id
 			JOIN games g ON g.id = gr.game_id
 			JOIN matches m ON m.id = g.match_id
+			JOIN season_weights sw ON sw.season_id = m.season_id
 			WHERE p.name = ?
 			  AND g.machine_key IS NOT NULL
 	`
-	args := []any{playerName}
+	args := append(s.statsArgs(), playerName)
 
 	if venueKey != "" {
 		query += " AND m.venue_id = (SELECT id FROM venues WHERE key = ?)"
 		args = append(args, venueKey)
 	}
 
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
 	query += `
 		),
 		machine_agg AS (
@@ -323,10 +589,10 @@ func (s *SQLiteStore) GetSinglePlayerMachineStats(ctx context.Context, playerNam
 		SELECT
 			ma.machine_key,
 			ma.total as games,
-			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
-			 AND s.rn = (ma.total + 1) / 2) as p50,
-			(SELECT score FROM scores s WHERE s.machine_key = ma.machine_key
-			 AND s.rn = (ma.total * 9 + 9) / 10) as p90
+			(SELECT MIN(score) FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.cw * 2 >= s.tw) as p50,
+			(SELECT MIN(score) FROM scores s WHERE s.machine_key = ma.machine_key
+			 AND s.cw * 10 >= s.tw * 9) as p90
 		FROM machine_agg ma
 		ORDER BY games DESC
 	`
@@ -361,9 +627,10 @@ func (s *SQLiteStore) GetPlayer(ctx context.Context, playerName string) (PlayerS
 		FROM players p
 		JOIN rosters r ON r.player_id = p.id
 		JOIN teams t ON t.id = r.team_id
+		JOIN seasons s ON s.id = t.season_id
 		LEFT JOIN player_iprs ipr ON ipr.name = p.name
 		WHERE p.name = ?
-		ORDER BY t.season_id DESC
+		ORDER BY s.number DESC
 		LIMIT 1
 	`, playerName).Scan(&p.Name, &p.TeamKey, &p.Team, &p.IPR)
 	if err != nil {
@@ -396,21 +663,51 @@ func (s *SQLiteStore) GetMachineNames(ctx context.Context) (map[string]string, e
 	return result, nil
 }
 
-// GetPlayerMachineStats returns stats for players on a team's current roster.
-// Stats are aggregated across all seasons, but only for players currently on
-// the team (latest season with that team key).
-// If venueKey is non-empty, filters to games played at that venue.
-// Results are ordered by P50 score descending.
-func (s *SQLiteStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string) ([]PlayerStats, error) {
+// GetMachineYears returns a map of machine key to the year the machine was
+// made, for machines whose year is known. See UpsertMachineDetails.
+func (s *SQLiteStore) GetMachineYears(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT machine_key, year FROM machine_details WHERE year > 0")
+	if err != nil {
+		return nil, fmt.Errorf("query machine years: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var year int
+		if err := rows.Scan(&key, &year); err != nil {
+			return nil, fmt.Errorf("scan machine year: %w", err)
+		}
+		result[key] = year
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate machine years: %w", err)
+	}
+
+	return result, nil
+}
+
+// playerScores returns the CTEs GetPlayerMachineStats and
+// GetPlayerMachinePercentiles select from, and their arguments. player_scores
+// has each roster player's weighted scores on the machine, and player_agg one
+// row per player. Percentile p of a player's scores is the lowest score whose
+// cumulative weight cw reaches p percent of their total weight tw.
+func (s *SQLiteStore) playerScores(teamKey, machineKey, venueKey string, seasons []int) (string, []any) {
 	query := `
-		WITH current_roster AS (
+		WITH` + statsCTEs + `,
+		current_roster AS (
 			SELECT DISTINCT p.id as player_id
 			FROM players p
 			JOIN rosters r ON r.player_id = p.id
 			JOIN teams t ON t.id = r.team_id
 			WHERE t.key = ?
 			  AND t.season_id = (
-				SELECT MAX(t2.season_id) FROM teams t2 WHERE t2.key = ?
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
 			  )
 		),
 		player_scores AS (
@@ -419,36 +716,63 @@ func (s *SQLiteStore) GetPlayerMachineStats(ctx context.Context, teamKey, machin
 				p.name,
 				COALESCE(pipr.ipr, 0) as ipr,
 				gr.score,
-				ROW_NUMBER() OVER (PARTITION BY p.id ORDER BY gr.score) as rn,
+				SUM(sw.weight) OVER (PARTITION BY p.id ORDER BY gr.score ROWS UNBOUNDED PRECEDING) as cw,
+				SUM(sw.weight) OVER (PARTITION BY p.id) as tw,
 				COUNT(*) OVER (PARTITION BY p.id) as total
-			FROM game_results gr
+			FROM adjusted_results gr
 			JOIN players p ON p.id = gr.player_id
 			LEFT JOIN player_iprs pipr ON pipr.name = p.name
 			JOIN games g ON g.id = gr.game_id
 			JOIN matches m ON m.id = g.match_id
+			JOIN season_weights sw ON sw.season_id = m.season_id
 			WHERE g.machine_key = ?
 			  AND p.id IN (SELECT player_id FROM current_roster)
 	`
-	args := []any{teamKey, teamKey, machineKey}
+	args := append(s.statsArgs(), teamKey, teamKey, machineKey)
 
 	if venueKey != "" {
 		query += " AND m.venue_id = (SELECT id FROM venues WHERE key = ?)"
 		args = append(args, venueKey)
 	}
 
+	cond, condArgs := inSeasons(seasons)
+	query += cond
+	args = append(args, condArgs...)
+
 	query += `
 		),
 		player_agg AS (
 			SELECT DISTINCT player_id, name, ipr, total
 			FROM player_scores
-		)
+		)`
+	return query, args
+}
+
+// GetPlayerMachineStats returns stats for players on a team's current roster.
+// Stats are aggregated across all seasons, but only for players currently on
+// the team (latest season with that team key). Earlier seasons are weighted
+// per WithRecencyWeight.
+// If venueKey is non-empty, filters to games played at that venue. If seasons
+// is non-empty, filters to games played in those seasons.
+// Results are ordered by P50 score descending.
+func (s *SQLiteStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]PlayerStats, error) {
+	rollups, err := s.useRollups(ctx, venueKey, seasons)
+	if err != nil {
+		return nil, err
+	}
+	if rollups {
+		return s.lookupPlayerMachineStats(ctx, teamKey, machineKey)
+	}
+
+	query, args := s.playerScores(teamKey, machineKey, venueKey, seasons)
+	query += `
 		SELECT
 			pa.name,
 			pa.total as games,
-			(SELECT score FROM player_scores ps WHERE ps.player_id = pa.player_id
-			 AND ps.rn = (pa.total + 1) / 2) as p50,
-			(SELECT score FROM player_scores ps WHERE ps.player_id = pa.player_id
-			 AND ps.rn = (pa.total * 9 + 9) / 10) as p90,
+			(SELECT MIN(score) FROM player_scores ps WHERE ps.player_id = pa.player_id
+			 AND ps.cw * 2 >= ps.tw) as p50,
+			(SELECT MIN(score) FROM player_scores ps WHERE ps.player_id = pa.player_id
+			 AND ps.cw * 10 >= ps.tw * 9) as p90,
 			pa.ipr
 		FROM player_agg pa
 		ORDER BY p50 DESC
@@ -475,3 +799,298 @@ func (s *SQLiteStore) GetPlayerMachineStats(ctx context.Context, teamKey, machin
 
 	return stats, nil
 }
+
+// GetPlayerMachinePercentiles returns each current roster player's scores on
+// a machine at the supplied percentiles, from 1 to 100, keyed by player name.
+// Each player's scores are in the same order as the percentiles. Scores are
+// weighted and filtered like GetPlayerMachineStats, but are always computed
+// from game results, since only P50 and P90 are materialized.
+func (s *SQLiteStore) GetPlayerMachinePercentiles(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error) {
+	for _, pct := range percentiles {
+		if pct < 1 || pct > 100 {
+			return nil, fmt.Errorf("percentile %d is not between 1 and 100", pct)
+		}
+	}
+
+	query, args := s.playerScores(teamKey, machineKey, venueKey, seasons)
+	query += `
+		SELECT pa.name`
+	for _, pct := range percentiles {
+		query += `,
+			(SELECT MIN(score) FROM player_scores ps WHERE ps.player_id = pa.player_id
+			 AND ps.cw * 100 >= ps.tw * ?)`
+		args = append(args, pct)
+	}
+	query += `
+		FROM player_agg pa
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query player percentiles: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	result := make(map[string][]float64)
+	for rows.Next() {
+		var name string
+		scores := make([]float64, len(percentiles))
+		dest := []any{&name}
+		for i := range scores {
+			dest = append(dest, &scores[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan player percentiles: %w", err)
+		}
+		result[name] = scores
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player percentiles: %w", err)
+	}
+
+	return result, nil
+}
+
+// PlayerMachineScore is one of a player's scores on a machine.
+type PlayerMachineScore struct {
+	MachineKey  string
+	Score       int64
+	PercentRank float64 // Fraction of league scores on the machine below this one, 0-1.
+	Date        string  // ISO date of the match. Empty if unknown.
+}
+
+// ListPlayerMachineScores returns every score a player has posted, ordered by
+// machine and then oldest first. Each score is ranked against every league
+// score on the same machine.
+func (s *SQLiteStore) ListPlayerMachineScores(ctx context.Context, playerName string) ([]PlayerMachineScore, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH ranked AS (
+			SELECT
+				gr.player_id,
+				g.machine_key,
+				gr.score,
+				s.number as season,
+				m.week,
+				g.round,
+				g.id as game_id,
+				COALESCE(m.date, '') as date,
+				PERCENT_RANK() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as pct
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			JOIN seasons s ON s.id = m.season_id
+			WHERE g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+		)
+		SELECT machine_key, score, pct, date
+		FROM ranked
+		WHERE player_id = (SELECT id FROM players WHERE name = ?)
+		ORDER BY machine_key, season, week, round, game_id
+	`, playerName)
+	if err != nil {
+		return nil, fmt.Errorf("query player machine scores: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var scores []PlayerMachineScore
+	for rows.Next() {
+		var ps PlayerMachineScore
+		if err := rows.Scan(&ps.MachineKey, &ps.Score, &ps.PercentRank, &ps.Date); err != nil {
+			return nil, fmt.Errorf("scan player machine score: %w", err)
+		}
+		scores = append(scores, ps)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player machine scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// RosterScore is one of a rostered player's scores, ranked against the league.
+type RosterScore struct {
+	PlayerName  string
+	Season      int
+	Week        int
+	PercentRank float64 // Fraction of league scores on the machine below this one, 0-1.
+}
+
+// ListTeamRosterScores returns every score posted by the players on a team's
+// current roster, for any team, ordered by player and then oldest first. Each
+// score is ranked against every league score on the same machine.
+func (s *SQLiteStore) ListTeamRosterScores(ctx context.Context, teamKey string) ([]RosterScore, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH current_roster AS (
+			SELECT DISTINCT r.player_id
+			FROM rosters r
+			JOIN teams t ON t.id = r.team_id
+			WHERE t.key = ?
+			  AND t.season_id = (
+				SELECT t2.season_id FROM teams t2
+				JOIN seasons s2 ON s2.id = t2.season_id
+				WHERE t2.key = ?
+				ORDER BY s2.number DESC LIMIT 1
+			  )
+		),
+		ranked AS (
+			SELECT
+				gr.player_id,
+				s.number as season,
+				m.week,
+				g.round,
+				g.id as game_id,
+				PERCENT_RANK() OVER (PARTITION BY g.machine_key ORDER BY gr.score) as pct
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			JOIN seasons s ON s.id = m.season_id
+			WHERE g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+		)
+		SELECT p.name, rk.season, rk.week, rk.pct
+		FROM ranked rk
+		JOIN players p ON p.id = rk.player_id
+		WHERE rk.player_id IN (SELECT player_id FROM current_roster)
+		ORDER BY p.name, rk.season, rk.week, rk.round, rk.game_id
+	`, teamKey, teamKey)
+	if err != nil {
+		return nil, fmt.Errorf("query team roster scores: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var scores []RosterScore
+	for rows.Next() {
+		var rs RosterScore
+		if err := rows.Scan(&rs.PlayerName, &rs.Season, &rs.Week, &rs.PercentRank); err != nil {
+			return nil, fmt.Errorf("scan team roster score: %w", err)
+		}
+		scores = append(scores, rs)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team roster scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// PlayerSeasonStats contains a player's stats on a machine in one season.
+type PlayerSeasonStats struct {
+	MachineKey string
+	Season     int
+	Games      int
+	P50Score   float64
+}
+
+// GetPlayerTrend returns a player's P50 on each machine in each season they
+// played it, ordered by machine and then oldest season first.
+func (s *SQLiteStore) GetPlayerTrend(ctx context.Context, playerName string) ([]PlayerSeasonStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH scores AS (
+			SELECT
+				g.machine_key,
+				s.number as season,
+				gr.score,
+				ROW_NUMBER() OVER (PARTITION BY g.machine_key, s.number ORDER BY gr.score) as rn,
+				COUNT(*) OVER (PARTITION BY g.machine_key, s.number) as total
+			FROM game_results gr
+			JOIN players p ON p.id = gr.player_id
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			JOIN seasons s ON s.id = m.season_id
+			WHERE p.name = ?
+			  AND g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+		)
+		SELECT machine_key, season, total, score
+		FROM scores
+		WHERE rn = (total + 1) / 2
+		ORDER BY machine_key, season
+	`, playerName)
+	if err != nil {
+		return nil, fmt.Errorf("query player trend: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []PlayerSeasonStats
+	for rows.Next() {
+		var ps PlayerSeasonStats
+		if err := rows.Scan(&ps.MachineKey, &ps.Season, &ps.Games, &ps.P50Score); err != nil {
+			return nil, fmt.Errorf("scan player trend: %w", err)
+		}
+		stats = append(stats, ps)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate player trend: %w", err)
+	}
+
+	return stats, nil
+}
+
+// MonthlyStats is a P50 on a machine over one calendar month.
+type MonthlyStats struct {
+	MachineKey string
+	Month      string // e.g. 2024-01.
+	Games      int
+	P50Score   float64
+}
+
+// GetMonthlyP50 returns the P50 on each machine in each month it was played,
+// ordered by machine and then oldest month first. If playerName is non-empty,
+// only that player's scores count. Otherwise every league score does. Games in
+// matches without a date are left out.
+func (s *SQLiteStore) GetMonthlyP50(ctx context.Context, playerName string) ([]MonthlyStats, error) {
+	query := `
+		WITH scores AS (
+			SELECT
+				g.machine_key,
+				SUBSTR(m.date, 1, 7) as month,
+				gr.score,
+				ROW_NUMBER() OVER (PARTITION BY g.machine_key, SUBSTR(m.date, 1, 7) ORDER BY gr.score) as rn,
+				COUNT(*) OVER (PARTITION BY g.machine_key, SUBSTR(m.date, 1, 7)) as total
+			FROM game_results gr
+			JOIN games g ON g.id = gr.game_id
+			JOIN matches m ON m.id = g.match_id
+			WHERE g.machine_key IS NOT NULL
+			  AND gr.score IS NOT NULL
+			  AND COALESCE(m.date, '') != ''
+	`
+	var args []any
+
+	if playerName != "" {
+		query += " AND gr.player_id = (SELECT id FROM players WHERE name = ?)"
+		args = append(args, playerName)
+	}
+
+	query += `
+		)
+		SELECT machine_key, month, total, score
+		FROM scores
+		WHERE rn = (total + 1) / 2
+		ORDER BY machine_key, month
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query monthly P50: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // Read-only query.
+
+	var stats []MonthlyStats
+	for rows.Next() {
+		var ms MonthlyStats
+		if err := rows.Scan(&ms.MachineKey, &ms.Month, &ms.Games, &ms.P50Score); err != nil {
+			return nil, fmt.Errorf("scan monthly P50: %w", err)
+		}
+		stats = append(stats, ms)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate monthly P50: %w", err)
+	}
+
+	return stats, nil
+}
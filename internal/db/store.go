@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// A Store stores MNP data. SQLiteStore is the only Store today. A store backed
+// by another database, such as a network database shared by several mnp serve
+// replicas, needs only implement Store to be used in its place.
+type Store interface {
+	ReadStore
+	StatsStore
+	WriteStore
+
+	// Init creates or migrates the schema.
+	Init(ctx context.Context) error
+
+	// Close releases the store's connections.
+	Close() error
+
+	// Size returns the size of the database in bytes.
+	Size(ctx context.Context) (int64, error)
+
+	// Export copies the database to a new file at path, which CheckExport
+	// accepts.
+	Export(ctx context.Context, path string) error
+
+	// QueryReadOnly runs a single ad-hoc SELECT statement.
+	QueryReadOnly(ctx context.Context, query string) (*QueryResult, error)
+}
+
+// A StatsStore computes P50 and P90 stats from game results, filtered by venue
+// and season.
+type StatsStore interface {
+	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]TeamMachineStats, error)
+	GetTeamMachineAgg(ctx context.Context, teamKey, venueKey string, seasons []int) ([]TeamMachineStats, error)
+	GetTopPlayers(ctx context.Context, teamKey, venueKey string, seasons []int) (map[string][]LikelyPlayer, error)
+	GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]PlayerMachineStats, error)
+	GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]PlayerStats, error)
+	GetPlayerMachinePercentiles(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error)
+	GetTeamSeasonMachineStats(ctx context.Context, teamKey string, season int) ([]TeamMachineStats, error)
+	GetTeamSeasonRosterMachineStats(ctx context.Context, teamKey string, season int) ([]TeamMachineStats, error)
+	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetLeagueScores(ctx context.Context) (map[string]LeagueScores, error)
+	GetPlayerTrend(ctx context.Context, playerName string) ([]PlayerSeasonStats, error)
+	GetMonthlyP50(ctx context.Context, playerName string) ([]MonthlyStats, error)
+	ListPlayerP50s(ctx context.Context) ([]PlayerP50, error)
+
+	// RebuildStats materializes unfiltered stats so the queries above can look
+	// them up rather than compute them.
+	RebuildStats(ctx context.Context) error
+}
+
+// A WriteStore loads and changes MNP data.
+type WriteStore interface { //nolint:interfacebloat // Maps 1:1 to the writes the ETL and commands perform.
+	UpsertMachine(ctx context.Context, m Machine) error
+	UpsertMachineDetails(ctx context.Context, d MachineDetails) error
+	UpsertMachineAlias(ctx context.Context, a MachineAlias) error
+	UpsertSeason(ctx context.Context, number int) (int64, error)
+	UpsertPlayer(ctx context.Context, name string) (int64, error)
+	UpsertPlayerIPR(ctx context.Context, name string, ipr int) error
+	UpsertVenue(ctx context.Context, key, name string) (int64, error)
+	UpsertVenueMachine(ctx context.Context, venueID, seasonID int64, machineKey string) error
+	ReplaceVenueMachines(ctx context.Context, venueID, seasonID int64, machineKeys []string) error
+	UpsertTeam(ctx context.Context, t Team) (int64, error)
+	UpsertRoster(ctx context.Context, playerID, teamID int64, role string) error
+	ReplaceRoster(ctx context.Context, teamID int64, playerIDs []int64, role string) error
+	UpsertMatch(ctx context.Context, m Match) (int64, error)
+	InsertGame(ctx context.Context, g Game) (int64, error)
+	InsertGameResult(ctx context.Context, r GameResult) error
+	InsertMatchLineup(ctx context.Context, l MatchLineup) error
+	DeleteMatchGames(ctx context.Context, matchID int64) error
+	LoadMatchBatch(ctx context.Context, matches []MatchGames) error
+	ReplaceExternalResults(ctx context.Context, source string, results []ExternalResult) error
+	UpsertPick(ctx context.Context, p Pick) error
+	ClaimPicker(ctx context.Context, name, token string) error
+	UpsertPrediction(ctx context.Context, p Prediction) error
+	AddGoal(ctx context.Context, g Goal) (int64, error)
+	DeleteGoal(ctx context.Context, id int64) error
+	SaveBadges(ctx context.Context, badges []Badge) (int, error)
+	SaveP50Snapshot(ctx context.Context, p50s []PlayerP50, takenAt time.Time) error
+	SetMetadata(ctx context.Context, key, value string) error
+	RecordSyncAttempt(ctx context.Context, started time.Time, d time.Duration, syncErr error) error
+	RebuildSearchIndex(ctx context.Context) error
+}
+
+// A ReadStore queries MNP data.
+type ReadStore interface { //nolint:interfacebloat // Maps 1:1 to the queries the commands and web UI perform.
+	// Sync metadata.
+	GetMetadata(ctx context.Context, key string) (string, error)
+	GetFreshness(ctx context.Context) (Freshness, error)
+	GetSyncStatus(ctx context.Context) (SyncStatus, error)
+	LoadedSeasons(ctx context.Context) (map[int]bool, error)
+	MaxSeasonNumber(ctx context.Context) (int, error)
+	ListMatchJSON(ctx context.Context, season int) ([]MatchJSON, error)
+
+	// Machines, venues, teams, and players.
+	ListMachines(ctx context.Context, search string) ([]Machine, error)
+	ListMachineSummaries(ctx context.Context, search string) ([]MachineSummary, error)
+	ListMachineKeys(ctx context.Context) (map[string]bool, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetMachineYears(ctx context.Context) (map[string]int, error)
+	ListMachineAliases(ctx context.Context, source string) (map[string]string, error)
+	ListUnmatchedMachines(ctx context.Context) ([]Machine, error)
+	ListVenues(ctx context.Context, search string) ([]Venue, error)
+	ListVenueSummaries(ctx context.Context, search string) ([]VenueSummary, error)
+	ListVenuesWithoutMachines(ctx context.Context) ([]Venue, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	GetVenueMachineHistory(ctx context.Context, venueKey string) ([]VenueSeason, error)
+	ListTeams(ctx context.Context, search string, season int) ([]TeamSummary, error)
+	GetTeamID(ctx context.Context, key string, seasonID int64) (int64, error)
+	ListPlayers(ctx context.Context, search string) ([]PlayerSummary, error)
+	ListPlayerNames(ctx context.Context) ([]string, error)
+	GetPlayer(ctx context.Context, playerName string) (PlayerSummary, error)
+	Search(ctx context.Context, query string) ([]SearchResult, error)
+
+	// Seasons, schedules, and standings.
+	ListSeasons(ctx context.Context) ([]int, error)
+	ListSchedule(ctx context.Context, after string) ([]ScheduleMatch, error)
+	GetLatestPlayedWeek(ctx context.Context) (int, error)
+	GetStandings(ctx context.Context, season int) ([]Standing, error)
+	GetStandingsThroughWeek(ctx context.Context, season, week int) ([]Standing, error)
+
+	// Teams' seasons and rosters.
+	ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error)
+	GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error)
+	GetTeamSeasonPoints(ctx context.Context, teamKey string, season int) (TeamSeasonPoints, error)
+	GetTeamAttendance(ctx context.Context, teamKey string) (map[string]Attendance, error)
+	GetTeamAbsences(ctx context.Context, teamKey string) (map[string][]int, error)
+	ListRosterRetention(ctx context.Context) ([]RosterRetention, error)
+	ListRosterEvents(ctx context.Context, teamKey string) ([]RosterEvent, error)
+	ListTeamGameCounts(ctx context.Context) ([]TeamGameCount, error)
+
+	// Matches and games.
+	ListPlayedMatches(ctx context.Context, teamKey string) ([]PlayedMatch, error)
+	ListPlayedResults(ctx context.Context, from, to string) ([]PlayedResult, error)
+	ListMatchResults(ctx context.Context, matchKey string) ([]MatchResult, error)
+	GetMatchDetail(ctx context.Context, matchKey string) (*MatchDetail, error)
+	GetSignatureWin(ctx context.Context, playerName string) (*SignatureWin, error)
+	GetGameLog(ctx context.Context, f GameLogFilter) ([]GameLogEntry, error)
+	GetPlayerGameLog(ctx context.Context, playerName string) ([]GameLogEntry, error)
+	GetLeaderboard(ctx context.Context, machineKey, venueKey string, season, top int) ([]TopScore, error)
+	ListTeamLineups(ctx context.Context, teamKey string) ([]Lineup, error)
+	ListTeamRoundMachines(ctx context.Context, teamKey string, seasons []int) ([]RoundMachine, error)
+	GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]MachinePicks, error)
+	GetDoublesPairs(ctx context.Context, teamKey string, seasons []int) ([]DoublesPair, error)
+
+	// Scores and points.
+	ListPlayerMachineScores(ctx context.Context, playerName string) ([]PlayerMachineScore, error)
+	ListTeamRosterScores(ctx context.Context, teamKey string) ([]RosterScore, error)
+	ListTeamMachineScores(ctx context.Context, teamKey string) ([]TeamMachineScore, error)
+	GetPlayerMachinePoints(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]Points, error)
+	GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]Points, error)
+	GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]HomeAwaySplit, error)
+	GetPlayerHomeAwaySplits(ctx context.Context, playerName string, seasons []int) (map[string]HomeAwaySplit, error)
+	GetExternalMachineStats(ctx context.Context, venueKey, machineKey string) ([]ExternalStats, error)
+	GetP50Snapshot(ctx context.Context) ([]PlayerP50, time.Time, error)
+
+	// Picks, predictions, goals, and badges.
+	ListPicks(ctx context.Context, picker string) ([]Pick, error)
+	ListPickOutcomes(ctx context.Context) ([]PickOutcome, error)
+	ListPredictionOutcomes(ctx context.Context) ([]PredictionOutcome, error)
+	ListPlayerGoals(ctx context.Context, playerName string) ([]Goal, error)
+	ListPlayerBadges(ctx context.Context, playerName string) ([]Badge, error)
+	ListMatchBadges(ctx context.Context, matchKey string) ([]Badge, error)
+}
@@ -0,0 +1,175 @@
+// Package digest summarizes a team's next match before match night, along with
+// how its last match went, and posts the summary to a Slack-compatible webhook.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/recap"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+// maxFocus is the most machines a digest recommends focusing on.
+const maxFocus = 3
+
+// Store is the set of queries needed for a digest.
+type Store interface {
+	scout.Store
+	matchup.Store
+	recap.Store
+	schedule.Store
+}
+
+// A Digest summarizes a team's next match.
+type Digest struct {
+	Team     string
+	Match    *db.ScheduleMatch // Nil if the team has no upcoming match.
+	Opponent string
+
+	// The opponent's strongest and weakest machines at the venue, by name.
+	Strongest []string
+	Weakest   []string
+
+	// Focus is the machines the team has its biggest edges on at the venue,
+	// biggest first. These are the machines to pick.
+	Focus []matchup.MachineMatchup
+
+	// Last recaps the team's most recently completed match. Nil if it hasn't
+	// completed one this season.
+	Last *recap.Result
+}
+
+// Analyze summarizes a team's first match on or after the supplied date. The
+// digest has no match if there isn't one, and no machines if the match has no
+// venue yet.
+func Analyze(ctx context.Context, s Store, date, team string) (*Digest, error) {
+	m, err := schedule.NextMatch(ctx, s, date, team)
+	if err != nil {
+		return nil, fmt.Errorf("find %s's next match: %w", team, err)
+	}
+	d := &Digest{Team: team, Match: m}
+
+	last, err := recap.Analyze(ctx, s, team)
+	if err != nil {
+		return nil, fmt.Errorf("recap %s's last match: %w", team, err)
+	}
+	if last.Match != nil {
+		d.Last = last
+	}
+
+	if m == nil {
+		return d, nil
+	}
+	d.Opponent = schedule.Opponent(*m, team)
+	if m.VenueKey == "" {
+		return d, nil
+	}
+
+	sr, err := scout.Analyze(ctx, s, d.Opponent, scout.AtVenue(m.VenueKey))
+	if err != nil {
+		return nil, fmt.Errorf("scout %s: %w", d.Opponent, err)
+	}
+	d.Strongest, d.Weakest = sr.Analysis.Strongest, sr.Analysis.Weakest
+
+	mr, err := matchup.Analyze(ctx, s, m.VenueKey, team, d.Opponent)
+	if err != nil {
+		return nil, fmt.Errorf("matchup %s vs %s: %w", team, d.Opponent, err)
+	}
+	// Machines are sorted by edge, the team's best first.
+	for _, mm := range mr.Machines {
+		if mm.Edge <= 0 || len(d.Focus) == maxFocus {
+			break
+		}
+		d.Focus = append(d.Focus, mm)
+	}
+	return d, nil
+}
+
+// Due returns true if the digest's match starts within the supplied lead time
+// of now, and so the digest should be posted.
+func Due(d *Digest, c schedule.Clock, now time.Time, lead time.Duration) bool {
+	if d.Match == nil {
+		return false
+	}
+	start, err := c.StartTime(d.Match.Date)
+	if err != nil {
+		return false
+	}
+	return !now.Before(start.Add(-lead)) && now.Before(start)
+}
+
+// Message formats a digest as a Slack message.
+func Message(d *Digest) string {
+	if d.Match == nil {
+		return fmt.Sprintf("%s has no upcoming matches.", d.Team) + lastMatch(d.Last)
+	}
+
+	m := d.Match
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s: %s vs %s* on %s", m.WeekName(), d.Team, d.Opponent, m.Date)
+	if m.VenueKey == "" {
+		b.WriteString(". The venue isn't known yet.")
+		return b.String() + lastMatch(d.Last)
+	}
+	fmt.Fprintf(&b, " at %s (%s)", m.Venue, m.VenueKey)
+
+	if len(d.Strongest) > 0 {
+		fmt.Fprintf(&b, "\n• %s is strongest on %s.", d.Opponent, strings.Join(d.Strongest, ", "))
+	}
+	if len(d.Weakest) > 0 {
+		fmt.Fprintf(&b, "\n• %s is weakest on %s.", d.Opponent, strings.Join(d.Weakest, ", "))
+	}
+	if len(d.Focus) == 0 {
+		fmt.Fprintf(&b, "\n• %s has no edge on any machine there.", d.Team)
+		return b.String() + lastMatch(d.Last)
+	}
+	focus := make([]string, len(d.Focus))
+	for i, mm := range d.Focus {
+		focus[i] = mm.MachineName
+		// The edge is infinite when the opponent has no data for a machine.
+		if !math.IsInf(mm.Edge, 0) && mm.Edge < 1e15 {
+			focus[i] += fmt.Sprintf(" (+%.0f%%)", mm.Edge)
+		}
+	}
+	fmt.Fprintf(&b, "\n• Focus on %s.", strings.Join(focus, ", "))
+	return b.String() + lastMatch(d.Last)
+}
+
+// lastMatch formats a recap of the team's last match as a line of a Slack
+// message, or returns an empty string if there's no recap.
+func lastMatch(r *recap.Result) string {
+	if r == nil {
+		return ""
+	}
+
+	m := r.Match
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n• Last match: %s %s @ %s %s.", m.AwayTeamKey, output.FormatPoints(r.AwayPoints), m.HomeTeamKey, output.FormatPoints(r.HomePoints))
+	if len(r.MVPs) > 0 {
+		names := make([]string, len(r.MVPs))
+		for i, p := range r.MVPs {
+			names[i] = p.Name
+		}
+		fmt.Fprintf(&b, " MVP %s with %s points.", strings.Join(names, ", "), output.FormatPoints(r.MVPs[0].Points))
+	}
+	if len(r.Predictions) > 0 {
+		fmt.Fprintf(&b, " Favorites won %d of %d predicted machines", len(r.Right()), len(r.Predictions))
+		if wrong := r.Wrong(); len(wrong) > 0 {
+			names := make([]string, len(wrong))
+			for i, p := range wrong {
+				names[i] = p.MachineName
+			}
+			fmt.Fprintf(&b, ", and lost %s", strings.Join(names, ", "))
+		}
+		b.WriteString(".")
+	}
+	return b.String()
+}
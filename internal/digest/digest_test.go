@@ -0,0 +1,283 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/recap"
+)
+
+type MockStore struct {
+	MockGetLeagueP50           func(ctx context.Context) (map[string]float64, error)
+	MockGetLeagueScores        func(ctx context.Context) (map[string]db.LeagueScores, error)
+	MockGetMachineNames        func(ctx context.Context) (map[string]string, error)
+	MockGetTeamMachineStats    func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines       func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockGetTeamAttendance      func(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+	MockListSchedule           func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+	MockGetTeamHomeAwaySplits  func(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error)
+	MockGetTeamMachinePicks    func(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error)
+	MockGetTeamMachinePoints   func(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error)
+	MockListTeamMachineScores  func(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error)
+	MockListMatchBadges        func(ctx context.Context, matchKey string) ([]db.Badge, error)
+	MockListMatchResults       func(ctx context.Context, matchKey string) ([]db.MatchResult, error)
+	MockListPlayedMatches      func(ctx context.Context, teamKey string) ([]db.PlayedMatch, error)
+	MockListPredictionOutcomes func(ctx context.Context) ([]db.PredictionOutcome, error)
+}
+
+func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
+	return m.MockGetLeagueP50(ctx)
+}
+
+func (m *MockStore) GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error) {
+	return m.MockGetLeagueScores(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return m.MockGetTeamAttendance(ctx, teamKey)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func (m *MockStore) GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error) {
+	return m.MockGetTeamHomeAwaySplits(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error) {
+	return m.MockGetTeamMachinePicks(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error) {
+	return m.MockGetTeamMachinePoints(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) ListTeamMachineScores(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error) {
+	return m.MockListTeamMachineScores(ctx, teamKey)
+}
+
+func (m *MockStore) ListMatchBadges(ctx context.Context, matchKey string) ([]db.Badge, error) {
+	return m.MockListMatchBadges(ctx, matchKey)
+}
+
+func (m *MockStore) ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error) {
+	return m.MockListMatchResults(ctx, matchKey)
+}
+
+func (m *MockStore) ListPlayedMatches(ctx context.Context, teamKey string) ([]db.PlayedMatch, error) {
+	return m.MockListPlayedMatches(ctx, teamKey)
+}
+
+func (m *MockStore) ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error) {
+	return m.MockListPredictionOutcomes(ctx)
+}
+
+func TestAnalyze(t *testing.T) {
+	craPYC := db.ScheduleMatch{Key: "mnp-23-1-CRA-PYC", Week: 1, Date: "2025-01-06", HomeTeamKey: "CRA", AwayTeamKey: "PYC", VenueKey: "STN", Venue: "Shorty's"}
+	craKNR := db.ScheduleMatch{Key: "mnp-23-1-CRA-KNR", Week: 1, Date: "2025-01-06", HomeTeamKey: "CRA", AwayTeamKey: "KNR"}
+
+	// CRA beat PYC on TAF last week, which PYC was predicted to win.
+	lastWeek := db.PlayedMatch{Key: "mnp-22-10-PYC-CRA", Week: 10, HomeTeamKey: "PYC", AwayTeamKey: "CRA"}
+	lastPlayed := map[string][]db.PlayedMatch{"CRA": {lastWeek}}
+
+	likely := func(p50 float64) []db.LikelyPlayer {
+		return []db.LikelyPlayer{{Name: "Someone", P50Score: p50}}
+	}
+	store := func(sched ...db.ScheduleMatch) *MockStore {
+		return &MockStore{
+			MockListPlayedMatches: func(_ context.Context, team string) ([]db.PlayedMatch, error) {
+				return lastPlayed[team], nil
+			},
+			MockListMatchResults: func(_ context.Context, _ string) ([]db.MatchResult, error) {
+				return []db.MatchResult{
+					{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Cam", TeamKey: "CRA", Points: 3},
+					{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Pat", TeamKey: "PYC"},
+				}, nil
+			},
+			MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+				return nil, nil
+			},
+			MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+				return []db.PredictionOutcome{{
+					Prediction: db.Prediction{MatchKey: lastWeek.Key, MachineKey: "TAF", HomeTeamKey: "PYC", AwayTeamKey: "CRA", Edge: 25, Confidence: 1},
+					AwayPoints: 3,
+				}}, nil
+			},
+			MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+				return sched, nil
+			},
+			MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+				return map[string]float64{"TAF": 50, "MM": 50, "TZ": 50, "AFM": 50}, nil
+			},
+			MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+				return nil, nil
+			},
+			MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
+				return map[string]bool{"TAF": true, "MM": true, "TZ": true, "AFM": true}, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone", "AFM": "Attack from Mars"}, nil
+			},
+			MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+				return nil, nil
+			},
+			MockGetTeamMachineStats: func(_ context.Context, team, _ string, _ []int) ([]db.TeamMachineStats, error) {
+				if team == "CRA" {
+					return []db.TeamMachineStats{
+						{MachineKey: "TAF", LikelyPlayers: likely(100)},
+						{MachineKey: "MM", LikelyPlayers: likely(60)},
+						{MachineKey: "TZ", LikelyPlayers: likely(20)},
+						{MachineKey: "AFM", LikelyPlayers: likely(55)},
+					}, nil
+				}
+				return []db.TeamMachineStats{
+					{MachineKey: "TAF", Games: 5, P50Score: 50, LikelyPlayers: likely(50)},
+					{MachineKey: "MM", Games: 5, P50Score: 40, LikelyPlayers: likely(50)},
+					{MachineKey: "TZ", Games: 5, P50Score: 80, LikelyPlayers: likely(40)},
+					{MachineKey: "AFM", Games: 5, P50Score: 45, LikelyPlayers: likely(50)},
+				}, nil
+			},
+		}
+	}
+
+	type args struct {
+		store Store
+		team  string
+	}
+	type want struct {
+		digest *Digest
+		err    error
+	}
+
+	last := &recap.Result{
+		Team:       "CRA",
+		Match:      &lastWeek,
+		AwayPoints: 3,
+		MVPs:       []recap.PlayerPoints{{Name: "Cam", TeamKey: "CRA", Points: 3}},
+		Games: []recap.Game{{
+			Round:       1,
+			MachineKey:  "TAF",
+			MachineName: "The Addams Family",
+			Home:        recap.Side{TeamKey: "PYC", Players: []string{"Pat"}},
+			Away:        recap.Side{TeamKey: "CRA", Players: []string{"Cam"}, Points: 3},
+		}},
+		Predictions: []recap.Prediction{{MachineKey: "TAF", MachineName: "The Addams Family", Favorite: "PYC", Winner: "CRA", Edge: 25, Confidence: 1}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NextMatch": {
+			reason: "The digest should scout the opponent at the venue, focus on the team's biggest edges there, and recap the team's last match.",
+			args:   args{store: store(craPYC), team: "CRA"},
+			want: want{digest: &Digest{
+				Team:      "CRA",
+				Match:     &craPYC,
+				Opponent:  "PYC",
+				Strongest: []string{"Twilight Zone", "The Addams Family", "Attack from Mars"},
+				Weakest:   []string{"Medieval Madness", "Attack from Mars", "The Addams Family"},
+				Focus: []matchup.MachineMatchup{
+					{MachineKey: "TAF", MachineName: "The Addams Family", Team1Likely: 100, Team2P50: 50, Team2Likely: 50, Edge: 100},
+					{MachineKey: "MM", MachineName: "Medieval Madness", Team1Likely: 60, Team2P50: 40, Team2Likely: 50, Edge: 20},
+					{MachineKey: "AFM", MachineName: "Attack from Mars", Team1Likely: 55, Team2P50: 45, Team2Likely: 50, Edge: 10},
+				},
+				Last: last,
+			}},
+		},
+		"NoVenue": {
+			reason: "A match without a venue can't be scouted.",
+			args:   args{store: store(craKNR), team: "CRA"},
+			want:   want{digest: &Digest{Team: "CRA", Match: &craKNR, Opponent: "KNR", Last: last}},
+		},
+		"NoMatch": {
+			reason: "A team with no upcoming or completed match should get an empty digest.",
+			args:   args{store: store(craKNR), team: "PYC"},
+			want:   want{digest: &Digest{Team: "PYC"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "2025-01-01", tc.args.team)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.digest, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	clock := schedule.Clock{Location: time.UTC, Start: 20 * time.Hour}
+	d := &Digest{Team: "CRA", Match: &db.ScheduleMatch{Date: "2025-01-06"}}
+
+	cases := map[string]struct {
+		now  time.Time
+		want bool
+	}{
+		"TooEarly":   {now: time.Date(2025, 1, 6, 13, 59, 0, 0, time.UTC), want: false},
+		"WithinLead": {now: time.Date(2025, 1, 6, 14, 0, 0, 0, time.UTC), want: true},
+		"Started":    {now: time.Date(2025, 1, 6, 20, 0, 0, 0, time.UTC), want: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Due(d, clock, tc.now, 6*time.Hour); got != tc.want {
+				t.Errorf("Due(..., %s, 6h): got %t, want %t", tc.now, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	d := &Digest{
+		Team:      "CRA",
+		Match:     &db.ScheduleMatch{Week: 1, Date: "2025-01-06", VenueKey: "STN", Venue: "Shorty's"},
+		Opponent:  "PYC",
+		Strongest: []string{"Twilight Zone"},
+		Weakest:   []string{"Medieval Madness"},
+		Focus:     []matchup.MachineMatchup{{MachineName: "The Addams Family", Edge: 100}},
+	}
+	want := "*Week 1: CRA vs PYC* on 2025-01-06 at Shorty's (STN)\n" +
+		"• PYC is strongest on Twilight Zone.\n" +
+		"• PYC is weakest on Medieval Madness.\n" +
+		"• Focus on The Addams Family (+100%)."
+	if diff := cmp.Diff(want, Message(d)); diff != "" {
+		t.Errorf("Message(...): -want, +got:\n%s", diff)
+	}
+
+	d.Last = &recap.Result{
+		Match:       &db.PlayedMatch{HomeTeamKey: "PYC", AwayTeamKey: "CRA"},
+		HomePoints:  9,
+		AwayPoints:  13.5,
+		MVPs:        []recap.PlayerPoints{{Name: "Cam", Points: 6}},
+		Predictions: []recap.Prediction{{MachineName: "The Addams Family", Favorite: "PYC", Winner: "CRA"}, {MachineName: "Twilight Zone", Favorite: "CRA", Winner: "CRA"}},
+	}
+	want += "\n• Last match: CRA 13.5 @ PYC 9. MVP Cam with 6 points. Favorites won 1 of 2 predicted machines, and lost The Addams Family."
+	if diff := cmp.Diff(want, Message(d)); diff != "" {
+		t.Errorf("Message(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,91 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// metadataPosted prefixes the sync metadata key Notify records each posted
+// digest under, e.g. digest_posted/CRA/mnp-23-1-CRA-PYC.
+const metadataPosted = "digest_posted/"
+
+// A PostedStore records which digests have been posted.
+type PostedStore interface {
+	GetMetadata(ctx context.Context, key string) (string, error)
+	SetMetadata(ctx context.Context, key, value string) error
+}
+
+// A Webhook posts digests to a Slack-compatible incoming webhook URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+
+	mu sync.Mutex // Serializes Notify, so a digest is only posted once.
+}
+
+// NewWebhook returns a Webhook that posts to the supplied URL.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts the supplied digest, unless the store records that a digest of
+// the same team's same match was already posted. Digests are checked after
+// every sync, so this keeps a match's digest from being posted again and
+// again, even across restarts. Digests without a match aren't posted.
+func (w *Webhook) Notify(ctx context.Context, s PostedStore, d *Digest) error {
+	if d.Match == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := metadataPosted + d.Team + "/" + d.Match.Key
+	posted, err := s.GetMetadata(ctx, key)
+	if err != nil {
+		return fmt.Errorf("check whether digest was posted: %w", err)
+	}
+	if posted != "" {
+		return nil
+	}
+	if err := w.Post(ctx, d); err != nil {
+		return err
+	}
+	if err := s.SetMetadata(ctx, key, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("record posted digest: %w", err)
+	}
+	return nil
+}
+
+// Post posts the supplied digest.
+func (w *Webhook) Post(ctx context.Context, d *Digest) error {
+	body, err := json.Marshal(map[string]string{"text": Message(d)})
+	if err != nil {
+		return fmt.Errorf("encode webhook message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Response body is ignored.
+
+	if rsp.StatusCode/100 != 2 {
+		return fmt.Errorf("post webhook: unexpected status %s", rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockPostedStore struct {
+	MockGetMetadata func(ctx context.Context, key string) (string, error)
+	MockSetMetadata func(ctx context.Context, key, value string) error
+}
+
+func (m *MockPostedStore) GetMetadata(ctx context.Context, key string) (string, error) {
+	return m.MockGetMetadata(ctx, key)
+}
+
+func (m *MockPostedStore) SetMetadata(ctx context.Context, key, value string) error {
+	return m.MockSetMetadata(ctx, key, value)
+}
+
+func TestNotify(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		got = append(got, body.Text)
+	}))
+	defer srv.Close()
+
+	week1 := &Digest{Team: "CRA", Opponent: "PYC", Match: &db.ScheduleMatch{Key: "mnp-23-1-CRA-PYC", Week: 1, Date: "2025-01-06"}}
+	week2 := &Digest{Team: "CRA", Opponent: "KNR", Match: &db.ScheduleMatch{Key: "mnp-23-2-KNR-CRA", Week: 2, Date: "2025-01-13"}}
+
+	metadata := map[string]string{}
+	s := &MockPostedStore{
+		MockGetMetadata: func(_ context.Context, key string) (string, error) {
+			return metadata[key], nil
+		},
+		MockSetMetadata: func(_ context.Context, key, value string) error {
+			metadata[key] = value
+			return nil
+		},
+	}
+
+	w := NewWebhook(srv.URL)
+	for _, d := range []*Digest{
+		week1,
+		week1,         // Already posted.
+		{Team: "CRA"}, // No match.
+	} {
+		if err := w.Notify(context.Background(), s, d); err != nil {
+			t.Fatalf("Notify(...): %v", err)
+		}
+	}
+
+	// A new webhook, e.g. after mnp serve restarts, remembers what was posted.
+	w = NewWebhook(srv.URL)
+	for _, d := range []*Digest{
+		week1, // Already posted.
+		week2,
+	} {
+		if err := w.Notify(context.Background(), s, d); err != nil {
+			t.Fatalf("Notify(...): %v", err)
+		}
+	}
+
+	want := []string{
+		"*Week 1: CRA vs PYC* on 2025-01-06. The venue isn't known yet.",
+		"*Week 2: CRA vs KNR* on 2025-01-13. The venue isn't known yet.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Notify(...) should post each match's digest once: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,267 @@
+package discord
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/player"
+	"github.com/negz/mnp/internal/strategy/recommend"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+// maxBodyBytes is the largest interaction the bot will read.
+const maxBodyBytes = 1 << 20
+
+// Store is the set of queries needed to answer slash commands.
+type Store interface {
+	scout.Store
+	matchup.Store
+	recommend.Store
+	player.Store
+	schedule.Store
+}
+
+// A Bot answers slash commands Discord POSTs to its interactions endpoint.
+type Bot struct {
+	store Store
+	key   ed25519.PublicKey
+	log   *slog.Logger
+	clock schedule.Clock
+	now   func() time.Time
+}
+
+// A BotOption configures a Bot.
+type BotOption func(*Bot)
+
+// WithClock sets the clock used to find teams' next matches, and thus which
+// venue to scout or compare teams at when none is given.
+func WithClock(c schedule.Clock) BotOption {
+	return func(b *Bot) {
+		b.clock = c
+	}
+}
+
+// NewBot returns a Bot that answers slash commands using the supplied store.
+// It only answers interactions signed by the supplied public key.
+func NewBot(store Store, key ed25519.PublicKey, log *slog.Logger, opts ...BotOption) *Bot {
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		log.Warn("cannot load league timezone, using UTC", "err", err)
+		clock = schedule.Clock{Location: time.UTC, Start: schedule.DefaultStart, Rollover: schedule.DefaultRollover}
+	}
+
+	b := &Bot{
+		store: store,
+		key:   key,
+		log:   log,
+		clock: clock,
+		now:   time.Now,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// ParsePublicKey parses a hex encoded Ed25519 public key, such as an
+// application's verify key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	k, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key is %d bytes, want %d", len(k), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(k), nil
+}
+
+// ServeHTTP answers an interaction. Discord rejects an interactions endpoint
+// that answers unsigned or wrongly signed requests, so those get 401
+// Unauthorized.
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "Cannot read request", http.StatusBadRequest)
+		return
+	}
+
+	sig, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+	if err != nil || !ed25519.Verify(b.key, append([]byte(r.Header.Get("X-Signature-Timestamp")), body...), sig) {
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var in Interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, "Cannot decode interaction", http.StatusBadRequest)
+		return
+	}
+
+	var rsp Response
+	switch in.Type {
+	case interactionPing:
+		rsp = Response{Type: responsePong}
+	case interactionApplicationCommand:
+		rsp = b.answer(r.Context(), in.Data)
+	default:
+		http.Error(w, "Unsupported interaction type", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rsp); err != nil {
+		b.log.Error("write interaction response", "err", err)
+	}
+}
+
+// A usageError is returned when a command can't be answered as used, for
+// example because the team has no data. It's shown to the user as is.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// answer answers a slash command. Errors are shown only to the user who used
+// the command.
+func (b *Bot) answer(ctx context.Context, d InteractionData) Response {
+	opts := make(map[string]string, len(d.Options))
+	for _, o := range d.Options {
+		opts[o.Name] = strings.TrimSpace(o.Value)
+	}
+
+	var e Embed
+	var err error
+	switch d.Name {
+	case "scout":
+		e, err = b.scout(ctx, strings.ToUpper(opts["team"]), strings.ToUpper(opts["venue"]))
+	case "matchup":
+		e, err = b.matchup(ctx, strings.ToUpper(opts["team1"]), strings.ToUpper(opts["team2"]), strings.ToUpper(opts["venue"]))
+	case "recommend":
+		e, err = b.recommend(ctx, strings.ToUpper(opts["team"]), strings.ToUpper(opts["machine"]), strings.ToUpper(opts["venue"]), strings.ToUpper(opts["vs"]))
+	case "player":
+		e, err = b.player(ctx, opts["name"])
+	default:
+		err = usageError(fmt.Sprintf("Unknown command /%s.", d.Name))
+	}
+
+	var ue usageError
+	switch {
+	case errors.As(err, &ue):
+		return message(string(ue))
+	case err != nil:
+		b.log.Error("answer slash command", "command", d.Name, "err", err)
+		return message("Something went wrong. Please try again later.")
+	}
+	e.Color = embedColor
+	return Response{Type: responseChannelMessage, Data: &ResponseData{Embeds: []Embed{e}}}
+}
+
+// message returns an ephemeral text reply.
+func message(text string) Response {
+	return Response{Type: responseChannelMessage, Data: &ResponseData{Content: text, Flags: flagEphemeral}}
+}
+
+func (b *Bot) scout(ctx context.Context, team, venue string) (Embed, error) {
+	if venue == "" {
+		m, err := schedule.NextVenue(ctx, b.store, b.clock.Today(b.now()), team, "")
+		if err != nil {
+			return Embed{}, fmt.Errorf("find %s's next venue: %w", team, err)
+		}
+		if m != nil {
+			venue = m.VenueKey
+		}
+	}
+
+	opts := []scout.Option{scout.WithPoints()}
+	if venue != "" {
+		opts = append(opts, scout.AtVenue(venue))
+	}
+	r, err := scout.Analyze(ctx, b.store, team, opts...)
+	if err != nil {
+		return Embed{}, fmt.Errorf("scout %s: %w", team, err)
+	}
+	if len(r.GlobalStats) == 0 {
+		return Embed{}, usageError(fmt.Sprintf("No data for %s.", team))
+	}
+	return scoutEmbed(r), nil
+}
+
+func (b *Bot) matchup(ctx context.Context, team1, team2, venue string) (Embed, error) {
+	if venue == "" {
+		m, err := schedule.NextVenue(ctx, b.store, b.clock.Today(b.now()), team1, team2)
+		if err != nil {
+			return Embed{}, fmt.Errorf("find %s's next venue: %w", team1, err)
+		}
+		if m == nil {
+			return Embed{}, usageError(fmt.Sprintf("No venue given, and %s has no upcoming match at a known venue.", team1))
+		}
+		venue = m.VenueKey
+	}
+
+	r, err := matchup.Analyze(ctx, b.store, venue, team1, team2)
+	if err != nil {
+		return Embed{}, fmt.Errorf("matchup %s vs %s: %w", team1, team2, err)
+	}
+	if len(r.Machines) == 0 {
+		return Embed{}, usageError(fmt.Sprintf("No machines found at %s.", venue))
+	}
+	return matchupEmbed(r), nil
+}
+
+func (b *Bot) recommend(ctx context.Context, team, machine, venue, opponent string) (Embed, error) {
+	if venue == "" {
+		m, err := schedule.NextVenue(ctx, b.store, b.clock.Today(b.now()), team, opponent)
+		if err != nil {
+			return Embed{}, fmt.Errorf("find %s's next venue: %w", team, err)
+		}
+		if m != nil {
+			venue = m.VenueKey
+		}
+	}
+
+	var opts []recommend.Option
+	if venue != "" {
+		opts = append(opts, recommend.AtVenue(venue))
+	}
+	if opponent != "" {
+		opts = append(opts, recommend.VsOpponent(opponent))
+	}
+	r, err := recommend.Analyze(ctx, b.store, team, machine, opts...)
+	if err != nil {
+		return Embed{}, fmt.Errorf("recommend %s players for %s: %w", team, machine, err)
+	}
+	if len(r.VenueStats) == 0 && len(r.GlobalStats) == 0 && len(r.OpponentStats) == 0 {
+		return Embed{}, usageError(fmt.Sprintf("No data for %s on %s.", team, machine))
+	}
+	return recommendEmbed(r), nil
+}
+
+func (b *Bot) player(ctx context.Context, name string) (Embed, error) {
+	r, err := player.Analyze(ctx, b.store, name)
+	if err != nil {
+		return Embed{}, fmt.Errorf("analyze %s: %w", name, err)
+	}
+	if len(r.Suggestions) > 0 {
+		return Embed{}, usageError(fmt.Sprintf("No player named %s. Did you mean %s?", name, strings.Join(r.Suggestions, ", or ")))
+	}
+	if len(r.GlobalStats) == 0 {
+		return Embed{}, usageError(fmt.Sprintf("No data for %s.", name))
+	}
+	return playerEmbed(r), nil
+}
@@ -0,0 +1,144 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+func TestServeHTTP(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	_, other, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	type args struct {
+		body string
+		key  ed25519.PrivateKey
+	}
+	type want struct {
+		status int
+		rsp    *Response
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Ping": {
+			reason: "A ping from Discord should be answered with a pong.",
+			args: args{
+				body: `{"type":1}`,
+				key:  priv,
+			},
+			want: want{
+				status: http.StatusOK,
+				rsp:    &Response{Type: responsePong},
+			},
+		},
+		"WrongSignature": {
+			reason: "An interaction not signed by the application's key should be rejected.",
+			args: args{
+				body: `{"type":1}`,
+				key:  other,
+			},
+			want: want{
+				status: http.StatusUnauthorized,
+			},
+		},
+		"UnknownCommand": {
+			reason: "A command the bot doesn't know should be answered only to the user who used it.",
+			args: args{
+				body: `{"type":2,"data":{"name":"nope"}}`,
+				key:  priv,
+			},
+			want: want{
+				status: http.StatusOK,
+				rsp:    &Response{Type: responseChannelMessage, Data: &ResponseData{Content: "Unknown command /nope.", Flags: flagEphemeral}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := NewBot(nil, pub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			ts := "1700000000"
+			req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(tc.args.body))
+			req.Header.Set("X-Signature-Timestamp", ts)
+			req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(tc.args.key, []byte(ts+tc.args.body))))
+			w := httptest.NewRecorder()
+			b.ServeHTTP(w, req)
+
+			if diff := cmp.Diff(tc.want.status, w.Code); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if tc.want.rsp == nil {
+				return
+			}
+			got := &Response{}
+			if err := json.NewDecoder(w.Body).Decode(got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestScoutEmbed(t *testing.T) {
+	r := &scout.Result{
+		Team:  "TTT",
+		Venue: "STN",
+		GlobalStats: []scout.MachineStats{
+			{
+				MachineName: "The Addams Family",
+				Games:       3,
+				P50Score:    40_000_000,
+				P90Score:    50_000_000,
+				LikelyPlayers: []scout.LikelyPlayer{
+					{Name: "Alice Smith"},
+					{Name: "Bob Jones"},
+				},
+			},
+			{
+				MachineName: "Twilight Zone",
+				Games:       1,
+				P50Score:    100_000_000,
+				P90Score:    100_000_000,
+			},
+		},
+		Analysis: scout.Analysis{Strongest: []string{"The Addams Family"}},
+		Points:   db.Points{Won: 5, Possible: 10},
+	}
+
+	want := Embed{
+		Title:       "Scouting TTT at STN",
+		Description: "**Strongest:** The Addams Family",
+		Fields: []EmbedField{
+			{Name: "The Addams Family", Value: "P50 40.0M · P90 50.0M\n3 games\nLikely: Alice S, Bob J", Inline: true},
+			{Name: "Twilight Zone", Value: "P50 100.0M · P90 100.0M\n1 game", Inline: true},
+		},
+		Footer: &EmbedFooter{Text: "Points won: 50% overall."},
+	}
+	if diff := cmp.Diff(want, scoutEmbed(r)); diff != "" {
+		t.Errorf("scoutEmbed(...): -want, +got:\n%s", diff)
+	}
+}
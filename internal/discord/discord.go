@@ -0,0 +1,223 @@
+// Package discord implements a Discord bot that answers MNP slash commands.
+//
+// The bot doesn't hold a gateway connection open. Discord instead POSTs each
+// slash command to the bot's interactions endpoint URL, signed with the
+// application's public key, and the bot replies with embeds.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultAPIURL is Discord's REST API.
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// Interaction types.
+const (
+	interactionPing               = 1
+	interactionApplicationCommand = 2
+)
+
+// Interaction response types.
+const (
+	responsePong           = 1
+	responseChannelMessage = 4
+)
+
+// Command option types.
+const (
+	optionString = 3
+)
+
+// flagEphemeral makes a message visible only to the user who sent the command.
+const flagEphemeral = 1 << 6
+
+// An Interaction is a slash command, or a ping from Discord checking that the
+// interactions endpoint works.
+type Interaction struct {
+	Type int             `json:"type"`
+	Data InteractionData `json:"data"`
+}
+
+// InteractionData is the slash command that was used.
+type InteractionData struct {
+	Name    string              `json:"name"`
+	Options []InteractionOption `json:"options,omitempty"`
+}
+
+// An InteractionOption is a slash command option the user filled in.
+type InteractionOption struct {
+	Name  string `json:"name"`
+	Type  int    `json:"type"`
+	Value string `json:"value"`
+}
+
+// A Response replies to an Interaction.
+type Response struct {
+	Type int           `json:"type"`
+	Data *ResponseData `json:"data,omitempty"`
+}
+
+// ResponseData is the message sent in reply to a slash command.
+type ResponseData struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+	Flags   int     `json:"flags,omitempty"`
+}
+
+// An Embed is a rich message block.
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+// An EmbedField is a titled block of text within an Embed.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// An EmbedFooter is small text at the bottom of an Embed.
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// A Command is a slash command the bot registers with Discord.
+type Command struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Options     []CommandOption `json:"options,omitempty"`
+}
+
+// A CommandOption is an option a slash command takes.
+type CommandOption struct {
+	Type        int    `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Commands returns the slash commands the bot answers.
+func Commands() []Command {
+	team := func(name, desc string) CommandOption {
+		return CommandOption{Type: optionString, Name: name, Description: desc, Required: true}
+	}
+	venue := CommandOption{Type: optionString, Name: "venue", Description: "Venue key (e.g. STN). Defaults to the venue of the team's next match."}
+	return []Command{
+		{
+			Name:        "scout",
+			Description: "Scout a team's strengths and weaknesses.",
+			Options:     []CommandOption{team("team", "Team key (e.g. CRA)."), venue},
+		},
+		{
+			Name:        "matchup",
+			Description: "Compare two teams head-to-head at a venue.",
+			Options:     []CommandOption{team("team1", "Team key (e.g. CRA)."), team("team2", "Opponent's team key (e.g. PYC)."), venue},
+		},
+		{
+			Name:        "recommend",
+			Description: "Recommend players for a machine.",
+			Options: []CommandOption{
+				team("team", "Team key (e.g. CRA)."),
+				{Type: optionString, Name: "machine", Description: "Machine key (e.g. TAF).", Required: true},
+				venue,
+				{Type: optionString, Name: "vs", Description: "Compare against an opponent's players (e.g. PYC)."},
+			},
+		},
+		{
+			Name:        "player",
+			Description: "Show a player's stats across machines.",
+			Options:     []CommandOption{{Type: optionString, Name: "name", Description: "Player name.", Required: true}},
+		},
+	}
+}
+
+// A Client calls Discord's REST API as a bot.
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewClient returns a Client that authenticates with the supplied bot token.
+func NewClient(url, token string) *Client {
+	return &Client{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// An Application is the Discord application a bot belongs to.
+type Application struct {
+	ID string `json:"id"`
+
+	// VerifyKey is the hex encoded Ed25519 public key Discord signs
+	// interactions with.
+	VerifyKey string `json:"verify_key"`
+}
+
+// Application returns the application the bot token belongs to.
+func (c *Client) Application(ctx context.Context) (*Application, error) {
+	a := &Application{}
+	if err := c.do(ctx, http.MethodGet, "/applications/@me", nil, a); err != nil {
+		return nil, fmt.Errorf("get application: %w", err)
+	}
+	return a, nil
+}
+
+// RegisterCommands registers the supplied global slash commands, replacing any
+// registered before.
+func (c *Client) RegisterCommands(ctx context.Context, appID string, cmds []Command) error {
+	if err := c.do(ctx, http.MethodPut, "/applications/"+appID+"/commands", cmds, nil); err != nil {
+		return fmt.Errorf("register commands: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bot "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Nothing useful to do if closing fails.
+
+	if rsp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", rsp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	var registered []Command
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bot s3cr3t" {
+			t.Errorf("Authorization: got %q, want %q", got, "Bot s3cr3t")
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /applications/@me":
+			w.Write([]byte(`{"id":"123","verify_key":"abc"}`)) //nolint:errcheck // Test server.
+		case "PUT /applications/123/commands":
+			if err := json.NewDecoder(r.Body).Decode(&registered); err != nil {
+				t.Errorf("decode commands: %v", err)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "s3cr3t")
+	ctx := context.Background()
+
+	app, err := c.Application(ctx)
+	if err != nil {
+		t.Fatalf("Application(...): %v", err)
+	}
+	if diff := cmp.Diff(&Application{ID: "123", VerifyKey: "abc"}, app); diff != "" {
+		t.Errorf("Application(...): -want, +got:\n%s", diff)
+	}
+
+	if err := c.RegisterCommands(ctx, app.ID, Commands()); err != nil {
+		t.Fatalf("RegisterCommands(...): %v", err)
+	}
+	if diff := cmp.Diff(Commands(), registered); diff != "" {
+		t.Errorf("RegisterCommands(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,215 @@
+package discord
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/player"
+	"github.com/negz/mnp/internal/strategy/recommend"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+// embedColor is the stripe down the side of the bot's embeds.
+const embedColor = 0x1095c1
+
+// maxFields is the most machines an embed lists. Discord allows 25 fields, but
+// a phone screen fits far fewer.
+const maxFields = 10
+
+// maxPlayers is the most players a recommend embed lists for each team.
+const maxPlayers = 8
+
+func scoutEmbed(r *scout.Result) Embed {
+	e := Embed{Title: "Scouting " + r.Team}
+	if r.Venue != "" {
+		e.Title += " at " + r.Venue
+	}
+	e.Description = strengths(r.Analysis.Strongest, r.Analysis.Weakest)
+
+	for _, s := range r.GlobalStats[:min(len(r.GlobalStats), maxFields)] {
+		v := fmt.Sprintf("P50 %s · P90 %s\n%s", output.FormatP50(s.P50Score, s.LeagueP50), output.FormatScore(s.P90Score), games(s.Games))
+		if len(s.LikelyPlayers) > 0 {
+			names := make([]string, len(s.LikelyPlayers))
+			for i, p := range s.LikelyPlayers {
+				names[i] = output.ShortPlayerName(p.Name)
+			}
+			v += "\nLikely: " + strings.Join(names, ", ")
+		}
+		e.Fields = append(e.Fields, EmbedField{Name: s.MachineName, Value: v, Inline: true})
+	}
+
+	var footer []string
+	if r.Points.Possible > 0 {
+		footer = append(footer, "Points won: "+output.FormatEfficiency(r.Points.Won, r.Points.Possible)+" overall.")
+	}
+	if n := len(r.GlobalStats); n > maxFields {
+		footer = append(footer, fmt.Sprintf("Most played %d of %d machines.", maxFields, n))
+	}
+	if len(footer) > 0 {
+		e.Footer = &EmbedFooter{Text: strings.Join(footer, " ")}
+	}
+	return e
+}
+
+func matchupEmbed(r *matchup.Result) Embed {
+	e := Embed{Title: fmt.Sprintf("%s vs %s at %s", r.Team1, r.Team2, r.Venue)}
+
+	var lines []string
+	a := r.Analysis
+	if len(a.Team1Advantages) > 0 {
+		lines = append(lines, fmt.Sprintf("**%s advantages:** %s", r.Team1, strings.Join(a.Team1Advantages, ", ")))
+	}
+	if len(a.Team2Advantages) > 0 {
+		lines = append(lines, fmt.Sprintf("**%s advantages:** %s", r.Team2, strings.Join(a.Team2Advantages, ", ")))
+	}
+	if len(a.Contested) > 0 {
+		lines = append(lines, "**Contested:** "+strings.Join(a.Contested, ", "))
+	}
+	e.Description = strings.Join(lines, "\n")
+
+	for _, m := range r.Machines[:min(len(r.Machines), maxFields)] {
+		v := fmt.Sprintf("%s %s · %s %s\n%s", r.Team1, score(m.Team1P50), r.Team2, score(m.Team2P50), edge(m, r.Team1, r.Team2))
+		e.Fields = append(e.Fields, EmbedField{Name: m.MachineName, Value: v, Inline: true})
+	}
+
+	if n := len(r.Machines); n > maxFields {
+		e.Footer = &EmbedFooter{Text: fmt.Sprintf("Biggest edges on %d of %d machines.", maxFields, n)}
+	}
+	return e
+}
+
+func recommendEmbed(r *recommend.Result) Embed {
+	e := Embed{Title: r.Team + " on " + r.Machine}
+	if r.Venue != "" {
+		e.Title += " at " + r.Venue
+	}
+	if r.Opponent != "" {
+		e.Title += " vs " + r.Opponent
+	}
+
+	// Venue stats only cover games at the venue. Fall back to every venue's
+	// when the team hasn't played the machine there.
+	stats, where := r.VenueStats, "At "+r.Venue
+	if len(stats) == 0 {
+		stats, where = r.GlobalStats, "Everywhere"
+	}
+	e.Fields = append(e.Fields, EmbedField{Name: r.Team + " · " + where, Value: players(stats)})
+	if r.Opponent != "" {
+		e.Fields = append(e.Fields, EmbedField{Name: r.Opponent + " · Likely players", Value: players(r.OpponentStats)})
+	}
+
+	if a := r.Assessment; a != nil {
+		e.Description = assessment(r)
+	}
+	return e
+}
+
+func playerEmbed(r *player.Result) Embed {
+	e := Embed{Title: r.Name}
+	var lines []string
+	if r.Team != nil {
+		lines = append(lines, fmt.Sprintf("Plays for %s (%s).", r.Team.Name, r.Team.Key))
+	}
+	if s := strengths(r.Analysis.Strongest, r.Analysis.Weakest); s != "" {
+		lines = append(lines, s)
+	}
+	e.Description = strings.Join(lines, "\n")
+
+	for _, s := range r.GlobalStats[:min(len(r.GlobalStats), maxFields)] {
+		v := fmt.Sprintf("P50 %s · P90 %s\n%s", output.FormatP50(s.P50Score, s.LeagueP50), output.FormatScore(s.P90Score), games(s.Games))
+		e.Fields = append(e.Fields, EmbedField{Name: s.MachineName, Value: v, Inline: true})
+	}
+
+	var footer []string
+	if r.IPR > 0 {
+		footer = append(footer, "IPR "+output.FormatIPR(r.IPR)+".")
+	}
+	if n := len(r.GlobalStats); n > maxFields {
+		footer = append(footer, fmt.Sprintf("Most played %d of %d machines.", maxFields, n))
+	}
+	if len(footer) > 0 {
+		e.Footer = &EmbedFooter{Text: strings.Join(footer, " ")}
+	}
+	return e
+}
+
+func strengths(strongest, weakest []string) string {
+	var lines []string
+	if len(strongest) > 0 {
+		lines = append(lines, "**Strongest:** "+strings.Join(strongest, ", "))
+	}
+	if len(weakest) > 0 {
+		lines = append(lines, "**Weakest:** "+strings.Join(weakest, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// players lists players' stats on a machine, one per line.
+func players(stats []recommend.PlayerStats) string {
+	if len(stats) == 0 {
+		return "No data."
+	}
+	lines := make([]string, 0, maxPlayers)
+	for _, s := range stats[:min(len(stats), maxPlayers)] {
+		lines = append(lines, fmt.Sprintf("%s: P50 %s · %s", s.Name, output.FormatP50(s.P50Score, s.LeagueP50), games(s.Games)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func assessment(r *recommend.Result) string {
+	a := r.Assessment
+	switch a.Verdict {
+	case recommend.VerdictStrong:
+		return fmt.Sprintf("%s outscores %s's best (%s) by ~%s P50. Strong pick.", a.OurBest, r.Opponent, a.TheirBest, output.FormatScore(a.Diff))
+	case recommend.VerdictWeak:
+		return fmt.Sprintf("%s's best (%s) outscores %s by ~%s P50. Weak pick.", r.Opponent, a.TheirBest, a.OurBest, output.FormatScore(-a.Diff))
+	case recommend.VerdictContested:
+		return fmt.Sprintf("%s and %s's best (%s) are roughly even. Contested.", a.OurBest, r.Opponent, a.TheirBest)
+	}
+	return ""
+}
+
+func games(n int) string {
+	if n == 1 {
+		return "1 game"
+	}
+	return fmt.Sprintf("%d games", n)
+}
+
+func score(s float64) string {
+	if s == 0 {
+		return "-"
+	}
+	return output.FormatScore(s)
+}
+
+// edge describes which team a machine favors, and by how much.
+func edge(m matchup.MachineMatchup, team1, team2 string) string {
+	if math.IsInf(m.Edge, 0) || math.Abs(m.Edge) > 1e15 {
+		if m.Edge > 0 {
+			return "Edge: " + team1
+		}
+		return "Edge: " + team2
+	}
+	rounded := int(math.Round(m.Edge))
+	var conf string
+	switch m.Confidence {
+	case matchup.ConfidenceHigh:
+		conf = "high confidence"
+	case matchup.ConfidenceMedium:
+		conf = "medium confidence"
+	case matchup.ConfidenceLow:
+		conf = "low confidence"
+	}
+	switch {
+	case rounded > 0:
+		return fmt.Sprintf("Edge: %s %d%% (%s)", team1, rounded, conf)
+	case rounded < 0:
+		return fmt.Sprintf("Edge: %s %d%% (%s)", team2, -rounded, conf)
+	default:
+		return "Even"
+	}
+}
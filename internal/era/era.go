@@ -0,0 +1,40 @@
+// Package era groups pinball machines by the era of technology they were made
+// in, which shapes how they play: electromechanical machines, early solid state
+// machines, dot matrix display machines, and modern LCD machines.
+package era
+
+// An Era is a span of years in which machines were built with similar
+// technology.
+type Era string
+
+// Eras, oldest first. The years are approximate, since manufacturers adopted
+// each technology at different times.
+const (
+	Unknown Era = ""
+	EM      Era = "EM"  // Electromechanical, before 1978.
+	SS      Era = "SS"  // Solid state with segmented displays, 1978 to 1990.
+	DMD     Era = "DMD" // Dot matrix displays, 1991 to 2014.
+	LCD     Era = "LCD" // LCD screens, 2015 onwards.
+)
+
+// All returns every known era, oldest first.
+func All() []Era {
+	return []Era{EM, SS, DMD, LCD}
+}
+
+// Of returns the era a machine made in the supplied year belongs to, or
+// Unknown if the year is zero.
+func Of(year int) Era {
+	switch {
+	case year <= 0:
+		return Unknown
+	case year < 1978:
+		return EM
+	case year < 1991:
+		return SS
+	case year < 2015:
+		return DMD
+	default:
+		return LCD
+	}
+}
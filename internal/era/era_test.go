@@ -0,0 +1,50 @@
+package era
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOf(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		year   int
+		want   Era
+	}{
+		"Unknown": {
+			reason: "A machine without a year should have no era.",
+			year:   0,
+			want:   Unknown,
+		},
+		"EM": {
+			reason: "A machine made before 1978 should be electromechanical.",
+			year:   1977,
+			want:   EM,
+		},
+		"SS": {
+			reason: "A machine made from 1978 should be solid state.",
+			year:   1978,
+			want:   SS,
+		},
+		"DMD": {
+			reason: "A machine made from 1991 should have a dot matrix display.",
+			year:   1992,
+			want:   DMD,
+		},
+		"LCD": {
+			reason: "A machine made from 2015 should have an LCD.",
+			year:   2021,
+			want:   LCD,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Of(tc.year)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nOf(%d): -want, +got:\n%s", tc.reason, tc.year, diff)
+			}
+		})
+	}
+}
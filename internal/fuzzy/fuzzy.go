@@ -0,0 +1,84 @@
+// Package fuzzy matches names that are spelled slightly differently, such as
+// a player's name typed with the wrong capitalization or a typo.
+package fuzzy
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Fold returns s lowercased, with leading and trailing whitespace removed and
+// runs of whitespace collapsed to a single space.
+func Fold(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Distance returns the Levenshtein distance between a and b: how many single
+// character insertions, deletions, or substitutions turn one into the other.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Only the previous row of the edit matrix is needed to compute the next.
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ra {
+		cur[0] = i + 1
+		for j := range rb {
+			sub := prev[j]
+			if ra[i] != rb[j] {
+				sub++
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, sub)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Find looks for s in candidates. It returns the candidate that's the same as
+// s ignoring case and whitespace, if there is one. Otherwise it returns up to
+// n candidates that are a few edits away from s, closest first, as
+// suggestions. Longer names tolerate more edits: one for every three
+// characters.
+func Find(s string, candidates []string, n int) (string, []string) {
+	folded := Fold(s)
+	if folded == "" {
+		return "", nil
+	}
+
+	// Prefer an exact match to one that only differs in case.
+	if slices.Contains(candidates, s) {
+		return s, nil
+	}
+
+	limit := max(1, len([]rune(folded))/3)
+
+	type match struct {
+		name     string
+		distance int
+	}
+	var near []match
+	for _, c := range candidates {
+		d := Distance(folded, Fold(c))
+		if d == 0 {
+			return c, nil
+		}
+		if d <= limit {
+			near = append(near, match{name: c, distance: d})
+		}
+	}
+
+	slices.SortFunc(near, func(a, b match) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.name, b.name))
+	})
+
+	var suggestions []string
+	for _, m := range near[:min(n, len(near))] {
+		suggestions = append(suggestions, m.name)
+	}
+	return "", suggestions
+}
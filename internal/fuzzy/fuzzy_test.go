@@ -0,0 +1,133 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDistance(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a      string
+		b      string
+		want   int
+	}{
+		"Same": {
+			reason: "Identical strings should be zero edits apart.",
+			a:      "jay ostby",
+			b:      "jay ostby",
+			want:   0,
+		},
+		"Empty": {
+			reason: "A string should be as many edits from the empty string as it has characters.",
+			a:      "",
+			b:      "bob",
+			want:   3,
+		},
+		"Substitution": {
+			reason: "Changing one character should be one edit.",
+			a:      "jay ostby",
+			b:      "jay ostbe",
+			want:   1,
+		},
+		"Transposition": {
+			reason: "Swapping adjacent characters should be two edits.",
+			a:      "jay ostby",
+			b:      "jay osbty",
+			want:   2,
+		},
+		"InsertionAndDeletion": {
+			reason: "Dropping one character and adding another should be two edits.",
+			a:      "kitten",
+			b:      "sitting",
+			want:   3,
+		},
+		"Unicode": {
+			reason: "Distance should count characters, not bytes.",
+			a:      "zoë",
+			b:      "zoe",
+			want:   1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Distance(tc.a, tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDistance(%q, %q): -want, +got:\n%s", tc.reason, tc.a, tc.b, diff)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	candidates := []string{"Jay Ostby", "Jay Ostbye", "Jane Ostby", "Bob", "Rob"}
+
+	type want struct {
+		match       string
+		suggestions []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		n      int
+		want   want
+	}{
+		"Exact": {
+			reason: "A name spelled exactly as a candidate should match it.",
+			s:      "Jay Ostby",
+			n:      3,
+			want:   want{match: "Jay Ostby"},
+		},
+		"IgnoreCaseAndSpace": {
+			reason: "A name that differs from a candidate only in case and whitespace should match it.",
+			s:      "  jay   OSTBY ",
+			n:      3,
+			want:   want{match: "Jay Ostby"},
+		},
+		"Typo": {
+			reason: "A misspelled name should suggest the closest candidates, closest first.",
+			s:      "jay osbty",
+			n:      3,
+			want:   want{suggestions: []string{"Jay Ostby", "Jay Ostbye"}},
+		},
+		"ShortName": {
+			reason: "Short names should tolerate one edit, and suggestions at the same distance should be in alphabetical order.",
+			s:      "Tob",
+			n:      3,
+			want:   want{suggestions: []string{"Bob", "Rob"}},
+		},
+		"Limit": {
+			reason: "No more suggestions than asked for should be returned.",
+			s:      "Tob",
+			n:      1,
+			want:   want{suggestions: []string{"Bob"}},
+		},
+		"NoneClose": {
+			reason: "A name that isn't close to any candidate should have no suggestions.",
+			s:      "Alice",
+			n:      3,
+			want:   want{},
+		},
+		"Blank": {
+			reason: "A blank name should match nothing.",
+			s:      " ",
+			n:      3,
+			want:   want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			match, suggestions := Find(tc.s, candidates, tc.n)
+			if diff := cmp.Diff(tc.want.match, match); diff != "" {
+				t.Errorf("\n%s\nFind(%q): -want match, +got match:\n%s", tc.reason, tc.s, diff)
+			}
+			if diff := cmp.Diff(tc.want.suggestions, suggestions); diff != "" {
+				t.Errorf("\n%s\nFind(%q): -want suggestions, +got suggestions:\n%s", tc.reason, tc.s, diff)
+			}
+		})
+	}
+}
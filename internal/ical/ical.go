@@ -0,0 +1,103 @@
+// Package ical writes iCalendar (RFC 5545) files, for subscribing to match
+// schedules from calendar apps.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of iCalendar files.
+const ContentType = "text/calendar; charset=utf-8"
+
+// timeLayout is the layout of UTC date-times in iCalendar files.
+const timeLayout = "20060102T150405Z"
+
+// maxLineOctets is the longest a line may be before it must be folded.
+const maxLineOctets = 75
+
+// A Calendar is a named set of events.
+type Calendar struct {
+	Name   string
+	Stamp  time.Time // When the calendar was generated.
+	Events []Event
+}
+
+// An Event is a calendar event.
+type Event struct {
+	UID         string // Unique across every calendar, and stable between updates.
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// Write writes the calendar to w.
+func Write(w io.Writer, c Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//negz//mnp//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", c.Stamp.UTC().Format(timeLayout))
+		line("DTSTART", e.Start.UTC().Format(timeLayout))
+		line("DTEND", e.End.UTC().Format(timeLayout))
+		line("SUMMARY", escape(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+	return nil
+}
+
+// escape escapes text values per RFC 5545 section 3.3.11.
+var escape = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+).Replace
+
+// writeFolded writes a content line, folding it onto continuation lines that
+// start with a space so no line is longer than 75 octets. It never splits a
+// UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.WriteString(s[:i] + "\r\n ") //nolint:errcheck // Checked on flush.
+		s = s[i:]
+		// Continuation lines' leading space counts toward their length.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s + "\r\n") //nolint:errcheck // Checked on flush.
+}
@@ -0,0 +1,104 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	stamp := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	type want struct {
+		lines []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		cal    Calendar
+		want   want
+	}{
+		"Event": {
+			reason: "Events should be written in UTC, with text escaped and empty properties left out.",
+			cal: Calendar{
+				Name:  "TTT matches",
+				Stamp: stamp,
+				Events: []Event{{
+					UID:     "mnp-23-1-TTT-KNR@mnp",
+					Summary: "TTT vs KNR; week 1, at home",
+					Start:   time.Date(2025, 1, 6, 19, 30, 0, 0, la),
+					End:     time.Date(2025, 1, 6, 23, 0, 0, 0, la),
+				}},
+			},
+			want: want{lines: []string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"PRODID:-//negz//mnp//EN",
+				"CALSCALE:GREGORIAN",
+				"METHOD:PUBLISH",
+				"X-WR-CALNAME:TTT matches",
+				"BEGIN:VEVENT",
+				"UID:mnp-23-1-TTT-KNR@mnp",
+				"DTSTAMP:20250101T120000Z",
+				"DTSTART:20250107T033000Z",
+				"DTEND:20250107T070000Z",
+				`SUMMARY:TTT vs KNR\; week 1\, at home`,
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}},
+		},
+		"Folded": {
+			reason: "Lines longer than 75 octets should be folded without splitting a UTF-8 sequence.",
+			cal: Calendar{
+				Stamp: stamp,
+				Events: []Event{{
+					UID:         "x",
+					Summary:     "x",
+					Description: strings.Repeat("a", 62) + "é" + strings.Repeat("b", 80),
+					Start:       stamp,
+					End:         stamp,
+				}},
+			},
+			want: want{lines: []string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"PRODID:-//negz//mnp//EN",
+				"CALSCALE:GREGORIAN",
+				"METHOD:PUBLISH",
+				"BEGIN:VEVENT",
+				"UID:x",
+				"DTSTAMP:20250101T120000Z",
+				"DTSTART:20250101T120000Z",
+				"DTEND:20250101T120000Z",
+				"SUMMARY:x",
+				// "DESCRIPTION:" is 12 octets, so the first line ends
+				// before the two octet é that would make it 76.
+				"DESCRIPTION:" + strings.Repeat("a", 62),
+				" é" + strings.Repeat("b", 72),
+				" " + strings.Repeat("b", 8),
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tc.cal); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+			if diff := cmp.Diff(tc.want.lines, got); diff != "" {
+				t.Errorf("\n%s\nWrite(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
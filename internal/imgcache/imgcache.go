@@ -0,0 +1,240 @@
+// Package imgcache fetches remote images and caches them on disk.
+package imgcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when the source has no image for a key.
+var ErrNotFound = errors.New("image not found")
+
+const (
+	// DefaultMaxBytes is the default total size of the on-disk cache.
+	DefaultMaxBytes = 50 << 20
+
+	// maxImageBytes is the largest single image the cache will fetch.
+	maxImageBytes = 2 << 20
+
+	// missTTL is how long a missing image is remembered before the source is
+	// asked again.
+	missTTL = time.Hour
+)
+
+// An Image is a cached image.
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithMaxBytes sets the maximum total size of cached images. The least
+// recently used images are evicted when the cache grows past this size.
+func WithMaxBytes(n int64) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to fetch images.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Cache) {
+		c.client = hc
+	}
+}
+
+// A Cache fetches images from a URL template and caches them on disk.
+type Cache struct {
+	dir         string
+	urlTemplate string
+	maxBytes    int64
+	client      *http.Client
+
+	mu     sync.Mutex // Protects everything below.
+	misses map[string]time.Time
+}
+
+// New returns a Cache that stores images in dir. The urlTemplate is the source
+// URL for each image, with {key} replaced by the path-escaped image key (e.g.
+// https://example.org/backglass/{key}.jpg).
+func New(dir, urlTemplate string, opts ...Option) *Cache {
+	c := &Cache{
+		dir:         dir,
+		urlTemplate: urlTemplate,
+		maxBytes:    DefaultMaxBytes,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		misses:      make(map[string]time.Time),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Get returns the image for a key, fetching it from the source if it isn't
+// already cached.
+func (c *Cache) Get(ctx context.Context, key string) (Image, error) {
+	path := filepath.Join(c.dir, url.PathEscape(key))
+
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // Path is escaped and rooted in the cache dir.
+		now := time.Now()
+		_ = os.Chtimes(path, now, now) // Best effort LRU bookkeeping.
+		return Image{Data: data, ContentType: http.DetectContentType(data)}, nil
+	}
+
+	c.mu.Lock()
+	missed, ok := c.misses[key]
+	c.mu.Unlock()
+	if ok && time.Since(missed) < missTTL {
+		return Image{}, ErrNotFound
+	}
+
+	img, err := c.fetch(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		c.miss(key, time.Now())
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	if err := c.store(path, img.Data); err != nil {
+		return Image{}, err
+	}
+	return img, nil
+}
+
+// miss remembers that the source has no image for a key, and forgets misses
+// old enough to be asked about again so they don't accumulate.
+func (c *Cache) miss(key string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, missed := range c.misses {
+		if now.Sub(missed) >= missTTL {
+			delete(c.misses, k)
+		}
+	}
+	c.misses[key] = now
+}
+
+func (c *Cache) fetch(ctx context.Context, key string) (Image, error) {
+	u := strings.ReplaceAll(c.urlTemplate, "{key}", url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Image{}, fmt.Errorf("create request: %w", err)
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return Image{}, fmt.Errorf("fetch image: %w", err)
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Read-only response.
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return Image{}, ErrNotFound
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return Image{}, fmt.Errorf("fetch image: unexpected status %s", rsp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxImageBytes+1))
+	if err != nil {
+		return Image{}, fmt.Errorf("read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return Image{}, fmt.Errorf("image is larger than %d bytes", maxImageBytes)
+	}
+
+	ct := http.DetectContentType(data)
+	if !strings.HasPrefix(ct, "image/") {
+		return Image{}, fmt.Errorf("unexpected content type %s", ct)
+	}
+
+	return Image{Data: data, ContentType: ct}, nil
+}
+
+// store writes an image to the cache, then evicts the least recently used
+// images until the cache fits within its size limit.
+func (c *Cache) store(path string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o750); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()           //nolint:errcheck // Already returning an error.
+		os.Remove(tmp.Name()) //nolint:errcheck // Already returning an error.
+		return fmt.Errorf("write image: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck // Already returning an error.
+		return fmt.Errorf("close image: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename image: %w", err)
+	}
+
+	return c.evict()
+}
+
+func (c *Cache) evict() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type entry struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+
+	var entries []entry
+	var total int64
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), mod: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan cache directory: %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		return a.mod.Compare(b.mod)
+	})
+
+	for _, e := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("evict %s: %w", filepath.Base(e.path), err)
+		}
+		total -= e.size
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package imgcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// png is the smallest prefix http.DetectContentType recognizes as a PNG.
+var png = []byte("\x89PNG\r\n\x1a\n0000") //nolint:gochecknoglobals // Test fixture.
+
+func TestGet(t *testing.T) {
+	type args struct {
+		key string
+	}
+	type want struct {
+		img     Image
+		err     error
+		fetches int
+	}
+
+	cases := map[string]struct {
+		reason string
+		cached map[string][]byte
+		source map[string][]byte
+		args   args
+		want   want
+	}{
+		"Cached": {
+			reason: "An image already on disk should be served without contacting the source.",
+			cached: map[string][]byte{"TAF": png},
+			args:   args{key: "TAF"},
+			want:   want{img: Image{Data: png, ContentType: "image/png"}},
+		},
+		"Fetched": {
+			reason: "An image not on disk should be fetched from the source.",
+			source: map[string][]byte{"TAF": png},
+			args:   args{key: "TAF"},
+			want:   want{img: Image{Data: png, ContentType: "image/png"}, fetches: 1},
+		},
+		"NotFound": {
+			reason: "A key the source doesn't know should return ErrNotFound.",
+			args:   args{key: "TAF"},
+			want:   want{err: ErrNotFound, fetches: 1},
+		},
+		"NotAnImage": {
+			reason: "A source response that isn't an image should be rejected.",
+			source: map[string][]byte{"TAF": []byte("<html>nope</html>")},
+			args:   args{key: "TAF"},
+			want:   want{err: cmpopts.AnyError, fetches: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetches := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetches++
+				data, ok := tc.source[filepath.Base(r.URL.Path)]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write(data)
+			}))
+			defer srv.Close()
+
+			dir := t.TempDir()
+			for k, v := range tc.cached {
+				if err := os.WriteFile(filepath.Join(dir, k), v, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c := New(dir, srv.URL+"/{key}")
+			got, err := c.Get(context.Background(), tc.args.key)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.img, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.fetches, fetches); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want fetches, +got fetches:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetRemembersMisses(t *testing.T) {
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	c := New(t.TempDir(), srv.URL+"/{key}")
+	for range 3 {
+		if _, err := c.Get(context.Background(), "TAF"); err == nil {
+			t.Fatal("Get(...): expected error")
+		}
+	}
+
+	if diff := cmp.Diff(1, fetches); diff != "" {
+		t.Errorf("Get(...) should only ask the source once for a missing image: -want fetches, +got fetches:\n%s", diff)
+	}
+}
+
+func TestMissForgetsExpired(t *testing.T) {
+	c := New(t.TempDir(), "")
+	now := time.Now()
+	c.miss("OLD", now.Add(-2*missTTL))
+	c.miss("TAF", now)
+
+	got := make([]string, 0, len(c.misses))
+	for k := range c.misses {
+		got = append(got, k)
+	}
+
+	want := []string{"TAF"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("miss(...) should forget expired misses: -want, +got:\n%s", diff)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for i, k := range []string{"old", "mid", "new"} {
+		p := filepath.Join(dir, k)
+		if err := os.WriteFile(p, make([]byte, 10), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		mod := old.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := New(dir, "", WithMaxBytes(20))
+	if err := c.evict(); err != nil {
+		t.Fatalf("evict(): %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(entries))
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+
+	want := []string{"mid", "new"}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("evict() should remove the least recently used images: -want, +got:\n%s", diff)
+	}
+}
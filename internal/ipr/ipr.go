@@ -0,0 +1,276 @@
+// Package ipr loads Individual Player Ratings (IPRs) from a CSV or JSON file
+// or URL.
+package ipr
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sync metadata keys. Sync records where ratings were last loaded from, and
+// when.
+const (
+	MetadataSource   = "ipr_source"
+	MetadataLastSync = "ipr_last_sync"
+)
+
+// MaxAge is how long SyncIfStale considers a sync fresh. IPRs are revised a
+// few times a season, so a day is plenty.
+const MaxAge = 24 * time.Hour
+
+// maxSourceBytes is the largest source Sync will read. The league has a few
+// hundred players, so this is generous.
+const maxSourceBytes = 10 << 20
+
+// A Rating is a player's IPR.
+type Rating struct {
+	Name string `json:"name"`
+	IPR  int    `json:"ipr"`
+}
+
+// A Store stores player ratings.
+type Store interface {
+	UpsertPlayerIPR(ctx context.Context, name string, ipr int) error
+	GetMetadata(ctx context.Context, key string) (string, error)
+	SetMetadata(ctx context.Context, key, value string) error
+}
+
+// Options configures Sync.
+type Options struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// Option configures Sync.
+type Option func(*Options)
+
+// WithHTTPClient sets the HTTP client used to fetch URL sources.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *Options) {
+		o.client = hc
+	}
+}
+
+// WithClock sets the clock used to record when ratings were synced.
+func WithClock(now func() time.Time) Option {
+	return func(o *Options) {
+		o.now = now
+	}
+}
+
+func options(opts []Option) Options {
+	o := Options{
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Sync loads ratings from a source and upserts them into the store, then
+// records the source and time in the store's sync metadata. The source is a
+// file path or an http(s) URL. Sources ending in .json are parsed as JSON, and
+// anything else as CSV. Sync returns how many ratings it loaded.
+func Sync(ctx context.Context, s Store, source string, opts ...Option) (int, error) {
+	o := options(opts)
+
+	data, err := read(ctx, o.client, source)
+	if err != nil {
+		return 0, err
+	}
+
+	parse := ParseCSV
+	if isJSON(source) {
+		parse = ParseJSON
+	}
+	ratings, err := parse(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", source, err)
+	}
+
+	for _, r := range ratings {
+		if err := s.UpsertPlayerIPR(ctx, r.Name, r.IPR); err != nil {
+			return 0, fmt.Errorf("update IPR for %s: %w", r.Name, err)
+		}
+	}
+
+	if err := s.SetMetadata(ctx, MetadataSource, source); err != nil {
+		return 0, err
+	}
+	if err := s.SetMetadata(ctx, MetadataLastSync, o.now().UTC().Format(time.RFC3339)); err != nil {
+		return 0, err
+	}
+	return len(ratings), nil
+}
+
+// SyncIfStale calls Sync unless the store was synced from the same source
+// within MaxAge, or force is true. It returns whether it synced, and how many
+// ratings it loaded if it did.
+func SyncIfStale(ctx context.Context, s Store, source string, force bool, opts ...Option) (int, bool, error) {
+	if !force {
+		prev, err := s.GetMetadata(ctx, MetadataSource)
+		if err != nil {
+			return 0, false, err
+		}
+		last, err := s.GetMetadata(ctx, MetadataLastSync)
+		if err != nil {
+			return 0, false, err
+		}
+		if t, err := time.Parse(time.RFC3339, last); err == nil && prev == source && options(opts).now().Sub(t) < MaxAge {
+			return 0, false, nil
+		}
+	}
+	n, err := Sync(ctx, s, source, opts...)
+	return n, err == nil, err
+}
+
+// ParseCSV parses ratings from a CSV file. The first row is a header naming the
+// columns, in any order:
+//
+//	name  Player name. Required.
+//	ipr   Rating, from 1 to 6. Rows without one are unrated and skipped.
+//
+// Column names are case-insensitive. Other columns are ignored. This is the
+// format of the MNP archive's IPR.csv.
+func ParseCSV(r io.Reader) ([]Rating, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"name", "ipr"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header is missing required column %q", required)
+		}
+	}
+
+	var ratings []Rating
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV row: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(col string) string {
+			i := cols[col]
+			if i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		name, rating := get("name"), get("ipr")
+		if name == "" || rating == "" {
+			continue
+		}
+		ipr, err := strconv.Atoi(rating)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid IPR %q", line, rating)
+		}
+		r := Rating{Name: name, IPR: ipr}
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ratings = append(ratings, r)
+	}
+
+	return ratings, nil
+}
+
+// ParseJSON parses ratings from a JSON array of objects with "name" and "ipr"
+// fields, e.g. [{"name": "Jay Ostby", "ipr": 6}].
+func ParseJSON(r io.Reader) ([]Rating, error) {
+	var ratings []Rating
+	if err := json.NewDecoder(r).Decode(&ratings); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	for i := range ratings {
+		ratings[i].Name = strings.TrimSpace(ratings[i].Name)
+		if ratings[i].Name == "" {
+			return nil, fmt.Errorf("rating %d: name is required", i)
+		}
+		if err := ratings[i].validate(); err != nil {
+			return nil, fmt.Errorf("rating %d: %w", i, err)
+		}
+	}
+	return ratings, nil
+}
+
+func (r Rating) validate() error {
+	if r.IPR < 1 || r.IPR > 6 {
+		return fmt.Errorf("IPR %d for %s is not between 1 and 6", r.IPR, r.Name)
+	}
+	return nil
+}
+
+// read returns the contents of a file or http(s) URL.
+func read(ctx context.Context, hc *http.Client, source string) ([]byte, error) {
+	if !isURL(source) {
+		data, err := os.ReadFile(source) //nolint:gosec // Reading the user's chosen source is the point.
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	rsp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", source, err)
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Read-only response.
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %s", source, rsp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", source, err)
+	}
+	if len(data) > maxSourceBytes {
+		return nil, fmt.Errorf("%s is larger than %d bytes", source, maxSourceBytes)
+	}
+	return data, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func isJSON(source string) bool {
+	p := source
+	if isURL(source) {
+		if u, err := url.Parse(source); err == nil {
+			p = u.Path
+		}
+	}
+	return strings.EqualFold(path.Ext(p), ".json")
+}
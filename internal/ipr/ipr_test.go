@@ -0,0 +1,350 @@
+package ipr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type MockStore struct {
+	MockUpsertPlayerIPR func(ctx context.Context, name string, ipr int) error
+	MockGetMetadata     func(ctx context.Context, key string) (string, error)
+	MockSetMetadata     func(ctx context.Context, key, value string) error
+}
+
+func (m *MockStore) UpsertPlayerIPR(ctx context.Context, name string, ipr int) error {
+	return m.MockUpsertPlayerIPR(ctx, name, ipr)
+}
+
+func (m *MockStore) GetMetadata(ctx context.Context, key string) (string, error) {
+	return m.MockGetMetadata(ctx, key)
+}
+
+func (m *MockStore) SetMetadata(ctx context.Context, key, value string) error {
+	return m.MockSetMetadata(ctx, key, value)
+}
+
+// recordingStore returns a MockStore that records what it's sent.
+func recordingStore(ratings *[]Rating, metadata map[string]string) *MockStore {
+	return &MockStore{
+		MockUpsertPlayerIPR: func(_ context.Context, name string, ipr int) error {
+			*ratings = append(*ratings, Rating{Name: name, IPR: ipr})
+			return nil
+		},
+		MockGetMetadata: func(_ context.Context, key string) (string, error) {
+			return metadata[key], nil
+		},
+		MockSetMetadata: func(_ context.Context, key, value string) error {
+			metadata[key] = value
+			return nil
+		},
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	type want struct {
+		ratings []Rating
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		content string
+		want    want
+	}{
+		"Valid": {
+			reason:  "Rows should be parsed into ratings, whatever order and case the columns are in.",
+			content: "Team,Name,IPR\nCRA,Alice,3\nPYC, Bob ,5\n",
+			want: want{
+				ratings: []Rating{
+					{Name: "Alice", IPR: 3},
+					{Name: "Bob", IPR: 5},
+				},
+			},
+		},
+		"Unrated": {
+			reason:  "Rows without an IPR should be skipped.",
+			content: "name,ipr\nAlice,3\nBob,\n",
+			want: want{
+				ratings: []Rating{{Name: "Alice", IPR: 3}},
+			},
+		},
+		"MissingColumn": {
+			reason:  "A header without an ipr column should be rejected.",
+			content: "name,rating\nAlice,3\n",
+			want:    want{err: cmpopts.AnyError},
+		},
+		"InvalidIPR": {
+			reason:  "A non-numeric IPR should be rejected.",
+			content: "name,ipr\nAlice,three\n",
+			want:    want{err: cmpopts.AnyError},
+		},
+		"OutOfRange": {
+			reason:  "An IPR outside 1 to 6 should be rejected.",
+			content: "name,ipr\nAlice,7\n",
+			want:    want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tc.content))
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseCSV(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ratings, got); diff != "" {
+				t.Errorf("\n%s\nParseCSV(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	type want struct {
+		ratings []Rating
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		content string
+		want    want
+	}{
+		"Valid": {
+			reason:  "An array of ratings should be parsed.",
+			content: `[{"name": "Alice", "ipr": 3}, {"name": "Bob", "ipr": 5}]`,
+			want: want{
+				ratings: []Rating{
+					{Name: "Alice", IPR: 3},
+					{Name: "Bob", IPR: 5},
+				},
+			},
+		},
+		"MissingName": {
+			reason:  "A rating without a name should be rejected.",
+			content: `[{"ipr": 3}]`,
+			want:    want{err: cmpopts.AnyError},
+		},
+		"OutOfRange": {
+			reason:  "An IPR outside 1 to 6 should be rejected.",
+			content: `[{"name": "Alice", "ipr": 0}]`,
+			want:    want{err: cmpopts.AnyError},
+		},
+		"Malformed": {
+			reason:  "Malformed JSON should be rejected.",
+			content: `{"name": "Alice"`,
+			want:    want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseJSON(strings.NewReader(tc.content))
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseJSON(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ratings, got); diff != "" {
+				t.Errorf("\n%s\nParseJSON(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/iprs.json":
+			_, _ = w.Write([]byte(`[{"name": "Carol", "ipr": 4}]`))
+		case "/iprs.csv":
+			_, _ = w.Write([]byte("name,ipr\nDave,2\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	file := filepath.Join(t.TempDir(), "IPR.csv")
+	if err := os.WriteFile(file, []byte("ipr,name\n3,Alice\n5,Bob\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		n        int
+		ratings  []Rating
+		metadata map[string]string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		source string
+		want   want
+	}{
+		"File": {
+			reason: "Ratings in a CSV file should be upserted and the sync recorded.",
+			source: file,
+			want: want{
+				n:       2,
+				ratings: []Rating{{Name: "Alice", IPR: 3}, {Name: "Bob", IPR: 5}},
+				metadata: map[string]string{
+					MetadataSource:   file,
+					MetadataLastSync: "2024-01-18T12:00:00Z",
+				},
+			},
+		},
+		"JSONURL": {
+			reason: "Ratings at a URL ending in .json should be parsed as JSON.",
+			source: srv.URL + "/iprs.json",
+			want: want{
+				n:       1,
+				ratings: []Rating{{Name: "Carol", IPR: 4}},
+				metadata: map[string]string{
+					MetadataSource:   srv.URL + "/iprs.json",
+					MetadataLastSync: "2024-01-18T12:00:00Z",
+				},
+			},
+		},
+		"CSVURL": {
+			reason: "Ratings at any other URL should be parsed as CSV.",
+			source: srv.URL + "/iprs.csv",
+			want: want{
+				n:       1,
+				ratings: []Rating{{Name: "Dave", IPR: 2}},
+				metadata: map[string]string{
+					MetadataSource:   srv.URL + "/iprs.csv",
+					MetadataLastSync: "2024-01-18T12:00:00Z",
+				},
+			},
+		},
+		"NotFound": {
+			reason: "A URL that doesn't return ratings should return an error, and record nothing.",
+			source: srv.URL + "/missing.csv",
+			want: want{
+				metadata: map[string]string{},
+				err:      cmpopts.AnyError,
+			},
+		},
+		"MissingFile": {
+			reason: "A file that doesn't exist should return an error, and record nothing.",
+			source: filepath.Join(t.TempDir(), "missing.csv"),
+			want: want{
+				metadata: map[string]string{},
+				err:      cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var ratings []Rating
+			metadata := map[string]string{}
+			s := recordingStore(&ratings, metadata)
+
+			n, err := Sync(context.Background(), s, tc.source, WithHTTPClient(srv.Client()), WithClock(func() time.Time { return now }))
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.n, n); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want count, +got count:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ratings, ratings); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want ratings, +got ratings:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.metadata, metadata); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want metadata, +got metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSyncUpsertError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "IPR.csv")
+	if err := os.WriteFile(file, []byte("name,ipr\nAlice,3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &MockStore{
+		MockUpsertPlayerIPR: func(_ context.Context, _ string, _ int) error {
+			return errors.New("boom")
+		},
+	}
+	if _, err := Sync(context.Background(), s, file); err == nil {
+		t.Errorf("Sync(...): want error when the store can't upsert a rating, got nil")
+	}
+}
+
+func TestSyncIfStale(t *testing.T) {
+	now := time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC)
+	file := filepath.Join(t.TempDir(), "IPR.csv")
+	if err := os.WriteFile(file, []byte("name,ipr\nAlice,3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		n      int
+		synced bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		metadata map[string]string
+		force    bool
+		want     want
+	}{
+		"Never": {
+			reason:   "A store that's never been synced should sync.",
+			metadata: map[string]string{},
+			want:     want{n: 1, synced: true},
+		},
+		"Fresh": {
+			reason:   "A store synced from the same source within MaxAge shouldn't sync.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-18T00:00:00Z"},
+			want:     want{},
+		},
+		"Forced": {
+			reason:   "A forced sync should sync, however fresh the store is.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-18T00:00:00Z"},
+			force:    true,
+			want:     want{n: 1, synced: true},
+		},
+		"Stale": {
+			reason:   "A store synced longer than MaxAge ago should sync.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-17T00:00:00Z"},
+			want:     want{n: 1, synced: true},
+		},
+		"NewSource": {
+			reason:   "A store synced from a different source should sync.",
+			metadata: map[string]string{MetadataSource: "https://example.org/iprs.json", MetadataLastSync: "2024-01-18T00:00:00Z"},
+			want:     want{n: 1, synced: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var ratings []Rating
+			s := recordingStore(&ratings, tc.metadata)
+
+			n, synced, err := SyncIfStale(context.Background(), s, file, tc.force, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("\n%s\nSyncIfStale(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, want{n: n, synced: synced}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nSyncIfStale(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if got := len(ratings) > 0; got != tc.want.synced {
+				t.Errorf("\n%s\nSyncIfStale(...): upserted ratings %t, want %t", tc.reason, got, tc.want.synced)
+			}
+		})
+	}
+}
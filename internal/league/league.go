@@ -0,0 +1,158 @@
+// Package league describes the format MNP matches were played in each season.
+package league
+
+import (
+	_ "embed" // For the default formats.
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+)
+
+//go:embed formats.json
+var defaults []byte
+
+// A Format is how a season's matches were played.
+type Format struct {
+	Rounds        int   `json:"rounds"`         // Rounds in a match, numbered from 1.
+	DoublesRounds []int `json:"doubles_rounds"` // Rounds played as doubles, four players to a game.
+}
+
+// Points available in each singles and doubles game.
+const (
+	SinglesPoints = 3
+	DoublesPoints = 5
+)
+
+// Games played in each singles and doubles round. A match of two doubles and
+// two singles rounds is worth 82 points.
+const (
+	SinglesGames = 7
+	DoublesGames = 4
+)
+
+// IsDoubles returns true if games in the supplied round are doubles.
+func (f Format) IsDoubles(round int) bool {
+	return slices.Contains(f.DoublesRounds, round)
+}
+
+// Points returns the points available in each game of the supplied round.
+func (f Format) Points(round int) int {
+	if f.IsDoubles(round) {
+		return DoublesPoints
+	}
+	return SinglesPoints
+}
+
+// Games returns the number of games played in the supplied round.
+func (f Format) Games(round int) int {
+	if f.IsDoubles(round) {
+		return DoublesGames
+	}
+	return SinglesGames
+}
+
+// TotalPoints returns the points available in a match.
+func (f Format) TotalPoints() int {
+	total := 0
+	for round := 1; round <= f.Rounds; round++ {
+		total += f.Games(round) * f.Points(round)
+	}
+	return total
+}
+
+// HomePicks returns true if the home team picks the machines played in the
+// supplied round. The away team picks in odd rounds, and the home team in even
+// rounds.
+func HomePicks(round int) bool {
+	return round%2 == 0
+}
+
+// Formats maps the first season each format was played in to the format. A
+// format applies until the next season with a format of its own.
+type Formats map[int]Format
+
+// For returns the format of the supplied season. Seasons before the first
+// known format use the first known format.
+func (f Formats) For(season int) Format {
+	first, found := 0, false
+	for s := range f {
+		if s <= season && (!found || s > first) {
+			first, found = s, true
+		}
+	}
+	if !found {
+		for s := range f {
+			if !found || s < first {
+				first, found = s, true
+			}
+		}
+	}
+	return f[first]
+}
+
+// Latest returns the format of the latest season with a known format.
+func (f Formats) Latest() Format {
+	return f.For(math.MaxInt)
+}
+
+// Defaults returns the formats MNP has played, as far as mnp knows.
+func Defaults() Formats {
+	f, err := parse(defaults)
+	if err != nil {
+		panic(fmt.Sprintf("parse default formats: %v", err))
+	}
+	return f
+}
+
+// Load returns the default formats, overridden by any in the JSON file at the
+// supplied path. The file maps each season a format started in to the format,
+// e.g. {"12": {"rounds": 4, "doubles_rounds": [1, 4]}}. An empty path returns
+// the defaults.
+func Load(path string) (Formats, error) {
+	f := Defaults()
+	if path == "" {
+		return f, nil
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // Reading the user's own formats file.
+	if err != nil {
+		return nil, fmt.Errorf("read formats: %w", err)
+	}
+
+	overrides, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse formats %s: %w", path, err)
+	}
+	for s, o := range overrides {
+		f[s] = o
+	}
+	return f, nil
+}
+
+func parse(b []byte) (Formats, error) {
+	var raw map[string]Format
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	f := make(Formats, len(raw))
+	for key, format := range raw {
+		season, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid season %q", key)
+		}
+		if format.Rounds < 1 {
+			return nil, fmt.Errorf("season %d: rounds must be at least 1", season)
+		}
+		for _, r := range format.DoublesRounds {
+			if r < 1 || r > format.Rounds {
+				return nil, fmt.Errorf("season %d: doubles round %d isn't between 1 and %d", season, r, format.Rounds)
+			}
+		}
+		f[season] = format
+	}
+	return f, nil
+}
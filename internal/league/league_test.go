@@ -0,0 +1,163 @@
+package league
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFormatsFor(t *testing.T) {
+	f := Formats{
+		5:  {Rounds: 3, DoublesRounds: []int{1}},
+		12: {Rounds: 4, DoublesRounds: []int{1, 4}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		season int
+		want   Format
+	}{
+		"FirstSeason": {
+			reason: "A season a format started in should use that format.",
+			season: 5,
+			want:   Format{Rounds: 3, DoublesRounds: []int{1}},
+		},
+		"LaterSeason": {
+			reason: "A season should use the latest format that started before it.",
+			season: 11,
+			want:   Format{Rounds: 3, DoublesRounds: []int{1}},
+		},
+		"CurrentSeason": {
+			reason: "A season after the last format started should use the last format.",
+			season: 22,
+			want:   Format{Rounds: 4, DoublesRounds: []int{1, 4}},
+		},
+		"EarlySeason": {
+			reason: "A season before any known format should use the first format.",
+			season: 1,
+			want:   Format{Rounds: 3, DoublesRounds: []int{1}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := f.For(tc.season)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFor(%d): -want, +got:\n%s", tc.reason, tc.season, diff)
+			}
+		})
+	}
+}
+
+func TestFormatsLatest(t *testing.T) {
+	f := Formats{
+		5:  {Rounds: 3, DoublesRounds: []int{1}},
+		12: {Rounds: 4, DoublesRounds: []int{1, 4}},
+	}
+	want := Format{Rounds: 4, DoublesRounds: []int{1, 4}}
+	if diff := cmp.Diff(want, f.Latest()); diff != "" {
+		t.Errorf("Latest(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestFormatPoints(t *testing.T) {
+	f := Format{Rounds: 4, DoublesRounds: []int{1, 4}}
+	for round, points := range map[int]int{1: DoublesPoints, 2: SinglesPoints, 3: SinglesPoints, 4: DoublesPoints} {
+		if got := f.Points(round); got != points {
+			t.Errorf("Points(%d): want %d, got %d", round, points, got)
+		}
+	}
+}
+
+func TestFormatGames(t *testing.T) {
+	f := Format{Rounds: 4, DoublesRounds: []int{1, 4}}
+	for round, games := range map[int]int{1: DoublesGames, 2: SinglesGames, 3: SinglesGames, 4: DoublesGames} {
+		if got := f.Games(round); got != games {
+			t.Errorf("Games(%d): want %d, got %d", round, games, got)
+		}
+	}
+	if got := f.TotalPoints(); got != 82 {
+		t.Errorf("TotalPoints(): want 82, got %d", got)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	f := Defaults().For(22)
+	for round, doubles := range map[int]bool{1: true, 2: false, 3: false, 4: true} {
+		if got := f.IsDoubles(round); got != doubles {
+			t.Errorf("Defaults().For(22).IsDoubles(%d): want %t, got %t", round, doubles, got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	type want struct {
+		formats Formats
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"NoPath": {
+			reason: "An empty path should return the defaults.",
+			want:   want{formats: Defaults()},
+		},
+		"Override": {
+			reason: "Formats in the file should be added to the defaults.",
+			path:   write("override.json", `{"8": {"rounds": 5, "doubles_rounds": [1, 3, 5]}}`),
+			want: want{formats: Formats{
+				1: {Rounds: 4, DoublesRounds: []int{1, 4}},
+				8: {Rounds: 5, DoublesRounds: []int{1, 3, 5}},
+			}},
+		},
+		"Replace": {
+			reason: "A format in the file should replace a default format for the same season.",
+			path:   write("replace.json", `{"1": {"rounds": 3, "doubles_rounds": []}}`),
+			want: want{formats: Formats{
+				1: {Rounds: 3, DoublesRounds: []int{}},
+			}},
+		},
+		"InvalidSeason": {
+			reason: "A season that isn't a number should be rejected.",
+			path:   write("season.json", `{"twelve": {"rounds": 4}}`),
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidRound": {
+			reason: "A doubles round the format doesn't have should be rejected.",
+			path:   write("round.json", `{"12": {"rounds": 4, "doubles_rounds": [5]}}`),
+			want:   want{err: cmpopts.AnyError},
+		},
+		"MissingFile": {
+			reason: "A file that doesn't exist should return an error.",
+			path:   filepath.Join(dir, "missing.json"),
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Load(tc.path)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoad(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.formats, got); diff != "" {
+				t.Errorf("\n%s\nLoad(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
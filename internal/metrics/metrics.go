@@ -0,0 +1,257 @@
+// Package metrics exposes counters, histograms, and gauges in the Prometheus
+// text format, for monitoring a long running server.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets suited to HTTP request latencies, in
+// seconds. They're the same as the Prometheus client's defaults.
+//
+//nolint:gochecknoglobals // Read-only buckets.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// A metric can write itself in the Prometheus text format.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// A Registry is a set of metrics. It's an http.Handler that serves them in the
+// Prometheus text format.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Counter registers and returns a counter with the supplied label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Histogram registers and returns a histogram with the supplied upper bucket
+// bounds, in increasing order, and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{desc: desc{name: name, help: help, labels: labels}, buckets: buckets, series: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// GaugeFunc registers a gauge whose value is returned by fn each time the
+// metrics are written. Gauges whose fn returns an error are left out.
+func (r *Registry) GaugeFunc(name, help string, fn func() (float64, error)) {
+	r.register(&valueFunc{desc: desc{name: name, help: help}, kind: "gauge", fn: fn})
+}
+
+// CounterFunc registers a counter whose value is returned by fn each time the
+// metrics are written, for counts kept elsewhere. Counters whose fn returns an
+// error are left out.
+func (r *Registry) CounterFunc(name, help string, fn func() (float64, error)) {
+	r.register(&valueFunc{desc: desc{name: name, help: help}, kind: "counter", fn: fn})
+}
+
+// Write writes every metric in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves every metric in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w) //nolint:errcheck // Nothing to do if the client went away.
+}
+
+// desc describes a metric.
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d desc) header(w io.Writer, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, d.help, d.name, kind)
+	return err
+}
+
+// key joins label values into a map key.
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\x00")
+}
+
+// pairs returns the label pairs of a map key, e.g. `route="/",code="200"`.
+func (d desc) pairs(key string) []string {
+	if len(d.labels) == 0 {
+		return nil
+	}
+	values := strings.Split(key, "\x00")
+	pairs := make([]string, len(d.labels))
+	for i, l := range d.labels {
+		pairs[i] = l + `="` + escape(values[i]) + `"`
+	}
+	return pairs
+}
+
+// A Counter is a count that only goes up, such as requests served.
+type Counter struct {
+	desc
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// Inc adds one to the counter with the supplied label values, in the order the
+// counter's labels were registered.
+func (c *Counter) Inc(values ...string) {
+	k := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[k]++
+}
+
+func (c *Counter) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.header(w, "counter"); err != nil {
+		return err
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.values)) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, braces(c.pairs(k)), format(c.values[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A Histogram counts observations, such as request latencies, in buckets.
+type Histogram struct {
+	desc
+
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // Observations at or below each bucket's bound.
+	count  uint64
+	sum    float64
+}
+
+// Observe records a value in the histogram with the supplied label values, in
+// the order the histogram's labels were registered.
+func (h *Histogram) Observe(v float64, values ...string) {
+	k := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[k]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[k] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.header(w, "histogram"); err != nil {
+		return err
+	}
+	for _, k := range slices.Sorted(maps.Keys(h.series)) {
+		s, pairs := h.series[k], h.pairs(k)
+		for i, b := range h.buckets {
+			le := append(slices.Clone(pairs), `le="`+format(b)+`"`)
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, braces(le), s.counts[i]); err != nil {
+				return err
+			}
+		}
+		inf := append(slices.Clone(pairs), `le="+Inf"`)
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, braces(inf), s.count, h.name, braces(pairs), format(s.sum), h.name, braces(pairs), s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A valueFunc is a gauge or counter whose value is read when it's written.
+type valueFunc struct {
+	desc
+
+	kind string
+	fn   func() (float64, error)
+}
+
+func (f *valueFunc) write(w io.Writer) error {
+	v, err := f.fn()
+	if err != nil {
+		return nil //nolint:nilerr // A metric that can't be read is left out.
+	}
+	if err := f.header(w, f.kind); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s %s\n", f.name, format(v))
+	return err
+}
+
+func braces(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func format(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escape escapes a label value.
+func escape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.Counter("requests_total", "Requests served.", "route", "code")
+	c.Inc("GET /", "200")
+	c.Inc("GET /", "200")
+	c.Inc(`GET /m/{machine}`, "404")
+
+	h := r.Histogram("duration_seconds", "Request latency.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+
+	r.GaugeFunc("size_bytes", "Database size.", func() (float64, error) { return 4096, nil })
+	r.GaugeFunc("broken", "A gauge that can't be read.", func() (float64, error) { return 0, errors.New("boom") })
+	r.CounterFunc("hits_total", "Cache hits.", func() (float64, error) { return 7, nil })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := strings.Join([]string{
+		"# HELP requests_total Requests served.",
+		"# TYPE requests_total counter",
+		`requests_total{route="GET /",code="200"} 2`,
+		`requests_total{route="GET /m/{machine}",code="404"} 1`,
+		"# HELP duration_seconds Request latency.",
+		"# TYPE duration_seconds histogram",
+		`duration_seconds_bucket{le="0.1"} 1`,
+		`duration_seconds_bucket{le="1"} 2`,
+		`duration_seconds_bucket{le="+Inf"} 3`,
+		"duration_seconds_sum 2.55",
+		"duration_seconds_count 3",
+		"# HELP size_bytes Database size.",
+		"# TYPE size_bytes gauge",
+		"size_bytes 4096",
+		"# HELP hits_total Cache hits.",
+		"# TYPE hits_total counter",
+		"hits_total 7",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, rec.Body.String()); diff != "" {
+		t.Errorf("ServeHTTP(...): -want, +got:\n%s", diff)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("ServeHTTP(...): Content-Type = %q, want text/plain", got)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		v      string
+		want   string
+	}{
+		"Plain": {
+			reason: "Values without special characters should be unchanged.",
+			v:      "GET /",
+			want:   "GET /",
+		},
+		"Special": {
+			reason: "Backslashes, quotes, and newlines should be escaped.",
+			v:      "a\\b\"c\nd",
+			want:   `a\\b\"c\nd`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, escape(tc.v)); diff != "" {
+				t.Errorf("\n%s\nescape(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
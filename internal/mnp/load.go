@@ -13,25 +13,28 @@ import (
 	"time"
 
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
 )
 
 // A Store loads MNP data.
 type Store interface { //nolint:interfacebloat // Maps 1:1 to the store operations the ETL performs.
 	UpsertMachine(ctx context.Context, m db.Machine) error
 	UpsertVenue(ctx context.Context, key, name string) (int64, error)
-	UpsertVenueMachine(ctx context.Context, venueID int64, machineKey string) error
+	ReplaceVenueMachines(ctx context.Context, venueID, seasonID int64, machineKeys []string) error
 	UpsertSeason(ctx context.Context, number int) (int64, error)
 	UpsertTeam(ctx context.Context, t db.Team) (int64, error)
 	UpsertPlayer(ctx context.Context, name string) (int64, error)
 	UpsertRoster(ctx context.Context, playerID, teamID int64, role string) error
+	ReplaceRoster(ctx context.Context, teamID int64, playerIDs []int64, role string) error
 	UpsertMatch(ctx context.Context, m db.Match) (int64, error)
-	InsertGame(ctx context.Context, g db.Game) (int64, error)
-	InsertGameResult(ctx context.Context, r db.GameResult) error
-	DeleteMatchGames(ctx context.Context, matchID int64) error
+	LoadMatchBatch(ctx context.Context, matches []db.MatchGames) error
 	GetTeamID(ctx context.Context, key string, seasonID int64) (int64, error)
 	ListMachineKeys(ctx context.Context) (map[string]bool, error)
 	LoadedSeasons(ctx context.Context) (map[int]bool, error)
+	ListMatchJSON(ctx context.Context, season int) ([]db.MatchJSON, error)
 	UpsertPlayerIPR(ctx context.Context, name string, ipr int) error
+	GetMetadata(ctx context.Context, key string) (string, error)
+	SetMetadata(ctx context.Context, key, value string) error
 }
 
 // Machines extracts, transforms, and loads pinball machine data.
@@ -100,9 +103,11 @@ func (v *Venues) Transform() []VenueData {
 	return out
 }
 
-// Load inserts the transformed venue data into the store. It skips machine
-// associations for machines not already known to the store.
-func (v *Venues) Load(ctx context.Context, s Store) error {
+// Load inserts the transformed venue data into the store. The archive lists
+// the machines at each venue now, so they replace the venue's machines in the
+// supplied season, which should be the latest. It skips machine associations
+// for machines not already known to the store.
+func (v *Venues) Load(ctx context.Context, s Store, seasonID int64) error {
 	knownMachines, err := s.ListMachineKeys(ctx)
 	if err != nil {
 		return fmt.Errorf("list machine keys: %w", err)
@@ -113,14 +118,15 @@ func (v *Venues) Load(ctx context.Context, s Store) error {
 		if err != nil {
 			return fmt.Errorf("upsert venue %s: %w", vd.Key, err)
 		}
+		machines := make([]string, 0, len(vd.Machines))
 		for _, mk := range vd.Machines {
-			if !knownMachines[mk] {
-				continue
-			}
-			if err := s.UpsertVenueMachine(ctx, venueID, mk); err != nil {
-				return fmt.Errorf("upsert venue machine %s/%s: %w", vd.Key, mk, err)
+			if knownMachines[mk] {
+				machines = append(machines, mk)
 			}
 		}
+		if err := s.ReplaceVenueMachines(ctx, venueID, seasonID, machines); err != nil {
+			return fmt.Errorf("replace venue %s's machines: %w", vd.Key, err)
+		}
 	}
 
 	return nil
@@ -203,14 +209,18 @@ func (s *Season) Load(ctx context.Context, st Store, seasonNum int) (int64, erro
 			return 0, fmt.Errorf("upsert team %s: %w", t.Key, err)
 		}
 
+		// Replacing the roster drops players who left the team mid-season,
+		// and records who joined or left since the last sync.
+		playerIDs := make([]int64, 0, len(t.Roster))
 		for _, name := range t.Roster {
 			playerID, err := st.UpsertPlayer(ctx, name)
 			if err != nil {
 				return 0, fmt.Errorf("upsert player %s: %w", name, err)
 			}
-			if err := st.UpsertRoster(ctx, playerID, teamID, RolePlayer); err != nil {
-				return 0, fmt.Errorf("upsert roster %s: %w", name, err)
-			}
+			playerIDs = append(playerIDs, playerID)
+		}
+		if err := st.ReplaceRoster(ctx, teamID, playerIDs, RolePlayer); err != nil {
+			return 0, fmt.Errorf("replace roster %s: %w", t.Key, err)
 		}
 	}
 
@@ -243,12 +253,14 @@ type weekMatchJSON struct {
 
 // ScheduleMatchData is a transformed schedule entry ready for loading.
 type ScheduleMatchData struct {
-	Key     string
-	Week    int
-	Date    string
-	HomeKey string
-	AwayKey string
-	Venue   VenueRef
+	Key       string
+	Week      int
+	WeekLabel string
+	Stage     db.Stage
+	Date      string
+	HomeKey   string
+	AwayKey   string
+	Venue     VenueRef
 }
 
 // Extract reads and decodes schedule data from a season.json file.
@@ -256,20 +268,34 @@ func (s *Schedule) Extract(path string) error {
 	return decodeJSONFile(path, &s.raw)
 }
 
-// Transform returns clean schedule entries from the raw JSON data.
+// Transform returns clean schedule entries from the raw JSON data. Playoff
+// weeks are numbered on from the last regular season week, in order.
 func (s *Schedule) Transform() []ScheduleMatchData {
+	last := 0
+	for _, w := range s.raw.Weeks {
+		if n, stage := parseWeek(w.N); stage == db.StageRegular {
+			last = max(last, n)
+		}
+	}
+
 	var out []ScheduleMatchData
 	for _, w := range s.raw.Weeks {
-		weekNum, _ := strconv.Atoi(w.N)
+		weekNum, stage := parseWeek(w.N)
+		if stage == db.StagePlayoffs {
+			last++
+			weekNum = last
+		}
 		date := isoDate(w.Date)
 		for _, m := range w.Matches {
 			out = append(out, ScheduleMatchData{
-				Key:     m.MatchKey,
-				Week:    weekNum,
-				Date:    date,
-				HomeKey: m.HomeKey,
-				AwayKey: m.AwayKey,
-				Venue:   VenueRef{Key: m.Venue.Key, Name: m.Venue.Name},
+				Key:       m.MatchKey,
+				Week:      weekNum,
+				WeekLabel: w.N,
+				Stage:     stage,
+				Date:      date,
+				HomeKey:   m.HomeKey,
+				AwayKey:   m.AwayKey,
+				Venue:     VenueRef{Key: m.Venue.Key, Name: m.Venue.Name},
 			})
 		}
 	}
@@ -301,6 +327,8 @@ func (s *Schedule) Load(ctx context.Context, st Store, seasonID int64) error {
 			Key:        m.Key,
 			SeasonID:   seasonID,
 			Week:       m.Week,
+			WeekLabel:  m.WeekLabel,
+			Stage:      m.Stage,
 			Date:       m.Date,
 			HomeTeamID: homeTeamID,
 			AwayTeamID: awayTeamID,
@@ -314,7 +342,8 @@ func (s *Schedule) Load(ctx context.Context, st Store, seasonID int64) error {
 
 // Match extracts, transforms, and loads match data.
 type Match struct {
-	raw matchRawJSON
+	raw  matchRawJSON
+	json []byte
 }
 
 type matchRawJSON struct {
@@ -368,15 +397,21 @@ type gameJSON struct {
 	Points4 float64 `json:"points_4"`
 }
 
-// MatchData is a transformed match ready for loading.
+// MatchData is a transformed match ready for loading. Playoff matches' Week is
+// 0, because match files don't number playoff weeks. They keep the number
+// their schedule entry gave them.
 type MatchData struct {
-	Key     string
-	Week    int
-	Date    string
-	Venue   VenueRef
-	HomeKey string
-	AwayKey string
-	Games   []GameData
+	Key        string
+	Week       int
+	WeekLabel  string
+	Stage      db.Stage
+	Date       string
+	Venue      VenueRef
+	HomeKey    string
+	AwayKey    string
+	HomeLineup []string // Player names, including players who didn't play a game.
+	AwayLineup []string
+	Games      []GameData
 }
 
 // VenueRef is a reference to a venue by key and name.
@@ -397,13 +432,32 @@ type GameData struct {
 type ResultData struct {
 	PlayerName string
 	Score      int64
+	Points     float64
 	Position   int
 	IsHome     bool
 }
 
 // Extract reads and decodes match data from a match file.
 func (m *Match) Extract(path string) error {
-	return decodeJSONFile(path, &m.raw)
+	data, err := os.ReadFile(path) //nolint:gosec // Internal archive path.
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return m.Decode(data)
+}
+
+// Decode decodes match data from the JSON of a match file.
+func (m *Match) Decode(data []byte) error {
+	if err := json.Unmarshal(data, &m.raw); err != nil {
+		return err
+	}
+	m.json = data
+	return nil
+}
+
+// JSON returns the JSON the match was decoded from.
+func (m *Match) JSON() []byte {
+	return m.json
 }
 
 // decodeJSONFile opens a file and decodes its JSON contents into v.
@@ -416,21 +470,25 @@ func decodeJSONFile(path string, v any) error {
 	return json.NewDecoder(f).Decode(v)
 }
 
-// Transform resolves player hashes to names and builds clean game results.
-func (m *Match) Transform() MatchData {
+// Transform resolves player hashes to names and builds clean game results,
+// using the supplied format to decide which rounds are doubles.
+func (m *Match) Transform(f league.Format) MatchData {
 	playerNames := make(map[string]string)
+	var home, away []string
 	for _, p := range m.raw.Home.Lineup {
 		playerNames[p.Key] = p.Name
+		home = append(home, p.Name)
 	}
 	for _, p := range m.raw.Away.Lineup {
 		playerNames[p.Key] = p.Name
+		away = append(away, p.Name)
 	}
 
-	weekNum, _ := strconv.Atoi(m.raw.Week)
+	weekNum, stage := parseWeek(m.raw.Week)
 
 	var games []GameData
 	for _, r := range m.raw.Rounds {
-		isDoubles := r.N == 1 || r.N == 4
+		isDoubles := f.IsDoubles(r.N)
 		for _, g := range r.Games {
 			if !g.Done {
 				continue
@@ -445,35 +503,53 @@ func (m *Match) Transform() MatchData {
 	}
 
 	return MatchData{
-		Key:     m.raw.Key,
-		Week:    weekNum,
-		Date:    isoDate(m.raw.Date),
-		Venue:   VenueRef{Key: m.raw.Venue.Key, Name: m.raw.Venue.Name},
-		HomeKey: m.raw.Home.Key,
-		AwayKey: m.raw.Away.Key,
-		Games:   games,
+		Key:        m.raw.Key,
+		Week:       weekNum,
+		WeekLabel:  m.raw.Week,
+		Stage:      stage,
+		Date:       isoDate(m.raw.Date),
+		Venue:      VenueRef{Key: m.raw.Venue.Key, Name: m.raw.Venue.Name},
+		HomeKey:    m.raw.Home.Key,
+		AwayKey:    m.raw.Away.Key,
+		HomeLineup: home,
+		AwayLineup: away,
+		Games:      games,
+	}
+}
+
+// parseWeek parses the archive's label for a week. Regular season weeks are
+// numbered. Playoff weeks are named instead (e.g. "SF"), and parse to week 0.
+func parseWeek(label string) (int, db.Stage) {
+	if label == "" {
+		return 0, db.StageRegular
+	}
+	n, err := strconv.Atoi(label)
+	if err != nil {
+		return 0, db.StagePlayoffs
 	}
+	return n, db.StageRegular
 }
 
 // buildResults constructs player results for a game, resolving hashes to names.
-// In doubles (rounds 1,4): players 1,3 are away team, 2,4 are home team.
-// In singles (rounds 2,3): player 1 is away, player 2 is home.
+// In doubles: players 1,3 are away team, 2,4 are home team.
+// In singles: player 1 is away, player 2 is home.
 func buildResults(g gameJSON, playerNames map[string]string, isDoubles bool) []ResultData {
 	type raw struct {
 		hash   string
 		score  int64
+		points float64
 		pos    int
 		isHome bool
 	}
 
 	raws := []raw{
-		{hash: g.Player1, score: g.Score1, pos: 1, isHome: false},
-		{hash: g.Player2, score: g.Score2, pos: 2, isHome: true},
+		{hash: g.Player1, score: g.Score1, points: g.Points1, pos: 1, isHome: false},
+		{hash: g.Player2, score: g.Score2, points: g.Points2, pos: 2, isHome: true},
 	}
 	if isDoubles {
 		raws = append(raws,
-			raw{hash: g.Player3, score: g.Score3, pos: 3, isHome: false},
-			raw{hash: g.Player4, score: g.Score4, pos: 4, isHome: true},
+			raw{hash: g.Player3, score: g.Score3, points: g.Points3, pos: 3, isHome: false},
+			raw{hash: g.Player4, score: g.Score4, points: g.Points4, pos: 4, isHome: true},
 		)
 	}
 
@@ -489,6 +565,7 @@ func buildResults(g gameJSON, playerNames map[string]string, isDoubles bool) []R
 		results = append(results, ResultData{
 			PlayerName: name,
 			Score:      r.score,
+			Points:     r.points,
 			Position:   r.pos,
 			IsHome:     r.isHome,
 		})
@@ -563,77 +640,204 @@ func isoDate(s string) string {
 }
 
 // Load inserts the transformed match data into the store.
-func (m *Match) Load(ctx context.Context, s Store, seasonID int64) error {
-	data := m.Transform()
+func (m *Match) Load(ctx context.Context, s Store, seasonID int64, f league.Format) error {
+	return loadMatches(ctx, s, seasonID, []MatchData{m.Transform(f)})
+}
+
+// LoadMatches inserts matches that aren't in the MNP archive, such as seasons
+// imported from spreadsheets, into the store. It creates the season and its
+// teams, and builds each team's roster from the players who played for it.
+func LoadMatches(ctx context.Context, st Store, seasonNum int, matches []MatchData) error {
+	seasonID, err := st.UpsertSeason(ctx, seasonNum)
+	if err != nil {
+		return fmt.Errorf("upsert season %d: %w", seasonNum, err)
+	}
 
+	// A team's home venue is wherever it hosted its first match.
+	var teams []string
+	venues := make(map[string]VenueRef)
+	for _, m := range matches {
+		for _, key := range []string{m.HomeKey, m.AwayKey} {
+			if _, ok := venues[key]; !ok {
+				teams = append(teams, key)
+				venues[key] = VenueRef{}
+			}
+		}
+		if venues[m.HomeKey].Key == "" {
+			venues[m.HomeKey] = m.Venue
+		}
+	}
+
+	teamIDs := make(map[string]int64, len(teams))
+	for _, key := range teams {
+		var venueID int64
+		if v := venues[key]; v.Key != "" {
+			venueID, err = st.UpsertVenue(ctx, v.Key, v.Name)
+			if err != nil {
+				return fmt.Errorf("upsert venue %s: %w", v.Key, err)
+			}
+		}
+		teamIDs[key], err = st.UpsertTeam(ctx, db.Team{
+			Key:         key,
+			Name:        key,
+			SeasonID:    seasonID,
+			HomeVenueID: venueID,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert team %s: %w", key, err)
+		}
+	}
+
+	for _, m := range matches {
+		for _, g := range m.Games {
+			for _, r := range g.Results {
+				teamKey := m.AwayKey
+				if r.IsHome {
+					teamKey = m.HomeKey
+				}
+				playerID, err := st.UpsertPlayer(ctx, r.PlayerName)
+				if err != nil {
+					return fmt.Errorf("upsert player %s: %w", r.PlayerName, err)
+				}
+				if err := st.UpsertRoster(ctx, playerID, teamIDs[teamKey], RolePlayer); err != nil {
+					return fmt.Errorf("upsert roster %s: %w", r.PlayerName, err)
+				}
+			}
+		}
+	}
+
+	return loadMatches(ctx, st, seasonID, matches)
+}
+
+// loadMatches inserts transformed matches into the store in one batch,
+// replacing any games previously loaded for them.
+func loadMatches(ctx context.Context, s Store, seasonID int64, matches []MatchData) error {
+	r := newMatchResolver(s, seasonID)
+	batch := make([]db.MatchGames, 0, len(matches))
+	for _, data := range matches {
+		mg, err := r.resolve(ctx, data)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, mg)
+	}
+	if err := s.LoadMatchBatch(ctx, batch); err != nil {
+		return fmt.Errorf("load matches: %w", err)
+	}
+	return nil
+}
+
+// A matchResolver resolves the venues and teams of a season's matches to IDs,
+// remembering those it has already resolved.
+type matchResolver struct {
+	store    Store
+	seasonID int64
+	venues   map[VenueRef]int64
+	teams    map[string]int64
+}
+
+func newMatchResolver(s Store, seasonID int64) *matchResolver {
+	return &matchResolver{
+		store:    s,
+		seasonID: seasonID,
+		venues:   make(map[VenueRef]int64),
+		teams:    make(map[string]int64),
+	}
+}
+
+// resolve returns a transformed match ready to be loaded by LoadMatchBatch.
+func (r *matchResolver) resolve(ctx context.Context, data MatchData) (db.MatchGames, error) {
 	var venueID int64
 	if data.Venue.Key != "" {
-		var err error
-		venueID, err = s.UpsertVenue(ctx, data.Venue.Key, data.Venue.Name)
-		if err != nil {
-			return fmt.Errorf("upsert venue %s: %w", data.Venue.Key, err)
+		id, ok := r.venues[data.Venue]
+		if !ok {
+			var err error
+			id, err = r.store.UpsertVenue(ctx, data.Venue.Key, data.Venue.Name)
+			if err != nil {
+				return db.MatchGames{}, fmt.Errorf("upsert venue %s: %w", data.Venue.Key, err)
+			}
+			r.venues[data.Venue] = id
 		}
+		venueID = id
 	}
 
-	homeTeamID, err := s.GetTeamID(ctx, data.HomeKey, seasonID)
+	homeTeamID, err := r.team(ctx, data.HomeKey)
 	if err != nil {
-		return fmt.Errorf("get home team: %w", err)
+		return db.MatchGames{}, fmt.Errorf("get home team: %w", err)
 	}
-	awayTeamID, err := s.GetTeamID(ctx, data.AwayKey, seasonID)
-	if err != nil {
-		return fmt.Errorf("get away team: %w", err)
-	}
-
-	matchID, err := s.UpsertMatch(ctx, db.Match{
-		Key:        data.Key,
-		SeasonID:   seasonID,
-		Week:       data.Week,
-		Date:       data.Date,
-		HomeTeamID: homeTeamID,
-		AwayTeamID: awayTeamID,
-		VenueID:    venueID,
-	})
+	awayTeamID, err := r.team(ctx, data.AwayKey)
 	if err != nil {
-		return fmt.Errorf("upsert match %s: %w", data.Key, err)
+		return db.MatchGames{}, fmt.Errorf("get away team: %w", err)
 	}
 
-	if err := s.DeleteMatchGames(ctx, matchID); err != nil {
-		return fmt.Errorf("delete match games %s: %w", data.Key, err)
+	mg := db.MatchGames{
+		Match: db.Match{
+			Key:        data.Key,
+			SeasonID:   r.seasonID,
+			Week:       data.Week,
+			WeekLabel:  data.WeekLabel,
+			Stage:      data.Stage,
+			Date:       data.Date,
+			HomeTeamID: homeTeamID,
+			AwayTeamID: awayTeamID,
+			VenueID:    venueID,
+		},
+		Games: make([]db.GameResults, 0, len(data.Games)),
 	}
 
-	for _, g := range data.Games {
-		gameID, err := s.InsertGame(ctx, db.Game{
-			MatchID:    matchID,
-			Round:      g.Round,
-			MachineKey: g.MachineKey,
-			IsDoubles:  g.IsDoubles,
-		})
-		if err != nil {
-			return fmt.Errorf("insert game: %w", err)
+	// Matches imported from spreadsheets have no lineups, so anyone who
+	// played a game was in their team's lineup too.
+	inLineup := make(map[string]bool)
+	addToLineup := func(name string, teamID int64) {
+		if name == "" || inLineup[name] {
+			return
 		}
+		inLineup[name] = true
+		mg.Lineup = append(mg.Lineup, db.LineupPlayer{PlayerName: name, TeamID: teamID})
+	}
+	for _, name := range data.HomeLineup {
+		addToLineup(name, homeTeamID)
+	}
+	for _, name := range data.AwayLineup {
+		addToLineup(name, awayTeamID)
+	}
 
-		for _, r := range g.Results {
-			playerID, err := s.UpsertPlayer(ctx, r.PlayerName)
-			if err != nil {
-				return fmt.Errorf("upsert player %s: %w", r.PlayerName, err)
-			}
-
+	for _, g := range data.Games {
+		gr := db.GameResults{
+			Game: db.Game{
+				Round:      g.Round,
+				MachineKey: g.MachineKey,
+				IsDoubles:  g.IsDoubles,
+			},
+			Results: make([]db.PlayerResult, 0, len(g.Results)),
+		}
+		for _, res := range g.Results {
 			teamID := awayTeamID
-			if r.IsHome {
+			if res.IsHome {
 				teamID = homeTeamID
 			}
-
-			if err := s.InsertGameResult(ctx, db.GameResult{
-				GameID:   gameID,
-				PlayerID: playerID,
-				TeamID:   teamID,
-				Position: r.Position,
-				Score:    r.Score,
-			}); err != nil {
-				return fmt.Errorf("insert game result: %w", err)
-			}
+			addToLineup(res.PlayerName, teamID)
+			gr.Results = append(gr.Results, db.PlayerResult{
+				PlayerName: res.PlayerName,
+				TeamID:     teamID,
+				Position:   res.Position,
+				Score:      res.Score,
+				Points:     res.Points,
+			})
 		}
+		mg.Games = append(mg.Games, gr)
 	}
+	return mg, nil
+}
 
-	return nil
+func (r *matchResolver) team(ctx context.Context, key string) (int64, error) {
+	if id, ok := r.teams[key]; ok {
+		return id, nil
+	}
+	id, err := r.store.GetTeamID(ctx, key, r.seasonID)
+	if err != nil {
+		return 0, err
+	}
+	r.teams[key] = id
+	return id, nil
 }
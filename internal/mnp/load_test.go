@@ -11,24 +11,27 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
 )
 
 type MockStore struct {
-	MockUpsertMachine      func(ctx context.Context, m db.Machine) error
-	MockUpsertVenue        func(ctx context.Context, key, name string) (int64, error)
-	MockUpsertVenueMachine func(ctx context.Context, venueID int64, machineKey string) error
-	MockUpsertSeason       func(ctx context.Context, number int) (int64, error)
-	MockUpsertTeam         func(ctx context.Context, t db.Team) (int64, error)
-	MockUpsertPlayer       func(ctx context.Context, name string) (int64, error)
-	MockUpsertRoster       func(ctx context.Context, playerID, teamID int64, role string) error
-	MockUpsertMatch        func(ctx context.Context, m db.Match) (int64, error)
-	MockInsertGame         func(ctx context.Context, g db.Game) (int64, error)
-	MockInsertGameResult   func(ctx context.Context, r db.GameResult) error
-	MockDeleteMatchGames   func(ctx context.Context, matchID int64) error
-	MockGetTeamID          func(ctx context.Context, key string, seasonID int64) (int64, error)
-	MockListMachineKeys    func(ctx context.Context) (map[string]bool, error)
-	MockLoadedSeasons      func(ctx context.Context) (map[int]bool, error)
-	MockUpsertPlayerIPR    func(ctx context.Context, name string, ipr int) error
+	MockUpsertMachine        func(ctx context.Context, m db.Machine) error
+	MockUpsertVenue          func(ctx context.Context, key, name string) (int64, error)
+	MockReplaceVenueMachines func(ctx context.Context, venueID, seasonID int64, machineKeys []string) error
+	MockUpsertSeason         func(ctx context.Context, number int) (int64, error)
+	MockUpsertTeam           func(ctx context.Context, t db.Team) (int64, error)
+	MockUpsertPlayer         func(ctx context.Context, name string) (int64, error)
+	MockUpsertRoster         func(ctx context.Context, playerID, teamID int64, role string) error
+	MockReplaceRoster        func(ctx context.Context, teamID int64, playerIDs []int64, role string) error
+	MockUpsertMatch          func(ctx context.Context, m db.Match) (int64, error)
+	MockLoadMatchBatch       func(ctx context.Context, matches []db.MatchGames) error
+	MockGetTeamID            func(ctx context.Context, key string, seasonID int64) (int64, error)
+	MockListMachineKeys      func(ctx context.Context) (map[string]bool, error)
+	MockLoadedSeasons        func(ctx context.Context) (map[int]bool, error)
+	MockListMatchJSON        func(ctx context.Context, season int) ([]db.MatchJSON, error)
+	MockUpsertPlayerIPR      func(ctx context.Context, name string, ipr int) error
+	MockGetMetadata          func(ctx context.Context, key string) (string, error)
+	MockSetMetadata          func(ctx context.Context, key, value string) error
 }
 
 func (m *MockStore) UpsertMachine(ctx context.Context, machine db.Machine) error {
@@ -39,8 +42,8 @@ func (m *MockStore) UpsertVenue(ctx context.Context, key, name string) (int64, e
 	return m.MockUpsertVenue(ctx, key, name)
 }
 
-func (m *MockStore) UpsertVenueMachine(ctx context.Context, venueID int64, machineKey string) error {
-	return m.MockUpsertVenueMachine(ctx, venueID, machineKey)
+func (m *MockStore) ReplaceVenueMachines(ctx context.Context, venueID, seasonID int64, machineKeys []string) error {
+	return m.MockReplaceVenueMachines(ctx, venueID, seasonID, machineKeys)
 }
 
 func (m *MockStore) UpsertSeason(ctx context.Context, number int) (int64, error) {
@@ -59,20 +62,16 @@ func (m *MockStore) UpsertRoster(ctx context.Context, playerID, teamID int64, ro
 	return m.MockUpsertRoster(ctx, playerID, teamID, role)
 }
 
-func (m *MockStore) UpsertMatch(ctx context.Context, match db.Match) (int64, error) {
-	return m.MockUpsertMatch(ctx, match)
-}
-
-func (m *MockStore) InsertGame(ctx context.Context, g db.Game) (int64, error) {
-	return m.MockInsertGame(ctx, g)
+func (m *MockStore) ReplaceRoster(ctx context.Context, teamID int64, playerIDs []int64, role string) error {
+	return m.MockReplaceRoster(ctx, teamID, playerIDs, role)
 }
 
-func (m *MockStore) InsertGameResult(ctx context.Context, r db.GameResult) error {
-	return m.MockInsertGameResult(ctx, r)
+func (m *MockStore) UpsertMatch(ctx context.Context, match db.Match) (int64, error) {
+	return m.MockUpsertMatch(ctx, match)
 }
 
-func (m *MockStore) DeleteMatchGames(ctx context.Context, matchID int64) error {
-	return m.MockDeleteMatchGames(ctx, matchID)
+func (m *MockStore) LoadMatchBatch(ctx context.Context, matches []db.MatchGames) error {
+	return m.MockLoadMatchBatch(ctx, matches)
 }
 
 func (m *MockStore) GetTeamID(ctx context.Context, key string, seasonID int64) (int64, error) {
@@ -87,10 +86,22 @@ func (m *MockStore) LoadedSeasons(ctx context.Context) (map[int]bool, error) {
 	return m.MockLoadedSeasons(ctx)
 }
 
+func (m *MockStore) ListMatchJSON(ctx context.Context, season int) ([]db.MatchJSON, error) {
+	return m.MockListMatchJSON(ctx, season)
+}
+
 func (m *MockStore) UpsertPlayerIPR(ctx context.Context, name string, ipr int) error {
 	return m.MockUpsertPlayerIPR(ctx, name, ipr)
 }
 
+func (m *MockStore) GetMetadata(ctx context.Context, key string) (string, error) {
+	return m.MockGetMetadata(ctx, key)
+}
+
+func (m *MockStore) SetMetadata(ctx context.Context, key, value string) error {
+	return m.MockSetMetadata(ctx, key, value)
+}
+
 func TestMachinesLoad(t *testing.T) {
 	type args struct {
 		machines Machines
@@ -188,7 +199,7 @@ func TestVenuesLoad(t *testing.T) {
 		want   want
 	}{
 		"Success": {
-			reason: "Venues should be upserted with correct key and name. Only known machines should be associated.",
+			reason: "Venues should be upserted with correct key and name. Only known machines should replace their machines in the supplied season.",
 			args: args{
 				venues: Venues{raw: map[string]venueRawJSON{
 					"add": {Key: "ADD", Name: "Add-a-Ball", Machines: []string{"TAF", "UNKNOWN"}},
@@ -206,12 +217,15 @@ func TestVenuesLoad(t *testing.T) {
 						}
 						return 1, nil
 					},
-					MockUpsertVenueMachine: func(_ context.Context, venueID int64, machineKey string) error {
+					MockReplaceVenueMachines: func(_ context.Context, venueID, seasonID int64, machineKeys []string) error {
 						if diff := cmp.Diff(int64(1), venueID); diff != "" {
-							t.Errorf("UpsertVenueMachine venueID: -want, +got:\n%s", diff)
+							t.Errorf("ReplaceVenueMachines venueID: -want, +got:\n%s", diff)
+						}
+						if diff := cmp.Diff(int64(23), seasonID); diff != "" {
+							t.Errorf("ReplaceVenueMachines seasonID: -want, +got:\n%s", diff)
 						}
-						if diff := cmp.Diff("TAF", machineKey); diff != "" {
-							t.Errorf("UpsertVenueMachine machineKey: -want, +got:\n%s", diff)
+						if diff := cmp.Diff([]string{"TAF"}, machineKeys); diff != "" {
+							t.Errorf("ReplaceVenueMachines machineKeys: -want, +got:\n%s", diff)
 						}
 						return nil
 					},
@@ -250,8 +264,8 @@ func TestVenuesLoad(t *testing.T) {
 			},
 			want: want{err: cmpopts.AnyError},
 		},
-		"UpsertVenueMachineError": {
-			reason: "An error upserting a venue machine should be returned.",
+		"ReplaceVenueMachinesError": {
+			reason: "An error replacing a venue's machines should be returned.",
 			args: args{
 				venues: Venues{raw: map[string]venueRawJSON{
 					"add": {Key: "ADD", Name: "Add-a-Ball", Machines: []string{"TAF"}},
@@ -263,7 +277,7 @@ func TestVenuesLoad(t *testing.T) {
 					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
 						return 1, nil
 					},
-					MockUpsertVenueMachine: func(_ context.Context, _ int64, _ string) error {
+					MockReplaceVenueMachines: func(_ context.Context, _, _ int64, _ []string) error {
 						return errors.New("boom")
 					},
 				},
@@ -274,7 +288,7 @@ func TestVenuesLoad(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := tc.args.venues.Load(context.Background(), tc.args.store)
+			err := tc.args.venues.Load(context.Background(), tc.args.store, 23)
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nVenues.Load(...): -want error, +got error:\n%s", tc.reason, diff)
@@ -347,15 +361,15 @@ func TestSeasonLoad(t *testing.T) {
 						}
 						return 200, nil
 					},
-					MockUpsertRoster: func(_ context.Context, playerID, teamID int64, role string) error {
-						if diff := cmp.Diff(int64(200), playerID); diff != "" {
-							t.Errorf("UpsertRoster playerID: -want, +got:\n%s", diff)
-						}
+					MockReplaceRoster: func(_ context.Context, teamID int64, playerIDs []int64, role string) error {
 						if diff := cmp.Diff(int64(50), teamID); diff != "" {
-							t.Errorf("UpsertRoster teamID: -want, +got:\n%s", diff)
+							t.Errorf("ReplaceRoster teamID: -want, +got:\n%s", diff)
+						}
+						if diff := cmp.Diff([]int64{200}, playerIDs); diff != "" {
+							t.Errorf("ReplaceRoster playerIDs: -want, +got:\n%s", diff)
 						}
 						if diff := cmp.Diff(RolePlayer, role); diff != "" {
-							t.Errorf("UpsertRoster role: -want, +got:\n%s", diff)
+							t.Errorf("ReplaceRoster role: -want, +got:\n%s", diff)
 						}
 						return nil
 					},
@@ -383,6 +397,9 @@ func TestSeasonLoad(t *testing.T) {
 					MockUpsertTeam: func(_ context.Context, _ db.Team) (int64, error) {
 						return 50, nil
 					},
+					MockReplaceRoster: func(_ context.Context, _ int64, _ []int64, _ string) error {
+						return nil
+					},
 				},
 			},
 			want: want{seasonID: 100},
@@ -471,8 +488,8 @@ func TestSeasonLoad(t *testing.T) {
 			},
 			want: want{err: cmpopts.AnyError},
 		},
-		"UpsertRosterError": {
-			reason: "An error upserting a roster entry should be returned.",
+		"ReplaceRosterError": {
+			reason: "An error replacing a team's roster should be returned.",
 			args: args{
 				seasonNum: 25,
 				season: Season{raw: seasonRawJSON{
@@ -498,7 +515,7 @@ func TestSeasonLoad(t *testing.T) {
 					MockUpsertPlayer: func(_ context.Context, _ string) (int64, error) {
 						return 200, nil
 					},
-					MockUpsertRoster: func(_ context.Context, _, _ int64, _ string) error {
+					MockReplaceRoster: func(_ context.Context, _ int64, _ []int64, _ string) error {
 						return errors.New("boom")
 					},
 				},
@@ -525,6 +542,7 @@ func TestMatchLoad(t *testing.T) {
 	type args struct {
 		match    Match
 		seasonID int64
+		format   league.Format
 		store    Store
 	}
 
@@ -540,7 +558,7 @@ func TestMatchLoad(t *testing.T) {
 		Home: teamMatchJSON{
 			Key:    "CRA",
 			Name:   "Crazies",
-			Lineup: []lineupJSON{{Key: "h1", Name: "Alice"}},
+			Lineup: []lineupJSON{{Key: "h1", Name: "Alice"}, {Key: "h2", Name: "Carol"}},
 		},
 		Away: teamMatchJSON{
 			Key:    "PIN",
@@ -559,6 +577,7 @@ func TestMatchLoad(t *testing.T) {
 						Player2: "h1",
 						Score1:  50_000_000,
 						Score2:  30_000_000,
+						Points1: 3,
 					},
 				},
 			},
@@ -571,10 +590,11 @@ func TestMatchLoad(t *testing.T) {
 		want   want
 	}{
 		"Success": {
-			reason: "A match with one singles game should transform and load with the correct venue, teams, match, game, and player result data.",
+			reason: "A match with one singles game should transform and load with the correct venue, teams, match, game, player result, and lineup data, including lineup players who didn't play.",
 			args: args{
 				match:    singlesMatch,
 				seasonID: 100,
+				format:   league.Defaults().For(20),
 				store: &MockStore{
 					MockUpsertVenue: func(_ context.Context, key, name string) (int64, error) {
 						if diff := cmp.Diff("ADD", key); diff != "" {
@@ -594,62 +614,59 @@ func TestMatchLoad(t *testing.T) {
 						}
 						return 60, nil
 					},
-					MockUpsertMatch: func(_ context.Context, got db.Match) (int64, error) {
-						want := db.Match{
-							Key:        "match-1",
-							SeasonID:   100,
-							Week:       3,
-							Date:       "2024-01-15",
-							HomeTeamID: 50,
-							AwayTeamID: 60,
-							VenueID:    10,
-						}
+					MockLoadMatchBatch: func(_ context.Context, got []db.MatchGames) error {
+						want := []db.MatchGames{{
+							Match: db.Match{
+								Key:        "match-1",
+								SeasonID:   100,
+								Week:       3,
+								WeekLabel:  "3",
+								Stage:      db.StageRegular,
+								Date:       "2024-01-15",
+								HomeTeamID: 50,
+								AwayTeamID: 60,
+								VenueID:    10,
+							},
+							Games: []db.GameResults{{
+								Game: db.Game{Round: 2, MachineKey: "TAF", IsDoubles: false},
+								Results: []db.PlayerResult{
+									{PlayerName: "Bob", TeamID: 60, Position: 1, Score: 50_000_000, Points: 3},
+									{PlayerName: "Alice", TeamID: 50, Position: 2, Score: 30_000_000},
+								},
+							}},
+							Lineup: []db.LineupPlayer{
+								{PlayerName: "Alice", TeamID: 50},
+								{PlayerName: "Carol", TeamID: 50},
+								{PlayerName: "Bob", TeamID: 60},
+							},
+						}}
 						if diff := cmp.Diff(want, got); diff != "" {
-							t.Errorf("UpsertMatch(...): -want, +got:\n%s", diff)
-						}
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, matchID int64) error {
-						if diff := cmp.Diff(int64(500), matchID); diff != "" {
-							t.Errorf("DeleteMatchGames matchID: -want, +got:\n%s", diff)
+							t.Errorf("LoadMatchBatch(...): -want, +got:\n%s", diff)
 						}
 						return nil
 					},
-					MockInsertGame: func(_ context.Context, got db.Game) (int64, error) {
-						want := db.Game{
-							MatchID:    500,
-							Round:      2,
-							MachineKey: "TAF",
-							IsDoubles:  false,
-						}
-						if diff := cmp.Diff(want, got); diff != "" {
-							t.Errorf("InsertGame(...): -want, +got:\n%s", diff)
-						}
-						return 1000, nil
+				},
+			},
+			want: want{},
+		},
+		"HistoricalFormat": {
+			reason: "A round the season's format plays as doubles should load as doubles, even if it's a singles round today.",
+			args: args{
+				match:    singlesMatch,
+				seasonID: 100,
+				format:   league.Format{Rounds: 4, DoublesRounds: []int{2, 3}},
+				store: &MockStore{
+					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
+						return 10, nil
 					},
-					MockUpsertPlayer: func(_ context.Context, name string) (int64, error) {
-						switch name {
-						case "Bob":
-							return 201, nil
-						case "Alice":
-							return 200, nil
-						default:
-							t.Errorf("UpsertPlayer called with unexpected name %q", name)
-							return 0, nil
-						}
+					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
+						return 50, nil
 					},
-					MockInsertGameResult: func(_ context.Context, got db.GameResult) error {
-						want := map[int]db.GameResult{
-							1: {GameID: 1000, PlayerID: 201, TeamID: 60, Position: 1, Score: 50_000_000},
-							2: {GameID: 1000, PlayerID: 200, TeamID: 50, Position: 2, Score: 30_000_000},
-						}
-						w, ok := want[got.Position]
-						if !ok {
-							t.Errorf("InsertGameResult called with unexpected position %d", got.Position)
-							return nil
-						}
-						if diff := cmp.Diff(w, got); diff != "" {
-							t.Errorf("InsertGameResult(...): -want, +got:\n%s", diff)
+					MockLoadMatchBatch: func(_ context.Context, got []db.MatchGames) error {
+						for _, g := range got[0].Games {
+							if !g.Game.IsDoubles {
+								t.Errorf("LoadMatchBatch(...): want round %d to be doubles", g.Game.Round)
+							}
 						}
 						return nil
 					},
@@ -675,10 +692,7 @@ func TestMatchLoad(t *testing.T) {
 					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
 						return 50, nil
 					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, _ int64) error {
+					MockLoadMatchBatch: func(_ context.Context, _ []db.MatchGames) error {
 						return nil
 					},
 				},
@@ -736,8 +750,8 @@ func TestMatchLoad(t *testing.T) {
 			},
 			want: want{err: cmpopts.AnyError},
 		},
-		"UpsertMatchError": {
-			reason: "An error upserting the match should be returned.",
+		"LoadMatchBatchError": {
+			reason: "An error loading the match's games should be returned.",
 			args: args{
 				match:    singlesMatch,
 				seasonID: 100,
@@ -748,127 +762,139 @@ func TestMatchLoad(t *testing.T) {
 					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
 						return 50, nil
 					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 0, errors.New("boom")
-					},
-				},
-			},
-			want: want{err: cmpopts.AnyError},
-		},
-		"DeleteMatchGamesError": {
-			reason: "An error deleting old match games should be returned.",
-			args: args{
-				match:    singlesMatch,
-				seasonID: 100,
-				store: &MockStore{
-					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
-						return 10, nil
-					},
-					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
-						return 50, nil
-					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, _ int64) error {
+					MockLoadMatchBatch: func(_ context.Context, _ []db.MatchGames) error {
 						return errors.New("boom")
 					},
 				},
 			},
 			want: want{err: cmpopts.AnyError},
 		},
-		"InsertGameError": {
-			reason: "An error inserting a game should be returned.",
-			args: args{
-				match:    singlesMatch,
-				seasonID: 100,
-				store: &MockStore{
-					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
-						return 10, nil
-					},
-					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
-						return 50, nil
-					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, _ int64) error {
-						return nil
-					},
-					MockInsertGame: func(_ context.Context, _ db.Game) (int64, error) {
-						return 0, errors.New("boom")
-					},
-				},
-			},
-			want: want{err: cmpopts.AnyError},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.args.match.Load(context.Background(), tc.args.store, tc.args.seasonID, tc.args.format)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMatch.Load(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoadMatches(t *testing.T) {
+	type args struct {
+		matches  []MatchData
+		batchErr error
+	}
+
+	type want struct {
+		teams   []db.Team
+		rosters map[string]int64
+		batch   []db.MatchGames
+		err     error
+	}
+
+	match := MatchData{
+		Key:     "mnp-12-1-PYC-CRA",
+		Week:    1,
+		Date:    "2015-01-12",
+		Venue:   VenueRef{Key: "ADD", Name: "Add-a-Ball"},
+		HomeKey: "CRA",
+		AwayKey: "PYC",
+		Games: []GameData{
+			{Round: 2, MachineKey: "TAF", Results: []ResultData{
+				{PlayerName: "Alice", Score: 100, Points: 3, Position: 1, IsHome: true},
+				{PlayerName: "Bob", Score: 50, Position: 2},
+			}},
 		},
-		"UpsertPlayerError": {
-			reason: "An error upserting a player from game results should be returned.",
-			args: args{
-				match:    singlesMatch,
-				seasonID: 100,
-				store: &MockStore{
-					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
-						return 10, nil
-					},
-					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
-						return 50, nil
-					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, _ int64) error {
-						return nil
-					},
-					MockInsertGame: func(_ context.Context, _ db.Game) (int64, error) {
-						return 1000, nil
-					},
-					MockUpsertPlayer: func(_ context.Context, _ string) (int64, error) {
-						return 0, errors.New("boom")
-					},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Teams should be created with the venue they hosted at, rosters and lineups built from who played, and games loaded.",
+			args:   args{matches: []MatchData{match}},
+			want: want{
+				teams: []db.Team{
+					{Key: "CRA", Name: "CRA", SeasonID: 100, HomeVenueID: 10},
+					{Key: "PYC", Name: "PYC", SeasonID: 100},
 				},
+				rosters: map[string]int64{"Alice": 1, "Bob": 2},
+				batch: []db.MatchGames{{
+					Match: db.Match{Key: "mnp-12-1-PYC-CRA", SeasonID: 100, Week: 1, Date: "2015-01-12", HomeTeamID: 1, AwayTeamID: 2, VenueID: 10},
+					Games: []db.GameResults{{
+						Game: db.Game{Round: 2, MachineKey: "TAF"},
+						Results: []db.PlayerResult{
+							{PlayerName: "Alice", TeamID: 1, Position: 1, Score: 100, Points: 3},
+							{PlayerName: "Bob", TeamID: 2, Position: 2, Score: 50},
+						},
+					}},
+					Lineup: []db.LineupPlayer{{PlayerName: "Alice", TeamID: 1}, {PlayerName: "Bob", TeamID: 2}},
+				}},
 			},
-			want: want{err: cmpopts.AnyError},
 		},
-		"InsertGameResultError": {
-			reason: "An error inserting a game result should be returned.",
-			args: args{
-				match:    singlesMatch,
-				seasonID: 100,
-				store: &MockStore{
-					MockUpsertVenue: func(_ context.Context, _, _ string) (int64, error) {
-						return 10, nil
-					},
-					MockGetTeamID: func(_ context.Context, _ string, _ int64) (int64, error) {
-						return 50, nil
-					},
-					MockUpsertMatch: func(_ context.Context, _ db.Match) (int64, error) {
-						return 500, nil
-					},
-					MockDeleteMatchGames: func(_ context.Context, _ int64) error {
-						return nil
-					},
-					MockInsertGame: func(_ context.Context, _ db.Game) (int64, error) {
-						return 1000, nil
-					},
-					MockUpsertPlayer: func(_ context.Context, _ string) (int64, error) {
-						return 200, nil
-					},
-					MockInsertGameResult: func(_ context.Context, _ db.GameResult) error {
-						return errors.New("boom")
-					},
+		"LoadMatchBatchError": {
+			reason: "An error loading the matches should be returned.",
+			args:   args{matches: []MatchData{{Key: "mnp-12-1-PYC-CRA", HomeKey: "CRA", AwayKey: "PYC"}}, batchErr: errors.New("boom")},
+			want: want{
+				teams: []db.Team{
+					{Key: "CRA", Name: "CRA", SeasonID: 100},
+					{Key: "PYC", Name: "PYC", SeasonID: 100},
 				},
+				rosters: map[string]int64{},
+				err:     cmpopts.AnyError,
 			},
-			want: want{err: cmpopts.AnyError},
 		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := tc.args.match.Load(context.Background(), tc.args.store, tc.args.seasonID)
+			var teams []db.Team
+			var batch []db.MatchGames
+			rosters := map[string]int64{}
+			teamIDs := map[string]int64{"CRA": 1, "PYC": 2}
+			playerIDs := map[string]int64{"Alice": 200, "Bob": 201}
+			playerNames := map[int64]string{200: "Alice", 201: "Bob"}
+
+			s := &MockStore{
+				MockUpsertSeason: func(_ context.Context, _ int) (int64, error) { return 100, nil },
+				MockUpsertVenue:  func(_ context.Context, _, _ string) (int64, error) { return 10, nil },
+				MockUpsertTeam: func(_ context.Context, t db.Team) (int64, error) {
+					teams = append(teams, t)
+					return teamIDs[t.Key], nil
+				},
+				MockUpsertPlayer: func(_ context.Context, name string) (int64, error) { return playerIDs[name], nil },
+				MockUpsertRoster: func(_ context.Context, playerID, teamID int64, _ string) error {
+					rosters[playerNames[playerID]] = teamID
+					return nil
+				},
+				MockGetTeamID: func(_ context.Context, key string, _ int64) (int64, error) { return teamIDs[key], nil },
+				MockLoadMatchBatch: func(_ context.Context, matches []db.MatchGames) error {
+					if tc.args.batchErr != nil {
+						return tc.args.batchErr
+					}
+					batch = matches
+					return nil
+				},
+			}
+
+			err := LoadMatches(context.Background(), s, 12, tc.args.matches)
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\nMatch.Load(...): -want error, +got error:\n%s", tc.reason, diff)
+				t.Errorf("\n%s\nLoadMatches(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.teams, teams); diff != "" {
+				t.Errorf("\n%s\nLoadMatches(...): -want teams, +got teams:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rosters, rosters); diff != "" {
+				t.Errorf("\n%s\nLoadMatches(...): -want rosters, +got rosters:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.batch, batch); diff != "" {
+				t.Errorf("\n%s\nLoadMatches(...): -want batch, +got batch:\n%s", tc.reason, diff)
 			}
 		})
 	}
@@ -901,6 +927,18 @@ func TestScheduleTransform(t *testing.T) {
 					},
 				},
 			},
+			{
+				N:    "SF",
+				Date: "03/02/2026",
+				Matches: []weekMatchJSON{
+					{
+						MatchKey: "mnp-23-SF-CRA-PIN",
+						AwayKey:  "CRA",
+						HomeKey:  "PIN",
+						Venue:    venueRefJSON{Key: "ADD", Name: "Add-a-Ball"},
+					},
+				},
+			},
 		},
 	}}
 
@@ -908,20 +946,35 @@ func TestScheduleTransform(t *testing.T) {
 
 	want := []ScheduleMatchData{
 		{
-			Key:     "mnp-23-3-CRA-PIN",
-			Week:    3,
-			Date:    "2026-02-16",
-			HomeKey: "PIN",
-			AwayKey: "CRA",
-			Venue:   VenueRef{Key: "ADD", Name: "Add-a-Ball"},
+			Key:       "mnp-23-3-CRA-PIN",
+			Week:      3,
+			WeekLabel: "3",
+			Stage:     db.StageRegular,
+			Date:      "2026-02-16",
+			HomeKey:   "PIN",
+			AwayKey:   "CRA",
+			Venue:     VenueRef{Key: "ADD", Name: "Add-a-Ball"},
 		},
 		{
-			Key:     "mnp-23-4-PIN-CRA",
-			Week:    4,
-			Date:    "2026-02-23",
-			HomeKey: "CRA",
-			AwayKey: "PIN",
-			Venue:   VenueRef{Key: "ANC", Name: "Another Castle"},
+			Key:       "mnp-23-4-PIN-CRA",
+			Week:      4,
+			WeekLabel: "4",
+			Stage:     db.StageRegular,
+			Date:      "2026-02-23",
+			HomeKey:   "CRA",
+			AwayKey:   "PIN",
+			Venue:     VenueRef{Key: "ANC", Name: "Another Castle"},
+		},
+		{
+			// Playoff weeks are numbered on from the regular season.
+			Key:       "mnp-23-SF-CRA-PIN",
+			Week:      5,
+			WeekLabel: "SF",
+			Stage:     db.StagePlayoffs,
+			Date:      "2026-03-02",
+			HomeKey:   "PIN",
+			AwayKey:   "CRA",
+			Venue:     VenueRef{Key: "ADD", Name: "Add-a-Ball"},
 		},
 	}
 
@@ -992,6 +1045,8 @@ func TestScheduleLoad(t *testing.T) {
 							Key:        "mnp-23-3-CRA-PIN",
 							SeasonID:   100,
 							Week:       3,
+							WeekLabel:  "3",
+							Stage:      db.StageRegular,
 							Date:       "2026-02-16",
 							HomeTeamID: 50,
 							AwayTeamID: 60,
@@ -1261,6 +1316,44 @@ func TestIPRsLoad(t *testing.T) {
 	}
 }
 
+func TestParseWeek(t *testing.T) {
+	type want struct {
+		week  int
+		stage db.Stage
+	}
+	cases := map[string]struct {
+		reason string
+		label  string
+		want   want
+	}{
+		"Numbered": {
+			reason: "A numbered week should be a regular season week.",
+			label:  "3",
+			want:   want{week: 3, stage: db.StageRegular},
+		},
+		"Named": {
+			reason: "A named week should be a playoff week, with no number.",
+			label:  "SF",
+			want:   want{week: 0, stage: db.StagePlayoffs},
+		},
+		"Empty": {
+			reason: "A missing week should be treated as the regular season.",
+			label:  "",
+			want:   want{week: 0, stage: db.StageRegular},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			week, stage := parseWeek(tc.label)
+
+			if diff := cmp.Diff(tc.want, want{week: week, stage: stage}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nparseWeek(%q): -want, +got:\n%s", tc.reason, tc.label, diff)
+			}
+		})
+	}
+}
+
 func TestIsoDate(t *testing.T) {
 	cases := map[string]struct {
 		reason string
@@ -12,8 +12,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
 )
 
 const (
@@ -45,17 +49,35 @@ func WithStore(s Store) ClientOption {
 	}
 }
 
+// WithFormats sets the format each season was played in. Defaults to
+// league.Defaults().
+func WithFormats(f league.Formats) ClientOption {
+	return func(c *Client) {
+		c.formats = f
+	}
+}
+
+// WithoutIPRs skips loading the archive's IPR.csv, e.g. because IPRs are
+// loaded from another source that the archive's would overwrite.
+func WithoutIPRs() ClientOption {
+	return func(c *Client) {
+		c.skipIPRs = true
+	}
+}
+
 // Client syncs and loads MNP archive data.
 type Client struct {
 	archivePath string
 	repoURL     string
 	log         *slog.Logger
 	store       Store
+	formats     league.Formats
+	skipIPRs    bool
 }
 
 // NewClient creates a new MNP archive client.
 func NewClient(archivePath string, opts ...ClientOption) *Client {
-	c := &Client{archivePath: archivePath}
+	c := &Client{archivePath: archivePath, formats: league.Defaults()}
 	for _, o := range opts {
 		o(c)
 	}
@@ -64,6 +86,8 @@ func NewClient(archivePath string, opts ...ClientOption) *Client {
 
 // SyncIfStale syncs the git repo and loads any seasons that need updating.
 // A season needs loading if: forced, not yet loaded, or is the current (max) season.
+// Loading is split into stages, and a sync that's interrupted resumes after the
+// last stage it finished, as long as the archive hasn't changed since.
 func (c *Client) SyncIfStale(ctx context.Context, force bool) error {
 	if c.store == nil {
 		return fmt.Errorf("no store configured")
@@ -73,6 +97,16 @@ func (c *Client) SyncIfStale(ctx context.Context, force bool) error {
 		return fmt.Errorf("sync MNP archive: %w", err)
 	}
 
+	commit, when, err := archiveHead(c.archivePath)
+	if err != nil {
+		return fmt.Errorf("get archive commit: %w", err)
+	}
+
+	cp, err := c.loadCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
 	loaded, err := c.store.LoadedSeasons(ctx)
 	if err != nil {
 		return fmt.Errorf("check loaded seasons: %w", err)
@@ -86,10 +120,17 @@ func (c *Client) SyncIfStale(ctx context.Context, force bool) error {
 		return nil
 	}
 
+	// Seasons an interrupted sync was loading may have been partly loaded, so
+	// they need loading again even if they now look loaded.
+	resuming := make(map[int]bool, len(cp.Seasons))
+	for _, s := range cp.Seasons {
+		resuming[s] = true
+	}
+
 	maxSeason := available[len(available)-1]
 	var seasons []int
 	for _, s := range available {
-		if force || !loaded[s] || s == maxSeason {
+		if force || !loaded[s] || s == maxSeason || resuming[s] {
 			seasons = append(seasons, s)
 		}
 	}
@@ -97,16 +138,41 @@ func (c *Client) SyncIfStale(ctx context.Context, force bool) error {
 		return nil
 	}
 
-	if err := c.extractAndLoad(ctx, seasons); err != nil {
+	switch {
+	case cp.Commit != commit:
+		// The archive changed, so what the interrupted sync loaded is stale.
+		cp.Done = nil
+	case len(cp.Done) > 0:
+		c.log.Info("Resuming interrupted sync", "finished", len(cp.Done))
+	}
+	cp.Commit, cp.Seasons = commit, seasons
+
+	if err := c.runStages(ctx, cp, c.stages(seasons)); err != nil {
 		return err
 	}
+	return c.recordFreshness(ctx, commit, when)
+}
 
-	return nil
+// stages returns the stages that load the supplied seasons from the archive:
+// machines, venues, and IPRs first, then each season's teams, schedule, and
+// matches. The last season must be the latest, which venues' current machines
+// are associated with.
+func (c *Client) stages(seasons []int) []stage {
+	latest := seasons[len(seasons)-1]
+	stages := []stage{{name: "globals", run: func(ctx context.Context) error { return c.loadGlobals(ctx, latest) }}}
+	for _, n := range seasons {
+		stages = append(stages,
+			stage{name: fmt.Sprintf("season/%d", n), run: func(ctx context.Context) error { return c.loadSeason(ctx, n) }},
+			stage{name: fmt.Sprintf("schedule/%d", n), run: func(ctx context.Context) error { return c.loadSchedule(ctx, n) }},
+			stage{name: fmt.Sprintf("matches/%d", n), run: func(ctx context.Context) error { return c.loadMatches(ctx, n) }},
+		)
+	}
+	return stages
 }
 
-// extractAndLoad reads JSON files from the archive and loads them into the
-// store using the ETL types.
-func (c *Client) extractAndLoad(ctx context.Context, seasons []int) error {
+// loadGlobals loads the machines, venues, and IPRs that aren't specific to a
+// season. Venues' machines are those at each venue in the latest season.
+func (c *Client) loadGlobals(ctx context.Context, latest int) error {
 	// Machines.
 	var machines Machines
 	if err := machines.Extract(filepath.Join(c.archivePath, "machines.json")); err != nil {
@@ -121,11 +187,18 @@ func (c *Client) extractAndLoad(ctx context.Context, seasons []int) error {
 	if err := venues.Extract(filepath.Join(c.archivePath, "venues.json")); err != nil {
 		return fmt.Errorf("extract venues: %w", err)
 	}
-	if err := venues.Load(ctx, c.store); err != nil {
+	seasonID, err := c.store.UpsertSeason(ctx, latest)
+	if err != nil {
+		return fmt.Errorf("get season %d: %w", latest, err)
+	}
+	if err := venues.Load(ctx, c.store, seasonID); err != nil {
 		return fmt.Errorf("load venues: %w", err)
 	}
 
 	// IPRs.
+	if c.skipIPRs {
+		return nil
+	}
 	var iprs IPRs
 	if err := iprs.Extract(filepath.Join(c.archivePath, "IPR.csv")); err != nil {
 		return fmt.Errorf("extract IPRs: %w", err)
@@ -134,45 +207,150 @@ func (c *Client) extractAndLoad(ctx context.Context, seasons []int) error {
 		return fmt.Errorf("load IPRs: %w", err)
 	}
 
-	// Seasons and matches.
-	for _, seasonNum := range seasons {
-		c.log.Info("Loading season", "season", seasonNum)
-		seasonPath := filepath.Join(c.archivePath, fmt.Sprintf("season-%d", seasonNum))
+	return nil
+}
+
+func (c *Client) seasonPath(n int) string {
+	return filepath.Join(c.archivePath, fmt.Sprintf("season-%d", n))
+}
+
+// loadSeason loads a season's teams and rosters.
+func (c *Client) loadSeason(ctx context.Context, n int) error {
+	c.log.Info("Loading season", "season", n)
 
-		var season Season
-		if err := season.Extract(filepath.Join(seasonPath, "season.json")); err != nil {
-			return fmt.Errorf("extract season %d: %w", seasonNum, err)
+	var season Season
+	if err := season.Extract(filepath.Join(c.seasonPath(n), "season.json")); err != nil {
+		return fmt.Errorf("extract season %d: %w", n, err)
+	}
+	if _, err := season.Load(ctx, c.store, n); err != nil {
+		return fmt.Errorf("load season %d: %w", n, err)
+	}
+	return nil
+}
+
+// loadSchedule loads a season's schedule. The season must already be loaded.
+func (c *Client) loadSchedule(ctx context.Context, n int) error {
+	seasonID, err := c.store.UpsertSeason(ctx, n)
+	if err != nil {
+		return fmt.Errorf("get season %d: %w", n, err)
+	}
+
+	var schedule Schedule
+	if err := schedule.Extract(filepath.Join(c.seasonPath(n), "season.json")); err != nil {
+		return fmt.Errorf("extract schedule %d: %w", n, err)
+	}
+	if err := schedule.Load(ctx, c.store, seasonID); err != nil {
+		return fmt.Errorf("load schedule %d: %w", n, err)
+	}
+	return nil
+}
+
+// loadMatches loads a season's played matches. The season must already be
+// loaded.
+func (c *Client) loadMatches(ctx context.Context, n int) error {
+	seasonID, err := c.store.UpsertSeason(ctx, n)
+	if err != nil {
+		return fmt.Errorf("get season %d: %w", n, err)
+	}
+
+	matchFiles, err := findMatchFiles(c.seasonPath(n))
+	if err != nil {
+		return fmt.Errorf("find matches for season %d: %w", n, err)
+	}
+
+	// Load the season's matches in one batch. Inserting them a row at a
+	// time takes minutes for a full re-sync.
+	format := c.formats.For(n)
+	r := newMatchResolver(c.store, seasonID)
+	batch := make([]db.MatchGames, 0, len(matchFiles))
+	for _, path := range matchFiles {
+		var match Match
+		if err := match.Extract(path); err != nil {
+			c.log.Warn("Failed to extract match", "file", filepath.Base(path), "error", err)
+			continue
 		}
-		seasonID, err := season.Load(ctx, c.store, seasonNum)
+		mg, err := r.resolve(ctx, match.Transform(format))
 		if err != nil {
-			return fmt.Errorf("load season %d: %w", seasonNum, err)
+			c.log.Warn("Failed to load match", "file", filepath.Base(path), "error", err)
+			continue
 		}
+		mg.JSON = match.JSON()
+		batch = append(batch, mg)
+	}
+	if err := c.store.LoadMatchBatch(ctx, batch); err != nil {
+		return fmt.Errorf("load matches for season %d: %w", n, err)
+	}
+	return nil
+}
 
-		var schedule Schedule
-		if err := schedule.Extract(filepath.Join(seasonPath, "season.json")); err != nil {
-			return fmt.Errorf("extract schedule %d: %w", seasonNum, err)
+// Retransform loads the supplied seasons' matches again from the match JSON
+// stored when they were last synced, rather than from the archive. With no
+// seasons it retransforms every loaded season. This applies fixes to Transform
+// without walking the archive. It returns how many matches it loaded.
+func (c *Client) Retransform(ctx context.Context, seasons ...int) (int, error) {
+	if c.store == nil {
+		return 0, fmt.Errorf("no store configured")
+	}
+
+	if len(seasons) == 0 {
+		loaded, err := c.store.LoadedSeasons(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("check loaded seasons: %w", err)
 		}
-		if err := schedule.Load(ctx, c.store, seasonID); err != nil {
-			return fmt.Errorf("load schedule %d: %w", seasonNum, err)
+		for n := range loaded {
+			seasons = append(seasons, n)
 		}
+		sort.Ints(seasons)
+	}
 
-		matchFiles, err := findMatchFiles(seasonPath)
+	total := 0
+	for _, n := range seasons {
+		loaded, err := c.retransformSeason(ctx, n)
 		if err != nil {
-			return fmt.Errorf("find matches for season %d: %w", seasonNum, err)
-		}
-		for _, path := range matchFiles {
-			var match Match
-			if err := match.Extract(path); err != nil {
-				c.log.Warn("Failed to extract match", "file", filepath.Base(path), "error", err)
-				continue
-			}
-			if err := match.Load(ctx, c.store, seasonID); err != nil {
-				c.log.Warn("Failed to load match", "file", filepath.Base(path), "error", err)
-			}
+			return total, err
 		}
+		c.log.Info("Retransformed season", "season", n, "matches", loaded)
+		total += loaded
 	}
+	return total, nil
+}
 
-	return nil
+// retransformSeason loads a season's matches from their stored JSON, and
+// returns how many it loaded.
+func (c *Client) retransformSeason(ctx context.Context, n int) (int, error) {
+	stored, err := c.store.ListMatchJSON(ctx, n)
+	if err != nil {
+		return 0, fmt.Errorf("list match JSON for season %d: %w", n, err)
+	}
+	if len(stored) == 0 {
+		return 0, nil
+	}
+
+	seasonID, err := c.store.UpsertSeason(ctx, n)
+	if err != nil {
+		return 0, fmt.Errorf("get season %d: %w", n, err)
+	}
+
+	format := c.formats.For(n)
+	r := newMatchResolver(c.store, seasonID)
+	batch := make([]db.MatchGames, 0, len(stored))
+	for _, mj := range stored {
+		var match Match
+		if err := match.Decode(mj.JSON); err != nil {
+			c.log.Warn("Failed to decode match", "match", mj.Key, "error", err)
+			continue
+		}
+		mg, err := r.resolve(ctx, match.Transform(format))
+		if err != nil {
+			c.log.Warn("Failed to load match", "match", mj.Key, "error", err)
+			continue
+		}
+		batch = append(batch, mg)
+	}
+	if err := c.store.LoadMatchBatch(ctx, batch); err != nil {
+		return 0, fmt.Errorf("load matches for season %d: %w", n, err)
+	}
+	return len(batch), nil
 }
 
 // findMatchFiles returns paths to all match JSON files in a season directory.
@@ -254,6 +432,39 @@ func (c *Client) pull(ctx context.Context) error {
 	return err
 }
 
+// archiveHead returns the hash of the archive's checked out commit, and when
+// it was committed.
+func archiveHead(archivePath string) (string, time.Time, error) {
+	r, err := git.PlainOpen(archivePath)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("open repo: %w", err)
+	}
+	ref, err := r.Head()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get HEAD: %w", err)
+	}
+	commit, err := r.CommitObject(ref.Hash())
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get HEAD commit: %w", err)
+	}
+	return ref.Hash().String(), commit.Committer.When, nil
+}
+
+// recordFreshness records the archive commit just loaded, and when it was
+// committed, so users can tell how fresh their data is.
+func (c *Client) recordFreshness(ctx context.Context, commit string, when time.Time) error {
+	for key, value := range map[string]string{
+		db.MetadataArchiveCommit:     commit,
+		db.MetadataArchiveCommitTime: when.UTC().Format(time.RFC3339),
+		db.MetadataLastSync:          time.Now().UTC().Format(time.RFC3339),
+	} {
+		if err := c.store.SetMetadata(ctx, key, value); err != nil {
+			return fmt.Errorf("record archive freshness: %w", err)
+		}
+	}
+	return nil
+}
+
 func (c *Client) update(ctx context.Context, progress io.Writer) error {
 	r, err := git.PlainOpen(c.archivePath)
 	if err != nil {
@@ -0,0 +1,124 @@
+package mnp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+func TestRetransform(t *testing.T) {
+	match := `{
+		"key": "mnp-22-1-PIN-CRA",
+		"week": "1",
+		"date": "01/15/2024",
+		"home": {"key": "CRA", "lineup": [{"key": "h1", "name": "Alice"}]},
+		"away": {"key": "PIN", "lineup": [{"key": "a1", "name": "Bob"}]},
+		"rounds": [{"n": 2, "games": [{"n": 1, "machine": "TAF", "done": true, "player_1": "a1", "player_2": "h1", "score_1": 100, "score_2": 200, "points_2": 5}]}]
+	}`
+
+	type args struct {
+		seasons []int
+		stored  map[int][]db.MatchJSON
+		loadErr error
+	}
+	type want struct {
+		n     int
+		batch []db.MatchGames
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EveryLoadedSeason": {
+			reason: "With no seasons, every loaded season's stored matches should be transformed and loaded again, without storing their JSON again. Matches that can't be decoded should be skipped.",
+			args: args{
+				stored: map[int][]db.MatchJSON{
+					22: {
+						{Key: "mnp-22-1-PIN-CRA", Season: 22, JSON: []byte(match)},
+						{Key: "mnp-22-2-PIN-CRA", Season: 22, JSON: []byte(`{`)},
+					},
+				},
+			},
+			want: want{
+				n: 1,
+				batch: []db.MatchGames{{
+					Match: db.Match{Key: "mnp-22-1-PIN-CRA", SeasonID: 22, Week: 1, WeekLabel: "1", Stage: db.StageRegular, Date: "2024-01-15", HomeTeamID: 1, AwayTeamID: 2},
+					Games: []db.GameResults{{
+						Game: db.Game{Round: 2, MachineKey: "TAF"},
+						Results: []db.PlayerResult{
+							{PlayerName: "Bob", TeamID: 2, Position: 1, Score: 100},
+							{PlayerName: "Alice", TeamID: 1, Position: 2, Score: 200, Points: 5},
+						},
+					}},
+					Lineup: []db.LineupPlayer{{PlayerName: "Alice", TeamID: 1}, {PlayerName: "Bob", TeamID: 2}},
+				}},
+			},
+		},
+		"NoStoredJSON": {
+			reason: "A season with no stored JSON should be skipped.",
+			args: args{
+				seasons: []int{21},
+			},
+			want: want{n: 0},
+		},
+		"LoadError": {
+			reason: "An error loading a season's matches should be returned.",
+			args: args{
+				seasons: []int{22},
+				stored:  map[int][]db.MatchJSON{22: {{Key: "mnp-22-1-PIN-CRA", Season: 22, JSON: []byte(match)}}},
+				loadErr: errors.New("boom"),
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var batch []db.MatchGames
+			c := NewClient(t.TempDir(),
+				WithLogger(slog.New(slog.DiscardHandler)),
+				WithStore(&MockStore{
+					MockLoadedSeasons: func(_ context.Context) (map[int]bool, error) {
+						return map[int]bool{21: true, 22: true}, nil
+					},
+					MockListMatchJSON: func(_ context.Context, season int) ([]db.MatchJSON, error) {
+						return tc.args.stored[season], nil
+					},
+					MockUpsertSeason: func(_ context.Context, number int) (int64, error) {
+						return int64(number), nil
+					},
+					MockGetTeamID: func(_ context.Context, key string, _ int64) (int64, error) {
+						return map[string]int64{"CRA": 1, "PIN": 2}[key], nil
+					},
+					MockLoadMatchBatch: func(_ context.Context, matches []db.MatchGames) error {
+						batch = append(batch, matches...)
+						return tc.args.loadErr
+					},
+				}),
+			)
+
+			n, err := c.Retransform(context.Background(), tc.args.seasons...)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRetransform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.n, n); diff != "" {
+				t.Errorf("\n%s\nRetransform(...): -want matches, +got matches:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.batch, batch, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nRetransform(...): -want loaded, +got loaded:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package mnp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MetadataCheckpoint is the sync metadata key of an unfinished sync's
+// checkpoint. It's empty when the last sync finished.
+const MetadataCheckpoint = "mnp_sync_checkpoint"
+
+// A stage is one step of loading the archive, such as loading a season's
+// schedule.
+type stage struct {
+	name string // Unique within a sync, e.g. "schedule/22".
+	run  func(ctx context.Context) error
+}
+
+// A checkpoint records how far an unfinished sync got, so the next sync can
+// resume rather than start over.
+type checkpoint struct {
+	Commit  string   `json:"commit"`  // The archive commit being loaded.
+	Seasons []int    `json:"seasons"` // The seasons being loaded.
+	Done    []string `json:"done"`    // The stages that finished.
+}
+
+// loadCheckpoint returns the checkpoint of an unfinished sync, or an empty
+// checkpoint if the last sync finished.
+func (c *Client) loadCheckpoint(ctx context.Context) (*checkpoint, error) {
+	v, err := c.store.GetMetadata(ctx, MetadataCheckpoint)
+	if err != nil {
+		return nil, fmt.Errorf("get sync checkpoint: %w", err)
+	}
+	cp := &checkpoint{}
+	if v == "" {
+		return cp, nil
+	}
+	if err := json.Unmarshal([]byte(v), cp); err != nil {
+		// A checkpoint we can't read only costs us a full sync.
+		c.log.Warn("Ignoring unreadable sync checkpoint", "error", err)
+		return &checkpoint{}, nil
+	}
+	return cp, nil
+}
+
+func (c *Client) saveCheckpoint(ctx context.Context, cp *checkpoint) error {
+	v, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encode sync checkpoint: %w", err)
+	}
+	if err := c.store.SetMetadata(ctx, MetadataCheckpoint, string(v)); err != nil {
+		return fmt.Errorf("save sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// runStages runs stages in order, skipping any the checkpoint records as
+// done. It checkpoints each stage as it finishes, and clears the checkpoint
+// once every stage has. It logs how long each stage took.
+func (c *Client) runStages(ctx context.Context, cp *checkpoint, stages []stage) error {
+	if err := c.saveCheckpoint(ctx, cp); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	for _, s := range stages {
+		if slices.Contains(cp.Done, s.name) {
+			c.log.Debug("Skipping finished stage", "stage", s.name)
+			continue
+		}
+
+		began := time.Now()
+		if err := s.run(ctx); err != nil {
+			return err
+		}
+		c.log.Info("Finished stage", "stage", s.name, "duration", time.Since(began).Round(time.Millisecond))
+
+		cp.Done = append(cp.Done, s.name)
+		if err := c.saveCheckpoint(ctx, cp); err != nil {
+			return err
+		}
+	}
+	c.log.Info("Loaded MNP archive", "stages", len(stages), "duration", time.Since(start).Round(time.Millisecond))
+
+	if err := c.store.SetMetadata(ctx, MetadataCheckpoint, ""); err != nil {
+		return fmt.Errorf("clear sync checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package mnp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRunStages(t *testing.T) {
+	type want struct {
+		ran      []string
+		metadata string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason     string
+		checkpoint checkpoint
+		fail       string // The stage to fail, if any.
+		want       want
+	}{
+		"Fresh": {
+			reason:     "Every stage should run in order, and the checkpoint be cleared once they have.",
+			checkpoint: checkpoint{Commit: "abc", Seasons: []int{22}},
+			want: want{
+				ran: []string{"globals", "season/22", "schedule/22", "matches/22"},
+			},
+		},
+		"Resume": {
+			reason:     "Stages the checkpoint records as done should be skipped.",
+			checkpoint: checkpoint{Commit: "abc", Seasons: []int{22}, Done: []string{"globals", "season/22"}},
+			want: want{
+				ran: []string{"schedule/22", "matches/22"},
+			},
+		},
+		"Interrupted": {
+			reason:     "A failed stage should leave a checkpoint recording the stages that finished before it.",
+			checkpoint: checkpoint{Commit: "abc", Seasons: []int{22}},
+			fail:       "schedule/22",
+			want: want{
+				ran:      []string{"globals", "season/22", "schedule/22"},
+				metadata: `{"commit":"abc","seasons":[22],"done":["globals","season/22"]}`,
+				err:      cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			metadata := map[string]string{}
+			c := NewClient(t.TempDir(),
+				WithLogger(slog.New(slog.DiscardHandler)),
+				WithStore(&MockStore{
+					MockSetMetadata: func(_ context.Context, key, value string) error {
+						metadata[key] = value
+						return nil
+					},
+				}),
+			)
+
+			var ran []string
+			var stages []stage
+			for _, name := range []string{"globals", "season/22", "schedule/22", "matches/22"} {
+				stages = append(stages, stage{name: name, run: func(context.Context) error {
+					ran = append(ran, name)
+					if name == tc.fail {
+						return errors.New("boom")
+					}
+					return nil
+				}})
+			}
+
+			cp := tc.checkpoint
+			err := c.runStages(context.Background(), &cp, stages)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nrunStages(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ran, ran); diff != "" {
+				t.Errorf("\n%s\nrunStages(...): -want stages run, +got stages run:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.metadata, metadata[MetadataCheckpoint]); diff != "" {
+				t.Errorf("\n%s\nrunStages(...): -want checkpoint, +got checkpoint:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		value  string
+		want   *checkpoint
+	}{
+		"None": {
+			reason: "No checkpoint should return an empty checkpoint.",
+			want:   &checkpoint{},
+		},
+		"Saved": {
+			reason: "A saved checkpoint should be returned.",
+			value:  `{"commit":"abc","seasons":[21,22],"done":["globals"]}`,
+			want:   &checkpoint{Commit: "abc", Seasons: []int{21, 22}, Done: []string{"globals"}},
+		},
+		"Unreadable": {
+			reason: "An unreadable checkpoint should be ignored, costing a full sync.",
+			value:  `{"commit":`,
+			want:   &checkpoint{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(t.TempDir(),
+				WithLogger(slog.New(slog.DiscardHandler)),
+				WithStore(&MockStore{
+					MockGetMetadata: func(_ context.Context, _ string) (string, error) {
+						return tc.value, nil
+					},
+				}),
+			)
+			got, err := c.loadCheckpoint(context.Background())
+			if err != nil {
+				t.Fatalf("loadCheckpoint: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nloadCheckpoint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,583 @@
+// Package opdb loads machine manufacturers and years from the Open Pinball
+// Database (OPDB). The MNP archive only names machines, and OPDB adds new
+// titles quickly, including ones that show up at venues mid-season.
+package opdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// Sync metadata keys. Sync records where machines were last loaded from, and
+// when.
+const (
+	MetadataSource   = "opdb_source"
+	MetadataLastSync = "opdb_last_sync"
+)
+
+// SourceAPI is the source that fetches OPDB's export API, which needs an API
+// token. Any other source is a file or http(s) URL of a saved export.
+const SourceAPI = "opdb"
+
+// ExportURL is OPDB's export API. It returns every machine OPDB knows.
+const ExportURL = "https://opdb.org/api/export"
+
+// MaxAge is how long SyncIfStale considers a sync fresh. OPDB rate limits its
+// export API, and new machines are rare enough that a day is plenty.
+const MaxAge = 24 * time.Hour
+
+// maxSourceBytes is the largest source Sync will read. OPDB's export of every
+// machine is a few megabytes.
+const maxSourceBytes = 64 << 20
+
+// ErrNoToken is returned when syncing from OPDB's API without a token.
+var ErrNoToken = errors.New("OPDB's export API needs an API token; set one with --opdb-token")
+
+// A Machine is a machine in an OPDB export.
+type Machine struct {
+	ID              string       `json:"opdb_id"`
+	Name            string       `json:"name"`
+	CommonName      string       `json:"common_name"`
+	ManufactureDate string       `json:"manufacture_date"` // e.g., 1992-03-01.
+	Manufacturer    Manufacturer `json:"manufacturer"`
+}
+
+// A Manufacturer makes machines.
+type Manufacturer struct {
+	Name string `json:"name"`
+}
+
+// Year returns the year the machine was made, or zero if OPDB doesn't know.
+func (m Machine) Year() int {
+	if len(m.ManufactureDate) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(m.ManufactureDate[:4])
+	if err != nil {
+		return 0
+	}
+	return y
+}
+
+// A Store stores machine details.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	ListMachineAliases(ctx context.Context, source string) (map[string]string, error)
+	UpsertMachineDetails(ctx context.Context, d db.MachineDetails) error
+	GetMetadata(ctx context.Context, key string) (string, error)
+	SetMetadata(ctx context.Context, key, value string) error
+}
+
+// Options configures Sync.
+type Options struct {
+	client    *http.Client
+	now       func() time.Time
+	token     string
+	exportURL string
+}
+
+// Option configures Sync.
+type Option func(*Options)
+
+// WithHTTPClient sets the HTTP client used to fetch URL sources.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *Options) {
+		o.client = hc
+	}
+}
+
+// WithClock sets the clock used to record when machines were synced.
+func WithClock(now func() time.Time) Option {
+	return func(o *Options) {
+		o.now = now
+	}
+}
+
+// WithToken sets the API token used to fetch SourceAPI.
+func WithToken(token string) Option {
+	return func(o *Options) {
+		o.token = token
+	}
+}
+
+func options(opts []Option) Options {
+	o := Options{
+		client:    &http.Client{Timeout: 60 * time.Second},
+		now:       time.Now,
+		exportURL: ExportURL,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// A Result summarizes a sync.
+type Result struct {
+	Matched    int      // Machines matched to an OPDB machine.
+	Fuzzy      []string // Machines matched by a similar name, e.g. "Cirqus Voltaire as Circus Voltaire", sorted.
+	Duplicates []string // Machines matched to the same OPDB machine, e.g. "TZ and TZ2 as Twilight Zone", sorted.
+	Unmatched  []string // Names of machines that weren't matched, sorted.
+}
+
+// Sync loads machines from a source, matches them to the store's machines, and
+// upserts the matched machines' manufacturers and years. Machines are matched
+// by alias first, then by name, then by a similar name. It then records the
+// source and time in the store's sync metadata. The source is SourceAPI, or a
+// file path or http(s) URL of a saved OPDB export.
+func Sync(ctx context.Context, s Store, source string, opts ...Option) (*Result, error) {
+	o := options(opts)
+
+	data, err := read(ctx, o, source)
+	if err != nil {
+		return nil, err
+	}
+	machines, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", source, err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.ListMachineAliases(ctx, SourceAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := MatchAliases(aliases, machines)
+	maps.Copy(matched, Match(without(names, matched), machines))
+	fuzzy := MatchFuzzy(without(names, matched), machines)
+	maps.Copy(matched, fuzzy)
+
+	r := &Result{}
+	byID := map[string][]string{}
+	for key, name := range names {
+		m, ok := matched[key]
+		if !ok {
+			r.Unmatched = append(r.Unmatched, name)
+			continue
+		}
+		r.Matched++
+		if _, ok := fuzzy[key]; ok {
+			r.Fuzzy = append(r.Fuzzy, fmt.Sprintf("%s as %s", name, m.Name))
+		}
+		byID[m.ID] = append(byID[m.ID], key)
+		d := db.MachineDetails{
+			Key:          key,
+			Manufacturer: m.Manufacturer.Name,
+			Year:         m.Year(),
+			Source:       SourceAPI,
+			SourceID:     m.ID,
+		}
+		if err := s.UpsertMachineDetails(ctx, d); err != nil {
+			return nil, fmt.Errorf("update details for %s: %w", name, err)
+		}
+	}
+	// Two machines matched to one OPDB machine usually means one was matched
+	// by a similar name, or aliased, by mistake.
+	for _, m := range machines {
+		keys := byID[m.ID]
+		if len(keys) < 2 {
+			continue
+		}
+		sort.Strings(keys)
+		r.Duplicates = append(r.Duplicates, fmt.Sprintf("%s as %s", strings.Join(keys, " and "), m.Name))
+		delete(byID, m.ID)
+	}
+	sort.Strings(r.Fuzzy)
+	sort.Strings(r.Duplicates)
+	sort.Strings(r.Unmatched)
+
+	if err := s.SetMetadata(ctx, MetadataSource, source); err != nil {
+		return nil, err
+	}
+	if err := s.SetMetadata(ctx, MetadataLastSync, o.now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SyncIfStale calls Sync unless the store was synced from the same source
+// within MaxAge, or force is true. It returns a nil Result if it didn't sync.
+func SyncIfStale(ctx context.Context, s Store, source string, force bool, opts ...Option) (*Result, error) {
+	if force {
+		return Sync(ctx, s, source, opts...)
+	}
+
+	prev, err := s.GetMetadata(ctx, MetadataSource)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.GetMetadata(ctx, MetadataLastSync)
+	if err != nil {
+		return nil, err
+	}
+	if t, err := time.Parse(time.RFC3339, last); err == nil && prev == source && options(opts).now().Sub(t) < MaxAge {
+		return nil, nil
+	}
+	return Sync(ctx, s, source, opts...)
+}
+
+// An AliasStore stores machine aliases.
+type AliasStore interface {
+	ListMachineKeys(ctx context.Context) (map[string]bool, error)
+	UpsertMachineAlias(ctx context.Context, a db.MachineAlias) error
+}
+
+// LoadAliases loads curated machine aliases from a CSV file or http(s) URL,
+// and stores them for Sync to match machines by. It returns how many it
+// loaded. See ParseAliases for the format.
+func LoadAliases(ctx context.Context, s AliasStore, source string, opts ...Option) (int, error) {
+	o := options(opts)
+
+	data, err := read(ctx, o, source)
+	if err != nil {
+		return 0, err
+	}
+	aliases, err := ParseAliases(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", source, err)
+	}
+
+	keys, err := s.ListMachineKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, a := range aliases {
+		if !keys[a.Key] {
+			return 0, fmt.Errorf("%s is not a known machine key", a.Key)
+		}
+	}
+	for _, a := range aliases {
+		if err := s.UpsertMachineAlias(ctx, a); err != nil {
+			return 0, err
+		}
+	}
+	return len(aliases), nil
+}
+
+// ParseAliases parses machine aliases from a CSV file. The first row is a
+// header naming the columns, in any order:
+//
+//	key      MNP machine key, e.g. TAF. Required.
+//	opdb_id  OPDB machine or group ID, e.g. G5pe4-MePZv or G5pe4. Required.
+//
+// Column names are case-insensitive. Other columns are ignored, so a curated
+// file can note each machine's name.
+func ParseAliases(r io.Reader) ([]db.MachineAlias, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"key", "opdb_id"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header is missing required column %q", required)
+		}
+	}
+
+	var aliases []db.MachineAlias
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV row: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(col string) string {
+			i := cols[col]
+			if i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		key, id := strings.ToUpper(get("key")), get("opdb_id")
+		if key == "" || id == "" {
+			return nil, fmt.Errorf("line %d: key and opdb_id are required", line)
+		}
+		aliases = append(aliases, db.MachineAlias{Key: key, Source: SourceAPI, SourceID: id})
+	}
+
+	return aliases, nil
+}
+
+// Parse parses machines from an OPDB export, which is a JSON array of machines.
+func Parse(r io.Reader) ([]Machine, error) {
+	var machines []Machine
+	if err := json.NewDecoder(r).Decode(&machines); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	return machines, nil
+}
+
+// Match matches machines, keyed by MNP key to name, to OPDB machines by name.
+// Names are compared ignoring case, punctuation, a leading "The", and anything
+// in parentheses, like OPDB's "(Premium)" editions. When a name matches several
+// OPDB machines, as remakes do, one made by the manufacturer in the MNP name's
+// parentheses wins (e.g., "Jurassic Park (Data East)"), then the newest. OPDB
+// machines without a manufacturer or year are ignored.
+func Match(names map[string]string, machines []Machine) map[string]Machine {
+	candidates := candidates(machines)
+
+	matched := make(map[string]Machine)
+	for key, name := range names {
+		cs := candidates[normalize(name)]
+		if len(cs) == 0 {
+			continue
+		}
+		matched[key] = best(cs, qualifier(name))
+	}
+	return matched
+}
+
+// MatchAliases matches machines, keyed by MNP key to OPDB ID, to OPDB machines
+// by ID. An ID may be a group ID, like "G5pe4", to match the group's newest
+// machine. OPDB machines without a manufacturer or year are ignored.
+func MatchAliases(aliases map[string]string, machines []Machine) map[string]Machine {
+	matched := make(map[string]Machine)
+	for key, id := range aliases {
+		var cs []Machine
+		for _, m := range machines {
+			if m.Manufacturer.Name == "" && m.Year() == 0 {
+				continue
+			}
+			if m.ID == id || strings.HasPrefix(m.ID, id+"-") {
+				cs = append(cs, m)
+			}
+		}
+		if len(cs) > 0 {
+			matched[key] = best(cs, "")
+		}
+	}
+	return matched
+}
+
+// MatchFuzzy matches machines, keyed by MNP key to name, to OPDB machines with
+// similar names, normalized like Match. Names are similar if one can be made
+// from the other by inserting, deleting, or changing a letter for every
+// maxEditRatio letters, so short names must match exactly. The most similar
+// names win, then machines are chosen between like Match. It's a fallback for
+// names Match doesn't match, and may match the wrong machine.
+func MatchFuzzy(names map[string]string, machines []Machine) map[string]Machine {
+	candidates := candidates(machines)
+	normalized := slices.Sorted(maps.Keys(candidates))
+
+	matched := make(map[string]Machine)
+	for key, name := range names {
+		n := normalize(name)
+		limit := len(n) / maxEditRatio
+		if limit == 0 {
+			continue
+		}
+		var cs []Machine
+		for _, c := range normalized {
+			d := distance(n, c)
+			switch {
+			case d > limit:
+				continue
+			case d < limit:
+				limit, cs = d, nil
+			}
+			cs = append(cs, candidates[c]...)
+		}
+		if len(cs) > 0 {
+			matched[key] = best(cs, qualifier(name))
+		}
+	}
+	return matched
+}
+
+// maxEditRatio is how many letters of a name MatchFuzzy allows each edit for.
+const maxEditRatio = 5
+
+// candidates returns the OPDB machines with a manufacturer or year, keyed by
+// their normalized names.
+func candidates(machines []Machine) map[string][]Machine {
+	out := make(map[string][]Machine)
+	for _, m := range machines {
+		if m.Manufacturer.Name == "" && m.Year() == 0 {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, name := range []string{m.Name, m.CommonName} {
+			n := normalize(name)
+			if n == "" || seen[n] {
+				continue
+			}
+			seen[n] = true
+			out[n] = append(out[n], m)
+		}
+	}
+	return out
+}
+
+// best returns the best of several machines for a machine whose name qualifies
+// it with the supplied manufacturer, which may be empty.
+func best(machines []Machine, maker string) Machine {
+	b := machines[0]
+	for _, m := range machines[1:] {
+		if better(m, b, maker) {
+			b = m
+		}
+	}
+	return b
+}
+
+// without returns the names whose keys aren't matched.
+func without(names map[string]string, matched map[string]Machine) map[string]string {
+	out := make(map[string]string)
+	for key, name := range names {
+		if _, ok := matched[key]; !ok {
+			out[key] = name
+		}
+	}
+	return out
+}
+
+// distance returns the Levenshtein distance between two strings: how many
+// letters must be inserted, deleted, or changed to make one the other.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ra {
+		curr[0] = i + 1
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// better returns true if machine a is a better match than b for a machine whose
+// name qualifies it with the supplied manufacturer, which may be empty.
+func better(a, b Machine, maker string) bool {
+	am, bm := madeBy(a, maker), madeBy(b, maker)
+	if am != bm {
+		return am
+	}
+	return a.Year() > b.Year()
+}
+
+func madeBy(m Machine, maker string) bool {
+	if maker == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(m.Manufacturer.Name), maker)
+}
+
+// normalize returns a name without case, punctuation, a leading "The", or
+// anything in parentheses.
+func normalize(name string) string {
+	name = strings.ToLower(name)
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(strings.TrimSpace(name), "the ")
+
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// qualifier returns what's in a name's parentheses, lower case.
+func qualifier(name string) string {
+	i, j := strings.Index(name, "("), strings.LastIndex(name, ")")
+	if i < 0 || j < i {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(name[i+1 : j]))
+}
+
+// read returns the contents of a source.
+func read(ctx context.Context, o Options, source string) ([]byte, error) {
+	if source == SourceAPI {
+		if o.token == "" {
+			return nil, ErrNoToken
+		}
+		return fetch(ctx, o.client, "OPDB's export API", o.exportURL+"?api_token="+url.QueryEscape(o.token))
+	}
+	if isURL(source) {
+		return fetch(ctx, o.client, source, source)
+	}
+	data, err := os.ReadFile(source) //nolint:gosec // Reading the user's chosen source is the point.
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", source, err)
+	}
+	return data, nil
+}
+
+// fetch returns the body of a URL. Errors name the URL as desc, so they don't
+// leak API tokens.
+func fetch(ctx context.Context, hc *http.Client, desc, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	rsp, err := hc.Do(req)
+	if err != nil {
+		if uerr := (&url.Error{}); errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("fetch %s: %w", desc, err)
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Read-only response.
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %s", desc, rsp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", desc, err)
+	}
+	if len(data) > maxSourceBytes {
+		return nil, fmt.Errorf("%s is larger than %d bytes", desc, maxSourceBytes)
+	}
+	return data, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
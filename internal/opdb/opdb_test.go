@@ -0,0 +1,470 @@
+package opdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames      func(ctx context.Context) (map[string]string, error)
+	MockListMachineAliases   func(ctx context.Context, source string) (map[string]string, error)
+	MockUpsertMachineDetails func(ctx context.Context, d db.MachineDetails) error
+	MockGetMetadata          func(ctx context.Context, key string) (string, error)
+	MockSetMetadata          func(ctx context.Context, key, value string) error
+	MockListMachineKeys      func(ctx context.Context) (map[string]bool, error)
+	MockUpsertMachineAlias   func(ctx context.Context, a db.MachineAlias) error
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) ListMachineAliases(ctx context.Context, source string) (map[string]string, error) {
+	return m.MockListMachineAliases(ctx, source)
+}
+
+func (m *MockStore) UpsertMachineDetails(ctx context.Context, d db.MachineDetails) error {
+	return m.MockUpsertMachineDetails(ctx, d)
+}
+
+func (m *MockStore) GetMetadata(ctx context.Context, key string) (string, error) {
+	return m.MockGetMetadata(ctx, key)
+}
+
+func (m *MockStore) SetMetadata(ctx context.Context, key, value string) error {
+	return m.MockSetMetadata(ctx, key, value)
+}
+
+func (m *MockStore) ListMachineKeys(ctx context.Context) (map[string]bool, error) {
+	return m.MockListMachineKeys(ctx)
+}
+
+func (m *MockStore) UpsertMachineAlias(ctx context.Context, a db.MachineAlias) error {
+	return m.MockUpsertMachineAlias(ctx, a)
+}
+
+// recordingStore returns a MockStore with the supplied machines and aliases
+// that records what it's sent.
+func recordingStore(names, aliases map[string]string, details *[]db.MachineDetails, metadata map[string]string) *MockStore {
+	return &MockStore{
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+			return names, nil
+		},
+		MockListMachineAliases: func(_ context.Context, _ string) (map[string]string, error) {
+			return aliases, nil
+		},
+		MockUpsertMachineDetails: func(_ context.Context, d db.MachineDetails) error {
+			*details = append(*details, d)
+			return nil
+		},
+		MockGetMetadata: func(_ context.Context, key string) (string, error) {
+			return metadata[key], nil
+		},
+		MockSetMetadata: func(_ context.Context, key, value string) error {
+			metadata[key] = value
+			return nil
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	taf := Machine{ID: "G5pe4-MePZv", Name: "The Addams Family", ManufactureDate: "1992-03-01", Manufacturer: Manufacturer{Name: "Bally"}}
+	jpDataEast := Machine{ID: "GrknN-MQrdv", Name: "Jurassic Park", ManufactureDate: "1993-09-01", Manufacturer: Manufacturer{Name: "Data East"}}
+	jpStern := Machine{ID: "GR6d8-M1rZd", Name: "Jurassic Park (Pro)", ManufactureDate: "2019-07-01", Manufacturer: Manufacturer{Name: "Stern"}}
+	unknown := Machine{ID: "G4ODR-MDXEy", Name: "Twilight Zone"}
+
+	cases := map[string]struct {
+		reason string
+		names  map[string]string
+		want   map[string]Machine
+	}{
+		"Normalized": {
+			reason: "Names should match ignoring case, punctuation, and a leading The.",
+			names:  map[string]string{"TAF": "addams family"},
+			want:   map[string]Machine{"TAF": taf},
+		},
+		"Newest": {
+			reason: "A name matching several machines should match the newest, ignoring OPDB's edition.",
+			names:  map[string]string{"JP2": "Jurassic Park"},
+			want:   map[string]Machine{"JP2": jpStern},
+		},
+		"Manufacturer": {
+			reason: "A manufacturer in the name's parentheses should pick that manufacturer's machine.",
+			names:  map[string]string{"JP": "Jurassic Park (Data East)"},
+			want:   map[string]Machine{"JP": jpDataEast},
+		},
+		"Unknown": {
+			reason: "Machines OPDB knows nothing about, or doesn't have, shouldn't match.",
+			names:  map[string]string{"TZ": "Twilight Zone", "XYZ": "Not A Machine"},
+			want:   map[string]Machine{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Match(tc.names, []Machine{taf, jpDataEast, jpStern, unknown})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMatch(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMatchAliases(t *testing.T) {
+	taf := Machine{ID: "G5pe4-MePZv", Name: "The Addams Family", ManufactureDate: "1992-03-01", Manufacturer: Manufacturer{Name: "Bally"}}
+	tafGold := Machine{ID: "G5pe4-MkPRV", Name: "The Addams Family Special Collectors Edition", ManufactureDate: "1994-10-01", Manufacturer: Manufacturer{Name: "Bally"}}
+	unknown := Machine{ID: "G4ODR-MDXEy", Name: "Twilight Zone"}
+
+	cases := map[string]struct {
+		reason  string
+		aliases map[string]string
+		want    map[string]Machine
+	}{
+		"Machine": {
+			reason:  "An alias to a machine ID should match that machine.",
+			aliases: map[string]string{"TAF": "G5pe4-MePZv"},
+			want:    map[string]Machine{"TAF": taf},
+		},
+		"Group": {
+			reason:  "An alias to a group ID should match the group's newest machine.",
+			aliases: map[string]string{"TAF": "G5pe4"},
+			want:    map[string]Machine{"TAF": tafGold},
+		},
+		"Unknown": {
+			reason:  "Aliases to machines OPDB knows nothing about, or doesn't have, shouldn't match.",
+			aliases: map[string]string{"TZ": "G4ODR", "XYZ": "Gxxxx"},
+			want:    map[string]Machine{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := MatchAliases(tc.aliases, []Machine{taf, tafGold, unknown})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMatchAliases(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMatchFuzzy(t *testing.T) {
+	cv := Machine{ID: "GRBE4-MQK1Z", Name: "Cirqus Voltaire", ManufactureDate: "1997-10-01", Manufacturer: Manufacturer{Name: "Bally"}}
+	tzBally := Machine{ID: "G4ODR-MDXEy", Name: "Twilight Zone", ManufactureDate: "1993-04-01", Manufacturer: Manufacturer{Name: "Bally"}}
+	tzRemake := Machine{ID: "GrXzD-MjBPX", Name: "Twilight Zone", ManufactureDate: "2024-01-01", Manufacturer: Manufacturer{Name: "Chicago Gaming"}}
+	ss := Machine{ID: "GrO2E-MQ2Wq", Name: "Scared Stiff", ManufactureDate: "1996-09-01", Manufacturer: Manufacturer{Name: "Bally"}}
+
+	cases := map[string]struct {
+		reason string
+		names  map[string]string
+		want   map[string]Machine
+	}{
+		"Typo": {
+			reason: "A name a letter or two from a machine's should match it.",
+			names:  map[string]string{"CV": "Circus Voltaire"},
+			want:   map[string]Machine{"CV": cv},
+		},
+		"Manufacturer": {
+			reason: "A manufacturer in the name's parentheses should pick that manufacturer's machine, like Match.",
+			names:  map[string]string{"TZ": "Twighlight Zone (Bally)"},
+			want:   map[string]Machine{"TZ": tzBally},
+		},
+		"Newest": {
+			reason: "A name similar to several machines' should match the newest, like Match.",
+			names:  map[string]string{"TZ": "Twilight Zon"},
+			want:   map[string]Machine{"TZ": tzRemake},
+		},
+		"Short": {
+			reason: "Short names must match exactly, so shouldn't match fuzzily.",
+			names:  map[string]string{"SS2": "Scared"},
+			want:   map[string]Machine{},
+		},
+		"Different": {
+			reason: "A name too different from every machine's shouldn't match.",
+			names:  map[string]string{"XYZ": "Scared Silly"},
+			want:   map[string]Machine{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := MatchFuzzy(tc.names, []Machine{cv, tzBally, tzRemake, ss})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMatchFuzzy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC)
+	export := `[
+		{"opdb_id": "G5pe4-MePZv", "name": "The Addams Family", "manufacture_date": "1992-03-01", "manufacturer": {"name": "Bally"}},
+		{"opdb_id": "G43W4-MrRpw", "name": "Godzilla (Premium)", "manufacture_date": "2021-10-01", "manufacturer": {"name": "Stern"}},
+		{"opdb_id": "GRBE4-MQK1Z", "name": "Cirqus Voltaire", "manufacture_date": "1997-10-01", "manufacturer": {"name": "Bally"}},
+		{"opdb_id": "Gr9Lv-MQ2dN", "name": "Indiana Jones: The Pinball Adventure", "manufacture_date": "1993-08-01", "manufacturer": {"name": "Williams"}}
+	]`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/export" && r.URL.Query().Get("api_token") == "secret":
+			_, _ = w.Write([]byte(export))
+		case r.URL.Path == "/export.json":
+			_, _ = w.Write([]byte(export))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	file := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(file, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]string{"TAF": "The Addams Family", "GDZ": "Godzilla", "CV": "Circus Voltaire", "IJ": "Indiana Jones", "TZ": "Twilight Zone"}
+	details := []db.MachineDetails{
+		{Key: "CV", Manufacturer: "Bally", Year: 1997, Source: "opdb", SourceID: "GRBE4-MQK1Z"},
+		{Key: "GDZ", Manufacturer: "Stern", Year: 2021, Source: "opdb", SourceID: "G43W4-MrRpw"},
+		{Key: "TAF", Manufacturer: "Bally", Year: 1992, Source: "opdb", SourceID: "G5pe4-MePZv"},
+	}
+	fuzzy := []string{"Circus Voltaire as Cirqus Voltaire"}
+	result := &Result{Matched: 3, Fuzzy: fuzzy, Unmatched: []string{"Indiana Jones", "Twilight Zone"}}
+	synced := func(source string) map[string]string {
+		return map[string]string{MetadataSource: source, MetadataLastSync: "2024-01-18T12:00:00Z"}
+	}
+
+	type args struct {
+		source  string
+		token   string
+		aliases map[string]string
+	}
+	type want struct {
+		result   *Result
+		details  []db.MachineDetails
+		metadata map[string]string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"API": {
+			reason: "Machines from OPDB's API should be matched and upserted, and the sync recorded.",
+			args:   args{source: SourceAPI, token: "secret"},
+			want:   want{result: result, details: details, metadata: synced(SourceAPI)},
+		},
+		"NoToken": {
+			reason: "OPDB's API can't be used without a token.",
+			args:   args{source: SourceAPI},
+			want:   want{metadata: map[string]string{}, err: ErrNoToken},
+		},
+		"BadToken": {
+			reason: "An API error should return an error, and record nothing.",
+			args:   args{source: SourceAPI, token: "wrong"},
+			want:   want{metadata: map[string]string{}, err: cmpopts.AnyError},
+		},
+		"URL": {
+			reason: "Machines from a saved export at a URL should be upserted.",
+			args:   args{source: srv.URL + "/export.json"},
+			want:   want{result: result, details: details, metadata: synced(srv.URL + "/export.json")},
+		},
+		"File": {
+			reason: "Machines from a saved export file should be upserted.",
+			args:   args{source: file},
+			want:   want{result: result, details: details, metadata: synced(file)},
+		},
+		"Aliased": {
+			reason: "Machines whose names don't match should be matched by alias.",
+			args:   args{source: file, aliases: map[string]string{"IJ": "Gr9Lv"}},
+			want: want{
+				result:   &Result{Matched: 4, Fuzzy: fuzzy, Unmatched: []string{"Twilight Zone"}},
+				details:  append([]db.MachineDetails{{Key: "IJ", Manufacturer: "Williams", Year: 1993, Source: "opdb", SourceID: "Gr9Lv-MQ2dN"}}, details...),
+				metadata: synced(file),
+			},
+		},
+		"Duplicates": {
+			reason: "Machines matched to the same OPDB machine should be reported.",
+			args:   args{source: file, aliases: map[string]string{"TZ": "G5pe4"}},
+			want: want{
+				result: &Result{
+					Matched:    4,
+					Fuzzy:      fuzzy,
+					Duplicates: []string{"TAF and TZ as The Addams Family"},
+					Unmatched:  []string{"Indiana Jones"},
+				},
+				details:  append([]db.MachineDetails{{Key: "TZ", Manufacturer: "Bally", Year: 1992, Source: "opdb", SourceID: "G5pe4-MePZv"}}, details...),
+				metadata: synced(file),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []db.MachineDetails
+			metadata := map[string]string{}
+			s := recordingStore(names, tc.args.aliases, &got, metadata)
+
+			r, err := Sync(context.Background(), s, tc.args.source,
+				WithHTTPClient(srv.Client()),
+				WithClock(func() time.Time { return now }),
+				WithToken(tc.args.token),
+				func(o *Options) { o.exportURL = srv.URL + "/api/export" },
+			)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, r); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want result, +got result:\n%s", tc.reason, diff)
+			}
+			sortDetails := cmpopts.SortSlices(func(a, b db.MachineDetails) bool { return a.Key < b.Key })
+			if diff := cmp.Diff(tc.want.details, got, sortDetails); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want details, +got details:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.metadata, metadata); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want metadata, +got metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSyncIfStale(t *testing.T) {
+	now := time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC)
+	file := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(file, []byte(`[]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		metadata map[string]string
+		force    bool
+		want     bool
+	}{
+		"Never": {
+			reason:   "A store that's never been synced should sync.",
+			metadata: map[string]string{},
+			want:     true,
+		},
+		"Fresh": {
+			reason:   "A store synced from the same source within MaxAge shouldn't sync.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-18T00:00:00Z"},
+			want:     false,
+		},
+		"Forced": {
+			reason:   "A forced sync should sync, however fresh the store is.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-18T00:00:00Z"},
+			force:    true,
+			want:     true,
+		},
+		"Stale": {
+			reason:   "A store synced longer than MaxAge ago should sync.",
+			metadata: map[string]string{MetadataSource: file, MetadataLastSync: "2024-01-17T00:00:00Z"},
+			want:     true,
+		},
+		"NewSource": {
+			reason:   "A store synced from a different source should sync.",
+			metadata: map[string]string{MetadataSource: SourceAPI, MetadataLastSync: "2024-01-18T00:00:00Z"},
+			want:     true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var details []db.MachineDetails
+			s := recordingStore(map[string]string{}, nil, &details, tc.metadata)
+
+			r, err := SyncIfStale(context.Background(), s, file, tc.force, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("\n%s\nSyncIfStale(...): %v", tc.reason, err)
+			}
+			if got := r != nil; got != tc.want {
+				t.Errorf("\n%s\nSyncIfStale(...): synced %t, want %t", tc.reason, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	type want struct {
+		n       int
+		aliases []db.MachineAlias
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		source string
+		want   want
+	}{
+		"Loaded": {
+			reason: "Aliases should be loaded with upper case keys, ignoring other columns.",
+			source: write("aliases.csv", "Name,Key,OPDB_ID\nIndiana Jones,ij,Gr9Lv\nThe Addams Family,TAF,G5pe4-MePZv\n"),
+			want: want{n: 2, aliases: []db.MachineAlias{
+				{Key: "IJ", Source: SourceAPI, SourceID: "Gr9Lv"},
+				{Key: "TAF", Source: SourceAPI, SourceID: "G5pe4-MePZv"},
+			}},
+		},
+		"UnknownKey": {
+			reason: "An alias for a machine that doesn't exist should return an error, and load nothing.",
+			source: write("unknown.csv", "key,opdb_id\nTAF,G5pe4\nXYZ,G4ODR\n"),
+			want:   want{err: cmpopts.AnyError},
+		},
+		"MissingID": {
+			reason: "An alias without an ID should return an error.",
+			source: write("missing.csv", "key,opdb_id\nTAF,\n"),
+			want:   want{err: cmpopts.AnyError},
+		},
+		"MissingColumn": {
+			reason: "A file without an opdb_id column should return an error.",
+			source: write("column.csv", "key,name\nTAF,The Addams Family\n"),
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []db.MachineAlias
+			s := &MockStore{
+				MockListMachineKeys: func(_ context.Context) (map[string]bool, error) {
+					return map[string]bool{"IJ": true, "TAF": true}, nil
+				},
+				MockUpsertMachineAlias: func(_ context.Context, a db.MachineAlias) error {
+					got = append(got, a)
+					return nil
+				},
+			}
+
+			n, err := LoadAliases(context.Background(), s, tc.source)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoadAliases(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if n != tc.want.n {
+				t.Errorf("\n%s\nLoadAliases(...): want %d aliases, got %d", tc.reason, tc.want.n, n)
+			}
+			if diff := cmp.Diff(tc.want.aliases, got); diff != "" {
+				t.Errorf("\n%s\nLoadAliases(...): -want aliases, +got aliases:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package output
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // FormatScore formats a pinball score with appropriate suffix.
@@ -19,6 +22,32 @@ func FormatScore(score float64) string {
 	}
 }
 
+// ParseScore parses a pinball score written the way FormatScore writes them,
+// such as "100M", "1.5B", or "250K". Plain numbers, optionally with comma
+// separators, are also accepted.
+func ParseScore(score string) (float64, error) {
+	s := strings.ReplaceAll(strings.TrimSpace(score), ",", "")
+	mult := 1.0
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'B', 'b':
+			mult = 1_000_000_000
+		case 'M', 'm':
+			mult = 1_000_000
+		case 'K', 'k':
+			mult = 1_000
+		}
+		if mult != 1 {
+			s = s[:n-1]
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid score %q", score)
+	}
+	return v * mult, nil
+}
+
 // FormatP50 formats a P50 score with relative strength annotation. The
 // leagueP50 is the league-wide P50 for the same machine. If leagueP50 is zero
 // (no league data), the annotation is omitted.
@@ -30,6 +59,18 @@ func FormatP50(p50, leagueP50 float64) string {
 	return score + " " + FormatRelStr(p50, leagueP50)
 }
 
+// FormatSplit formats a P50 at home and away (e.g. "45.2M / 30.1M"). A zero
+// P50, for a side that hasn't been played, is formatted as "-".
+func FormatSplit(home, away float64) string {
+	side := func(p50 float64) string {
+		if p50 == 0 {
+			return "-"
+		}
+		return FormatScore(p50)
+	}
+	return side(home) + " / " + side(away)
+}
+
 // FormatRelStr formats relative strength as a percentage vs league P50.
 func FormatRelStr(p50, leagueP50 float64) string {
 	if leagueP50 == 0 {
@@ -47,6 +88,22 @@ func FormatRelStr(p50, leagueP50 float64) string {
 	}
 }
 
+// FormatChange formats the percentage change from one score to another.
+func FormatChange(from, to float64) string {
+	if from == 0 {
+		return "-"
+	}
+	rounded := int(math.Round((to - from) / from * 100))
+	switch {
+	case rounded > 0:
+		return fmt.Sprintf("+%d%%", rounded)
+	case rounded < 0:
+		return fmt.Sprintf("%d%%", rounded)
+	default:
+		return "Same"
+	}
+}
+
 // RelStr computes relative strength as a percentage vs league P50.
 func RelStr(p50, leagueP50 float64) float64 {
 	if leagueP50 == 0 {
@@ -55,6 +112,11 @@ func RelStr(p50, leagueP50 float64) float64 {
 	return (p50 - leagueP50) / leagueP50 * 100
 }
 
+// FormatPercentRank formats a percentile rank from 0 to 1 as a percentage.
+func FormatPercentRank(rank float64) string {
+	return fmt.Sprintf("%.0f%%", rank*100)
+}
+
 // MachineName returns the full name for a machine key, falling back to the key
 // itself if no name is found.
 func MachineName(names map[string]string, key string) string {
@@ -64,6 +126,30 @@ func MachineName(names map[string]string, key string) string {
 	return key
 }
 
+// shortMachineName is the most characters ShortMachineName keeps.
+const shortMachineName = 14
+
+// ShortMachineName shortens a machine name for narrow tables, dropping a
+// leading "The" and cutting it off with an ellipsis past 14 characters (e.g.
+// "Medieval Madn…").
+func ShortMachineName(name string) string {
+	name = strings.TrimPrefix(name, "The ")
+	r := []rune(name)
+	if len(r) <= shortMachineName {
+		return name
+	}
+	return strings.TrimSpace(string(r[:shortMachineName-1])) + "…"
+}
+
+// ShortPlayerName shortens a player's name to their first name and last
+// initial (e.g. "Jay O").
+func ShortPlayerName(name string) string {
+	if first, last, ok := strings.Cut(name, " "); ok {
+		return first + " " + last[:1]
+	}
+	return name
+}
+
 // FormatIPR formats an IPR value, returning "-" for zero (unknown).
 func FormatIPR(ipr int) string {
 	if ipr == 0 {
@@ -71,3 +157,54 @@ func FormatIPR(ipr int) string {
 	}
 	return fmt.Sprintf("%d", ipr)
 }
+
+// FormatRetention formats how many players from a team's previous roster are
+// still on it (e.g. "9 of 10"), or "New" if the team didn't play last season.
+func FormatRetention(kept, previous int) string {
+	if previous == 0 {
+		return "New"
+	}
+	return fmt.Sprintf("%d of %d", kept, previous)
+}
+
+// FormatAttendance formats how many of a team's matches this season a player
+// played in (e.g. "2 of 8 matches"), or "No matches yet" before the team has
+// played.
+func FormatAttendance(matches, teamMatches int) string {
+	if teamMatches == 0 {
+		return "No matches yet"
+	}
+	return fmt.Sprintf("%d of %d matches", matches, teamMatches)
+}
+
+// FormatPoints formats match points, which can be fractional (e.g. 2.5).
+func FormatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// FormatEfficiency formats the fraction of the match points possible that were
+// won as a percentage (e.g. "82%"), or "-" if no points were possible.
+func FormatEfficiency(won, possible float64) string {
+	if possible == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", won/possible*100)
+}
+
+// FormatDataAsOf describes how fresh the archive data is, given the archive
+// commit last synced and when it was made (e.g. "Data as of Mon 7:42pm (commit
+// abc1234)"). Commits more than six days before now include their date. It
+// returns an empty string if the archive has never been synced.
+func FormatDataAsOf(commit string, when, now time.Time) string {
+	if commit == "" {
+		return ""
+	}
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	layout := "Mon 3:04pm"
+	if now.Sub(when) > 6*24*time.Hour {
+		layout = "Mon Jan 2 3:04pm"
+	}
+	return fmt.Sprintf("Data as of %s (commit %s)", when.Format(layout), commit)
+}
@@ -2,8 +2,10 @@ package output
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestFormatScore(t *testing.T) {
@@ -65,6 +67,64 @@ func TestFormatScore(t *testing.T) {
 	}
 }
 
+func TestParseScore(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		result float64
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Millions": {
+			reason: "An M suffix should multiply by a million.",
+			args:   args{s: "100M"},
+			want:   want{result: 100_000_000},
+		},
+		"FractionalBillions": {
+			reason: "A fractional score with a B suffix should multiply by a billion.",
+			args:   args{s: "1.5b"},
+			want:   want{result: 1_500_000_000},
+		},
+		"Thousands": {
+			reason: "A K suffix should multiply by a thousand.",
+			args:   args{s: "250K"},
+			want:   want{result: 250_000},
+		},
+		"Separators": {
+			reason: "Comma separators should be ignored.",
+			args:   args{s: "12,345,670"},
+			want:   want{result: 12_345_670},
+		},
+		"Invalid": {
+			reason: "Anything else should be rejected.",
+			args:   args{s: "lots"},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Negative": {
+			reason: "Negative scores should be rejected.",
+			args:   args{s: "-5M"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseScore(tc.args.s)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseScore(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nParseScore(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestFormatRelStr(t *testing.T) {
 	type args struct {
 		p50       float64
@@ -115,6 +175,266 @@ func TestFormatRelStr(t *testing.T) {
 	}
 }
 
+func TestFormatChange(t *testing.T) {
+	type args struct {
+		from float64
+		to   float64
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Up": {
+			reason: "A higher score should show a positive percentage.",
+			args:   args{from: 1_000_000, to: 1_250_000},
+			want:   want{result: "+25%"},
+		},
+		"Down": {
+			reason: "A lower score should show a negative percentage.",
+			args:   args{from: 1_000_000, to: 900_000},
+			want:   want{result: "-10%"},
+		},
+		"Same": {
+			reason: "A change that rounds to zero should show Same.",
+			args:   args{from: 1_000_000, to: 1_000_001},
+			want:   want{result: "Same"},
+		},
+		"ZeroFrom": {
+			reason: "A change from zero can't be expressed as a percentage.",
+			args:   args{from: 0, to: 1_000_000},
+			want:   want{result: "-"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatChange(tc.args.from, tc.args.to)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatChange(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatRetention(t *testing.T) {
+	type args struct {
+		kept     int
+		previous int
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Returning": {
+			reason: "A team that played last season should show how many players it kept.",
+			args:   args{kept: 9, previous: 10},
+			want:   want{result: "9 of 10"},
+		},
+		"New": {
+			reason: "A team with no roster last season should show New.",
+			args:   args{kept: 0, previous: 0},
+			want:   want{result: "New"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatRetention(tc.args.kept, tc.args.previous)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatRetention(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatSplit(t *testing.T) {
+	type args struct {
+		home float64
+		away float64
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Both": {
+			reason: "Both P50s should be formatted as scores.",
+			args:   args{home: 45_200_000, away: 30_100_000},
+			want:   want{result: "45.2M / 30.1M"},
+		},
+		"OnlyHome": {
+			reason: "A side that hasn't been played should be a dash.",
+			args:   args{home: 45_200_000},
+			want:   want{result: "45.2M / -"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatSplit(tc.args.home, tc.args.away)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatSplit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatAttendance(t *testing.T) {
+	type args struct {
+		matches     int
+		teamMatches int
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Played": {
+			reason: "A player should show how many of the team's matches they played in.",
+			args:   args{matches: 2, teamMatches: 8},
+			want:   want{result: "2 of 8 matches"},
+		},
+		"NoMatches": {
+			reason: "Before the team has played, there's no attendance to show.",
+			args:   args{matches: 0, teamMatches: 0},
+			want:   want{result: "No matches yet"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatAttendance(tc.args.matches, tc.args.teamMatches)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatAttendance(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatDataAsOf(t *testing.T) {
+	now := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	type args struct {
+		commit string
+		when   time.Time
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Recent": {
+			reason: "A commit from the last few days should show its weekday and time, and an abbreviated hash.",
+			args:   args{commit: "abc1234def5678", when: time.Date(2024, 1, 15, 19, 42, 0, 0, time.UTC)},
+			want:   want{result: "Data as of Mon 7:42pm (commit abc1234)"},
+		},
+		"Old": {
+			reason: "A commit from more than six days ago should include its date.",
+			args:   args{commit: "abc1234", when: time.Date(2024, 1, 8, 19, 42, 0, 0, time.UTC)},
+			want:   want{result: "Data as of Mon Jan 8 7:42pm (commit abc1234)"},
+		},
+		"NeverSynced": {
+			reason: "Without a commit there's no freshness to show.",
+			want:   want{result: ""},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatDataAsOf(tc.args.commit, tc.args.when, now)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatDataAsOf(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatEfficiency(t *testing.T) {
+	type args struct {
+		won      float64
+		possible float64
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Won": {
+			reason: "Efficiency should be the rounded percentage of possible points won.",
+			args:   args{won: 4.5, possible: 5.5},
+			want:   want{result: "82%"},
+		},
+		"NonePossible": {
+			reason: "Without any possible points, there's no efficiency to show.",
+			args:   args{},
+			want:   want{result: "-"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatEfficiency(tc.args.won, tc.args.possible)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatEfficiency(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatPoints(t *testing.T) {
+	type args struct {
+		points float64
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Whole": {
+			reason: "Whole points should be formatted without a decimal.",
+			args:   args{points: 3},
+			want:   want{result: "3"},
+		},
+		"Fractional": {
+			reason: "Fractional points should keep their decimal.",
+			args:   args{points: 2.5},
+			want:   want{result: "2.5"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FormatPoints(tc.args.points)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nFormatPoints(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestMachineName(t *testing.T) {
 	type args struct {
 		names map[string]string
@@ -155,3 +475,64 @@ func TestMachineName(t *testing.T) {
 		})
 	}
 }
+
+func TestShortMachineName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   string
+	}{
+		"Short": {
+			reason: "A short name should be unchanged.",
+			name:   "Godzilla",
+			want:   "Godzilla",
+		},
+		"The": {
+			reason: "A leading The should be dropped.",
+			name:   "The Addams Family",
+			want:   "Addams Family",
+		},
+		"Long": {
+			reason: "A long name should be cut off with an ellipsis.",
+			name:   "Elvira's House of Horrors",
+			want:   "Elvira's Hous…",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ShortMachineName(tc.name)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nShortMachineName(%q): -want, +got:\n%s", tc.reason, tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestShortPlayerName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   string
+	}{
+		"FullName": {
+			reason: "A full name should be shortened to the first name and last initial.",
+			name:   "Jay Ostby",
+			want:   "Jay O",
+		},
+		"OneName": {
+			reason: "A single name should be unchanged.",
+			name:   "Cher",
+			want:   "Cher",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ShortPlayerName(tc.name)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nShortPlayerName(%q): -want, +got:\n%s", tc.reason, tc.name, diff)
+			}
+		})
+	}
+}
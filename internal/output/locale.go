@@ -0,0 +1,136 @@
+package output
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A Locale describes how a language writes numbers.
+type Locale struct {
+	Decimal string // Decimal separator, e.g. "." or ",".
+	Group   string // Thousands separator, e.g. "," or ".".
+}
+
+// English returns the default locale, e.g. 1,234.5.
+func English() Locale {
+	return Locale{Decimal: ".", Group: ","}
+}
+
+// ParseLocale returns the locale for a BCP 47 language tag such as "de" or
+// "fr-CA". Languages it doesn't know are written the English way.
+func ParseLocale(tag string) (Locale, bool) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	switch lang {
+	case "en", "ja", "ko", "zh", "he", "th":
+		return English(), true
+	case "de", "es", "it", "nl", "pt", "da", "id", "tr", "el", "ro", "sl", "hr":
+		return Locale{Decimal: ",", Group: "."}, true
+	case "fr", "sv", "nb", "no", "fi", "pl", "cs", "sk", "ru", "uk", "hu", "bg", "lt", "lv", "et":
+		return Locale{Decimal: ",", Group: "\u00a0"}, true // No-break space.
+	}
+	return English(), false
+}
+
+// LocaleFromAcceptLanguage returns the locale of the most preferred language
+// in an HTTP Accept-Language header that ParseLocale knows about.
+func LocaleFromAcceptLanguage(header string) Locale {
+	type pref struct {
+		tag string
+		q   float64
+	}
+	var prefs []pref
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		prefs = append(prefs, pref{tag: tag, q: q})
+	}
+
+	// Stable, so equally weighted languages stay in the order they were listed.
+	slices.SortStableFunc(prefs, func(a, b pref) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	for _, p := range prefs {
+		if l, ok := ParseLocale(p.tag); ok {
+			return l
+		}
+	}
+	return English()
+}
+
+// A ScoreFormat formats pinball scores according to a locale.
+type ScoreFormat struct {
+	Locale Locale
+
+	// Full writes every digit (e.g. 2,500,000,000) instead of abbreviating
+	// with a suffix (e.g. 2.5B). Abbreviated scores can hide the difference
+	// between two close scores.
+	Full bool
+}
+
+// Score formats a pinball score.
+func (f ScoreFormat) Score(score float64) string {
+	if f.Full {
+		return f.Locale.group(int64(math.Round(score)))
+	}
+	return f.Locale.decimal(FormatScore(score))
+}
+
+// P50 formats a P50 score with relative strength annotation, like FormatP50.
+func (f ScoreFormat) P50(p50, leagueP50 float64) string {
+	score := f.Score(p50)
+	if leagueP50 == 0 {
+		return score
+	}
+	return score + " " + FormatRelStr(p50, leagueP50)
+}
+
+// decimal replaces the decimal point in an English formatted number.
+func (l Locale) decimal(s string) string {
+	if l.Decimal == "" || l.Decimal == "." {
+		return s
+	}
+	return strings.Replace(s, ".", l.Decimal, 1)
+}
+
+// group writes an integer with thousands separators.
+func (l Locale) group(n int64) string {
+	sep := l.Group
+	if sep == "" {
+		sep = ","
+	}
+
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
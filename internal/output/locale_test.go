@@ -0,0 +1,111 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScoreFormat(t *testing.T) {
+	type args struct {
+		f     ScoreFormat
+		score float64
+	}
+	type want struct {
+		result string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ZeroValue": {
+			reason: "The zero value should format like FormatScore.",
+			args:   args{score: 2_500_000_000},
+			want:   want{result: "2.5B"},
+		},
+		"AbbreviatedGerman": {
+			reason: "Abbreviated scores should use the locale's decimal separator.",
+			args:   args{f: ScoreFormat{Locale: Locale{Decimal: ",", Group: "."}}, score: 1_234_567},
+			want:   want{result: "1,2M"},
+		},
+		"FullEnglish": {
+			reason: "Full scores should write every digit with thousands separators.",
+			args:   args{f: ScoreFormat{Locale: English(), Full: true}, score: 2_512_345_670},
+			want:   want{result: "2,512,345,670"},
+		},
+		"FullGerman": {
+			reason: "Full scores should use the locale's thousands separator.",
+			args:   args{f: ScoreFormat{Locale: Locale{Decimal: ",", Group: "."}, Full: true}, score: 45_678},
+			want:   want{result: "45.678"},
+		},
+		"FullSmall": {
+			reason: "Full scores under 1,000 shouldn't be grouped.",
+			args:   args{f: ScoreFormat{Locale: English(), Full: true}, score: 999},
+			want:   want{result: "999"},
+		},
+		"FullNegative": {
+			reason: "Negative full scores should keep their sign ahead of the digits.",
+			args:   args{f: ScoreFormat{Locale: English(), Full: true}, score: -123_456},
+			want:   want{result: "-123,456"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.args.f.Score(tc.args.score)
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nScore(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	type args struct {
+		header string
+	}
+	type want struct {
+		locale Locale
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Empty": {
+			reason: "A missing header should fall back to English.",
+			args:   args{header: ""},
+			want:   want{locale: English()},
+		},
+		"Region": {
+			reason: "A language with a region subtag should match the language.",
+			args:   args{header: "de-DE"},
+			want:   want{locale: Locale{Decimal: ",", Group: "."}},
+		},
+		"Quality": {
+			reason: "The language with the highest q-value should win.",
+			args:   args{header: "en;q=0.5, fr-CA;q=0.9"},
+			want:   want{locale: Locale{Decimal: ",", Group: "\u00a0"}},
+		},
+		"SkipUnknown": {
+			reason: "Unknown languages should be skipped in favour of known ones.",
+			args:   args{header: "tlh, es;q=0.8"},
+			want:   want{locale: Locale{Decimal: ",", Group: "."}},
+		},
+		"Wildcard": {
+			reason: "A wildcard alone should fall back to English.",
+			args:   args{header: "*"},
+			want:   want{locale: English()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := LocaleFromAcceptLanguage(tc.args.header)
+			if diff := cmp.Diff(tc.want.locale, got); diff != "" {
+				t.Errorf("\n%s\nLocaleFromAcceptLanguage(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// A Format is how a Printer writes tables.
+type Format string
+
+// Formats.
+const (
+	// FormatTable writes bordered ASCII tables for reading in a terminal.
+	FormatTable Format = "table"
+
+	// FormatCSV writes comma-separated values for pasting or importing into a
+	// spreadsheet.
+	FormatCSV Format = "csv"
+)
+
+// A Printer writes a command's tables, and the notes around them, in a
+// Format. In CSV format only tables are written to out. Notes go to errs, so
+// that out can be redirected to a file that contains only CSV.
+type Printer struct {
+	format Format
+	out    io.Writer
+	notes  io.Writer
+	tables int
+	opts   []TableOption
+}
+
+// A PrinterOption configures a Printer.
+type PrinterOption func(*Printer)
+
+// Compact makes a Printer write tables without borders, for reading on a
+// phone.
+func Compact() PrinterOption {
+	return func(p *Printer) {
+		p.opts = append(p.opts, Borderless())
+	}
+}
+
+// NewPrinter returns a Printer that writes tables in the supplied format to
+// out.
+func NewPrinter(f Format, out, errs io.Writer, opts ...PrinterOption) *Printer {
+	p := &Printer{format: f, out: out, notes: out}
+	if f == FormatCSV {
+		p.notes = errs
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Table writes a table. Table options don't affect CSV. In CSV format each
+// table after the first is preceded by an empty line.
+func (p *Printer) Table(headers []string, rows [][]string, opts ...TableOption) error {
+	defer func() { p.tables++ }()
+
+	if p.format != FormatCSV {
+		return Table(p.out, headers, rows, append(slices.Clone(p.opts), opts...)...)
+	}
+	if p.tables > 0 {
+		if _, err := io.WriteString(p.out, "\n"); err != nil {
+			return err
+		}
+	}
+	return CSV(p.out, headers, rows)
+}
+
+// Printf writes a note, formatted like fmt.Printf.
+func (p *Printer) Printf(format string, a ...any) {
+	fmt.Fprintf(p.notes, format, a...) //nolint:errcheck // Like fmt.Printf.
+}
+
+// Println writes a note, formatted like fmt.Println.
+func (p *Printer) Println(a ...any) {
+	fmt.Fprintln(p.notes, a...) //nolint:errcheck // Like fmt.Println.
+}
+
+// CSV writes a header row and rows as comma-separated values.
+func CSV(w io.Writer, headers []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
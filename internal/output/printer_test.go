@@ -0,0 +1,88 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrinter(t *testing.T) {
+	type want struct {
+		out   string
+		notes string
+	}
+	cases := map[string]struct {
+		reason string
+		format Format
+		opts   []PrinterOption
+		want   want
+	}{
+		"CSV": {
+			reason: "CSV tables should be written to out, separated by an empty line, with notes written to errs.",
+			format: FormatCSV,
+			want: want{
+				out:   "Machine,P50\nTAF,1.2M\n\nPlayer,Team\n\"Ostby, Jay\",CRA\n",
+				notes: "Strongest: TAF\n",
+			},
+		},
+		"Table": {
+			reason: "Tables and notes should both be written to out.",
+			format: FormatTable,
+			want: want{
+				out: strings.Join([]string{
+					"┌─────────┬──────┐",
+					"│ Machine │ P50  │",
+					"├─────────┼──────┤",
+					"│ TAF     │ 1.2M │",
+					"└─────────┴──────┘",
+					"Strongest: TAF",
+					"┌────────────┬──────┐",
+					"│   Player   │ Team │",
+					"├────────────┼──────┤",
+					"│ Ostby, Jay │ CRA  │",
+					"└────────────┴──────┘",
+					"",
+				}, "\n"),
+			},
+		},
+		"Compact": {
+			reason: "Compact tables should have no borders.",
+			format: FormatTable,
+			opts:   []PrinterOption{Compact()},
+			want: want{
+				out: strings.Join([]string{
+					" Machine  P50  ",
+					"───────────────",
+					" TAF      1.2M ",
+					"Strongest: TAF",
+					"   Player    Team ",
+					"──────────────────",
+					" Ostby, Jay  CRA  ",
+					"",
+				}, "\n"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out, errs strings.Builder
+			p := NewPrinter(tc.format, &out, &errs, tc.opts...)
+			if err := p.Table([]string{"Machine", "P50"}, [][]string{{"TAF", "1.2M"}}); err != nil {
+				t.Fatalf("Table(...): %v", err)
+			}
+			p.Printf("Strongest: %s\n", "TAF")
+			if err := p.Table([]string{"Player", "Team"}, [][]string{{"Ostby, Jay", "CRA"}}); err != nil {
+				t.Fatalf("Table(...): %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want.out, out.String()); diff != "" {
+				t.Errorf("\n%s\nPrinter out: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.notes, errs.String()); diff != "" {
+				t.Errorf("\n%s\nPrinter errs: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -8,12 +8,57 @@ import (
 	"github.com/olekukonko/tablewriter/tw"
 )
 
+// A TableOption configures a table.
+type TableOption func(*tableOptions)
+
+type tableOptions struct {
+	wrap    map[int]int
+	compact bool
+}
+
+// WrapColumn wraps the words of the supplied column (counting from zero) onto
+// new lines past the supplied width, for columns such as lists that would
+// otherwise make the table too wide for a terminal.
+func WrapColumn(col, width int) TableOption {
+	return func(o *tableOptions) {
+		o.wrap[col] = width
+	}
+}
+
+// Borderless renders a table without borders or lines between columns, so it
+// fits narrow screens.
+func Borderless() TableOption {
+	return func(o *tableOptions) {
+		o.compact = true
+	}
+}
+
 // Table renders a bordered ASCII table to the given writer.
-func Table(w io.Writer, headers []string, rows [][]string) error {
-	t := tablewriter.NewTable(w,
+func Table(w io.Writer, headers []string, rows [][]string, opts ...TableOption) error {
+	o := &tableOptions{wrap: make(map[int]int)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	wrap := tw.WrapNone
+	if len(o.wrap) > 0 {
+		wrap = tw.WrapNormal
+	}
+	topts := []tablewriter.Option{
 		tablewriter.WithHeaderAutoFormat(tw.Off),
-		tablewriter.WithRowAutoWrap(tw.WrapNone),
-	)
+		tablewriter.WithRowAutoWrap(wrap),
+		tablewriter.WithColumnWidths(o.wrap),
+	}
+	if o.compact {
+		topts = append(topts, tablewriter.WithRendition(tw.Rendition{
+			Borders: tw.BorderNone,
+			Settings: tw.Settings{
+				Lines:      tw.Lines{ShowHeaderLine: tw.On},
+				Separators: tw.Separators{BetweenColumns: tw.Off},
+			},
+		}))
+	}
+	t := tablewriter.NewTable(w, topts...)
 
 	h := make([]any, len(headers))
 	for i, v := range headers {
@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/ical"
+)
+
+// Length is roughly how long a match night lasts, from the start of round 1 to
+// the end of round 4.
+const Length = 3 * time.Hour
+
+// Calendar returns a calendar of the supplied matches that the team plays in,
+// or of every match if the team is empty. Each event starts the supplied
+// duration before the match does, so there's time to arrive and warm up.
+func (c Clock) Calendar(matches []db.ScheduleMatch, team string, arrive time.Duration, stamp time.Time) (ical.Calendar, error) {
+	cal := ical.Calendar{Name: "MNP matches", Stamp: stamp}
+	if team != "" {
+		cal.Name = team + " matches"
+	}
+
+	for _, m := range matches {
+		if team != "" && !plays(m, []string{team}) {
+			continue
+		}
+		start, err := c.StartTime(m.Date)
+		if err != nil {
+			return ical.Calendar{}, err
+		}
+
+		desc := fmt.Sprintf("%s. Matches start at %s.", m.WeekName(), start.Format("3:04pm"))
+		if arrive > 0 {
+			desc = fmt.Sprintf("%s. Arrive by %s, matches start at %s.", m.WeekName(), start.Add(-arrive).Format("3:04pm"), start.Format("3:04pm"))
+		}
+
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         m.Key + "@mnp",
+			Summary:     fmt.Sprintf("%s vs %s", m.HomeTeam, m.AwayTeam),
+			Location:    m.Venue,
+			Description: desc,
+			Start:       start.Add(-arrive),
+			End:         start.Add(Length),
+		})
+	}
+	return cal, nil
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/ical"
+)
+
+func TestCalendar(t *testing.T) {
+	seattle, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Clock{Location: seattle, Start: DefaultStart, Rollover: DefaultRollover}
+	stamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	matches := []db.ScheduleMatch{
+		{Key: "mnp-23-1-TTT-KNR", Week: 1, Date: "2025-01-06", HomeTeamKey: "TTT", HomeTeam: "The Trailer Trashers", AwayTeamKey: "KNR", AwayTeam: "Knight Riders", VenueKey: "STN", Venue: "Seattle Tavern"},
+		{Key: "mnp-23-1-CRA-PYC", Week: 1, Date: "2025-01-06", HomeTeamKey: "CRA", HomeTeam: "Castle Crashers", AwayTeamKey: "PYC", AwayTeam: "Pinball Pythons"},
+	}
+
+	type args struct {
+		matches []db.ScheduleMatch
+		team    string
+		arrive  time.Duration
+	}
+	type want struct {
+		cal ical.Calendar
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Team": {
+			reason: "A team's calendar should only include its matches, starting early enough to arrive before the match.",
+			args:   args{matches: matches, team: "KNR", arrive: 30 * time.Minute},
+			want: want{cal: ical.Calendar{
+				Name:  "KNR matches",
+				Stamp: stamp,
+				Events: []ical.Event{{
+					UID:         "mnp-23-1-TTT-KNR@mnp",
+					Summary:     "The Trailer Trashers vs Knight Riders",
+					Location:    "Seattle Tavern",
+					Description: "Week 1. Arrive by 7:30pm, matches start at 8:00pm.",
+					Start:       time.Date(2025, 1, 6, 19, 30, 0, 0, seattle),
+					End:         time.Date(2025, 1, 6, 23, 0, 0, 0, seattle),
+				}},
+			}},
+		},
+		"League": {
+			reason: "Without a team, the calendar should include every match, starting when the matches do.",
+			args:   args{matches: matches},
+			want: want{cal: ical.Calendar{
+				Name:  "MNP matches",
+				Stamp: stamp,
+				Events: []ical.Event{
+					{
+						UID:         "mnp-23-1-TTT-KNR@mnp",
+						Summary:     "The Trailer Trashers vs Knight Riders",
+						Location:    "Seattle Tavern",
+						Description: "Week 1. Matches start at 8:00pm.",
+						Start:       time.Date(2025, 1, 6, 20, 0, 0, 0, seattle),
+						End:         time.Date(2025, 1, 6, 23, 0, 0, 0, seattle),
+					},
+					{
+						UID:         "mnp-23-1-CRA-PYC@mnp",
+						Summary:     "Castle Crashers vs Pinball Pythons",
+						Description: "Week 1. Matches start at 8:00pm.",
+						Start:       time.Date(2025, 1, 6, 20, 0, 0, 0, seattle),
+						End:         time.Date(2025, 1, 6, 23, 0, 0, 0, seattle),
+					},
+				},
+			}},
+		},
+		"BadDate": {
+			reason: "A match with an unparseable date should return an error.",
+			args:   args{matches: []db.ScheduleMatch{{Key: "mnp-23-1-TTT-KNR", Date: "soon"}}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.Calendar(tc.args.matches, tc.args.team, tc.args.arrive, stamp)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCalendar(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cal, got); diff != "" {
+				t.Errorf("\n%s\nCalendar(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// Store is the set of queries needed to look up upcoming matches.
+type Store interface {
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+// NextMatch returns the first match on or after the supplied date that all
+// the supplied teams play in, or nil if there isn't one.
+func NextMatch(ctx context.Context, s Store, date string, teams ...string) (*db.ScheduleMatch, error) {
+	sched, err := s.ListSchedule(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("load schedule: %w", err)
+	}
+
+	for _, m := range sched {
+		if plays(m, teams) {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// NextVenue returns a team's next match on or after the supplied date with a
+// known venue. If the second team is not empty, their next match against each
+// other is preferred. It returns nil if there isn't one.
+func NextVenue(ctx context.Context, s Store, date, team, opponent string) (*db.ScheduleMatch, error) {
+	if opponent != "" {
+		m, err := NextMatch(ctx, s, date, team, opponent)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil && m.VenueKey != "" {
+			return m, nil
+		}
+	}
+
+	m, err := NextMatch(ctx, s, date, team)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.VenueKey == "" {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// Opponent returns the team's opponent in the supplied match.
+func Opponent(m db.ScheduleMatch, team string) string {
+	if m.HomeTeamKey == team {
+		return m.AwayTeamKey
+	}
+	return m.HomeTeamKey
+}
+
+func plays(m db.ScheduleMatch, teams []string) bool {
+	for _, t := range teams {
+		if m.HomeTeamKey != t && m.AwayTeamKey != t {
+			return false
+		}
+	}
+	return true
+}
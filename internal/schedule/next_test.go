@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockListSchedule func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func TestNextVenue(t *testing.T) {
+	week1 := db.ScheduleMatch{Week: 1, HomeTeamKey: "CRA", AwayTeamKey: "KNR", VenueKey: "8BT"}
+	week2 := db.ScheduleMatch{Week: 2, HomeTeamKey: "PYC", AwayTeamKey: "CRA", VenueKey: "ANC"}
+	week3 := db.ScheduleMatch{Week: 3, HomeTeamKey: "TTT", AwayTeamKey: "CRA"}
+
+	store := &MockStore{
+		MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+			return []db.ScheduleMatch{week1, week2, week3}, nil
+		},
+	}
+
+	type args struct {
+		store    Store
+		team     string
+		opponent string
+	}
+	type want struct {
+		match *db.ScheduleMatch
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NextMatch": {
+			reason: "Without an opponent, the team's next match should be returned.",
+			args:   args{store: store, team: "CRA"},
+			want:   want{match: &week1},
+		},
+		"VsOpponent": {
+			reason: "With an opponent, the team's next match against it should be returned.",
+			args:   args{store: store, team: "CRA", opponent: "PYC"},
+			want:   want{match: &week2},
+		},
+		"OpponentNotScheduled": {
+			reason: "If the teams don't play each other, the team's next match should be returned.",
+			args:   args{store: store, team: "CRA", opponent: "SKP"},
+			want:   want{match: &week1},
+		},
+		"NoVenue": {
+			reason: "A next match without a known venue should count as no match.",
+			args:   args{store: store, team: "TTT"},
+			want:   want{match: nil},
+		},
+		"NotScheduled": {
+			reason: "A team with no upcoming matches should have no next venue.",
+			args:   args{store: store, team: "SKP"},
+			want:   want{match: nil},
+		},
+		"ScheduleError": {
+			reason: "An error loading the schedule should be returned.",
+			args: args{store: &MockStore{
+				MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+					return nil, errors.New("boom")
+				},
+			}, team: "CRA"},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NextVenue(context.Background(), tc.args.store, "2025-01-01", tc.args.team, tc.args.opponent)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNextVenue(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.match, got); diff != "" {
+				t.Errorf("\n%s\nNextVenue(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package schedule works out when league matches happen.
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of match dates in the MNP archive, e.g. 2025-02-07.
+const DateLayout = "2006-01-02"
+
+// Defaults for the Seattle league.
+const (
+	DefaultTimezone = "America/Los_Angeles"
+	DefaultStart    = 20 * time.Hour
+	DefaultRollover = 3 * time.Hour
+)
+
+// A Clock maps bare match dates to moments in time.
+type Clock struct {
+	// Location is the league's timezone. Match dates are in this timezone.
+	Location *time.Location
+
+	// Start is when matches start, as an offset from midnight on the match
+	// date.
+	Start time.Duration
+
+	// Rollover is how long after midnight the previous night's matches are
+	// considered over. Until then, "tonight" is still the previous date.
+	Rollover time.Duration
+}
+
+// NewClock returns a clock for the named IANA timezone (e.g.
+// America/Los_Angeles), with the default start and rollover times.
+func NewClock(tz string) (Clock, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return Clock{}, fmt.Errorf("load timezone %q: %w", tz, err)
+	}
+	return Clock{Location: loc, Start: DefaultStart, Rollover: DefaultRollover}, nil
+}
+
+// Today returns the league date of the match night in progress at the supplied
+// time. Just after midnight this is still the previous date.
+func (c Clock) Today(now time.Time) string {
+	return now.In(c.location()).Add(-c.Rollover).Format(DateLayout)
+}
+
+// StartTime returns when matches on the supplied date start.
+func (c Clock) StartTime(date string) (time.Time, error) {
+	d, err := time.ParseInLocation(DateLayout, date, c.location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse match date %q: %w", date, err)
+	}
+	// Add to the wall clock date rather than the instant, so matches start at
+	// the same local time either side of a daylight saving change.
+	h, m := int(c.Start/time.Hour), int(c.Start%time.Hour/time.Minute)
+	return time.Date(d.Year(), d.Month(), d.Day(), h, m, 0, 0, c.location()), nil
+}
+
+// Countdown describes how long until matches on the supplied date, e.g.
+// "tonight", "tomorrow", or "in 3 days". It returns an empty string for past
+// or unparseable dates.
+func (c Clock) Countdown(now time.Time, date string) string {
+	today, err := time.Parse(DateLayout, c.Today(now))
+	if err != nil {
+		return ""
+	}
+	d, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return ""
+	}
+
+	// Both dates are parsed as UTC midnight, so there's no daylight saving
+	// change to throw the division off.
+	switch days := int(d.Sub(today).Hours() / 24); {
+	case days < 0:
+		return ""
+	case days == 0:
+		return "tonight"
+	case days == 1:
+		return "tomorrow"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
+
+func (c Clock) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestToday(t *testing.T) {
+	seattle, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Clock{Location: seattle, Start: DefaultStart, Rollover: DefaultRollover}
+
+	type args struct {
+		now time.Time
+	}
+	type want struct {
+		date string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Evening": {
+			reason: "Match night should be the current league date.",
+			args:   args{now: time.Date(2025, 2, 10, 21, 0, 0, 0, seattle)},
+			want:   want{date: "2025-02-10"},
+		},
+		"JustAfterMidnight": {
+			reason: "Matches running past midnight should still be tonight's matches.",
+			args:   args{now: time.Date(2025, 2, 11, 0, 30, 0, 0, seattle)},
+			want:   want{date: "2025-02-10"},
+		},
+		"Morning": {
+			reason: "After the rollover the league date should advance.",
+			args:   args{now: time.Date(2025, 2, 11, 9, 0, 0, 0, seattle)},
+			want:   want{date: "2025-02-11"},
+		},
+		"OtherTimezone": {
+			reason: "A time in another timezone should use the league's date, not the local one.",
+			args:   args{now: time.Date(2025, 2, 11, 4, 0, 0, 0, time.FixedZone("EST", -5*60*60))},
+			want:   want{date: "2025-02-10"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := c.Today(tc.args.now)
+			if diff := cmp.Diff(tc.want.date, got); diff != "" {
+				t.Errorf("\n%s\nToday(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStartTime(t *testing.T) {
+	seattle, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Clock{Location: seattle, Start: 20*time.Hour + 15*time.Minute}
+
+	type args struct {
+		date string
+	}
+	type want struct {
+		t   time.Time
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Winter": {
+			reason: "Matches should start at the start time in the league's timezone.",
+			args:   args{date: "2025-02-10"},
+			want:   want{t: time.Date(2025, 2, 11, 4, 15, 0, 0, time.UTC)},
+		},
+		"DaylightSaving": {
+			reason: "The start time should be wall clock time, even on the day clocks change.",
+			args:   args{date: "2025-03-09"},
+			want:   want{t: time.Date(2025, 3, 10, 3, 15, 0, 0, time.UTC)},
+		},
+		"BadDate": {
+			reason: "An unparseable date should return an error.",
+			args:   args{date: "Feb 10"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.StartTime(tc.args.date)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nStartTime(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.t, got, cmpopts.EquateApproxTime(0)); diff != "" {
+				t.Errorf("\n%s\nStartTime(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	seattle, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Clock{Location: seattle, Start: DefaultStart, Rollover: DefaultRollover}
+	now := time.Date(2025, 2, 10, 18, 0, 0, 0, seattle)
+
+	type args struct {
+		date string
+	}
+	type want struct {
+		countdown string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Tonight": {
+			reason: "A match today should be tonight.",
+			args:   args{date: "2025-02-10"},
+			want:   want{countdown: "tonight"},
+		},
+		"Tomorrow": {
+			reason: "A match the next day should be tomorrow.",
+			args:   args{date: "2025-02-11"},
+			want:   want{countdown: "tomorrow"},
+		},
+		"Days": {
+			reason: "A match further out should count the days.",
+			args:   args{date: "2025-02-17"},
+			want:   want{countdown: "in 7 days"},
+		},
+		"AcrossDaylightSaving": {
+			reason: "Counting days across a daylight saving change shouldn't be off by one.",
+			args:   args{date: "2025-03-10"},
+			want:   want{countdown: "in 28 days"},
+		},
+		"Past": {
+			reason: "A past match should have no countdown.",
+			args:   args{date: "2025-02-03"},
+			want:   want{countdown: ""},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := c.Countdown(now, tc.args.date)
+			if diff := cmp.Diff(tc.want.countdown, got); diff != "" {
+				t.Errorf("\n%s\nCountdown(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package accuracy tracks how well matchup predictions match actual results.
+package accuracy
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// Store is the set of queries needed for accuracy analysis.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error)
+}
+
+// RecordStore is the set of queries needed to record predictions.
+type RecordStore interface {
+	matchup.Store
+
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+	UpsertPrediction(ctx context.Context, p db.Prediction) error
+}
+
+// Record predicts the edge on every machine at each match in the next week
+// of the schedule on or after the supplied date, replacing earlier predictions
+// for matches that haven't been played yet. It returns the number of
+// predictions recorded.
+func Record(ctx context.Context, s RecordStore, date string) (int, error) {
+	sched, err := s.ListSchedule(ctx, date)
+	if err != nil {
+		return 0, fmt.Errorf("load schedule: %w", err)
+	}
+
+	n := 0
+	for _, m := range sched {
+		if m.Week != sched[0].Week {
+			break
+		}
+		if m.VenueKey == "" {
+			continue
+		}
+
+		r, err := matchup.Analyze(ctx, s, m.VenueKey, m.HomeTeamKey, m.AwayTeamKey)
+		if err != nil {
+			return n, fmt.Errorf("predict %s: %w", m.Key, err)
+		}
+
+		for _, mm := range r.Machines {
+			p := db.Prediction{
+				MatchKey:    m.Key,
+				MachineKey:  mm.MachineKey,
+				HomeTeamKey: m.HomeTeamKey,
+				AwayTeamKey: m.AwayTeamKey,
+				Edge:        mm.Edge,
+				Confidence:  int(mm.Confidence),
+			}
+			if err := s.UpsertPrediction(ctx, p); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Tally counts how often predictions favored the team that won.
+type Tally struct {
+	Predictions int // Predictions for machines that were played.
+	Correct     int // Predictions that favored the team that won more points.
+	Pushes      int // Predictions of an even game, or games where teams split the points.
+}
+
+// Accuracy returns the fraction of decided predictions that were correct,
+// between 0 and 1. Pushes aren't counted.
+func (t Tally) Accuracy() float64 {
+	decided := t.Predictions - t.Pushes
+	if decided == 0 {
+		return 0
+	}
+	return float64(t.Correct) / float64(decided)
+}
+
+// Add counts a prediction's outcome.
+func (t *Tally) Add(o db.PredictionOutcome) {
+	t.Predictions++
+
+	predicted := math.Round(o.Edge)
+	actual := o.HomePoints - o.AwayPoints
+	switch {
+	case predicted == 0 || actual == 0:
+		t.Pushes++
+	case (predicted > 0) == (actual > 0):
+		t.Correct++
+	}
+}
+
+// MachineAccuracy is the prediction tally for a single machine.
+type MachineAccuracy struct {
+	MachineKey  string
+	MachineName string
+	Tally
+}
+
+// ConfidenceAccuracy is the prediction tally for a single confidence level.
+type ConfidenceAccuracy struct {
+	Confidence matchup.Confidence
+	Tally
+}
+
+// Result is the output of an Accuracy query.
+type Result struct {
+	Overall      Tally
+	ByConfidence []ConfidenceAccuracy // High confidence first.
+	Machines     []MachineAccuracy    // Most predicted first.
+}
+
+// Analyze compares recorded predictions with the results of the games they
+// predicted.
+func Analyze(ctx context.Context, s Store) (*Result, error) {
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	outcomes, err := s.ListPredictionOutcomes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load prediction outcomes: %w", err)
+	}
+
+	return Summarize(names, outcomes), nil
+}
+
+// Summarize tallies prediction outcomes overall, by confidence, and by
+// machine. Machines are named using the supplied map of key to name.
+func Summarize(names map[string]string, outcomes []db.PredictionOutcome) *Result {
+	r := &Result{ByConfidence: []ConfidenceAccuracy{
+		{Confidence: matchup.ConfidenceHigh},
+		{Confidence: matchup.ConfidenceMedium},
+		{Confidence: matchup.ConfidenceLow},
+	}}
+	machines := make(map[string]*MachineAccuracy)
+	for _, o := range outcomes {
+		r.Overall.Add(o)
+
+		for i := range r.ByConfidence {
+			if r.ByConfidence[i].Confidence == matchup.Confidence(o.Confidence) {
+				r.ByConfidence[i].Add(o)
+			}
+		}
+
+		m, ok := machines[o.MachineKey]
+		if !ok {
+			m = &MachineAccuracy{MachineKey: o.MachineKey, MachineName: output.MachineName(names, o.MachineKey)}
+			machines[o.MachineKey] = m
+		}
+		m.Add(o)
+	}
+
+	r.Machines = make([]MachineAccuracy, 0, len(machines))
+	for _, m := range machines {
+		r.Machines = append(r.Machines, *m)
+	}
+	slices.SortFunc(r.Machines, func(a, b MachineAccuracy) int {
+		if c := cmp.Compare(b.Predictions, a.Predictions); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+
+	return r
+}
@@ -0,0 +1,208 @@
+package accuracy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+type MockStore struct {
+	MockGetMachineNames        func(ctx context.Context) (map[string]string, error)
+	MockGetTeamMachineStats    func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines       func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListSchedule           func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+	MockUpsertPrediction       func(ctx context.Context, p db.Prediction) error
+	MockListPredictionOutcomes func(ctx context.Context) ([]db.PredictionOutcome, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func (m *MockStore) UpsertPrediction(ctx context.Context, p db.Prediction) error {
+	return m.MockUpsertPrediction(ctx, p)
+}
+
+func (m *MockStore) ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error) {
+	return m.MockListPredictionOutcomes(ctx)
+}
+
+func TestAnalyze(t *testing.T) {
+	names := map[string]string{
+		"TAF": "The Addams Family",
+		"MM":  "Medieval Madness",
+	}
+
+	outcome := func(machine string, edge float64, conf matchup.Confidence, home, away float64) db.PredictionOutcome {
+		return db.PredictionOutcome{
+			Prediction: db.Prediction{MachineKey: machine, Edge: edge, Confidence: int(conf)},
+			HomePoints: home,
+			AwayPoints: away,
+		}
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  *MockStore
+		want   want
+	}{
+		"Ledger": {
+			reason: "Predictions should be tallied overall, by confidence, and by machine.",
+			store: &MockStore{
+				MockGetMachineNames: func(_ context.Context) (map[string]string, error) { return names, nil },
+				MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+					return []db.PredictionOutcome{
+						outcome("TAF", 20, matchup.ConfidenceHigh, 5, 0),    // Correct.
+						outcome("TAF", -15, matchup.ConfidenceHigh, 4, 1),   // Wrong.
+						outcome("TAF", 0.2, matchup.ConfidenceMedium, 3, 0), // Predicted even.
+						outcome("MM", -30, matchup.ConfidenceLow, 0, 3),     // Correct.
+						outcome("MM", 10, matchup.ConfidenceLow, 1.5, 1.5),  // Split points.
+					}, nil
+				},
+			},
+			want: want{result: &Result{
+				Overall: Tally{Predictions: 5, Correct: 2, Pushes: 2},
+				ByConfidence: []ConfidenceAccuracy{
+					{Confidence: matchup.ConfidenceHigh, Tally: Tally{Predictions: 2, Correct: 1}},
+					{Confidence: matchup.ConfidenceMedium, Tally: Tally{Predictions: 1, Pushes: 1}},
+					{Confidence: matchup.ConfidenceLow, Tally: Tally{Predictions: 2, Correct: 1, Pushes: 1}},
+				},
+				Machines: []MachineAccuracy{
+					{MachineKey: "TAF", MachineName: "The Addams Family", Tally: Tally{Predictions: 3, Correct: 1, Pushes: 1}},
+					{MachineKey: "MM", MachineName: "Medieval Madness", Tally: Tally{Predictions: 2, Correct: 1, Pushes: 1}},
+				},
+			}},
+		},
+		"NoPredictions": {
+			reason: "An empty ledger should return zero tallies.",
+			store: &MockStore{
+				MockGetMachineNames:        func(_ context.Context) (map[string]string, error) { return names, nil },
+				MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) { return nil, nil },
+			},
+			want: want{result: &Result{
+				ByConfidence: []ConfidenceAccuracy{
+					{Confidence: matchup.ConfidenceHigh},
+					{Confidence: matchup.ConfidenceMedium},
+					{Confidence: matchup.ConfidenceLow},
+				},
+				Machines: []MachineAccuracy{},
+			}},
+		},
+		"OutcomesError": {
+			reason: "An error loading outcomes should be returned.",
+			store: &MockStore{
+				MockGetMachineNames:        func(_ context.Context) (map[string]string, error) { return names, nil },
+				MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) { return nil, errors.New("boom") },
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.store)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTallyAccuracy(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tally  Tally
+		want   float64
+	}{
+		"IgnoresPushes": {
+			reason: "Pushes shouldn't count against accuracy.",
+			tally:  Tally{Predictions: 5, Correct: 3, Pushes: 1},
+			want:   0.75,
+		},
+		"AllPushes": {
+			reason: "A tally with no decided predictions should have zero accuracy.",
+			tally:  Tally{Predictions: 2, Pushes: 2},
+			want:   0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.tally.Accuracy()); diff != "" {
+				t.Errorf("\n%s\nAccuracy(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	stats := map[string][]db.TeamMachineStats{
+		"HOM": {{MachineKey: "TAF", LikelyPlayers: []db.LikelyPlayer{{Name: "Hank", Games: 10, P50Score: 150}}}},
+		"AWY": {{MachineKey: "TAF", LikelyPlayers: []db.LikelyPlayer{{Name: "Andy", Games: 10, P50Score: 100}}}},
+	}
+
+	var got []db.Prediction
+	s := &MockStore{
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) { return nil, nil },
+		MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
+			return stats[teamKey], nil
+		},
+		MockGetVenueMachines: func(_ context.Context, venueKey string, _ int) (map[string]bool, error) {
+			return map[string]bool{"TAF": venueKey == "STN"}, nil
+		},
+		MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+			return []db.ScheduleMatch{
+				{Key: "mnp-23-1-HOM-AWY", Week: 1, HomeTeamKey: "HOM", AwayTeamKey: "AWY", VenueKey: "STN"},
+				{Key: "mnp-23-1-OTH-TBD", Week: 1, HomeTeamKey: "OTH", AwayTeamKey: "TBD"},
+				{Key: "mnp-23-2-AWY-HOM", Week: 2, HomeTeamKey: "AWY", AwayTeamKey: "HOM", VenueKey: "STN"},
+			}, nil
+		},
+		MockUpsertPrediction: func(_ context.Context, p db.Prediction) error {
+			got = append(got, p)
+			return nil
+		},
+	}
+
+	n, err := Record(context.Background(), s, "2024-01-01")
+	if err != nil {
+		t.Fatalf("Record(...): %v", err)
+	}
+
+	// Only the next week is predicted, and matches without a venue are skipped.
+	want := []db.Prediction{
+		{MatchKey: "mnp-23-1-HOM-AWY", MachineKey: "TAF", HomeTeamKey: "HOM", AwayTeamKey: "AWY", Edge: 50, Confidence: int(matchup.ConfidenceHigh)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Record(...): -want predictions, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(len(want), n); diff != "" {
+		t.Errorf("Record(...): -want count, +got:\n%s", diff)
+	}
+}
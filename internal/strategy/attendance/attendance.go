@@ -0,0 +1,78 @@
+// Package attendance reports how often each player on a team's roster has
+// played this season, and which matches they've missed.
+package attendance
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// Store is the set of queries needed for an attendance report.
+type Store interface {
+	GetTeamAbsences(ctx context.Context, teamKey string) (map[string][]int, error)
+	GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+}
+
+// Player is a player on the team's roster, and how often they've played.
+type Player struct {
+	Name    string
+	Matches int   // Matches the player was in the lineup for.
+	Games   int   // Games the player played, at most one per round.
+	Missed  []int // Weeks of the matches the player wasn't in the lineup for.
+}
+
+// RoundsPerMatch returns how many rounds the player plays in an average match
+// they're in the lineup for.
+func (p Player) RoundsPerMatch() float64 {
+	if p.Matches == 0 {
+		return 0
+	}
+	return float64(p.Games) / float64(p.Matches)
+}
+
+// Result is the output of an attendance report.
+type Result struct {
+	Team    string
+	Matches int      // Matches the team has played this season.
+	Players []Player // Most matches first, then most games.
+}
+
+// Analyze returns how many of a team's matches this season each player on its
+// current roster was in the lineup for, how many rounds they played, and the
+// weeks they missed.
+func Analyze(ctx context.Context, s Store, team string) (*Result, error) {
+	attendance, err := s.GetTeamAttendance(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load attendance: %w", err)
+	}
+
+	absences, err := s.GetTeamAbsences(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load absences: %w", err)
+	}
+
+	r := &Result{Team: team, Players: make([]Player, 0, len(attendance))}
+	for name, a := range attendance {
+		r.Matches = a.TeamMatches
+		r.Players = append(r.Players, Player{
+			Name:    name,
+			Matches: a.Matches,
+			Games:   a.Games,
+			Missed:  absences[name],
+		})
+	}
+
+	slices.SortFunc(r.Players, func(a, b Player) int {
+		return cmp.Or(
+			cmp.Compare(b.Matches, a.Matches),
+			cmp.Compare(b.Games, a.Games),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	return r, nil
+}
@@ -0,0 +1,143 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetTeamAbsences   func(ctx context.Context, teamKey string) (map[string][]int, error)
+	MockGetTeamAttendance func(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+}
+
+func (m *MockStore) GetTeamAbsences(ctx context.Context, teamKey string) (map[string][]int, error) {
+	return m.MockGetTeamAbsences(ctx, teamKey)
+}
+
+func (m *MockStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return m.MockGetTeamAttendance(ctx, teamKey)
+}
+
+func TestAnalyze(t *testing.T) {
+	attendance := func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+		return map[string]db.Attendance{
+			"Alice": {Matches: 3, TeamMatches: 3, Games: 12},
+			"Bob":   {Matches: 3, TeamMatches: 3, Games: 9},
+			"Carol": {Matches: 2, TeamMatches: 3, Games: 6},
+			"Dave":  {Matches: 2, TeamMatches: 3, Games: 6},
+			"Erin":  {TeamMatches: 3},
+		}, nil
+	}
+	absences := func(_ context.Context, _ string) (map[string][]int, error) {
+		return map[string][]int{
+			"Carol": {3},
+			"Dave":  {1},
+			"Erin":  {1, 2, 3},
+		}, nil
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  Store
+		want   want
+	}{
+		"Success": {
+			reason: "Players should be ordered by matches, then games, then name, with the weeks they missed.",
+			store:  &MockStore{MockGetTeamAttendance: attendance, MockGetTeamAbsences: absences},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Matches: 3,
+				Players: []Player{
+					{Name: "Alice", Matches: 3, Games: 12},
+					{Name: "Bob", Matches: 3, Games: 9},
+					{Name: "Carol", Matches: 2, Games: 6, Missed: []int{3}},
+					{Name: "Dave", Matches: 2, Games: 6, Missed: []int{1}},
+					{Name: "Erin", Missed: []int{1, 2, 3}},
+				},
+			}},
+		},
+		"NoRoster": {
+			reason: "A team without a roster should return no players.",
+			store: &MockStore{
+				MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+					return nil, nil
+				},
+				MockGetTeamAbsences: func(_ context.Context, _ string) (map[string][]int, error) {
+					return nil, nil
+				},
+			},
+			want: want{result: &Result{Team: "CRA", Players: []Player{}}},
+		},
+		"GetTeamAttendanceError": {
+			reason: "An error loading attendance should be returned.",
+			store: &MockStore{
+				MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+					return nil, errors.New("boom")
+				},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"GetTeamAbsencesError": {
+			reason: "An error loading absences should be returned.",
+			store: &MockStore{
+				MockGetTeamAttendance: attendance,
+				MockGetTeamAbsences: func(_ context.Context, _ string) (map[string][]int, error) {
+					return nil, errors.New("boom")
+				},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.store, "CRA")
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want result, +got result:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRoundsPerMatch(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		player Player
+		want   float64
+	}{
+		"Played": {
+			reason: "Rounds per match should be games over matches.",
+			player: Player{Matches: 2, Games: 5},
+			want:   2.5,
+		},
+		"NeverPlayed": {
+			reason: "A player who hasn't been in a lineup should average zero rounds.",
+			player: Player{},
+			want:   0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.player.RoundsPerMatch(); got != tc.want {
+				t.Errorf("\n%s\nRoundsPerMatch(): want %v, got %v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
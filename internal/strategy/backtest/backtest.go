@@ -0,0 +1,374 @@
+// Package backtest replays past matches to measure how well matchup
+// predictions would have done, predicting each match using only games played
+// before it.
+package backtest
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/accuracy"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// likelyPlayers is how many players per machine are likely to play it, like
+// the store's GetTopPlayers.
+const likelyPlayers = 2
+
+// Store is the set of queries needed for a backtest.
+type Store interface {
+	GetGameLog(ctx context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error)
+	ListSeasons(ctx context.Context) ([]int, error)
+}
+
+// EdgeAccuracy is the prediction tally for predicted edges in a range, as
+// percentages.
+type EdgeAccuracy struct {
+	Min float64 // Inclusive.
+	Max float64 // Exclusive. Infinite for the largest edges.
+	accuracy.Tally
+}
+
+// edgeBounds are the lower bounds of each EdgeAccuracy range.
+var edgeBounds = []float64{0, 10, 25, 50, 100}
+
+// SeasonAccuracy is the prediction tally for a single season.
+type SeasonAccuracy struct {
+	Season int
+	accuracy.Tally
+}
+
+// Result is the output of a backtest.
+type Result struct {
+	Seasons  []int // Oldest first.
+	Matches  int   // Matches replayed.
+	Accuracy *accuracy.Result
+	ByEdge   []EdgeAccuracy   // Smallest edges first.
+	BySeason []SeasonAccuracy // Oldest first.
+}
+
+// BeatsCoinFlip returns true if a tally's decided predictions were right
+// significantly more often than a coin flip would be, at 95% confidence by a
+// one-sided binomial test.
+func BeatsCoinFlip(t accuracy.Tally) bool {
+	decided := float64(t.Predictions - t.Pushes)
+	if decided == 0 {
+		return false
+	}
+	// The normal approximation to the binomial distribution. A fair coin is
+	// right on half of n flips, with a standard deviation of sqrt(n)/2.
+	z := (float64(t.Correct) - decided/2) / (math.Sqrt(decided) / 2)
+	return z >= 1.645
+}
+
+// Option configures a backtest.
+type Option func(*Options)
+
+// Options holds optional parameters for a backtest.
+type Options struct {
+	seasons []int
+}
+
+// InSeasons replays matches from the supplied seasons, rather than only the
+// latest season.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// Analyze replays every match in the backtested seasons. It predicts each
+// match like matchup.Analyze would have the day before it was played, using
+// only the games played in earlier weeks, then tallies how often the team
+// with the predicted edge on each machine that was played won more of its
+// points.
+//
+// Each team's players are its roster that season, and its venue's machines
+// are the machines that were played. Unlike the store's stats, earlier
+// seasons aren't weighted less and scores aren't adjusted for opponents, so
+// the backtest measures the matchup model rather than those refinements.
+func Analyze(ctx context.Context, s Store, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	seasons := slices.Clone(o.seasons)
+	if len(seasons) == 0 {
+		all, err := s.ListSeasons(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load seasons: %w", err)
+		}
+		if len(all) > 0 {
+			seasons = all[:1]
+		}
+	}
+	slices.Sort(seasons)
+	seasons = slices.Compact(seasons)
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	games, err := s.GetGameLog(ctx, db.GameLogFilter{Sort: db.SortOldest})
+	if err != nil {
+		return nil, fmt.Errorf("load games: %w", err)
+	}
+
+	r := &Result{Seasons: seasons}
+	bySeason := make(map[int]*SeasonAccuracy)
+	for _, season := range seasons {
+		r.BySeason = append(r.BySeason, SeasonAccuracy{Season: season})
+	}
+	for i := range r.BySeason {
+		bySeason[r.BySeason[i].Season] = &r.BySeason[i]
+	}
+	for i, lo := range edgeBounds {
+		hi := math.Inf(1)
+		if i+1 < len(edgeBounds) {
+			hi = edgeBounds[i+1]
+		}
+		r.ByEdge = append(r.ByEdge, EdgeAccuracy{Min: lo, Max: hi})
+	}
+
+	h := &history{
+		scores:  make(map[string]map[string][]float64),
+		rosters: make(map[string][]string),
+		played:  make(map[string]map[string]bool),
+	}
+	var outcomes []db.PredictionOutcome
+	for _, week := range weeks(games) {
+		if _, ok := bySeason[week[0].Season]; ok {
+			for _, m := range matches(week) {
+				predicted, err := predict(ctx, s, h, names, m)
+				if err != nil {
+					return nil, err
+				}
+				r.Matches++
+				for _, po := range predicted {
+					bySeason[m.season].Add(po)
+					for i := range r.ByEdge {
+						if e := math.Abs(po.Edge); e >= r.ByEdge[i].Min && e < r.ByEdge[i].Max {
+							r.ByEdge[i].Add(po)
+						}
+					}
+				}
+				outcomes = append(outcomes, predicted...)
+			}
+		}
+		h.add(week)
+	}
+
+	r.Accuracy = accuracy.Summarize(names, outcomes)
+	return r, nil
+}
+
+// A match is the games two teams played against each other one week.
+type match struct {
+	season int
+	teams  [2]string
+	points map[string][2]float64 // Each team's points on each machine, by machine key.
+}
+
+// weeks splits games, ordered oldest first, into the weeks they were played.
+func weeks(games []db.GameLogEntry) [][]db.GameLogEntry {
+	var out [][]db.GameLogEntry
+	for i, g := range games {
+		if i == 0 || g.Season != games[i-1].Season || g.Week != games[i-1].Week {
+			out = append(out, nil)
+		}
+		out[len(out)-1] = append(out[len(out)-1], g)
+	}
+	return out
+}
+
+// matches groups a week's games into matches, in the order they were first
+// played.
+func matches(week []db.GameLogEntry) []match {
+	var out []match
+	index := make(map[[2]string]int)
+	for _, g := range week {
+		pair := [2]string{g.TeamKey, g.OpponentKey}
+		if pair[1] < pair[0] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		i, ok := index[pair]
+		if !ok {
+			i = len(out)
+			index[pair] = i
+			out = append(out, match{season: g.Season, teams: pair, points: make(map[string][2]float64)})
+		}
+		pts := out[i].points[g.MachineKey]
+		if g.TeamKey == pair[0] {
+			pts[0] += g.Points
+		} else {
+			pts[1] += g.Points
+		}
+		out[i].points[g.MachineKey] = pts
+	}
+	return out
+}
+
+// predict predicts a match from the games played before it, and returns each
+// prediction alongside the points each team won on the machine.
+func predict(ctx context.Context, s Store, h *history, names map[string]string, m match) ([]db.PredictionOutcome, error) {
+	pit := &pointInTime{names: names, machines: make(map[string]bool), stats: make(map[string][]db.TeamMachineStats)}
+	for key := range m.points {
+		pit.machines[key] = true
+	}
+	for _, team := range m.teams {
+		players, err := h.roster(ctx, s, m.season, team)
+		if err != nil {
+			return nil, err
+		}
+		pit.stats[team] = h.stats(players, pit.machines)
+	}
+
+	mr, err := matchup.Analyze(ctx, pit, "", m.teams[0], m.teams[1])
+	if err != nil {
+		return nil, fmt.Errorf("predict %s vs %s in season %d: %w", m.teams[0], m.teams[1], m.season, err)
+	}
+
+	out := make([]db.PredictionOutcome, 0, len(mr.Machines))
+	for _, mm := range mr.Machines {
+		pts := m.points[mm.MachineKey]
+		out = append(out, db.PredictionOutcome{
+			Prediction: db.Prediction{
+				MachineKey:  mm.MachineKey,
+				HomeTeamKey: m.teams[0],
+				AwayTeamKey: m.teams[1],
+				Edge:        mm.Edge,
+				Confidence:  int(mm.Confidence),
+			},
+			HomePoints: pts[0],
+			AwayPoints: pts[1],
+		})
+	}
+	return out, nil
+}
+
+// history is every score posted before the week being replayed.
+type history struct {
+	scores  map[string]map[string][]float64 // Scores by player, then machine.
+	rosters map[string][]string             // Rosters by season and team.
+	played  map[string]map[string]bool      // Players who've played for each team, by season and team.
+}
+
+// add adds a week's games to the history.
+func (h *history) add(week []db.GameLogEntry) {
+	for _, g := range week {
+		team := fmt.Sprintf("%d/%s", g.Season, g.TeamKey)
+		if h.played[team] == nil {
+			h.played[team] = make(map[string]bool)
+		}
+		h.played[team][g.PlayerName] = true
+
+		if g.Score <= 0 {
+			continue
+		}
+		if h.scores[g.PlayerName] == nil {
+			h.scores[g.PlayerName] = make(map[string][]float64)
+		}
+		h.scores[g.PlayerName][g.MachineKey] = append(h.scores[g.PlayerName][g.MachineKey], float64(g.Score))
+	}
+}
+
+// roster returns a team's roster in a season. The archive doesn't have
+// rosters for some early seasons, so a team without one is the players who've
+// played for it so far that season.
+func (h *history) roster(ctx context.Context, s Store, season int, team string) ([]string, error) {
+	key := fmt.Sprintf("%d/%s", season, team)
+	players, ok := h.rosters[key]
+	if !ok {
+		var err error
+		players, err = s.GetTeamSeasonRoster(ctx, team, season)
+		if err != nil {
+			return nil, fmt.Errorf("load %s's season %d roster: %w", team, season, err)
+		}
+		h.rosters[key] = players
+	}
+	if len(players) > 0 {
+		return players, nil
+	}
+	played := make([]string, 0, len(h.played[key]))
+	for p := range h.played[key] {
+		played = append(played, p)
+	}
+	slices.Sort(played)
+	return played, nil
+}
+
+// stats returns per-machine stats for the supplied players on the supplied
+// machines, computed like the store's GetTeamMachineStats but from the
+// history's unweighted scores.
+func (h *history) stats(players []string, machines map[string]bool) []db.TeamMachineStats {
+	var out []db.TeamMachineStats
+	for machine := range machines {
+		var all []float64
+		var likely []db.LikelyPlayer
+		for _, p := range players {
+			scores := h.scores[p][machine]
+			if len(scores) == 0 {
+				continue
+			}
+			all = append(all, scores...)
+			likely = append(likely, db.LikelyPlayer{Name: p, Games: len(scores), P50Score: p50(scores)})
+		}
+		if len(all) == 0 {
+			continue
+		}
+		slices.SortStableFunc(likely, func(a, b db.LikelyPlayer) int {
+			if c := cmp.Compare(b.Games, a.Games); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.P50Score, a.P50Score)
+		})
+		out = append(out, db.TeamMachineStats{
+			MachineKey:    machine,
+			Games:         len(all),
+			P50Score:      p50(all),
+			LikelyPlayers: likely[:min(len(likely), likelyPlayers)],
+		})
+	}
+	slices.SortFunc(out, func(a, b db.TeamMachineStats) int {
+		if c := cmp.Compare(b.Games, a.Games); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineKey, b.MachineKey)
+	})
+	return out
+}
+
+// p50 returns the lower median of some scores, like the store's P50s.
+func p50(scores []float64) float64 {
+	sorted := slices.Clone(scores)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)-1)/2]
+}
+
+// pointInTime is a matchup.Store that serves stats computed from a backtest's
+// history.
+type pointInTime struct {
+	names    map[string]string
+	machines map[string]bool
+	stats    map[string][]db.TeamMachineStats
+}
+
+func (p *pointInTime) GetMachineNames(_ context.Context) (map[string]string, error) {
+	return p.names, nil
+}
+
+func (p *pointInTime) GetTeamMachineStats(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
+	return p.stats[teamKey], nil
+}
+
+func (p *pointInTime) GetVenueMachines(_ context.Context, _ string, _ int) (map[string]bool, error) {
+	return p.machines, nil
+}
@@ -0,0 +1,186 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/accuracy"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+type MockStore struct {
+	MockGetGameLog          func(ctx context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error)
+	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
+	MockGetTeamSeasonRoster func(ctx context.Context, teamKey string, season int) ([]string, error)
+	MockListSeasons         func(ctx context.Context) ([]int, error)
+}
+
+func (m *MockStore) GetGameLog(ctx context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error) {
+	return m.MockGetGameLog(ctx, f)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	return m.MockGetTeamSeasonRoster(ctx, teamKey, season)
+}
+
+func (m *MockStore) ListSeasons(ctx context.Context) ([]int, error) {
+	return m.MockListSeasons(ctx)
+}
+
+func TestAnalyze(t *testing.T) {
+	game := func(season, week int, machine, player, team, opponent string, score int64, points float64) db.GameLogEntry {
+		return db.GameLogEntry{Season: season, Week: week, MachineKey: machine, PlayerName: player, TeamKey: team, OpponentKey: opponent, Score: score, Points: points}
+	}
+
+	// Alice beats Carol on TZ in season 22. In season 23 week 1 Carol wins
+	// though Alice was predicted to, and in week 2 Alice wins as predicted.
+	// Nobody had played MM before week 1, so it isn't predicted.
+	games := []db.GameLogEntry{
+		game(22, 1, "TZ", "Alice", "TTT", "KNR", 300, 3),
+		game(22, 1, "TZ", "Carol", "KNR", "TTT", 100, 0),
+		game(23, 1, "TZ", "Carol", "KNR", "TTT", 250, 3),
+		game(23, 1, "TZ", "Alice", "TTT", "KNR", 200, 0),
+		game(23, 1, "MM", "Alice", "TTT", "KNR", 500, 3),
+		game(23, 1, "MM", "Carol", "KNR", "TTT", 400, 0),
+		game(23, 2, "TZ", "Alice", "TTT", "KNR", 300, 3),
+		game(23, 2, "TZ", "Carol", "KNR", "TTT", 100, 0),
+	}
+
+	newStore := func() *MockStore {
+		return &MockStore{
+			MockGetGameLog: func(_ context.Context, f db.GameLogFilter) ([]db.GameLogEntry, error) {
+				if f.Sort != db.SortOldest {
+					return nil, errors.New("games should be oldest first")
+				}
+				return games, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TZ": "Twilight Zone", "MM": "Medieval Madness"}, nil
+			},
+			MockGetTeamSeasonRoster: func(_ context.Context, teamKey string, _ int) ([]string, error) {
+				// KNR has no roster, so it's whoever has played for it.
+				if teamKey == "TTT" {
+					return []string{"Alice"}, nil
+				}
+				return nil, nil
+			},
+			MockListSeasons: func(_ context.Context) ([]int, error) {
+				return []int{23, 22}, nil
+			},
+		}
+	}
+
+	// Alice's P50 on TZ is 300 then 200, Carol's 100 then 100. Both edges
+	// are at least 100%.
+	latest := accuracy.Tally{Predictions: 2, Correct: 1}
+	byEdge := func(biggest accuracy.Tally) []EdgeAccuracy {
+		return []EdgeAccuracy{
+			{Min: 0, Max: 10},
+			{Min: 10, Max: 25},
+			{Min: 25, Max: 50},
+			{Min: 50, Max: 100},
+			{Min: 100, Max: math.Inf(1), Tally: biggest},
+		}
+	}
+	byConfidence := func(low accuracy.Tally) []accuracy.ConfidenceAccuracy {
+		return []accuracy.ConfidenceAccuracy{
+			{Confidence: matchup.ConfidenceHigh},
+			{Confidence: matchup.ConfidenceMedium},
+			{Confidence: matchup.ConfidenceLow, Tally: low},
+		}
+	}
+
+	type want struct {
+		r   *Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"LatestSeason": {
+			reason: "The latest season should be replayed by default, predicting each week from earlier weeks' games.",
+			want: want{r: &Result{
+				Seasons: []int{23},
+				Matches: 2,
+				Accuracy: &accuracy.Result{
+					Overall:      latest,
+					ByConfidence: byConfidence(latest),
+					Machines:     []accuracy.MachineAccuracy{{MachineKey: "TZ", MachineName: "Twilight Zone", Tally: latest}},
+				},
+				ByEdge:   byEdge(latest),
+				BySeason: []SeasonAccuracy{{Season: 23, Tally: latest}},
+			}},
+		},
+		"FirstSeason": {
+			reason: "Matches with no earlier games to predict from should be replayed without predictions.",
+			opts:   []Option{InSeasons(22)},
+			want: want{r: &Result{
+				Seasons: []int{22},
+				Matches: 1,
+				Accuracy: &accuracy.Result{
+					ByConfidence: byConfidence(accuracy.Tally{}),
+					Machines:     []accuracy.MachineAccuracy{},
+				},
+				ByEdge:   byEdge(accuracy.Tally{}),
+				BySeason: []SeasonAccuracy{{Season: 22}},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), newStore(), tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBeatsCoinFlip(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tally  accuracy.Tally
+		want   bool
+	}{
+		"NoPredictions": {
+			reason: "No decided predictions can't beat a coin flip.",
+			tally:  accuracy.Tally{Predictions: 3, Pushes: 3},
+			want:   false,
+		},
+		"Lucky": {
+			reason: "Six of ten right could easily be luck.",
+			tally:  accuracy.Tally{Predictions: 10, Correct: 6},
+			want:   false,
+		},
+		"Significant": {
+			reason: "Sixty of a hundred right is unlikely to be luck.",
+			tally:  accuracy.Tally{Predictions: 110, Correct: 60, Pushes: 10},
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := BeatsCoinFlip(tc.tally); got != tc.want {
+				t.Errorf("\n%s\nBeatsCoinFlip(%+v): want %t, got %t", tc.reason, tc.tally, tc.want, got)
+			}
+		})
+	}
+}
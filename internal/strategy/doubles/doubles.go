@@ -0,0 +1,168 @@
+// Package doubles finds which teammates play doubles well together, and
+// recommends pairings for a match's doubles rounds.
+package doubles
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
+)
+
+// minGames is the number of doubles games a pair needs together before its
+// points are trusted enough to recommend it.
+const minGames = 3
+
+// pairsPerRound is the number of doubles games each team plays in a round.
+const pairsPerRound = 2
+
+// Store is the set of queries needed for a doubles report.
+type Store interface {
+	ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error)
+	GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error)
+	GetDoublesPairs(ctx context.Context, teamKey string, seasons []int) ([]db.DoublesPair, error)
+}
+
+// Pair is two teammates' record in doubles games played together.
+type Pair struct {
+	Player1        string
+	Player2        string
+	Games          int
+	PointsWon      float64
+	PointsPossible float64
+}
+
+// Efficiency returns the fraction of the points possible that the pair won,
+// or zero if they've played no games.
+func (p Pair) Efficiency() float64 {
+	if p.PointsPossible == 0 {
+		return 0
+	}
+	return p.PointsWon / p.PointsPossible
+}
+
+// Proven returns true if the pair has played together enough for its
+// efficiency to mean something.
+func (p Pair) Proven() bool {
+	return p.Games >= minGames
+}
+
+// Round is the pairs recommended for one doubles round.
+type Round struct {
+	Number int
+	Pairs  []Pair
+}
+
+// Result is the output of a doubles report.
+type Result struct {
+	Team   string
+	Season int     // The team's latest season, whose roster pairs are drawn from.
+	Pairs  []Pair  // Pairs on the current roster, most efficient first.
+	Rounds []Round // Recommended pairings for each doubles round.
+}
+
+// Option configures a doubles report.
+type Option func(*Options)
+
+// Options holds optional parameters for a doubles report.
+type Options struct {
+	formats league.Formats
+	seasons []int
+}
+
+// WithFormats uses the supplied league formats to decide which rounds are
+// doubles, rather than the built-in formats.
+func WithFormats(f league.Formats) Option {
+	return func(o *Options) {
+		o.formats = f
+	}
+}
+
+// InSeasons only counts doubles games played in the supplied seasons, rather
+// than every season.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// Analyze returns how each pair of players on a team's current roster has done
+// in doubles games together, and recommends pairings for each doubles round of
+// its next match. Only proven pairs are recommended, best first, and no pair
+// is recommended for more than one round.
+func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
+	o := Options{formats: league.Defaults()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	played, err := s.ListTeamSeasons(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load team seasons: %w", err)
+	}
+	if len(played) == 0 {
+		return nil, fmt.Errorf("no seasons found for team %s", team)
+	}
+	season := played[len(played)-1]
+
+	roster, err := s.GetTeamSeasonRoster(ctx, team, season)
+	if err != nil {
+		return nil, fmt.Errorf("load roster: %w", err)
+	}
+
+	pairs, err := s.GetDoublesPairs(ctx, team, o.seasons)
+	if err != nil {
+		return nil, fmt.Errorf("load doubles pairs: %w", err)
+	}
+
+	r := &Result{Team: team, Season: season}
+	for _, p := range pairs {
+		if !slices.Contains(roster, p.Player1) || !slices.Contains(roster, p.Player2) {
+			continue
+		}
+		r.Pairs = append(r.Pairs, Pair(p))
+	}
+	slices.SortFunc(r.Pairs, func(a, b Pair) int {
+		if c := cmp.Compare(b.Efficiency(), a.Efficiency()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Games, a.Games); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Player1, b.Player1); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Player2, b.Player2)
+	})
+
+	r.Rounds = recommend(r.Pairs, o.formats.For(season).DoublesRounds)
+	return r, nil
+}
+
+// recommend greedily assigns the most efficient proven pairs to each doubles
+// round. A player plays at most once per round, and a pair at most once per
+// match. Pairs must be sorted most efficient first.
+func recommend(pairs []Pair, rounds []int) []Round {
+	used := make(map[Pair]bool)
+	result := make([]Round, 0, len(rounds))
+	for _, n := range rounds {
+		round := Round{Number: n}
+		playing := make(map[string]bool)
+		for _, p := range pairs {
+			if len(round.Pairs) == pairsPerRound {
+				break
+			}
+			if !p.Proven() || used[p] || playing[p.Player1] || playing[p.Player2] {
+				continue
+			}
+			round.Pairs = append(round.Pairs, p)
+			used[p] = true
+			playing[p.Player1], playing[p.Player2] = true, true
+		}
+		result = append(result, round)
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package doubles
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
+)
+
+type MockStore struct {
+	MockListTeamSeasons     func(ctx context.Context, teamKey string) ([]int, error)
+	MockGetTeamSeasonRoster func(ctx context.Context, teamKey string, season int) ([]string, error)
+	MockGetDoublesPairs     func(ctx context.Context, teamKey string, seasons []int) ([]db.DoublesPair, error)
+}
+
+func (m *MockStore) ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error) {
+	return m.MockListTeamSeasons(ctx, teamKey)
+}
+
+func (m *MockStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	return m.MockGetTeamSeasonRoster(ctx, teamKey, season)
+}
+
+func (m *MockStore) GetDoublesPairs(ctx context.Context, teamKey string, seasons []int) ([]db.DoublesPair, error) {
+	return m.MockGetDoublesPairs(ctx, teamKey, seasons)
+}
+
+func TestAnalyze(t *testing.T) {
+	store := &MockStore{
+		MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) {
+			return []int{22, 23}, nil
+		},
+		MockGetTeamSeasonRoster: func(_ context.Context, _ string, season int) ([]string, error) {
+			if season != 23 {
+				return nil, errors.New("wrong season")
+			}
+			return []string{"Alice", "Bob", "Carol", "Dave", "Erin"}, nil
+		},
+		MockGetDoublesPairs: func(_ context.Context, _ string, _ []int) ([]db.DoublesPair, error) {
+			return []db.DoublesPair{
+				{Player1: "Alice", Player2: "Bob", Games: 10, PointsWon: 30, PointsPossible: 50},
+				{Player1: "Carol", Player2: "Dave", Games: 8, PointsWon: 28, PointsPossible: 40},
+				{Player1: "Alice", Player2: "Zed", Games: 6, PointsWon: 25, PointsPossible: 30},
+				{Player1: "Bob", Player2: "Dave", Games: 5, PointsWon: 10, PointsPossible: 25},
+				{Player1: "Alice", Player2: "Carol", Games: 4, PointsWon: 10, PointsPossible: 20},
+				{Player1: "Bob", Player2: "Erin", Games: 2, PointsWon: 8, PointsPossible: 10},
+			}, nil
+		},
+	}
+
+	bobErin := Pair{Player1: "Bob", Player2: "Erin", Games: 2, PointsWon: 8, PointsPossible: 10}
+	carolDave := Pair{Player1: "Carol", Player2: "Dave", Games: 8, PointsWon: 28, PointsPossible: 40}
+	aliceBob := Pair{Player1: "Alice", Player2: "Bob", Games: 10, PointsWon: 30, PointsPossible: 50}
+	aliceCarol := Pair{Player1: "Alice", Player2: "Carol", Games: 4, PointsWon: 10, PointsPossible: 20}
+	bobDave := Pair{Player1: "Bob", Player2: "Dave", Games: 5, PointsWon: 10, PointsPossible: 25}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RecommendedPairings": {
+			reason: "Pairs off the current roster should be left out, the rest ranked by efficiency, and the best proven pairs recommended for rounds 1 and 4 without repeating a pair or a player within a round.",
+			args:   args{store: store},
+			want: want{result: &Result{
+				Team:   "CRA",
+				Season: 23,
+				Pairs:  []Pair{bobErin, carolDave, aliceBob, aliceCarol, bobDave},
+				Rounds: []Round{
+					// Bob and Erin haven't played enough together.
+					{Number: 1, Pairs: []Pair{carolDave, aliceBob}},
+					{Number: 4, Pairs: []Pair{aliceCarol, bobDave}},
+				},
+			}},
+		},
+		"OtherFormat": {
+			reason: "Pairings should be recommended for the doubles rounds of the team's latest season's format, leaving rounds empty when proven pairs run out.",
+			args: args{
+				store: store,
+				opts:  []Option{WithFormats(league.Formats{1: {Rounds: 5, DoublesRounds: []int{1, 3, 5}}})},
+			},
+			want: want{result: &Result{
+				Team:   "CRA",
+				Season: 23,
+				Pairs:  []Pair{bobErin, carolDave, aliceBob, aliceCarol, bobDave},
+				Rounds: []Round{
+					{Number: 1, Pairs: []Pair{carolDave, aliceBob}},
+					{Number: 3, Pairs: []Pair{aliceCarol, bobDave}},
+					{Number: 5},
+				},
+			}},
+		},
+		"NoSeasons": {
+			reason: "A team that has never played should return an error.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) {
+					return nil, nil
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+		"DoublesPairsError": {
+			reason: "An error loading doubles pairs should be returned.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons:     store.MockListTeamSeasons,
+				MockGetTeamSeasonRoster: store.MockGetTeamSeasonRoster,
+				MockGetDoublesPairs: func(_ context.Context, _ string, _ []int) ([]db.DoublesPair, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "CRA", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
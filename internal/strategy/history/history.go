@@ -0,0 +1,141 @@
+// Package history finds notable scores from the same week in past seasons.
+package history
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// DefaultLimit is how many notable scores to return by default.
+const DefaultLimit = 5
+
+// window is how many days either side of the date a year ago count as the
+// same week. Matches are weekly, so this catches one match night.
+const window = 3
+
+// Store is the set of queries needed to look back a year.
+type Store interface {
+	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	ListPlayedResults(ctx context.Context, from, to string) ([]db.PlayedResult, error)
+}
+
+// Score is a notable score from a year ago.
+type Score struct {
+	db.PlayedResult
+
+	MachineName string
+	LeagueP50   float64
+}
+
+// Value returns the score as a float, as the output formatters take.
+func (s Score) Value() float64 {
+	return float64(s.Score)
+}
+
+// Multiple returns how many times the league P50 on the machine the score is.
+func (s Score) Multiple() float64 {
+	if s.LeagueP50 == 0 {
+		return 0
+	}
+	return float64(s.Score) / s.LeagueP50
+}
+
+// Result is the output of a look back.
+type Result struct {
+	From   string  // First date of the week a year ago.
+	To     string  // Last date of the week a year ago.
+	Scores []Score // Most notable first.
+}
+
+// Option configures a look back.
+type Option func(*Options)
+
+// Options holds optional parameters for a look back.
+type Options struct {
+	limit int
+}
+
+// WithLimit returns up to the supplied number of scores, rather than
+// DefaultLimit.
+func WithLimit(n int) Option {
+	return func(o *Options) {
+		o.limit = n
+	}
+}
+
+// YearAgo returns the most notable scores from matches played within a few
+// days of the supplied date one year earlier. A score is notable by how many
+// times the league P50 on its machine it is. Each player appears at most once,
+// with their most notable score, and scores below the league P50 are left out.
+func YearAgo(ctx context.Context, s Store, date string, opts ...Option) (*Result, error) {
+	o := Options{limit: DefaultLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	d, err := time.Parse(schedule.DateLayout, date)
+	if err != nil {
+		return nil, fmt.Errorf("parse date %q: %w", date, err)
+	}
+	ago := d.AddDate(-1, 0, 0)
+	r := &Result{
+		From: ago.AddDate(0, 0, -window).Format(schedule.DateLayout),
+		To:   ago.AddDate(0, 0, window).Format(schedule.DateLayout),
+	}
+
+	results, err := s.ListPlayedResults(ctx, r.From, r.To)
+	if err != nil {
+		return nil, fmt.Errorf("load results: %w", err)
+	}
+	if len(results) == 0 {
+		return r, nil
+	}
+
+	leagueP50, err := s.GetLeagueP50(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league averages: %w", err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	best := make(map[string]Score)
+	for _, pr := range results {
+		sc := Score{
+			PlayedResult: pr,
+			MachineName:  output.MachineName(names, pr.MachineKey),
+			LeagueP50:    leagueP50[pr.MachineKey],
+		}
+		if sc.Multiple() <= 1 {
+			continue
+		}
+		if b, ok := best[pr.PlayerName]; ok && b.Multiple() >= sc.Multiple() {
+			continue
+		}
+		best[pr.PlayerName] = sc
+	}
+
+	for _, sc := range best {
+		r.Scores = append(r.Scores, sc)
+	}
+	slices.SortFunc(r.Scores, func(a, b Score) int {
+		if c := cmp.Compare(b.Multiple(), a.Multiple()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PlayerName, b.PlayerName)
+	})
+	if len(r.Scores) > o.limit {
+		r.Scores = r.Scores[:o.limit]
+	}
+	return r, nil
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetLeagueP50      func(ctx context.Context) (map[string]float64, error)
+	MockGetMachineNames   func(ctx context.Context) (map[string]string, error)
+	MockListPlayedResults func(ctx context.Context, from, to string) ([]db.PlayedResult, error)
+}
+
+func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
+	return m.MockGetLeagueP50(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) ListPlayedResults(ctx context.Context, from, to string) ([]db.PlayedResult, error) {
+	return m.MockListPlayedResults(ctx, from, to)
+}
+
+func TestYearAgo(t *testing.T) {
+	result := func(player, machine string, score int64) db.PlayedResult {
+		return db.PlayedResult{
+			MatchKey:    "mnp-22-3-CRA-PYC",
+			Date:        "2024-01-15",
+			MatchResult: db.MatchResult{PlayerName: player, MachineKey: machine, Score: score},
+		}
+	}
+
+	store := &MockStore{
+		MockListPlayedResults: func(_ context.Context, _, _ string) ([]db.PlayedResult, error) {
+			return []db.PlayedResult{
+				result("Alice", "TAF", 300),
+				result("Alice", "MM", 250),
+				result("Bob", "MM", 400),
+				result("Carol", "TAF", 50),
+			}, nil
+		},
+		MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+			return map[string]float64{"TAF": 100, "MM": 100}, nil
+		},
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+			return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+		},
+	}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Notable": {
+			reason: "Scores should be ranked by how far above the league P50 they are, with each player's best only, and below average scores left out.",
+			args:   args{store: store},
+			want: want{result: &Result{
+				From: "2024-01-13",
+				To:   "2024-01-19",
+				Scores: []Score{
+					{PlayedResult: result("Bob", "MM", 400), MachineName: "Medieval Madness", LeagueP50: 100},
+					{PlayedResult: result("Alice", "TAF", 300), MachineName: "The Addams Family", LeagueP50: 100},
+				},
+			}},
+		},
+		"Limit": {
+			reason: "WithLimit should cap the number of scores.",
+			args:   args{store: store, opts: []Option{WithLimit(1)}},
+			want: want{result: &Result{
+				From: "2024-01-13",
+				To:   "2024-01-19",
+				Scores: []Score{
+					{PlayedResult: result("Bob", "MM", 400), MachineName: "Medieval Madness", LeagueP50: 100},
+				},
+			}},
+		},
+		"ListPlayedResultsError": {
+			reason: "An error loading results should be returned.",
+			args: args{store: &MockStore{
+				MockListPlayedResults: func(_ context.Context, _, _ string) ([]db.PlayedResult, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := YearAgo(context.Background(), tc.args.store, "2025-01-16", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nYearAgo(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nYearAgo(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
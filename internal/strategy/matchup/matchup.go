@@ -9,14 +9,15 @@ import (
 	"slices"
 
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
 	"github.com/negz/mnp/internal/output"
 )
 
 // Store is the set of queries needed for matchup comparison.
 type Store interface {
 	GetMachineNames(ctx context.Context) (map[string]string, error)
-	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error)
-	GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error)
+	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
 }
 
 // LikelyPlayer is a player likely to play a machine.
@@ -55,6 +56,20 @@ type Analysis struct {
 	Contested       []string // Machine names where teams are even.
 }
 
+// Round is advice for one round of the match: which machine the picking team
+// should pick, who it should play on it, and who the other team should
+// respond with.
+type Round struct {
+	Number    int
+	Doubles   bool
+	Points    int // Points available in each game.
+	Picker    string
+	Responder string
+	Machine   *MachineMatchup // The picker's best machine not picked in another round, or nil.
+	Play      []string        // The picker's best likely players on the machine.
+	Respond   []string        // The responder's best likely players on the machine.
+}
+
 // Result is the output of a Matchup query.
 type Result struct {
 	Venue    string
@@ -62,17 +77,53 @@ type Result struct {
 	Team2    string
 	Machines []MachineMatchup // Sorted by edge descending (team 1's best first).
 	Analysis Analysis
+	Rounds   []Round // In round order. Only planned if the home team is known.
 }
 
 // Option configures a Matchup query.
 type Option func(*Options)
 
 // Options holds optional parameters for a Matchup query.
-type Options struct{}
+type Options struct {
+	seasons []int
+	formats league.Formats
+	home    string
+}
+
+// InSeasons compares the teams using only games played in the supplied
+// seasons, rather than every season, on the machines at the venue in the
+// latest of them.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// WithFormats uses the latest of the supplied league formats to decide how
+// many rounds the match has, and which are doubles, rather than the built-in
+// formats.
+func WithFormats(f league.Formats) Option {
+	return func(o *Options) {
+		o.formats = f
+	}
+}
+
+// HomeTeam plans each round of the match, supposing the supplied team is at
+// home. Without it, rounds aren't planned.
+func HomeTeam(key string) Option {
+	return func(o *Options) {
+		o.home = key
+	}
+}
 
 // Analyze compares two teams head-to-head at a venue.
-func Analyze(ctx context.Context, s Store, venue, team1, team2 string, _ ...Option) (*Result, error) {
-	venueMachines, err := s.GetVenueMachines(ctx, venue)
+func Analyze(ctx context.Context, s Store, venue, team1, team2 string, opts ...Option) (*Result, error) {
+	o := Options{formats: league.Defaults()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	venueMachines, err := s.GetVenueMachines(ctx, venue, db.LatestSeason(o.seasons))
 	if err != nil {
 		return nil, fmt.Errorf("load venue machines: %w", err)
 	}
@@ -82,19 +133,24 @@ func Analyze(ctx context.Context, s Store, venue, team1, team2 string, _ ...Opti
 		return nil, fmt.Errorf("load machine names: %w", err)
 	}
 
-	stats1, err := s.GetTeamMachineStats(ctx, team1, "")
+	stats1, err := s.GetTeamMachineStats(ctx, team1, "", o.seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load stats for %s: %w", team1, err)
 	}
 
-	stats2, err := s.GetTeamMachineStats(ctx, team2, "")
+	stats2, err := s.GetTeamMachineStats(ctx, team2, "", o.seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load stats for %s: %w", team2, err)
 	}
 
 	stats2ByMachine := make(map[string]db.TeamMachineStats, len(stats2))
+	likely := map[string]map[string][]db.LikelyPlayer{team1: {}, team2: {}}
 	for _, s := range stats2 {
 		stats2ByMachine[s.MachineKey] = s
+		likely[team2][s.MachineKey] = s.LikelyPlayers
+	}
+	for _, s := range stats1 {
+		likely[team1][s.MachineKey] = s.LikelyPlayers
 	}
 
 	machines := make([]MachineMatchup, 0, len(stats1))
@@ -140,13 +196,88 @@ func Analyze(ctx context.Context, s Store, venue, team1, team2 string, _ ...Opti
 		return cmp.Compare(b.Edge, a.Edge)
 	})
 
-	return &Result{
+	r := &Result{
 		Venue:    venue,
 		Team1:    team1,
 		Team2:    team2,
 		Machines: machines,
 		Analysis: analyze(machines),
-	}, nil
+	}
+	if o.home != "" {
+		r.Rounds = plan(r, o.formats.Latest(), o.home, likely)
+	}
+	return r, nil
+}
+
+// plan advises each round of the match. The team picking a round's machines
+// should pick its biggest edge, and play its best players on it. Rounds worth
+// the most points are planned first, so they get the biggest edges. A machine
+// is only picked once per match.
+func plan(r *Result, f league.Format, home string, likely map[string]map[string][]db.LikelyPlayer) []Round {
+	rounds := make([]Round, f.Rounds)
+	for i := range rounds {
+		n := i + 1
+		picker, responder := r.Team1, r.Team2
+		if league.HomePicks(n) != (home == r.Team1) {
+			picker, responder = r.Team2, r.Team1
+		}
+		rounds[i] = Round{Number: n, Doubles: f.IsDoubles(n), Points: f.Points(n), Picker: picker, Responder: responder}
+	}
+
+	order := make([]int, len(rounds))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(rounds[b].Points, rounds[a].Points)
+	})
+
+	picked := make(map[string]bool)
+	for _, i := range order {
+		round := &rounds[i]
+		m := best(r.Machines, round.Picker == r.Team1, picked)
+		if m == nil {
+			continue
+		}
+		picked[m.MachineKey] = true
+
+		players := 1
+		if round.Doubles {
+			players = 2
+		}
+		round.Machine = m
+		round.Play = strongest(likely[round.Picker][m.MachineKey], players)
+		round.Respond = strongest(likely[round.Responder][m.MachineKey], players)
+	}
+	return rounds
+}
+
+// best returns the machine with the biggest edge for team 1, or for team 2 if
+// team1 is false, that hasn't been picked. Machines must be sorted by edge
+// descending.
+func best(machines []MachineMatchup, team1 bool, picked map[string]bool) *MachineMatchup {
+	for i := range machines {
+		if !team1 {
+			i = len(machines) - 1 - i
+		}
+		if !picked[machines[i].MachineKey] {
+			return &machines[i]
+		}
+	}
+	return nil
+}
+
+// strongest returns the names of the n likely players with the highest P50.
+func strongest(players []db.LikelyPlayer, n int) []string {
+	sorted := slices.Clone(players)
+	slices.SortStableFunc(sorted, func(a, b db.LikelyPlayer) int {
+		return cmp.Compare(b.P50Score, a.P50Score)
+	})
+	names := make([]string, 0, n)
+	for _, p := range sorted[:min(n, len(sorted))] {
+		names = append(names, p.Name)
+	}
+	return names
 }
 
 func analyze(machines []MachineMatchup) Analysis {
@@ -10,24 +10,25 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
 )
 
 type MockStore struct {
 	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
-	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error)
-	MockGetVenueMachines    func(ctx context.Context, venueKey string) (map[string]bool, error)
+	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines    func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
 }
 
 func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
 	return m.MockGetMachineNames(ctx)
 }
 
-func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error) {
-	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey)
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
 }
 
-func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error) {
-	return m.MockGetVenueMachines(ctx, venueKey)
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
 }
 
 func TestAnalyze(t *testing.T) {
@@ -36,6 +37,7 @@ func TestAnalyze(t *testing.T) {
 		venue string
 		team1 string
 		team2 string
+		opts  []Option
 	}
 
 	type want struct {
@@ -43,11 +45,110 @@ func TestAnalyze(t *testing.T) {
 		err    error
 	}
 
+	taf := MachineMatchup{
+		MachineKey:  "TAF",
+		MachineName: "The Addams Family",
+		Team1P50:    50_000_000,
+		Team1Likely: 50_000_000,
+		Team2P50:    25_000_000,
+		Team2Likely: 25_000_000,
+		Edge:        100,
+		Confidence:  ConfidenceMedium,
+	}
+	tz := MachineMatchup{
+		MachineKey:  "TZ",
+		MachineName: "Twilight Zone",
+		Team1P50:    110_000_000,
+		Team1Likely: 110_000_000,
+		Team2P50:    100_000_000,
+		Team2Likely: 100_000_000,
+		Edge:        10,
+		Confidence:  ConfidenceMedium,
+	}
+	mm := MachineMatchup{
+		MachineKey:  "MM",
+		MachineName: "Medieval Madness",
+		Team1P50:    10_000_000,
+		Team1Likely: 10_000_000,
+		Team2P50:    20_000_000,
+		Team2Likely: 19_000_000,
+		Edge:        -90,
+		Confidence:  ConfidenceMedium,
+	}
+
 	cases := map[string]struct {
 		reason string
 		args   args
 		want   want
 	}{
+		"PlanRounds": {
+			reason: "Rounds worth the most points should be planned first, each picking the picker's biggest edge that hasn't been picked, with its strongest players.",
+			args: args{
+				store: &MockStore{
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "TZ": "Twilight Zone", "MM": "Medieval Madness"}, nil
+					},
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
+						return map[string]bool{"TAF": true, "TZ": true, "MM": true}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						stats := map[string][]db.TeamMachineStats{
+							"CRA": {
+								{MachineKey: "TAF", P50Score: 50_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Bob", Games: 5, P50Score: 40_000_000},
+									{Name: "Alice", Games: 3, P50Score: 60_000_000},
+									{Name: "Dan", Games: 4, P50Score: 50_000_000},
+								}},
+								{MachineKey: "TZ", P50Score: 110_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Alice", Games: 5, P50Score: 110_000_000},
+								}},
+								{MachineKey: "MM", P50Score: 10_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Bob", Games: 5, P50Score: 10_000_000},
+								}},
+							},
+							"PYC": {
+								{MachineKey: "TAF", P50Score: 25_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Carol", Games: 5, P50Score: 25_000_000},
+								}},
+								{MachineKey: "TZ", P50Score: 100_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Erin", Games: 5, P50Score: 100_000_000},
+								}},
+								{MachineKey: "MM", P50Score: 20_000_000, LikelyPlayers: []db.LikelyPlayer{
+									{Name: "Carol", Games: 5, P50Score: 20_000_000},
+									{Name: "Erin", Games: 5, P50Score: 18_000_000},
+								}},
+							},
+						}
+						return stats[teamKey], nil
+					},
+				},
+				venue: "SAM",
+				team1: "CRA",
+				team2: "PYC",
+				opts:  []Option{WithFormats(league.Formats{1: {Rounds: 4, DoublesRounds: []int{1, 4}}}), HomeTeam("CRA")},
+			},
+			want: want{
+				result: &Result{
+					Venue:    "SAM",
+					Team1:    "CRA",
+					Team2:    "PYC",
+					Machines: []MachineMatchup{taf, tz, mm},
+					Analysis: Analysis{
+						Team1Advantages: []string{"The Addams Family", "Twilight Zone"},
+						Team2Advantages: []string{"Medieval Madness"},
+					},
+					Rounds: []Round{
+						// The away team picks first, in a doubles round.
+						{Number: 1, Doubles: true, Points: 5, Picker: "PYC", Responder: "CRA", Machine: &mm, Play: []string{"Carol", "Erin"}, Respond: []string{"Bob"}},
+						// TAF is saved for the home team's doubles round.
+						{Number: 2, Points: 3, Picker: "CRA", Responder: "PYC", Machine: &tz, Play: []string{"Alice"}, Respond: []string{"Erin"}},
+						// Every machine has been picked.
+						{Number: 3, Points: 3, Picker: "PYC", Responder: "CRA"},
+						{Number: 4, Doubles: true, Points: 5, Picker: "CRA", Responder: "PYC", Machine: &taf, Play: []string{"Alice", "Dan"}, Respond: []string{"Carol"}},
+					},
+				},
+			},
+		},
 		"BothTeamsHaveData": {
 			reason: "When both teams have stats for venue machines, the result should contain matchups sorted by edge descending with correct analysis.",
 			args: args{
@@ -58,10 +159,10 @@ func TestAnalyze(t *testing.T) {
 							"MM":  "Medieval Madness",
 						}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{"TAF": true, "MM": true}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						stats := map[string][]db.TeamMachineStats{
 							"CRA": {
 								{
@@ -149,10 +250,10 @@ func TestAnalyze(t *testing.T) {
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TZ": "Twilight Zone"}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{"TZ": true}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						stats := map[string][]db.TeamMachineStats{
 							"PYC": {
 								{
@@ -200,10 +301,10 @@ func TestAnalyze(t *testing.T) {
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TAF": "The Addams Family"}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _ string, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _ string, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return []db.TeamMachineStats{
 							{MachineKey: "TAF", Games: 10, P50Score: 50_000_000},
 						}, nil
@@ -226,7 +327,7 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading venue machines should be returned.",
 			args: args{
 				store: &MockStore{
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -242,7 +343,7 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading machine names should be returned.",
 			args: args{
 				store: &MockStore{
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{}, nil
 					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
@@ -261,13 +362,13 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading team stats should be returned.",
 			args: args{
 				store: &MockStore{
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{}, nil
 					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -283,7 +384,7 @@ func TestAnalyze(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := Analyze(context.Background(), tc.args.store, tc.args.venue, tc.args.team1, tc.args.team2)
+			got, err := Analyze(context.Background(), tc.args.store, tc.args.venue, tc.args.team1, tc.args.team2, tc.args.opts...)
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
@@ -0,0 +1,112 @@
+// Package pickem scores the picks league members make in the pick'em game.
+package pickem
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+// Store is the set of queries needed to score picks.
+type Store interface {
+	ListPickOutcomes(ctx context.Context) ([]db.PickOutcome, error)
+}
+
+// Standing is one picker's record.
+type Standing struct {
+	Picker  string
+	Picks   int // Picks for matches that have been played.
+	Correct int // Picks of the team that won more points.
+	Pushes  int // Picks for matches where the teams tied.
+}
+
+// Accuracy returns the fraction of decided picks that were correct, between 0
+// and 1. Pushes aren't counted.
+func (s Standing) Accuracy() float64 {
+	decided := s.Picks - s.Pushes
+	if decided == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(decided)
+}
+
+func (s *Standing) add(o db.PickOutcome) {
+	s.Picks++
+
+	switch {
+	case o.HomePoints == o.AwayPoints:
+		s.Pushes++
+	case o.TeamKey == o.HomeTeamKey && o.HomePoints > o.AwayPoints:
+		s.Correct++
+	case o.TeamKey == o.AwayTeamKey && o.AwayPoints > o.HomePoints:
+		s.Correct++
+	}
+}
+
+// Result is the output of a pick'em leaderboard.
+type Result struct {
+	Season    int        // Zero if every season is counted.
+	Standings []Standing // Most correct picks first.
+}
+
+// Option configures a pick'em leaderboard.
+type Option func(*Options)
+
+// Options holds optional parameters for a pick'em leaderboard.
+type Options struct {
+	season int
+}
+
+// InSeason only counts picks for matches in the supplied season.
+func InSeason(n int) Option {
+	return func(o *Options) {
+		o.season = n
+	}
+}
+
+// Analyze scores every pick for a played match, and ranks pickers by how many
+// they got right. Pickers with the same number right are ranked by accuracy,
+// so fewer misses is better.
+func Analyze(ctx context.Context, s Store, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	outcomes, err := s.ListPickOutcomes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load pick outcomes: %w", err)
+	}
+
+	pickers := make(map[string]*Standing)
+	for _, po := range outcomes {
+		if o.season != 0 && po.Season != o.season {
+			continue
+		}
+		st, ok := pickers[po.Picker]
+		if !ok {
+			st = &Standing{Picker: po.Picker}
+			pickers[po.Picker] = st
+		}
+		st.add(po)
+	}
+
+	r := &Result{Season: o.season, Standings: make([]Standing, 0, len(pickers))}
+	for _, st := range pickers {
+		r.Standings = append(r.Standings, *st)
+	}
+	slices.SortFunc(r.Standings, func(a, b Standing) int {
+		if c := cmp.Compare(b.Correct, a.Correct); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Accuracy(), a.Accuracy()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Picker, b.Picker)
+	})
+
+	return r, nil
+}
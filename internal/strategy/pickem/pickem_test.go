@@ -0,0 +1,105 @@
+package pickem
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockListPickOutcomes func(ctx context.Context) ([]db.PickOutcome, error)
+}
+
+func (m *MockStore) ListPickOutcomes(ctx context.Context) ([]db.PickOutcome, error) {
+	return m.MockListPickOutcomes(ctx)
+}
+
+func TestAnalyze(t *testing.T) {
+	outcome := func(picker, team string, season int, home, away float64) db.PickOutcome {
+		return db.PickOutcome{
+			Pick:        db.Pick{Picker: picker, MatchKey: "mnp-23-1-KNR-TTT", TeamKey: team},
+			Season:      season,
+			HomeTeamKey: "KNR",
+			AwayTeamKey: "TTT",
+			HomePoints:  home,
+			AwayPoints:  away,
+		}
+	}
+	store := &MockStore{
+		MockListPickOutcomes: func(_ context.Context) ([]db.PickOutcome, error) {
+			return []db.PickOutcome{
+				outcome("Erin", "KNR", 23, 20, 10),
+				outcome("Erin", "TTT", 23, 20, 10),
+				outcome("Frank", "KNR", 23, 20, 10),
+				outcome("Frank", "TTT", 23, 15, 15),
+				outcome("Gina", "TTT", 22, 10, 20),
+				outcome("Gina", "TTT", 22, 10, 20),
+			}, nil
+		},
+	}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EverySeason": {
+			reason: "Pickers should be ranked by correct picks, then by accuracy with ties not counted.",
+			args:   args{store: store},
+			want: want{result: &Result{Standings: []Standing{
+				{Picker: "Gina", Picks: 2, Correct: 2},
+				{Picker: "Frank", Picks: 2, Correct: 1, Pushes: 1},
+				{Picker: "Erin", Picks: 2, Correct: 1},
+			}}},
+		},
+		"InSeason": {
+			reason: "Only picks for matches in the supplied season should count.",
+			args:   args{store: store, opts: []Option{InSeason(23)}},
+			want: want{result: &Result{Season: 23, Standings: []Standing{
+				{Picker: "Frank", Picks: 2, Correct: 1, Pushes: 1},
+				{Picker: "Erin", Picks: 2, Correct: 1},
+			}}},
+		},
+		"NoPicks": {
+			reason: "A season without picks should have no standings.",
+			args:   args{store: store, opts: []Option{InSeason(21)}},
+			want:   want{result: &Result{Season: 21, Standings: []Standing{}}},
+		},
+		"OutcomesError": {
+			reason: "An error loading pick outcomes should be returned.",
+			args: args{store: &MockStore{
+				MockListPickOutcomes: func(_ context.Context) ([]db.PickOutcome, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
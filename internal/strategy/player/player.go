@@ -7,19 +7,44 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/negz/mnp/internal/badge"
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/fuzzy"
 	"github.com/negz/mnp/internal/output"
 )
 
 const minGamesForAnalysis = 3
 
+// maxSuggestions is how many similarly spelled names are suggested for a
+// player who can't be found.
+const maxSuggestions = 3
+
+// DefaultTrendWindow is how many recent games are compared against the same
+// number of games before them, by default.
+const DefaultTrendWindow = 5
+
+// DefaultTrendMonths is how many of a player's most recent months on a machine
+// monthly trends include, by default.
+const DefaultTrendMonths = 12
+
 // Store is the set of queries needed for player analysis.
 type Store interface {
 	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error)
 	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetMonthlyP50(ctx context.Context, playerName string) ([]db.MonthlyStats, error)
 	GetPlayer(ctx context.Context, playerName string) (db.PlayerSummary, error)
-	GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string) ([]db.PlayerMachineStats, error)
-	GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error)
+	GetPlayerGameLog(ctx context.Context, playerName string) ([]db.GameLogEntry, error)
+	GetPlayerHomeAwaySplits(ctx context.Context, playerName string, seasons []int) (map[string]db.HomeAwaySplit, error)
+	GetPlayerMachinePoints(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]db.Points, error)
+	GetPlayerTrend(ctx context.Context, playerName string) ([]db.PlayerSeasonStats, error)
+	GetSignatureWin(ctx context.Context, playerName string) (*db.SignatureWin, error)
+	GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	ListPlayerBadges(ctx context.Context, playerName string) ([]db.Badge, error)
+	ListPlayerGoals(ctx context.Context, playerName string) ([]db.Goal, error)
+	ListPlayerMachineScores(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error)
+	ListPlayerNames(ctx context.Context) ([]string, error)
 }
 
 // MachineStats is a player's performance on a single machine.
@@ -30,6 +55,134 @@ type MachineStats struct {
 	P50Score    float64
 	P90Score    float64
 	LeagueP50   float64
+	PercentRank float64          // Where P50 ranks among league scores on the machine, 0-1.
+	Points      db.Points        // Only set with WithPoints.
+	Split       db.HomeAwaySplit // Only set with WithSplits.
+}
+
+// Window is a player's stats over a run of consecutive games on a machine.
+type Window struct {
+	P50Score    float64
+	PercentRank float64 // Median percentile rank against league scores on the machine, 0-1.
+}
+
+// Trend compares a player's most recent games on a machine against the games
+// before them.
+type Trend struct {
+	MachineKey  string
+	MachineName string
+	Games       int // Games in each window.
+	Previous    Window
+	Recent      Window
+}
+
+// RankChange returns how many percentile points the player's rank moved
+// between windows. Positive is an improvement.
+func (t Trend) RankChange() float64 {
+	return (t.Recent.PercentRank - t.Previous.PercentRank) * 100
+}
+
+// Season is a player's stats on a machine in one season.
+type Season struct {
+	Season   int
+	Games    int
+	P50Score float64
+}
+
+// SeasonTrend is a player's P50 on a machine season by season.
+type SeasonTrend struct {
+	MachineKey  string
+	MachineName string
+	Seasons     []Season // Oldest first.
+}
+
+// Change returns the percentage change from the player's P50 in their first
+// season on the machine to their P50 in their latest.
+func (t SeasonTrend) Change() float64 {
+	first, last := t.Seasons[0].P50Score, t.Seasons[len(t.Seasons)-1].P50Score
+	if first == 0 {
+		return 0
+	}
+	return (last - first) / first * 100
+}
+
+// Month is a player's stats on a machine in one calendar month.
+type Month struct {
+	Month    string // e.g. 2024-01.
+	Games    int
+	P50Score float64
+}
+
+// MonthlyTrend is a player's P50 on a machine month by month.
+type MonthlyTrend struct {
+	MachineKey  string
+	MachineName string
+	Months      []Month // Oldest first.
+}
+
+// Games returns how many games the trend covers.
+func (t MonthlyTrend) Games() int {
+	n := 0
+	for _, m := range t.Months {
+		n += m.Games
+	}
+	return n
+}
+
+// Latest returns the player's most recent month on the machine.
+func (t MonthlyTrend) Latest() Month {
+	return t.Months[len(t.Months)-1]
+}
+
+// P50s returns the player's P50 in each month, oldest first.
+func (t MonthlyTrend) P50s() []float64 {
+	p50s := make([]float64, len(t.Months))
+	for i, m := range t.Months {
+		p50s[i] = m.P50Score
+	}
+	return p50s
+}
+
+// Game is a player's score in one game.
+type Game struct {
+	Week  int
+	Date  string // ISO date of the match. Empty if unknown.
+	Score int64
+}
+
+// GameLog is a player's score in each game they played on a machine in a
+// season.
+type GameLog struct {
+	MachineKey  string
+	MachineName string
+	Season      int
+	Games       []Game // Oldest first.
+}
+
+// GoalProgress is how close a player is to meeting one of their goals.
+type GoalProgress struct {
+	db.Goal
+
+	MachineName string
+	Games       int     // Games on the machine since the goal was set.
+	Current     float64 // Best score, or recent median percentile rank (0-100), since the goal was set.
+	Met         bool
+}
+
+// Progress returns how far the player is toward the goal's target, from 0 to 1.
+func (g GoalProgress) Progress() float64 {
+	if g.Target <= 0 {
+		return 1
+	}
+	return min(1, g.Current/g.Target)
+}
+
+// SignatureWin is the player's singles win against their highest rated
+// opponent.
+type SignatureWin struct {
+	db.SignatureWin
+
+	MachineName string
 }
 
 // Team is the player's current team.
@@ -52,6 +205,15 @@ type Result struct {
 	Team        *Team          // Nil if player's team can't be determined.
 	GlobalStats []MachineStats // All machines, or filtered to venue machines when a venue is set.
 	Analysis    Analysis
+	Trends      []Trend        // Only set with WithTrends. Biggest rank change first.
+	Seasons     []SeasonTrend  // Only set with WithSeasonTrends. Biggest change first.
+	Months      []MonthlyTrend // Only set with WithMonthlyTrends. Most games first.
+	GameLogs    []GameLog      // Only set with WithGameLog. Most games first.
+	Goals       []GoalProgress // Only set with WithGoals. Oldest first.
+	Badges      []badge.Earned // Only set with WithBadges. Oldest first.
+	Win         *SignatureWin  // Only set with WithSignatureWin, and nil if the player hasn't won a singles game.
+	Points      db.Points      // Only set with WithPoints. Totals across GlobalStats' machines.
+	Suggestions []string       // Similarly spelled names, when no player has the name. Closest first.
 }
 
 // Option configures a Player query.
@@ -59,7 +221,17 @@ type Option func(*Options)
 
 // Options holds optional parameters for a Player query.
 type Options struct {
-	venue string
+	venue        string
+	trends       int
+	seasonTrends bool
+	monthTrends  int
+	gameLog      bool
+	seasons      []int
+	goals        bool
+	badges       bool
+	win          bool
+	points       bool
+	splits       bool
 }
 
 // AtVenue filters player stats to a specific venue.
@@ -69,55 +241,250 @@ func AtVenue(key string) Option {
 	}
 }
 
-// Analyze returns an individual player's stats across all machines.
+// WithTrends compares the player's most recent games on each machine against
+// the same number of games before them. Machines the player hasn't played at
+// least twice the window size are left out.
+func WithTrends(window int) Option {
+	return func(o *Options) {
+		o.trends = window
+	}
+}
+
+// WithSeasonTrends includes the player's P50 on each machine in each season
+// they played it. Machines the player has only played in one season are left
+// out.
+func WithSeasonTrends() Option {
+	return func(o *Options) {
+		o.seasonTrends = true
+	}
+}
+
+// WithMonthlyTrends includes the player's P50 on each machine in each of the
+// last months calendar months they played it. Machines the player has only
+// played in one month are left out.
+func WithMonthlyTrends(months int) Option {
+	return func(o *Options) {
+		o.monthTrends = months
+	}
+}
+
+// WithGameLog includes the player's score in each game on each machine in the
+// latest season they played. Machines they only played once that season are
+// left out.
+func WithGameLog() Option {
+	return func(o *Options) {
+		o.gameLog = true
+	}
+}
+
+// InSeasons limits the player's machine stats to games played in the supplied
+// seasons, rather than every season. At a venue, it limits them to the
+// machines there in the latest of them. It doesn't affect trends, goals, or
+// badges.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// WithGoals tracks the player's progress toward their goals, counting only
+// results from on or after the day each goal was set. Percentile goals are
+// measured over the player's last DefaultTrendWindow games.
+func WithGoals() Option {
+	return func(o *Options) {
+		o.goals = true
+	}
+}
+
+// WithBadges includes the badges the player has earned.
+func WithBadges() Option {
+	return func(o *Options) {
+		o.badges = true
+	}
+}
+
+// WithSignatureWin includes the player's singles win against their highest
+// rated opponent.
+func WithSignatureWin() Option {
+	return func(o *Options) {
+		o.win = true
+	}
+}
+
+// WithPoints includes the match points the player won on each machine, out of
+// the points they could have won. Unlike P50, this credits a player who
+// scores just enough to beat strong opponents.
+func WithPoints() Option {
+	return func(o *Options) {
+		o.points = true
+	}
+}
+
+// WithSplits includes the player's P50 on each machine in matches their team
+// hosted, and in matches it played away.
+func WithSplits() Option {
+	return func(o *Options) {
+		o.splits = true
+	}
+}
+
+// Analyze returns an individual player's stats across all machines. The name
+// is matched ignoring case and whitespace, and the result is named as the
+// archive spells it. If no player has the name the result has no stats, and
+// suggests similarly spelled names.
 func Analyze(ctx context.Context, s Store, name string, opts ...Option) (*Result, error) {
 	var o Options
 	for _, opt := range opts {
 		opt(&o)
 	}
 
+	players, err := s.ListPlayerNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load player names: %w", err)
+	}
+	match, suggestions := fuzzy.Find(name, players, maxSuggestions)
+	if match == "" {
+		return &Result{Name: name, Suggestions: suggestions}, nil
+	}
+	name = match
+
 	leagueP50, err := s.GetLeagueP50(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("load league averages: %w", err)
 	}
 
+	leagueScores, err := s.GetLeagueScores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league scores: %w", err)
+	}
+
 	names, err := s.GetMachineNames(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("load machine names: %w", err)
 	}
 
+	var r *Result
 	if o.venue != "" {
-		return playerAtVenue(ctx, s, name, o.venue, leagueP50, names)
+		if r, err = playerAtVenue(ctx, s, name, o.venue, o.seasons, leagueP50, leagueScores, names); err != nil {
+			return nil, err
+		}
+	} else {
+		stats, err := s.GetSinglePlayerMachineStats(ctx, name, "", o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load player stats: %w", err)
+		}
+
+		var team *Team
+		var ipr int
+		if p, err := s.GetPlayer(ctx, name); err == nil {
+			team = &Team{Key: p.TeamKey, Name: p.Team}
+			ipr = p.IPR
+		}
+
+		r = &Result{
+			Name:        name,
+			IPR:         ipr,
+			Team:        team,
+			GlobalStats: enrichStats(stats, leagueP50, leagueScores, names),
+			Analysis:    AnalyzeMachines(stats, leagueP50, leagueScores, names),
+		}
 	}
 
-	stats, err := s.GetSinglePlayerMachineStats(ctx, name, "")
-	if err != nil {
-		return nil, fmt.Errorf("load player stats: %w", err)
+	if o.points {
+		pts, err := s.GetPlayerMachinePoints(ctx, name, "", o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load player points: %w", err)
+		}
+		for i := range r.GlobalStats {
+			r.GlobalStats[i].Points = pts[r.GlobalStats[i].MachineKey]
+			r.Points = r.Points.Add(r.GlobalStats[i].Points)
+		}
 	}
 
-	var team *Team
-	var ipr int
-	if p, err := s.GetPlayer(ctx, name); err == nil {
-		team = &Team{Key: p.TeamKey, Name: p.Team}
-		ipr = p.IPR
+	if o.splits {
+		splits, err := s.GetPlayerHomeAwaySplits(ctx, name, o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load player home and away splits: %w", err)
+		}
+		for i := range r.GlobalStats {
+			r.GlobalStats[i].Split = splits[r.GlobalStats[i].MachineKey]
+		}
 	}
 
-	return &Result{
-		Name:        name,
-		IPR:         ipr,
-		Team:        team,
-		GlobalStats: enrichStats(stats, leagueP50, names),
-		Analysis:    analyze(stats, leagueP50, names),
-	}, nil
+	if o.badges {
+		badges, err := s.ListPlayerBadges(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load player badges: %w", err)
+		}
+		r.Badges = badge.Describe(badges)
+	}
+
+	if o.win {
+		w, err := s.GetSignatureWin(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load signature win: %w", err)
+		}
+		if w != nil {
+			r.Win = &SignatureWin{SignatureWin: *w, MachineName: output.MachineName(names, w.MachineKey)}
+		}
+	}
+
+	if o.seasonTrends {
+		stats, err := s.GetPlayerTrend(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load player trend: %w", err)
+		}
+		r.Seasons = seasonTrends(stats, names)
+	}
+
+	if o.monthTrends > 0 {
+		stats, err := s.GetMonthlyP50(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load player monthly P50: %w", err)
+		}
+		r.Months = monthlyTrends(stats, o.monthTrends, names)
+	}
+
+	if o.gameLog {
+		log, err := s.GetPlayerGameLog(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load player game log: %w", err)
+		}
+		r.GameLogs = gameLogs(log, names)
+	}
+
+	var goals []db.Goal
+	if o.goals {
+		if goals, err = s.ListPlayerGoals(ctx, name); err != nil {
+			return nil, fmt.Errorf("load player goals: %w", err)
+		}
+	}
+
+	if o.trends == 0 && len(goals) == 0 {
+		return r, nil
+	}
+
+	scores, err := s.ListPlayerMachineScores(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("load player scores: %w", err)
+	}
+	if o.trends > 0 {
+		r.Trends = trends(scores, o.trends, names)
+	}
+	for _, g := range goals {
+		r.Goals = append(r.Goals, progress(g, scores, names))
+	}
+
+	return r, nil
 }
 
-func playerAtVenue(ctx context.Context, s Store, name, venue string, leagueP50 map[string]float64, machineNames map[string]string) (*Result, error) {
-	venueMachines, err := s.GetVenueMachines(ctx, venue)
+func playerAtVenue(ctx context.Context, s Store, name, venue string, seasons []int, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, machineNames map[string]string) (*Result, error) {
+	venueMachines, err := s.GetVenueMachines(ctx, venue, db.LatestSeason(seasons))
 	if err != nil {
 		return nil, fmt.Errorf("load venue machines: %w", err)
 	}
 
-	globalStats, err := s.GetSinglePlayerMachineStats(ctx, name, "")
+	globalStats, err := s.GetSinglePlayerMachineStats(ctx, name, "", seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load player stats: %w", err)
 	}
@@ -142,20 +509,20 @@ func playerAtVenue(ctx context.Context, s Store, name, venue string, leagueP50 m
 		IPR:         ipr,
 		Venue:       venue,
 		Team:        team,
-		GlobalStats: enrichStats(filtered, leagueP50, machineNames),
-		Analysis:    analyze(filtered, leagueP50, machineNames),
+		GlobalStats: enrichStats(filtered, leagueP50, leagueScores, machineNames),
+		Analysis:    AnalyzeMachines(filtered, leagueP50, leagueScores, machineNames),
 	}, nil
 }
 
-func enrichStats(stats []db.PlayerMachineStats, leagueP50 map[string]float64, names map[string]string) []MachineStats {
+func enrichStats(stats []db.PlayerMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string) []MachineStats {
 	result := make([]MachineStats, len(stats))
 	for i, s := range stats {
-		result[i] = enrichStat(s, leagueP50, names)
+		result[i] = enrichStat(s, leagueP50, leagueScores, names)
 	}
 	return result
 }
 
-func enrichStat(s db.PlayerMachineStats, leagueP50 map[string]float64, names map[string]string) MachineStats {
+func enrichStat(s db.PlayerMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string) MachineStats {
 	return MachineStats{
 		MachineKey:  s.MachineKey,
 		MachineName: output.MachineName(names, s.MachineKey),
@@ -163,10 +530,14 @@ func enrichStat(s db.PlayerMachineStats, leagueP50 map[string]float64, names map
 		P50Score:    s.P50Score,
 		P90Score:    s.P90Score,
 		LeagueP50:   leagueP50[s.MachineKey],
+		PercentRank: leagueScores[s.MachineKey].PercentRank(s.P50Score),
 	}
 }
 
-func analyze(stats []db.PlayerMachineStats, leagueP50 map[string]float64, names map[string]string) Analysis {
+// AnalyzeMachines returns a player's strongest and weakest machines among those
+// they've played at least three times, ranked by where their P50 falls among
+// the league's scores on each machine.
+func AnalyzeMachines(stats []db.PlayerMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string) Analysis {
 	sorted := make([]db.PlayerMachineStats, 0, len(stats))
 	for _, s := range stats {
 		if s.Games >= minGamesForAnalysis {
@@ -174,7 +545,15 @@ func analyze(stats []db.PlayerMachineStats, leagueP50 map[string]float64, names
 		}
 	}
 
+	// Scoring scales differ by orders of magnitude between machines, so rank
+	// machines by where their P50 falls among the league's scores, and only
+	// fall back to relative strength when that's a tie.
 	slices.SortFunc(sorted, func(a, b db.PlayerMachineStats) int {
+		aRank := leagueScores[a.MachineKey].PercentRank(a.P50Score)
+		bRank := leagueScores[b.MachineKey].PercentRank(b.P50Score)
+		if c := cmp.Compare(bRank, aRank); c != 0 {
+			return c
+		}
 		aRel := output.RelStr(a.P50Score, leagueP50[a.MachineKey])
 		bRel := output.RelStr(b.P50Score, leagueP50[b.MachineKey])
 		return cmp.Compare(bRel, aRel)
@@ -191,3 +570,172 @@ func analyze(stats []db.PlayerMachineStats, leagueP50 map[string]float64, names
 	}
 	return a
 }
+
+// trends compares the last window scores on each machine against the window
+// before them. Scores must be ordered by machine, then oldest first.
+func trends(scores []db.PlayerMachineScore, window int, names map[string]string) []Trend {
+	var result []Trend
+	for i := 0; i < len(scores); {
+		j := i
+		for j < len(scores) && scores[j].MachineKey == scores[i].MachineKey {
+			j++
+		}
+		if machine := scores[i:j]; len(machine) >= window*2 {
+			recent := machine[len(machine)-window:]
+			previous := machine[len(machine)-window*2 : len(machine)-window]
+			result = append(result, Trend{
+				MachineKey:  scores[i].MachineKey,
+				MachineName: output.MachineName(names, scores[i].MachineKey),
+				Games:       window,
+				Previous:    summarize(previous),
+				Recent:      summarize(recent),
+			})
+		}
+		i = j
+	}
+
+	slices.SortFunc(result, func(a, b Trend) int {
+		if c := cmp.Compare(b.RankChange(), a.RankChange()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return result
+}
+
+// seasonTrends groups a player's per-season stats by machine. Stats must be
+// ordered by machine, then oldest season first.
+func seasonTrends(stats []db.PlayerSeasonStats, names map[string]string) []SeasonTrend {
+	var result []SeasonTrend
+	for i := 0; i < len(stats); {
+		j := i
+		for j < len(stats) && stats[j].MachineKey == stats[i].MachineKey {
+			j++
+		}
+		if j-i >= 2 {
+			t := SeasonTrend{
+				MachineKey:  stats[i].MachineKey,
+				MachineName: output.MachineName(names, stats[i].MachineKey),
+			}
+			for _, ps := range stats[i:j] {
+				t.Seasons = append(t.Seasons, Season{Season: ps.Season, Games: ps.Games, P50Score: ps.P50Score})
+			}
+			result = append(result, t)
+		}
+		i = j
+	}
+
+	slices.SortFunc(result, func(a, b SeasonTrend) int {
+		if c := cmp.Compare(b.Change(), a.Change()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return result
+}
+
+// monthlyTrends groups monthly stats, which must be ordered by machine and then
+// oldest month first, into a trend per machine covering its last months.
+func monthlyTrends(stats []db.MonthlyStats, months int, names map[string]string) []MonthlyTrend {
+	var result []MonthlyTrend
+	for i := 0; i < len(stats); {
+		j := i
+		for j < len(stats) && stats[j].MachineKey == stats[i].MachineKey {
+			j++
+		}
+		if j-i >= 2 {
+			t := MonthlyTrend{
+				MachineKey:  stats[i].MachineKey,
+				MachineName: output.MachineName(names, stats[i].MachineKey),
+			}
+			for _, ms := range stats[max(i, j-months):j] {
+				t.Months = append(t.Months, Month{Month: ms.Month, Games: ms.Games, P50Score: ms.P50Score})
+			}
+			result = append(result, t)
+		}
+		i = j
+	}
+
+	slices.SortFunc(result, func(a, b MonthlyTrend) int {
+		if c := cmp.Compare(b.Games(), a.Games()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return result
+}
+
+// gameLogs groups a game log, which must be ordered by machine and then oldest
+// first, into a log per machine.
+func gameLogs(log []db.GameLogEntry, names map[string]string) []GameLog {
+	var result []GameLog
+	for i := 0; i < len(log); {
+		j := i
+		for j < len(log) && log[j].MachineKey == log[i].MachineKey {
+			j++
+		}
+		if j-i >= 2 {
+			l := GameLog{
+				MachineKey:  log[i].MachineKey,
+				MachineName: output.MachineName(names, log[i].MachineKey),
+				Season:      log[i].Season,
+			}
+			for _, e := range log[i:j] {
+				l.Games = append(l.Games, Game{Week: e.Week, Date: e.Date, Score: e.Score})
+			}
+			result = append(result, l)
+		}
+		i = j
+	}
+
+	slices.SortFunc(result, func(a, b GameLog) int {
+		if c := cmp.Compare(len(b.Games), len(a.Games)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return result
+}
+
+// summarize returns the median score and percentile rank of a window of games.
+func summarize(scores []db.PlayerMachineScore) Window {
+	s := make([]int64, len(scores))
+	r := make([]float64, len(scores))
+	for i, sc := range scores {
+		s[i] = sc.Score
+		r[i] = sc.PercentRank
+	}
+	slices.Sort(s)
+	slices.Sort(r)
+	// Same median as the database queries: the lower middle for even counts.
+	mid := (len(scores)+1)/2 - 1
+	return Window{P50Score: float64(s[mid]), PercentRank: r[mid]}
+}
+
+// progress tracks a goal against a player's scores, which must be ordered
+// oldest first within each machine.
+func progress(g db.Goal, scores []db.PlayerMachineScore, names map[string]string) GoalProgress {
+	gp := GoalProgress{Goal: g, MachineName: output.MachineName(names, g.MachineKey)}
+
+	var since []db.PlayerMachineScore
+	for _, sc := range scores {
+		if sc.MachineKey == g.MachineKey && sc.Date >= g.SetOn {
+			since = append(since, sc)
+		}
+	}
+	gp.Games = len(since)
+	if gp.Games == 0 {
+		return gp
+	}
+
+	switch g.Kind {
+	case db.GoalScore:
+		for _, sc := range since {
+			gp.Current = max(gp.Current, float64(sc.Score))
+		}
+	case db.GoalPercentile:
+		gp.Current = summarize(since[max(0, len(since)-DefaultTrendWindow):]).PercentRank * 100
+	}
+	gp.Met = gp.Current >= g.Target
+	return gp
+}
@@ -8,35 +8,91 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
+	"github.com/negz/mnp/internal/badge"
 	"github.com/negz/mnp/internal/db"
 )
 
 type MockStore struct {
 	MockGetLeagueP50                func(ctx context.Context) (map[string]float64, error)
+	MockGetLeagueScores             func(ctx context.Context) (map[string]db.LeagueScores, error)
 	MockGetMachineNames             func(ctx context.Context) (map[string]string, error)
+	MockGetMonthlyP50               func(ctx context.Context, playerName string) ([]db.MonthlyStats, error)
 	MockGetPlayer                   func(ctx context.Context, playerName string) (db.PlayerSummary, error)
-	MockGetSinglePlayerMachineStats func(ctx context.Context, playerName, venueKey string) ([]db.PlayerMachineStats, error)
-	MockGetVenueMachines            func(ctx context.Context, venueKey string) (map[string]bool, error)
+	MockGetPlayerGameLog            func(ctx context.Context, playerName string) ([]db.GameLogEntry, error)
+	MockGetPlayerHomeAwaySplits     func(ctx context.Context, playerName string, seasons []int) (map[string]db.HomeAwaySplit, error)
+	MockGetPlayerMachinePoints      func(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]db.Points, error)
+	MockGetPlayerTrend              func(ctx context.Context, playerName string) ([]db.PlayerSeasonStats, error)
+	MockGetSignatureWin             func(ctx context.Context, playerName string) (*db.SignatureWin, error)
+	MockGetSinglePlayerMachineStats func(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error)
+	MockGetVenueMachines            func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListPlayerBadges            func(ctx context.Context, playerName string) ([]db.Badge, error)
+	MockListPlayerGoals             func(ctx context.Context, playerName string) ([]db.Goal, error)
+	MockListPlayerMachineScores     func(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error)
+	MockListPlayerNames             func(ctx context.Context) ([]string, error)
 }
 
 func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
 	return m.MockGetLeagueP50(ctx)
 }
 
+func (m *MockStore) GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error) {
+	return m.MockGetLeagueScores(ctx)
+}
+
 func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
 	return m.MockGetMachineNames(ctx)
 }
 
+func (m *MockStore) GetMonthlyP50(ctx context.Context, playerName string) ([]db.MonthlyStats, error) {
+	return m.MockGetMonthlyP50(ctx, playerName)
+}
+
 func (m *MockStore) GetPlayer(ctx context.Context, playerName string) (db.PlayerSummary, error) {
 	return m.MockGetPlayer(ctx, playerName)
 }
 
-func (m *MockStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string) ([]db.PlayerMachineStats, error) {
-	return m.MockGetSinglePlayerMachineStats(ctx, playerName, venueKey)
+func (m *MockStore) GetPlayerGameLog(ctx context.Context, playerName string) ([]db.GameLogEntry, error) {
+	return m.MockGetPlayerGameLog(ctx, playerName)
+}
+
+func (m *MockStore) GetPlayerHomeAwaySplits(ctx context.Context, playerName string, seasons []int) (map[string]db.HomeAwaySplit, error) {
+	return m.MockGetPlayerHomeAwaySplits(ctx, playerName, seasons)
+}
+
+func (m *MockStore) GetPlayerMachinePoints(ctx context.Context, playerName, venueKey string, seasons []int) (map[string]db.Points, error) {
+	return m.MockGetPlayerMachinePoints(ctx, playerName, venueKey, seasons)
 }
 
-func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error) {
-	return m.MockGetVenueMachines(ctx, venueKey)
+func (m *MockStore) GetPlayerTrend(ctx context.Context, playerName string) ([]db.PlayerSeasonStats, error) {
+	return m.MockGetPlayerTrend(ctx, playerName)
+}
+
+func (m *MockStore) GetSignatureWin(ctx context.Context, playerName string) (*db.SignatureWin, error) {
+	return m.MockGetSignatureWin(ctx, playerName)
+}
+
+func (m *MockStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error) {
+	return m.MockGetSinglePlayerMachineStats(ctx, playerName, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListPlayerBadges(ctx context.Context, playerName string) ([]db.Badge, error) {
+	return m.MockListPlayerBadges(ctx, playerName)
+}
+
+func (m *MockStore) ListPlayerGoals(ctx context.Context, playerName string) ([]db.Goal, error) {
+	return m.MockListPlayerGoals(ctx, playerName)
+}
+
+func (m *MockStore) ListPlayerMachineScores(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error) {
+	return m.MockListPlayerMachineScores(ctx, playerName)
+}
+
+func (m *MockStore) ListPlayerNames(ctx context.Context) ([]string, error) {
+	return m.MockListPlayerNames(ctx)
 }
 
 func TestAnalyze(t *testing.T) {
@@ -60,6 +116,9 @@ func TestAnalyze(t *testing.T) {
 			reason: "Without a venue option, the result should contain global stats, analysis, and the player's team.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{
 							"TAF": 30_000_000,
@@ -68,6 +127,9 @@ func TestAnalyze(t *testing.T) {
 							"AFM": 20_000_000,
 						}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{
 							"TAF": "The Addams Family",
@@ -76,7 +138,7 @@ func TestAnalyze(t *testing.T) {
 							"AFM": "Attack From Mars",
 						}, nil
 					},
-					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string) ([]db.PlayerMachineStats, error) {
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
 						return []db.PlayerMachineStats{
 							{MachineKey: "TAF", Games: 10, P50Score: 60_000_000, P90Score: 80_000_000},
 							{MachineKey: "MM", Games: 8, P50Score: 15_000_000, P90Score: 25_000_000},
@@ -107,17 +169,67 @@ func TestAnalyze(t *testing.T) {
 				},
 			},
 		},
+		"NormalizedStrength": {
+			reason: "Machines should be ranked by where the player's P50 falls among league scores, not by how far it is above the league P50.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return map[string]db.LeagueScores{
+							"TAF": {5_000_000, 10_000_000, 30_000_000, 70_000_000, 90_000_000},
+							"MM":  {12_000_000, 14_000_000, 15_000_000, 16_000_000, 17_000_000},
+						}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return []db.PlayerMachineStats{
+							{MachineKey: "TAF", Games: 5, P50Score: 60_000_000},
+							{MachineKey: "MM", Games: 5, P50Score: 18_000_000},
+						}, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+				},
+				name: "Alice",
+			},
+			want: want{
+				result: &Result{
+					Name: "Alice",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 5, P50Score: 60_000_000, LeagueP50: 30_000_000, PercentRank: 0.6},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 5, P50Score: 18_000_000, LeagueP50: 15_000_000, PercentRank: 1},
+					},
+					Analysis: Analysis{
+						Strongest: []string{"Medieval Madness", "The Addams Family"},
+					},
+				},
+			},
+		},
 		"GlobalTeamNotFound": {
 			reason: "When the player's team can't be determined, Team should be nil but the result should otherwise be correct.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TAF": "The Addams Family"}, nil
 					},
-					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string) ([]db.PlayerMachineStats, error) {
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
 						return []db.PlayerMachineStats{
 							{MachineKey: "TAF", Games: 5, P50Score: 50_000_000},
 						}, nil
@@ -144,16 +256,22 @@ func TestAnalyze(t *testing.T) {
 			reason: "With a venue option, global stats should be filtered to venue machines.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{"TAF": true, "MM": true}, nil
 					},
-					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string) ([]db.PlayerMachineStats, error) {
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
 						return []db.PlayerMachineStats{
 							{MachineKey: "TAF", Games: 10, P50Score: 50_000_000},
 							{MachineKey: "MM", Games: 8, P50Score: 20_000_000},
@@ -182,13 +300,647 @@ func TestAnalyze(t *testing.T) {
 				},
 			},
 		},
+		"WithTrends": {
+			reason: "With trends, each machine played at least twice the window should compare its last window of games against the one before.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockListPlayerMachineScores: func(_ context.Context, _ string) ([]db.PlayerMachineScore, error) {
+						return []db.PlayerMachineScore{
+							// Too few games to compare.
+							{MachineKey: "AFM", Score: 1, PercentRank: 0.1},
+							{MachineKey: "AFM", Score: 2, PercentRank: 0.2},
+							{MachineKey: "AFM", Score: 3, PercentRank: 0.3},
+							// The oldest game falls outside both windows.
+							{MachineKey: "MM", Score: 90, PercentRank: 0.9},
+							{MachineKey: "MM", Score: 50, PercentRank: 0.5},
+							{MachineKey: "MM", Score: 70, PercentRank: 0.7},
+							{MachineKey: "MM", Score: 40, PercentRank: 0.4},
+							{MachineKey: "MM", Score: 30, PercentRank: 0.3},
+							{MachineKey: "TAF", Score: 10, PercentRank: 0.2},
+							{MachineKey: "TAF", Score: 20, PercentRank: 0.4},
+							{MachineKey: "TAF", Score: 30, PercentRank: 0.6},
+							{MachineKey: "TAF", Score: 40, PercentRank: 0.8},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithTrends(2)},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Trends: []Trend{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, Previous: Window{P50Score: 10, PercentRank: 0.2}, Recent: Window{P50Score: 30, PercentRank: 0.6}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 2, Previous: Window{P50Score: 50, PercentRank: 0.5}, Recent: Window{P50Score: 30, PercentRank: 0.3}},
+					},
+				},
+			},
+		},
+		"ListPlayerMachineScoresError": {
+			reason: "An error loading the player's scores for trends should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, nil
+					},
+					MockListPlayerMachineScores: func(_ context.Context, _ string) ([]db.PlayerMachineScore, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithTrends(2)},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"WithSeasonTrends": {
+			reason: "With season trends, each machine played in at least two seasons should list its P50 season by season, biggest improvement first.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetPlayerTrend: func(_ context.Context, _ string) ([]db.PlayerSeasonStats, error) {
+						return []db.PlayerSeasonStats{
+							// Only one season, so there's no trend.
+							{MachineKey: "AFM", Season: 23, Games: 4, P50Score: 100},
+							{MachineKey: "MM", Season: 21, Games: 3, P50Score: 200},
+							{MachineKey: "MM", Season: 23, Games: 2, P50Score: 100},
+							{MachineKey: "TAF", Season: 22, Games: 5, P50Score: 100},
+							{MachineKey: "TAF", Season: 23, Games: 1, P50Score: 150},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithSeasonTrends()},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Seasons: []SeasonTrend{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Seasons: []Season{{Season: 22, Games: 5, P50Score: 100}, {Season: 23, Games: 1, P50Score: 150}}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Seasons: []Season{{Season: 21, Games: 3, P50Score: 200}, {Season: 23, Games: 2, P50Score: 100}}},
+					},
+				},
+			},
+		},
+		"GetPlayerTrendError": {
+			reason: "An error loading the player's season trend should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, nil
+					},
+					MockGetPlayerTrend: func(_ context.Context, _ string) ([]db.PlayerSeasonStats, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithSeasonTrends()},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"WithMonthlyTrends": {
+			reason: "With monthly trends, each machine played in at least two months should list its P50 over its most recent months, most played first.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetMonthlyP50: func(_ context.Context, _ string) ([]db.MonthlyStats, error) {
+						return []db.MonthlyStats{
+							// Only one month, so there's no trend.
+							{MachineKey: "AFM", Month: "2024-01", Games: 4, P50Score: 100},
+							{MachineKey: "MM", Month: "2023-11", Games: 1, P50Score: 200},
+							{MachineKey: "MM", Month: "2024-01", Games: 2, P50Score: 100},
+							// The oldest month is outside the window.
+							{MachineKey: "TAF", Month: "2023-09", Games: 3, P50Score: 50},
+							{MachineKey: "TAF", Month: "2023-10", Games: 2, P50Score: 100},
+							{MachineKey: "TAF", Month: "2024-01", Games: 2, P50Score: 150},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithMonthlyTrends(2)},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Months: []MonthlyTrend{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Months: []Month{{Month: "2023-10", Games: 2, P50Score: 100}, {Month: "2024-01", Games: 2, P50Score: 150}}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Months: []Month{{Month: "2023-11", Games: 1, P50Score: 200}, {Month: "2024-01", Games: 2, P50Score: 100}}},
+					},
+				},
+			},
+		},
+		"WithGameLog": {
+			reason: "With a game log, each machine played at least twice in the latest season should list its scores, most played first.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetPlayerGameLog: func(_ context.Context, _ string) ([]db.GameLogEntry, error) {
+						return []db.GameLogEntry{
+							// Only one game, so there's nothing to chart.
+							{MachineKey: "AFM", Season: 23, Week: 1, Date: "2024-01-08", Score: 100},
+							{MachineKey: "MM", Season: 23, Week: 1, Date: "2024-01-08", Score: 200},
+							{MachineKey: "MM", Season: 23, Week: 3, Date: "2024-01-22", Score: 300},
+							{MachineKey: "TAF", Season: 23, Week: 1, Date: "2024-01-08", Score: 50},
+							{MachineKey: "TAF", Season: 23, Week: 2, Score: 100},
+							{MachineKey: "TAF", Season: 23, Week: 3, Date: "2024-01-22", Score: 150},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithGameLog()},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					GameLogs: []GameLog{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Season: 23, Games: []Game{
+							{Week: 1, Date: "2024-01-08", Score: 50},
+							{Week: 2, Score: 100},
+							{Week: 3, Date: "2024-01-22", Score: 150},
+						}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Season: 23, Games: []Game{
+							{Week: 1, Date: "2024-01-08", Score: 200},
+							{Week: 3, Date: "2024-01-22", Score: 300},
+						}},
+					},
+				},
+			},
+		},
+		"WithGoals": {
+			reason: "With goals, each goal should be tracked against the player's scores on its machine from the day it was set.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockListPlayerGoals: func(_ context.Context, _ string) ([]db.Goal, error) {
+						return []db.Goal{
+							{ID: 1, MachineKey: "MM", Kind: db.GoalScore, Target: 100, SetOn: "2024-02-01"},
+							{ID: 2, MachineKey: "TAF", Kind: db.GoalPercentile, Target: 50, SetOn: "2024-01-01"},
+							{ID: 3, MachineKey: "TZ", Kind: db.GoalScore, Target: 100, SetOn: "2024-01-01"},
+						}, nil
+					},
+					MockListPlayerMachineScores: func(_ context.Context, _ string) ([]db.PlayerMachineScore, error) {
+						return []db.PlayerMachineScore{
+							// Posted before the goal was set, so it doesn't count.
+							{MachineKey: "MM", Score: 500, PercentRank: 0.9, Date: "2024-01-15"},
+							{MachineKey: "MM", Score: 120, PercentRank: 0.6, Date: "2024-02-05"},
+							{MachineKey: "MM", Score: 80, PercentRank: 0.4, Date: "2024-02-12"},
+							{MachineKey: "TAF", Score: 10, PercentRank: 0.2, Date: "2024-01-15"},
+							{MachineKey: "TAF", Score: 30, PercentRank: 0.4, Date: "2024-01-22"},
+							{MachineKey: "TAF", Score: 40, PercentRank: 0.7, Date: "2024-01-29"},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithGoals()},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Goals: []GoalProgress{
+						{Goal: db.Goal{ID: 1, MachineKey: "MM", Kind: db.GoalScore, Target: 100, SetOn: "2024-02-01"}, MachineName: "Medieval Madness", Games: 2, Current: 120, Met: true},
+						{Goal: db.Goal{ID: 2, MachineKey: "TAF", Kind: db.GoalPercentile, Target: 50, SetOn: "2024-01-01"}, MachineName: "The Addams Family", Games: 3, Current: 40},
+						{Goal: db.Goal{ID: 3, MachineKey: "TZ", Kind: db.GoalScore, Target: 100, SetOn: "2024-01-01"}, MachineName: "Twilight Zone"},
+					},
+				},
+			},
+		},
+		"ListPlayerGoalsError": {
+			reason: "An error loading the player's goals should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, nil
+					},
+					MockListPlayerGoals: func(_ context.Context, _ string) ([]db.Goal, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithGoals()},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"WithPoints": {
+			reason: "With points, each machine should include the points the player won on it, and the result their total.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return []db.PlayerMachineStats{{MachineKey: "TAF", Games: 2, P50Score: 60_000_000, P90Score: 80_000_000}}, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetPlayerMachinePoints: func(_ context.Context, _, _ string, _ []int) (map[string]db.Points, error) {
+						return map[string]db.Points{"TAF": {Games: 2, Won: 5, Possible: 5.5}}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithPoints()},
+			},
+			want: want{
+				result: &Result{
+					Name: "Alice",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, P50Score: 60_000_000, P90Score: 80_000_000, LeagueP50: 30_000_000, Points: db.Points{Games: 2, Won: 5, Possible: 5.5}},
+					},
+					Points: db.Points{Games: 2, Won: 5, Possible: 5.5},
+				},
+			},
+		},
+		"WithSplits": {
+			reason: "With splits, each machine should include the player's P50 at home and away.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return []db.PlayerMachineStats{{MachineKey: "TAF", Games: 2, P50Score: 60_000_000, P90Score: 80_000_000}}, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetPlayerHomeAwaySplits: func(_ context.Context, _ string, _ []int) (map[string]db.HomeAwaySplit, error) {
+						return map[string]db.HomeAwaySplit{
+							"TAF": {HomeGames: 1, HomeP50: 70_000_000, AwayGames: 1, AwayP50: 40_000_000},
+							"MM":  {HomeGames: 1, HomeP50: 10_000_000},
+						}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithSplits()},
+			},
+			want: want{
+				result: &Result{
+					Name: "Alice",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, P50Score: 60_000_000, P90Score: 80_000_000, LeagueP50: 30_000_000, Split: db.HomeAwaySplit{HomeGames: 1, HomeP50: 70_000_000, AwayGames: 1, AwayP50: 40_000_000}},
+					},
+				},
+			},
+		},
+		"GetPlayerMachinePointsError": {
+			reason: "An error loading the player's points should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetPlayerMachinePoints: func(_ context.Context, _, _ string, _ []int) (map[string]db.Points, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithPoints()},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"WithBadges": {
+			reason: "With badges, the result should describe each badge the player has earned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockListPlayerBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return []db.Badge{{PlayerName: "Alice", Badge: "billion", MatchKey: "mnp-23-1-TTT-KNR", EarnedOn: "2024-01-15"}}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithBadges()},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Badges: []badge.Earned{
+						{
+							Badge:       db.Badge{PlayerName: "Alice", Badge: "billion", MatchKey: "mnp-23-1-TTT-KNR", EarnedOn: "2024-01-15"},
+							Name:        "Billionaire",
+							Description: "Scored a billion points in a game.",
+						},
+					},
+				},
+			},
+		},
+		"WithSignatureWin": {
+			reason: "With a signature win, the result should include it with its machine's name.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"MM": "Medieval Madness"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						return nil, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+					MockGetSignatureWin: func(_ context.Context, _ string) (*db.SignatureWin, error) {
+						return &db.SignatureWin{MatchKey: "mnp-23-1-TTT-KNR", MachineKey: "MM", Opponent: "Bob", OpponentIPR: 6, Score: 700}, nil
+					},
+				},
+				name: "Alice",
+				opts: []Option{WithSignatureWin()},
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alice",
+					GlobalStats: []MachineStats{},
+					Win: &SignatureWin{
+						SignatureWin: db.SignatureWin{MatchKey: "mnp-23-1-TTT-KNR", MachineKey: "MM", Opponent: "Bob", OpponentIPR: 6, Score: 700},
+						MachineName:  "Medieval Madness",
+					},
+				},
+			},
+		},
+		"DifferentCase": {
+			reason: "A name that only differs in case should be resolved to the player's name as the archive spells it.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Bob"}, nil
+					},
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetSinglePlayerMachineStats: func(_ context.Context, name, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+						if name != "Alice" {
+							return nil, nil
+						}
+						return []db.PlayerMachineStats{
+							{MachineKey: "TAF", Games: 5, P50Score: 50_000_000},
+						}, nil
+					},
+					MockGetPlayer: func(_ context.Context, _ string) (db.PlayerSummary, error) {
+						return db.PlayerSummary{}, errors.New("not found")
+					},
+				},
+				name: "alice",
+			},
+			want: want{
+				result: &Result{
+					Name: "Alice",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 5, P50Score: 50_000_000, LeagueP50: 30_000_000},
+					},
+					Analysis: Analysis{
+						Strongest: []string{"The Addams Family"},
+					},
+				},
+			},
+		},
+		"Misspelled": {
+			reason: "A misspelled name should return a result with no stats that suggests similarly spelled names.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Bob", "Elise"}, nil
+					},
+				},
+				name: "Alise",
+			},
+			want: want{
+				result: &Result{
+					Name:        "Alise",
+					Suggestions: []string{"Alice", "Elise"},
+				},
+			},
+		},
+		"ListPlayerNamesError": {
+			reason: "An error loading player names should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "Alice",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
 		"GetLeagueP50Error": {
 			reason: "An error loading league P50 should be returned.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return nil, errors.New("boom")
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 				},
 				name: "Alice",
 			},
@@ -200,9 +952,15 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading machine names should be returned.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return nil, errors.New("boom")
 					},
@@ -217,13 +975,19 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading player stats should be returned.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{}, nil
 					},
-					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string) ([]db.PlayerMachineStats, error) {
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -237,16 +1001,22 @@ func TestAnalyze(t *testing.T) {
 			reason: "An error loading venue machines when analyzing at a venue should be returned.",
 			args: args{
 				store: &MockStore{
+					MockListPlayerNames: func(_ context.Context) ([]string, error) {
+						return []string{"Alice", "Unknown"}, nil
+					},
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{}, nil
 					},
-					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string) ([]db.PlayerMachineStats, error) {
+					MockGetSinglePlayerMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
 						return nil, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -0,0 +1,207 @@
+// Package practice prioritizes machines for a team to practice before its
+// upcoming matches.
+package practice
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// DefaultWeeks is how many weeks of upcoming matches to plan for by default.
+const DefaultWeeks = 3
+
+// minGames is the average number of games a team's likely players need on a
+// machine before the team counts as having played it.
+const minGames = 3
+
+// Store is the set of queries needed to plan practice.
+type Store interface {
+	matchup.Store
+
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+// Machine is a machine worth practicing, summed over the upcoming matches at
+// venues that have it.
+type Machine struct {
+	MachineKey  string
+	MachineName string
+	Matches     []db.ScheduleMatch // Upcoming matches at venues with this machine.
+	Edge        float64            // Average matchup edge over Matches. Positive favors the team.
+	LikelyGames float64            // Average games the team's likely players have played on it.
+	FewGames    bool               // The team's likely players have barely played it.
+	Behind      bool               // The team's likely players trail an opponent's in at least one match.
+	Priority    float64
+}
+
+// Reasons explains why the machine is worth practicing.
+func (m Machine) Reasons() []string {
+	var reasons []string
+	if m.FewGames {
+		reasons = append(reasons, "Few games")
+	}
+	if m.Behind {
+		reasons = append(reasons, "Behind")
+	}
+	return reasons
+}
+
+// Result is the output of a practice plan.
+type Result struct {
+	Team     string
+	Matches  []db.ScheduleMatch // The team's upcoming matches, soonest first.
+	Machines []Machine          // Highest priority first.
+}
+
+// Option configures a practice plan.
+type Option func(*Options)
+
+// Options holds optional parameters for a practice plan.
+type Options struct {
+	weeks int
+}
+
+// ForWeeks plans for the supplied number of weeks of upcoming matches, rather
+// than DefaultWeeks.
+func ForWeeks(n int) Option {
+	return func(o *Options) {
+		o.weeks = n
+	}
+}
+
+// Analyze plans practice for a team's matches in the upcoming weeks of the
+// schedule on or after the supplied date.
+//
+// Each machine at each upcoming venue is scored by need and leverage. Need is
+// one point if the team's likely players have barely played the machine, and
+// one if they trail the opponent's likely players on it. Leverage is how
+// contested the machine is: 1 for an even matchup, falling to 0 at a 100%
+// edge either way. A machine the team has barely played has full leverage,
+// since its edge means little. A machine's priority is the sum of need times
+// leverage over the upcoming matches it's available at.
+func Analyze(ctx context.Context, s Store, team, date string, opts ...Option) (*Result, error) {
+	o := Options{weeks: DefaultWeeks}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sched, err := s.ListSchedule(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("load schedule: %w", err)
+	}
+
+	r := &Result{Team: team}
+	weeks := 0
+	for i, m := range sched {
+		if i == 0 || m.Week != sched[i-1].Week {
+			weeks++
+		}
+		if weeks > o.weeks {
+			break
+		}
+		if m.HomeTeamKey != team && m.AwayTeamKey != team {
+			continue
+		}
+		r.Matches = append(r.Matches, m)
+	}
+
+	stats, err := s.GetTeamMachineStats(ctx, team, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("load team stats: %w", err)
+	}
+	games := make(map[string]float64, len(stats))
+	for _, ts := range stats {
+		games[ts.MachineKey] = likelyGames(ts.LikelyPlayers)
+	}
+
+	machines := make(map[string]*Machine)
+	edges := make(map[string]float64)
+	for _, m := range r.Matches {
+		if m.VenueKey == "" {
+			continue
+		}
+		opponent := m.AwayTeamKey
+		if opponent == team {
+			opponent = m.HomeTeamKey
+		}
+
+		mr, err := matchup.Analyze(ctx, s, m.VenueKey, team, opponent)
+		if err != nil {
+			return nil, fmt.Errorf("compare %s and %s: %w", team, opponent, err)
+		}
+
+		for _, mm := range mr.Machines {
+			pm, ok := machines[mm.MachineKey]
+			if !ok {
+				pm = &Machine{
+					MachineKey:  mm.MachineKey,
+					MachineName: mm.MachineName,
+					LikelyGames: games[mm.MachineKey],
+					FewGames:    games[mm.MachineKey] < minGames,
+				}
+				machines[mm.MachineKey] = pm
+			}
+			pm.Matches = append(pm.Matches, m)
+			pm.Behind = pm.Behind || mm.Edge < 0
+			edges[mm.MachineKey] += clampEdge(mm.Edge)
+			pm.Priority += priority(pm.FewGames, mm.Edge)
+		}
+	}
+
+	for key, pm := range machines {
+		pm.Edge = edges[key] / float64(len(pm.Matches))
+		if pm.Priority == 0 {
+			continue
+		}
+		r.Machines = append(r.Machines, *pm)
+	}
+
+	slices.SortFunc(r.Machines, func(a, b Machine) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return r, nil
+}
+
+// priority scores one upcoming game on a machine. See Analyze.
+func priority(fewGames bool, edge float64) float64 {
+	need := 0.0
+	if fewGames {
+		need++
+	}
+	if edge < 0 {
+		need++
+	}
+
+	leverage := 1.0
+	if !fewGames {
+		leverage = 1 - math.Abs(clampEdge(edge))/100
+	}
+	return need * leverage
+}
+
+// clampEdge limits an edge to ±100%. Edges are unbounded when one team has
+// never played a machine.
+func clampEdge(edge float64) float64 {
+	return max(-100, min(100, edge))
+}
+
+// likelyGames returns the average games a team's likely players have played.
+func likelyGames(players []db.LikelyPlayer) float64 {
+	if len(players) == 0 {
+		return 0
+	}
+	total := 0
+	for _, p := range players {
+		total += p.Games
+	}
+	return float64(total) / float64(len(players))
+}
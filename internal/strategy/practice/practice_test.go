@@ -0,0 +1,148 @@
+package practice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
+	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines    func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListSchedule        func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func TestAnalyze(t *testing.T) {
+	week1 := db.ScheduleMatch{Key: "mnp-23-1-PYC-CRA", Week: 1, HomeTeamKey: "CRA", AwayTeamKey: "PYC", VenueKey: "8BT"}
+	week2 := db.ScheduleMatch{Key: "mnp-23-2-CRA-KNR", Week: 2, HomeTeamKey: "KNR", AwayTeamKey: "CRA", VenueKey: "GPA"}
+	week3 := db.ScheduleMatch{Key: "mnp-23-3-TTT-CRA", Week: 3, HomeTeamKey: "CRA", AwayTeamKey: "TTT", VenueKey: "8BT"}
+
+	likely := func(games int, p50 float64) []db.LikelyPlayer {
+		return []db.LikelyPlayer{{Name: "Someone", Games: games, P50Score: p50}}
+	}
+	stats := map[string][]db.TeamMachineStats{
+		"CRA": {
+			{MachineKey: "TAF", LikelyPlayers: likely(10, 100)},
+			{MachineKey: "MM", LikelyPlayers: likely(1, 50)},
+			{MachineKey: "TZ", LikelyPlayers: likely(10, 200)},
+		},
+		"PYC": {
+			{MachineKey: "TAF", LikelyPlayers: likely(10, 120)},
+			{MachineKey: "MM", LikelyPlayers: likely(10, 50)},
+			{MachineKey: "TZ", LikelyPlayers: likely(10, 100)},
+		},
+		"KNR": {
+			{MachineKey: "TAF", LikelyPlayers: likely(10, 50)},
+		},
+	}
+
+	store := &MockStore{
+		MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+			return []db.ScheduleMatch{
+				week1,
+				{Week: 1, HomeTeamKey: "KNR", AwayTeamKey: "TTT", VenueKey: "GPA"},
+				week2,
+				week3,
+			}, nil
+		},
+		MockGetVenueMachines: func(_ context.Context, venue string, _ int) (map[string]bool, error) {
+			return map[string]map[string]bool{
+				"8BT": {"TAF": true, "MM": true, "TZ": true},
+				"GPA": {"TAF": true},
+			}[venue], nil
+		},
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+			return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone"}, nil
+		},
+		MockGetTeamMachineStats: func(_ context.Context, team, _ string, _ []int) ([]db.TeamMachineStats, error) {
+			return stats[team], nil
+		},
+	}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Prioritized": {
+			reason: "Contested machines the team has barely played, or trails on, should be prioritized; machines it leads comfortably on should be left out.",
+			args:   args{store: store, opts: []Option{ForWeeks(2)}},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Matches: []db.ScheduleMatch{week1, week2},
+				Machines: []Machine{
+					// Barely played and even with PYC: need 1, leverage 1.
+					{MachineKey: "MM", MachineName: "Medieval Madness", Matches: []db.ScheduleMatch{week1}, LikelyGames: 1, FewGames: true, Priority: 1},
+					// 20% behind PYC (need 1, leverage 0.8), but far ahead of KNR.
+					{MachineKey: "TAF", MachineName: "The Addams Family", Matches: []db.ScheduleMatch{week1, week2}, Edge: 40, LikelyGames: 10, Behind: true, Priority: 0.8},
+				},
+			}},
+		},
+		"DefaultWeeks": {
+			reason: "Without ForWeeks, the next three weeks should be planned for.",
+			args:   args{store: store},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Matches: []db.ScheduleMatch{week1, week2, week3},
+				Machines: []Machine{
+					{MachineKey: "MM", MachineName: "Medieval Madness", Matches: []db.ScheduleMatch{week1, week3}, Edge: 50, LikelyGames: 1, FewGames: true, Priority: 2},
+					{MachineKey: "TAF", MachineName: "The Addams Family", Matches: []db.ScheduleMatch{week1, week2, week3}, Edge: 60, LikelyGames: 10, Behind: true, Priority: 0.8},
+				},
+			}},
+		},
+		"ScheduleError": {
+			reason: "An error loading the schedule should be returned.",
+			args: args{store: &MockStore{
+				MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "CRA", "2025-01-01", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
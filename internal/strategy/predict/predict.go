@@ -0,0 +1,252 @@
+// Package predict predicts who a team will field in its next match, and which
+// machines it will pick, from the lineups and picks of its past matches.
+package predict
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
+	"github.com/negz/mnp/internal/output"
+)
+
+// DefaultMatches is how many of a team's most recent matches its lineup is
+// predicted from, by default.
+const DefaultMatches = 6
+
+// Teams field between minLineup and maxLineup players in a match.
+const (
+	minLineup = 8
+	maxLineup = 10
+)
+
+// Store is the set of queries needed to predict a team's lineup and picks.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	ListTeamLineups(ctx context.Context, teamKey string) ([]db.Lineup, error)
+	ListTeamRoundMachines(ctx context.Context, teamKey string, seasons []int) ([]db.RoundMachine, error)
+	ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error)
+}
+
+// Player is a player on the team's roster, and how often they've played.
+type Player struct {
+	Name    string
+	Matches int  // Recent matches the player was in the lineup for.
+	Likely  bool // The player is predicted to be in the lineup.
+}
+
+// Pick is a machine the team has picked in a round.
+type Pick struct {
+	MachineKey  string
+	MachineName string
+	Matches     int // Matches in which the team picked the machine in this round.
+}
+
+// Round is the machines the team is likely to pick in one round.
+type Round struct {
+	Number  int
+	Doubles bool
+	Picks   []Pick // Most often picked first.
+}
+
+// Result is the output of a prediction.
+type Result struct {
+	Team    string
+	Season  int      // The team's latest season, whose roster players are drawn from.
+	Matches int      // Recent matches the lineup was predicted from.
+	Lineup  int      // How many players the team is predicted to field.
+	Players []Player // The current roster, most often played first.
+	Rounds  []Round  // Rounds the team picks machines in, or every round without AtHome.
+}
+
+// Option configures a prediction.
+type Option func(*Options)
+
+// Options holds optional parameters for a prediction.
+type Options struct {
+	formats league.Formats
+	matches int
+	venue   string
+	home    *bool
+	seasons []int
+}
+
+// WithFormats uses the supplied league formats to decide how many rounds a
+// match has, and which are doubles, rather than the built-in formats.
+func WithFormats(f league.Formats) Option {
+	return func(o *Options) {
+		o.formats = f
+	}
+}
+
+// FromMatches predicts the lineup from the team's last n matches, rather than
+// its last DefaultMatches.
+func FromMatches(n int) Option {
+	return func(o *Options) {
+		o.matches = n
+	}
+}
+
+// AtVenue only predicts picks of machines at the venue.
+func AtVenue(key string) Option {
+	return func(o *Options) {
+		o.venue = key
+	}
+}
+
+// AtHome predicts picks for a match the team plays at home, or away if home is
+// false. Without it, picks are predicted for every round.
+func AtHome(home bool) Option {
+	return func(o *Options) {
+		o.home = &home
+	}
+}
+
+// InSeasons only counts picks made in the supplied seasons, rather than every
+// season, of the machines at the venue in the latest of them. It doesn't
+// affect the lineup, which is predicted from recent matches.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// Analyze predicts which players on a team's current roster it will field in
+// its next match, and which machines it will pick in each round.
+//
+// Players are ranked by how many of the team's recent lineups they were in,
+// and the top players are predicted to play, as many as the team usually
+// fields. Machines are ranked by how many of the team's matches it picked them
+// in, in the same round.
+func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
+	o := Options{formats: league.Defaults(), matches: DefaultMatches}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	played, err := s.ListTeamSeasons(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load team seasons: %w", err)
+	}
+	if len(played) == 0 {
+		return nil, fmt.Errorf("no seasons found for team %s", team)
+	}
+	season := played[len(played)-1]
+
+	roster, err := s.GetTeamSeasonRoster(ctx, team, season)
+	if err != nil {
+		return nil, fmt.Errorf("load roster: %w", err)
+	}
+
+	lineups, err := s.ListTeamLineups(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load lineups: %w", err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	var venueMachines map[string]bool
+	if o.venue != "" {
+		if venueMachines, err = s.GetVenueMachines(ctx, o.venue, db.LatestSeason(o.seasons)); err != nil {
+			return nil, fmt.Errorf("load venue machines: %w", err)
+		}
+	}
+
+	machines, err := s.ListTeamRoundMachines(ctx, team, o.seasons)
+	if err != nil {
+		return nil, fmt.Errorf("load team machines: %w", err)
+	}
+
+	recent := lineups[:min(o.matches, len(lineups))]
+	r := &Result{
+		Team:    team,
+		Season:  season,
+		Matches: len(recent),
+		Lineup:  lineupSize(recent),
+		Players: players(roster, recent),
+	}
+	for i := range r.Players[:min(r.Lineup, len(r.Players))] {
+		r.Players[i].Likely = r.Players[i].Matches > 0
+	}
+
+	format := o.formats.For(season)
+	for n := 1; n <= format.Rounds; n++ {
+		if o.home != nil && !picking(n, *o.home) {
+			continue
+		}
+		round := Round{Number: n, Doubles: format.IsDoubles(n)}
+		round.Picks = picks(machines, n, venueMachines, names)
+		r.Rounds = append(r.Rounds, round)
+	}
+
+	return r, nil
+}
+
+// picking returns true if a team picks machines in the supplied round.
+func picking(round int, home bool) bool {
+	return league.HomePicks(round) == home
+}
+
+// lineupSize returns the median number of players the team fielded in the
+// supplied matches, within the league's limits.
+func lineupSize(lineups []db.Lineup) int {
+	if len(lineups) == 0 {
+		return minLineup
+	}
+	sizes := make([]int, len(lineups))
+	for i, l := range lineups {
+		sizes[i] = len(l.Players)
+	}
+	slices.Sort(sizes)
+	return min(max(sizes[len(sizes)/2], minLineup), maxLineup)
+}
+
+// players returns the roster, ranked by how many of the supplied matches each
+// player played in.
+func players(roster []string, lineups []db.Lineup) []Player {
+	result := make([]Player, len(roster))
+	for i, name := range roster {
+		result[i] = Player{Name: name}
+		for _, l := range lineups {
+			if slices.Contains(l.Players, name) {
+				result[i].Matches++
+			}
+		}
+	}
+	slices.SortStableFunc(result, func(a, b Player) int {
+		return cmp.Compare(b.Matches, a.Matches)
+	})
+	return result
+}
+
+// picks returns the machines the team picked in a round, most often first.
+// Only matches in which the team picked the round's machines count. If
+// venueMachines is non-nil, only machines at the venue are included.
+func picks(machines []db.RoundMachine, round int, venueMachines map[string]bool, names map[string]string) []Pick {
+	var result []Pick
+	for _, m := range machines {
+		if m.Round != round || !picking(round, m.Home) {
+			continue
+		}
+		if venueMachines != nil && !venueMachines[m.MachineKey] {
+			continue
+		}
+		result = append(result, Pick{
+			MachineKey:  m.MachineKey,
+			MachineName: output.MachineName(names, m.MachineKey),
+			Matches:     m.Matches,
+		})
+	}
+	slices.SortFunc(result, func(a, b Pick) int {
+		return cmp.Or(cmp.Compare(b.Matches, a.Matches), cmp.Compare(a.MachineName, b.MachineName))
+	})
+	return result
+}
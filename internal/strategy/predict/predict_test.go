@@ -0,0 +1,233 @@
+package predict
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames       func(ctx context.Context) (map[string]string, error)
+	MockGetTeamSeasonRoster   func(ctx context.Context, teamKey string, season int) ([]string, error)
+	MockGetVenueMachines      func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListTeamLineups       func(ctx context.Context, teamKey string) ([]db.Lineup, error)
+	MockListTeamRoundMachines func(ctx context.Context, teamKey string, seasons []int) ([]db.RoundMachine, error)
+	MockListTeamSeasons       func(ctx context.Context, teamKey string) ([]int, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	return m.MockGetTeamSeasonRoster(ctx, teamKey, season)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListTeamLineups(ctx context.Context, teamKey string) ([]db.Lineup, error) {
+	return m.MockListTeamLineups(ctx, teamKey)
+}
+
+func (m *MockStore) ListTeamRoundMachines(ctx context.Context, teamKey string, seasons []int) ([]db.RoundMachine, error) {
+	return m.MockListTeamRoundMachines(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error) {
+	return m.MockListTeamSeasons(ctx, teamKey)
+}
+
+func TestAnalyze(t *testing.T) {
+	// Nine regulars, who play most weeks, and two subs who rarely do.
+	regulars := []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Gina", "Hank", "Ivy"}
+	lineup := func(key string, players ...string) db.Lineup {
+		return db.Lineup{MatchKey: key, Season: 23, Players: players}
+	}
+
+	store := &MockStore{
+		MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) {
+			return []int{22, 23}, nil
+		},
+		MockGetTeamSeasonRoster: func(_ context.Context, _ string, season int) ([]string, error) {
+			if season != 23 {
+				return nil, errors.New("wrong season")
+			}
+			return append(regulars, "Jo", "Kim"), nil
+		},
+		MockListTeamLineups: func(_ context.Context, _ string) ([]db.Lineup, error) {
+			return []db.Lineup{
+				// Ivy missed the latest match, and Jo subbed in.
+				lineup("mnp-23-4", "Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Gina", "Hank", "Jo"),
+				lineup("mnp-23-3", regulars...),
+				lineup("mnp-23-2", regulars...),
+				// Too old to count in the last three matches.
+				lineup("mnp-23-1", "Jo", "Kim", "Zed"),
+			}, nil
+		},
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+			return map[string]string{"TAF": "The Addams Family", "TZ": "Twilight Zone", "MM": "Medieval Madness"}, nil
+		},
+		MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
+			return map[string]bool{"TAF": true, "MM": true}, nil
+		},
+		MockListTeamRoundMachines: func(_ context.Context, _ string, _ []int) ([]db.RoundMachine, error) {
+			return []db.RoundMachine{
+				// Picked away.
+				{Round: 1, MachineKey: "TZ", Matches: 3},
+				{Round: 1, MachineKey: "TAF", Matches: 2},
+				// Picked by the other team, at home.
+				{Round: 1, Home: true, MachineKey: "MM", Matches: 5},
+				// Picked at home.
+				{Round: 2, Home: true, MachineKey: "MM", Matches: 4},
+				{Round: 2, Home: true, MachineKey: "TAF", Matches: 4},
+			}, nil
+		},
+	}
+
+	players := []Player{
+		{Name: "Alice", Matches: 3, Likely: true},
+		{Name: "Bob", Matches: 3, Likely: true},
+		{Name: "Carol", Matches: 3, Likely: true},
+		{Name: "Dave", Matches: 3, Likely: true},
+		{Name: "Erin", Matches: 3, Likely: true},
+		{Name: "Frank", Matches: 3, Likely: true},
+		{Name: "Gina", Matches: 3, Likely: true},
+		{Name: "Hank", Matches: 3, Likely: true},
+		{Name: "Ivy", Matches: 2, Likely: true},
+		{Name: "Jo", Matches: 1},
+		{Name: "Kim"},
+	}
+	taf := Pick{MachineKey: "TAF", MachineName: "The Addams Family", Matches: 2}
+	tz := Pick{MachineKey: "TZ", MachineName: "Twilight Zone", Matches: 3}
+	mm := Pick{MachineKey: "MM", MachineName: "Medieval Madness", Matches: 4}
+	tafHome := Pick{MachineKey: "TAF", MachineName: "The Addams Family", Matches: 4}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"EveryRound": {
+			reason: "The players who played the most recent matches should be likely to play, and each round should list the machines the team picked in it, most often first.",
+			args:   args{store: store, opts: []Option{FromMatches(3)}},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Season:  23,
+				Matches: 3,
+				Lineup:  9,
+				Players: players,
+				Rounds: []Round{
+					{Number: 1, Doubles: true, Picks: []Pick{tz, taf}},
+					{Number: 2, Picks: []Pick{mm, tafHome}},
+					{Number: 3},
+					{Number: 4, Doubles: true},
+				},
+			}},
+		},
+		"AwayAtVenue": {
+			reason: "Playing away, only the rounds the away team picks should be listed, with machines at the venue.",
+			args:   args{store: store, opts: []Option{FromMatches(3), AtHome(false), AtVenue("STN")}},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Season:  23,
+				Matches: 3,
+				Lineup:  9,
+				Players: players,
+				Rounds: []Round{
+					{Number: 1, Doubles: true, Picks: []Pick{taf}},
+					{Number: 3},
+				},
+			}},
+		},
+		"FromMatches": {
+			reason: "Predicting from one match, ties should keep roster order, and at home only the rounds the home team picks should be listed.",
+			args:   args{store: store, opts: []Option{FromMatches(1), AtHome(true)}},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Season:  23,
+				Matches: 1,
+				Lineup:  9,
+				Players: []Player{
+					{Name: "Alice", Matches: 1, Likely: true},
+					{Name: "Bob", Matches: 1, Likely: true},
+					{Name: "Carol", Matches: 1, Likely: true},
+					{Name: "Dave", Matches: 1, Likely: true},
+					{Name: "Erin", Matches: 1, Likely: true},
+					{Name: "Frank", Matches: 1, Likely: true},
+					{Name: "Gina", Matches: 1, Likely: true},
+					{Name: "Hank", Matches: 1, Likely: true},
+					{Name: "Jo", Matches: 1, Likely: true},
+					{Name: "Ivy"},
+					{Name: "Kim"},
+				},
+				Rounds: []Round{
+					{Number: 2, Picks: []Pick{mm, tafHome}},
+					{Number: 4, Doubles: true},
+				},
+			}},
+		},
+		"NoSeasons": {
+			reason: "A team that has never played should return an error.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) {
+					return nil, nil
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+		"ListTeamLineupsError": {
+			reason: "An error loading lineups should be returned.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons:     store.MockListTeamSeasons,
+				MockGetTeamSeasonRoster: store.MockGetTeamSeasonRoster,
+				MockListTeamLineups: func(_ context.Context, _ string) ([]db.Lineup, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+		"ListTeamRoundMachinesError": {
+			reason: "An error loading the machines the team played should be returned.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons:     store.MockListTeamSeasons,
+				MockGetTeamSeasonRoster: store.MockGetTeamSeasonRoster,
+				MockListTeamLineups:     store.MockListTeamLineups,
+				MockGetMachineNames:     store.MockGetMachineNames,
+				MockListTeamRoundMachines: func(_ context.Context, _ string, _ []int) ([]db.RoundMachine, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "CRA", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want result, +got result:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Package recap summarizes a completed match.
+package recap
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/negz/mnp/internal/badge"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Store is the set of queries needed for a match recap.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	ListMatchBadges(ctx context.Context, matchKey string) ([]db.Badge, error)
+	ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error)
+	ListPlayedMatches(ctx context.Context, teamKey string) ([]db.PlayedMatch, error)
+	ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error)
+}
+
+// Side is one team's players and points in a game.
+type Side struct {
+	TeamKey string
+	Players []string
+	IPR     int // Combined IPR of the side's players. Zero if any are unrated.
+	Points  float64
+}
+
+// Game is a single game within a match.
+type Game struct {
+	Round       int
+	MachineKey  string
+	MachineName string
+	Home        Side
+	Away        Side
+}
+
+// Winner returns the side that earned more points, and false if the game was
+// tied.
+func (g Game) Winner() (winner, loser Side, ok bool) {
+	switch {
+	case g.Home.Points > g.Away.Points:
+		return g.Home, g.Away, true
+	case g.Away.Points > g.Home.Points:
+		return g.Away, g.Home, true
+	}
+	return Side{}, Side{}, false
+}
+
+// PlayerPoints is the total points a player earned in a match.
+type PlayerPoints struct {
+	Name    string
+	TeamKey string
+	Points  float64
+}
+
+// Upset is a game won by the side with the lower combined IPR.
+type Upset struct {
+	Game   Game
+	Winner string // Team key of the winning side.
+	IPRGap int    // How much higher the losing side's combined IPR was.
+}
+
+// A Prediction is the edge predicted on a machine before a match, and how it
+// turned out.
+type Prediction struct {
+	MachineKey  string
+	MachineName string
+	Favorite    string  // Key of the team predicted to have the edge.
+	Winner      string  // Key of the team that won more points. Empty if tied.
+	Edge        float64 // The favorite's predicted edge, as a percentage.
+	Confidence  int
+}
+
+// Right returns true if the favorite won more points.
+func (p Prediction) Right() bool {
+	return p.Winner == p.Favorite
+}
+
+// Wrong returns true if the favorite won fewer points.
+func (p Prediction) Wrong() bool {
+	return p.Winner != "" && p.Winner != p.Favorite
+}
+
+// Grade returns how a recorded prediction turned out. It returns false if the
+// prediction gave neither team the edge.
+func Grade(po db.PredictionOutcome) (Prediction, bool) {
+	p := Prediction{MachineKey: po.MachineKey, Edge: math.Abs(po.Edge), Confidence: po.Confidence}
+	switch {
+	case po.Edge > 0:
+		p.Favorite = po.HomeTeamKey
+	case po.Edge < 0:
+		p.Favorite = po.AwayTeamKey
+	default:
+		return Prediction{}, false
+	}
+	switch {
+	case po.HomePoints > po.AwayPoints:
+		p.Winner = po.HomeTeamKey
+	case po.AwayPoints > po.HomePoints:
+		p.Winner = po.AwayTeamKey
+	}
+	return p, true
+}
+
+// Result is the output of a Recap query.
+type Result struct {
+	Team       string
+	Match      *db.PlayedMatch // Nil if the team hasn't completed a match this season.
+	HomePoints float64
+	AwayPoints float64
+	MVPs       []PlayerPoints // Players with the most points. Several if tied.
+	Upset      *Upset         // Nil if no game was an upset.
+	Badges     []badge.Earned // Badges players earned for the first time in the match.
+	Games      []Game
+
+	// Predictions are the edges predicted on the match's machines, in the
+	// order they were played. Empty if no predictions were recorded.
+	Predictions []Prediction
+}
+
+// Right returns the predictions the favorite won.
+func (r *Result) Right() []Prediction {
+	var out []Prediction
+	for _, p := range r.Predictions {
+		if p.Right() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Wrong returns the predictions the favorite lost.
+func (r *Result) Wrong() []Prediction {
+	var out []Prediction
+	for _, p := range r.Predictions {
+		if p.Wrong() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Option configures a Recap query.
+type Option func(*Options)
+
+// Options holds optional parameters for a Recap query.
+type Options struct {
+	match string
+}
+
+// ForMatch recaps a specific match, rather than the team's most recent one.
+func ForMatch(key string) Option {
+	return func(o *Options) {
+		o.match = key
+	}
+}
+
+// Analyze recaps a team's most recently completed match. Predictions are the
+// ones the web UI records before each week's matches, so there are none if it
+// wasn't running.
+func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	played, err := s.ListPlayedMatches(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load played matches: %w", err)
+	}
+
+	r := &Result{Team: team}
+	for i := range played {
+		if o.match == "" || played[i].Key == o.match {
+			r.Match = &played[i]
+			break
+		}
+	}
+	if r.Match == nil {
+		return r, nil
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	results, err := s.ListMatchResults(ctx, r.Match.Key)
+	if err != nil {
+		return nil, fmt.Errorf("load match results: %w", err)
+	}
+
+	r.Games = groupGames(results, r.Match.HomeTeamKey, names)
+	for _, g := range r.Games {
+		r.HomePoints += g.Home.Points
+		r.AwayPoints += g.Away.Points
+	}
+	r.MVPs = mvps(results)
+	r.Upset = biggestUpset(r.Games)
+
+	badges, err := s.ListMatchBadges(ctx, r.Match.Key)
+	if err != nil {
+		return nil, fmt.Errorf("load match badges: %w", err)
+	}
+	r.Badges = badge.Describe(badges)
+
+	outcomes, err := s.ListPredictionOutcomes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load prediction outcomes: %w", err)
+	}
+	graded := make(map[string]Prediction)
+	for _, po := range outcomes {
+		if po.MatchKey != r.Match.Key {
+			continue
+		}
+		if p, ok := Grade(po); ok {
+			p.MachineName = output.MachineName(names, po.MachineKey)
+			graded[po.MachineKey] = p
+		}
+	}
+	for _, g := range r.Games {
+		if p, ok := graded[g.MachineKey]; ok {
+			r.Predictions = append(r.Predictions, p)
+			delete(graded, g.MachineKey) // A machine played twice was predicted once.
+		}
+	}
+
+	return r, nil
+}
+
+// groupGames groups player results into games, in the order they were played.
+func groupGames(results []db.MatchResult, homeKey string, names map[string]string) []Game {
+	var games []Game
+	index := make(map[int64]int)
+	unrated := make(map[int64]map[string]bool)
+
+	for _, mr := range results {
+		i, ok := index[mr.GameID]
+		if !ok {
+			i = len(games)
+			index[mr.GameID] = i
+			unrated[mr.GameID] = make(map[string]bool)
+			games = append(games, Game{
+				Round:       mr.Round,
+				MachineKey:  mr.MachineKey,
+				MachineName: output.MachineName(names, mr.MachineKey),
+			})
+		}
+
+		side := &games[i].Away
+		if mr.TeamKey == homeKey {
+			side = &games[i].Home
+		}
+		side.TeamKey = mr.TeamKey
+		side.Players = append(side.Players, mr.PlayerName)
+		side.IPR += mr.IPR
+		side.Points += mr.Points
+		if mr.IPR == 0 {
+			unrated[mr.GameID][mr.TeamKey] = true
+		}
+	}
+
+	// A combined IPR that's missing a player isn't comparable.
+	for id, i := range index {
+		if unrated[id][games[i].Home.TeamKey] {
+			games[i].Home.IPR = 0
+		}
+		if unrated[id][games[i].Away.TeamKey] {
+			games[i].Away.IPR = 0
+		}
+	}
+
+	return games
+}
+
+// mvps returns the players who earned the most points in the match.
+func mvps(results []db.MatchResult) []PlayerPoints {
+	var totals []PlayerPoints
+	index := make(map[string]int)
+	for _, mr := range results {
+		i, ok := index[mr.PlayerName]
+		if !ok {
+			i = len(totals)
+			index[mr.PlayerName] = i
+			totals = append(totals, PlayerPoints{Name: mr.PlayerName, TeamKey: mr.TeamKey})
+		}
+		totals[i].Points += mr.Points
+	}
+
+	best := 0.0
+	for _, t := range totals {
+		best = max(best, t.Points)
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var out []PlayerPoints
+	for _, t := range totals {
+		if t.Points == best {
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b PlayerPoints) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+// biggestUpset returns the game won by the side with the largest IPR deficit.
+func biggestUpset(games []Game) *Upset {
+	var u *Upset
+	for _, g := range games {
+		winner, loser, ok := g.Winner()
+		if !ok || winner.IPR == 0 || loser.IPR == 0 {
+			continue
+		}
+		gap := loser.IPR - winner.IPR
+		if gap <= 0 {
+			continue
+		}
+		if u == nil || gap > u.IPRGap {
+			u = &Upset{Game: g, Winner: winner.TeamKey, IPRGap: gap}
+		}
+	}
+	return u
+}
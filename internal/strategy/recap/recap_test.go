@@ -0,0 +1,401 @@
+package recap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/badge"
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames        func(ctx context.Context) (map[string]string, error)
+	MockListMatchBadges        func(ctx context.Context, matchKey string) ([]db.Badge, error)
+	MockListMatchResults       func(ctx context.Context, matchKey string) ([]db.MatchResult, error)
+	MockListPlayedMatches      func(ctx context.Context, teamKey string) ([]db.PlayedMatch, error)
+	MockListPredictionOutcomes func(ctx context.Context) ([]db.PredictionOutcome, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) ListMatchBadges(ctx context.Context, matchKey string) ([]db.Badge, error) {
+	return m.MockListMatchBadges(ctx, matchKey)
+}
+
+func (m *MockStore) ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error) {
+	return m.MockListMatchResults(ctx, matchKey)
+}
+
+func (m *MockStore) ListPlayedMatches(ctx context.Context, teamKey string) ([]db.PlayedMatch, error) {
+	return m.MockListPlayedMatches(ctx, teamKey)
+}
+
+func (m *MockStore) ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error) {
+	return m.MockListPredictionOutcomes(ctx)
+}
+
+func TestAnalyze(t *testing.T) {
+	week2 := db.PlayedMatch{Key: "mnp-23-2-HOM-AWY", Week: 2, HomeTeamKey: "HOM", AwayTeamKey: "AWY"}
+	week1 := db.PlayedMatch{Key: "mnp-23-1-AWY-HOM", Week: 1, HomeTeamKey: "AWY", AwayTeamKey: "HOM"}
+
+	// HOM has lower rated players, but wins both singles games.
+	results := []db.MatchResult{
+		{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Hank", TeamKey: "HOM", IPR: 3, Points: 1},
+		{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Andy", TeamKey: "AWY", IPR: 5, Points: 2},
+		{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Hope", TeamKey: "HOM", IPR: 2, Points: 1},
+		{GameID: 1, Round: 1, MachineKey: "TAF", PlayerName: "Amy", TeamKey: "AWY", IPR: 4, Points: 1},
+		{GameID: 2, Round: 2, MachineKey: "MM", PlayerName: "Hank", TeamKey: "HOM", IPR: 3, Points: 3},
+		{GameID: 2, Round: 2, MachineKey: "MM", PlayerName: "Andy", TeamKey: "AWY", IPR: 5, Points: 0},
+		{GameID: 3, Round: 2, MachineKey: "TZ", PlayerName: "Hope", TeamKey: "HOM", IPR: 2, Points: 3},
+		{GameID: 3, Round: 2, MachineKey: "TZ", PlayerName: "Andy", TeamKey: "AWY", IPR: 5, Points: 0},
+	}
+
+	// AWY was predicted to win TAF and TZ, and HOM to win MM. The prediction
+	// for the week 1 match isn't part of the week 2 recap.
+	predicted := func(match db.PlayedMatch, machine string, edge, home, away float64) db.PredictionOutcome {
+		return db.PredictionOutcome{
+			Prediction: db.Prediction{MatchKey: match.Key, MachineKey: machine, HomeTeamKey: match.HomeTeamKey, AwayTeamKey: match.AwayTeamKey, Edge: edge, Confidence: 1},
+			HomePoints: home,
+			AwayPoints: away,
+		}
+	}
+	outcomes := []db.PredictionOutcome{
+		predicted(week1, "TAF", 10, 3, 2),
+		predicted(week2, "MM", 15, 3, 0),
+		predicted(week2, "TAF", -20, 2, 3),
+		predicted(week2, "TZ", -5, 3, 0),
+	}
+
+	names := map[string]string{
+		"TAF": "The Addams Family",
+		"MM":  "Medieval Madness",
+		"TZ":  "Twilight Zone",
+	}
+
+	tz := Game{
+		Round:       2,
+		MachineKey:  "TZ",
+		MachineName: "Twilight Zone",
+		Home:        Side{TeamKey: "HOM", Players: []string{"Hope"}, IPR: 2, Points: 3},
+		Away:        Side{TeamKey: "AWY", Players: []string{"Andy"}, IPR: 5},
+	}
+
+	type args struct {
+		store Store
+		team  string
+		opts  []Option
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MostRecentMatch": {
+			reason: "The recap should cover the team's most recent match, with totals, MVPs, the biggest upset, and how its predictions turned out.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2, week1}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, matchKey string) ([]db.MatchResult, error) {
+						if diff := cmp.Diff(week2.Key, matchKey); diff != "" {
+							t.Errorf("ListMatchResults matchKey: -want, +got:\n%s", diff)
+						}
+						return results, nil
+					},
+					MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return []db.Badge{{PlayerName: "Hope", Badge: "giant-killer", MatchKey: week2.Key}}, nil
+					},
+					MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+						return outcomes, nil
+					},
+				},
+				team: "HOM",
+			},
+			want: want{
+				result: &Result{
+					Team:       "HOM",
+					Match:      &week2,
+					HomePoints: 8,
+					AwayPoints: 3,
+					MVPs: []PlayerPoints{
+						{Name: "Hank", TeamKey: "HOM", Points: 4},
+						{Name: "Hope", TeamKey: "HOM", Points: 4},
+					},
+					Upset: &Upset{Game: tz, Winner: "HOM", IPRGap: 3},
+					Badges: []badge.Earned{
+						{
+							Badge:       db.Badge{PlayerName: "Hope", Badge: "giant-killer", MatchKey: week2.Key},
+							Name:        "Giant Killer",
+							Description: "Beat a higher rated player in a singles game.",
+						},
+					},
+					Games: []Game{
+						{
+							Round:       1,
+							MachineKey:  "TAF",
+							MachineName: "The Addams Family",
+							Home:        Side{TeamKey: "HOM", Players: []string{"Hank", "Hope"}, IPR: 5, Points: 2},
+							Away:        Side{TeamKey: "AWY", Players: []string{"Andy", "Amy"}, IPR: 9, Points: 3},
+						},
+						{
+							Round:       2,
+							MachineKey:  "MM",
+							MachineName: "Medieval Madness",
+							Home:        Side{TeamKey: "HOM", Players: []string{"Hank"}, IPR: 3, Points: 3},
+							Away:        Side{TeamKey: "AWY", Players: []string{"Andy"}, IPR: 5},
+						},
+						tz,
+					},
+					Predictions: []Prediction{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Favorite: "AWY", Winner: "AWY", Edge: 20, Confidence: 1},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Favorite: "HOM", Winner: "HOM", Edge: 15, Confidence: 1},
+						{MachineKey: "TZ", MachineName: "Twilight Zone", Favorite: "AWY", Winner: "HOM", Edge: 5, Confidence: 1},
+					},
+				},
+			},
+		},
+		"ForMatch": {
+			reason: "The ForMatch option should recap the requested match.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2, week1}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, matchKey string) ([]db.MatchResult, error) {
+						if diff := cmp.Diff(week1.Key, matchKey); diff != "" {
+							t.Errorf("ListMatchResults matchKey: -want, +got:\n%s", diff)
+						}
+						return nil, nil
+					},
+					MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return nil, nil
+					},
+					MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+						return nil, nil
+					},
+				},
+				team: "HOM",
+				opts: []Option{ForMatch(week1.Key)},
+			},
+			want: want{
+				result: &Result{Team: "HOM", Match: &week1},
+			},
+		},
+		"UnratedPlayer": {
+			reason: "A game with an unrated player can't be judged an upset.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, _ string) ([]db.MatchResult, error) {
+						return []db.MatchResult{
+							{GameID: 1, Round: 2, MachineKey: "MM", PlayerName: "Hank", TeamKey: "HOM", Points: 3},
+							{GameID: 1, Round: 2, MachineKey: "MM", PlayerName: "Andy", TeamKey: "AWY", IPR: 5},
+						}, nil
+					},
+					MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return nil, nil
+					},
+					MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+						return nil, nil
+					},
+				},
+				team: "HOM",
+			},
+			want: want{
+				result: &Result{
+					Team:       "HOM",
+					Match:      &week2,
+					HomePoints: 3,
+					MVPs:       []PlayerPoints{{Name: "Hank", TeamKey: "HOM", Points: 3}},
+					Games: []Game{
+						{
+							Round:       2,
+							MachineKey:  "MM",
+							MachineName: "Medieval Madness",
+							Home:        Side{TeamKey: "HOM", Players: []string{"Hank"}, Points: 3},
+							Away:        Side{TeamKey: "AWY", Players: []string{"Andy"}, IPR: 5},
+						},
+					},
+				},
+			},
+		},
+		"NoPlayedMatches": {
+			reason: "A team that hasn't completed a match should get an empty recap.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return nil, nil
+					},
+				},
+				team: "HOM",
+			},
+			want: want{
+				result: &Result{Team: "HOM"},
+			},
+		},
+		"ListPlayedMatchesError": {
+			reason: "An error loading played matches should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "HOM",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"ListMatchResultsError": {
+			reason: "An error loading match results should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, _ string) ([]db.MatchResult, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "HOM",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"ListMatchBadgesError": {
+			reason: "An error loading the badges earned in the match should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, _ string) ([]db.MatchResult, error) {
+						return results, nil
+					},
+					MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "HOM",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"ListPredictionOutcomesError": {
+			reason: "An error loading prediction outcomes should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListPlayedMatches: func(_ context.Context, _ string) ([]db.PlayedMatch, error) {
+						return []db.PlayedMatch{week2}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return names, nil
+					},
+					MockListMatchResults: func(_ context.Context, _ string) ([]db.MatchResult, error) {
+						return results, nil
+					},
+					MockListMatchBadges: func(_ context.Context, _ string) ([]db.Badge, error) {
+						return nil, nil
+					},
+					MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "HOM",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, tc.args.team, tc.args.opts...)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGrade(t *testing.T) {
+	outcome := func(edge, home, away float64) db.PredictionOutcome {
+		return db.PredictionOutcome{
+			Prediction: db.Prediction{MachineKey: "TZ", HomeTeamKey: "HOM", AwayTeamKey: "AWY", Edge: edge, Confidence: 2},
+			HomePoints: home,
+			AwayPoints: away,
+		}
+	}
+
+	type want struct {
+		p     Prediction
+		ok    bool
+		right bool
+		wrong bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		po     db.PredictionOutcome
+		want   want
+	}{
+		"Right": {
+			reason: "A favorite that won more points was predicted right.",
+			po:     outcome(12, 3, 0),
+			want:   want{p: Prediction{MachineKey: "TZ", Favorite: "HOM", Winner: "HOM", Edge: 12, Confidence: 2}, ok: true, right: true},
+		},
+		"Wrong": {
+			reason: "A favorite that won fewer points was predicted wrong.",
+			po:     outcome(-12, 3, 0),
+			want:   want{p: Prediction{MachineKey: "TZ", Favorite: "AWY", Winner: "HOM", Edge: 12, Confidence: 2}, ok: true, wrong: true},
+		},
+		"Tied": {
+			reason: "A tied machine was predicted neither right nor wrong.",
+			po:     outcome(12, 1.5, 1.5),
+			want:   want{p: Prediction{MachineKey: "TZ", Favorite: "HOM", Edge: 12, Confidence: 2}, ok: true},
+		},
+		"NoEdge": {
+			reason: "A prediction without an edge has no favorite to grade.",
+			po:     outcome(0, 3, 0),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, ok := Grade(tc.po)
+			got := want{p: p, ok: ok, right: ok && p.Right(), wrong: ok && p.Wrong()}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nGrade(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -11,7 +11,9 @@ import (
 // Store is the set of queries needed for player recommendations.
 type Store interface {
 	GetLeagueP50(ctx context.Context) (map[string]float64, error)
-	GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string) ([]db.PlayerStats, error)
+	GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]db.PlayerStats, error)
+	GetPlayerMachinePercentiles(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error)
+	GetExternalMachineStats(ctx context.Context, venueKey, machineKey string) ([]db.ExternalStats, error)
 }
 
 // PlayerStats is a player's performance on the target machine.
@@ -22,7 +24,8 @@ type PlayerStats struct {
 	P90Score    float64
 	LeagueP50   float64
 	IPR         int
-	NoVenueData bool // True in global stats when this player has no venue-specific data.
+	NoVenueData bool      // True in global stats when this player has no venue-specific data.
+	Percentiles []float64 // Scores at the Result's Percentiles. Only set with WithPercentiles.
 }
 
 // Assessment summarizes how the team's best compares to the opponent's best.
@@ -53,6 +56,11 @@ type Result struct {
 	GlobalStats   []PlayerStats // Always populated (basic or global fallback).
 	OpponentStats []PlayerStats // Nil if no opponent.
 	Assessment    *Assessment   // Nil if no opponent or insufficient data.
+	Percentiles   []int         // Only set with WithPercentiles.
+
+	// ExternalStats are other leagues' scores on the machine at the venue,
+	// by source. Nil if no venue filter or no imported scores.
+	ExternalStats []db.ExternalStats
 }
 
 // Option configures a Recommend query.
@@ -60,8 +68,10 @@ type Option func(*Options)
 
 // Options holds optional parameters for a Recommend query.
 type Options struct {
-	venue    string
-	opponent string
+	venue       string
+	opponent    string
+	seasons     []int
+	percentiles []int
 }
 
 // AtVenue filters recommendations to a specific venue.
@@ -78,6 +88,22 @@ func VsOpponent(key string) Option {
 	}
 }
 
+// InSeasons recommends players using only games played in the supplied
+// seasons, rather than every season.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// WithPercentiles includes each player's scores at the supplied percentiles,
+// from 1 to 100, e.g. 25, 50, 75, and 90.
+func WithPercentiles(percentiles ...int) Option {
+	return func(o *Options) {
+		o.percentiles = percentiles
+	}
+}
+
 // Analyze returns player recommendations for a team on a machine.
 func Analyze(ctx context.Context, s Store, team, machine string, opts ...Option) (*Result, error) {
 	var o Options
@@ -85,21 +111,42 @@ func Analyze(ctx context.Context, s Store, team, machine string, opts ...Option)
 		opt(&o)
 	}
 
+	r, err := analyze(ctx, s, team, machine, o)
+	if err != nil || len(o.percentiles) == 0 {
+		return r, err
+	}
+	if err := addPercentiles(ctx, s, r, o.seasons, o.percentiles); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func analyze(ctx context.Context, s Store, team, machine string, o Options) (*Result, error) {
 	leagueP50, err := s.GetLeagueP50(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("load league averages: %w", err)
 	}
 	lp50 := leagueP50[machine]
 
-	if o.opponent != "" {
-		return recommendVsOpponent(ctx, s, team, machine, o.venue, o.opponent, lp50)
-	}
+	if o.venue != "" || o.opponent != "" {
+		var r *Result
+		if o.opponent != "" {
+			r, err = recommendVsOpponent(ctx, s, team, machine, o.venue, o.opponent, o.seasons, lp50)
+		} else {
+			r, err = recommendAtVenue(ctx, s, team, machine, o.venue, o.seasons, lp50)
+		}
+		if err != nil || o.venue == "" {
+			return r, err
+		}
 
-	if o.venue != "" {
-		return recommendAtVenue(ctx, s, team, machine, o.venue, lp50)
+		r.ExternalStats, err = s.GetExternalMachineStats(ctx, o.venue, machine)
+		if err != nil {
+			return nil, fmt.Errorf("load other leagues' stats: %w", err)
+		}
+		return r, nil
 	}
 
-	stats, err := s.GetPlayerMachineStats(ctx, team, machine, "")
+	stats, err := s.GetPlayerMachineStats(ctx, team, machine, "", o.seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load player stats: %w", err)
 	}
@@ -111,13 +158,13 @@ func Analyze(ctx context.Context, s Store, team, machine string, opts ...Option)
 	}, nil
 }
 
-func recommendAtVenue(ctx context.Context, s Store, team, machine, venue string, lp50 float64) (*Result, error) {
-	venueStats, err := s.GetPlayerMachineStats(ctx, team, machine, venue)
+func recommendAtVenue(ctx context.Context, s Store, team, machine, venue string, seasons []int, lp50 float64) (*Result, error) {
+	venueStats, err := s.GetPlayerMachineStats(ctx, team, machine, venue, seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load player stats at venue: %w", err)
 	}
 
-	globalStats, err := s.GetPlayerMachineStats(ctx, team, machine, "")
+	globalStats, err := s.GetPlayerMachineStats(ctx, team, machine, "", seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load player global stats: %w", err)
 	}
@@ -141,13 +188,13 @@ func recommendAtVenue(ctx context.Context, s Store, team, machine, venue string,
 	}, nil
 }
 
-func recommendVsOpponent(ctx context.Context, s Store, team, machine, venue, opponent string, lp50 float64) (*Result, error) {
-	ourStats, err := s.GetPlayerMachineStats(ctx, team, machine, venue)
+func recommendVsOpponent(ctx context.Context, s Store, team, machine, venue, opponent string, seasons []int, lp50 float64) (*Result, error) {
+	ourStats, err := s.GetPlayerMachineStats(ctx, team, machine, venue, seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load stats for %s: %w", team, err)
 	}
 
-	theirStats, err := s.GetPlayerMachineStats(ctx, opponent, machine, venue)
+	theirStats, err := s.GetPlayerMachineStats(ctx, opponent, machine, venue, seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load stats for %s: %w", opponent, err)
 	}
@@ -183,6 +230,40 @@ func recommendVsOpponent(ctx context.Context, s Store, team, machine, venue, opp
 	return r, nil
 }
 
+// addPercentiles adds each player's scores at the supplied percentiles, loaded
+// with the same team, venue, and seasons as their other stats.
+func addPercentiles(ctx context.Context, s Store, r *Result, seasons, percentiles []int) error {
+	// Global stats are only filtered to the venue when comparing to an
+	// opponent.
+	globalVenue := ""
+	if r.Opponent != "" {
+		globalVenue = r.Venue
+	}
+
+	r.Percentiles = percentiles
+	for _, l := range []struct {
+		stats []PlayerStats
+		team  string
+		venue string
+	}{
+		{r.VenueStats, r.Team, r.Venue},
+		{r.GlobalStats, r.Team, globalVenue},
+		{r.OpponentStats, r.Opponent, r.Venue},
+	} {
+		if len(l.stats) == 0 {
+			continue
+		}
+		pcts, err := s.GetPlayerMachinePercentiles(ctx, l.team, r.Machine, l.venue, seasons, percentiles)
+		if err != nil {
+			return fmt.Errorf("load percentiles for %s: %w", l.team, err)
+		}
+		for i := range l.stats {
+			l.stats[i].Percentiles = pcts[l.stats[i].Name]
+		}
+	}
+	return nil
+}
+
 func enrichStats(stats []db.PlayerStats, lp50 float64) []PlayerStats {
 	result := make([]PlayerStats, len(stats))
 	for i, s := range stats {
@@ -12,16 +12,26 @@ import (
 )
 
 type MockStore struct {
-	MockGetLeagueP50          func(ctx context.Context) (map[string]float64, error)
-	MockGetPlayerMachineStats func(ctx context.Context, teamKey, machineKey, venueKey string) ([]db.PlayerStats, error)
+	MockGetLeagueP50                func(ctx context.Context) (map[string]float64, error)
+	MockGetPlayerMachineStats       func(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]db.PlayerStats, error)
+	MockGetExternalMachineStats     func(ctx context.Context, venueKey, machineKey string) ([]db.ExternalStats, error)
+	MockGetPlayerMachinePercentiles func(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error)
 }
 
 func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
 	return m.MockGetLeagueP50(ctx)
 }
 
-func (m *MockStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string) ([]db.PlayerStats, error) {
-	return m.MockGetPlayerMachineStats(ctx, teamKey, machineKey, venueKey)
+func (m *MockStore) GetPlayerMachineStats(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int) ([]db.PlayerStats, error) {
+	return m.MockGetPlayerMachineStats(ctx, teamKey, machineKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetExternalMachineStats(ctx context.Context, venueKey, machineKey string) ([]db.ExternalStats, error) {
+	return m.MockGetExternalMachineStats(ctx, venueKey, machineKey)
+}
+
+func (m *MockStore) GetPlayerMachinePercentiles(ctx context.Context, teamKey, machineKey, venueKey string, seasons []int, percentiles []int) (map[string][]float64, error) {
+	return m.MockGetPlayerMachinePercentiles(ctx, teamKey, machineKey, venueKey, seasons, percentiles)
 }
 
 func TestAnalyze(t *testing.T) {
@@ -49,7 +59,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, _, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, _, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						return []db.PlayerStats{
 							{Name: "Alice", Games: 10, P50Score: 50_000_000, P90Score: 70_000_000},
 							{Name: "Bob", Games: 5, P50Score: 30_000_000, P90Score: 40_000_000},
@@ -71,13 +81,13 @@ func TestAnalyze(t *testing.T) {
 			},
 		},
 		"AtVenue": {
-			reason: "With a venue option, venue stats should be populated and global stats should flag players missing venue-specific data.",
+			reason: "With a venue option, venue and external stats should be populated and global stats should flag players missing venue-specific data.",
 			args: args{
 				store: &MockStore{
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, _, _, venueKey string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, _, _, venueKey string, _ []int) ([]db.PlayerStats, error) {
 						if venueKey != "" {
 							return []db.PlayerStats{
 								{Name: "Alice", Games: 3, P50Score: 45_000_000, P90Score: 55_000_000},
@@ -88,6 +98,9 @@ func TestAnalyze(t *testing.T) {
 							{Name: "Bob", Games: 5, P50Score: 30_000_000, P90Score: 40_000_000},
 						}, nil
 					},
+					MockGetExternalMachineStats: func(_ context.Context, _, _ string) ([]db.ExternalStats, error) {
+						return []db.ExternalStats{{Source: "Tuesday league", Games: 12, P50Score: 25_000_000}}, nil
+					},
 				},
 				team:    "CRA",
 				machine: "TAF",
@@ -105,6 +118,7 @@ func TestAnalyze(t *testing.T) {
 						{Name: "Alice", Games: 10, P50Score: 50_000_000, P90Score: 70_000_000, LeagueP50: 30_000_000},
 						{Name: "Bob", Games: 5, P50Score: 30_000_000, P90Score: 40_000_000, LeagueP50: 30_000_000, NoVenueData: true},
 					},
+					ExternalStats: []db.ExternalStats{{Source: "Tuesday league", Games: 12, P50Score: 25_000_000}},
 				},
 			},
 		},
@@ -115,7 +129,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						stats := map[string][]db.PlayerStats{
 							"CRA": {{Name: "Alice", Games: 10, P50Score: 50_000_000}},
 							"PYC": {{Name: "Carol", Games: 8, P50Score: 40_000_000}},
@@ -150,7 +164,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						stats := map[string][]db.PlayerStats{
 							"CRA": {{Name: "Alice", Games: 10, P50Score: 30_000_000}},
 							"PYC": {{Name: "Carol", Games: 8, P50Score: 50_000_000}},
@@ -185,7 +199,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						stats := map[string][]db.PlayerStats{
 							"CRA": {{Name: "Alice", Games: 10, P50Score: 30_500_000}},
 							"PYC": {{Name: "Carol", Games: 8, P50Score: 30_000_000}},
@@ -220,7 +234,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, teamKey, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						stats := map[string][]db.PlayerStats{
 							"CRA": {{Name: "Alice", Games: 10, P50Score: 50_000_000}},
 						}
@@ -256,6 +270,68 @@ func TestAnalyze(t *testing.T) {
 				err: cmpopts.AnyError,
 			},
 		},
+		"WithPercentiles": {
+			reason: "With percentiles, each player's scores at them should be loaded with the same venue as their other stats.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetPlayerMachineStats: func(_ context.Context, _, _, venueKey string, _ []int) ([]db.PlayerStats, error) {
+						if venueKey != "" {
+							return []db.PlayerStats{{Name: "Alice", Games: 3, P50Score: 45_000_000}}, nil
+						}
+						return []db.PlayerStats{{Name: "Alice", Games: 10, P50Score: 50_000_000}}, nil
+					},
+					MockGetPlayerMachinePercentiles: func(_ context.Context, _, _, venueKey string, _ []int, _ []int) (map[string][]float64, error) {
+						if venueKey != "" {
+							return map[string][]float64{"Alice": {40_000_000, 60_000_000}}, nil
+						}
+						return map[string][]float64{"Alice": {35_000_000, 75_000_000}}, nil
+					},
+					MockGetExternalMachineStats: func(_ context.Context, _, _ string) ([]db.ExternalStats, error) {
+						return nil, nil
+					},
+				},
+				team:    "CRA",
+				machine: "TAF",
+				opts:    []Option{AtVenue("SAM"), WithPercentiles(25, 75)},
+			},
+			want: want{
+				result: &Result{
+					Team:    "CRA",
+					Machine: "TAF",
+					Venue:   "SAM",
+					VenueStats: []PlayerStats{
+						{Name: "Alice", Games: 3, P50Score: 45_000_000, LeagueP50: 30_000_000, Percentiles: []float64{40_000_000, 60_000_000}},
+					},
+					GlobalStats: []PlayerStats{
+						{Name: "Alice", Games: 10, P50Score: 50_000_000, LeagueP50: 30_000_000, Percentiles: []float64{35_000_000, 75_000_000}},
+					},
+					Percentiles: []int{25, 75},
+				},
+			},
+		},
+		"GetPlayerMachinePercentilesError": {
+			reason: "Errors loading percentiles should be returned.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetPlayerMachineStats: func(_ context.Context, _, _, _ string, _ []int) ([]db.PlayerStats, error) {
+						return []db.PlayerStats{{Name: "Alice", Games: 10, P50Score: 50_000_000}}, nil
+					},
+					MockGetPlayerMachinePercentiles: func(_ context.Context, _, _, _ string, _ []int, _ []int) (map[string][]float64, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team:    "CRA",
+				machine: "TAF",
+				opts:    []Option{WithPercentiles(25)},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
 		"GetPlayerMachineStatsError": {
 			reason: "An error loading player stats should be returned.",
 			args: args{
@@ -263,7 +339,7 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
-					MockGetPlayerMachineStats: func(_ context.Context, _, _, _ string) ([]db.PlayerStats, error) {
+					MockGetPlayerMachineStats: func(_ context.Context, _, _, _ string, _ []int) ([]db.PlayerStats, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -0,0 +1,172 @@
+// Package recruit finds the machines, and eras of machine, a team most needs
+// stronger players on, given the venues on its schedule.
+package recruit
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/era"
+	"github.com/negz/mnp/internal/output"
+)
+
+// minGames is the number of games a team needs on a machine before its P50 is
+// trusted. Machines the team has played less are treated as unplayed.
+const minGames = 3
+
+// Store is the set of queries needed for a recruiting report.
+type Store interface {
+	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetMachineYears(ctx context.Context) (map[string]int, error)
+	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+// Machine is a machine the team is weak on, and how often it'll see it.
+type Machine struct {
+	MachineKey  string
+	MachineName string
+	Era         era.Era // Unknown if the machine's year isn't known.
+	Visits      int     // The team's scheduled matches at venues with this machine.
+	Games       int
+	P50Score    float64
+	LeagueP50   float64
+}
+
+// Played returns true if the team has played the machine enough for its P50
+// to mean something.
+func (m Machine) Played() bool {
+	return m.Games >= minGames
+}
+
+// Need scores how much the team would gain from a stronger player on the
+// machine: how far below the league P50 the team is, weighted by how often it
+// visits a venue with the machine. Unplayed machines count as 100% below.
+func (m Machine) Need() float64 {
+	gap := 100.0
+	if m.Played() {
+		gap = -output.RelStr(m.P50Score, m.LeagueP50)
+	}
+	return gap * float64(m.Visits)
+}
+
+// EraNeed is how much the team would gain from a stronger player on machines
+// from an era.
+type EraNeed struct {
+	Era      era.Era
+	Machines int     // Machines from the era the team is weak on.
+	Need     float64 // The sum of the machines' needs.
+}
+
+// Result is the output of a recruiting report.
+type Result struct {
+	Team     string
+	Matches  int       // The team's scheduled matches this season at known venues.
+	Machines []Machine // Greatest need first.
+	Eras     []EraNeed // Greatest need first. Omits machines without a known year.
+}
+
+// Analyze returns the machines at venues on a team's schedule this season that
+// the team plays below the league average, or has barely played, ordered by
+// need.
+func Analyze(ctx context.Context, s Store, team string) (*Result, error) {
+	sched, err := s.ListSchedule(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("load schedule: %w", err)
+	}
+
+	r := &Result{Team: team}
+	visits := make(map[string]int)
+	for _, m := range sched {
+		if m.VenueKey == "" || (m.HomeTeamKey != team && m.AwayTeamKey != team) {
+			continue
+		}
+		visits[m.VenueKey]++
+		r.Matches++
+	}
+
+	machineVisits := make(map[string]int)
+	for venue, n := range visits {
+		machines, err := s.GetVenueMachines(ctx, venue, 0)
+		if err != nil {
+			return nil, fmt.Errorf("load venue %s machines: %w", venue, err)
+		}
+		for key := range machines {
+			machineVisits[key] += n
+		}
+	}
+
+	leagueP50, err := s.GetLeagueP50(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league averages: %w", err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	years, err := s.GetMachineYears(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine years: %w", err)
+	}
+
+	stats, err := s.GetTeamMachineStats(ctx, team, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("load team stats: %w", err)
+	}
+	byMachine := make(map[string]db.TeamMachineStats, len(stats))
+	for _, ts := range stats {
+		byMachine[ts.MachineKey] = ts
+	}
+
+	for key, n := range machineVisits {
+		ts := byMachine[key]
+		m := Machine{
+			MachineKey:  key,
+			MachineName: output.MachineName(names, key),
+			Era:         era.Of(years[key]),
+			Visits:      n,
+			Games:       ts.Games,
+			P50Score:    ts.P50Score,
+			LeagueP50:   leagueP50[key],
+		}
+		if m.Need() <= 0 {
+			continue
+		}
+		r.Machines = append(r.Machines, m)
+	}
+
+	slices.SortFunc(r.Machines, func(a, b Machine) int {
+		if c := cmp.Compare(b.Need(), a.Need()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+
+	eras := make(map[era.Era]*EraNeed)
+	for _, m := range r.Machines {
+		if m.Era == era.Unknown {
+			continue
+		}
+		if eras[m.Era] == nil {
+			eras[m.Era] = &EraNeed{Era: m.Era}
+		}
+		eras[m.Era].Machines++
+		eras[m.Era].Need += m.Need()
+	}
+	for _, e := range era.All() {
+		if n, ok := eras[e]; ok {
+			r.Eras = append(r.Eras, *n)
+		}
+	}
+	slices.SortStableFunc(r.Eras, func(a, b EraNeed) int {
+		return cmp.Compare(b.Need, a.Need)
+	})
+	return r, nil
+}
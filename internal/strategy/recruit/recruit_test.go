@@ -0,0 +1,161 @@
+package recruit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/era"
+)
+
+type MockStore struct {
+	MockGetLeagueP50        func(ctx context.Context) (map[string]float64, error)
+	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
+	MockGetMachineYears     func(ctx context.Context) (map[string]int, error)
+	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines    func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListSchedule        func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
+	return m.MockGetLeagueP50(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetMachineYears(ctx context.Context) (map[string]int, error) {
+	return m.MockGetMachineYears(ctx)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func TestAnalyze(t *testing.T) {
+	store := &MockStore{
+		MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+			return []db.ScheduleMatch{
+				{Week: 1, HomeTeamKey: "CRA", AwayTeamKey: "PYC", VenueKey: "8BT"},
+				{Week: 2, HomeTeamKey: "KNR", AwayTeamKey: "CRA", VenueKey: "GPA"},
+				{Week: 3, HomeTeamKey: "CRA", AwayTeamKey: "TTT", VenueKey: "8BT"},
+				{Week: 3, HomeTeamKey: "KNR", AwayTeamKey: "PYC", VenueKey: "GPA"},
+				{Week: 4, HomeTeamKey: "CRA", AwayTeamKey: "KNR"},
+			}, nil
+		},
+		MockGetVenueMachines: func(_ context.Context, venue string, _ int) (map[string]bool, error) {
+			return map[string]map[string]bool{
+				"8BT": {"TAF": true, "MM": true},
+				"GPA": {"TAF": true, "TZ": true, "AFM": true},
+			}[venue], nil
+		},
+		MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+			return map[string]float64{"TAF": 40_000_000, "MM": 20_000_000, "TZ": 100_000_000, "AFM": 10_000_000}, nil
+		},
+		MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+			return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone", "AFM": "Attack From Mars"}, nil
+		},
+		MockGetMachineYears: func(_ context.Context) (map[string]int, error) {
+			// AFM's year isn't known.
+			return map[string]int{"TAF": 1992, "MM": 1997, "TZ": 1993}, nil
+		},
+		MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+			return []db.TeamMachineStats{
+				{MachineKey: "TAF", Games: 20, P50Score: 30_000_000},
+				{MachineKey: "MM", Games: 10, P50Score: 30_000_000},
+				{MachineKey: "AFM", Games: 2, P50Score: 20_000_000},
+			}, nil
+		},
+	}
+
+	// A store where the team's weak machines span eras.
+	eras := *store
+	eras.MockGetMachineYears = func(_ context.Context) (map[string]int, error) {
+		return map[string]int{"TAF": 1992, "TZ": 1981, "AFM": 2017}, nil
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  Store
+		want   want
+	}{
+		"RankedByNeed": {
+			reason: "Machines the team is above average on should be left out, and the rest ranked by how far below average the team is times how often it visits them, then totalled by era.",
+			store:  store,
+			want: want{result: &Result{
+				Team:    "CRA",
+				Matches: 3,
+				Machines: []Machine{
+					// Barely played, at 1 scheduled match.
+					{MachineKey: "AFM", MachineName: "Attack From Mars", Visits: 1, Games: 2, P50Score: 20_000_000, LeagueP50: 10_000_000},
+					// Never played, at 1 scheduled match.
+					{MachineKey: "TZ", MachineName: "Twilight Zone", Era: era.DMD, Visits: 1, LeagueP50: 100_000_000},
+					// 25% below average, at all 3 scheduled matches.
+					{MachineKey: "TAF", MachineName: "The Addams Family", Era: era.DMD, Visits: 3, Games: 20, P50Score: 30_000_000, LeagueP50: 40_000_000},
+				},
+				Eras: []EraNeed{
+					// TZ's need of 100 plus TAF's of 75. AFM's era isn't known.
+					{Era: era.DMD, Machines: 2, Need: 175},
+				},
+			}},
+		},
+		"ErasRankedByNeed": {
+			reason: "Eras should be ranked by the total need of the team's weak machines from them, oldest first when tied.",
+			store:  &eras,
+			want: want{result: &Result{
+				Team:    "CRA",
+				Matches: 3,
+				Machines: []Machine{
+					{MachineKey: "AFM", MachineName: "Attack From Mars", Era: era.LCD, Visits: 1, Games: 2, P50Score: 20_000_000, LeagueP50: 10_000_000},
+					{MachineKey: "TZ", MachineName: "Twilight Zone", Era: era.SS, Visits: 1, LeagueP50: 100_000_000},
+					{MachineKey: "TAF", MachineName: "The Addams Family", Era: era.DMD, Visits: 3, Games: 20, P50Score: 30_000_000, LeagueP50: 40_000_000},
+				},
+				Eras: []EraNeed{
+					{Era: era.SS, Machines: 1, Need: 100},
+					{Era: era.LCD, Machines: 1, Need: 100},
+					{Era: era.DMD, Machines: 1, Need: 75},
+				},
+			}},
+		},
+		"ScheduleError": {
+			reason: "An error loading the schedule should be returned.",
+			store: &MockStore{
+				MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+					return nil, errors.New("boom")
+				},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.store, "CRA")
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/negz/mnp/internal/output"
+)
+
+// Formats a report can be written in.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Markdown formats a report as Markdown. The team the report is for, if any,
+// is bold in the standings.
+func Markdown(r *Result) string {
+	if r.Week == 0 {
+		return "No matches have been played yet.\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n## Results\n\n", title(r))
+	for _, m := range r.Matches {
+		fmt.Fprintf(&b, "- %s\n", result(m))
+	}
+
+	if len(r.Scores) > 0 {
+		b.WriteString("\n## Standout scores\n\n")
+		for i, s := range r.Scores {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, score(s))
+		}
+	}
+
+	if len(r.Upsets) > 0 {
+		b.WriteString("\n## Upsets\n\n")
+		for _, u := range r.Upsets {
+			fmt.Fprintf(&b, "- %s\n", upsetLine(u))
+		}
+	}
+
+	if len(r.Standings) > 0 {
+		b.WriteString("\n## Standings\n\n| # | Team | W-L-T | Points |\n|---|------|-------|--------|\n")
+		for i, st := range r.Standings {
+			team := st.TeamName
+			if st.TeamKey == r.Team {
+				team = "**" + team + "**"
+			}
+			fmt.Fprintf(&b, "| %d | %s | %d-%d-%d | %s |\n", i+1, team, st.Wins, st.Losses, st.Ties, output.FormatPoints(st.Points))
+		}
+	}
+	return b.String()
+}
+
+// HTML formats a report as an HTML fragment. The team the report is for, if
+// any, is bold in the standings.
+func HTML(r *Result) string {
+	if r.Week == 0 {
+		return "<p>No matches have been played yet.</p>\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<h2>Results</h2>\n<ul>\n", html.EscapeString(title(r)))
+	for _, m := range r.Matches {
+		fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(result(m)))
+	}
+	b.WriteString("</ul>\n")
+
+	if len(r.Scores) > 0 {
+		b.WriteString("<h2>Standout scores</h2>\n<ol>\n")
+		for _, s := range r.Scores {
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(score(s)))
+		}
+		b.WriteString("</ol>\n")
+	}
+
+	if len(r.Upsets) > 0 {
+		b.WriteString("<h2>Upsets</h2>\n<ul>\n")
+		for _, u := range r.Upsets {
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(upsetLine(u)))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	if len(r.Standings) > 0 {
+		b.WriteString("<h2>Standings</h2>\n<table>\n<tr><th>#</th><th>Team</th><th>W-L-T</th><th>Points</th></tr>\n")
+		for i, st := range r.Standings {
+			team := html.EscapeString(st.TeamName)
+			if st.TeamKey == r.Team {
+				team = "<strong>" + team + "</strong>"
+			}
+			fmt.Fprintf(&b, "<tr><td>%d</td><td>%s</td><td>%d-%d-%d</td><td>%s</td></tr>\n", i+1, team, st.Wins, st.Losses, st.Ties, output.FormatPoints(st.Points))
+		}
+		b.WriteString("</table>\n")
+	}
+	return b.String()
+}
+
+func title(r *Result) string {
+	if r.Team != "" {
+		return fmt.Sprintf("Week %d recap: %s", r.Week, r.Team)
+	}
+	return fmt.Sprintf("Week %d recap", r.Week)
+}
+
+// result describes a match's result, e.g. "TTT 12 @ KNR 8 at Georgetown Pizza
+// and Arcade".
+func result(m Match) string {
+	s := fmt.Sprintf("%s @ %s", m.AwayTeamKey, m.HomeTeamKey)
+	if m.Played {
+		s = fmt.Sprintf("%s %s @ %s %s", m.AwayTeamKey, output.FormatPoints(m.AwayPoints), m.HomeTeamKey, output.FormatPoints(m.HomePoints))
+	}
+	if m.Venue != "" {
+		s += " at " + m.Venue
+	}
+	switch {
+	case !m.Played:
+		s += " (not played yet)"
+	case m.Upset():
+		s += fmt.Sprintf(" (upset: %s were favored)", m.Favorite)
+	}
+	return s
+}
+
+// score describes a standout score, e.g. "Alice (TTT): 1.2B on The Addams
+// Family, 3.1x the league P50".
+func score(s Score) string {
+	return fmt.Sprintf("%s (%s): %s on %s, %.1fx the league P50",
+		s.PlayerName, s.TeamKey, output.FormatScore(float64(s.Score)), s.MachineName, s.Ratio())
+}
+
+// upsetLine describes an upset, e.g. "KNR won Twilight Zone despite TTT's
+// predicted +25% edge".
+func upsetLine(u Upset) string {
+	// Edges are effectively infinite when the winner's likely players had
+	// no scores on the machine.
+	if u.Edge > 1e15 {
+		return fmt.Sprintf("%s won %s against %s with no scores on it to predict from", u.Winner, u.MachineName, u.Favorite)
+	}
+	return fmt.Sprintf("%s won %s despite %s's predicted +%.0f%% edge", u.Winner, u.MachineName, u.Favorite, u.Edge)
+}
@@ -0,0 +1,244 @@
+// Package report recaps a week of league results, formatted to paste into a
+// team chat.
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/recap"
+)
+
+const (
+	// maxScores is the most standout scores a report lists.
+	maxScores = 5
+
+	// maxUpsets is the most upsets a report lists.
+	maxUpsets = 5
+)
+
+// Store is the set of queries needed for a week's report.
+type Store interface {
+	GetLatestPlayedWeek(ctx context.Context) (int, error)
+	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetStandingsThroughWeek(ctx context.Context, season, week int) ([]db.Standing, error)
+	ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error)
+	ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error)
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+// A Match is one match of the week and its final score.
+type Match struct {
+	db.ScheduleMatch
+
+	Played     bool // False if the match has no results yet.
+	HomePoints float64
+	AwayPoints float64
+
+	// Favorite is the key of the team predicted to have the edge on more of
+	// the machines that were played, or empty if neither was or no
+	// predictions were recorded.
+	Favorite string
+}
+
+// Winner returns the key of the team that earned more points, or an empty
+// string if the match was tied or hasn't been played.
+func (m Match) Winner() string {
+	switch {
+	case !m.Played:
+		return ""
+	case m.HomePoints > m.AwayPoints:
+		return m.HomeTeamKey
+	case m.AwayPoints > m.HomePoints:
+		return m.AwayTeamKey
+	default:
+		return ""
+	}
+}
+
+// Upset returns true if the match's favorite lost.
+func (m Match) Upset() bool {
+	w := m.Winner()
+	return m.Favorite != "" && w != "" && w != m.Favorite
+}
+
+// A Score is a standout individual score.
+type Score struct {
+	PlayerName  string
+	TeamKey     string
+	MachineKey  string
+	MachineName string
+	Score       int64
+	LeagueP50   float64
+}
+
+// Ratio returns how many times the league's P50 on the machine the score is.
+func (s Score) Ratio() float64 {
+	return float64(s.Score) / s.LeagueP50
+}
+
+// An Upset is a machine where the team predicted to have the edge won fewer
+// points than its opponent.
+type Upset struct {
+	recap.Prediction
+
+	MatchKey string
+}
+
+// Result is a week's report.
+type Result struct {
+	Week      int    // Zero if no matches have been played.
+	Team      string // Empty if the report covers every team.
+	Matches   []Match
+	Scores    []Score // The week's highest scores relative to each machine's league P50, highest first.
+	Upsets    []Upset // Machines won against the biggest predicted edges, biggest first.
+	Standings []db.Standing
+}
+
+// Option configures a week's report.
+type Option func(*Options)
+
+// Options holds optional parameters for a week's report.
+type Options struct {
+	week int
+	team string
+}
+
+// InWeek reports on the supplied week of the current season, rather than the
+// latest week with results.
+func InWeek(n int) Option {
+	return func(o *Options) {
+		o.week = n
+	}
+}
+
+// ForTeam reports only on the supplied team's match. Standings still include
+// every team.
+func ForTeam(team string) Option {
+	return func(o *Options) {
+		o.team = team
+	}
+}
+
+// Analyze reports on a week of the current season: each match's score, the
+// standout individual scores, the machines won against the predicted edge, and
+// the standings as they stood after the week. Predictions are the ones the web
+// UI records before each week's matches, so there are no upsets if it wasn't
+// running.
+func Analyze(ctx context.Context, s Store, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r := &Result{Week: o.week, Team: o.team}
+	if r.Week == 0 {
+		w, err := s.GetLatestPlayedWeek(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("find latest played week: %w", err)
+		}
+		if w == 0 {
+			return r, nil
+		}
+		r.Week = w
+	}
+
+	sched, err := s.ListSchedule(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("load schedule: %w", err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+	p50s, err := s.GetLeagueP50(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league P50s: %w", err)
+	}
+	outcomes, err := s.ListPredictionOutcomes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load prediction outcomes: %w", err)
+	}
+	predicted := make(map[string][]db.PredictionOutcome)
+	for _, po := range outcomes {
+		predicted[po.MatchKey] = append(predicted[po.MatchKey], po)
+	}
+
+	for _, sm := range sched {
+		if sm.Week != r.Week {
+			continue
+		}
+		if o.team != "" && sm.HomeTeamKey != o.team && sm.AwayTeamKey != o.team {
+			continue
+		}
+
+		results, err := s.ListMatchResults(ctx, sm.Key)
+		if err != nil {
+			return nil, fmt.Errorf("load %s results: %w", sm.Key, err)
+		}
+
+		m := Match{ScheduleMatch: sm, Played: len(results) > 0}
+		for _, mr := range results {
+			switch mr.TeamKey {
+			case sm.HomeTeamKey:
+				m.HomePoints += mr.Points
+			case sm.AwayTeamKey:
+				m.AwayPoints += mr.Points
+			}
+			if p50 := p50s[mr.MachineKey]; mr.Score > 0 && p50 > 0 {
+				r.Scores = append(r.Scores, Score{
+					PlayerName:  mr.PlayerName,
+					TeamKey:     mr.TeamKey,
+					MachineKey:  mr.MachineKey,
+					MachineName: output.MachineName(names, mr.MachineKey),
+					Score:       mr.Score,
+					LeagueP50:   p50,
+				})
+			}
+		}
+
+		home, away := 0, 0
+		for _, po := range predicted[sm.Key] {
+			switch {
+			case po.Edge > 0:
+				home++
+			case po.Edge < 0:
+				away++
+			}
+			if p, ok := recap.Grade(po); ok && p.Wrong() {
+				p.MachineName = output.MachineName(names, po.MachineKey)
+				r.Upsets = append(r.Upsets, Upset{Prediction: p, MatchKey: po.MatchKey})
+			}
+		}
+		switch {
+		case home > away:
+			m.Favorite = sm.HomeTeamKey
+		case away > home:
+			m.Favorite = sm.AwayTeamKey
+		}
+
+		r.Matches = append(r.Matches, m)
+	}
+
+	slices.SortStableFunc(r.Scores, func(a, b Score) int {
+		return cmp.Compare(b.Ratio(), a.Ratio())
+	})
+	r.Scores = r.Scores[:min(len(r.Scores), maxScores)]
+
+	slices.SortStableFunc(r.Upsets, func(a, b Upset) int {
+		return cmp.Compare(b.Edge, a.Edge)
+	})
+	r.Upsets = r.Upsets[:min(len(r.Upsets), maxUpsets)]
+
+	r.Standings, err = s.GetStandingsThroughWeek(ctx, 0, r.Week)
+	if err != nil {
+		return nil, fmt.Errorf("load standings: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/recap"
+)
+
+type MockStore struct {
+	MockGetLatestPlayedWeek     func(ctx context.Context) (int, error)
+	MockGetLeagueP50            func(ctx context.Context) (map[string]float64, error)
+	MockGetMachineNames         func(ctx context.Context) (map[string]string, error)
+	MockGetStandingsThroughWeek func(ctx context.Context, season, week int) ([]db.Standing, error)
+	MockListMatchResults        func(ctx context.Context, matchKey string) ([]db.MatchResult, error)
+	MockListPredictionOutcomes  func(ctx context.Context) ([]db.PredictionOutcome, error)
+	MockListSchedule            func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+func (m *MockStore) GetLatestPlayedWeek(ctx context.Context) (int, error) {
+	return m.MockGetLatestPlayedWeek(ctx)
+}
+
+func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
+	return m.MockGetLeagueP50(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetStandingsThroughWeek(ctx context.Context, season, week int) ([]db.Standing, error) {
+	return m.MockGetStandingsThroughWeek(ctx, season, week)
+}
+
+func (m *MockStore) ListMatchResults(ctx context.Context, matchKey string) ([]db.MatchResult, error) {
+	return m.MockListMatchResults(ctx, matchKey)
+}
+
+func (m *MockStore) ListPredictionOutcomes(ctx context.Context) ([]db.PredictionOutcome, error) {
+	return m.MockListPredictionOutcomes(ctx)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func TestAnalyze(t *testing.T) {
+	tttKNR := db.ScheduleMatch{Key: "mnp-23-2-KNR-TTT", Week: 2, HomeTeamKey: "KNR", AwayTeamKey: "TTT", VenueKey: "GPA", Venue: "Georgetown Pizza and Arcade"}
+	craPYC := db.ScheduleMatch{Key: "mnp-23-2-PYC-CRA", Week: 2, HomeTeamKey: "PYC", AwayTeamKey: "CRA"}
+	week1 := db.ScheduleMatch{Key: "mnp-23-1-TTT-KNR", Week: 1, HomeTeamKey: "TTT", AwayTeamKey: "KNR"}
+
+	results := map[string][]db.MatchResult{
+		tttKNR.Key: {
+			{MachineKey: "TZ", PlayerName: "Alice", TeamKey: "TTT", Score: 300, Points: 3},
+			{MachineKey: "TZ", PlayerName: "Carol", TeamKey: "KNR", Score: 100},
+			{MachineKey: "MM", PlayerName: "Bob", TeamKey: "TTT", Score: 50},
+			{MachineKey: "MM", PlayerName: "Dave", TeamKey: "KNR", Score: 400, Points: 3},
+			{MachineKey: "AFM", PlayerName: "Alice", TeamKey: "TTT", Score: 1000, Points: 3},
+			{MachineKey: "AFM", PlayerName: "Dave", TeamKey: "KNR", Score: 0},
+		},
+	}
+	standings := []db.Standing{
+		{TeamKey: "TTT", TeamName: "The Trailer Trashers", Played: 2, Wins: 2, Points: 15},
+		{TeamKey: "KNR", TeamName: "Knight Riders", Played: 2, Losses: 2, Points: 6},
+	}
+
+	store := func(latest int) *MockStore {
+		return &MockStore{
+			MockGetLatestPlayedWeek: func(_ context.Context) (int, error) {
+				return latest, nil
+			},
+			MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+				// AFM has no league P50, so its scores can't be compared.
+				return map[string]float64{"TZ": 100, "MM": 200}, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TZ": "Twilight Zone", "MM": "Medieval Madness"}, nil
+			},
+			MockGetStandingsThroughWeek: func(_ context.Context, _, week int) ([]db.Standing, error) {
+				if week != 2 {
+					return nil, errors.New("wrong week")
+				}
+				return standings, nil
+			},
+			MockListMatchResults: func(_ context.Context, matchKey string) ([]db.MatchResult, error) {
+				return results[matchKey], nil
+			},
+			MockListPredictionOutcomes: func(_ context.Context) ([]db.PredictionOutcome, error) {
+				return []db.PredictionOutcome{
+					// KNR were favored on TZ and MM, but only won MM.
+					{Prediction: db.Prediction{MatchKey: tttKNR.Key, MachineKey: "TZ", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: 30, Confidence: 2}, AwayPoints: 3},
+					{Prediction: db.Prediction{MatchKey: tttKNR.Key, MachineKey: "MM", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: 10}, HomePoints: 3},
+					{Prediction: db.Prediction{MatchKey: tttKNR.Key, MachineKey: "AFM", HomeTeamKey: "KNR", AwayTeamKey: "TTT", Edge: -5}, AwayPoints: 3},
+					// Other weeks' predictions don't matter.
+					{Prediction: db.Prediction{MatchKey: week1.Key, MachineKey: "TZ", HomeTeamKey: "TTT", AwayTeamKey: "KNR", Edge: 50}, AwayPoints: 3},
+				}, nil
+			},
+			MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+				return []db.ScheduleMatch{week1, tttKNR, craPYC}, nil
+			},
+		}
+	}
+
+	played := Match{ScheduleMatch: tttKNR, Played: true, HomePoints: 3, AwayPoints: 6, Favorite: "KNR"}
+	scores := []Score{
+		{PlayerName: "Alice", TeamKey: "TTT", MachineKey: "TZ", MachineName: "Twilight Zone", Score: 300, LeagueP50: 100},
+		{PlayerName: "Dave", TeamKey: "KNR", MachineKey: "MM", MachineName: "Medieval Madness", Score: 400, LeagueP50: 200},
+		{PlayerName: "Carol", TeamKey: "KNR", MachineKey: "TZ", MachineName: "Twilight Zone", Score: 100, LeagueP50: 100},
+		{PlayerName: "Bob", TeamKey: "TTT", MachineKey: "MM", MachineName: "Medieval Madness", Score: 50, LeagueP50: 200},
+	}
+	upsets := []Upset{{MatchKey: tttKNR.Key, Prediction: recap.Prediction{MachineKey: "TZ", MachineName: "Twilight Zone", Favorite: "KNR", Winner: "TTT", Edge: 30, Confidence: 2}}}
+
+	type args struct {
+		latest int
+		opts   []Option
+	}
+	type want struct {
+		r   *Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"LatestWeek": {
+			reason: "The report should cover the latest played week, including matches that haven't been played yet.",
+			args:   args{latest: 2},
+			want: want{r: &Result{
+				Week:      2,
+				Matches:   []Match{played, {ScheduleMatch: craPYC}},
+				Scores:    scores,
+				Upsets:    upsets,
+				Standings: standings,
+			}},
+		},
+		"Team": {
+			reason: "A team's report should cover only its match, but every team's standings.",
+			args:   args{latest: 2, opts: []Option{ForTeam("TTT")}},
+			want: want{r: &Result{
+				Week:      2,
+				Team:      "TTT",
+				Matches:   []Match{played},
+				Scores:    scores,
+				Upsets:    upsets,
+				Standings: standings,
+			}},
+		},
+		"InWeek": {
+			reason: "A report for a week should load standings as they stood after that week.",
+			args:   args{latest: 3, opts: []Option{InWeek(2), ForTeam("CRA")}},
+			want: want{r: &Result{
+				Week:      2,
+				Team:      "CRA",
+				Matches:   []Match{{ScheduleMatch: craPYC}},
+				Standings: standings,
+			}},
+		},
+		"NothingPlayed": {
+			reason: "A report before any matches have been played should be empty.",
+			args:   args{latest: 0},
+			want:   want{r: &Result{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), store(tc.args.latest), tc.args.opts...)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := &Result{
+		Week: 2,
+		Team: "TTT",
+		Matches: []Match{
+			{ScheduleMatch: db.ScheduleMatch{HomeTeamKey: "KNR", AwayTeamKey: "TTT", Venue: "Georgetown Pizza and Arcade"}, Played: true, HomePoints: 3, AwayPoints: 6, Favorite: "KNR"},
+			{ScheduleMatch: db.ScheduleMatch{HomeTeamKey: "PYC", AwayTeamKey: "CRA"}},
+		},
+		Scores: []Score{{PlayerName: "Alice", TeamKey: "TTT", MachineName: "Twilight Zone", Score: 300_000_000, LeagueP50: 100_000_000}},
+		Upsets: []Upset{
+			{Prediction: recap.Prediction{MachineName: "Twilight Zone", Favorite: "KNR", Winner: "TTT", Edge: 30}},
+			{Prediction: recap.Prediction{MachineName: "Attack from Mars", Favorite: "KNR", Winner: "TTT", Edge: 1e18}},
+		},
+		Standings: []db.Standing{
+			{TeamKey: "TTT", TeamName: "The Trailer Trashers", Wins: 2, Points: 15},
+			{TeamKey: "KNR", TeamName: "Knight Riders", Losses: 2, Points: 6.5},
+		},
+	}
+
+	want := `# Week 2 recap: TTT
+
+## Results
+
+- TTT 6 @ KNR 3 at Georgetown Pizza and Arcade (upset: KNR were favored)
+- CRA @ PYC (not played yet)
+
+## Standout scores
+
+1. Alice (TTT): 300.0M on Twilight Zone, 3.0x the league P50
+
+## Upsets
+
+- TTT won Twilight Zone despite KNR's predicted +30% edge
+- TTT won Attack from Mars against KNR with no scores on it to predict from
+
+## Standings
+
+| # | Team | W-L-T | Points |
+|---|------|-------|--------|
+| 1 | **The Trailer Trashers** | 2-0-0 | 15 |
+| 2 | Knight Riders | 0-2-0 | 6.5 |
+`
+	if diff := cmp.Diff(want, Markdown(r)); diff != "" {
+		t.Errorf("Markdown(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,94 @@
+// Package roster summarizes each player on a team's current roster.
+package roster
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+// Store is the set of queries needed for a roster summary.
+type Store interface {
+	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error)
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error)
+	GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+	ListPlayers(ctx context.Context, search string) ([]db.PlayerSummary, error)
+}
+
+// Player is a player on the team's roster.
+type Player struct {
+	Name      string
+	IPR       int
+	Games     int      // Games the player played for the team this season.
+	Strongest []string // Machine names, up to 3. See player.AnalyzeMachines.
+}
+
+// Result is the output of a roster summary.
+type Result struct {
+	Team    string
+	Players []Player // Most games first, then highest IPR.
+}
+
+// Analyze returns each player on a team's current roster with their IPR, how
+// many games they've played for the team this season, and their strongest
+// machines across every season.
+func Analyze(ctx context.Context, s Store, team string) (*Result, error) {
+	players, err := s.ListPlayers(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("list players: %w", err)
+	}
+
+	attendance, err := s.GetTeamAttendance(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load attendance: %w", err)
+	}
+
+	leagueP50, err := s.GetLeagueP50(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league P50: %w", err)
+	}
+
+	leagueScores, err := s.GetLeagueScores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league scores: %w", err)
+	}
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	r := &Result{Team: team, Players: []Player{}}
+	for _, p := range players {
+		// ListPlayers also matches players whose names contain the key.
+		if p.TeamKey != team {
+			continue
+		}
+		stats, err := s.GetSinglePlayerMachineStats(ctx, p.Name, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("load %s's machine stats: %w", p.Name, err)
+		}
+		r.Players = append(r.Players, Player{
+			Name:      p.Name,
+			IPR:       p.IPR,
+			Games:     attendance[p.Name].Games,
+			Strongest: player.AnalyzeMachines(stats, leagueP50, leagueScores, names).Strongest,
+		})
+	}
+
+	slices.SortFunc(r.Players, func(a, b Player) int {
+		return cmp.Or(
+			cmp.Compare(b.Games, a.Games),
+			cmp.Compare(b.IPR, a.IPR),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	return r, nil
+}
@@ -0,0 +1,155 @@
+package roster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetLeagueP50                func(ctx context.Context) (map[string]float64, error)
+	MockGetLeagueScores             func(ctx context.Context) (map[string]db.LeagueScores, error)
+	MockGetMachineNames             func(ctx context.Context) (map[string]string, error)
+	MockGetSinglePlayerMachineStats func(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error)
+	MockGetTeamAttendance           func(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+	MockListPlayers                 func(ctx context.Context, search string) ([]db.PlayerSummary, error)
+}
+
+func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
+	return m.MockGetLeagueP50(ctx)
+}
+
+func (m *MockStore) GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error) {
+	return m.MockGetLeagueScores(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetSinglePlayerMachineStats(ctx context.Context, playerName, venueKey string, seasons []int) ([]db.PlayerMachineStats, error) {
+	return m.MockGetSinglePlayerMachineStats(ctx, playerName, venueKey, seasons)
+}
+
+func (m *MockStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return m.MockGetTeamAttendance(ctx, teamKey)
+}
+
+func (m *MockStore) ListPlayers(ctx context.Context, search string) ([]db.PlayerSummary, error) {
+	return m.MockListPlayers(ctx, search)
+}
+
+func TestAnalyze(t *testing.T) {
+	store := func() *MockStore {
+		return &MockStore{
+			MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+				return map[string]float64{"TAF": 100, "TZ": 100, "MM": 100, "AFM": 100}, nil
+			},
+			MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+				return map[string]db.LeagueScores{
+					"TAF": {50, 100, 150, 200},
+					"TZ":  {50, 100, 150, 200},
+					"MM":  {50, 100, 150, 200},
+					"AFM": {50, 100, 150, 200},
+				}, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TAF": "The Addams Family", "TZ": "Twilight Zone", "MM": "Medieval Madness", "AFM": "Attack from Mars"}, nil
+			},
+			MockGetSinglePlayerMachineStats: func(_ context.Context, name, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+				if name != "Alice" {
+					return nil, nil
+				}
+				return []db.PlayerMachineStats{
+					{MachineKey: "TAF", Games: 5, P50Score: 175},
+					{MachineKey: "TZ", Games: 3, P50Score: 75},
+					{MachineKey: "MM", Games: 4, P50Score: 125},
+					{MachineKey: "AFM", Games: 3, P50Score: 60},
+					// Too few games to count.
+					{MachineKey: "GDZ", Games: 1, P50Score: 1000},
+				}, nil
+			},
+			MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+				return map[string]db.Attendance{
+					"Alice": {Matches: 3, TeamMatches: 3, Games: 12},
+					"Bob":   {Matches: 2, TeamMatches: 3, Games: 6},
+					"Carol": {TeamMatches: 3},
+				}, nil
+			},
+			MockListPlayers: func(_ context.Context, _ string) ([]db.PlayerSummary, error) {
+				return []db.PlayerSummary{
+					{Name: "Alice", TeamKey: "CRA", IPR: 5},
+					{Name: "Bob", TeamKey: "CRA", IPR: 3},
+					{Name: "Carol", TeamKey: "CRA", IPR: 2},
+					{Name: "Dave", TeamKey: "CRA", IPR: 4},
+					// Matches the search, but isn't on the team.
+					{Name: "Cranky Pat", TeamKey: "PYC", IPR: 6},
+				}, nil
+			},
+		}
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		store  Store
+		want   want
+	}{
+		"Success": {
+			reason: "Rostered players should be ordered by games, then IPR, with their strongest machines by league percentile.",
+			store:  store(),
+			want: want{result: &Result{
+				Team: "CRA",
+				Players: []Player{
+					{Name: "Alice", IPR: 5, Games: 12, Strongest: []string{"The Addams Family", "Medieval Madness", "Twilight Zone"}},
+					{Name: "Bob", IPR: 3, Games: 6},
+					{Name: "Dave", IPR: 4},
+					{Name: "Carol", IPR: 2},
+				},
+			}},
+		},
+		"ListPlayersError": {
+			reason: "An error listing players should be returned.",
+			store: func() Store {
+				s := store()
+				s.MockListPlayers = func(_ context.Context, _ string) ([]db.PlayerSummary, error) {
+					return nil, errors.New("boom")
+				}
+				return s
+			}(),
+			want: want{err: cmpopts.AnyError},
+		},
+		"GetSinglePlayerMachineStatsError": {
+			reason: "An error loading a player's machine stats should be returned.",
+			store: func() Store {
+				s := store()
+				s.MockGetSinglePlayerMachineStats = func(_ context.Context, _, _ string, _ []int) ([]db.PlayerMachineStats, error) {
+					return nil, errors.New("boom")
+				}
+				return s
+			}(),
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.store, "CRA")
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
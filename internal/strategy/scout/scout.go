@@ -13,19 +13,31 @@ import (
 
 const minGamesForAnalysis = 3
 
+// BlendWeight is the recency weight a store should compute stats with when
+// blending. A score from last season counts half as much as one from this
+// season, and one from two seasons ago a quarter as much.
+const BlendWeight = 0.5
+
 // Store is the set of queries needed for scouting.
 type Store interface {
 	GetLeagueP50(ctx context.Context) (map[string]float64, error)
+	GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error)
 	GetMachineNames(ctx context.Context) (map[string]string, error)
-	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error)
-	GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error)
+	GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error)
+	GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error)
+	GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error)
+	GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	ListTeamMachineScores(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error)
+	GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
 }
 
 // LikelyPlayer is a player likely to play a machine.
 type LikelyPlayer struct {
-	Name     string
-	Games    int
-	P50Score float64
+	Name       string
+	Games      int
+	P50Score   float64
+	Attendance db.Attendance // How often the player has played for the team this season.
 }
 
 // MachineStats is a team's performance on a single machine.
@@ -36,7 +48,12 @@ type MachineStats struct {
 	P50Score      float64
 	P90Score      float64
 	LeagueP50     float64
+	PercentRank   float64 // Where P50 ranks among league scores on the machine, 0-1.
 	LikelyPlayers []LikelyPlayer
+	CurrentGames  int              // Games this season. Only set when blending.
+	Points        db.Points        // Only set with WithPoints.
+	Picks         db.MachinePicks  // Only set with WithPicks.
+	Split         db.HomeAwaySplit // Only set with WithSplits.
 }
 
 // Analysis summarizes a team's strongest and weakest machines.
@@ -51,6 +68,8 @@ type Result struct {
 	Venue       string         // Empty for global-only queries.
 	GlobalStats []MachineStats // All machines, or filtered to venue machines when a venue is set.
 	Analysis    Analysis
+	Blended     bool      // P50 and P90 weight recent seasons over older ones.
+	Points      db.Points // Only set with WithPoints. Totals across GlobalStats' machines.
 }
 
 // Option configures a Scout query.
@@ -58,7 +77,12 @@ type Option func(*Options)
 
 // Options holds optional parameters for a Scout query.
 type Options struct {
-	venue string
+	venue   string
+	blend   bool
+	seasons []int
+	points  bool
+	picks   bool
+	splits  bool
 }
 
 // AtVenue filters scouting to a specific venue.
@@ -68,75 +92,168 @@ func AtVenue(key string) Option {
 	}
 }
 
+// Blended marks the result as blended and counts each machine's games this
+// season. The store does the blending: scout a store opened with
+// db.WithRecencyWeight(BlendWeight) so this season's scores count most and
+// older seasons' scores decay. Early in a season, when few games have been
+// played, this keeps scouting anchored to how returning players played last
+// season rather than treating every past season equally.
+func Blended() Option {
+	return func(o *Options) {
+		o.blend = true
+	}
+}
+
+// InSeasons scouts the team using only games played in the supplied seasons,
+// rather than every season. At a venue, it scouts the machines there in the
+// latest of them. It can't be combined with Blended.
+func InSeasons(seasons ...int) Option {
+	return func(o *Options) {
+		o.seasons = seasons
+	}
+}
+
+// WithPoints includes the match points the team's current roster won on each
+// machine, out of the points they could have won.
+func WithPoints() Option {
+	return func(o *Options) {
+		o.points = true
+	}
+}
+
+// WithPicks includes how often the team picked each machine, and how often it
+// played the machine when its opponent picked it.
+func WithPicks() Option {
+	return func(o *Options) {
+		o.picks = true
+	}
+}
+
+// WithSplits includes the team's P50 on each machine in matches it hosted, and
+// in matches it played away. Like P50, it counts the current roster's games
+// for any team.
+func WithSplits() Option {
+	return func(o *Options) {
+		o.splits = true
+	}
+}
+
 // Analyze returns a team's strengths and weaknesses across machines.
 func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
 	var o Options
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.blend && len(o.seasons) > 0 {
+		return nil, fmt.Errorf("cannot blend seasons when scouting specific seasons")
+	}
 
 	leagueP50, err := s.GetLeagueP50(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("load league averages: %w", err)
 	}
 
+	leagueScores, err := s.GetLeagueScores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league scores: %w", err)
+	}
+
 	names, err := s.GetMachineNames(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("load machine names: %w", err)
 	}
 
+	var venueMachines map[string]bool
 	if o.venue != "" {
-		return scoutVenue(ctx, s, team, o.venue, leagueP50, names)
+		if venueMachines, err = s.GetVenueMachines(ctx, o.venue, db.LatestSeason(o.seasons)); err != nil {
+			return nil, fmt.Errorf("load venue machines: %w", err)
+		}
 	}
 
-	stats, err := s.GetTeamMachineStats(ctx, team, "")
+	stats, err := s.GetTeamMachineStats(ctx, team, "", o.seasons)
 	if err != nil {
 		return nil, fmt.Errorf("load team stats: %w", err)
 	}
 
-	return &Result{
-		Team:        team,
-		GlobalStats: enrichStats(stats, leagueP50, names),
-		Analysis:    analyze(stats, leagueP50, names),
-	}, nil
-}
+	var current map[string]int
+	if o.blend {
+		scores, err := s.ListTeamMachineScores(ctx, team)
+		if err != nil {
+			return nil, fmt.Errorf("load team scores: %w", err)
+		}
+		current = make(map[string]int)
+		for _, sc := range scores {
+			if sc.SeasonsAgo == 0 {
+				current[sc.MachineKey]++
+			}
+		}
+	}
 
-func scoutVenue(ctx context.Context, s Store, team, venue string, leagueP50 map[string]float64, names map[string]string) (*Result, error) {
-	venueMachines, err := s.GetVenueMachines(ctx, venue)
+	attendance, err := s.GetTeamAttendance(ctx, team)
 	if err != nil {
-		return nil, fmt.Errorf("load venue machines: %w", err)
+		return nil, fmt.Errorf("load team attendance: %w", err)
 	}
 
-	globalStats, err := s.GetTeamMachineStats(ctx, team, "")
-	if err != nil {
-		return nil, fmt.Errorf("load team stats: %w", err)
+	r := &Result{Team: team, Venue: o.venue, Blended: o.blend}
+	if o.venue != "" {
+		// Filter global stats to machines at the venue.
+		filtered := make([]db.TeamMachineStats, 0, len(stats))
+		for _, gs := range stats {
+			if venueMachines[gs.MachineKey] {
+				filtered = append(filtered, gs)
+			}
+		}
+		stats = filtered
 	}
 
-	// Filter global stats to machines at the venue.
-	filtered := make([]db.TeamMachineStats, 0, len(globalStats))
-	for _, gs := range globalStats {
-		if venueMachines[gs.MachineKey] {
-			filtered = append(filtered, gs)
+	r.GlobalStats = enrichStats(stats, leagueP50, leagueScores, names, attendance)
+	r.Analysis = analyze(stats, leagueP50, leagueScores, names)
+	for i := range r.GlobalStats {
+		r.GlobalStats[i].CurrentGames = current[r.GlobalStats[i].MachineKey]
+	}
+
+	if o.points {
+		pts, err := s.GetTeamMachinePoints(ctx, team, o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load team points: %w", err)
+		}
+		for i := range r.GlobalStats {
+			r.GlobalStats[i].Points = pts[r.GlobalStats[i].MachineKey]
+			r.Points = r.Points.Add(r.GlobalStats[i].Points)
 		}
 	}
 
-	return &Result{
-		Team:        team,
-		Venue:       venue,
-		GlobalStats: enrichStats(filtered, leagueP50, names),
-		Analysis:    analyze(filtered, leagueP50, names),
-	}, nil
+	if o.picks {
+		picks, err := s.GetTeamMachinePicks(ctx, team, o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load team picks: %w", err)
+		}
+		for i := range r.GlobalStats {
+			r.GlobalStats[i].Picks = picks[r.GlobalStats[i].MachineKey]
+		}
+	}
+
+	if o.splits {
+		splits, err := s.GetTeamHomeAwaySplits(ctx, team, o.seasons)
+		if err != nil {
+			return nil, fmt.Errorf("load team home and away splits: %w", err)
+		}
+		for i := range r.GlobalStats {
+			r.GlobalStats[i].Split = splits[r.GlobalStats[i].MachineKey]
+		}
+	}
+	return r, nil
 }
 
-func enrichStats(stats []db.TeamMachineStats, leagueP50 map[string]float64, names map[string]string) []MachineStats {
+func enrichStats(stats []db.TeamMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string, attendance map[string]db.Attendance) []MachineStats {
 	result := make([]MachineStats, len(stats))
 	for i, s := range stats {
-		result[i] = enrichStat(s, leagueP50, names)
+		result[i] = enrichStat(s, leagueP50, leagueScores, names, attendance)
 	}
 	return result
 }
 
-func enrichStat(s db.TeamMachineStats, leagueP50 map[string]float64, names map[string]string) MachineStats {
+func enrichStat(s db.TeamMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string, attendance map[string]db.Attendance) MachineStats {
 	ms := MachineStats{
 		MachineKey:  s.MachineKey,
 		MachineName: output.MachineName(names, s.MachineKey),
@@ -144,19 +261,22 @@ func enrichStat(s db.TeamMachineStats, leagueP50 map[string]float64, names map[s
 		P50Score:    s.P50Score,
 		P90Score:    s.P90Score,
 		LeagueP50:   leagueP50[s.MachineKey],
+		PercentRank: leagueScores[s.MachineKey].PercentRank(s.P50Score),
 	}
 	for _, lp := range s.LikelyPlayers {
 		ms.LikelyPlayers = append(ms.LikelyPlayers, LikelyPlayer{
-			Name:     lp.Name,
-			Games:    lp.Games,
-			P50Score: lp.P50Score,
+			Name:       lp.Name,
+			Games:      lp.Games,
+			P50Score:   lp.P50Score,
+			Attendance: attendance[lp.Name],
 		})
 	}
 	return ms
 }
 
-// analyze computes strongest/weakest machines by relative strength.
-func analyze(stats []db.TeamMachineStats, leagueP50 map[string]float64, names map[string]string) Analysis {
+// analyze computes strongest/weakest machines by where their P50s rank among
+// league scores.
+func analyze(stats []db.TeamMachineStats, leagueP50 map[string]float64, leagueScores map[string]db.LeagueScores, names map[string]string) Analysis {
 	sorted := make([]db.TeamMachineStats, 0, len(stats))
 	for _, s := range stats {
 		if s.Games >= minGamesForAnalysis {
@@ -164,7 +284,15 @@ func analyze(stats []db.TeamMachineStats, leagueP50 map[string]float64, names ma
 		}
 	}
 
+	// Scoring scales differ by orders of magnitude between machines, so rank
+	// machines by where their P50 falls among the league's scores, and only
+	// fall back to relative strength when that's a tie.
 	slices.SortFunc(sorted, func(a, b db.TeamMachineStats) int {
+		aRank := leagueScores[a.MachineKey].PercentRank(a.P50Score)
+		bRank := leagueScores[b.MachineKey].PercentRank(b.P50Score)
+		if c := cmp.Compare(bRank, aRank); c != 0 {
+			return c
+		}
 		aRel := output.RelStr(a.P50Score, leagueP50[a.MachineKey])
 		bRel := output.RelStr(b.P50Score, leagueP50[b.MachineKey])
 		return cmp.Compare(bRel, aRel)
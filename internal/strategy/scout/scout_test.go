@@ -12,26 +12,56 @@ import (
 )
 
 type MockStore struct {
-	MockGetLeagueP50        func(ctx context.Context) (map[string]float64, error)
-	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
-	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error)
-	MockGetVenueMachines    func(ctx context.Context, venueKey string) (map[string]bool, error)
+	MockGetLeagueP50          func(ctx context.Context) (map[string]float64, error)
+	MockGetLeagueScores       func(ctx context.Context) (map[string]db.LeagueScores, error)
+	MockGetMachineNames       func(ctx context.Context) (map[string]string, error)
+	MockGetTeamHomeAwaySplits func(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error)
+	MockGetTeamMachinePicks   func(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error)
+	MockGetTeamMachinePoints  func(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error)
+	MockGetTeamMachineStats   func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines      func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListTeamMachineScores func(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error)
+	MockGetTeamAttendance     func(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
 }
 
 func (m *MockStore) GetLeagueP50(ctx context.Context) (map[string]float64, error) {
 	return m.MockGetLeagueP50(ctx)
 }
 
+func (m *MockStore) GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error) {
+	return m.MockGetLeagueScores(ctx)
+}
+
 func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
 	return m.MockGetMachineNames(ctx)
 }
 
-func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string) ([]db.TeamMachineStats, error) {
-	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey)
+func (m *MockStore) GetTeamHomeAwaySplits(ctx context.Context, teamKey string, seasons []int) (map[string]db.HomeAwaySplit, error) {
+	return m.MockGetTeamHomeAwaySplits(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) GetTeamMachinePicks(ctx context.Context, teamKey string, seasons []int) (map[string]db.MachinePicks, error) {
+	return m.MockGetTeamMachinePicks(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) GetTeamMachinePoints(ctx context.Context, teamKey string, seasons []int) (map[string]db.Points, error) {
+	return m.MockGetTeamMachinePoints(ctx, teamKey, seasons)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
 }
 
-func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string) (map[string]bool, error) {
-	return m.MockGetVenueMachines(ctx, venueKey)
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListTeamMachineScores(ctx context.Context, teamKey string) ([]db.TeamMachineScore, error) {
+	return m.MockListTeamMachineScores(ctx, teamKey)
+}
+
+func (m *MockStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return m.MockGetTeamAttendance(ctx, teamKey)
 }
 
 func TestAnalyze(t *testing.T) {
@@ -63,6 +93,9 @@ func TestAnalyze(t *testing.T) {
 							"AFM": 20_000_000,
 						}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{
 							"TAF": "The Addams Family",
@@ -71,14 +104,19 @@ func TestAnalyze(t *testing.T) {
 							"AFM": "Attack From Mars",
 						}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return []db.TeamMachineStats{
-							{MachineKey: "TAF", Games: 10, P50Score: 60_000_000, P90Score: 80_000_000},
+							{MachineKey: "TAF", Games: 10, P50Score: 60_000_000, P90Score: 80_000_000, LikelyPlayers: []db.LikelyPlayer{
+								{Name: "Alice", Games: 6, P50Score: 70_000_000},
+							}},
 							{MachineKey: "MM", Games: 8, P50Score: 15_000_000, P90Score: 25_000_000},
 							{MachineKey: "TZ", Games: 5, P50Score: 20_000_000, P90Score: 30_000_000},
 							{MachineKey: "AFM", Games: 4, P50Score: 10_000_000, P90Score: 15_000_000},
 						}, nil
 					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return map[string]db.Attendance{"Alice": {Matches: 2, TeamMatches: 8, Games: 6}}, nil
+					},
 				},
 				team: "CRA",
 			},
@@ -86,7 +124,9 @@ func TestAnalyze(t *testing.T) {
 				result: &Result{
 					Team: "CRA",
 					GlobalStats: []MachineStats{
-						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 10, P50Score: 60_000_000, P90Score: 80_000_000, LeagueP50: 30_000_000},
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 10, P50Score: 60_000_000, P90Score: 80_000_000, LeagueP50: 30_000_000, LikelyPlayers: []LikelyPlayer{
+							{Name: "Alice", Games: 6, P50Score: 70_000_000, Attendance: db.Attendance{Matches: 2, TeamMatches: 8, Games: 6}},
+						}},
 						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 8, P50Score: 15_000_000, P90Score: 25_000_000, LeagueP50: 15_000_000},
 						{MachineKey: "TZ", MachineName: "Twilight Zone", Games: 5, P50Score: 20_000_000, P90Score: 30_000_000, LeagueP50: 40_000_000},
 						{MachineKey: "AFM", MachineName: "Attack From Mars", Games: 4, P50Score: 10_000_000, P90Score: 15_000_000, LeagueP50: 20_000_000},
@@ -98,6 +138,64 @@ func TestAnalyze(t *testing.T) {
 				},
 			},
 		},
+		"NormalizedStrength": {
+			reason: "Machines should be ranked by where their P50 falls among league scores, not by how far it is above the league P50.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return map[string]db.LeagueScores{
+							// TAF scores vary wildly, so doubling its P50 is less impressive...
+							"TAF": {5_000_000, 10_000_000, 30_000_000, 70_000_000, 90_000_000},
+							// ...than beating every MM score, which are tightly clustered.
+							"MM": {12_000_000, 14_000_000, 15_000_000, 16_000_000, 17_000_000},
+						}, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 5, P50Score: 60_000_000},
+							{MachineKey: "MM", Games: 5, P50Score: 18_000_000},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+			},
+			want: want{
+				result: &Result{
+					Team: "CRA",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 5, P50Score: 60_000_000, LeagueP50: 30_000_000, PercentRank: 0.6},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 5, P50Score: 18_000_000, LeagueP50: 15_000_000, PercentRank: 1},
+					},
+					Analysis: Analysis{
+						Strongest: []string{"Medieval Madness", "The Addams Family"},
+					},
+				},
+			},
+		},
+		"GetLeagueScoresError": {
+			reason: "Errors loading league scores should be returned.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return nil, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "CRA",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
 		"AtVenue": {
 			reason: "With a venue option, global stats should be filtered to venue machines.",
 			args: args{
@@ -105,19 +203,25 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return map[string]bool{"TAF": true, "MM": true}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return []db.TeamMachineStats{
 							{MachineKey: "TAF", Games: 10, P50Score: 50_000_000, P90Score: 70_000_000},
 							{MachineKey: "MM", Games: 8, P50Score: 20_000_000, P90Score: 30_000_000},
 							{MachineKey: "TZ", Games: 5, P50Score: 20_000_000, P90Score: 30_000_000},
 						}, nil
 					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
 				},
 				team: "CRA",
 				opts: []Option{AtVenue("SAM")},
@@ -136,6 +240,282 @@ func TestAnalyze(t *testing.T) {
 				},
 			},
 		},
+		"WithPoints": {
+			reason: "With points, each machine should include the points won on it, and the result their total.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000},
+							{MachineKey: "MM", Games: 1, P50Score: 20_000_000, P90Score: 30_000_000},
+						}, nil
+					},
+					MockGetTeamMachinePoints: func(_ context.Context, _ string, _ []int) (map[string]db.Points, error) {
+						return map[string]db.Points{
+							"TAF": {Games: 2, Won: 4.5, Possible: 5.5},
+							"MM":  {Games: 1, Won: 0, Possible: 3},
+							"TZ":  {Games: 1, Won: 3, Possible: 3},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+				opts: []Option{WithPoints()},
+			},
+			want: want{
+				result: &Result{
+					Team: "CRA",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000, LeagueP50: 30_000_000, Points: db.Points{Games: 2, Won: 4.5, Possible: 5.5}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 1, P50Score: 20_000_000, P90Score: 30_000_000, LeagueP50: 15_000_000, Points: db.Points{Games: 1, Won: 0, Possible: 3}},
+					},
+					// TZ isn't in the team's stats, so doesn't count.
+					Points: db.Points{Games: 3, Won: 4.5, Possible: 8.5},
+				},
+			},
+		},
+		"WithPicks": {
+			reason: "With picks, each machine should include how often the team picked it, and how often its opponents did.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000},
+							{MachineKey: "MM", Games: 1, P50Score: 20_000_000, P90Score: 30_000_000},
+						}, nil
+					},
+					MockGetTeamMachinePicks: func(_ context.Context, _ string, _ []int) (map[string]db.MachinePicks, error) {
+						return map[string]db.MachinePicks{
+							"TAF": {Picked: 4, Stuck: 1},
+							"TZ":  {Picked: 2},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+				opts: []Option{WithPicks()},
+			},
+			want: want{
+				result: &Result{
+					Team: "CRA",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000, LeagueP50: 30_000_000, Picks: db.MachinePicks{Picked: 4, Stuck: 1}},
+						{MachineKey: "MM", MachineName: "Medieval Madness", Games: 1, P50Score: 20_000_000, P90Score: 30_000_000, LeagueP50: 15_000_000},
+					},
+				},
+			},
+		},
+		"WithSplits": {
+			reason: "With splits, each machine should include the team's P50 at home and away.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000},
+						}, nil
+					},
+					MockGetTeamHomeAwaySplits: func(_ context.Context, _ string, _ []int) (map[string]db.HomeAwaySplit, error) {
+						return map[string]db.HomeAwaySplit{
+							"TAF": {HomeGames: 1, HomeP50: 60_000_000, AwayGames: 1, AwayP50: 40_000_000},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+				opts: []Option{WithSplits()},
+			},
+			want: want{
+				result: &Result{
+					Team: "CRA",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 2, P50Score: 50_000_000, P90Score: 70_000_000, LeagueP50: 30_000_000, Split: db.HomeAwaySplit{HomeGames: 1, HomeP50: 60_000_000, AwayGames: 1, AwayP50: 40_000_000}},
+					},
+				},
+			},
+		},
+		"Blended": {
+			reason: "When blending, P50 and P90 should be the store's, which weights recent seasons, and this season's games should be counted.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 4, P50Score: 20_000_000, P90Score: 80_000_000},
+						}, nil
+					},
+					MockListTeamMachineScores: func(_ context.Context, _ string) ([]db.TeamMachineScore, error) {
+						return []db.TeamMachineScore{
+							{MachineKey: "TAF", SeasonsAgo: 2, Score: 10_000_000},
+							{MachineKey: "TAF", SeasonsAgo: 2, Score: 20_000_000},
+							{MachineKey: "TAF", SeasonsAgo: 1, Score: 40_000_000},
+							{MachineKey: "TAF", SeasonsAgo: 0, Score: 80_000_000},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+				opts: []Option{Blended()},
+			},
+			want: want{
+				result: &Result{
+					Team:    "CRA",
+					Blended: true,
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 4, P50Score: 20_000_000, P90Score: 80_000_000, LeagueP50: 30_000_000, CurrentGames: 1},
+					},
+					Analysis: Analysis{
+						Strongest: []string{"The Addams Family"},
+					},
+				},
+			},
+		},
+		"InSeasons": {
+			reason: "With a seasons option, stats should only be loaded for those seasons.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{"TAF": 30_000_000}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{"TAF": "The Addams Family"}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, seasons []int) ([]db.TeamMachineStats, error) {
+						if !cmp.Equal(seasons, []int{22}) {
+							return nil, errors.New("unexpected seasons")
+						}
+						return []db.TeamMachineStats{
+							{MachineKey: "TAF", Games: 4, P50Score: 40_000_000, P90Score: 60_000_000},
+						}, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
+				},
+				team: "CRA",
+				opts: []Option{InSeasons(22)},
+			},
+			want: want{
+				result: &Result{
+					Team: "CRA",
+					GlobalStats: []MachineStats{
+						{MachineKey: "TAF", MachineName: "The Addams Family", Games: 4, P50Score: 40_000_000, P90Score: 60_000_000, LeagueP50: 30_000_000},
+					},
+					Analysis: Analysis{
+						Strongest: []string{"The Addams Family"},
+					},
+				},
+			},
+		},
+		"BlendedInSeasons": {
+			reason: "Blending can't be combined with specific seasons.",
+			args: args{
+				store: &MockStore{},
+				team:  "CRA",
+				opts:  []Option{Blended(), InSeasons(22)},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ListTeamMachineScoresError": {
+			reason: "An error loading team scores when blending should be returned.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return nil, nil
+					},
+					MockListTeamMachineScores: func(_ context.Context, _ string) ([]db.TeamMachineScore, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "CRA",
+				opts: []Option{Blended()},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"GetTeamAttendanceError": {
+			reason: "An error loading team attendance should be returned.",
+			args: args{
+				store: &MockStore{
+					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
+						return map[string]float64{}, nil
+					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
+					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+						return map[string]string{}, nil
+					},
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
+						return nil, nil
+					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				team: "CRA",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
 		"AnalysisMinGamesFilter": {
 			reason: "Machines with fewer than 3 games should be excluded from the strongest/weakest analysis.",
 			args: args{
@@ -143,15 +523,21 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{"TAF": 30_000_000, "MM": 15_000_000}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness"}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return []db.TeamMachineStats{
 							{MachineKey: "TAF", Games: 3, P50Score: 60_000_000},
 							{MachineKey: "MM", Games: 2, P50Score: 100_000_000},
 						}, nil
 					},
+					MockGetTeamAttendance: func(_ context.Context, _ string) (map[string]db.Attendance, error) {
+						return nil, nil
+					},
 				},
 				team: "CRA",
 			},
@@ -175,6 +561,9 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return nil, errors.New("boom")
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 				},
 				team: "CRA",
 			},
@@ -189,6 +578,9 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return nil, errors.New("boom")
 					},
@@ -206,10 +598,13 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{}, nil
 					},
-					MockGetTeamMachineStats: func(_ context.Context, _, _ string) ([]db.TeamMachineStats, error) {
+					MockGetTeamMachineStats: func(_ context.Context, _, _ string, _ []int) ([]db.TeamMachineStats, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -226,10 +621,13 @@ func TestAnalyze(t *testing.T) {
 					MockGetLeagueP50: func(_ context.Context) (map[string]float64, error) {
 						return map[string]float64{}, nil
 					},
+					MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+						return nil, nil
+					},
 					MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
 						return map[string]string{}, nil
 					},
-					MockGetVenueMachines: func(_ context.Context, _ string) (map[string]bool, error) {
+					MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
 						return nil, errors.New("boom")
 					},
 				},
@@ -0,0 +1,276 @@
+// Package seasons compares a team across two seasons.
+package seasons
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Store is the set of queries needed for a season comparison.
+type Store interface {
+	GetMachineNames(ctx context.Context) (map[string]string, error)
+	ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error)
+	GetTeamSeasonMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error)
+	GetTeamSeasonRosterMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error)
+	GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error)
+	GetTeamSeasonPoints(ctx context.Context, teamKey string, season int) (db.TeamSeasonPoints, error)
+}
+
+// Season is a team's roster and results in one season.
+type Season struct {
+	Number int
+	Roster []string
+	Points db.TeamSeasonPoints
+}
+
+// PointsPct returns the fraction of points in the team's matches that the
+// team earned, or zero if it played none.
+func (s Season) PointsPct() float64 {
+	total := s.Points.Points + s.Points.OpponentPoints
+	if total == 0 {
+		return 0
+	}
+	return s.Points.Points / total
+}
+
+// Stats are a team's stats on a machine in one season.
+type Stats struct {
+	Games    int
+	P50Score float64
+}
+
+// MinRosterGames is the fewest games each season's roster must have played on
+// a machine, in any season, for their strength on it to be compared.
+const MinRosterGames = 3
+
+// Machine compares a team's stats on a machine between two seasons.
+type Machine struct {
+	MachineKey  string
+	MachineName string
+	From        Stats
+	To          Stats
+
+	// FromRoster and ToRoster are the stats of each season's roster on the
+	// machine, counting their games in every season. They compare the
+	// rosters, rather than how the team played that season.
+	FromRoster Stats
+	ToRoster   Stats
+}
+
+// Compared returns true if the team played the machine in both seasons.
+func (m Machine) Compared() bool {
+	return m.From.Games > 0 && m.To.Games > 0
+}
+
+// RosterCompared returns true if both seasons' rosters played the machine
+// enough to compare them.
+func (m Machine) RosterCompared() bool {
+	return m.FromRoster.Games >= MinRosterGames && m.ToRoster.Games >= MinRosterGames
+}
+
+// Weaker returns true if the later season's roster is weaker on the machine
+// than the earlier season's.
+func (m Machine) Weaker() bool {
+	return m.RosterCompared() && m.ToRoster.P50Score < m.FromRoster.P50Score
+}
+
+// Result is the output of a season comparison.
+type Result struct {
+	Team     string
+	Seasons  []int // Every season the team played, oldest first.
+	From     Season
+	To       Season
+	Machines []Machine // Most played first.
+	Weaker   []string  // Names of machines the later roster is weaker on, biggest drop first.
+	Kept     []string  // Players on both rosters.
+	Joined   []string  // Players only on the later roster.
+	Left     []string  // Players only on the earlier roster.
+}
+
+// Option configures a season comparison.
+type Option func(*Options)
+
+// Options holds optional parameters for a season comparison.
+type Options struct {
+	from, to int
+}
+
+// Between compares the supplied seasons, rather than the team's two most
+// recent seasons.
+func Between(from, to int) Option {
+	return func(o *Options) {
+		o.from, o.to = from, to
+	}
+}
+
+// Analyze compares a team's machine strengths, roster, and points percentage
+// between two seasons. By default it compares the team's two most recent
+// seasons. Result.From is zero if the team has played only one season.
+func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	played, err := s.ListTeamSeasons(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load team seasons: %w", err)
+	}
+	if len(played) == 0 {
+		return nil, fmt.Errorf("no seasons found for team %s", team)
+	}
+
+	r := &Result{Team: team, Seasons: played}
+	if o.from == 0 && o.to == 0 {
+		if len(played) < 2 {
+			return r, nil
+		}
+		o.from, o.to = played[len(played)-2], played[len(played)-1]
+	}
+	for _, n := range []int{o.from, o.to} {
+		if !slices.Contains(played, n) {
+			return nil, fmt.Errorf("%s didn't play in season %d", team, n)
+		}
+	}
+
+	if r.From, err = loadSeason(ctx, s, team, o.from); err != nil {
+		return nil, err
+	}
+	if r.To, err = loadSeason(ctx, s, team, o.to); err != nil {
+		return nil, err
+	}
+	r.Kept, r.Joined, r.Left = turnover(r.From.Roster, r.To.Roster)
+
+	names, err := s.GetMachineNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load machine names: %w", err)
+	}
+
+	from, err := s.GetTeamSeasonMachineStats(ctx, team, o.from)
+	if err != nil {
+		return nil, fmt.Errorf("load season %d machine stats: %w", o.from, err)
+	}
+	to, err := s.GetTeamSeasonMachineStats(ctx, team, o.to)
+	if err != nil {
+		return nil, fmt.Errorf("load season %d machine stats: %w", o.to, err)
+	}
+	r.Machines = compareMachines(from, to, names)
+
+	fromRoster, err := s.GetTeamSeasonRosterMachineStats(ctx, team, o.from)
+	if err != nil {
+		return nil, fmt.Errorf("load season %d roster machine stats: %w", o.from, err)
+	}
+	toRoster, err := s.GetTeamSeasonRosterMachineStats(ctx, team, o.to)
+	if err != nil {
+		return nil, fmt.Errorf("load season %d roster machine stats: %w", o.to, err)
+	}
+	compareRosters(r.Machines, fromRoster, toRoster)
+	r.Weaker = weaker(r.Machines)
+
+	return r, nil
+}
+
+func loadSeason(ctx context.Context, s Store, team string, n int) (Season, error) {
+	roster, err := s.GetTeamSeasonRoster(ctx, team, n)
+	if err != nil {
+		return Season{}, fmt.Errorf("load season %d roster: %w", n, err)
+	}
+	points, err := s.GetTeamSeasonPoints(ctx, team, n)
+	if err != nil {
+		return Season{}, fmt.Errorf("load season %d points: %w", n, err)
+	}
+	return Season{Number: n, Roster: roster, Points: points}, nil
+}
+
+// turnover splits two rosters into the players on both, only the later, and
+// only the earlier, each sorted by name.
+func turnover(from, to []string) (kept, joined, left []string) {
+	for _, p := range to {
+		if slices.Contains(from, p) {
+			kept = append(kept, p)
+			continue
+		}
+		joined = append(joined, p)
+	}
+	for _, p := range from {
+		if !slices.Contains(to, p) {
+			left = append(left, p)
+		}
+	}
+	slices.Sort(kept)
+	slices.Sort(joined)
+	slices.Sort(left)
+	return kept, joined, left
+}
+
+// compareMachines joins two seasons' machine stats, most played first.
+func compareMachines(from, to []db.TeamMachineStats, names map[string]string) []Machine {
+	var machines []Machine
+	index := make(map[string]int)
+	add := func(stats []db.TeamMachineStats, set func(m *Machine, s Stats)) {
+		for _, ts := range stats {
+			i, ok := index[ts.MachineKey]
+			if !ok {
+				i = len(machines)
+				index[ts.MachineKey] = i
+				machines = append(machines, Machine{
+					MachineKey:  ts.MachineKey,
+					MachineName: output.MachineName(names, ts.MachineKey),
+				})
+			}
+			set(&machines[i], Stats{Games: ts.Games, P50Score: ts.P50Score})
+		}
+	}
+	add(from, func(m *Machine, s Stats) { m.From = s })
+	add(to, func(m *Machine, s Stats) { m.To = s })
+
+	slices.SortFunc(machines, func(a, b Machine) int {
+		if c := cmp.Compare(b.From.Games+b.To.Games, a.From.Games+a.To.Games); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MachineName, b.MachineName)
+	})
+	return machines
+}
+
+// compareRosters sets each machine's roster stats. Machines the team didn't
+// play in either season are left out, even if its rosters played them.
+func compareRosters(machines []Machine, from, to []db.TeamMachineStats) {
+	index := make(map[string]int, len(machines))
+	for i, m := range machines {
+		index[m.MachineKey] = i
+	}
+	set := func(stats []db.TeamMachineStats, set func(m *Machine, s Stats)) {
+		for _, ts := range stats {
+			if i, ok := index[ts.MachineKey]; ok {
+				set(&machines[i], Stats{Games: ts.Games, P50Score: ts.P50Score})
+			}
+		}
+	}
+	set(from, func(m *Machine, s Stats) { m.FromRoster = s })
+	set(to, func(m *Machine, s Stats) { m.ToRoster = s })
+}
+
+// weaker returns the names of the machines the later roster is weaker on,
+// biggest relative drop in P50 first.
+func weaker(machines []Machine) []string {
+	var w []Machine
+	for _, m := range machines {
+		if m.Weaker() {
+			w = append(w, m)
+		}
+	}
+	slices.SortStableFunc(w, func(a, b Machine) int {
+		return cmp.Compare(a.ToRoster.P50Score/a.FromRoster.P50Score, b.ToRoster.P50Score/b.FromRoster.P50Score)
+	})
+	var names []string
+	for _, m := range w {
+		names = append(names, m.MachineName)
+	}
+	return names
+}
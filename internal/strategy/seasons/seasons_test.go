@@ -0,0 +1,240 @@
+package seasons
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockGetMachineNames                 func(ctx context.Context) (map[string]string, error)
+	MockListTeamSeasons                 func(ctx context.Context, teamKey string) ([]int, error)
+	MockGetTeamSeasonMachineStats       func(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error)
+	MockGetTeamSeasonRosterMachineStats func(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error)
+	MockGetTeamSeasonRoster             func(ctx context.Context, teamKey string, season int) ([]string, error)
+	MockGetTeamSeasonPoints             func(ctx context.Context, teamKey string, season int) (db.TeamSeasonPoints, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) ListTeamSeasons(ctx context.Context, teamKey string) ([]int, error) {
+	return m.MockListTeamSeasons(ctx, teamKey)
+}
+
+func (m *MockStore) GetTeamSeasonMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamSeasonMachineStats(ctx, teamKey, season)
+}
+
+func (m *MockStore) GetTeamSeasonRosterMachineStats(ctx context.Context, teamKey string, season int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamSeasonRosterMachineStats(ctx, teamKey, season)
+}
+
+func (m *MockStore) GetTeamSeasonRoster(ctx context.Context, teamKey string, season int) ([]string, error) {
+	return m.MockGetTeamSeasonRoster(ctx, teamKey, season)
+}
+
+func (m *MockStore) GetTeamSeasonPoints(ctx context.Context, teamKey string, season int) (db.TeamSeasonPoints, error) {
+	return m.MockGetTeamSeasonPoints(ctx, teamKey, season)
+}
+
+func TestAnalyze(t *testing.T) {
+	rosters := map[int][]string{
+		21: {"Alice", "Bob", "Dave"},
+		22: {"Alice", "Bob", "Carol"},
+		23: {"Alice", "Erin", "Frank"},
+	}
+	points := map[int]db.TeamSeasonPoints{
+		21: {Matches: 10, Points: 200, OpponentPoints: 200},
+		22: {Matches: 10, Points: 240, OpponentPoints: 160},
+		23: {Matches: 2, Points: 30, OpponentPoints: 50},
+	}
+	machines := map[int][]db.TeamMachineStats{
+		22: {
+			{MachineKey: "TAF", Games: 10, P50Score: 1_000_000},
+			{MachineKey: "MM", Games: 4, P50Score: 50_000_000},
+		},
+		23: {
+			{MachineKey: "TZ", Games: 6, P50Score: 200_000_000},
+			{MachineKey: "TAF", Games: 2, P50Score: 1_500_000},
+		},
+	}
+
+	// Each roster's games in every season. Season 22's roster has barely
+	// played TZ, so it can't be compared.
+	rosterMachines := map[int][]db.TeamMachineStats{
+		21: {
+			{MachineKey: "TAF", Games: 20, P50Score: 1_200_000},
+		},
+		22: {
+			{MachineKey: "TAF", Games: 30, P50Score: 1_500_000},
+			{MachineKey: "MM", Games: 12, P50Score: 40_000_000},
+			{MachineKey: "TZ", Games: 2, P50Score: 300_000_000},
+		},
+		23: {
+			{MachineKey: "TAF", Games: 25, P50Score: 1_200_000},
+			{MachineKey: "MM", Games: 8, P50Score: 20_000_000},
+			{MachineKey: "TZ", Games: 15, P50Score: 150_000_000},
+			{MachineKey: "AFM", Games: 5, P50Score: 900_000_000},
+		},
+	}
+
+	store := func(seasons []int) *MockStore {
+		return &MockStore{
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone"}, nil
+			},
+			MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) { return seasons, nil },
+			MockGetTeamSeasonMachineStats: func(_ context.Context, _ string, n int) ([]db.TeamMachineStats, error) {
+				return machines[n], nil
+			},
+			MockGetTeamSeasonRosterMachineStats: func(_ context.Context, _ string, n int) ([]db.TeamMachineStats, error) {
+				return rosterMachines[n], nil
+			},
+			MockGetTeamSeasonRoster: func(_ context.Context, _ string, n int) ([]string, error) { return rosters[n], nil },
+			MockGetTeamSeasonPoints: func(_ context.Context, _ string, n int) (db.TeamSeasonPoints, error) {
+				return points[n], nil
+			},
+		}
+	}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"LatestTwo": {
+			reason: "Without seasons, the team's two most recent seasons should be compared, with the machines the later roster is weaker on.",
+			args:   args{store: store([]int{21, 22, 23})},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Seasons: []int{21, 22, 23},
+				From:    Season{Number: 22, Roster: rosters[22], Points: points[22]},
+				To:      Season{Number: 23, Roster: rosters[23], Points: points[23]},
+				Machines: []Machine{
+					{
+						MachineKey: "TAF", MachineName: "The Addams Family",
+						From: Stats{Games: 10, P50Score: 1_000_000}, To: Stats{Games: 2, P50Score: 1_500_000},
+						FromRoster: Stats{Games: 30, P50Score: 1_500_000}, ToRoster: Stats{Games: 25, P50Score: 1_200_000},
+					},
+					{
+						MachineKey: "TZ", MachineName: "Twilight Zone",
+						To:         Stats{Games: 6, P50Score: 200_000_000},
+						FromRoster: Stats{Games: 2, P50Score: 300_000_000}, ToRoster: Stats{Games: 15, P50Score: 150_000_000},
+					},
+					{
+						MachineKey: "MM", MachineName: "Medieval Madness",
+						From:       Stats{Games: 4, P50Score: 50_000_000},
+						FromRoster: Stats{Games: 12, P50Score: 40_000_000}, ToRoster: Stats{Games: 8, P50Score: 20_000_000},
+					},
+				},
+				Weaker: []string{"Medieval Madness", "The Addams Family"},
+				Kept:   []string{"Alice"},
+				Joined: []string{"Erin", "Frank"},
+				Left:   []string{"Bob", "Carol"},
+			}},
+		},
+		"Between": {
+			reason: "The requested seasons should be compared, even if they aren't the latest.",
+			args:   args{store: store([]int{21, 22, 23}), opts: []Option{Between(21, 22)}},
+			want: want{result: &Result{
+				Team:    "CRA",
+				Seasons: []int{21, 22, 23},
+				From:    Season{Number: 21, Roster: rosters[21], Points: points[21]},
+				To:      Season{Number: 22, Roster: rosters[22], Points: points[22]},
+				Machines: []Machine{
+					{
+						MachineKey: "TAF", MachineName: "The Addams Family",
+						To:         Stats{Games: 10, P50Score: 1_000_000},
+						FromRoster: Stats{Games: 20, P50Score: 1_200_000}, ToRoster: Stats{Games: 30, P50Score: 1_500_000},
+					},
+					{
+						MachineKey: "MM", MachineName: "Medieval Madness",
+						To:       Stats{Games: 4, P50Score: 50_000_000},
+						ToRoster: Stats{Games: 12, P50Score: 40_000_000},
+					},
+				},
+				Kept:   []string{"Alice", "Bob"},
+				Joined: []string{"Carol"},
+				Left:   []string{"Dave"},
+			}},
+		},
+		"OneSeason": {
+			reason: "A team that has played one season has nothing to compare.",
+			args:   args{store: store([]int{23})},
+			want:   want{result: &Result{Team: "CRA", Seasons: []int{23}}},
+		},
+		"UnplayedSeason": {
+			reason: "Asking for a season the team didn't play should return an error.",
+			args:   args{store: store([]int{22, 23}), opts: []Option{Between(20, 23)}},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"UnknownTeam": {
+			reason: "A team with no seasons should return an error.",
+			args:   args{store: store(nil)},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"SeasonsError": {
+			reason: "An error loading the team's seasons should be returned.",
+			args: args{store: &MockStore{
+				MockListTeamSeasons: func(_ context.Context, _ string) ([]int, error) { return nil, errors.New("boom") },
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "CRA", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPointsPct(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		season Season
+		want   float64
+	}{
+		"Played": {
+			reason: "Points percentage should be the team's share of points in its matches.",
+			season: Season{Points: db.TeamSeasonPoints{Matches: 1, Points: 30, OpponentPoints: 10}},
+			want:   0.75,
+		},
+		"NotPlayed": {
+			reason: "A season with no points should have a zero points percentage.",
+			want:   0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.season.PointsPct()); diff != "" {
+				t.Errorf("\n%s\nPointsPct(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package simulate plays out matches between two teams many times, sampling
+// each player's score from the scores they've posted before, to estimate how
+// likely each team is to win.
+package simulate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// DefaultSimulations is how many matches are simulated by default.
+const DefaultSimulations = 10000
+
+// Store is the set of queries needed to simulate a match.
+type Store interface {
+	matchup.Store
+
+	GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error)
+	GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+	ListPlayerMachineScores(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error)
+}
+
+// A Game is one game the simulated match plays in every simulation.
+type Game struct {
+	MachineKey   string
+	MachineName  string
+	Team1Players []string // Empty names are substitutes, who score like the league.
+	Team2Players []string
+}
+
+// A Round is one round of the simulated match.
+type Round struct {
+	Number  int
+	Doubles bool
+	Picker  string // Key of the team that picks the round's machines.
+	Games   []Game
+}
+
+// An Outcome is how many simulated matches ended with a point spread.
+type Outcome struct {
+	Spread float64 // Team 1's points minus team 2's.
+	Count  int
+}
+
+// Result is the output of a simulation.
+type Result struct {
+	Venue  string
+	Team1  string
+	Team2  string
+	Home   string
+	Rounds []Round
+
+	Simulations int
+	Team1Wins   int
+	Team2Wins   int
+	Ties        int
+	Team1Points float64   // Mean points per match.
+	Team2Points float64   // Mean points per match.
+	Outcomes    []Outcome // Ordered by spread, team 2's biggest win first.
+}
+
+// Team1WinProbability returns the fraction of simulated matches team 1 won,
+// counting ties as half a win.
+func (r *Result) Team1WinProbability() float64 {
+	if r.Simulations == 0 {
+		return 0
+	}
+	return (float64(r.Team1Wins) + float64(r.Ties)/2) / float64(r.Simulations)
+}
+
+// Spread returns team 1's mean points minus team 2's.
+func (r *Result) Spread() float64 {
+	return r.Team1Points - r.Team2Points
+}
+
+// SpreadPercentile returns the point spread that the supplied fraction of
+// simulated matches, from 0 to 1, ended at or below.
+func (r *Result) SpreadPercentile(p float64) float64 {
+	want := p * float64(r.Simulations)
+	seen := 0
+	for _, o := range r.Outcomes {
+		seen += o.Count
+		if float64(seen) >= want {
+			return o.Spread
+		}
+	}
+	if len(r.Outcomes) == 0 {
+		return 0
+	}
+	return r.Outcomes[len(r.Outcomes)-1].Spread
+}
+
+// Option configures a simulation.
+type Option func(*Options)
+
+// Options holds optional parameters for a simulation.
+type Options struct {
+	formats     league.Formats
+	home        string
+	simulations int
+	rng         *rand.Rand
+}
+
+// WithFormats uses the latest of the supplied league formats to decide how
+// many rounds the match has, and which are doubles, rather than the built-in
+// formats.
+func WithFormats(f league.Formats) Option {
+	return func(o *Options) {
+		o.formats = f
+	}
+}
+
+// HomeTeam simulates the match with the supplied team at home, rather than
+// team 1. The home team picks machines in even rounds.
+func HomeTeam(key string) Option {
+	return func(o *Options) {
+		o.home = key
+	}
+}
+
+// Simulations sets how many matches to simulate, rather than
+// DefaultSimulations.
+func Simulations(n int) Option {
+	return func(o *Options) {
+		o.simulations = n
+	}
+}
+
+// WithSeed seeds the simulation's random numbers, so it's repeatable.
+func WithSeed(seed uint64) Option {
+	return func(o *Options) {
+		o.rng = rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // Simulations don't need secure random numbers.
+	}
+}
+
+// Analyze simulates a match between two teams at a venue.
+//
+// Every simulation plays the same match. Each round's picking team picks the
+// machines it has the biggest matchup edges on, a different machine for each
+// game in the round. For each game, in order, both teams play their available
+// players with the highest P50 on the machine, and each player plays once per
+// round. Then each simulation samples each player's score from every score
+// they've posted on the machine, or from the league's scores on it if they
+// haven't played it, and awards points: all of a singles game's points to the
+// higher score, and in doubles a point for each opponent each player beats
+// plus a point for the higher combined score. Tied scores split the points.
+func Analyze(ctx context.Context, s Store, venue, team1, team2 string, opts ...Option) (*Result, error) {
+	o := Options{formats: league.Defaults(), home: team1, simulations: DefaultSimulations}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.rng == nil {
+		o.rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec // Simulations don't need secure random numbers.
+	}
+	if o.simulations < 1 {
+		return nil, errors.New("simulations must be at least 1")
+	}
+
+	mr, err := matchup.Analyze(ctx, s, venue, team1, team2, matchup.WithFormats(o.formats))
+	if err != nil {
+		return nil, fmt.Errorf("matchup %s vs %s: %w", team1, team2, err)
+	}
+	if len(mr.Machines) == 0 {
+		return nil, fmt.Errorf("neither %s nor %s has played any of %s's machines", team1, team2, venue)
+	}
+
+	leagueScores, err := s.GetLeagueScores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load league scores: %w", err)
+	}
+
+	teams := make(map[string]*team, 2)
+	for _, key := range []string{team1, team2} {
+		t, err := loadTeam(ctx, s, key)
+		if err != nil {
+			return nil, err
+		}
+		teams[key] = t
+	}
+
+	r := &Result{Venue: venue, Team1: team1, Team2: team2, Home: o.home, Simulations: o.simulations}
+	r.Rounds = plan(mr, o.formats.Latest(), o.home, teams)
+
+	// Each player's scores on each machine they play, or the league's if they
+	// haven't played it.
+	type slot struct {
+		team1, team2 [][]int64
+	}
+	slots := make([][]slot, len(r.Rounds))
+	for i, round := range r.Rounds {
+		for _, g := range round.Games {
+			var sl slot
+			for _, p := range g.Team1Players {
+				sl.team1 = append(sl.team1, teams[team1].distribution(p, g.MachineKey, leagueScores))
+			}
+			for _, p := range g.Team2Players {
+				sl.team2 = append(sl.team2, teams[team2].distribution(p, g.MachineKey, leagueScores))
+			}
+			slots[i] = append(slots[i], sl)
+		}
+	}
+
+	spreads := make(map[float64]int)
+	for range o.simulations {
+		var p1, p2 float64
+		for i := range slots {
+			for _, sl := range slots[i] {
+				a, b := points(sample(o.rng, sl.team1), sample(o.rng, sl.team2), r.Rounds[i].Doubles, o.formats.Latest().Points(r.Rounds[i].Number))
+				p1 += a
+				p2 += b
+			}
+		}
+		r.Team1Points += p1
+		r.Team2Points += p2
+		switch {
+		case p1 > p2:
+			r.Team1Wins++
+		case p2 > p1:
+			r.Team2Wins++
+		default:
+			r.Ties++
+		}
+		spreads[p1-p2]++
+	}
+	r.Team1Points /= float64(o.simulations)
+	r.Team2Points /= float64(o.simulations)
+
+	for spread, n := range spreads {
+		r.Outcomes = append(r.Outcomes, Outcome{Spread: spread, Count: n})
+	}
+	slices.SortFunc(r.Outcomes, func(a, b Outcome) int {
+		return cmp.Compare(a.Spread, b.Spread)
+	})
+	return r, nil
+}
+
+// A team is a team's current roster and every score they've posted, by
+// player then machine.
+type team struct {
+	players []string
+	scores  map[string]map[string][]int64
+}
+
+func loadTeam(ctx context.Context, s Store, key string) (*team, error) {
+	attendance, err := s.GetTeamAttendance(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("load %s's roster: %w", key, err)
+	}
+	t := &team{scores: make(map[string]map[string][]int64)}
+	for name := range attendance {
+		t.players = append(t.players, name)
+	}
+	slices.Sort(t.players)
+
+	for _, name := range t.players {
+		scores, err := s.ListPlayerMachineScores(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("load %s's scores: %w", name, err)
+		}
+		t.scores[name] = make(map[string][]int64)
+		for _, sc := range scores {
+			t.scores[name][sc.MachineKey] = append(t.scores[name][sc.MachineKey], sc.Score)
+		}
+	}
+	return t, nil
+}
+
+// p50 returns a player's lower median score on a machine, or zero if they
+// haven't played it.
+func (t *team) p50(player, machine string) int64 {
+	scores := slices.Clone(t.scores[player][machine])
+	if len(scores) == 0 {
+		return 0
+	}
+	slices.Sort(scores)
+	return scores[(len(scores)-1)/2]
+}
+
+// distribution returns the scores to sample a player's score on a machine
+// from.
+func (t *team) distribution(player, machine string, league map[string]db.LeagueScores) []int64 {
+	if scores := t.scores[player][machine]; len(scores) > 0 {
+		return scores
+	}
+	return league[machine]
+}
+
+// best returns the n available players with the highest P50 on a machine,
+// and marks them unavailable. Slots without an available player are filled by
+// substitutes, whose names are empty.
+func (t *team) best(machine string, n int, used map[string]bool) []string {
+	available := make([]string, 0, len(t.players))
+	for _, p := range t.players {
+		if !used[p] {
+			available = append(available, p)
+		}
+	}
+	slices.SortStableFunc(available, func(a, b string) int {
+		return cmp.Compare(t.p50(b, machine), t.p50(a, machine))
+	})
+
+	out := make([]string, n)
+	for i := range out {
+		if i < len(available) {
+			out[i] = available[i]
+			used[available[i]] = true
+		}
+	}
+	return out
+}
+
+// plan decides which machines are played in each round, and who plays them.
+func plan(mr *matchup.Result, f league.Format, home string, teams map[string]*team) []Round {
+	rounds := make([]Round, f.Rounds)
+	for i := range rounds {
+		n := i + 1
+		picker := mr.Team1
+		if league.HomePicks(n) != (home == mr.Team1) {
+			picker = mr.Team2
+		}
+		rounds[i] = Round{Number: n, Doubles: f.IsDoubles(n), Picker: picker}
+
+		// Machines are sorted by edge, team 1's best first. A venue with
+		// fewer machines than games reuses machines.
+		players := 1
+		if rounds[i].Doubles {
+			players = 2
+		}
+		used := map[string]map[string]bool{mr.Team1: {}, mr.Team2: {}}
+		for g := range f.Games(n) {
+			m := mr.Machines[g%len(mr.Machines)]
+			if picker == mr.Team2 {
+				m = mr.Machines[len(mr.Machines)-1-g%len(mr.Machines)]
+			}
+			rounds[i].Games = append(rounds[i].Games, Game{
+				MachineKey:   m.MachineKey,
+				MachineName:  m.MachineName,
+				Team1Players: teams[mr.Team1].best(m.MachineKey, players, used[mr.Team1]),
+				Team2Players: teams[mr.Team2].best(m.MachineKey, players, used[mr.Team2]),
+			})
+		}
+	}
+	return rounds
+}
+
+// sample returns a score sampled from each supplied distribution. Players
+// with no scores to sample from score zero.
+func sample(rng *rand.Rand, distributions [][]int64) []int64 {
+	scores := make([]int64, len(distributions))
+	for i, d := range distributions {
+		if len(d) > 0 {
+			scores[i] = d[rng.IntN(len(d))]
+		}
+	}
+	return scores
+}
+
+// points returns the points each team wins in a game worth the supplied
+// points.
+func points(team1, team2 []int64, doubles bool, worth int) (float64, float64) {
+	// split awards a point, or several, to the higher score.
+	split := func(a, b int64, pts float64) (float64, float64) {
+		switch {
+		case a > b:
+			return pts, 0
+		case b > a:
+			return 0, pts
+		default:
+			return pts / 2, pts / 2
+		}
+	}
+
+	if !doubles {
+		return split(team1[0], team2[0], float64(worth))
+	}
+
+	var p1, p2, sum1, sum2 float64
+	for _, a := range team1 {
+		sum1 += float64(a)
+		for _, b := range team2 {
+			x, y := split(a, b, 1)
+			p1, p2 = p1+x, p2+y
+		}
+	}
+	for _, b := range team2 {
+		sum2 += float64(b)
+	}
+	// The combined score's point is whatever the head-to-heads didn't award.
+	bonus := float64(worth) - float64(len(team1)*len(team2))
+	switch {
+	case sum1 > sum2:
+		p1 += bonus
+	case sum2 > sum1:
+		p2 += bonus
+	default:
+		p1, p2 = p1+bonus/2, p2+bonus/2
+	}
+	return p1, p2
+}
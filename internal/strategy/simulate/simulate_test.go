@@ -0,0 +1,262 @@
+package simulate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/league"
+)
+
+type MockStore struct {
+	MockGetLeagueScores         func(ctx context.Context) (map[string]db.LeagueScores, error)
+	MockGetMachineNames         func(ctx context.Context) (map[string]string, error)
+	MockGetTeamAttendance       func(ctx context.Context, teamKey string) (map[string]db.Attendance, error)
+	MockGetTeamMachineStats     func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines        func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListPlayerMachineScores func(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error)
+}
+
+func (m *MockStore) GetLeagueScores(ctx context.Context) (map[string]db.LeagueScores, error) {
+	return m.MockGetLeagueScores(ctx)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamAttendance(ctx context.Context, teamKey string) (map[string]db.Attendance, error) {
+	return m.MockGetTeamAttendance(ctx, teamKey)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListPlayerMachineScores(ctx context.Context, playerName string) ([]db.PlayerMachineScore, error) {
+	return m.MockListPlayerMachineScores(ctx, playerName)
+}
+
+func TestAnalyze(t *testing.T) {
+	// The teams' stats give TTT the edge on TZ and KNR the edge on MM, though
+	// Carol always outscores Alice on TZ. Players without scores on a machine
+	// play like the league, which always scores 50.
+	scores := map[string][]db.PlayerMachineScore{
+		"Alice": {{MachineKey: "TZ", Score: 300}},
+		"Bob":   {{MachineKey: "MM", Score: 100}},
+		"Carol": {{MachineKey: "TZ", Score: 400}},
+		"Dave":  {{MachineKey: "MM", Score: 300}},
+	}
+	stats := map[string][]db.TeamMachineStats{
+		"TTT": {
+			{MachineKey: "TZ", Games: 1, P50Score: 300, LikelyPlayers: []db.LikelyPlayer{{Name: "Alice", Games: 1, P50Score: 300}}},
+			{MachineKey: "MM", Games: 1, P50Score: 100, LikelyPlayers: []db.LikelyPlayer{{Name: "Bob", Games: 1, P50Score: 100}}},
+		},
+		"KNR": {
+			{MachineKey: "TZ", Games: 1, P50Score: 100, LikelyPlayers: []db.LikelyPlayer{{Name: "Carol", Games: 1, P50Score: 100}}},
+			{MachineKey: "MM", Games: 1, P50Score: 300, LikelyPlayers: []db.LikelyPlayer{{Name: "Dave", Games: 1, P50Score: 300}}},
+		},
+	}
+	rosters := map[string]map[string]db.Attendance{
+		"TTT": {"Alice": {}, "Bob": {}},
+		"KNR": {"Carol": {}, "Dave": {}},
+	}
+
+	newStore := func(machines map[string]bool) *MockStore {
+		return &MockStore{
+			MockGetLeagueScores: func(_ context.Context) (map[string]db.LeagueScores, error) {
+				return map[string]db.LeagueScores{"TZ": {50}, "MM": {50}}, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TZ": "Twilight Zone", "MM": "Medieval Madness"}, nil
+			},
+			MockGetTeamAttendance: func(_ context.Context, teamKey string) (map[string]db.Attendance, error) {
+				return rosters[teamKey], nil
+			},
+			MockGetTeamMachineStats: func(_ context.Context, teamKey, _ string, _ []int) ([]db.TeamMachineStats, error) {
+				return stats[teamKey], nil
+			},
+			MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
+				return machines, nil
+			},
+			MockListPlayerMachineScores: func(_ context.Context, playerName string) ([]db.PlayerMachineScore, error) {
+				return scores[playerName], nil
+			},
+		}
+	}
+
+	tz := Game{MachineKey: "TZ", MachineName: "Twilight Zone"}
+	mm := Game{MachineKey: "MM", MachineName: "Medieval Madness"}
+	with := func(g Game, team1, team2 []string) Game {
+		g.Team1Players, g.Team2Players = team1, team2
+		return g
+	}
+	// games returns n games alternating between two machines. Each team has
+	// only two players, so games after the played ones have substitutes.
+	games := func(n, players int, first, second Game, played ...Game) []Game {
+		out := played
+		for i := len(played); i < n; i++ {
+			g := first
+			if i%2 == 1 {
+				g = second
+			}
+			out = append(out, with(g, make([]string, players), make([]string, players)))
+		}
+		return out
+	}
+
+	singles := league.Formats{1: {Rounds: 1}}
+	doubles := league.Formats{1: {Rounds: 1, DoublesRounds: []int{1}}}
+
+	type args struct {
+		machines map[string]bool
+		opts     []Option
+	}
+	type want struct {
+		r   *Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Singles": {
+			reason: "The away team should pick its best machines, and each team should play its best available players on them. Substitutes play like the league.",
+			args: args{
+				machines: map[string]bool{"TZ": true, "MM": true},
+				opts:     []Option{WithFormats(singles), Simulations(100), WithSeed(1)},
+			},
+			want: want{r: &Result{
+				Venue: "GPA",
+				Team1: "TTT",
+				Team2: "KNR",
+				Home:  "TTT",
+				Rounds: []Round{{
+					Number: 1,
+					Picker: "KNR",
+					Games:  games(league.SinglesGames, 1, mm, tz, with(mm, []string{"Bob"}, []string{"Dave"}), with(tz, []string{"Alice"}, []string{"Carol"})),
+				}},
+				Simulations: 100,
+				Team2Wins:   100,
+				Team1Points: 7.5,
+				Team2Points: 13.5,
+				Outcomes:    []Outcome{{Spread: -6, Count: 100}},
+			}},
+		},
+		"Doubles": {
+			reason: "Doubles games should award a point for each head-to-head and one for the higher combined score.",
+			args: args{
+				machines: map[string]bool{"TZ": true, "MM": true},
+				opts:     []Option{WithFormats(doubles), HomeTeam("KNR"), Simulations(100), WithSeed(1)},
+			},
+			want: want{r: &Result{
+				Venue: "GPA",
+				Team1: "TTT",
+				Team2: "KNR",
+				Home:  "KNR",
+				Rounds: []Round{{
+					Number:  1,
+					Doubles: true,
+					Picker:  "TTT",
+					Games:   games(league.DoublesGames, 2, tz, mm, with(tz, []string{"Alice", "Bob"}, []string{"Carol", "Dave"})),
+				}},
+				Simulations: 100,
+				Team2Wins:   100,
+				Team1Points: 9,
+				Team2Points: 11,
+				Outcomes:    []Outcome{{Spread: -2, Count: 100}},
+			}},
+		},
+		"NoMachines": {
+			reason: "A venue neither team has played any machines at can't be simulated.",
+			args: args{
+				machines: map[string]bool{"AFM": true},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"NoSimulations": {
+			reason: "At least one match must be simulated.",
+			args: args{
+				machines: map[string]bool{"TZ": true},
+				opts:     []Option{Simulations(0)},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), newStore(tc.args.machines), "GPA", "TTT", "KNR", tc.args.opts...)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPoints(t *testing.T) {
+	type want struct {
+		team1 float64
+		team2 float64
+	}
+
+	cases := map[string]struct {
+		reason  string
+		team1   []int64
+		team2   []int64
+		doubles bool
+		want    want
+	}{
+		"SinglesWin": {
+			reason: "The higher singles score should win all three points.",
+			team1:  []int64{200},
+			team2:  []int64{100},
+			want:   want{team1: 3},
+		},
+		"SinglesTie": {
+			reason: "Tied singles scores should split the points.",
+			team1:  []int64{100},
+			team2:  []int64{100},
+			want:   want{team1: 1.5, team2: 1.5},
+		},
+		"DoublesSweep": {
+			reason:  "A team that beats both opponents with both players should win all five points.",
+			team1:   []int64{100, 100},
+			team2:   []int64{50, 50},
+			doubles: true,
+			want:    want{team1: 5},
+		},
+		"DoublesSplit": {
+			reason:  "One great score can win the combined point without winning most head-to-heads.",
+			team1:   []int64{1000, 10},
+			team2:   []int64{100, 100},
+			doubles: true,
+			want:    want{team1: 3, team2: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			worth := league.SinglesPoints
+			if tc.doubles {
+				worth = league.DoublesPoints
+			}
+			team1, team2 := points(tc.team1, tc.team2, tc.doubles, worth)
+			if team1 != tc.want.team1 || team2 != tc.want.team2 {
+				t.Errorf("\n%s\npoints(...): want %v-%v, got %v-%v", tc.reason, tc.want.team1, tc.want.team2, team1, team2)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package streaks flags players who are currently playing well above or below
+// their usual level.
+package streaks
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+const (
+	// DefaultWeeks is how many of a player's most recent weeks make a streak.
+	DefaultWeeks = 3
+
+	// Threshold is how many standard errors a player's recent median must be
+	// from their career median to count as a streak.
+	Threshold = 1.5
+
+	minRecentGames = 4  // Fewer recent games is noise, not a streak.
+	minCareerGames = 10 // Fewer career games doesn't establish a baseline.
+)
+
+// Store is the set of queries needed to find streaks.
+type Store interface {
+	ListTeamRosterScores(ctx context.Context, teamKey string) ([]db.RosterScore, error)
+}
+
+// Streak is a player on a hot or cold streak. Medians are percentile ranks
+// against the league on each machine, 0-1.
+type Streak struct {
+	Name   string
+	Games  int     // Games in the streak.
+	Recent float64 // Median percentile rank in the player's recent weeks.
+	Career float64 // Median percentile rank before their recent weeks.
+	Z      float64 // Standard errors between the recent and career medians.
+}
+
+// Result is the output of a streaks query.
+type Result struct {
+	Team  string
+	Weeks int      // Weeks that make a streak.
+	Hot   []Streak // Hottest first.
+	Cold  []Streak // Coldest first.
+}
+
+// Option configures a streaks query.
+type Option func(*Options)
+
+// Options holds optional parameters for a streaks query.
+type Options struct {
+	weeks int
+}
+
+// OverWeeks considers the player's last n weeks, rather than DefaultWeeks.
+func OverWeeks(n int) Option {
+	return func(o *Options) {
+		o.weeks = n
+	}
+}
+
+// Analyze finds the players on a team's current roster whose median
+// percentile rank over their last few weeks played this season is at least
+// Threshold standard errors from their career median before those weeks.
+func Analyze(ctx context.Context, s Store, team string, opts ...Option) (*Result, error) {
+	o := Options{weeks: DefaultWeeks}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	scores, err := s.ListTeamRosterScores(ctx, team)
+	if err != nil {
+		return nil, fmt.Errorf("load roster scores: %w", err)
+	}
+
+	// Only players who've played this season can be on a streak.
+	current := 0
+	for _, sc := range scores {
+		current = max(current, sc.Season)
+	}
+
+	r := &Result{Team: team, Weeks: o.weeks}
+	for i := 0; i < len(scores); {
+		j := i
+		for j < len(scores) && scores[j].PlayerName == scores[i].PlayerName {
+			j++
+		}
+		st, ok := streak(scores[i:j], current, o.weeks)
+		i = j
+		if !ok {
+			continue
+		}
+		switch {
+		case st.Z >= Threshold:
+			r.Hot = append(r.Hot, st)
+		case st.Z <= -Threshold:
+			r.Cold = append(r.Cold, st)
+		}
+	}
+
+	slices.SortFunc(r.Hot, func(a, b Streak) int {
+		return cmp.Or(cmp.Compare(b.Z, a.Z), cmp.Compare(a.Name, b.Name))
+	})
+	slices.SortFunc(r.Cold, func(a, b Streak) int {
+		return cmp.Or(cmp.Compare(a.Z, b.Z), cmp.Compare(a.Name, b.Name))
+	})
+
+	return r, nil
+}
+
+// streak compares a player's last weeks to the rest of their career. Scores
+// must be ordered oldest first. It returns false if the player hasn't played
+// in the current season, or hasn't played enough games to compare.
+func streak(scores []db.RosterScore, current, weeks int) (Streak, bool) {
+	last := scores[len(scores)-1]
+	if last.Season != current {
+		return Streak{}, false
+	}
+
+	// Walk back until we've seen the player's last n distinct weeks.
+	split := len(scores)
+	seen := 0
+	for split > 0 {
+		sc := scores[split-1]
+		if split == len(scores) || sc.Season != scores[split].Season || sc.Week != scores[split].Week {
+			if seen == weeks {
+				break
+			}
+			seen++
+		}
+		split--
+	}
+
+	career, recent := ranks(scores[:split]), ranks(scores[split:])
+	if len(recent) < minRecentGames || len(career) < minCareerGames {
+		return Streak{}, false
+	}
+
+	sd := stddev(career)
+	if sd == 0 {
+		return Streak{}, false
+	}
+
+	st := Streak{
+		Name:   last.PlayerName,
+		Games:  len(recent),
+		Recent: median(recent),
+		Career: median(career),
+	}
+	st.Z = (st.Recent - st.Career) / (sd / math.Sqrt(float64(len(recent))))
+	return st, true
+}
+
+func ranks(scores []db.RosterScore) []float64 {
+	r := make([]float64, len(scores))
+	for i, sc := range scores {
+		r[i] = sc.PercentRank
+	}
+	return r
+}
+
+// median returns the median of the supplied values. Like the database
+// queries, it takes the lower middle for even counts.
+func median(v []float64) float64 {
+	s := slices.Clone(v)
+	slices.Sort(s)
+	return s[(len(s)+1)/2-1]
+}
+
+func stddev(v []float64) float64 {
+	mean := 0.0
+	for _, x := range v {
+		mean += x
+	}
+	mean /= float64(len(v))
+
+	ss := 0.0
+	for _, x := range v {
+		ss += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(ss / float64(len(v)-1))
+}
@@ -0,0 +1,152 @@
+package streaks
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+)
+
+type MockStore struct {
+	MockListTeamRosterScores func(ctx context.Context, teamKey string) ([]db.RosterScore, error)
+}
+
+func (m *MockStore) ListTeamRosterScores(ctx context.Context, teamKey string) ([]db.RosterScore, error) {
+	return m.MockListTeamRosterScores(ctx, teamKey)
+}
+
+// career returns twelve steady games over four weeks of season 22, with a
+// median percentile rank of 0.5.
+func career(name string) []db.RosterScore {
+	var scores []db.RosterScore
+	for week := 1; week <= 4; week++ {
+		for _, pct := range []float64{0.4, 0.5, 0.6} {
+			scores = append(scores, db.RosterScore{PlayerName: name, Season: 22, Week: week, PercentRank: pct})
+		}
+	}
+	return scores
+}
+
+// recent returns two games at the supplied percentile rank in each of the
+// supplied weeks of season 23.
+func recent(name string, pct float64, weeks ...int) []db.RosterScore {
+	var scores []db.RosterScore
+	for _, week := range weeks {
+		for range 2 {
+			scores = append(scores, db.RosterScore{PlayerName: name, Season: 23, Week: week, PercentRank: pct})
+		}
+	}
+	return scores
+}
+
+func TestAnalyze(t *testing.T) {
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"HotAndCold": {
+			reason: "Players well above their career median should be hot, and well below it cold.",
+			args: args{
+				store: &MockStore{
+					MockListTeamRosterScores: func(_ context.Context, _ string) ([]db.RosterScore, error) {
+						return slices.Concat(
+							career("Alice"), recent("Alice", 0.7, 1, 2, 3),
+							career("Bob"), recent("Bob", 0.3, 1, 2, 3),
+							career("Carol"), recent("Carol", 0.5, 1, 2, 3),
+						), nil
+					},
+				},
+			},
+			want: want{result: &Result{
+				Team:  "CRA",
+				Weeks: 3,
+				Hot:   []Streak{{Name: "Alice", Games: 6, Recent: 0.7, Career: 0.5, Z: 5.744562646538}},
+				Cold:  []Streak{{Name: "Bob", Games: 6, Recent: 0.3, Career: 0.5, Z: -5.744562646538}},
+			}},
+		},
+		"OverWeeks": {
+			reason: "Older weeks this season should count toward the career median when considering fewer weeks.",
+			args: args{
+				store: &MockStore{
+					MockListTeamRosterScores: func(_ context.Context, _ string) ([]db.RosterScore, error) {
+						return slices.Concat(career("Alice"), recent("Alice", 0.7, 1, 2, 3)), nil
+					},
+				},
+				opts: []Option{OverWeeks(2)},
+			},
+			want: want{result: &Result{
+				Team:  "CRA",
+				Weeks: 2,
+				Hot:   []Streak{{Name: "Alice", Games: 4, Recent: 0.7, Career: 0.5, Z: 3.741657386774}},
+			}},
+		},
+		"NotEnoughGames": {
+			reason: "Players without enough recent or career games shouldn't be on a streak.",
+			args: args{
+				store: &MockStore{
+					MockListTeamRosterScores: func(_ context.Context, _ string) ([]db.RosterScore, error) {
+						return slices.Concat(
+							career("Alice"), recent("Alice", 0.9, 1),
+							recent("Bob", 0.9, 1, 2, 3, 4, 5, 6, 7, 8),
+						), nil
+					},
+				},
+			},
+			want: want{result: &Result{Team: "CRA", Weeks: 3}},
+		},
+		"NotPlayingThisSeason": {
+			reason: "Players who haven't played this season shouldn't be on a streak.",
+			args: args{
+				store: &MockStore{
+					MockListTeamRosterScores: func(_ context.Context, _ string) ([]db.RosterScore, error) {
+						return slices.Concat(
+							career("Alice"), recent("Alice", 0.5, 1, 2, 3),
+							career("Bob"),
+						), nil
+					},
+				},
+			},
+			want: want{result: &Result{Team: "CRA", Weeks: 3}},
+		},
+		"ListTeamRosterScoresError": {
+			reason: "An error loading scores should be returned.",
+			args: args{
+				store: &MockStore{
+					MockListTeamRosterScores: func(_ context.Context, _ string) ([]db.RosterScore, error) {
+						return nil, errors.New("boom")
+					},
+				},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "CRA", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.result, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want result, +got result:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Package week summarizes every match in a week of the schedule.
+package week
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// Store is the set of queries needed for a week summary.
+type Store interface {
+	matchup.Store
+
+	ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+// Match summarizes one match's matchup at its venue.
+type Match struct {
+	db.ScheduleMatch
+
+	HomeEdges int // Machines at the venue where the home team has the edge.
+	AwayEdges int // Machines at the venue where the away team has the edge.
+
+	// The machine each team has its biggest edge on, if it has one.
+	HomeKey *matchup.MachineMatchup
+	AwayKey *matchup.MachineMatchup
+}
+
+// Analyzed returns true if the match has a venue, and so a matchup.
+func (m Match) Analyzed() bool {
+	return m.VenueKey != ""
+}
+
+// Favorite returns the key of the team with the edge on more machines at the
+// venue, or an empty string if neither has.
+func (m Match) Favorite() string {
+	switch {
+	case m.HomeEdges > m.AwayEdges:
+		return m.HomeTeamKey
+	case m.AwayEdges > m.HomeEdges:
+		return m.AwayTeamKey
+	default:
+		return ""
+	}
+}
+
+// Result is the output of a week summary.
+type Result struct {
+	Week    int // Zero if there are no matches to summarize.
+	Matches []Match
+}
+
+// Option configures a week summary.
+type Option func(*Options)
+
+// Options holds optional parameters for a week summary.
+type Options struct {
+	week int
+}
+
+// InWeek summarizes the supplied week of the current season, rather than the
+// next week on the schedule.
+func InWeek(n int) Option {
+	return func(o *Options) {
+		o.week = n
+	}
+}
+
+// Analyze runs a matchup for every match in the first week of the schedule on
+// or after the supplied date, and summarizes each. Matches without a venue are
+// included but not analyzed.
+func Analyze(ctx context.Context, s Store, date string, opts ...Option) (*Result, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	after := date
+	if o.week != 0 {
+		after = ""
+	}
+	sched, err := s.ListSchedule(ctx, after)
+	if err != nil {
+		return nil, fmt.Errorf("load schedule: %w", err)
+	}
+
+	r := &Result{Week: o.week}
+	if r.Week == 0 && len(sched) > 0 {
+		r.Week = sched[0].Week
+	}
+
+	for _, sm := range sched {
+		if sm.Week != r.Week {
+			continue
+		}
+		m := Match{ScheduleMatch: sm}
+		if !m.Analyzed() {
+			r.Matches = append(r.Matches, m)
+			continue
+		}
+
+		mr, err := matchup.Analyze(ctx, s, sm.VenueKey, sm.HomeTeamKey, sm.AwayTeamKey)
+		if err != nil {
+			return nil, fmt.Errorf("analyze %s: %w", sm.Key, err)
+		}
+		summarize(&m, mr)
+		r.Matches = append(r.Matches, m)
+	}
+
+	if len(r.Matches) == 0 {
+		r.Week = 0
+	}
+	return r, nil
+}
+
+// summarize counts each team's edges and picks out its key machine. Machines
+// are sorted by edge descending, so the home team's key machine is first and
+// the away team's last.
+func summarize(m *Match, r *matchup.Result) {
+	m.HomeEdges = len(r.Analysis.Team1Advantages)
+	m.AwayEdges = len(r.Analysis.Team2Advantages)
+	if n := len(r.Machines); n > 0 {
+		if first := r.Machines[0]; first.Edge > 0 {
+			m.HomeKey = &first
+		}
+		if last := r.Machines[n-1]; last.Edge < 0 {
+			m.AwayKey = &last
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package week
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+type MockStore struct {
+	MockGetMachineNames     func(ctx context.Context) (map[string]string, error)
+	MockGetTeamMachineStats func(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error)
+	MockGetVenueMachines    func(ctx context.Context, venueKey string, season int) (map[string]bool, error)
+	MockListSchedule        func(ctx context.Context, after string) ([]db.ScheduleMatch, error)
+}
+
+func (m *MockStore) GetMachineNames(ctx context.Context) (map[string]string, error) {
+	return m.MockGetMachineNames(ctx)
+}
+
+func (m *MockStore) GetTeamMachineStats(ctx context.Context, teamKey, venueKey string, seasons []int) ([]db.TeamMachineStats, error) {
+	return m.MockGetTeamMachineStats(ctx, teamKey, venueKey, seasons)
+}
+
+func (m *MockStore) GetVenueMachines(ctx context.Context, venueKey string, season int) (map[string]bool, error) {
+	return m.MockGetVenueMachines(ctx, venueKey, season)
+}
+
+func (m *MockStore) ListSchedule(ctx context.Context, after string) ([]db.ScheduleMatch, error) {
+	return m.MockListSchedule(ctx, after)
+}
+
+func TestAnalyze(t *testing.T) {
+	craPYC := db.ScheduleMatch{Key: "mnp-23-1-CRA-PYC", Week: 1, Date: "2025-01-06", HomeTeamKey: "CRA", AwayTeamKey: "PYC", VenueKey: "STN"}
+	knrTTT := db.ScheduleMatch{Key: "mnp-23-1-KNR-TTT", Week: 1, Date: "2025-01-06", HomeTeamKey: "KNR", AwayTeamKey: "TTT"}
+	pycKNR := db.ScheduleMatch{Key: "mnp-23-2-PYC-KNR", Week: 2, Date: "2025-01-13", HomeTeamKey: "PYC", AwayTeamKey: "KNR"}
+
+	likely := func(p50 float64) []db.LikelyPlayer {
+		return []db.LikelyPlayer{{Name: "Someone", P50Score: p50}}
+	}
+	store := func(sched ...db.ScheduleMatch) *MockStore {
+		return &MockStore{
+			MockListSchedule: func(_ context.Context, after string) ([]db.ScheduleMatch, error) {
+				var out []db.ScheduleMatch
+				for _, m := range sched {
+					if m.Date >= after {
+						out = append(out, m)
+					}
+				}
+				return out, nil
+			},
+			MockGetVenueMachines: func(_ context.Context, _ string, _ int) (map[string]bool, error) {
+				return map[string]bool{"TAF": true, "MM": true, "TZ": true}, nil
+			},
+			MockGetMachineNames: func(_ context.Context) (map[string]string, error) {
+				return map[string]string{"TAF": "The Addams Family", "MM": "Medieval Madness", "TZ": "Twilight Zone"}, nil
+			},
+			MockGetTeamMachineStats: func(_ context.Context, team, _ string, _ []int) ([]db.TeamMachineStats, error) {
+				if team == "CRA" {
+					return []db.TeamMachineStats{
+						{MachineKey: "TAF", LikelyPlayers: likely(100)},
+						{MachineKey: "MM", LikelyPlayers: likely(50)},
+						{MachineKey: "TZ", LikelyPlayers: likely(10)},
+					}, nil
+				}
+				return []db.TeamMachineStats{
+					{MachineKey: "TAF", LikelyPlayers: likely(50)},
+					{MachineKey: "MM", LikelyPlayers: likely(100)},
+					{MachineKey: "TZ", LikelyPlayers: likely(8)},
+				}, nil
+			},
+		}
+	}
+
+	type args struct {
+		store Store
+		opts  []Option
+	}
+	type want struct {
+		result *Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NextWeek": {
+			reason: "Every match in the next week should be summarized, with the team that has the edge on more machines favored.",
+			args:   args{store: store(craPYC, knrTTT, pycKNR)},
+			want: want{result: &Result{
+				Week: 1,
+				Matches: []Match{
+					{
+						ScheduleMatch: craPYC,
+						HomeEdges:     2,
+						AwayEdges:     1,
+						HomeKey:       &matchup.MachineMatchup{MachineKey: "TAF", MachineName: "The Addams Family", Team1Likely: 100, Team2Likely: 50, Edge: 100},
+						AwayKey:       &matchup.MachineMatchup{MachineKey: "MM", MachineName: "Medieval Madness", Team1Likely: 50, Team2Likely: 100, Edge: -100},
+					},
+					// No venue, so no matchup.
+					{ScheduleMatch: knrTTT},
+				},
+			}},
+		},
+		"InWeek": {
+			reason: "A specific week should be summarized even if it's not the next.",
+			args:   args{store: store(craPYC, knrTTT, pycKNR), opts: []Option{InWeek(2)}},
+			want: want{result: &Result{
+				Week:    2,
+				Matches: []Match{{ScheduleMatch: pycKNR}},
+			}},
+		},
+		"NoMatches": {
+			reason: "A week without matches should have no week number.",
+			args:   args{store: store(craPYC), opts: []Option{InWeek(9)}},
+			want:   want{result: &Result{}},
+		},
+		"ScheduleError": {
+			reason: "An error loading the schedule should be returned.",
+			args: args{store: &MockStore{
+				MockListSchedule: func(_ context.Context, _ string) ([]db.ScheduleMatch, error) {
+					return nil, errors.New("boom")
+				},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Analyze(context.Background(), tc.args.store, "2025-01-01", tc.args.opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nAnalyze(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/negz/mnp/internal/anomaly"
+)
+
+// Data anomalies admin page.
+
+type anomaliesData struct {
+	Checked   bool
+	Anomalies []anomaly.Anomaly
+	Error     string
+}
+
+func (s *Server) handleAnomalies(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	s.render(w, r, s.template.anomalies, anomaliesData{})
+}
+
+func (s *Server) handleAnomaliesCheck(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if !s.authorized(r.FormValue("token")) {
+		s.renderStatus(w, r, http.StatusForbidden, s.template.anomalies, anomaliesData{Error: "Invalid admin token."})
+		return
+	}
+
+	data := anomaliesData{Checked: true}
+	anomalies, err := anomaly.Check(r.Context(), s.store)
+	if err != nil {
+		data.Error = fmt.Sprintf("Error: %v", err)
+	}
+	data.Anomalies = anomalies
+
+	s.render(w, r, s.template.anomalies, data)
+}
@@ -0,0 +1,442 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/strategy/matchup"
+)
+
+// The JSON API is versioned by path prefix, e.g. /api/v1/standings, so bots
+// built against one version keep working as the API grows. Within a version,
+// responses only ever gain fields. Renaming, removing, or retyping a field
+// needs a new version, and the old version's routes are deprecated rather than
+// changed. testdata/api holds recorded responses that every build must stay
+// compatible with.
+
+// apiV1 is the path prefix of version 1 of the JSON API.
+const apiV1 = "/api/v1"
+
+// A deprecation marks an API route that will be removed.
+type deprecation struct {
+	Since  time.Time // When the route was deprecated.
+	Sunset time.Time // When the route will be removed. Zero if not yet decided.
+	Link   string    // Documents the route's replacement. Optional.
+}
+
+// apiDeprecations returns deprecated API routes, keyed by their mux pattern.
+// Deprecate a route when a newer version replaces it, at least a season before
+// its sunset, so bot authors have time to move.
+func apiDeprecations() map[string]deprecation {
+	return map[string]deprecation{}
+}
+
+// withDeprecation wraps an API handler to announce that it's deprecated using
+// the Deprecation (RFC 9745) and Sunset (RFC 8594) headers.
+func withDeprecation(next http.Handler, d deprecation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		if !d.Sunset.IsZero() {
+			w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Link != "" {
+			w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiRoutes registers the JSON API's routes.
+func (s *Server) apiRoutes(mux *http.ServeMux) {
+	deprecations := apiDeprecations()
+	handle := func(pattern string, h http.HandlerFunc) {
+		var handler http.Handler = h
+		if d, ok := deprecations[pattern]; ok {
+			handler = withDeprecation(handler, d)
+		}
+		mux.Handle(pattern, handler)
+	}
+
+	handle("GET "+apiV1+"/standings", s.handleAPIStandings)
+	handle("GET "+apiV1+"/t/{team}/scout", s.handleAPIScout)
+	handle("GET "+apiV1+"/matchup", s.handleAPIMatchup)
+	handle("GET "+apiV1+"/players", s.handleAPIPlayers)
+	handle("GET "+apiV1+"/teams", s.handleAPITeams)
+	handle("GET "+apiV1+"/machines", s.handleAPIMachines)
+	handle("GET "+apiV1+"/sync", s.handleAPISync)
+
+	mux.HandleFunc("GET /api/", func(w http.ResponseWriter, _ *http.Request) {
+		s.writeAPIError(w, http.StatusNotFound, "no such API route")
+	})
+}
+
+// writeJSON writes v as a JSON response.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		s.log.Error("encode JSON response", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) writeAPIError(w http.ResponseWriter, status int, msg string) {
+	s.writeJSON(w, status, apiError{Error: msg})
+}
+
+// Standings.
+
+type apiStandings struct {
+	Season    int           `json:"season"`
+	Standings []apiStanding `json:"standings"`
+}
+
+type apiStanding struct {
+	Team           string  `json:"team"`
+	TeamName       string  `json:"team_name"`
+	Played         int     `json:"played"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	Ties           int     `json:"ties"`
+	Points         float64 `json:"points"`
+	OpponentPoints float64 `json:"opponent_points"`
+}
+
+func (s *Server) handleAPIStandings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	season := 0
+	if v := r.URL.Query().Get("season"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			s.writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid season %q", v))
+			return
+		}
+		season = n
+	}
+
+	if season == 0 {
+		seasons, err := s.store.ListSeasons(ctx)
+		if err != nil {
+			s.log.Error("list seasons", "err", err)
+			s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if len(seasons) > 0 {
+			season = seasons[0]
+		}
+	}
+
+	standings, err := s.store.GetStandings(ctx, season)
+	if err != nil {
+		s.log.Error("get standings", "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	rsp := apiStandings{Season: season, Standings: make([]apiStanding, len(standings))}
+	for i, st := range standings {
+		rsp.Standings[i] = apiStanding{
+			Team:           st.TeamKey,
+			TeamName:       st.TeamName,
+			Played:         st.Played,
+			Wins:           st.Wins,
+			Losses:         st.Losses,
+			Ties:           st.Ties,
+			Points:         st.Points,
+			OpponentPoints: st.OpponentPoints,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Scout.
+
+type apiScout struct {
+	Team      string            `json:"team"`
+	Venue     string            `json:"venue,omitempty"`
+	Machines  []apiScoutMachine `json:"machines"`
+	Strongest []string          `json:"strongest"`
+	Weakest   []string          `json:"weakest"`
+}
+
+type apiScoutMachine struct {
+	Machine       string            `json:"machine"`
+	MachineName   string            `json:"machine_name"`
+	Games         int               `json:"games"`
+	P50Score      float64           `json:"p50_score"`
+	P90Score      float64           `json:"p90_score"`
+	LeagueP50     float64           `json:"league_p50"`
+	LikelyPlayers []apiLikelyPlayer `json:"likely_players"`
+}
+
+type apiLikelyPlayer struct {
+	Name     string  `json:"name"`
+	Games    int     `json:"games"`
+	P50Score float64 `json:"p50_score"`
+}
+
+func (s *Server) handleAPIScout(w http.ResponseWriter, r *http.Request) {
+	team := strings.ToUpper(r.PathValue("team"))
+	venue := strings.ToUpper(r.URL.Query().Get("venue"))
+
+	result, err := s.analyzeScout(r.Context(), team, venue, false)
+	if err != nil {
+		s.log.Error("scout", "team", team, "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(result.GlobalStats) == 0 {
+		s.writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no data for %s", team))
+		return
+	}
+
+	rsp := apiScout{
+		Team:      result.Team,
+		Venue:     result.Venue,
+		Machines:  make([]apiScoutMachine, len(result.GlobalStats)),
+		Strongest: nonNil(result.Analysis.Strongest),
+		Weakest:   nonNil(result.Analysis.Weakest),
+	}
+	for i, m := range result.GlobalStats {
+		players := make([]apiLikelyPlayer, len(m.LikelyPlayers))
+		for j, p := range m.LikelyPlayers {
+			players[j] = apiLikelyPlayer{Name: p.Name, Games: p.Games, P50Score: p.P50Score}
+		}
+		rsp.Machines[i] = apiScoutMachine{
+			Machine:       m.MachineKey,
+			MachineName:   m.MachineName,
+			Games:         m.Games,
+			P50Score:      m.P50Score,
+			P90Score:      m.P90Score,
+			LeagueP50:     m.LeagueP50,
+			LikelyPlayers: players,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Matchup.
+
+type apiMatchup struct {
+	Venue    string              `json:"venue"`
+	Team1    string              `json:"team1"`
+	Team2    string              `json:"team2"`
+	Machines []apiMatchupMachine `json:"machines"`
+}
+
+type apiMatchupMachine struct {
+	Machine     string  `json:"machine"`
+	MachineName string  `json:"machine_name"`
+	Team1P50    float64 `json:"team1_p50"`
+	Team1Likely float64 `json:"team1_likely_p50"`
+	Team2P50    float64 `json:"team2_p50"`
+	Team2Likely float64 `json:"team2_likely_p50"`
+	Edge        float64 `json:"edge"`
+	Confidence  string  `json:"confidence"`
+}
+
+func (s *Server) handleAPIMatchup(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	venue, team1, team2 := strings.ToUpper(q.Get("venue")), strings.ToUpper(q.Get("t1")), strings.ToUpper(q.Get("t2"))
+	if venue == "" || team1 == "" || team2 == "" {
+		s.writeAPIError(w, http.StatusBadRequest, "venue, t1, and t2 are required")
+		return
+	}
+
+	result, err := s.analyzeMatchup(r.Context(), venue, team1, team2)
+	if err != nil {
+		s.log.Error("matchup", "venue", venue, "team1", team1, "team2", team2, "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(result.Machines) == 0 {
+		s.writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no machines found at %s", venue))
+		return
+	}
+
+	rsp := apiMatchup{
+		Venue:    result.Venue,
+		Team1:    result.Team1,
+		Team2:    result.Team2,
+		Machines: make([]apiMatchupMachine, len(result.Machines)),
+	}
+	for i, m := range result.Machines {
+		rsp.Machines[i] = apiMatchupMachine{
+			Machine:     m.MachineKey,
+			MachineName: m.MachineName,
+			Team1P50:    m.Team1P50,
+			Team1Likely: m.Team1Likely,
+			Team2P50:    m.Team2P50,
+			Team2Likely: m.Team2Likely,
+			Edge:        m.Edge,
+			Confidence:  apiConfidence(m.Confidence),
+		}
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Players.
+
+type apiPlayers struct {
+	Players []apiPlayer `json:"players"`
+}
+
+type apiPlayer struct {
+	Name     string `json:"name"`
+	Team     string `json:"team"`
+	TeamName string `json:"team_name"`
+	IPR      int    `json:"ipr,omitempty"`
+}
+
+func (s *Server) handleAPIPlayers(w http.ResponseWriter, r *http.Request) {
+	players, err := s.store.ListPlayers(r.Context(), strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		s.log.Error("list players", "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	rsp := apiPlayers{Players: make([]apiPlayer, len(players))}
+	for i, p := range players {
+		rsp.Players[i] = apiPlayer{Name: p.Name, Team: p.TeamKey, TeamName: p.Team, IPR: p.IPR}
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Teams.
+
+type apiTeams struct {
+	Teams []apiTeam `json:"teams"`
+}
+
+type apiTeam struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Venue string `json:"venue,omitempty"` // Home venue, e.g. "Seattle Tavern and Pool Hall (STN)".
+}
+
+func (s *Server) handleAPITeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := s.store.ListTeams(r.Context(), strings.TrimSpace(r.URL.Query().Get("q")), 0)
+	if err != nil {
+		s.log.Error("list teams", "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	rsp := apiTeams{Teams: make([]apiTeam, len(teams))}
+	for i, t := range teams {
+		rsp.Teams[i] = apiTeam{Key: t.Key, Name: t.Name, Venue: t.Venue}
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Machines.
+
+type apiMachines struct {
+	Machines []apiMachine `json:"machines"`
+}
+
+type apiMachine struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+func (s *Server) handleAPIMachines(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	machines, err := s.store.ListMachines(ctx, strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		s.log.Error("list machines", "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// Only list the machines at the venue, if one was supplied.
+	var at map[string]bool
+	if venue := r.URL.Query().Get("venue"); venue != "" {
+		if at, err = s.store.GetVenueMachines(ctx, venue, 0); err != nil {
+			s.log.Error("get venue machines", "venue", venue, "err", err)
+			s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+
+	rsp := apiMachines{Machines: make([]apiMachine, 0, len(machines))}
+	for _, m := range machines {
+		if at != nil && !at[m.Key] {
+			continue
+		}
+		rsp.Machines = append(rsp.Machines, apiMachine{Key: m.Key, Name: m.Name})
+	}
+	s.writeJSON(w, http.StatusOK, rsp)
+}
+
+// Sync.
+
+type apiSync struct {
+	Commit          string  `json:"commit"`                 // Archive commit last loaded. Empty if never synced.
+	CommitTime      string  `json:"commit_time,omitempty"`  // When that commit was made, RFC 3339.
+	LastSuccess     string  `json:"last_success,omitempty"` // When a sync last succeeded, RFC 3339.
+	LastAttempt     string  `json:"last_attempt,omitempty"` // When a sync last started, RFC 3339.
+	DurationSeconds float64 `json:"duration_seconds"`       // How long the last attempt took.
+	OK              bool    `json:"ok"`                     // Whether the last attempt succeeded.
+	Error           string  `json:"error,omitempty"`        // Why the last attempt failed.
+}
+
+func (s *Server) handleAPISync(w http.ResponseWriter, r *http.Request) {
+	st, err := s.store.GetSyncStatus(r.Context())
+	if err != nil {
+		s.log.Error("get sync status", "err", err)
+		s.writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, apiSync{
+		Commit:          st.Commit,
+		CommitTime:      apiTime(st.CommitTime),
+		LastSuccess:     apiTime(st.LastSync),
+		LastAttempt:     apiTime(st.Attempted),
+		DurationSeconds: st.Duration.Seconds(),
+		OK:              !st.Failed(),
+		Error:           st.Error,
+	})
+}
+
+// apiTime formats t as RFC 3339 in UTC, or returns an empty string if t is zero.
+func apiTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func apiConfidence(c matchup.Confidence) string {
+	switch c {
+	case matchup.ConfidenceHigh:
+		return "high"
+	case matchup.ConfidenceMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// nonNil returns s, or an empty slice if s is nil, so it encodes as [] rather
+// than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,232 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// A recording is an API request and the response an earlier build returned.
+type recording struct {
+	Request  string          `json:"request"` // Method and path, e.g. "GET /api/v1/standings".
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// TestAPICompatibility replays the recorded requests in testdata/api against
+// this build. Responses may add fields, but every recorded field must still be
+// there with the same value. Running with -update re-records the responses,
+// which should only be needed to pick up added fields. Add a recording by
+// creating a file with just a request, then running with -update.
+func TestAPICompatibility(t *testing.T) {
+	h := newTestServer(t)
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "api", "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no recorded API requests in testdata/api")
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want recording
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatalf("decode %s: %v", path, err)
+			}
+
+			method, target, ok := strings.Cut(want.Request, " ")
+			if !ok {
+				t.Fatalf("%s: request %q should be a method and a path", path, want.Request)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("%s: want Content-Type application/json, got %s", want.Request, got)
+			}
+
+			if *update {
+				got := recording{Request: want.Request, Status: rec.Code, Response: json.RawMessage(rec.Body.Bytes())}
+				var out bytes.Buffer
+				enc := json.NewEncoder(&out)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(got); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+					t.Fatalf("write recording: %v", err)
+				}
+				return
+			}
+
+			if rec.Code != want.Status {
+				t.Errorf("%s: want status %d, got %d", want.Request, want.Status, rec.Code)
+			}
+
+			var wantBody, gotBody any
+			if err := json.Unmarshal(want.Response, &wantBody); err != nil {
+				t.Fatalf("%s: decode recorded response (run with -update to record it): %v", path, err)
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("%s: decode response: %v", want.Request, err)
+			}
+			for _, problem := range incompatibilities("$", wantBody, gotBody) {
+				t.Errorf("%s: %s", want.Request, problem)
+			}
+		})
+	}
+}
+
+// incompatibilities returns how a response differs from a recorded response
+// in ways that could break a client. Objects may gain keys, but everything
+// else must match.
+func incompatibilities(path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want an object, got %T", path, got)}
+		}
+		var problems []string
+		keys := make([]string, 0, len(w))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			gv, ok := g[k]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			problems = append(problems, incompatibilities(path+"."+k, w[k], gv)...)
+		}
+		return problems
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want an array, got %T", path, got)}
+		}
+		if len(g) != len(w) {
+			return []string{fmt.Sprintf("%s: want %d elements, got %d", path, len(w), len(g))}
+		}
+		var problems []string
+		for i := range w {
+			problems = append(problems, incompatibilities(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return problems
+	default:
+		if diff := cmp.Diff(want, got); diff != "" {
+			return []string{fmt.Sprintf("%s: -want, +got:\n%s", path, diff)}
+		}
+		return nil
+	}
+}
+
+func TestIncompatibilities(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		want   string
+		got    string
+		n      int
+	}{
+		"Identical": {
+			reason: "An identical response is compatible.",
+			want:   `{"a": 1, "b": [{"c": "d"}]}`,
+			got:    `{"a": 1, "b": [{"c": "d"}]}`,
+		},
+		"AddedField": {
+			reason: "A response may add fields.",
+			want:   `{"a": 1, "b": [{"c": "d"}]}`,
+			got:    `{"a": 1, "b": [{"c": "d", "e": true}], "f": 2}`,
+		},
+		"RemovedField": {
+			reason: "A response may not remove fields.",
+			want:   `{"a": 1, "b": [{"c": "d"}]}`,
+			got:    `{"a": 1, "b": [{}]}`,
+			n:      1,
+		},
+		"RetypedField": {
+			reason: "A response may not change a field's type.",
+			want:   `{"a": 1, "b": [{"c": "d"}]}`,
+			got:    `{"a": "1", "b": {"c": "d"}}`,
+			n:      2,
+		},
+		"ChangedValue": {
+			reason: "A response may not change a value.",
+			want:   `{"a": 1}`,
+			got:    `{"a": 2}`,
+			n:      1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var want, got any
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tc.got), &got); err != nil {
+				t.Fatal(err)
+			}
+			problems := incompatibilities("$", want, got)
+			if len(problems) != tc.n {
+				t.Errorf("\n%s\nincompatibilities(...): want %d problems, got %d: %v", tc.reason, tc.n, len(problems), problems)
+			}
+		})
+	}
+}
+
+func TestWithDeprecation(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason string
+		d      deprecation
+		want   http.Header
+	}{
+		"DeprecatedOnly": {
+			reason: "A deprecated route without a sunset should only set the Deprecation header.",
+			d:      deprecation{Since: since},
+			want:   http.Header{"Deprecation": {"@1704067200"}},
+		},
+		"Sunset": {
+			reason: "A route with a sunset and a successor should announce both.",
+			d:      deprecation{Since: since, Sunset: sunset, Link: "https://example.org/api/v2"},
+			want: http.Header{
+				"Deprecation": {"@1704067200"},
+				"Sunset":      {"Sat, 01 Jun 2024 00:00:00 GMT"},
+				"Link":        {`<https://example.org/api/v2>; rel="deprecation"`},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := withDeprecation(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), tc.d)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/standings", nil))
+			if diff := cmp.Diff(tc.want, rec.Header()); diff != "" {
+				t.Errorf("\n%s\nwithDeprecation(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
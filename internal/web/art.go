@@ -0,0 +1,54 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/negz/mnp/internal/imgcache"
+)
+
+// artURL returns the URL of a machine's backglass thumbnail, or an empty string
+// if machine art is disabled.
+func (s *Server) artURL(machineKey string) string {
+	if s.art == nil {
+		return ""
+	}
+	return "/art/" + url.PathEscape(machineKey)
+}
+
+func (s *Server) handleArt(w http.ResponseWriter, r *http.Request) {
+	if s.art == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	// Only known machines have art. Checking first keeps arbitrary keys from
+	// reaching the image source.
+	key := r.PathValue("key")
+	names, err := s.store.GetMachineNames(r.Context())
+	if err != nil {
+		s.log.Error("get machine names", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if _, ok := names[key]; !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	img, err := s.art.Get(r.Context(), key)
+	if errors.Is(err, imgcache.ErrNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.log.Warn("get machine art", "machine", key, "err", err)
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(img.Data) //nolint:errcheck // Nothing to do if the client went away.
+}
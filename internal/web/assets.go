@@ -0,0 +1,74 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// An assetFS serves static files under fingerprinted names that change when
+// the file's content does (e.g. pico.min.1a2b3c4d5e.css), so browsers can
+// cache them forever without serving stale files after a deploy. Requests
+// for unfingerprinted names are still served, with a short cache lifetime.
+type assetFS struct {
+	fs          fs.FS
+	fingerprint map[string]string // Name to fingerprinted name.
+	original    map[string]string // Fingerprinted name to name.
+}
+
+// newAssetFS fingerprints every file in the supplied filesystem.
+func newAssetFS(fsys fs.FS) (*assetFS, error) {
+	a := &assetFS{
+		fs:          fsys,
+		fingerprint: make(map[string]string),
+		original:    make(map[string]string),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		hashed := fingerprinted(name, hex.EncodeToString(sum[:5]))
+		a.fingerprint[name] = hashed
+		a.original[hashed] = name
+		return nil
+	})
+	return a, err
+}
+
+// fingerprinted inserts the supplied hash before a name's extension.
+func fingerprinted(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// URL returns the URL of the supplied static file, fingerprinted if possible.
+func (a *assetFS) URL(name string) string {
+	if hashed, ok := a.fingerprint[name]; ok {
+		return "/static/" + hashed
+	}
+	return "/static/" + name
+}
+
+// ServeHTTP serves static files. Fingerprinted names are cached immutably.
+func (a *assetFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/static/")
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if orig, ok := a.original[name]; ok {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		http.ServeFileFS(w, r, a.fs, orig)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFileFS(w, r, a.fs, name)
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAssetFS(t *testing.T) {
+	const css = "body { color: black; }"
+	sum := sha256.Sum256([]byte(css))
+	hashed := "site." + hex.EncodeToString(sum[:5]) + ".css"
+
+	a, err := newAssetFS(fstest.MapFS{"site.css": {Data: []byte(css)}})
+	if err != nil {
+		t.Fatalf("newAssetFS: %v", err)
+	}
+
+	t.Run("URL", func(t *testing.T) {
+		if got, want := a.URL("site.css"), "/static/"+hashed; got != want {
+			t.Errorf("URL(%q): want %q, got %q", "site.css", want, got)
+		}
+		if got, want := a.URL("missing.css"), "/static/missing.css"; got != want {
+			t.Errorf("URL(%q): want %q for a file that doesn't exist, got %q", "missing.css", want, got)
+		}
+	})
+
+	type want struct {
+		status       int
+		cacheControl string
+		body         string
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Fingerprinted": {
+			reason: "A fingerprinted name's content can never change, so it should be cached immutably.",
+			path:   "/static/" + hashed,
+			want:   want{status: http.StatusOK, cacheControl: "public, max-age=31536000, immutable", body: css},
+		},
+		"Unfingerprinted": {
+			reason: "A file's own name may serve new content after a deploy, so it shouldn't be cached immutably.",
+			path:   "/static/site.css",
+			want:   want{status: http.StatusOK, cacheControl: "public, max-age=86400", body: css},
+		},
+		"StaleHash": {
+			reason: "A fingerprint from before the file changed should be missing, not serve the new content.",
+			path:   "/static/site.0123456789.css",
+			want:   want{status: http.StatusNotFound},
+		},
+		"UnknownFile": {
+			reason: "A file that doesn't exist should be missing.",
+			path:   "/static/other." + hex.EncodeToString(sum[:5]) + ".css",
+			want:   want{status: http.StatusNotFound},
+		},
+		"Directory": {
+			reason: "The static directory itself should be missing.",
+			path:   "/static/",
+			want:   want{status: http.StatusNotFound},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			rsp := rec.Result()
+
+			if rsp.StatusCode != tc.want.status {
+				t.Errorf("\n%s\nGET %s: want status %d, got %d", tc.reason, tc.path, tc.want.status, rsp.StatusCode)
+			}
+			if tc.want.status != http.StatusOK {
+				if got := rsp.Header.Get("Cache-Control"); strings.Contains(got, "immutable") {
+					t.Errorf("\n%s\nGET %s: want a missing file not to be cached immutably, got Cache-Control %q", tc.reason, tc.path, got)
+				}
+				return
+			}
+			if got := rsp.Header.Get("Cache-Control"); got != tc.want.cacheControl {
+				t.Errorf("\n%s\nGET %s: want Cache-Control %q, got %q", tc.reason, tc.path, tc.want.cacheControl, got)
+			}
+			b, _ := io.ReadAll(rsp.Body)
+			if string(b) != tc.want.body {
+				t.Errorf("\n%s\nGET %s: want body %q, got %q", tc.reason, tc.path, tc.want.body, b)
+			}
+		})
+	}
+}
+
+func TestAssetURLs(t *testing.T) {
+	h := newTestServer(t)
+	staticFS, _ := fs.Sub(static, "static")
+	a, err := newAssetFS(staticFS)
+	if err != nil {
+		t.Fatalf("newAssetFS: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Pages should link fingerprinted assets, which should then be served.
+	for _, name := range []string{"pico.min.css", "htmx.min.js"} {
+		link := a.URL(name)
+		if link == "/static/"+name {
+			t.Fatalf("URL(%q): want a fingerprinted URL, got %q", name, link)
+		}
+		if !strings.Contains(rec.Body.String(), link) {
+			t.Errorf("GET /: want a link to %s", link)
+		}
+		asset := httptest.NewRecorder()
+		h.ServeHTTP(asset, httptest.NewRequest(http.MethodGet, link, nil))
+		if asset.Code != http.StatusOK {
+			t.Errorf("GET %s: want status %d, got %d", link, http.StatusOK, asset.Code)
+		}
+		if got := asset.Header().Get("Cache-Control"); !strings.Contains(got, "immutable") {
+			t.Errorf("GET %s: want an immutable Cache-Control, got %q", link, got)
+		}
+	}
+}
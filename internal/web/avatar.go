@@ -0,0 +1,240 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxAvatarBytes is the largest avatar image the upload form accepts.
+const maxAvatarBytes = 1 << 20
+
+type avatarType struct {
+	contentType string
+	ext         string
+}
+
+// avatarTypes returns the accepted avatar content types and their file
+// extensions, in the order they're looked up on disk.
+func avatarTypes() []avatarType {
+	return []avatarType{
+		{"image/png", ".png"},
+		{"image/jpeg", ".jpg"},
+		{"image/webp", ".webp"},
+	}
+}
+
+// avatarSlug returns the file name stem used to store a player's avatar, e.g.
+// "Jay Ostby" becomes "jay-ostby".
+func avatarSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// avatarURL returns the URL of a player's avatar.
+func avatarURL(name string) string {
+	return "/avatars/" + url.PathEscape(name)
+}
+
+// initials returns up to two uppercase initials for a player name.
+func initials(name string) string {
+	var out []rune
+	for _, f := range strings.Fields(name) {
+		r := []rune(f)[0]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		out = append(out, unicode.ToUpper(r))
+	}
+	if len(out) > 2 {
+		out = []rune{out[0], out[len(out)-1]}
+	}
+	if len(out) == 0 {
+		return "?"
+	}
+	return string(out)
+}
+
+// generatedAvatar returns a gravatar-style placeholder SVG. The background
+// colour is derived from a hash of the player's normalized name, so each player
+// gets a stable colour without anyone uploading a photo.
+func generatedAvatar(name string) []byte {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(name))))
+	hue := binary.BigEndian.Uint16(sum[:2]) % 360
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" width="64" height="64" viewBox="0 0 64 64">`+
+		`<rect width="64" height="64" rx="32" fill="hsl(%d,55%%,42%%)"/>`+
+		`<text x="32" y="32" dy=".35em" text-anchor="middle" font-family="sans-serif" font-size="26" fill="#fff">%s</text>`+
+		`</svg>`, hue, template.HTMLEscapeString(initials(name)))
+}
+
+// findAvatar returns the path of an uploaded avatar for the player, if any.
+func (s *Server) findAvatar(name string) (string, bool) {
+	if s.avatarDir == "" {
+		return "", false
+	}
+	slug := avatarSlug(name)
+	if slug == "" {
+		return "", false
+	}
+	for _, e := range avatarTypes() {
+		p := filepath.Join(s.avatarDir, slug+e.ext)
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func (s *Server) handleAvatar(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+
+	if p, ok := s.findAvatar(name); ok {
+		http.ServeFile(w, r, p)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(generatedAvatar(name)) //nolint:errcheck // Nothing to do if the client went away.
+}
+
+// Avatar admin page.
+
+type avatarAdminData struct {
+	Players []string
+	Player  string
+	Message string
+	Error   string
+}
+
+// adminEnabled reports whether avatar uploads are configured.
+func (s *Server) adminEnabled() bool {
+	return s.avatarDir != "" && s.adminToken != ""
+}
+
+// authorized reports whether the supplied token matches the admin token.
+func (s *Server) authorized(token string) bool {
+	return s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+func (s *Server) handleAvatarAdmin(w http.ResponseWriter, r *http.Request) {
+	if !s.adminEnabled() {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	data := avatarAdminData{Player: r.URL.Query().Get("player")}
+	s.renderAvatarAdmin(w, r, http.StatusOK, data)
+}
+
+func (s *Server) handleAvatarUpload(w http.ResponseWriter, r *http.Request) {
+	if !s.adminEnabled() {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarBytes+4096)
+	if err := r.ParseMultipartForm(maxAvatarBytes); err != nil {
+		s.renderAvatarAdmin(w, r, http.StatusBadRequest, avatarAdminData{Error: "Upload too large (max 1MB)."})
+		return
+	}
+
+	data := avatarAdminData{Player: r.FormValue("player")}
+
+	if !s.authorized(r.FormValue("token")) {
+		data.Error = "Invalid admin token."
+		s.renderAvatarAdmin(w, r, http.StatusForbidden, data)
+		return
+	}
+
+	if err := s.saveAvatar(r, data.Player); err != nil {
+		data.Error = fmt.Sprintf("Error: %v", err)
+		s.renderAvatarAdmin(w, r, http.StatusBadRequest, data)
+		return
+	}
+
+	data.Message = fmt.Sprintf("Saved avatar for %s.", data.Player)
+	s.renderAvatarAdmin(w, r, http.StatusOK, data)
+}
+
+// saveAvatar writes the uploaded image to the avatar directory, replacing any
+// existing avatar for the player.
+func (s *Server) saveAvatar(r *http.Request, name string) error {
+	slug := avatarSlug(name)
+	if slug == "" {
+		return errors.New("player name is required")
+	}
+
+	f, _, err := r.FormFile("avatar")
+	if err != nil {
+		return fmt.Errorf("read upload: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only upload.
+
+	img, err := io.ReadAll(io.LimitReader(f, maxAvatarBytes+1))
+	if err != nil {
+		return fmt.Errorf("read upload: %w", err)
+	}
+	if len(img) > maxAvatarBytes {
+		return errors.New("image is larger than 1MB")
+	}
+
+	ext := ""
+	ct := http.DetectContentType(img)
+	for _, e := range avatarTypes() {
+		if ct == e.contentType {
+			ext = e.ext
+		}
+	}
+	if ext == "" {
+		return fmt.Errorf("unsupported image type %s (use PNG, JPEG, or WebP)", ct)
+	}
+
+	if err := os.MkdirAll(s.avatarDir, 0o750); err != nil {
+		return fmt.Errorf("create avatar directory: %w", err)
+	}
+	for _, e := range avatarTypes() {
+		if err := os.Remove(filepath.Join(s.avatarDir, slug+e.ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove old avatar: %w", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(s.avatarDir, slug+ext), img, 0o600); err != nil {
+		return fmt.Errorf("write avatar: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) renderAvatarAdmin(w http.ResponseWriter, r *http.Request, status int, data avatarAdminData) {
+	players, err := s.store.ListPlayers(r.Context(), "")
+	if err != nil {
+		s.log.Error("list players", "err", err)
+	}
+	for _, p := range players {
+		data.Players = append(data.Players, p.Name)
+	}
+
+	s.renderStatus(w, r, status, s.template.avatars, data)
+}
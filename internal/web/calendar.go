@@ -0,0 +1,53 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/negz/mnp/internal/ical"
+)
+
+// Calendar feeds.
+
+// handleCalendar serves the season's schedule as an iCalendar feed, for
+// subscribing from a calendar app. It serves every match, or only a team's
+// when the path names one. An arrive parameter (e.g. arrive=30m) starts events
+// that long before matches do.
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team := strings.ToUpper(r.PathValue("team"))
+
+	var arrive time.Duration
+	if v := r.URL.Query().Get("arrive"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "arrive must be a duration, such as 30m", http.StatusBadRequest)
+			return
+		}
+		arrive = d
+	}
+
+	matches, err := s.store.ListSchedule(ctx, "")
+	if err != nil {
+		s.log.Error("list schedule", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	cal, err := s.clock.Calendar(matches, team, arrive, s.now())
+	if err != nil {
+		s.log.Error("build calendar", "team", team, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if team != "" && len(cal.Events) == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	if err := ical.Write(w, cal); err != nil {
+		s.log.Error("write calendar", "err", err)
+	}
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressible are the media types worth compressing. Images are already
+// compressed.
+func compressible() map[string]bool {
+	return map[string]bool{
+		"text/html":              true,
+		"text/css":               true,
+		"text/csv":               true,
+		"text/plain":             true,
+		"text/javascript":        true,
+		"application/javascript": true,
+		"application/json":       true,
+		"image/svg+xml":          true,
+	}
+}
+
+//nolint:gochecknoglobals // Writers are expensive to allocate, so they're shared.
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+
+// WithCompression wraps an http.Handler to gzip text responses, such as HTML,
+// CSS, JSON, and CSV, for clients that accept it.
+func WithCompression(next http.Handler) http.Handler {
+	types := compressible()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, types: types}
+		defer gw.Close() //nolint:errcheck // Nothing to do if the client went away.
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip returns true if the request's Accept-Encoding header names gzip
+// with a quality above zero (e.g. not gzip;q=0).
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		k, v, _ := strings.Cut(strings.ReplaceAll(params, " ", ""), "=")
+		if q, err := strconv.ParseFloat(v, 64); strings.EqualFold(k, "q") && err == nil && q == 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// A gzipResponseWriter decides whether to compress a response when its headers
+// are written, based on its content type.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	types       map[string]bool
+	wroteHeader bool
+	gz          *gzip.Writer // Nil unless the response is being compressed.
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if w.types[mt] && h.Get("Content-Encoding") == "" && code != http.StatusNoContent && code != http.StatusNotModified {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer) //nolint:forcetypeassert // The pool only holds gzip writers.
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+// Close flushes any compressed data.
+func (w *gzipResponseWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	gzipWriters.Put(w.gz)
+	w.gz = nil
+	return err
+}
@@ -0,0 +1,174 @@
+package web
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithCompression(t *testing.T) {
+	const body = "<p>Hello, Monday Night Pinball.</p>"
+
+	type args struct {
+		method         string
+		acceptEncoding string
+		contentType    string
+		status         int
+	}
+	type want struct {
+		encoding      string
+		contentLength string
+		body          string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Gzip": {
+			reason: "A compressible response should be gzipped for a client that accepts gzip, without the uncompressed Content-Length.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "text/html; charset=utf-8", status: http.StatusOK},
+			want:   want{encoding: "gzip", body: body},
+		},
+		"GzipAmongOthers": {
+			reason: "gzip should be found among several encodings, whatever its case and quality.",
+			args:   args{method: http.MethodGet, acceptEncoding: "br;q=1.0, GZIP;q=0.5, deflate", contentType: "application/json", status: http.StatusOK},
+			want:   want{encoding: "gzip", body: body},
+		},
+		"GzipRefused": {
+			reason: "A client that gives gzip a quality of zero doesn't accept it.",
+			args:   args{method: http.MethodGet, acceptEncoding: "br, gzip; q=0", contentType: "text/html", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body)), body: body},
+		},
+		"GzipRefusedDecimal": {
+			reason: "A quality of zero may be written with decimals.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip;q=0.000", contentType: "text/html", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body)), body: body},
+		},
+		"NoAcceptEncoding": {
+			reason: "A client that doesn't send Accept-Encoding shouldn't get gzip.",
+			args:   args{method: http.MethodGet, contentType: "text/html", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body)), body: body},
+		},
+		"OtherEncoding": {
+			reason: "A client that only accepts other encodings shouldn't get gzip.",
+			args:   args{method: http.MethodGet, acceptEncoding: "br, deflate", contentType: "text/html", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body)), body: body},
+		},
+		"Image": {
+			reason: "Images are already compressed, so shouldn't be gzipped.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "image/png", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body)), body: body},
+		},
+		"SVG": {
+			reason: "SVG images are text, so should be gzipped.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "image/svg+xml", status: http.StatusOK},
+			want:   want{encoding: "gzip", body: body},
+		},
+		"CSV": {
+			reason: "CSV downloads should be gzipped.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "text/csv; charset=utf-8", status: http.StatusOK},
+			want:   want{encoding: "gzip", body: body},
+		},
+		"Head": {
+			reason: "HEAD responses have no body to compress.",
+			args:   args{method: http.MethodHead, acceptEncoding: "gzip", contentType: "text/html", status: http.StatusOK},
+			want:   want{contentLength: strconv.Itoa(len(body))},
+		},
+		"NoContent": {
+			reason: "204 responses have no body to compress.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "text/html", status: http.StatusNoContent},
+		},
+		"NotModified": {
+			reason: "304 responses have no body to compress.",
+			args:   args{method: http.MethodGet, acceptEncoding: "gzip", contentType: "text/html", status: http.StatusNotModified},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := WithCompression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.args.contentType)
+				if tc.args.status == http.StatusNoContent || tc.args.status == http.StatusNotModified {
+					w.WriteHeader(tc.args.status)
+					return
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				w.WriteHeader(tc.args.status)
+				if r.Method != http.MethodHead {
+					w.Write([]byte(body)) //nolint:errcheck // Test handler.
+				}
+			}))
+
+			req := httptest.NewRequest(tc.args.method, "/", nil)
+			if tc.args.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.args.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			rsp := rec.Result()
+
+			if rsp.StatusCode != tc.args.status {
+				t.Errorf("\n%s\nServeHTTP(...): want status %d, got %d", tc.reason, tc.args.status, rsp.StatusCode)
+			}
+			if got := rsp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("\n%s\nServeHTTP(...): want Vary: Accept-Encoding, got %q", tc.reason, got)
+			}
+			if got := rsp.Header.Get("Content-Encoding"); got != tc.want.encoding {
+				t.Errorf("\n%s\nServeHTTP(...): want Content-Encoding %q, got %q", tc.reason, tc.want.encoding, got)
+			}
+			if got := rsp.Header.Get("Content-Length"); got != tc.want.contentLength {
+				t.Errorf("\n%s\nServeHTTP(...): want Content-Length %q, got %q", tc.reason, tc.want.contentLength, got)
+			}
+			if diff := cmp.Diff(tc.want.body, decode(t, rsp)); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithCompressionReusesWriters(t *testing.T) {
+	// Each request's gzip writer is returned to a pool for the next request
+	// to reuse, so nothing from one response may leak into the next.
+	bodies := []string{"first response, which is the longer of the two", "second", "third"}
+
+	for _, body := range bodies {
+		h := WithCompression(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(body)) //nolint:errcheck // Test handler.
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if diff := cmp.Diff(body, decode(t, rec.Result())); diff != "" {
+			t.Errorf("ServeHTTP(...): a reused gzip writer should only write this response: -want body, +got body:\n%s", diff)
+		}
+	}
+}
+
+// decode returns a response's body, gunzipped if it's gzipped.
+func decode(t *testing.T, rsp *http.Response) string {
+	t.Helper()
+
+	var r io.Reader = rsp.Body
+	if rsp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(rsp.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		r = gz
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return string(b)
+}
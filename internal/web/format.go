@@ -0,0 +1,148 @@
+package web
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/negz/mnp/internal/output"
+)
+
+// scoresCookie remembers whether a visitor prefers full or abbreviated scores.
+const scoresCookie = "scores"
+
+const (
+	scoresFull  = "full"
+	scoresShort = "short"
+)
+
+func formatScore(f output.ScoreFormat) func(float64) string {
+	return func(score float64) string {
+		if score == 0 {
+			return "-"
+		}
+		return f.Score(score)
+	}
+}
+
+func formatP50(f output.ScoreFormat) func(float64, float64) string {
+	return func(p50, leagueP50 float64) string {
+		if p50 == 0 {
+			return "-"
+		}
+		return f.P50(p50, leagueP50)
+	}
+}
+
+// scoreFormat returns how scores should be formatted for a request. The locale
+// comes from the server if set, otherwise from the Accept-Language header. A
+// "scores" query parameter switches between full and abbreviated scores, and
+// is remembered in a cookie.
+func (s *Server) scoreFormat(w http.ResponseWriter, r *http.Request) output.ScoreFormat {
+	f := output.ScoreFormat{Full: s.fullScores}
+	if s.locale != nil {
+		f.Locale = *s.locale
+	} else {
+		f.Locale = output.LocaleFromAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	pref := r.URL.Query().Get("scores")
+	switch pref {
+	case scoresFull, scoresShort:
+		http.SetCookie(w, &http.Cookie{
+			Name:     scoresCookie,
+			Value:    pref,
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	default:
+		if c, err := r.Cookie(scoresCookie); err == nil {
+			pref = c.Value
+		}
+	}
+	switch pref {
+	case scoresFull:
+		f.Full = true
+	case scoresShort:
+		f.Full = false
+	}
+	return f
+}
+
+// scoresURL returns the current page's URL with the scores preference set.
+func scoresURL(r *http.Request) func(string) string {
+	return func(pref string) string {
+		q := r.URL.Query()
+		q.Set("scores", pref)
+		u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+		return u.String()
+	}
+}
+
+// dataAsOf describes how fresh the archive data is, in the viewer's timezone.
+func (s *Server) dataAsOf(ctx context.Context, loc *time.Location) string {
+	f, err := s.store.GetFreshness(ctx)
+	if err != nil {
+		s.log.Warn("get data freshness", "err", err)
+		return ""
+	}
+	return output.FormatDataAsOf(f.Commit, f.CommitTime.In(loc), s.now())
+}
+
+// syncFailed describes when the last sync failed, in the viewer's timezone. It
+// returns an empty string if the last sync succeeded.
+func (s *Server) syncFailed(ctx context.Context, loc *time.Location) string {
+	st, err := s.store.GetSyncStatus(ctx)
+	if err != nil {
+		s.log.Warn("get sync status", "err", err)
+		return ""
+	}
+	if !st.Failed() {
+		return ""
+	}
+	return "Sync failed " + st.Attempted.In(loc).Format("Mon 3:04pm")
+}
+
+// render executes a page template with number and time formatting for the
+// request.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, t *template.Template, data any) {
+	s.renderStatus(w, r, http.StatusOK, t, data)
+}
+
+// renderStatus is render with a status other than 200 OK. Rendering sets
+// cookies and headers, so handlers must let it write the status rather than
+// calling WriteHeader first.
+func (s *Server) renderStatus(w http.ResponseWriter, r *http.Request, status int, t *template.Template, data any) {
+	f := s.scoreFormat(w, r)
+	loc := s.viewerLocation(w, r)
+
+	t, err := t.Clone()
+	if err != nil {
+		s.log.Error("clone template", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	t.Funcs(template.FuncMap{
+		"formatScore": formatScore(f),
+		"formatP50":   formatP50(f),
+		"scoreFormat": func() output.ScoreFormat { return f },
+		"fullScores":  func() bool { return f.Full },
+		"scoresURL":   scoresURL(r),
+		"matchTime":   formatMatchTime(s.clock, loc),
+		"dataAsOf":    func() string { return s.dataAsOf(r.Context(), loc) },
+		"syncFailed":  func() string { return s.syncFailed(r.Context(), loc) },
+	})
+
+	// Pages are cached, but depend on the visitor's language and cookies.
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	if err := t.ExecuteTemplate(w, "layout.html", data); err != nil {
+		s.log.Error("render template", "err", err)
+	}
+}
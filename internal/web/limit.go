@@ -0,0 +1,113 @@
+package web
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unlimited reports whether a request is exempt from rate limiting. Static
+// assets are cheap, and a page pulls in several at once. Health checks and
+// metrics scrapes come from infrastructure.
+func unlimited(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/healthz", "/readyz", "/metrics", "/favicon.ico", "/robots.txt":
+		return true
+	}
+	return strings.HasPrefix(r.URL.Path, "/static/")
+}
+
+// WithRateLimit wraps an http.Handler to limit how often each client may make
+// requests. A client may make burst requests at once, then rate requests per
+// second after that. Clients over their limit get 429 Too Many Requests, with a
+// Retry-After header saying when to try again. Clients are identified by the IP
+// in the supplied header, set by a trusted proxy, or by the address they
+// connected from if header is empty.
+func WithRateLimit(next http.Handler, rate float64, burst int, header string) http.Handler {
+	l := &rateLimiter{rate: rate, burst: float64(burst), clients: make(map[string]*bucket)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unlimited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		wait := l.take(clientIP(r, header), time.Now())
+		if wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too many requests. Please slow down.", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP of the client that made the request.
+func clientIP(r *http.Request, header string) string {
+	if header != "" {
+		if ip := strings.TrimSpace(r.Header.Get(header)); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// A bucket holds a client's tokens. Each request takes one, and they refill at
+// the limiter's rate up to its burst.
+type bucket struct {
+	tokens float64
+	last   time.Time // When tokens was last refilled.
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	clients map[string]*bucket
+	swept   time.Time
+}
+
+// take takes a token from the client's bucket. It returns how long the client
+// must wait for one, or zero if it took one.
+func (l *rateLimiter) take(client string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep forgets clients whose buckets have refilled, at most once a minute, so
+// the limiter doesn't remember every client it has ever seen. A forgotten
+// client gets a full bucket, just as it would have if remembered.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for c, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, c)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+)
+
+// Match detail page.
+
+type matchData struct {
+	Key        string
+	Match      *db.MatchDetail
+	HomePoints float64
+	AwayPoints float64
+	Games      []matchGame
+	Error      string
+}
+
+// matchGame is one game of a match, with each side's players in the order they
+// played.
+type matchGame struct {
+	Round       int
+	MachineName string
+	Away        matchSide
+	Home        matchSide
+}
+
+type matchSide struct {
+	Players []matchPlayer
+	Points  float64
+}
+
+type matchPlayer struct {
+	Name   string
+	Score  float64 // Float so it can be passed to formatScore.
+	Points float64
+}
+
+func (s *Server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := r.PathValue("match")
+
+	data := matchData{Key: key}
+	status := http.StatusOK
+
+	detail, err := s.store.GetMatchDetail(ctx, key)
+	switch {
+	case err != nil:
+		data.Error = fmt.Sprintf("Error: %v", err)
+	case detail == nil:
+		status = http.StatusNotFound
+		data.Error = fmt.Sprintf("No match %s.", key)
+	default:
+		data.Match = detail
+	}
+
+	if data.Match != nil {
+		names, err := s.store.GetMachineNames(ctx)
+		if err != nil {
+			s.log.Error("get machine names", "err", err)
+		}
+		data.Games = matchGames(detail.Results, detail.HomeTeamKey, names)
+		for _, g := range data.Games {
+			data.HomePoints += g.Home.Points
+			data.AwayPoints += g.Away.Points
+		}
+	}
+
+	s.renderStatus(w, r, status, s.template.match, data)
+}
+
+// matchGames groups player results into games, in the order they were played.
+func matchGames(results []db.MatchResult, homeKey string, names map[string]string) []matchGame {
+	var games []matchGame
+	index := make(map[int64]int)
+	for _, mr := range results {
+		i, ok := index[mr.GameID]
+		if !ok {
+			i = len(games)
+			index[mr.GameID] = i
+			games = append(games, matchGame{Round: mr.Round, MachineName: output.MachineName(names, mr.MachineKey)})
+		}
+
+		side := &games[i].Away
+		if mr.TeamKey == homeKey {
+			side = &games[i].Home
+		}
+		side.Players = append(side.Players, matchPlayer{Name: mr.PlayerName, Score: float64(mr.Score), Points: mr.Points})
+		side.Points += mr.Points
+	}
+	return games
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/metrics"
+)
+
+// syncBuckets are histogram buckets for sync durations, in seconds. A sync
+// that only pulls a few new matches takes seconds; a full rebuild takes
+// minutes.
+//
+//nolint:gochecknoglobals // Read-only buckets.
+var syncBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600}
+
+// Metrics records request, sync, database, and analysis cache metrics, and
+// serves them for Prometheus to scrape.
+type Metrics struct {
+	registry     *metrics.Registry
+	requests     *metrics.Counter
+	latency      *metrics.Histogram
+	syncs        *metrics.Counter
+	syncDuration *metrics.Histogram
+}
+
+// NewMetrics returns metrics that report the database size returned by size,
+// and the analysis cache stats returned by stats.
+func NewMetrics(size func(context.Context) (int64, error), stats func() cache.Stats) *Metrics {
+	r := metrics.NewRegistry()
+	m := &Metrics{
+		registry:     r,
+		requests:     r.Counter("mnp_http_requests_total", "HTTP requests served, by route, method, and status code.", "route", "method", "code"),
+		latency:      r.Histogram("mnp_http_request_duration_seconds", "HTTP request latency, by route.", metrics.DefaultBuckets, "route"),
+		syncs:        r.Counter("mnp_syncs_total", "Syncs from the MNP archive, by result (success or failure).", "result"),
+		syncDuration: r.Histogram("mnp_sync_duration_seconds", "How long syncs from the MNP archive take.", syncBuckets),
+	}
+	r.GaugeFunc("mnp_database_size_bytes", "Size of the SQLite database.", func() (float64, error) {
+		n, err := size(context.Background())
+		return float64(n), err
+	})
+
+	// The cache counts hits, misses, and evictions itself, so they're read
+	// when scraped like its size.
+	cacheStat := func(fn func(cache.Stats) float64) func() (float64, error) {
+		return func() (float64, error) { return fn(stats()), nil }
+	}
+	r.GaugeFunc("mnp_analysis_cache_entries", "Analyses and stats in the in-memory cache.", cacheStat(func(s cache.Stats) float64 { return float64(s.Entries) }))
+	r.GaugeFunc("mnp_analysis_cache_bytes", "Approximate size of the in-memory analysis cache.", cacheStat(func(s cache.Stats) float64 { return float64(s.Bytes) }))
+	r.GaugeFunc("mnp_analysis_cache_max_bytes", "Approximate size past which the analysis cache evicts entries.", cacheStat(func(s cache.Stats) float64 { return float64(s.MaxBytes) }))
+	r.CounterFunc("mnp_analysis_cache_hits_total", "Analyses and stats served from the in-memory cache.", cacheStat(func(s cache.Stats) float64 { return float64(s.Hits) }))
+	r.CounterFunc("mnp_analysis_cache_misses_total", "Analyses and stats that had to be computed.", cacheStat(func(s cache.Stats) float64 { return float64(s.Misses) }))
+	r.CounterFunc("mnp_analysis_cache_evictions_total", "Analysis cache entries evicted to stay under its maximum size.", cacheStat(func(s cache.Stats) float64 { return float64(s.Evictions) }))
+	return m
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.registry.ServeHTTP(w, r)
+}
+
+// Sync wraps a sync function to record whether it succeeded and how long it
+// took. Pass the result to Sync.
+func (m *Metrics) Sync(syncFn func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := syncFn(ctx)
+		m.syncDuration.Observe(time.Since(start).Seconds())
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		m.syncs.Inc(result)
+		return err
+	}
+}
+
+// unmatchedRoute is the route label of requests that matched no pattern.
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method label of requests with a nonstandard method.
+const otherMethod = "OTHER"
+
+// instrument wraps a ServeMux to record each request's route, method, status
+// code, and latency. Routes are the mux's patterns (e.g. GET /t/{team}), so
+// that requests for different teams count toward the same route. Labels only
+// take a bounded set of values, so clients can't create series at will.
+func (m *Metrics) instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, r)
+
+		// The mux sets the pattern it matched, if any, on the request.
+		route := r.Pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.Inc(route, method(r.Method), strconv.Itoa(rec.status))
+		m.latency.Observe(time.Since(start).Seconds(), route)
+	})
+}
+
+// method returns a method label: the method if it's a standard HTTP method, or
+// otherMethod.
+func method(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return m
+	default:
+		return otherMethod
+	}
+}
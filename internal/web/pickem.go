@@ -0,0 +1,197 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/pickem"
+)
+
+// Pick'em page.
+
+// pickerCookie remembers the name a visitor makes picks under, so they don't
+// have to enter it every week.
+const pickerCookie = "picker"
+
+// pickerTokenCookie holds a random token identifying the visitor's browser.
+// The first browser to make picks under a name owns it, so other visitors
+// can't change their picks.
+const pickerTokenCookie = "picker_token"
+
+// maxPickerLength is the longest name a visitor can make picks under.
+const maxPickerLength = 40
+
+type pickemData struct {
+	Picker      string
+	Week        *scheduleWeek     // Next week's matches. Nil if there are none.
+	Picks       map[string]string // The team the picker picked in each match, keyed by match.
+	Started     map[string]bool   // Matches that have started, and can no longer be picked.
+	Leaderboard *pickem.Result
+	Message     string
+	Error       string
+}
+
+func (s *Server) handlePickem(w http.ResponseWriter, r *http.Request) {
+	var picker string
+	if c, err := r.Cookie(pickerCookie); err == nil {
+		picker = c.Value
+	}
+	s.renderPickem(w, r, http.StatusOK, pickemData{Picker: picker})
+}
+
+func (s *Server) handlePickemSubmit(w http.ResponseWriter, r *http.Request) {
+	data := pickemData{Picker: strings.TrimSpace(r.FormValue("picker"))}
+	switch {
+	case data.Picker == "":
+		data.Error = "Enter your name to make picks."
+		s.renderPickem(w, r, http.StatusBadRequest, data)
+		return
+	case len(data.Picker) > maxPickerLength:
+		data.Error = fmt.Sprintf("Names can be at most %d characters.", maxPickerLength)
+		s.renderPickem(w, r, http.StatusBadRequest, data)
+		return
+	}
+
+	token, err := pickerToken(r)
+	if err != nil {
+		s.log.Error("generate picker token", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	err = s.store.ClaimPicker(r.Context(), data.Picker, token)
+	if errors.Is(err, db.ErrPickerClaimed) {
+		data.Error = fmt.Sprintf("Someone else makes picks as %s. Choose another name.", data.Picker)
+		data.Picker = ""
+		s.renderPickem(w, r, http.StatusForbidden, data)
+		return
+	}
+	if err != nil {
+		s.log.Error("claim picker", "picker", data.Picker, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	for _, c := range [][2]string{{pickerCookie, data.Picker}, {pickerTokenCookie, token}} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     c[0],
+			Value:    c[1],
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	week, err := s.nextWeek(r)
+	if err != nil {
+		s.log.Error("list schedule", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	saved := 0
+	for _, m := range week.Matches {
+		team := r.FormValue("pick-" + m.Key)
+		if team == "" || s.started(m) {
+			continue
+		}
+		err := s.store.UpsertPick(r.Context(), db.Pick{Picker: data.Picker, MatchKey: m.Key, TeamKey: team})
+		if errors.Is(err, db.ErrPickRejected) {
+			continue
+		}
+		if err != nil {
+			s.log.Error("upsert pick", "picker", data.Picker, "match", m.Key, "err", err)
+			data.Error = "Couldn't save your picks. Please try again."
+			break
+		}
+		saved++
+	}
+
+	if data.Error == "" {
+		data.Message = fmt.Sprintf("Saved %d picks for %s.", saved, data.Picker)
+		if saved == 1 {
+			data.Message = fmt.Sprintf("Saved 1 pick for %s.", data.Picker)
+		}
+	}
+	s.renderPickem(w, r, http.StatusOK, data)
+}
+
+// pickerToken returns the visitor's picker token, or a new random one if they
+// don't have one yet.
+func pickerToken(r *http.Request) (string, error) {
+	if c, err := r.Cookie(pickerTokenCookie); err == nil && c.Value != "" {
+		return c.Value, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *Server) renderPickem(w http.ResponseWriter, r *http.Request, status int, data pickemData) {
+	ctx := r.Context()
+
+	week, err := s.nextWeek(r)
+	if err != nil {
+		s.log.Error("list schedule", "err", err)
+	}
+	if week != nil && len(week.Matches) > 0 {
+		data.Week = week
+		data.Started = make(map[string]bool)
+		for _, m := range week.Matches {
+			data.Started[m.Key] = s.started(m)
+		}
+	}
+
+	if data.Picker != "" {
+		picks, err := s.store.ListPicks(ctx, data.Picker)
+		if err != nil {
+			s.log.Error("list picks", "picker", data.Picker, "err", err)
+		}
+		data.Picks = make(map[string]string, len(picks))
+		for _, p := range picks {
+			data.Picks[p.MatchKey] = p.TeamKey
+		}
+	}
+
+	var opts []pickem.Option
+	if seasons, err := s.store.ListSeasons(ctx); err == nil && len(seasons) > 0 {
+		opts = append(opts, pickem.InSeason(seasons[0]))
+	}
+	data.Leaderboard, err = pickem.Analyze(ctx, s.store, opts...)
+	if err != nil {
+		s.log.Error("pickem leaderboard", "err", err)
+	}
+
+	s.renderStatus(w, r, status, s.template.pickem, data)
+}
+
+// nextWeek returns the next week of matches on the schedule, which may be
+// tonight's. It returns an empty week if there are no more matches.
+func (s *Server) nextWeek(r *http.Request) (*scheduleWeek, error) {
+	matches, err := s.store.ListSchedule(r.Context(), s.clock.Today(s.now()))
+	if err != nil {
+		return nil, err
+	}
+	weeks := groupByWeek(matches)
+	if len(weeks) == 0 {
+		return &scheduleWeek{}, nil
+	}
+	return &weeks[0], nil
+}
+
+// started returns true if the supplied match has started. Matches with an
+// unparseable date are never considered started.
+func (s *Server) started(m db.ScheduleMatch) bool {
+	start, err := s.clock.StartTime(m.Date)
+	if err != nil {
+		return false
+	}
+	return !s.now().Before(start)
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"cmp"
+	"html/template"
+	"slices"
+	"strings"
+
+	"github.com/negz/mnp/internal/chart"
+	"github.com/negz/mnp/internal/era"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+const (
+	// radarMachines is the most machines a player's radar chart shows.
+	radarMachines = 8
+
+	// radarMinAxes is the fewest axes a radar chart can draw.
+	radarMinAxes = 3
+
+	// radarMax is the P50 relative to the league's at the chart's outer ring.
+	// The league's P50 is halfway out.
+	radarMax = 2
+)
+
+// Radar returns an SVG chart comparing the player's P50 with the league's, or
+// an empty string if they've played too few machines to chart. It charts one
+// axis per machine era if the player has played machines from enough eras,
+// and their most played machines otherwise.
+func (d playerData) Radar() template.HTML {
+	var b strings.Builder
+	if err := d.radar().WriteSVG(&b); err != nil {
+		return ""
+	}
+	return template.HTML(b.String()) //nolint:gosec // WriteSVG escapes text.
+}
+
+// radar returns the player's radar chart, by era if it can be and by machine
+// otherwise.
+func (d playerData) radar() chart.Radar {
+	stats := slices.DeleteFunc(slices.Clone(d.Result.GlobalStats), func(s player.MachineStats) bool {
+		return s.LeagueP50 <= 0
+	})
+	if r, ok := d.eraRadar(stats); ok {
+		return r
+	}
+	return d.machineRadar(stats)
+}
+
+// eraRadar returns a radar chart with one axis per machine era. Each axis is
+// the player's P50 relative to the league's on machines from that era,
+// weighted by how many games they've played on each machine. It returns false
+// if the player has played machines from too few eras with known years.
+func (d playerData) eraRadar(stats []player.MachineStats) (chart.Radar, bool) {
+	type total struct {
+		relative float64 // Sum of relative P50 times games.
+		games    int
+	}
+	totals := make(map[era.Era]total)
+	for _, s := range stats {
+		e := era.Of(d.Years[s.MachineKey])
+		if e == era.Unknown || s.Games == 0 {
+			continue
+		}
+		t := totals[e]
+		t.relative += s.P50Score / s.LeagueP50 * float64(s.Games)
+		t.games += s.Games
+		totals[e] = t
+	}
+	if len(totals) < radarMinAxes {
+		return chart.Radar{}, false
+	}
+
+	r := chart.Radar{
+		Title:  d.Name + "'s P50 compared with the league's by era",
+		Series: []chart.Series{{Name: d.Name}, {Name: "League"}},
+		Max:    radarMax,
+	}
+	for _, e := range era.All() {
+		t, ok := totals[e]
+		if !ok {
+			continue
+		}
+		r.Axes = append(r.Axes, string(e))
+		r.Series[0].Values = append(r.Series[0].Values, t.relative/float64(t.games))
+		r.Series[1].Values = append(r.Series[1].Values, 1)
+	}
+	return r, true
+}
+
+// machineRadar returns a radar chart with one axis per machine, for the
+// player's most played machines.
+func (d playerData) machineRadar(stats []player.MachineStats) chart.Radar {
+	slices.SortStableFunc(stats, func(a, b player.MachineStats) int {
+		return cmp.Compare(b.Games, a.Games)
+	})
+	stats = stats[:min(len(stats), radarMachines)]
+
+	r := chart.Radar{
+		Title:  d.Name + "'s P50 compared with the league's",
+		Series: []chart.Series{{Name: d.Name}, {Name: "League"}},
+		Max:    radarMax,
+	}
+	for _, s := range stats {
+		r.Axes = append(r.Axes, s.MachineKey)
+		r.Series[0].Values = append(r.Series[0].Values, s.P50Score/s.LeagueP50)
+		r.Series[1].Values = append(r.Series[1].Values, 1)
+	}
+	return r
+}
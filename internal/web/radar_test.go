@@ -0,0 +1,66 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/chart"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+func TestRadarAxes(t *testing.T) {
+	stats := []player.MachineStats{
+		{MachineKey: "TAF", Games: 3, P50Score: 150, LeagueP50: 100},
+		{MachineKey: "TZ", Games: 1, P50Score: 50, LeagueP50: 100},
+		{MachineKey: "Paragon", Games: 2, P50Score: 100, LeagueP50: 100},
+		{MachineKey: "Godzilla", Games: 4, P50Score: 50, LeagueP50: 100},
+		{MachineKey: "Unranked", Games: 5, P50Score: 50},
+	}
+
+	cases := map[string]struct {
+		reason string
+		years  map[string]int
+		want   chart.Radar
+	}{
+		"ByEra": {
+			reason: "A player who has played machines from three or more eras should be charted by era, weighting each machine by games played.",
+			years:  map[string]int{"TAF": 1992, "TZ": 1993, "Paragon": 1979, "Godzilla": 2021},
+			want: chart.Radar{
+				Title:  "Alice's P50 compared with the league's by era",
+				Axes:   []string{"SS", "DMD", "LCD"},
+				Series: []chart.Series{{Name: "Alice", Values: []float64{1, 1.25, 0.5}}, {Name: "League", Values: []float64{1, 1, 1}}},
+				Max:    radarMax,
+			},
+		},
+		"TooFewEras": {
+			reason: "A player who has played machines from fewer than three eras should be charted by machine, most played first.",
+			years:  map[string]int{"TAF": 1992, "TZ": 1993, "Godzilla": 2021},
+			want: chart.Radar{
+				Title:  "Alice's P50 compared with the league's",
+				Axes:   []string{"Godzilla", "TAF", "Paragon", "TZ"},
+				Series: []chart.Series{{Name: "Alice", Values: []float64{0.5, 1.5, 1, 0.5}}, {Name: "League", Values: []float64{1, 1, 1, 1}}},
+				Max:    radarMax,
+			},
+		},
+		"NoYears": {
+			reason: "A player whose machines' years aren't known should be charted by machine.",
+			want: chart.Radar{
+				Title:  "Alice's P50 compared with the league's",
+				Axes:   []string{"Godzilla", "TAF", "Paragon", "TZ"},
+				Series: []chart.Series{{Name: "Alice", Values: []float64{0.5, 1.5, 1, 0.5}}, {Name: "League", Values: []float64{1, 1, 1, 1}}},
+				Max:    radarMax,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := playerData{Name: "Alice", Result: &player.Result{GlobalStats: stats}, Years: tc.years}
+			got := d.radar()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nradar(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Fill in the scout, matchup, and recommend forms' choices from the JSON API,
+// rather than rendering every team and machine into every page.
+(function () {
+  "use strict";
+
+  function getJSON(url) {
+    return fetch(url).then(function (rsp) {
+      if (!rsp.ok) {
+        throw new Error(url + ": " + rsp.status);
+      }
+      return rsp.json();
+    });
+  }
+
+  // A machine select lists the machines at the venue chosen by the form field
+  // its data-machines attribute names, or every machine if none is chosen.
+  document.querySelectorAll("select[data-machines]").forEach(function (sel) {
+    var venue = sel.form.elements[sel.dataset.machines];
+    var url = "/api/v1/machines";
+    if (venue && venue.value) {
+      url += "?venue=" + encodeURIComponent(venue.value);
+    }
+    getJSON(url).then(function (rsp) {
+      var selected = sel.value;
+      sel.length = 1; // Keep the placeholder.
+      rsp.machines.forEach(function (m) {
+        sel.add(new Option(m.name, m.key, false, m.key === selected));
+      });
+    }).catch(console.error);
+  });
+
+  // A team input suggests this season's teams matching what's been typed, and
+  // submits its form once one is picked.
+  document.querySelectorAll("input[data-teams]").forEach(function (input) {
+    var keys = {};
+    function suggest() {
+      getJSON("/api/v1/teams?q=" + encodeURIComponent(input.value.trim())).then(function (rsp) {
+        keys = {};
+        input.list.replaceChildren.apply(input.list, rsp.teams.map(function (t) {
+          keys[t.key] = true;
+          return new Option(t.name, t.key);
+        }));
+      }).catch(console.error);
+    }
+    input.addEventListener("focus", suggest, { once: true });
+    input.addEventListener("input", function () {
+      if (keys[input.value]) {
+        input.form.requestSubmit();
+        return;
+      }
+      suggest();
+    });
+  });
+})();
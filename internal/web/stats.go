@@ -0,0 +1,138 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/strategy/recommend"
+	"github.com/negz/mnp/internal/strategy/scout"
+)
+
+// statsTable is a table of P50 and P90 stats, with a row per machine or
+// player. Pages build one and render it with the "stats-table" template, so
+// a column added here and there appears on every page that shows stats.
+type statsTable struct {
+	Caption       string
+	Subject       string // Heading of the first column, e.g. "Machine".
+	IPR           bool   // Show each row's IPR.
+	LikelyPlayers bool   // Show each row's likely players.
+	Rows          []statsRow
+}
+
+// statsRow is one machine or player in a statsTable.
+type statsRow struct {
+	Name          string
+	URL           string // Links the name when set.
+	Note          string // Footnotes the name when set.
+	Games         int
+	GamesNote     string // Shown after the games count when set.
+	P50Score      float64
+	LeagueP50     float64
+	P90Score      float64
+	IPR           int
+	LikelyPlayers []likelyPlayer
+}
+
+// likelyPlayer is a player likely to play a machine, rendered with the
+// "likely-players" template.
+type likelyPlayer struct {
+	Name       string
+	P50Score   float64
+	Attendance db.Attendance
+}
+
+// StatsTable returns the team's stats on each machine.
+func (d scoutData) StatsTable() statsTable {
+	t := statsTable{
+		Caption:       d.TeamName + " machine stats",
+		Subject:       "Machine",
+		LikelyPlayers: true,
+	}
+	for _, s := range d.Result.GlobalStats {
+		row := statsRow{
+			Name:      s.MachineName,
+			URL:       "/t/" + d.Team + "/recommend/" + s.MachineKey,
+			Games:     s.Games,
+			P50Score:  s.P50Score,
+			LeagueP50: s.LeagueP50,
+			P90Score:  s.P90Score,
+		}
+		if d.Result.Blended {
+			row.GamesNote = fmt.Sprintf("%d this season", s.CurrentGames)
+		}
+		row.LikelyPlayers = likelyPlayers(s.LikelyPlayers)
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// VenueTable returns the team's players' stats on the machine at the venue.
+func (d recommendData) VenueTable() statsTable {
+	return playerStatsTable(d.TeamName+" players at "+d.Result.Venue, d.Result.VenueStats)
+}
+
+// GlobalTable returns the team's players' stats on the machine league-wide.
+func (d recommendData) GlobalTable() statsTable {
+	return playerStatsTable(d.TeamName+" players league-wide", d.Result.GlobalStats)
+}
+
+// OpponentTable returns the opponent's likely players' stats on the machine.
+func (d recommendData) OpponentTable() statsTable {
+	return playerStatsTable(d.Result.Opponent+" likely players", d.Result.OpponentStats)
+}
+
+// StatsTable returns the player's stats on each machine. Machines link to the
+// recommend page for the player's team, if they have one.
+func (d playerData) StatsTable() statsTable {
+	t := statsTable{
+		Caption: d.Name + " machine stats",
+		Subject: "Machine",
+	}
+	for _, s := range d.Result.GlobalStats {
+		row := statsRow{
+			Name:      s.MachineName,
+			Games:     s.Games,
+			P50Score:  s.P50Score,
+			LeagueP50: s.LeagueP50,
+			P90Score:  s.P90Score,
+		}
+		if d.Result.Team != nil {
+			row.URL = "/t/" + d.Result.Team.Key + "/recommend/" + s.MachineKey
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+func playerStatsTable(caption string, stats []recommend.PlayerStats) statsTable {
+	t := statsTable{
+		Caption: caption,
+		Subject: "Player",
+		IPR:     true,
+	}
+	for _, s := range stats {
+		row := statsRow{
+			Name:      s.Name,
+			URL:       "/p/" + url.PathEscape(s.Name),
+			Games:     s.Games,
+			P50Score:  s.P50Score,
+			LeagueP50: s.LeagueP50,
+			P90Score:  s.P90Score,
+			IPR:       s.IPR,
+		}
+		if s.NoVenueData {
+			row.Note = "No venue data"
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+func likelyPlayers(players []scout.LikelyPlayer) []likelyPlayer {
+	result := make([]likelyPlayer, len(players))
+	for i, p := range players {
+		result[i] = likelyPlayer{Name: p.Name, P50Score: p.P50Score, Attendance: p.Attendance}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/negz/mnp/internal/schedule"
+)
+
+// tzCookie remembers a visitor's timezone. It's set by a script in the page
+// layout, or by passing a "tz" query parameter.
+const tzCookie = "tz"
+
+// viewerLocation returns the timezone to show times in for a request, falling
+// back to the league's timezone.
+func (s *Server) viewerLocation(w http.ResponseWriter, r *http.Request) *time.Location {
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tzCookie,
+				Value:    tz,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				SameSite: http.SameSiteLaxMode,
+			})
+			return loc
+		}
+	}
+	if c, err := r.Cookie(tzCookie); err == nil {
+		if loc, err := time.LoadLocation(c.Value); err == nil {
+			return loc
+		}
+	}
+	return s.clock.Location
+}
+
+// formatMatchTime returns a function that formats when matches on a date
+// start, in the viewer's timezone. The timezone is named when it differs from
+// the league's.
+func formatMatchTime(c schedule.Clock, viewer *time.Location) func(string) string {
+	return func(date string) string {
+		t, err := c.StartTime(date)
+		if err != nil {
+			return date
+		}
+		local := t.In(viewer)
+		if viewer.String() == c.Location.String() {
+			return local.Format("Mon Jan 2, 3:04 PM")
+		}
+		return local.Format("Mon Jan 2, 3:04 PM MST")
+	}
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/negz/mnp/internal/chart"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/strategy/player"
+)
+
+// lineChart returns an SVG line chart of the supplied values, or an empty
+// string if there are too few values to chart.
+func lineChart(title string, values []float64) template.HTML {
+	var b strings.Builder
+	if err := (chart.Line{Title: title, Values: values}).WriteSVG(&b); err != nil {
+		return ""
+	}
+	return template.HTML(b.String()) //nolint:gosec // WriteSVG escapes text.
+}
+
+// gameLogChart returns an SVG chart of a player's score in each game of a game
+// log, or an empty string if there are too few games to chart.
+func gameLogChart(l player.GameLog) template.HTML {
+	t := chart.Timeline{
+		Title:  fmt.Sprintf("%s scores in season %d", l.MachineName, l.Season),
+		Format: output.FormatScore,
+	}
+	for _, g := range l.Games {
+		title := fmt.Sprintf("Week %d: %s", g.Week, output.FormatScore(float64(g.Score)))
+		if g.Date != "" {
+			title += " (" + g.Date + ")"
+		}
+		t.Points = append(t.Points, chart.Point{Label: fmt.Sprintf("Wk %d", g.Week), Value: float64(g.Score), Title: title})
+	}
+
+	var b strings.Builder
+	if err := t.WriteSVG(&b); err != nil {
+		return ""
+	}
+	return template.HTML(b.String()) //nolint:gosec // WriteSVG escapes text.
+}
+
+// monthlyP50s returns each machine's P50 over its last months, oldest first.
+// Stats must be ordered by machine and then oldest month first.
+func monthlyP50s(stats []db.MonthlyStats, months int) map[string][]float64 {
+	p50s := make(map[string][]float64)
+	for _, ms := range stats {
+		p50s[ms.MachineKey] = append(p50s[ms.MachineKey], ms.P50Score)
+	}
+	for k, v := range p50s {
+		p50s[k] = v[max(0, len(v)-months):]
+	}
+	return p50s
+}
@@ -2,6 +2,8 @@
 package web
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"embed"
 	"fmt"
@@ -11,17 +13,29 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/card"
 	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/imgcache"
 	"github.com/negz/mnp/internal/output"
+	"github.com/negz/mnp/internal/schedule"
+	"github.com/negz/mnp/internal/strategy/accuracy"
+	"github.com/negz/mnp/internal/strategy/history"
 	"github.com/negz/mnp/internal/strategy/matchup"
+	"github.com/negz/mnp/internal/strategy/pickem"
 	"github.com/negz/mnp/internal/strategy/player"
+	"github.com/negz/mnp/internal/strategy/practice"
+	"github.com/negz/mnp/internal/strategy/recap"
 	"github.com/negz/mnp/internal/strategy/recommend"
+	"github.com/negz/mnp/internal/strategy/roster"
 	"github.com/negz/mnp/internal/strategy/scout"
+	"github.com/negz/mnp/internal/strategy/seasons"
+	"github.com/negz/mnp/internal/strategy/streaks"
 	"github.com/negz/mnp/internal/version"
 )
 
@@ -32,48 +46,199 @@ var static embed.FS
 var tmpls embed.FS
 
 type serverTemplate struct {
-	home      *template.Template
-	team      *template.Template
-	matchup   *template.Template
-	recommend *template.Template
-	scout     *template.Template
-	player    *template.Template
-	teams     *template.Template
+	home        *template.Template
+	team        *template.Template
+	matchup     *template.Template
+	recommend   *template.Template
+	scout       *template.Template
+	player      *template.Template
+	teams       *template.Template
+	standings   *template.Template
+	venues      *template.Template
+	machines    *template.Template
+	accuracy    *template.Template
+	seasons     *template.Template
+	practice    *template.Template
+	avatars     *template.Template
+	anomalies   *template.Template
+	match       *template.Template
+	pickem      *template.Template
+	players     *template.Template
+	search      *template.Template
+	leaderboard *template.Template
 }
 
 // Server serves the MNP web UI.
 type Server struct {
 	store    cache.Store
+	blended  scout.Store
 	log      *slog.Logger
 	template serverTemplate
+
+	avatarDir  string
+	adminToken string
+	art        *imgcache.Cache
+	locale     *output.Locale
+	fullScores bool
+	clock      schedule.Clock
+	assets     *assetFS
+	metrics    *Metrics
+	ready      func() bool
+	now        func() time.Time
+}
+
+// An analysisCache serves scout and matchup analyses from memory. The server
+// uses it when its store is one, such as a cache.InMemoryStore.
+type analysisCache interface {
+	Scout(ctx context.Context, team, venue string) (*scout.Result, error)
+	Matchup(ctx context.Context, venue, team1, team2 string) (*matchup.Result, error)
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithAvatarDir serves player avatars from the supplied directory. Avatars are
+// stored as <slug>.png, .jpg, or .webp, where the slug is the lowercased player
+// name with runs of non-alphanumeric characters replaced by a dash (e.g.
+// jay-ostby.png). Players without an avatar get a generated placeholder.
+func WithAvatarDir(dir string) ServerOption {
+	return func(s *Server) {
+		s.avatarDir = dir
+	}
+}
+
+// WithBlendedStore lets visitors blend seasons when scouting, using the
+// supplied store, whose stats should weight recent seasons over older ones per
+// scout.BlendWeight. By default the scout page doesn't offer blending.
+func WithBlendedStore(st scout.Store) ServerOption {
+	return func(s *Server) {
+		s.blended = st
+	}
+}
+
+// WithMachineArt shows machine backglass thumbnails, served from the supplied
+// image cache.
+func WithMachineArt(c *imgcache.Cache) ServerOption {
+	return func(s *Server) {
+		s.art = c
+	}
+}
+
+// WithLocale formats numbers for the supplied locale, rather than the locale
+// each visitor's browser asks for.
+func WithLocale(l output.Locale) ServerOption {
+	return func(s *Server) {
+		s.locale = &l
+	}
+}
+
+// WithFullScores writes every digit of scores by default, rather than
+// abbreviating them (e.g. 2.5B). Visitors can still switch from the footer.
+func WithFullScores() ServerOption {
+	return func(s *Server) {
+		s.fullScores = true
+	}
+}
+
+// WithClock sets the league's timezone and match times. By default the server
+// uses schedule.DefaultTimezone.
+func WithClock(c schedule.Clock) ServerOption {
+	return func(s *Server) {
+		s.clock = c
+	}
+}
+
+// WithAdminToken enables admin pages, such as avatar uploads, protected by the
+// supplied token.
+func WithAdminToken(token string) ServerOption {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithMetrics records request metrics and serves them, along with the
+// supplied metrics' sync and database metrics, at /metrics.
+func WithMetrics(m *Metrics) ServerOption {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+// WithReadyCheck reports the server ready at /readyz only once ready returns
+// true, for example once its store has data to serve. By default the server is
+// ready as soon as it's serving.
+func WithReadyCheck(ready func() bool) ServerOption {
+	return func(s *Server) {
+		s.ready = ready
+	}
 }
 
 // NewServer returns a new Server.
-func NewServer(store cache.Store, log *slog.Logger) *Server {
-	return &Server{
+func NewServer(store cache.Store, log *slog.Logger, opts ...ServerOption) *Server {
+	clock, err := schedule.NewClock(schedule.DefaultTimezone)
+	if err != nil {
+		log.Warn("cannot load league timezone, using UTC", "err", err)
+		clock = schedule.Clock{Location: time.UTC, Start: schedule.DefaultStart, Rollover: schedule.DefaultRollover}
+	}
+
+	s := &Server{
 		store: store,
 		log:   log,
-		template: serverTemplate{
-			home:      parseTemplates("templates/home.html"),
-			team:      parseTemplates("templates/team.html"),
-			matchup:   parseTemplates("templates/matchup.html"),
-			recommend: parseTemplates("templates/recommend.html"),
-			scout:     parseTemplates("templates/scout.html"),
-			player:    parseTemplates("templates/player.html"),
-			teams:     parseTemplates("templates/teams.html"),
-		},
+		clock: clock,
+		now:   time.Now,
+	}
+	for _, o := range opts {
+		o(s)
 	}
+
+	staticFS, _ := fs.Sub(static, "static")
+	assets, err := newAssetFS(staticFS)
+	if err != nil {
+		log.Warn("cannot fingerprint static files, serving them unversioned", "err", err)
+	}
+	s.assets = assets
+
+	funcs := s.templateFuncs()
+	s.template = serverTemplate{
+		home:        parseTemplates(funcs, "templates/home.html"),
+		team:        parseTemplates(funcs, "templates/team.html"),
+		matchup:     parseTemplates(funcs, "templates/matchup.html"),
+		recommend:   parseTemplates(funcs, "templates/recommend.html"),
+		scout:       parseTemplates(funcs, "templates/scout.html"),
+		player:      parseTemplates(funcs, "templates/player.html"),
+		teams:       parseTemplates(funcs, "templates/teams.html"),
+		standings:   parseTemplates(funcs, "templates/standings.html"),
+		venues:      parseTemplates(funcs, "templates/venues.html"),
+		machines:    parseTemplates(funcs, "templates/machines.html"),
+		accuracy:    parseTemplates(funcs, "templates/accuracy.html"),
+		seasons:     parseTemplates(funcs, "templates/seasons.html"),
+		practice:    parseTemplates(funcs, "templates/practice.html"),
+		avatars:     parseTemplates(funcs, "templates/avatars.html"),
+		anomalies:   parseTemplates(funcs, "templates/anomalies.html"),
+		match:       parseTemplates(funcs, "templates/match.html"),
+		pickem:      parseTemplates(funcs, "templates/pickem.html"),
+		players:     parseTemplates(funcs, "templates/players.html"),
+		search:      parseTemplates(funcs, "templates/search.html"),
+		leaderboard: parseTemplates(funcs, "templates/leaderboard.html"),
+	}
+	return s
 }
 
 // Handler returns an http.Handler with all routes registered.
 func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 
-	staticFS, _ := fs.Sub(static, "static")
-	mux.Handle("GET /static/", WithCacheControl(http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))), "public, max-age=86400"))
+	mux.Handle("GET /static/", s.assets)
 	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if s.ready != nil && !s.ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
 	mux.HandleFunc("GET /robots.txt", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
 	})
@@ -85,10 +250,19 @@ func (s *Server) Handler() http.Handler {
 
 	mux.HandleFunc("GET /t/{team}", s.handleTeam)
 
+	mux.HandleFunc("GET /m/{match}", s.handleMatch)
+
 	mux.HandleFunc("GET /matchup", s.handleMatchup)
 
 	mux.HandleFunc("GET /t/{team}/scout", s.handleScout)
 
+	mux.HandleFunc("GET /t/{team}/seasons", s.handleSeasons)
+
+	mux.HandleFunc("GET /t/{team}/practice", s.handlePractice)
+
+	mux.HandleFunc("GET /calendar.ics", s.handleCalendar)
+	mux.HandleFunc("GET /t/{team}/calendar.ics", s.handleCalendar)
+
 	mux.HandleFunc("GET /scout", func(w http.ResponseWriter, r *http.Request) {
 		team := strings.ToUpper(r.URL.Query().Get("team"))
 		if team != "" {
@@ -104,12 +278,40 @@ func (s *Server) Handler() http.Handler {
 		s.handleScoutForm(w, r)
 	})
 
+	mux.HandleFunc("GET /players", s.handlePlayers)
+
+	mux.HandleFunc("GET /search", s.handleSearch)
+
 	mux.HandleFunc("GET /p/{name...}", s.handlePlayer)
 
+	mux.HandleFunc("GET /avatars/{name...}", s.handleAvatar)
+
+	mux.HandleFunc("GET /cards/{name...}", s.handleCard)
+
+	mux.HandleFunc("GET /art/{key}", s.handleArt)
+
+	mux.HandleFunc("GET /admin/avatars", s.handleAvatarAdmin)
+	mux.HandleFunc("POST /admin/avatars", s.handleAvatarUpload)
+	mux.HandleFunc("GET /admin/anomalies", s.handleAnomalies)
+	mux.HandleFunc("POST /admin/anomalies", s.handleAnomaliesCheck)
+
 	mux.HandleFunc("GET /t/{team}/recommend/{machine}", s.handleRecommend)
 
 	mux.HandleFunc("GET /teams", s.handleTeams)
 
+	mux.HandleFunc("GET /standings", s.handleStandings)
+
+	mux.HandleFunc("GET /venues", s.handleVenues)
+
+	mux.HandleFunc("GET /machines", s.handleMachines)
+
+	mux.HandleFunc("GET /leaderboard", s.handleLeaderboard)
+
+	mux.HandleFunc("GET /model/accuracy", s.handleAccuracy)
+
+	mux.HandleFunc("GET /pickem", s.handlePickem)
+	mux.HandleFunc("POST /pickem", s.handlePickemSubmit)
+
 	mux.HandleFunc("GET /recommend", func(w http.ResponseWriter, r *http.Request) {
 		team := strings.ToUpper(r.URL.Query().Get("team"))
 		machine := r.URL.Query().Get("machine")
@@ -127,6 +329,13 @@ func (s *Server) Handler() http.Handler {
 		s.handleRecommendForm(w, r)
 	})
 
+	s.apiRoutes(mux)
+
+	if s.metrics != nil {
+		mux.Handle("GET /metrics", s.metrics)
+		return s.metrics.instrument(mux)
+	}
+
 	return mux
 }
 
@@ -161,49 +370,80 @@ func WithCacheControl(next http.Handler, value string) http.Handler {
 	})
 }
 
-func newTemplateFuncs() template.FuncMap {
+func (s *Server) templateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"version": func() string { return version.Version },
-		"formatScore": func(score float64) string {
-			if score == 0 {
-				return "-"
-			}
-			return output.FormatScore(score)
-		},
-		"formatEdge": func(pct float64, team1, team2 string, conf matchup.Confidence) string {
+		"version":     func() string { return version.Version },
+		"asset":       s.assets.URL,
+		"formatScore": formatScore(output.ScoreFormat{}),
+		"formatEdge": func(pct float64, team1, team2 string, conf matchup.Confidence) template.HTML {
 			if math.IsInf(pct, 0) || pct > 1e15 || pct < -1e15 {
 				if pct > 0 {
-					return team1
+					return template.HTML(template.HTMLEscapeString(team1)) //nolint:gosec // Escaped.
 				}
-				return team2
+				return template.HTML(template.HTMLEscapeString(team2)) //nolint:gosec // Escaped.
 			}
 			rounded := int(math.Round(pct))
-			icon := confidenceIcon(conf)
+			icon := fmt.Sprintf(`<span role="img" aria-label="%[1]s" title="%[1]s">%[2]s</span>`, confidenceLabel(conf), confidenceIcon(conf))
 			switch {
 			case rounded > 0:
-				return fmt.Sprintf("%s %d%% %s", team1, rounded, icon)
+				return template.HTML(fmt.Sprintf("%s %d%% %s", template.HTMLEscapeString(team1), rounded, icon)) //nolint:gosec // Escaped.
 			case rounded < 0:
-				return fmt.Sprintf("%s %d%% %s", team2, -rounded, icon)
+				return template.HTML(fmt.Sprintf("%s %d%% %s", template.HTMLEscapeString(team2), -rounded, icon)) //nolint:gosec // Escaped.
 			default:
 				return "Even"
 			}
 		},
-		"join": strings.Join,
-		"formatP50": func(p50, leagueP50 float64) string {
-			if p50 == 0 {
+		"join":         strings.Join,
+		"formatP50":    formatP50(output.ScoreFormat{}),
+		"scoreFormat":  func() output.ScoreFormat { return output.ScoreFormat{} },
+		"fullScores":   func() bool { return false },
+		"scoresURL":    func(string) string { return "" },
+		"matchTime":    formatMatchTime(s.clock, s.clock.Location),
+		"dataAsOf":     func() string { return "" },
+		"syncFailed":   func() string { return "" },
+		"formatRelStr": output.FormatRelStr,
+		"formatChange": output.FormatChange,
+		"formatRank":   func(rank float64) string { return fmt.Sprintf("%.0f%%", rank*100) },
+		"shortName":    output.ShortPlayerName,
+		"pathEscape":   url.PathEscape,
+		"formatIPR":    output.FormatIPR,
+		"formatPoints": output.FormatPoints,
+		"inc":          func(i int) int { return i + 1 },
+		"lineChart":    lineChart,
+		"gameLogChart": gameLogChart,
+		"formatRetention": func(rr db.RosterRetention) string {
+			return output.FormatRetention(rr.Kept, rr.Previous)
+		},
+		"formatAttendance": func(a db.Attendance) string {
+			return output.FormatAttendance(a.Matches, a.TeamMatches) + " this season"
+		},
+		"formatAccuracy": func(t accuracy.Tally) string {
+			if t.Predictions == t.Pushes {
+				return "-"
+			}
+			return fmt.Sprintf("%.0f%%", t.Accuracy()*100)
+		},
+		"formatPickAccuracy": func(s pickem.Standing) string {
+			if s.Picks == s.Pushes {
 				return "-"
 			}
-			return output.FormatP50(p50, leagueP50)
+			return fmt.Sprintf("%.0f%%", s.Accuracy()*100)
 		},
-		"formatRelStr": output.FormatRelStr,
-		"shortName": func(name string) string {
-			if first, last, ok := strings.Cut(name, " "); ok {
-				return first + " " + last[:1]
+		"formatPointsPct": func(s seasons.Season) string {
+			if s.Points.Matches == 0 {
+				return "-"
 			}
-			return name
+			return fmt.Sprintf("%.0f%%", s.PointsPct()*100)
 		},
-		"pathEscape": url.PathEscape,
-		"formatIPR":  output.FormatIPR,
+		"heat": func(n, most int) float64 {
+			if most == 0 {
+				return 0
+			}
+			return float64(n) / float64(most)
+		},
+		"confidenceLabel": confidenceLabel,
+		"avatarURL":       avatarURL,
+		"artURL":          s.artURL,
 	}
 }
 
@@ -220,9 +460,24 @@ func confidenceIcon(c matchup.Confidence) string {
 	}
 }
 
-func parseTemplates(pages ...string) *template.Template {
-	files := append([]string{"templates/layout.html"}, pages...)
-	return template.Must(template.New("layout.html").Funcs(newTemplateFuncs()).ParseFS(tmpls, files...))
+// confidenceLabel returns a text alternative for a confidence icon, for screen
+// readers and tooltips.
+func confidenceLabel(c matchup.Confidence) string {
+	switch c {
+	case matchup.ConfidenceHigh:
+		return "high confidence"
+	case matchup.ConfidenceMedium:
+		return "medium confidence"
+	case matchup.ConfidenceLow:
+		return "low confidence"
+	default:
+		return "low confidence"
+	}
+}
+
+func parseTemplates(funcs template.FuncMap, pages ...string) *template.Template {
+	files := append([]string{"templates/layout.html", "templates/partials.html"}, pages...)
+	return template.Must(template.New("layout.html").Funcs(funcs).ParseFS(tmpls, files...))
 }
 
 // Sync runs a data sync using the provided function, then repeats every
@@ -251,6 +506,7 @@ func Sync(ctx context.Context, syncFn func(context.Context) error, interval time
 
 type scheduleWeek struct {
 	Week    int
+	Name    string // e.g. "Week 3", or "Playoffs SF".
 	Date    string
 	Matches []db.ScheduleMatch
 }
@@ -258,11 +514,17 @@ type scheduleWeek struct {
 type homeData struct {
 	Weeks       []scheduleWeek
 	CurrentWeek int
+
+	// Next is the next match night and how long until it starts, if any.
+	Next      *scheduleWeek
+	Countdown string
+
+	// YearAgo is the most notable scores from this week last year.
+	YearAgo *history.Result
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
-	seattle, _ := time.LoadLocation("America/Los_Angeles")
-	today := time.Now().In(seattle).Format("2006-01-02")
+	today := s.clock.Today(s.now())
 	matches, err := s.store.ListSchedule(r.Context(), "")
 	if err != nil {
 		s.log.Error("list schedule", "err", err)
@@ -272,13 +534,19 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 
 	weeks := groupByWeek(matches)
 
-	currentWeek := 0
-	for _, wk := range weeks {
+	data := homeData{Weeks: weeks}
+	for i, wk := range weeks {
 		if wk.Date >= today {
-			currentWeek = wk.Week
+			data.Next = &weeks[i]
+			data.Countdown = s.clock.Countdown(s.now(), wk.Date)
 			break
 		}
 	}
+
+	currentWeek := 0
+	if data.Next != nil {
+		currentWeek = data.Next.Week
+	}
 	if currentWeek == 0 && len(weeks) > 0 {
 		currentWeek = weeks[len(weeks)-1].Week
 	}
@@ -288,16 +556,24 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := s.template.home.ExecuteTemplate(w, "layout.html", homeData{Weeks: weeks, CurrentWeek: currentWeek}); err != nil {
-		s.log.Error("render template", "err", err)
+	data.CurrentWeek = currentWeek
+
+	ago, err := history.YearAgo(r.Context(), s.store, today)
+	if err != nil {
+		s.log.Error("year ago", "err", err)
+	}
+	if ago != nil && len(ago.Scores) > 0 {
+		data.YearAgo = ago
 	}
+
+	s.render(w, r, s.template.home, data)
 }
 
 func groupByWeek(matches []db.ScheduleMatch) []scheduleWeek {
 	var weeks []scheduleWeek
 	for _, m := range matches {
 		if len(weeks) == 0 || weeks[len(weeks)-1].Week != m.Week {
-			weeks = append(weeks, scheduleWeek{Week: m.Week, Date: m.Date})
+			weeks = append(weeks, scheduleWeek{Week: m.Week, Name: m.WeekName(), Date: m.Date})
 		}
 		weeks[len(weeks)-1].Matches = append(weeks[len(weeks)-1].Matches, m)
 	}
@@ -310,15 +586,26 @@ type teamData struct {
 	TeamKey  string
 	TeamName string
 	Matches  []db.ScheduleMatch
-	Roster   []db.PlayerSummary
+	Roster   []roster.Player
+
+	// Countdown is how long until the team's next match, the first of Matches.
+	Countdown string
+
+	// Recap summarizes the team's most recently completed match.
+	Recap *recap.Result
+
+	// Streaks are the rostered players currently on hot or cold streaks.
+	Streaks *streaks.Result
+
+	// RosterChanges are the players who recently joined or left the team.
+	RosterChanges []db.RosterEvent
 }
 
 func (s *Server) handleTeam(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	team := strings.ToUpper(r.PathValue("team"))
 
-	seattle, _ := time.LoadLocation("America/Los_Angeles")
-	today := time.Now().In(seattle).Format("2006-01-02")
+	today := s.clock.Today(s.now())
 	matches, err := s.store.ListSchedule(ctx, today)
 	if err != nil {
 		s.log.Error("list schedule", "err", err)
@@ -329,7 +616,7 @@ func (s *Server) handleTeam(w http.ResponseWriter, r *http.Request) {
 	matches = filterMatches(matches, team)
 
 	name := team
-	teams, err := s.store.ListTeams(ctx, team)
+	teams, err := s.store.ListTeams(ctx, team, 0)
 	if err == nil {
 		for _, t := range teams {
 			if t.Key == team {
@@ -339,17 +626,59 @@ func (s *Server) handleTeam(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	roster, _ := s.store.ListPlayers(ctx, team)
-	var filtered []db.PlayerSummary
-	for _, p := range roster {
-		if p.TeamKey == team {
-			filtered = append(filtered, p)
-		}
+	data := teamData{TeamKey: team, TeamName: name, Matches: matches}
+	if rs, err := roster.Analyze(ctx, s.store, team); err != nil {
+		s.log.Error("roster", "team", team, "err", err)
+	} else {
+		data.Roster = rs.Players
+	}
+	if len(matches) > 0 {
+		data.Countdown = s.clock.Countdown(s.now(), matches[0].Date)
 	}
 
-	if err := s.template.team.ExecuteTemplate(w, "layout.html", teamData{TeamKey: team, TeamName: name, Matches: matches, Roster: filtered}); err != nil {
-		s.log.Error("render template", "err", err)
+	rc, err := recap.Analyze(ctx, s.store, team)
+	if err != nil {
+		s.log.Error("recap", "team", team, "err", err)
+	}
+	if rc != nil && rc.Match != nil {
+		data.Recap = rc
+	}
+
+	st, err := streaks.Analyze(ctx, s.store, team)
+	if err != nil {
+		s.log.Error("streaks", "team", team, "err", err)
+	}
+	if st != nil && (len(st.Hot) > 0 || len(st.Cold) > 0) {
+		data.Streaks = st
 	}
+
+	data.RosterChanges = s.recentRosterChanges(ctx, team)
+
+	s.render(w, r, s.template.team, data)
+}
+
+// rosterChangeAge is how long a roster change is noticed on team and matchup
+// pages, long enough to span the week between matches.
+const rosterChangeAge = 14 * 24 * time.Hour
+
+// recentRosterChanges returns the supplied teams' recent roster changes,
+// newest first within each team.
+func (s *Server) recentRosterChanges(ctx context.Context, teams ...string) []db.RosterEvent {
+	since := s.now().Add(-rosterChangeAge)
+	var recent []db.RosterEvent
+	for _, team := range teams {
+		events, err := s.store.ListRosterEvents(ctx, strings.ToUpper(team))
+		if err != nil {
+			s.log.Error("list roster changes", "team", team, "err", err)
+			continue
+		}
+		for _, e := range events {
+			if e.SeenAt.After(since) {
+				recent = append(recent, e)
+			}
+		}
+	}
+	return recent
 }
 
 func filterMatches(matches []db.ScheduleMatch, team string) []db.ScheduleMatch {
@@ -366,12 +695,14 @@ func filterMatches(matches []db.ScheduleMatch, team string) []db.ScheduleMatch {
 
 type matchupData struct {
 	Venues []db.Venue
-	Teams  []db.TeamSummary
 	Venue  string
 	Team1  string
 	Team2  string
 	Result *matchup.Result
 	Error  string
+
+	// RosterChanges are the players who recently joined or left either team.
+	RosterChanges []db.RosterEvent
 }
 
 func (s *Server) handleMatchup(w http.ResponseWriter, r *http.Request) {
@@ -384,23 +715,15 @@ func (s *Server) handleMatchup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	teams, err := s.store.ListTeams(ctx, "")
-	if err != nil {
-		s.log.Error("list teams", "err", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
 	data := matchupData{
 		Venues: venues,
-		Teams:  teams,
 		Venue:  r.URL.Query().Get("venue"),
 		Team1:  r.URL.Query().Get("t1"),
 		Team2:  r.URL.Query().Get("t2"),
 	}
 
 	if data.Venue != "" && data.Team1 != "" && data.Team2 != "" {
-		result, err := matchup.Analyze(ctx, s.store, data.Venue, data.Team1, data.Team2)
+		result, err := s.analyzeMatchup(ctx, data.Venue, data.Team1, data.Team2)
 		switch {
 		case err != nil:
 			data.Error = fmt.Sprintf("Error: %v", err)
@@ -408,20 +731,17 @@ func (s *Server) handleMatchup(w http.ResponseWriter, r *http.Request) {
 			data.Error = fmt.Sprintf("No machines found at %s.", data.Venue)
 		default:
 			data.Result = result
+			data.RosterChanges = s.recentRosterChanges(ctx, data.Team1, data.Team2)
 		}
 	}
 
-	if err := s.template.matchup.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
-	}
+	s.render(w, r, s.template.matchup, data)
 }
 
 // Recommend page.
 
 type recommendData struct {
-	Teams    []db.TeamSummary
-	Machines []db.Machine
-	Venues   []db.Venue
+	Venues []db.Venue
 
 	Team    string
 	Machine string
@@ -435,7 +755,7 @@ type recommendData struct {
 	Error  string
 }
 
-func (d recommendData) FormatAssessment() string {
+func (d recommendData) FormatAssessment(f output.ScoreFormat) string {
 	a := d.Result.Assessment
 	if a == nil {
 		return ""
@@ -443,10 +763,10 @@ func (d recommendData) FormatAssessment() string {
 	switch a.Verdict {
 	case recommend.VerdictStrong:
 		return fmt.Sprintf("%s outscores %s's best (%s) by ~%s P50. Strong pick.",
-			a.OurBest, d.Result.Opponent, a.TheirBest, output.FormatScore(a.Diff))
+			a.OurBest, d.Result.Opponent, a.TheirBest, f.Score(a.Diff))
 	case recommend.VerdictWeak:
 		return fmt.Sprintf("%s's best (%s) outscores %s by ~%s P50. Weak pick.",
-			d.Result.Opponent, a.TheirBest, a.OurBest, output.FormatScore(-a.Diff))
+			d.Result.Opponent, a.TheirBest, a.OurBest, f.Score(-a.Diff))
 	case recommend.VerdictContested:
 		return fmt.Sprintf("%s and %s's best (%s) are roughly even. Contested.",
 			a.OurBest, d.Result.Opponent, a.TheirBest)
@@ -457,13 +777,6 @@ func (d recommendData) FormatAssessment() string {
 func (s *Server) handleRecommendForm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	teams, err := s.store.ListTeams(ctx, "")
-	if err != nil {
-		s.log.Error("list teams", "err", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
 	machines, err := s.store.ListMachines(ctx, "")
 	if err != nil {
 		s.log.Error("list machines", "err", err)
@@ -479,16 +792,20 @@ func (s *Server) handleRecommendForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := recommendData{
-		Teams:    teams,
-		Machines: machines,
-		Venues:   venues,
-		Team:     r.URL.Query().Get("team"),
-		Machine:  r.URL.Query().Get("machine"),
+		Venues:  venues,
+		Team:    r.URL.Query().Get("team"),
+		Machine: r.URL.Query().Get("machine"),
+		Venue:   r.URL.Query().Get("venue"),
+		Vs:      r.URL.Query().Get("vs"),
 	}
-
-	if err := s.template.recommend.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
+	for _, m := range machines {
+		if m.Key == data.Machine {
+			data.MachineName = m.Name
+			break
+		}
 	}
+
+	s.render(w, r, s.template.recommend, data)
 }
 
 func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
@@ -496,7 +813,7 @@ func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
 	team := strings.ToUpper(r.PathValue("team"))
 	machine := r.PathValue("machine")
 
-	teams, err := s.store.ListTeams(ctx, "")
+	teams, err := s.store.ListTeams(ctx, "", 0)
 	if err != nil {
 		s.log.Error("list teams", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -521,13 +838,11 @@ func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
 	vs := r.URL.Query().Get("vs")
 
 	data := recommendData{
-		Teams:    teams,
-		Machines: machines,
-		Venues:   venues,
-		Team:     team,
-		Machine:  machine,
-		Venue:    venue,
-		Vs:       vs,
+		Venues:  venues,
+		Team:    team,
+		Machine: machine,
+		Venue:   venue,
+		Vs:      vs,
 	}
 
 	for _, t := range teams {
@@ -561,20 +876,19 @@ func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
 		data.Result = result
 	}
 
-	if err := s.template.recommend.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
-	}
+	s.render(w, r, s.template.recommend, data)
 }
 
 // Scout page.
 
 type scoutData struct {
-	Teams  []db.TeamSummary
 	Venues []db.Venue
 
 	Team     string
 	Venue    string
 	TeamName string
+	CanBlend bool
+	Blend    bool
 
 	Result *scout.Result
 	Error  string
@@ -583,13 +897,6 @@ type scoutData struct {
 func (s *Server) handleScoutForm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	teams, err := s.store.ListTeams(ctx, "")
-	if err != nil {
-		s.log.Error("list teams", "err", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
 	venues, err := s.store.ListVenues(ctx, "")
 	if err != nil {
 		s.log.Error("list venues", "err", err)
@@ -598,20 +905,18 @@ func (s *Server) handleScoutForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := scoutData{
-		Teams:  teams,
-		Venues: venues,
+		Venues:   venues,
+		CanBlend: s.blended != nil,
 	}
 
-	if err := s.template.scout.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
-	}
+	s.render(w, r, s.template.scout, data)
 }
 
 func (s *Server) handleScout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	team := strings.ToUpper(r.PathValue("team"))
 
-	teams, err := s.store.ListTeams(ctx, "")
+	teams, err := s.store.ListTeams(ctx, "", 0)
 	if err != nil {
 		s.log.Error("list teams", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -626,12 +931,14 @@ func (s *Server) handleScout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	venue := r.URL.Query().Get("venue")
+	blend := s.blended != nil && r.URL.Query().Get("blend") != ""
 
 	data := scoutData{
-		Teams:  teams,
-		Venues: venues,
-		Team:   team,
-		Venue:  venue,
+		Venues:   venues,
+		Team:     team,
+		Venue:    venue,
+		CanBlend: s.blended != nil,
+		Blend:    blend,
 	}
 
 	for _, t := range teams {
@@ -641,12 +948,7 @@ func (s *Server) handleScout(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var opts []scout.Option
-	if venue != "" {
-		opts = append(opts, scout.AtVenue(venue))
-	}
-
-	result, err := scout.Analyze(ctx, s.store, team, opts...)
+	result, err := s.analyzeScout(ctx, team, venue, blend)
 	switch {
 	case err != nil:
 		data.Error = fmt.Sprintf("Error: %v", err)
@@ -656,17 +958,39 @@ func (s *Server) handleScout(w http.ResponseWriter, r *http.Request) {
 		data.Result = result
 	}
 
-	if err := s.template.scout.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
+	s.render(w, r, s.template.scout, data)
+}
+
+func (s *Server) analyzeScout(ctx context.Context, team, venue string, blend bool) (*scout.Result, error) {
+	if c, ok := s.store.(analysisCache); ok && !blend {
+		return c.Scout(ctx, team, venue)
 	}
+
+	var opts []scout.Option
+	if venue != "" {
+		opts = append(opts, scout.AtVenue(venue))
+	}
+	if blend {
+		return scout.Analyze(ctx, s.blended, team, append(opts, scout.Blended())...)
+	}
+	return scout.Analyze(ctx, s.store, team, opts...)
+}
+
+func (s *Server) analyzeMatchup(ctx context.Context, venue, team1, team2 string) (*matchup.Result, error) {
+	if c, ok := s.store.(analysisCache); ok {
+		return c.Matchup(ctx, venue, team1, team2)
+	}
+	return matchup.Analyze(ctx, s.store, venue, team1, team2)
 }
 
 // Player page.
 
 type playerData struct {
-	Name   string
-	Result *player.Result
-	Error  string
+	Name        string
+	Result      *player.Result
+	Error       string
+	Suggestions []string       // Similarly spelled names, if no player has Name.
+	Years       map[string]int // The year each machine was made, if known.
 }
 
 func (s *Server) handlePlayer(w http.ResponseWriter, r *http.Request) {
@@ -677,36 +1001,499 @@ func (s *Server) handlePlayer(w http.ResponseWriter, r *http.Request) {
 		Name: name,
 	}
 
-	result, err := player.Analyze(ctx, s.store, name)
+	result, err := player.Analyze(ctx, s.store, name, player.WithTrends(player.DefaultTrendWindow), player.WithMonthlyTrends(player.DefaultTrendMonths), player.WithGameLog(), player.WithGoals(), player.WithBadges())
 	switch {
 	case err != nil:
 		data.Error = fmt.Sprintf("Error: %v", err)
+	case result.Name != name:
+		// The name was spelled with different case. Send them to the
+		// page with the player's name as the archive spells it.
+		http.Redirect(w, r, "/p/"+url.PathEscape(result.Name), http.StatusFound)
+		return
 	case len(result.GlobalStats) == 0:
 		data.Error = fmt.Sprintf("No data for %s.", name)
+		data.Suggestions = result.Suggestions
 	default:
 		data.Result = result
 	}
 
-	if err := s.template.player.ExecuteTemplate(w, "layout.html", data); err != nil {
-		s.log.Error("render template", "err", err)
+	if data.Result != nil {
+		// Without years the radar charts machines instead of eras.
+		years, err := s.store.GetMachineYears(ctx)
+		if err != nil {
+			s.log.Error("load machine years", "err", err)
+		}
+		data.Years = years
+	}
+
+	s.render(w, r, s.template.player, data)
+}
+
+func (s *Server) handleCard(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	result, err := player.Analyze(r.Context(), s.store, name, player.WithSignatureWin())
+	switch {
+	case err != nil:
+		s.log.Error("player card", "player", name, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	case len(result.GlobalStats) == 0:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := card.Render(&buf, result); err != nil {
+		s.log.Error("render player card", "player", name, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes()) //nolint:errcheck // Nothing to do if the client went away.
+}
+
+// Players page.
+
+type playersData struct {
+	Query   string
+	Players []db.PlayerSummary
+}
+
+func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	players, err := s.store.ListPlayers(r.Context(), q)
+	if err != nil {
+		s.log.Error("list players", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.render(w, r, s.template.players, playersData{Query: q, Players: players})
+}
+
+// Search page.
+
+type searchData struct {
+	Query   string
+	Results []db.SearchResult
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	results, err := s.store.Search(r.Context(), q)
+	if err != nil {
+		s.log.Error("search", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
+
+	s.render(w, r, s.template.search, searchData{Query: q, Results: results})
+}
+
+// Standings page.
+
+type standingsData struct {
+	Standings []db.Standing
+	Seasons   []int // Loaded seasons, newest first.
+	Season    int   // Season to show. Zero for the current season.
+}
+
+func (s *Server) handleStandings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	season, _ := strconv.Atoi(r.URL.Query().Get("season"))
+
+	standings, err := s.store.GetStandings(ctx, season)
+	if err != nil {
+		s.log.Error("get standings", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := standingsData{Standings: standings, Season: season}
+
+	data.Seasons, err = s.store.ListSeasons(ctx)
+	if err != nil {
+		s.log.Error("list seasons", "err", err)
+	}
+	if len(data.Seasons) > 0 && season == data.Seasons[0] {
+		data.Season = 0
+	}
+
+	s.render(w, r, s.template.standings, data)
 }
 
 // Teams page.
 
 type teamsData struct {
-	Teams []db.TeamSummary
+	Teams     []db.TeamSummary
+	Returning map[string]db.RosterRetention
+	Kept      int   // Players league-wide still on last season's team.
+	Previous  int   // Players league-wide on last season's rosters of returning teams.
+	Seasons   []int // Loaded seasons, newest first.
+	Season    int   // Season to list. Zero for the current season.
 }
 
 func (s *Server) handleTeams(w http.ResponseWriter, r *http.Request) {
-	teams, err := s.store.ListTeams(r.Context(), "")
+	ctx := r.Context()
+
+	season, _ := strconv.Atoi(r.URL.Query().Get("season"))
+
+	teams, err := s.store.ListTeams(ctx, "", season)
 	if err != nil {
 		s.log.Error("list teams", "err", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	if err := s.template.teams.ExecuteTemplate(w, "layout.html", teamsData{Teams: teams}); err != nil {
-		s.log.Error("render template", "err", err)
+	data := teamsData{Teams: teams, Returning: make(map[string]db.RosterRetention), Season: season}
+
+	data.Seasons, err = s.store.ListSeasons(ctx)
+	if err != nil {
+		s.log.Error("list seasons", "err", err)
+	}
+	if len(data.Seasons) > 0 && season == data.Seasons[0] {
+		data.Season = 0
+	}
+
+	// Roster retention is only known for the current season.
+	if data.Season == 0 {
+		retention, err := s.store.ListRosterRetention(ctx)
+		if err != nil {
+			s.log.Error("list roster retention", "err", err)
+		}
+		for _, rr := range retention {
+			data.Returning[rr.TeamKey] = rr
+			data.Kept += rr.Kept
+			data.Previous += rr.Previous
+		}
+	}
+
+	s.render(w, r, s.template.teams, data)
+}
+
+// Venue schedule page.
+
+type venuesData struct {
+	All    []db.VenueSummary
+	Weeks  []scheduleWeek
+	Venues []venueWeeks
+	Teams  []teamWeeks
+	Team   string // Team to highlight.
+	Max    int    // Most matches any venue hosts in a week.
+}
+
+// venueWeeks is how many matches a venue hosts each week.
+type venueWeeks struct {
+	Key     string
+	Name    string
+	Matches []int // One per week, in the order of venuesData.Weeks.
+	Total   int
+}
+
+// teamWeeks is where a team plays each week.
+type teamWeeks struct {
+	Key    string
+	Name   string
+	Visits []teamVisit // One per week, in the order of venuesData.Weeks.
+}
+
+// teamVisit is the venue a team plays at in a week. VenueKey is empty if the
+// team has no match that week.
+type teamVisit struct {
+	VenueKey string
+	Venue    string
+	Home     bool
+}
+
+func (s *Server) handleVenues(w http.ResponseWriter, r *http.Request) {
+	matches, err := s.store.ListSchedule(r.Context(), "")
+	if err != nil {
+		s.log.Error("list schedule", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := venueSchedule(groupByWeek(matches))
+	data.Team = strings.ToUpper(r.URL.Query().Get("team"))
+
+	data.All, err = s.store.ListVenueSummaries(r.Context(), "")
+	if err != nil {
+		s.log.Error("list venue summaries", "err", err)
+	}
+	s.render(w, r, s.template.venues, data)
+}
+
+// venueSchedule tallies how many matches each venue hosts per week, and where
+// each team plays each week. Venues are ordered by total matches, and teams by
+// name.
+func venueSchedule(weeks []scheduleWeek) venuesData {
+	data := venuesData{Weeks: weeks}
+	venues := make(map[string]*venueWeeks)
+	teams := make(map[string]*teamWeeks)
+
+	for i, wk := range weeks {
+		for _, m := range wk.Matches {
+			for _, t := range []struct {
+				key, name string
+				home      bool
+			}{
+				{m.HomeTeamKey, m.HomeTeam, true},
+				{m.AwayTeamKey, m.AwayTeam, false},
+			} {
+				tw, ok := teams[t.key]
+				if !ok {
+					tw = &teamWeeks{Key: t.key, Name: t.name, Visits: make([]teamVisit, len(weeks))}
+					teams[t.key] = tw
+				}
+				tw.Visits[i] = teamVisit{VenueKey: m.VenueKey, Venue: m.Venue, Home: t.home}
+			}
+
+			if m.VenueKey == "" {
+				continue
+			}
+			vw, ok := venues[m.VenueKey]
+			if !ok {
+				vw = &venueWeeks{Key: m.VenueKey, Name: m.Venue, Matches: make([]int, len(weeks))}
+				venues[m.VenueKey] = vw
+			}
+			vw.Matches[i]++
+			vw.Total++
+			data.Max = max(data.Max, vw.Matches[i])
+		}
+	}
+
+	for _, vw := range venues {
+		data.Venues = append(data.Venues, *vw)
+	}
+	slices.SortFunc(data.Venues, func(a, b venueWeeks) int {
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	for _, tw := range teams {
+		data.Teams = append(data.Teams, *tw)
+	}
+	slices.SortFunc(data.Teams, func(a, b teamWeeks) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return data
+}
+
+// Machines page.
+
+type machinesData struct {
+	Machines []db.MachineSummary
+	Trends   map[string][]float64 // League P50 by month, keyed by machine.
+}
+
+func (s *Server) handleMachines(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	machines, err := s.store.ListMachineSummaries(ctx, "")
+	if err != nil {
+		s.log.Error("list machine summaries", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	monthly, err := s.store.GetMonthlyP50(ctx, "")
+	if err != nil {
+		s.log.Error("get monthly P50", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
+
+	s.render(w, r, s.template.machines, machinesData{Machines: machines, Trends: monthlyP50s(monthly, player.DefaultTrendMonths)})
+}
+
+// Leaderboard page.
+
+// leaderboardTop is how many scores the leaderboard lists for each machine.
+const leaderboardTop = 5
+
+type leaderboardData struct {
+	Boards   []machineBoard
+	Machines []db.Machine
+	Venues   []db.Venue
+	Seasons  []int  // Loaded seasons, newest first.
+	Machine  string // Machine to list. Empty for every machine.
+	Venue    string // Venue to list scores posted at. Empty for every venue.
+	Season   int    // Season to list scores posted in. Zero for every season.
+}
+
+// machineBoard is a machine's highest scores, highest first.
+type machineBoard struct {
+	Key    string
+	Name   string
+	Scores []topScore
+}
+
+// topScore is a score on the leaderboard. Its Score shadows the embedded
+// TopScore's so templates can format it.
+type topScore struct {
+	db.TopScore
+
+	Score float64
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	data := leaderboardData{
+		Machine: strings.ToUpper(r.URL.Query().Get("machine")),
+		Venue:   strings.ToUpper(r.URL.Query().Get("venue")),
+	}
+	data.Season, _ = strconv.Atoi(r.URL.Query().Get("season"))
+
+	scores, err := s.store.GetLeaderboard(ctx, data.Machine, data.Venue, data.Season, leaderboardTop)
+	if err != nil {
+		s.log.Error("get leaderboard", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	names, err := s.store.GetMachineNames(ctx)
+	if err != nil {
+		s.log.Error("get machine names", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Scores are ordered by machine, so each machine's are contiguous.
+	for _, ts := range scores {
+		if n := len(data.Boards); n == 0 || data.Boards[n-1].Key != ts.MachineKey {
+			data.Boards = append(data.Boards, machineBoard{Key: ts.MachineKey, Name: output.MachineName(names, ts.MachineKey)})
+		}
+		b := &data.Boards[len(data.Boards)-1]
+		b.Scores = append(b.Scores, topScore{TopScore: ts, Score: float64(ts.Score)})
+	}
+	slices.SortStableFunc(data.Boards, func(a, b machineBoard) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	if data.Machines, err = s.store.ListMachines(ctx, ""); err != nil {
+		s.log.Error("list machines", "err", err)
+	}
+	if data.Venues, err = s.store.ListVenues(ctx, ""); err != nil {
+		s.log.Error("list venues", "err", err)
+	}
+	if data.Seasons, err = s.store.ListSeasons(ctx); err != nil {
+		s.log.Error("list seasons", "err", err)
+	}
+
+	s.render(w, r, s.template.leaderboard, data)
+}
+
+// Season comparison page.
+
+type seasonsData struct {
+	TeamKey  string
+	TeamName string
+	Result   *seasons.Result
+	Message  string
+	Error    string
+}
+
+func (s *Server) handleSeasons(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team := strings.ToUpper(r.PathValue("team"))
+
+	data := seasonsData{TeamKey: team, TeamName: team}
+	if teams, err := s.store.ListTeams(ctx, team, 0); err == nil {
+		for _, t := range teams {
+			if t.Key == team {
+				data.TeamName = t.Name
+				break
+			}
+		}
+	}
+
+	var opts []seasons.Option
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom == nil && errTo == nil {
+		opts = append(opts, seasons.Between(from, to))
+	}
+
+	result, err := seasons.Analyze(ctx, s.store, team, opts...)
+	switch {
+	case err != nil:
+		data.Error = fmt.Sprintf("Error: %v", err)
+	case result.From.Number == 0:
+		data.Message = fmt.Sprintf("%s has only played one season.", data.TeamName)
+	default:
+		data.Result = result
+	}
+
+	s.render(w, r, s.template.seasons, data)
+}
+
+// Practice planner page.
+
+type practiceData struct {
+	TeamKey     string
+	TeamName    string
+	Weeks       int
+	WeekChoices []int
+	Result      *practice.Result
+	Error       string
+}
+
+func (s *Server) handlePractice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team := strings.ToUpper(r.PathValue("team"))
+
+	data := practiceData{TeamKey: team, TeamName: team, Weeks: practice.DefaultWeeks, WeekChoices: []int{1, 2, 3, 4, 5, 6}}
+	if teams, err := s.store.ListTeams(ctx, team, 0); err == nil {
+		for _, t := range teams {
+			if t.Key == team {
+				data.TeamName = t.Name
+				break
+			}
+		}
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("weeks")); err == nil && n > 0 {
+		data.Weeks = n
+	}
+
+	result, err := practice.Analyze(ctx, s.store, team, s.clock.Today(s.now()), practice.ForWeeks(data.Weeks))
+	if err != nil {
+		data.Error = fmt.Sprintf("Error: %v", err)
+	} else {
+		data.Result = result
+	}
+
+	s.render(w, r, s.template.practice, data)
+}
+
+// Model accuracy page.
+
+type accuracyData struct {
+	Result  *accuracy.Result
+	Message string
+	Error   string
+}
+
+func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
+	var data accuracyData
+
+	result, err := accuracy.Analyze(r.Context(), s.store)
+	switch {
+	case err != nil:
+		data.Error = fmt.Sprintf("Error: %v", err)
+	case result.Overall.Predictions == 0:
+		data.Message = "No predictions have been scored yet. Predictions are recorded before each match, and scored once its results load."
+	default:
+		data.Result = result
+	}
+
+	s.render(w, r, s.template.accuracy, data)
 }
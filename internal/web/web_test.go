@@ -0,0 +1,836 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/negz/mnp/internal/cache"
+	"github.com/negz/mnp/internal/db"
+	"github.com/negz/mnp/internal/imgcache"
+	"github.com/negz/mnp/internal/schedule"
+)
+
+var update = flag.Bool("update", false, "Update golden files in testdata.")
+
+// newTestServer returns a server backed by an in-memory store seeded with a
+// small league fixture:
+//
+//	Season 23
+//	Venue STN (Seattle Tavern and Pool Hall) with machines TAF, TZ
+//	Venue GPA (Georgetown Pizza and Arcade) with machines MM, TZ
+//	Team TTT (The Trailer Trashers) at STN — players Alice Smith, Bob Jones
+//	Team KNR (Knight Riders) at GPA — players Carol White, Dave Brown
+//	Week 1, 2024-01-15: KNR at TTT, played, with TTT predicted to win TZ and KNR MM
+//	Week 2, 2024-01-22: TTT at KNR, not yet played
+//	Erin Green left KNR's roster on 2024-01-17
+//	Synced from archive commit abc1234, made 2024-01-15 at 7:42pm, in 12s
+//
+// The server's clock is fixed to 2024-01-18, between the two matches. Any
+// options are applied after the fixture's.
+func newTestServer(t *testing.T, opts ...ServerOption) http.Handler {
+	t.Helper()
+
+	ctx := context.Background()
+	s, err := db.Open(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() }) //nolint:errcheck // Nothing to do if closing fails.
+
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	seasonID, err := s.UpsertSeason(ctx, 23)
+	if err != nil {
+		t.Fatalf("UpsertSeason: %v", err)
+	}
+
+	stn, err := s.UpsertVenue(ctx, "STN", "Seattle Tavern and Pool Hall")
+	if err != nil {
+		t.Fatalf("UpsertVenue: %v", err)
+	}
+	gpa, err := s.UpsertVenue(ctx, "GPA", "Georgetown Pizza and Arcade")
+	if err != nil {
+		t.Fatalf("UpsertVenue: %v", err)
+	}
+
+	for _, m := range []db.Machine{
+		{Key: "TAF", Name: "The Addams Family"},
+		{Key: "TZ", Name: "Twilight Zone"},
+		{Key: "MM", Name: "Medieval Madness"},
+	} {
+		if err := s.UpsertMachine(ctx, m); err != nil {
+			t.Fatalf("UpsertMachine: %v", err)
+		}
+	}
+	for venue, machines := range map[int64][]string{stn: {"TAF", "TZ"}, gpa: {"MM", "TZ"}} {
+		for _, m := range machines {
+			if err := s.UpsertVenueMachine(ctx, venue, seasonID, m); err != nil {
+				t.Fatalf("UpsertVenueMachine: %v", err)
+			}
+		}
+	}
+
+	ttt, err := s.UpsertTeam(ctx, db.Team{Key: "TTT", Name: "The Trailer Trashers", SeasonID: seasonID, HomeVenueID: stn})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+	knr, err := s.UpsertTeam(ctx, db.Team{Key: "KNR", Name: "Knight Riders", SeasonID: seasonID, HomeVenueID: gpa})
+	if err != nil {
+		t.Fatalf("UpsertTeam: %v", err)
+	}
+
+	players := map[string]int64{}
+	for name, team := range map[string]int64{"Alice Smith": ttt, "Bob Jones": ttt, "Carol White": knr, "Dave Brown": knr} {
+		id, err := s.UpsertPlayer(ctx, name)
+		if err != nil {
+			t.Fatalf("UpsertPlayer: %v", err)
+		}
+		if err := s.UpsertRoster(ctx, id, team, "P"); err != nil {
+			t.Fatalf("UpsertRoster: %v", err)
+		}
+		players[name] = id
+	}
+
+	matchID, err := s.UpsertMatch(ctx, db.Match{Key: "mnp-23-1-KNR-TTT", SeasonID: seasonID, Week: 1, Date: "2024-01-15", HomeTeamID: ttt, AwayTeamID: knr, VenueID: stn})
+	if err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+	if _, err := s.UpsertMatch(ctx, db.Match{Key: "mnp-23-2-TTT-KNR", SeasonID: seasonID, Week: 2, Date: "2024-01-22", HomeTeamID: knr, AwayTeamID: ttt, VenueID: gpa}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	// The web UI predicted TTT's edge on TZ and KNR's on MM before week 1. Predictions
+	// are only recorded before a match has results.
+	for machine, edge := range map[string]float64{"TZ": 20, "MM": -10} {
+		if err := s.UpsertPrediction(ctx, db.Prediction{MatchKey: "mnp-23-1-KNR-TTT", MachineKey: machine, HomeTeamKey: "TTT", AwayTeamKey: "KNR", Edge: edge, Confidence: 1}); err != nil {
+			t.Fatalf("UpsertPrediction: %v", err)
+		}
+	}
+
+	type result struct {
+		player string
+		team   int64
+		score  int64
+		points float64
+	}
+	games := []struct {
+		round   int
+		machine string
+		doubles bool
+		results []result
+	}{
+		{1, "TAF", true, []result{{"Alice Smith", ttt, 50_000_000, 2.5}, {"Bob Jones", ttt, 40_000_000, 2}, {"Carol White", knr, 30_000_000, 0.5}, {"Dave Brown", knr, 20_000_000, 0}}},
+		{2, "TZ", false, []result{{"Alice Smith", ttt, 100_000_000, 0}, {"Carol White", knr, 150_000_000, 3}}},
+		{3, "TAF", false, []result{{"Bob Jones", ttt, 35_000_000, 3}, {"Dave Brown", knr, 25_000_000, 0}}},
+		{4, "MM", false, []result{{"Alice Smith", ttt, 60_000_000, 0}, {"Carol White", knr, 70_000_000, 3}}},
+	}
+	for _, g := range games {
+		gameID, err := s.InsertGame(ctx, db.Game{MatchID: matchID, Round: g.round, MachineKey: g.machine, IsDoubles: g.doubles})
+		if err != nil {
+			t.Fatalf("InsertGame: %v", err)
+		}
+		for i, r := range g.results {
+			if err := s.InsertGameResult(ctx, db.GameResult{GameID: gameID, PlayerID: players[r.player], TeamID: r.team, Position: i + 1, Score: r.score, Points: r.points}); err != nil {
+				t.Fatalf("InsertGameResult: %v", err)
+			}
+		}
+	}
+
+	for name, team := range map[string]int64{"Alice Smith": ttt, "Bob Jones": ttt, "Carol White": knr, "Dave Brown": knr} {
+		if err := s.InsertMatchLineup(ctx, db.MatchLineup{MatchID: matchID, PlayerID: players[name], TeamID: team}); err != nil {
+			t.Fatalf("InsertMatchLineup: %v", err)
+		}
+	}
+
+	for key, value := range map[string]string{
+		db.MetadataArchiveCommit:     "abc1234def5678",
+		db.MetadataArchiveCommitTime: "2024-01-15T19:42:00Z",
+		db.MetadataLastSync:          "2024-01-16T08:00:00Z",
+		db.MetadataSyncAttempt:       "2024-01-16T08:00:00Z",
+		db.MetadataSyncDuration:      "12s",
+	} {
+		if err := s.SetMetadata(ctx, key, value); err != nil {
+			t.Fatalf("SetMetadata: %v", err)
+		}
+	}
+
+	// A sync noticed Erin Green leave KNR. She's left no other trace, so the
+	// event is inserted directly.
+	if _, err := s.DB().ExecContext(ctx, `
+		INSERT INTO roster_events (season, team_key, player_name, change, seen_at)
+		VALUES (23, 'KNR', 'Erin Green', 'left', '2024-01-17T08:00:00Z')
+	`); err != nil {
+		t.Fatalf("insert roster event: %v", err)
+	}
+
+	if err := s.RebuildSearchIndex(ctx); err != nil {
+		t.Fatalf("RebuildSearchIndex: %v", err)
+	}
+
+	st := cache.NewInMemoryStore(s)
+	if err := st.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	clock := schedule.Clock{Location: time.UTC, Start: schedule.DefaultStart, Rollover: schedule.DefaultRollover}
+	opts = append([]ServerOption{WithClock(clock), WithAdminToken("secret"), WithAvatarDir(t.TempDir()), WithMetrics(NewMetrics(s.Size, st.Stats)), WithBlendedStore(s)}, opts...)
+	srv := NewServer(st, log, opts...)
+	srv.now = func() time.Time { return time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC) }
+	return srv.Handler()
+}
+
+// mainElement returns the page's <main> element, which holds everything that
+// differs between pages.
+func mainElement(body string) string {
+	start := strings.Index(body, "<main")
+	end := strings.Index(body, "</main>")
+	if start < 0 || end < start {
+		return body
+	}
+	return body[start:end+len("</main>")] + "\n"
+}
+
+func TestRoutes(t *testing.T) {
+	h := newTestServer(t)
+
+	type want struct {
+		status      int
+		location    string // Checked if set.
+		contentType string // Checked if set, as a prefix.
+		contains    string // Checked if set, against the whole body.
+		vary        string // Checked if set.
+		golden      string // Name of a file in testdata to compare the <main> element to. Checked if set.
+	}
+
+	cases := map[string]struct {
+		reason string
+		method string
+		path   string
+		body   string // Form-encoded request body, if any.
+		want   want
+	}{
+		"Home": {
+			reason: "The home page should show the upcoming week's schedule.",
+			path:   "/",
+			want:   want{status: http.StatusOK, golden: "home.html"},
+		},
+		"Team": {
+			reason: "A team page should show the team's schedule and latest match.",
+			path:   "/t/TTT",
+			want:   want{status: http.StatusOK, golden: "team.html"},
+		},
+		"Matchup": {
+			reason: "The matchup page should compare two teams at a venue.",
+			path:   "/matchup?venue=STN&t1=TTT&t2=KNR",
+			want:   want{status: http.StatusOK, golden: "matchup.html"},
+		},
+		"ScoutForm": {
+			reason: "The scout page without a team should show the team picker.",
+			path:   "/scout",
+			want:   want{status: http.StatusOK, golden: "scout-form.html"},
+		},
+		"ScoutRedirect": {
+			reason: "The scout form should redirect to the team's scout page, keeping other parameters.",
+			path:   "/scout?team=ttt&venue=STN",
+			want:   want{status: http.StatusFound, location: "/t/TTT/scout?venue=STN"},
+		},
+		"Scout": {
+			reason: "A team's scout page should show its stats on each machine.",
+			path:   "/t/TTT/scout",
+			want:   want{status: http.StatusOK, golden: "scout.html"},
+		},
+		"ScoutBlended": {
+			reason: "A team's scout page should blend seasons when asked.",
+			path:   "/t/TTT/scout?blend=1",
+			want:   want{status: http.StatusOK, golden: "scout-blended.html"},
+		},
+		"Seasons": {
+			reason: "A team's seasons page should compare its seasons.",
+			path:   "/t/TTT/seasons",
+			want:   want{status: http.StatusOK, golden: "seasons.html"},
+		},
+		"Practice": {
+			reason: "A team's practice page should plan practice for its upcoming matches.",
+			path:   "/t/TTT/practice",
+			want:   want{status: http.StatusOK, golden: "practice.html"},
+		},
+		"RecommendForm": {
+			reason: "The recommend page without a team and machine should show the pickers.",
+			path:   "/recommend",
+			want:   want{status: http.StatusOK, golden: "recommend-form.html"},
+		},
+		"RecommendRedirect": {
+			reason: "The recommend form should redirect to the team and machine's recommend page.",
+			path:   "/recommend?team=ttt&machine=TAF",
+			want:   want{status: http.StatusFound, location: "/t/TTT/recommend/TAF"},
+		},
+		"Recommend": {
+			reason: "A recommend page should rank the team's players on the machine.",
+			path:   "/t/TTT/recommend/TAF",
+			want:   want{status: http.StatusOK, golden: "recommend.html"},
+		},
+		"RecommendVsOpponent": {
+			reason: "A recommend page with an opponent should compare both teams' players.",
+			path:   "/t/TTT/recommend/TAF?vs=KNR",
+			want:   want{status: http.StatusOK, golden: "recommend-vs.html"},
+		},
+		"Player": {
+			reason: "A player page should show the player's stats on each machine.",
+			path:   "/p/Alice%20Smith",
+			want:   want{status: http.StatusOK, golden: "player.html"},
+		},
+		"PlayerWithoutData": {
+			reason: "A player page for an unknown player should say there's no data.",
+			path:   "/p/Nobody",
+			want:   want{status: http.StatusOK, golden: "player-no-data.html"},
+		},
+		"PlayerDifferentCase": {
+			reason: "A player page for a name spelled with different case should redirect to the player's page.",
+			path:   "/p/alice%20SMITH",
+			want:   want{status: http.StatusFound, location: "/p/Alice%20Smith"},
+		},
+		"PlayerMisspelled": {
+			reason: "A player page for a misspelled name should suggest similarly spelled players.",
+			path:   "/p/Alise%20Smith",
+			want:   want{status: http.StatusOK, golden: "player-misspelled.html"},
+		},
+		"Match": {
+			reason: "A match page should show every game's players, scores, and points.",
+			path:   "/m/mnp-23-1-KNR-TTT",
+			want:   want{status: http.StatusOK, golden: "match.html"},
+		},
+		"UnplayedMatch": {
+			reason: "A match page for a match that hasn't been played should say so.",
+			path:   "/m/mnp-23-2-TTT-KNR",
+			want:   want{status: http.StatusOK, contains: "This match hasn't been played yet."},
+		},
+		"UnknownMatch": {
+			reason: "A match page for an unknown match should be missing.",
+			path:   "/m/mnp-23-9-TTT-KNR",
+			want:   want{status: http.StatusNotFound, contains: "No match mnp-23-9-TTT-KNR.", vary: "Accept-Language, Cookie"},
+		},
+		"Pickem": {
+			reason: "The pick'em page should offer next week's matches to pick, and a leaderboard.",
+			path:   "/pickem",
+			want:   want{status: http.StatusOK, golden: "pickem.html"},
+		},
+		"PickemSubmit": {
+			reason: "Submitting picks should save picks for matches that haven't started.",
+			method: http.MethodPost,
+			path:   "/pickem",
+			body:   "picker=Erin&pick-mnp-23-2-TTT-KNR=TTT",
+			want:   want{status: http.StatusOK, contains: "Saved 1 pick for Erin."},
+		},
+		"PickemSubmitWithoutName": {
+			reason: "Submitting picks without a name should be rejected.",
+			method: http.MethodPost,
+			path:   "/pickem",
+			body:   "pick-mnp-23-2-TTT-KNR=TTT",
+			want:   want{status: http.StatusBadRequest, contains: "Enter your name to make picks.", vary: "Accept-Language, Cookie"},
+		},
+		"Players": {
+			reason: "The players page should list this season's players.",
+			path:   "/players",
+			want:   want{status: http.StatusOK, golden: "players.html"},
+		},
+		"PlayersSearch": {
+			reason: "The players page should filter players by name or team.",
+			path:   "/players?q=knight",
+			want:   want{status: http.StatusOK, golden: "players-search.html"},
+		},
+		"Search": {
+			reason: "The search page should link to the players, teams, machines, and venues that match.",
+			path:   "/search?q=t",
+			want:   want{status: http.StatusOK, golden: "search.html"},
+		},
+		"Teams": {
+			reason: "The teams page should list the current season's teams.",
+			path:   "/teams",
+			want:   want{status: http.StatusOK, golden: "teams.html"},
+		},
+		"Standings": {
+			reason: "The standings page should show the league table.",
+			path:   "/standings",
+			want:   want{status: http.StatusOK, golden: "standings.html"},
+		},
+		"Venues": {
+			reason: "The venues page should list venues and their schedule.",
+			path:   "/venues",
+			want:   want{status: http.StatusOK, golden: "venues.html"},
+		},
+		"Leaderboard": {
+			reason: "The leaderboard page should list each machine's highest scores.",
+			path:   "/leaderboard",
+			want:   want{status: http.StatusOK, golden: "leaderboard.html"},
+		},
+		"LeaderboardMachine": {
+			reason: "The leaderboard page should list one machine's highest scores when filtered to it.",
+			path:   "/leaderboard?machine=taf&season=23",
+			want:   want{status: http.StatusOK, golden: "leaderboard-machine.html"},
+		},
+		"Machines": {
+			reason: "The machines page should list machines.",
+			path:   "/machines",
+			want:   want{status: http.StatusOK, golden: "machines.html"},
+		},
+		"Accuracy": {
+			reason: "The model accuracy page should render without predictions.",
+			path:   "/model/accuracy",
+			want:   want{status: http.StatusOK, golden: "accuracy.html"},
+		},
+		"AvatarAdmin": {
+			reason: "The avatar admin page should render when an admin token and avatar directory are set.",
+			path:   "/admin/avatars",
+			want:   want{status: http.StatusOK, golden: "avatars.html"},
+		},
+		"Anomalies": {
+			reason: "The anomalies page should render when an admin token is set.",
+			path:   "/admin/anomalies",
+			want:   want{status: http.StatusOK, golden: "anomalies.html"},
+		},
+		"AvatarUploadInvalid": {
+			reason: "A rejected avatar upload should still vary by the headers the page depends on.",
+			method: http.MethodPost,
+			path:   "/admin/avatars",
+			body:   "token=secret",
+			want:   want{status: http.StatusBadRequest, vary: "Accept-Language, Cookie"},
+		},
+		"AnomaliesInvalidToken": {
+			reason: "Checking anomalies with the wrong token should be forbidden, and vary by the headers the page depends on.",
+			method: http.MethodPost,
+			path:   "/admin/anomalies",
+			body:   "token=wrong",
+			want:   want{status: http.StatusForbidden, vary: "Accept-Language, Cookie"},
+		},
+		"Avatar": {
+			reason: "Players without an avatar should get a generated one.",
+			path:   "/avatars/Alice%20Smith",
+			want:   want{status: http.StatusOK, contentType: "image/svg+xml"},
+		},
+		"Card": {
+			reason: "A player card should be a PNG.",
+			path:   "/cards/Alice%20Smith",
+			want:   want{status: http.StatusOK, contentType: "image/png"},
+		},
+		"ArtDisabled": {
+			reason: "Machine art should be missing when no image cache is configured.",
+			path:   "/art/TAF",
+			want:   want{status: http.StatusNotFound},
+		},
+		"Static": {
+			reason: "Static assets should be served.",
+			path:   "/static/pico.min.css",
+			want:   want{status: http.StatusOK, contentType: "text/css"},
+		},
+		"DataAsOf": {
+			reason: "The footer should say which archive commit the data is from, and when it was made.",
+			path:   "/standings",
+			want:   want{status: http.StatusOK, contains: "Data as of Mon 7:42pm (commit abc1234)"},
+		},
+		"Healthz": {
+			reason: "The health check should succeed.",
+			path:   "/healthz",
+			want:   want{status: http.StatusOK},
+		},
+		"Calendar": {
+			reason: "The calendar feed should list every match.",
+			path:   "/calendar.ics",
+			want:   want{status: http.StatusOK, contentType: "text/calendar", contains: "SUMMARY:The Trailer Trashers vs Knight Riders"},
+		},
+		"TeamCalendar": {
+			reason: "A team's calendar feed should list its matches, starting early enough to arrive.",
+			path:   "/t/ttt/calendar.ics?arrive=30m",
+			want:   want{status: http.StatusOK, contentType: "text/calendar", contains: "Arrive by"},
+		},
+		"TeamCalendarUnknownTeam": {
+			reason: "A calendar feed for a team with no matches should not be found.",
+			path:   "/t/XXX/calendar.ics",
+			want:   want{status: http.StatusNotFound},
+		},
+		"CalendarInvalidArrive": {
+			reason: "A calendar feed with an unparseable arrive parameter should be a bad request.",
+			path:   "/calendar.ics?arrive=soon",
+			want:   want{status: http.StatusBadRequest},
+		},
+		"Readyz": {
+			reason: "The server should be ready when it has no ready check.",
+			path:   "/readyz",
+			want:   want{status: http.StatusOK},
+		},
+		"RobotsTxt": {
+			reason: "robots.txt should be empty plain text.",
+			path:   "/robots.txt",
+			want:   want{status: http.StatusOK, contentType: "text/plain"},
+		},
+		"Metrics": {
+			reason: "Metrics should be served in the Prometheus text format.",
+			path:   "/metrics",
+			want:   want{status: http.StatusOK, contentType: "text/plain", contains: "# TYPE mnp_database_size_bytes gauge"},
+		},
+		"Favicon": {
+			reason: "There's no favicon.",
+			path:   "/favicon.ico",
+			want:   want{status: http.StatusNotFound},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.want.status {
+				t.Errorf("\n%s\n%s %s: want status %d, got %d", tc.reason, method, tc.path, tc.want.status, rec.Code)
+			}
+			if tc.want.location != "" {
+				if diff := cmp.Diff(tc.want.location, rec.Header().Get("Location")); diff != "" {
+					t.Errorf("\n%s\n%s %s: Location -want, +got:\n%s", tc.reason, method, tc.path, diff)
+				}
+			}
+			if tc.want.contentType != "" {
+				if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tc.want.contentType) {
+					t.Errorf("\n%s\n%s %s: want Content-Type %s, got %s", tc.reason, method, tc.path, tc.want.contentType, got)
+				}
+			}
+			if tc.want.vary != "" {
+				if diff := cmp.Diff(tc.want.vary, rec.Result().Header.Get("Vary")); diff != "" {
+					t.Errorf("\n%s\n%s %s: Vary -want, +got:\n%s", tc.reason, method, tc.path, diff)
+				}
+			}
+			if tc.want.contains != "" && !strings.Contains(rec.Body.String(), tc.want.contains) {
+				t.Errorf("\n%s\n%s %s: want body to contain %q", tc.reason, method, tc.path, tc.want.contains)
+			}
+			if tc.want.golden == "" {
+				return
+			}
+
+			got := mainElement(rec.Body.String())
+			path := filepath.Join("testdata", tc.want.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o600); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if diff := cmp.Diff(string(want), got); diff != "" {
+				t.Errorf("\n%s\n%s %s: -want, +got:\n%s", tc.reason, method, tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestPickemOwnership(t *testing.T) {
+	h := newTestServer(t)
+
+	// submit posts picks, with the supplied cookies, and returns the response.
+	submit := func(body string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pickem", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Erin's first picks claim her name for her browser.
+	rec := submit("picker=Erin&pick-mnp-23-2-TTT-KNR=TTT")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /pickem as Erin: want status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var token *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == pickerTokenCookie {
+			token = c
+		}
+	}
+	if token == nil {
+		t.Fatalf("POST /pickem as Erin: want a %s cookie", pickerTokenCookie)
+	}
+
+	// Someone else can't overwrite them, with or without a token of their own.
+	for _, cookies := range [][]*http.Cookie{nil, {{Name: pickerTokenCookie, Value: "impostor"}}} {
+		rec = submit("picker=Erin&pick-mnp-23-2-TTT-KNR=KNR", cookies...)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("POST /pickem as someone else claiming to be Erin: want status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+		if want := "Someone else makes picks as Erin."; !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("POST /pickem as someone else claiming to be Erin: want body to contain %q", want)
+		}
+	}
+
+	// Erin's picks are unchanged, and she can still change them.
+	req := httptest.NewRequest(http.MethodGet, "/pickem", nil)
+	req.AddCookie(&http.Cookie{Name: pickerCookie, Value: "Erin"})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if want := `value="TTT" checked`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("GET /pickem as Erin after someone else claimed to be her: want her pick of TTT, %q", want)
+	}
+
+	rec = submit("picker=Erin&pick-mnp-23-2-TTT-KNR=KNR", token)
+	if want := "Saved 1 pick for Erin."; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("POST /pickem as Erin again: want body to contain %q", want)
+	}
+}
+
+func TestArt(t *testing.T) {
+	var fetched []string
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		w.Write([]byte("\x89PNG\r\n\x1a\n")) //nolint:errcheck // Test server.
+	}))
+	defer src.Close()
+
+	h := newTestServer(t, WithMachineArt(imgcache.New(t.TempDir(), src.URL+"/{key}")))
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   int
+	}{
+		"KnownMachine": {
+			reason: "A known machine's art should be fetched from the source.",
+			path:   "/art/TAF",
+			want:   http.StatusOK,
+		},
+		"UnknownMachine": {
+			reason: "Art for a machine that doesn't exist should be missing without asking the source.",
+			path:   "/art/NOPE",
+			want:   http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != tc.want {
+				t.Errorf("\n%s\nGET %s: want status %d, got %d", tc.reason, tc.path, tc.want, rec.Code)
+			}
+		})
+	}
+
+	if diff := cmp.Diff([]string{"/TAF"}, fetched); diff != "" {
+		t.Errorf("GET /art/...: only known machines should be fetched: -want, +got:\n%s", diff)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ready  bool
+		want   int
+	}{
+		"Ready": {
+			reason: "The server should be ready once its ready check passes.",
+			ready:  true,
+			want:   http.StatusOK,
+		},
+		"NotReady": {
+			reason: "The server should be unavailable until its ready check passes.",
+			ready:  false,
+			want:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+			h := NewServer(cache.NewInMemoryStore(nil), log, WithReadyCheck(func() bool { return tc.ready })).Handler()
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tc.want {
+				t.Errorf("\n%s\nGET /readyz: want status %d, got %d", tc.reason, tc.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestSyncFailed(t *testing.T) {
+	ctx := context.Background()
+	s, err := db.Open(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := s.RecordSyncAttempt(ctx, time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC), time.Second, errors.New("no network")); err != nil {
+		t.Fatalf("RecordSyncAttempt: %v", err)
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	clock := schedule.Clock{Location: time.UTC, Start: schedule.DefaultStart, Rollover: schedule.DefaultRollover}
+	h := NewServer(cache.NewInMemoryStore(s), log, WithClock(clock)).Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/players", nil))
+
+	want := `<a class="sync-failed" href="/api/v1/sync">⚠ Sync failed Tue 8:00am</a>`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("GET /players: want footer to contain %q", want)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(func(context.Context) (int64, error) { return 4096, nil }, func() cache.Stats {
+		return cache.Stats{Entries: 3, Bytes: 2048, MaxBytes: 8192, Hits: 5, Misses: 3, Evictions: 1}
+	})
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	h := NewServer(cache.NewInMemoryStore(nil), log, WithMetrics(m)).Handler()
+
+	// Requests are counted by the route they matched. The DELETE matches none.
+	// Nonstandard methods are counted together, whatever they are.
+	for _, path := range []string{"/healthz", "/robots.txt", "/robots.txt"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/healthz", nil))
+	for _, method := range []string{"BREW", "PROPFIND"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/no/such/page", nil))
+	}
+
+	syncFn := m.Sync(func(context.Context) error { return nil })
+	syncFn(context.Background()) //nolint:errcheck // Always succeeds.
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, want := range []string{
+		`mnp_http_requests_total{route="GET /healthz",method="GET",code="200"} 1`,
+		`mnp_http_requests_total{route="GET /robots.txt",method="GET",code="200"} 2`,
+		`mnp_http_requests_total{route="unmatched",method="DELETE",code="405"} 1`,
+		`mnp_http_requests_total{route="unmatched",method="OTHER",code="405"} 2`,
+		`mnp_http_request_duration_seconds_count{route="GET /robots.txt"} 2`,
+		`mnp_syncs_total{result="success"} 1`,
+		`mnp_sync_duration_seconds_count 1`,
+		`mnp_database_size_bytes 4096`,
+		`mnp_analysis_cache_entries 3`,
+		`mnp_analysis_cache_bytes 2048`,
+		`mnp_analysis_cache_max_bytes 8192`,
+		"# TYPE mnp_analysis_cache_hits_total counter\nmnp_analysis_cache_hits_total 5",
+		`mnp_analysis_cache_misses_total 3`,
+		`mnp_analysis_cache_evictions_total 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET /metrics: want body to contain %q, got:\n%s", want, rec.Body.String())
+		}
+	}
+	for _, unwanted := range []string{"BREW", "PROPFIND", "/no/such/page"} {
+		if strings.Contains(rec.Body.String(), unwanted) {
+			t.Errorf("GET /metrics: want no label derived from the request, got %q in:\n%s", unwanted, rec.Body.String())
+		}
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	type request struct {
+		path   string
+		addr   string
+		client string // Fly-Client-IP header.
+	}
+
+	cases := map[string]struct {
+		reason   string
+		header   string
+		requests []request
+		want     []int
+	}{
+		"Burst": {
+			reason: "A client should be limited once it has used its burst.",
+			requests: []request{
+				{path: "/", addr: "10.0.0.1:1234"},
+				{path: "/players", addr: "10.0.0.1:1235"},
+				{path: "/", addr: "10.0.0.1:1236"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		"PerClient": {
+			reason: "Each client should have its own limit.",
+			requests: []request{
+				{path: "/", addr: "10.0.0.1:1234"},
+				{path: "/", addr: "10.0.0.1:1234"},
+				{path: "/", addr: "10.0.0.2:1234"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		"Unlimited": {
+			reason: "Static assets and health checks shouldn't be limited.",
+			requests: []request{
+				{path: "/", addr: "10.0.0.1:1234"},
+				{path: "/", addr: "10.0.0.1:1234"},
+				{path: "/static/style.css", addr: "10.0.0.1:1234"},
+				{path: "/healthz", addr: "10.0.0.1:1234"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		"Header": {
+			reason: "Clients should be identified by the header a proxy sets, if any.",
+			header: "Fly-Client-IP",
+			requests: []request{
+				{path: "/", addr: "10.0.0.1:1234", client: "192.0.2.1"},
+				{path: "/", addr: "10.0.0.1:1234", client: "192.0.2.1"},
+				{path: "/", addr: "10.0.0.1:1234", client: "192.0.2.2"},
+				{path: "/", addr: "10.0.0.1:1234", client: "192.0.2.1"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	ok := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {})
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Two requests at once, then one every 1000 seconds.
+			h := WithRateLimit(ok, 0.001, 2, tc.header)
+
+			got := make([]int, len(tc.requests))
+			for i, r := range tc.requests {
+				req := httptest.NewRequest(http.MethodGet, r.path, nil)
+				req.RemoteAddr = r.addr
+				if r.client != "" {
+					req.Header.Set("Fly-Client-IP", r.client)
+				}
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				got[i] = rec.Code
+
+				if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "1000" {
+					t.Errorf("\n%s\nGET %s: want Retry-After 1000, got %q", tc.reason, r.path, rec.Header().Get("Retry-After"))
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWithRateLimit(...): -want status codes, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}